@@ -7,24 +7,85 @@ import (
 	"github.com/projectdiscovery/goflags"
 	"github.com/projectdiscovery/gologger"
 	"github.com/projectdiscovery/nuclei/v2/internal/runner"
+	"github.com/projectdiscovery/nuclei/v2/pkg/control"
 	"github.com/projectdiscovery/nuclei/v2/pkg/types"
 )
 
 var (
-	cfgFile string
-	options = &types.Options{}
+	cfgFile     string
+	profileFile string
+	options     = &types.Options{}
 )
 
 func main() {
+	// Commands for a running scan are sent with the ctl subcommand
+	if len(os.Args) > 1 && os.Args[1] == "ctl" {
+		if err := runner.RunControl(os.Args[2:]); err != nil {
+			gologger.Fatal().Msgf("Could not send control command: %s\n", err)
+		}
+		return
+	}
 	readConfig()
 
+	if options.TemplateSchema {
+		if err := runner.WriteTemplateSchema(); err != nil {
+			gologger.Fatal().Msgf("Could not write template schema: %s\n", err)
+		}
+		return
+	}
+
 	runner.ParseOptions(options)
 
+	stopProfiling, err := runner.StartProfiling(options)
+	if err != nil {
+		gologger.Fatal().Msgf("Could not start profiling: %s\n", err)
+	}
+	defer stopProfiling()
+
+	if options.NewTemplate != "" {
+		if err := runner.RunNewTemplate(options); err != nil {
+			gologger.Fatal().Msgf("Could not create template: %s\n", err)
+		}
+		return
+	}
+	if options.MigrateTemplates != "" {
+		if err := runner.RunMigrateTemplates(options); err != nil {
+			gologger.Fatal().Msgf("Could not migrate templates: %s\n", err)
+		}
+		return
+	}
+	if options.Test != "" {
+		if err := runner.RunTemplateTests(options); err != nil {
+			gologger.Fatal().Msgf("Could not run template tests: %s\n", err)
+		}
+		return
+	}
+	if options.MockServer != "" {
+		if err := runner.RunMockServer(options); err != nil {
+			gologger.Fatal().Msgf("Could not run mock server: %s\n", err)
+		}
+		return
+	}
+	if options.Server {
+		if err := runner.RunServer(options); err != nil {
+			gologger.Fatal().Msgf("Could not run api server: %s\n", err)
+		}
+		return
+	}
+	if options.Schedule != "" {
+		if err := runner.RunScheduled(options); err != nil {
+			gologger.Fatal().Msgf("Could not run scheduled scans: %s\n", err)
+		}
+		return
+	}
+
 	nucleiRunner, err := runner.New(options)
 	if err != nil {
 		gologger.Fatal().Msgf("Could not create runner: %s\n", err)
 	}
-	nucleiRunner.RunEnumeration()
+	if err := nucleiRunner.RunEnumeration(); err != nil {
+		gologger.Fatal().Msgf("Could not run nuclei: %s\n", err)
+	}
 	nucleiRunner.Close()
 }
 
@@ -36,29 +97,82 @@ func readConfig() {
 	set.SetDescription(`Nuclei is a fast tool for configurable targeted scanning 
 based on templates offering massive extensibility and ease of use.`)
 	set.StringVar(&cfgFile, "config", "", "Nuclei configuration file")
+	set.StringVar(&profileFile, "profile", "", "Scan profile file or name in ~/.config/nuclei/profiles with preset options (flags take precedence)")
 	set.BoolVar(&options.Metrics, "metrics", false, "Expose nuclei metrics on a port")
 	set.IntVar(&options.MetricsPort, "metrics-port", 9092, "Port to expose nuclei metrics on")
+	set.BoolVar(&options.PProf, "pprof", false, "Expose pprof diagnostics on localhost during the scan")
+	set.IntVar(&options.PProfPort, "pprof-port", 8086, "Port to expose pprof diagnostics on")
+	set.StringVar(&options.CPUProfile, "cpuprofile", "", "File to write a cpu profile of the scan to")
+	set.StringVar(&options.MemProfile, "memprofile", "", "File to write a memory profile to at the end of the scan")
 	set.StringVarP(&options.Target, "target", "u", "", "URL to scan with nuclei")
 	set.StringSliceVarP(&options.Templates, "templates", "t", []string{}, "Templates to run, supports single and multiple templates using directory.")
 	set.StringSliceVarP(&options.Workflows, "workflows", "w", []string{}, "Workflows to run for nuclei")
 	set.StringSliceVarP(&options.ExcludedTemplates, "exclude", "et", []string{}, "Templates to exclude, supports single and multiple templates using directory.")
 	set.StringSliceVarP(&options.Severity, "severity", "impact", []string{}, "Templates to run based on severity, supports single and multiple severity.")
+	set.StringSliceVarP(&options.TemplateConditions, "template-condition", "tc", []string{}, "Templates to run based on conditions on their info (ex. confidence>=medium, metadata.verified=true)")
 	set.StringVarP(&options.Targets, "list", "l", "", "List of URLs to run templates on")
+	set.StringVarP(&options.TargetRoutes, "target-routes", "tr", "", "JSON lines file of targets with tags/templates to run on each (eg. {\"host\":\"https://example.com\",\"tags\":[\"wordpress\"]})")
+	set.BoolVar(&options.Uncover, "uncover", false, "Load targets from internet wide search engines using the uncover query")
+	set.StringVarP(&options.UncoverQuery, "uncover-query", "uq", "", "Search query to discover targets with (eg. 'ssl:\"example.com\"')")
+	set.StringSliceVarP(&options.UncoverEngine, "uncover-engine", "ue", []string{"shodan"}, "Search engines to run the uncover query on (shodan, censys, fofa)")
+	set.IntVarP(&options.UncoverLimit, "uncover-limit", "ul", 100, "Maximum number of targets to load per uncover engine")
+	set.StringVar(&options.UncoverConfig, "uncover-config", "", "File containing the API keys of the uncover engines (default ~/.config/nuclei/uncover-config.yaml)")
+	set.StringVar(&options.NmapInput, "nmap-input", "", "Nmap/Masscan XML output file of services to scan, running service templates only on matching ports")
+	set.StringVar(&options.APISpec, "api-spec", "", "OpenAPI 3 specification or Postman collection file of requests to scan")
+	set.StringSliceVar(&options.APIVariables, "api-vars", []string{}, "Variables (key=value) for the api specification requests")
+	set.StringVar(&options.TrafficInput, "traffic-input", "", "Burp Suite XML export or HAR file of proxy traffic of requests to scan")
+	set.StringVarP(&options.SecretsFile, "secret-file", "sf", "", "Secrets file with the static credentials or login templates for authenticated http scans")
+	set.BoolVar(&options.CSRF, "csrf", false, "Inject the csrf tokens found in previous responses of a host in its POST/PUT/PATCH/DELETE requests")
+	set.StringSliceVar(&options.CSRFPatterns, "csrf-pattern", []string{}, "Additional regex with a value (and optional name) group extracting csrf tokens from responses")
+	set.BoolVar(&options.GraphQL, "graphql", false, "Run introspection on the http targets and scan the graphql operations found with fuzzing templates")
+	set.BoolVar(&options.GraphQLMutations, "graphql-mutations", false, "Also scan the graphql mutations found by introspection (changes the state of the targets)")
+	set.BoolVar(&options.Crawl, "crawl", false, "Crawl the http targets and scan the discovered urls with fuzzing templates")
+	set.IntVarP(&options.CrawlDepth, "crawl-depth", "cd", 3, "Maximum depth of links to follow when crawling")
+	set.StringVar(&options.CrawlScope, "crawl-scope", "host", "Scope of the crawled urls (host, subdomain)")
+	set.IntVar(&options.CrawlLimit, "crawl-limit", 1000, "Maximum number of urls to discover per crawled target")
+	set.StringVarP(&options.Ports, "ports", "p", "", "Ports to scan each target on, supports ranges (eg. 80,443,8080-8090)")
+	set.BoolVar(&options.RandomAgent, "random-agent", false, "Use a random User-Agent for each http and headless request (the first agent of the list is used otherwise)")
+	set.StringVarP(&options.UserAgentFile, "user-agent-file", "uaf", "", "File with a list of User-Agents to use for http requests")
+	set.BoolVar(&options.ProfileTemplates, "profile-templates", false, "Print a report of the time, requests and errors of each template at the end of the scan")
+	set.BoolVar(&options.Shuffle, "shuffle", false, "Randomize the order of targets and templates")
+	set.IntVar(&options.Jitter, "jitter", 0, "Maximum random delay in milliseconds to add before each request")
+	set.IntVar(&options.Seed, "seed", 0, "Seed for the random ordering and jitter to reproduce a scan")
+	set.StringVar(&options.Shard, "shard", "", "Scan only the index/total shard of the targets for splitting across machines (eg. 3/10)")
+	set.StringVar(&options.PathMode, "path-mode", "prepend", "Mode of combining target path with template paths (prepend, replace, merge)")
 	set.StringVarP(&options.Output, "output", "o", "", "File to write output to (optional)")
+	set.BoolVarP(&options.StoreResponse, "store-resp", "sresp", false, "Store the request and response of every match in a per host directory")
+	set.BoolVar(&options.StoreResponseAll, "store-resp-all", false, "Store the requests and responses of all the traffic in a per host directory")
+	set.StringVarP(&options.StoreResponseDir, "store-resp-dir", "srd", "output", "Directory to store the responses in")
+	set.BoolVar(&options.Screenshot, "screenshot", false, "Store a screenshot of the matched urls of http templates using the headless browser")
+	set.StringVar(&options.ScreenshotSeverity, "screenshot-severity", "info", "Minimum severity of http matches to screenshot (info, low, medium, high, critical)")
 	set.StringVar(&options.ProxyURL, "proxy-url", "", "URL of the proxy server")
 	set.StringVar(&options.ProxySocksURL, "proxy-socks-url", "", "URL of the proxy socks server")
-	set.BoolVar(&options.Silent, "silent", false, "Show only results in output")
+	set.StringVar(&options.LogFile, "log-file", "", "File to write the log messages to, without colors")
+	set.StringVar(&options.LogLevel, "log-level", "", "Maximum level of the log messages (fatal, silent, error, info, warning, debug, verbose)")
+	set.BoolVar(&options.Silent, "silent", false, "Show only results in output, one per line on stdout")
 	set.BoolVar(&options.Version, "version", false, "Show version of nuclei")
 	set.BoolVarP(&options.Verbose, "verbose", "v", false, "Show verbose output")
 	set.BoolVarP(&options.NoColor, "no-color", "nc", false, "Disable colors in output")
 	set.IntVar(&options.Timeout, "timeout", 5, "Time to wait in seconds before timeout")
+	set.StringVar(&options.ScanTimeout, "scan-timeout", "", "Maximum duration of the scan after which the remaining work is skipped (eg. 4h)")
+	set.StringVar(&options.HostTimeout, "host-timeout", "", "Maximum duration spent on each host after which the remaining work for it is skipped (eg. 10m)")
 	set.IntVar(&options.Retries, "retries", 1, "Number of times to retry a failed request")
+	set.IntVar(&options.Verify, "verify", 0, "Number of times to re-execute matched templates, only reporting the findings that reproduce")
+	set.IntVar(&options.RetryBackoff, "retry-backoff", 500, "Base delay in milliseconds for exponential backoff between retries")
+	set.IntVar(&options.RetryMaxBackoff, "retry-max-backoff", 10000, "Maximum delay in milliseconds between retries")
 	set.StringSliceVarP(&options.CustomHeaders, "header", "H", []string{}, "Custom Header.")
+	set.IntVar(&options.MaxConnsPerHost, "max-conns-per-host", 0, "Maximum number of http connections to each host (0 uses the defaults)")
+	set.IntVar(&options.IdleConnTimeout, "idle-conn-timeout", 90, "Seconds an idle http connection is kept open for reuse")
+	set.BoolVar(&options.DisableKeepAlive, "disable-keep-alive", false, "Send each http request on a new connection")
+	set.BoolVarP(&options.EnvironmentVariables, "env-vars", "ev", false, "Expand ${NAME} environment variables in the variables block of the templates")
+	set.BoolVar(&options.ReuseCookies, "reuse-cookies", false, "Share the cookies of each host between the http requests of all the templates")
 	set.BoolVar(&options.Debug, "debug", false, "Debugging request and responses")
 	set.BoolVar(&options.DebugRequests, "debug-req", false, "Debugging request")
 	set.BoolVar(&options.DebugResponse, "debug-resp", false, "Debugging response")
 	set.BoolVarP(&options.UpdateTemplates, "update-templates", "ut", false, "Download / updates nuclei community templates")
 	set.StringVar(&options.TraceLogFile, "trace-log", "", "File to write sent requests trace log")
+	set.StringVar(&options.ScanMetadataFile, "scan-metadata", "", "File to write the scan metadata (versions, redacted arguments, templates, targets and timing) as json")
+	set.StringVar(&options.AuditLog, "audit-log", "", "JSONL file recording every outbound request with timestamp, template, target and byte counts")
 	set.StringVarP(&options.TemplatesDirectory, "update-directory", "ud", templatesDirectory, "Directory storing nuclei-templates")
 	set.BoolVar(&options.JSON, "json", false, "Write json output to files")
 	set.BoolVarP(&options.JSONRequests, "include-rr", "irr", false, "Write requests/responses for matches in JSON output")
@@ -71,32 +185,103 @@ based on templates offering massive extensibility and ease of use.`)
 	set.BoolVar(&options.Project, "project", false, "Use a project folder to avoid sending same request multiple times")
 	set.StringVar(&options.ProjectPath, "project-path", "", "Use a user defined project folder, temporary folder is used if not specified but enabled")
 	set.BoolVarP(&options.NoMeta, "no-meta", "nm", false, "Don't display metadata for the matches")
+	set.BoolVar(&options.Control, "control", false, "Accept pause, resume, set-rate, exclude-host and reload-templates commands from 'nuclei ctl' on a control socket")
+	set.StringVar(&options.ControlSocket, "control-socket", control.DefaultSocket, "Path of the control socket")
+	set.BoolVar(&options.WatchTemplates, "watch-templates", false, "Watch the template paths and run templates added during the scan on all the targets")
+	set.BoolVar(&options.Dashboard, "dashboard", false, "Show a live dashboard of the findings, running templates and slowest hosts")
+	set.BoolVar(&options.Table, "table", false, "Display the findings as aligned severity-colored columns")
+	set.IntVar(&options.TableWidth, "table-width", 40, "Width the columns of the table output are truncated at")
+	set.StringVar(&options.Lang, "lang", "", "Language of the template info fields to display and export (ex. zh for name_zh, description_zh)")
 	set.BoolVarP(&options.TemplatesVersion, "templates-version", "tv", false, "Shows the installed nuclei-templates version")
+	set.StringSliceVar(&options.FileAllowlist, "file-allowlist", []string{}, "Directories the file templates are allowed to read (default the input paths except sensitive system paths)")
+	set.BoolVar(&options.AllowLocalFileAccess, "allow-local-file-access", false, "Allow templates to load payloads from files outside the templates directory")
 	set.BoolVar(&options.OfflineHTTP, "passive", false, "Enable Passive HTTP response processing mode")
 	set.StringVarP(&options.ReportingConfig, "report-config", "rc", "", "Nuclei Reporting Module configuration file")
 	set.StringVarP(&options.ReportingDB, "report-db", "rdb", "", "Local Nuclei Reporting Database (Always use this to persistent report data)")
+	set.BoolVar(&options.GroupIssues, "group-issues", false, "Report a single issue per template listing the affected hosts to trackers and exporters")
+	set.BoolVar(&options.Enrich, "enrich", false, "Annotate the findings of CVE templates with their EPSS score and CISA KEV membership")
+	set.StringVar(&options.EnrichmentFile, "enrichment-file", "", "File of the EPSS/KEV enrichment dataset (default ~/.config/nuclei/enrichment.json)")
+	set.BoolVar(&options.UpdateEnrichment, "update-enrichment", false, "Download the latest EPSS/KEV enrichment dataset")
+	set.StringVar(&options.TriageFile, "triage-file", "", "File of severity overrides and suppressions (with expiry and reason) applied to the findings")
 	set.StringSliceVar(&options.Tags, "tags", []string{}, "Tags to execute templates for")
 	set.StringSliceVarP(&options.ExcludeTags, "exclude-tags", "etags", []string{}, "Exclude templates with the provided tags")
-	set.StringVarP(&options.ResolversFile, "resolvers", "r", "", "File containing resolver list for nuclei")
+	set.StringSliceVarP(&options.Protocols, "protocol-type", "pt", []string{}, "Protocols the templates and features are allowed to use (http,dns,file,network,mail,ssh,service,headless)")
+	set.StringSliceVarP(&options.ExcludeProtocols, "exclude-protocol-type", "exclude-pt", []string{}, "Protocols the templates and features are not allowed to use")
+	set.StringVarP(&options.ResolversFile, "resolvers", "r", "", "File containing resolver list for nuclei (supports https:// DNS-over-HTTPS resolvers)")
+	set.StringSliceVarP(&options.IPVersion, "ip-version", "iv", []string{}, "IP versions to scan hostnames with in order of preference (4,6)")
+	set.StringVar(&options.HostsFile, "hosts-file", "", "File containing static host mappings in /etc/hosts format")
+	set.BoolVar(&options.Takeover, "takeover", false, "Check the targets for subdomain takeover using cname chains and service fingerprints")
+	set.StringVar(&options.TakeoverFingerprints, "takeover-fingerprints", "", "Yaml file of subdomain takeover fingerprints replacing the default ones")
+	set.StringVar(&options.DNSDataset, "dns-dataset", "", "Zone file or passive DNS dataset (.jsonl) answering the dns templates without live queries")
+	set.StringVar(&options.Interface, "interface", "", "Network interface to send the requests from (eg. eth1)")
+	set.StringVar(&options.SourceIP, "source-ip", "", "Local ip address to send the requests from")
 	set.BoolVar(&options.Headless, "headless", false, "Enable headless browser based templates support")
 	set.BoolVar(&options.ShowBrowser, "show-browser", false, "Show the browser on the screen")
+	set.StringVar(&options.HeadlessSession, "headless-session", "", "JSON file of the cookies and local storage items of an origin to load in headless pages")
+	set.BoolVar(&options.HeadlessPersistSession, "headless-persist-session", false, "Persist the cookies and local storage of headless pages per host across templates")
+	set.IntVar(&options.HeadlessMaxTabs, "headless-max-tabs", 10, "Maximum number of concurrently open headless browser tabs")
 	set.IntVarP(&options.StatsInterval, "stats-interval", "si", 5, "Number of seconds between each stats line")
 	set.BoolVar(&options.SystemResolvers, "system-resolvers", false, "Use system dns resolving as error fallback")
 	set.IntVar(&options.PageTimeout, "page-timeout", 20, "Seconds to wait for each page in headless")
 	set.BoolVarP(&options.NewTemplates, "new-templates", "nt", false, "Only run newly added templates")
+	set.StringVar(&options.Test, "test", "", "Run the <template>_test.yaml fixtures of the templates of a directory offline against their canned responses")
+	set.StringVar(&options.MockServer, "mock-server", "", "Serve the canned responses (status, headers and body per path) of a yaml fixture file for template development")
+	set.StringVar(&options.MockServerAddress, "mock-server-address", "127.0.0.1:8080", "Address to listen on in mock server mode")
+	set.StringVar(&options.MigrateTemplates, "migrate-templates", "", "Rewrite the deprecated fields of the templates of a directory to the current spec-version in place")
+	set.BoolVar(&options.TemplateSchema, "template-schema", false, "Write the JSON Schema of the template format to stdout for editor autocompletion and validation")
+	set.IntVar(&options.ConfirmThreshold, "confirm-threshold", 1000000, "Number of planned requests above which the scan requires -yes to run (0 disables)")
+	set.BoolVar(&options.Yes, "yes", false, "Run scans planning more requests than -confirm-threshold")
+	set.BoolVar(&options.TemplateStats, "template-stats", false, "Print the counts of the selected templates by protocol, severity, tag and author with the estimated requests per target and exit")
+	set.BoolVar(&options.Lint, "lint", false, "Check the templates for common pitfalls and exit, failing on errors (issues are written as json lines with -json)")
+	set.StringVar(&options.NewTemplate, "new-template", "", "Write a skeleton template to the given file after asking for its protocol, matcher, severity and tags")
 	set.StringVarP(&options.DiskExportDirectory, "markdown-export", "me", "", "Directory to export results in markdown format")
 	set.StringVarP(&options.SarifExport, "sarif-export", "se", "", "File to export results in sarif format")
 	set.BoolVar(&options.NoInteractsh, "no-interactsh", false, "Do not use interactsh server for blind interaction polling")
+	set.BoolVar(&options.NoHTTPXFallback, "no-httpx-fallback", false, "Do not retry http requests over https (and vice versa) on protocol errors")
 	set.StringVar(&options.InteractshURL, "interactsh-url", "https://interact.sh", "Self Hosted Interactsh Server URL")
 	set.IntVar(&options.InteractionsCacheSize, "interactions-cache-size", 5000, "Number of requests to keep in interactions cache")
 	set.IntVar(&options.InteractionsEviction, "interactions-eviction", 60, "Number of seconds to wait before evicting requests from cache")
 	set.IntVar(&options.InteractionsPollDuration, "interactions-poll-duration", 5, "Number of seconds before each interaction poll request")
 	set.IntVar(&options.InteractionsColldownPeriod, "interactions-cooldown-period", 5, "Extra time for interaction polling before exiting")
+	set.BoolVar(&options.WafDetection, "waf-detect", false, "Detect WAF/rate-limit responses per host, slow down and skip intrusive templates")
+	set.BoolVar(&options.ServiceDetection, "service-detect", false, "Fingerprint services on host:port inputs and run network templates only on matching services")
+	set.IntVar(&options.WafThreshold, "waf-threshold", 5, "Number of WAF/rate-limit responses after which intrusive templates are skipped for a host")
+	set.IntVarP(&options.ResponseReadSize, "response-size-read", "rsr", 10*1024*1024, "Maximum response body size to read in bytes")
+	set.IntVarP(&options.ResponseSaveSize, "response-size-save", "rss", 1024*1024, "Maximum response body size to save in output in bytes")
+	set.IntVarP(&options.OutputBufferSize, "output-buffer-size", "obs", 0, "Memory in MB to buffer findings in before spilling to disk, writing them asynchronously (0 to disable)")
+	set.StringVar(&options.OTLPEndpoint, "otlp-endpoint", "", "OTLP/HTTP collector url to export execution traces to (eg. http://localhost:4318)")
+	set.StringVar(&options.StatsDAddress, "statsd-address", "", "StatsD server address to send execution timings to")
+	set.StringVar(&options.Coordinator, "coordinator", "", "Run as distributed scan coordinator listening for workers on address (eg. 0.0.0.0:8822)")
+	set.StringVar(&options.Worker, "worker", "", "Run as distributed scan worker pulling work from coordinator url")
+	set.StringVar(&options.DistributedToken, "distributed-token", "", "Token to authenticate workers with the coordinator (required on non-loopback addresses)")
+	set.IntVar(&options.UnitTargets, "unit-targets", 100, "Number of targets per distributed work unit")
+	set.IntVar(&options.UnitTemplates, "unit-templates", 50, "Number of templates per distributed work unit")
+	set.BoolVar(&options.Server, "server", false, "Run as a REST api server for submitting and managing scans")
+	set.StringVar(&options.ServerAddress, "server-address", "127.0.0.1:8822", "Address to listen on for api requests in server mode")
+	set.StringVar(&options.ServerToken, "server-token", "", "Token to authenticate api requests in server mode")
+	set.StringVar(&options.GRPCAddress, "grpc-address", "", "Address to listen on for grpc scan calls in server mode (eg. 127.0.0.1:8823)")
+	set.StringVar(&options.Schedule, "schedule", "", "Cron expression to rerun the scan on, only reporting new findings (eg. \"0 2 * * *\")")
+	set.StringVar(&options.ScheduleState, "schedule-state", "", "Directory storing findings seen by scheduled scans (default ~/.config/nuclei/schedule)")
 	_ = set.Parse()
 
+	// The values are layered as default < config < profile < command line
+	var files []string
+	if defaultConfig := runner.DefaultConfigFile(); defaultConfig != "" {
+		if _, err := os.Stat(defaultConfig); err == nil {
+			files = append(files, defaultConfig)
+		}
+	}
 	if cfgFile != "" {
-		if err := set.MergeConfigFile(cfgFile); err != nil {
-			gologger.Fatal().Msgf("Could not read config: %s\n", err)
+		files = append(files, cfgFile)
+	}
+	if profileFile != "" {
+		profilePath, err := runner.ResolveProfile(profileFile)
+		if err != nil {
+			gologger.Fatal().Msgf("Could not find profile: %s\n", err)
 		}
+		files = append(files, profilePath)
+	}
+	if err := runner.MergeConfigFiles(files...); err != nil {
+		gologger.Fatal().Msgf("Could not read config: %s\n", err)
 	}
 }
@@ -0,0 +1,64 @@
+package runner
+
+import (
+	"strings"
+
+	"github.com/projectdiscovery/nuclei/v2/pkg/input/apispec"
+	"github.com/projectdiscovery/nuclei/v2/pkg/input/traffic"
+)
+
+// loadAPISpecInput adds the URLs of the operations of an OpenAPI specification
+// or Postman collection to the input, storing their requests to be used by
+// the fuzzing templates. The number of duplicate targets is returned.
+func (r *Runner) loadAPISpecInput(file string) (int, error) {
+	variables := make(map[string]string)
+	for _, variable := range r.options.APIVariables {
+		parts := strings.SplitN(variable, "=", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		variables[strings.TrimSpace(parts[0])] = strings.TrimSpace(parts[1])
+	}
+
+	requests, err := apispec.Load(file, variables)
+	if err != nil {
+		return 0, err
+	}
+	return r.addInputRequests(requests), nil
+}
+
+// loadTrafficInput adds the URLs of the requests of a Burp Suite XML export
+// or HAR file of proxy traffic to the input, storing their requests to be
+// used by the fuzzing templates. The number of duplicate targets is returned.
+func (r *Runner) loadTrafficInput(file string) (int, error) {
+	requests, err := traffic.Load(file)
+	if err != nil {
+		return 0, err
+	}
+	return r.addInputRequests(requests), nil
+}
+
+// addInputRequests stores the requests for their URLs and adds the URLs to
+// the input, returning the number of duplicate targets.
+func (r *Runner) addInputRequests(requests []*apispec.Request) int {
+	if r.inputRequests == nil {
+		r.inputRequests = apispec.NewStore()
+	}
+
+	dupeCount := 0
+	for _, request := range requests {
+		r.inputRequests.Add(request)
+		// Requests with different methods on the same URL are a single target
+		if len(r.inputRequests.Get(request.URL)) > 1 {
+			continue
+		}
+		if _, ok := r.hostMap.Get(request.URL); ok {
+			dupeCount++
+			continue
+		}
+		r.inputCount++
+		// nolint:errcheck // ignoring error
+		r.hostMap.Set(request.URL, nil)
+	}
+	return dupeCount
+}
@@ -0,0 +1,72 @@
+package runner
+
+import (
+	"github.com/pkg/errors"
+	"github.com/projectdiscovery/nuclei/v2/pkg/output"
+	"github.com/projectdiscovery/nuclei/v2/pkg/protocols"
+	"github.com/projectdiscovery/nuclei/v2/pkg/protocols/common/variables"
+	"github.com/projectdiscovery/nuclei/v2/pkg/templates"
+	"github.com/projectdiscovery/nuclei/v2/pkg/types"
+)
+
+// loginTemplate runs the login template of a dynamic secret on the input
+// and returns the values extracted by the template.
+func (r *Runner) loginTemplate(template, input string, values map[string]interface{}) (map[string]interface{}, error) {
+	path, err := r.catalog.ResolvePath(template, "")
+	if err != nil {
+		return nil, errors.Wrap(err, "could not resolve login template")
+	}
+
+	// The tag filters of the scan don't apply to login templates
+	options := *r.options
+	options.Tags = nil
+	options.ExcludeTags = nil
+
+	loginVariables := make(variables.Variable, 0, len(values))
+	for k, v := range values {
+		loginVariables = append(loginVariables, variables.Item{Name: k, Value: types.ToString(v)})
+	}
+	executerOpts := protocols.ExecuterOptions{
+		Output:       r.output,
+		Options:      &options,
+		Progress:     r.progress,
+		Catalog:      r.catalog,
+		IssuesClient: r.issuesClient,
+		RateLimiter:  r.ratelimiter,
+		Interactsh:   r.interactsh,
+		ProjectFile:  r.projectFile,
+		Browser:      r.browser,
+		AuditLog:     r.auditLog,
+		Variables:    loginVariables,
+		Context:      r.ctx,
+	}
+	parsed, err := templates.Parse(path, executerOpts)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not parse login template")
+	}
+	if parsed == nil || parsed.Executer == nil {
+		return nil, errors.New("login template has no requests")
+	}
+
+	extracted := make(map[string]interface{})
+	err = parsed.Executer.ExecuteWithResults(input, func(event *output.InternalWrappedEvent) {
+		if event.OperatorsResult == nil {
+			return
+		}
+		for k, v := range event.OperatorsResult.DynamicValues {
+			extracted[k] = v
+		}
+		for k, v := range event.OperatorsResult.Extracts {
+			if _, ok := extracted[k]; !ok && len(v) > 0 {
+				extracted[k] = v[0]
+			}
+		}
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "could not execute login template")
+	}
+	if len(extracted) == 0 {
+		return nil, errors.New("no values extracted by login template")
+	}
+	return extracted, nil
+}
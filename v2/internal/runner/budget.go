@@ -0,0 +1,134 @@
+package runner
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/projectdiscovery/gologger"
+	"github.com/projectdiscovery/nuclei/v2/pkg/templates"
+)
+
+// parseBudget parses a time budget like 4h or 10m. Empty values disable the budget.
+func parseBudget(value string) (time.Duration, error) {
+	if value == "" {
+		return 0, nil
+	}
+	duration, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, errors.Wrapf(err, "invalid time budget %s", value)
+	}
+	if duration < 0 {
+		return 0, errors.Errorf("invalid time budget %s", value)
+	}
+	return duration, nil
+}
+
+// hostBudget tracks the time spent on each host since its first template
+type hostBudget struct {
+	timeout time.Duration
+
+	mutex    sync.Mutex
+	started  map[string]time.Time
+	exceeded map[string]int
+}
+
+// newHostBudget creates a per host time budget
+func newHostBudget(timeout time.Duration) *hostBudget {
+	return &hostBudget{timeout: timeout, started: make(map[string]time.Time), exceeded: make(map[string]int)}
+}
+
+// exhausted returns true if the time budget of the host is exhausted,
+// starting the clock for the host on the first call.
+func (b *hostBudget) exhausted(host string) bool {
+	if b == nil {
+		return false
+	}
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	started, ok := b.started[host]
+	if !ok {
+		b.started[host] = time.Now()
+		return false
+	}
+	if time.Since(started) < b.timeout {
+		return false
+	}
+	b.exceeded[host]++
+	return true
+}
+
+// exceededHosts returns the hosts that exhausted their budget sorted by name
+func (b *hostBudget) exceededHosts() []string {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	hosts := make([]string, 0, len(b.exceeded))
+	for host := range b.exceeded {
+		hosts = append(hosts, host)
+	}
+	sort.Strings(hosts)
+	return hosts
+}
+
+// skipped returns the number of template executions skipped for a host
+func (b *hostBudget) skipped(host string) int {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	return b.exceeded[host]
+}
+
+// skipForBudget returns true if the scan or host time budget is exhausted
+func (r *Runner) skipForBudget(template *templates.Template, URL string) bool {
+	if !r.scanTimedOut.Load() && !r.hostBudget.exhausted(URL) {
+		return false
+	}
+	r.budgetSkipped.Inc()
+	r.progress.IncrementSkippedBy(int64(template.TotalRequests))
+	return true
+}
+
+// startScanBudget sets the scan timeout as the deadline of the context of
+// the executers compiled afterwards, skipping the remaining work and
+// cancelling the requests being executed once it is reached. The returned
+// function restores the context of the runner.
+func (r *Runner) startScanBudget() func() {
+	if r.scanTimeout <= 0 {
+		return func() {}
+	}
+	parent := r.ctx
+	ctx, cancel := context.WithTimeout(parent, r.scanTimeout)
+	r.ctx = ctx
+	go func() {
+		<-ctx.Done()
+		if ctx.Err() == context.DeadlineExceeded {
+			gologger.Warning().Msgf("Scan timeout of %s reached, skipping remaining work", r.scanTimeout)
+			r.scanTimedOut.Store(true)
+		}
+	}()
+	return func() {
+		cancel()
+		r.ctx = parent
+	}
+}
+
+// reportBudget reports the work skipped because of the time budgets
+func (r *Runner) reportBudget() {
+	skipped := r.budgetSkipped.Load()
+	if skipped == 0 {
+		return
+	}
+	if r.scanTimedOut.Load() {
+		gologger.Warning().Msgf("Scan timeout reached, %d template executions were skipped", skipped)
+	}
+	if r.hostBudget == nil {
+		return
+	}
+	for _, host := range r.hostBudget.exceededHosts() {
+		gologger.Warning().Msgf("Host timeout reached for %s, %d template executions were skipped", host, r.hostBudget.skipped(host))
+	}
+}
@@ -0,0 +1,58 @@
+package runner
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/atomic"
+)
+
+func TestParseBudget(t *testing.T) {
+	duration, err := parseBudget("4h")
+	require.Nil(t, err, "could not parse budget")
+	require.Equal(t, 4*time.Hour, duration, "could not get correct budget")
+
+	duration, err = parseBudget("")
+	require.Nil(t, err, "could not parse empty budget")
+	require.Equal(t, time.Duration(0), duration, "could get budget for empty value")
+
+	_, err = parseBudget("10 minutes")
+	require.NotNil(t, err, "could parse invalid budget")
+}
+
+func TestHostBudget(t *testing.T) {
+	budget := newHostBudget(50 * time.Millisecond)
+	require.False(t, budget.exhausted("a.example.com"), "could exhaust budget on first template")
+	require.False(t, budget.exhausted("a.example.com"), "could exhaust budget before timeout")
+
+	time.Sleep(60 * time.Millisecond)
+	require.False(t, budget.exhausted("b.example.com"), "could exhaust budget of new host")
+	require.True(t, budget.exhausted("a.example.com"), "could not exhaust budget after timeout")
+	require.Equal(t, []string{"a.example.com"}, budget.exceededHosts(), "could not get exceeded hosts")
+	require.Equal(t, 1, budget.skipped("a.example.com"), "could not get skipped executions of host")
+	require.Equal(t, 0, budget.skipped("b.example.com"), "could get skipped executions of host within budget")
+
+	var nilBudget *hostBudget
+	require.False(t, nilBudget.exhausted("a.example.com"), "could exhaust nil budget")
+}
+
+func TestScanBudget(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	r := &Runner{scanTimeout: 10 * time.Millisecond, scanTimedOut: &atomic.Bool{}, ctx: ctx, cancel: cancel}
+	stop := r.startScanBudget()
+	_, ok := r.ctx.Deadline()
+	require.True(t, ok, "could not set scan deadline on executer context")
+
+	select {
+	case <-r.ctx.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("could not cancel running requests after scan timeout")
+	}
+	require.Eventually(t, r.scanTimedOut.Load, 5*time.Second, 10*time.Millisecond, "could not skip remaining work after scan timeout")
+
+	stop()
+	require.Equal(t, ctx, r.ctx, "could not restore runner context")
+	require.Nil(t, r.ctx.Err(), "could cancel runner context after scan")
+}
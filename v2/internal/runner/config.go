@@ -1,13 +1,21 @@
 package runner
 
 import (
+	"flag"
+	"fmt"
+	"io/ioutil"
 	"os"
 	"path"
+	"path/filepath"
 	"regexp"
+	"strings"
 	"time"
 
 	jsoniter "github.com/json-iterator/go"
+	"github.com/pkg/errors"
+	"github.com/projectdiscovery/goflags"
 	"github.com/projectdiscovery/gologger"
+	"github.com/projectdiscovery/nuclei/v2/pkg/types"
 	"gopkg.in/yaml.v2"
 )
 
@@ -125,3 +133,93 @@ func (r *Runner) getIgnoreFilePath() string {
 	cwdIgnoreFilePath := path.Join(cwd, nucleiIgnoreFile)
 	return cwdIgnoreFilePath
 }
+
+// profilesDirectory is the directory in nuclei config directory storing profiles
+const profilesDirectory = "profiles"
+
+// ResolveProfile returns the path of a scan profile file. Profiles not found
+// as a file are looked up by name in the nuclei profiles config directory.
+func ResolveProfile(profile string) (string, error) {
+	if _, err := os.Stat(profile); err == nil {
+		return profile, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	name := profile
+	if path.Ext(name) == "" {
+		name += ".yaml"
+	}
+	profilePath := path.Join(home, ".config", "nuclei", profilesDirectory, name)
+	if _, err := os.Stat(profilePath); err != nil {
+		return "", fmt.Errorf("profile %s not found", profile)
+	}
+	return profilePath, nil
+}
+
+// DefaultConfigFile returns the path of the config file read by goflags
+// from the nuclei config directory.
+func DefaultConfigFile() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	appName := filepath.Base(os.Args[0])
+	appName = strings.TrimSuffix(appName, filepath.Ext(appName))
+	return filepath.Join(home, ".config", appName, "config.yaml")
+}
+
+// MergeConfigFiles merges the values of the config files with the environment
+// variables expanded into the flags not set on the command line. The values
+// of the later files take precedence over the earlier ones. The files are
+// expanded in memory so the values of the variables never hit the disk.
+func MergeConfigFiles(files ...string) error {
+	return mergeConfigFiles(flag.CommandLine, files)
+}
+
+func mergeConfigFiles(set *flag.FlagSet, files []string) error {
+	values := make(map[string]interface{})
+	for _, file := range files {
+		data, err := ioutil.ReadFile(file)
+		if err != nil {
+			return err
+		}
+		fileValues := make(map[string]interface{})
+		if err := yaml.Unmarshal(types.ExpandEnv(data), &fileValues); err != nil {
+			return errors.Wrapf(err, "could not parse %s", file)
+		}
+		for k, v := range fileValues {
+			values[k] = v
+		}
+	}
+
+	// Only the flags actually set on the command line are visited
+	cli := make(map[string]struct{})
+	set.Visit(func(f *flag.Flag) {
+		cli[f.Name] = struct{}{}
+	})
+	set.VisitAll(func(f *flag.Flag) {
+		item, ok := values[f.Name]
+		if !ok {
+			return
+		}
+		if _, ok := cli[f.Name]; ok {
+			return
+		}
+		// The slices append the values set, the values merged by goflags
+		// from the default config file are replaced.
+		if slice, ok := f.Value.(*goflags.StringSlice); ok {
+			*slice = nil
+		}
+		switch value := item.(type) {
+		case []interface{}:
+			for _, v := range value {
+				_ = f.Value.Set(types.ToString(v))
+			}
+		default:
+			_ = f.Value.Set(types.ToString(value))
+		}
+	})
+	return nil
+}
@@ -0,0 +1,66 @@
+package runner
+
+import (
+	"flag"
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/projectdiscovery/goflags"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolveProfile(t *testing.T) {
+	home, err := ioutil.TempDir("", "nuclei-home-*")
+	require.Nil(t, err, "could not create home directory")
+	defer os.RemoveAll(home)
+
+	oldHome := os.Getenv("HOME")
+	os.Setenv("HOME", home)
+	defer os.Setenv("HOME", oldHome)
+
+	profiles := path.Join(home, ".config", "nuclei", profilesDirectory)
+	require.Nil(t, os.MkdirAll(profiles, os.ModePerm), "could not create profiles directory")
+	require.Nil(t, ioutil.WriteFile(path.Join(profiles, "recon.yaml"), []byte("tags: tech\n"), 0644), "could not write profile")
+
+	profilePath, err := ResolveProfile("recon")
+	require.Nil(t, err, "could not resolve profile by name")
+	require.Equal(t, path.Join(profiles, "recon.yaml"), profilePath, "could not get profile path")
+
+	filePath := path.Join(home, "custom.yaml")
+	require.Nil(t, ioutil.WriteFile(filePath, []byte("tags: cve\n"), 0644), "could not write profile")
+	profilePath, err = ResolveProfile(filePath)
+	require.Nil(t, err, "could not resolve profile by path")
+	require.Equal(t, filePath, profilePath, "could not get profile file path")
+
+	_, err = ResolveProfile("missing")
+	require.NotNil(t, err, "could resolve missing profile")
+}
+
+func TestMergeConfigFilesLayers(t *testing.T) {
+	directory, err := ioutil.TempDir("", "nuclei-config-*")
+	require.Nil(t, err, "could not create config directory")
+	defer os.RemoveAll(directory)
+
+	config := path.Join(directory, "config.yaml")
+	require.Nil(t, ioutil.WriteFile(config, []byte("rate-limit: 10\ntimeout: 10\nretries: 3\ntags: [cve]\n"), 0644), "could not write config")
+	profile := path.Join(directory, "profile.yaml")
+	require.Nil(t, ioutil.WriteFile(profile, []byte("rate-limit: 50\ntimeout: 20\nretries: 2\ntags: [tech]\n"), 0644), "could not write profile")
+
+	var rateLimit, timeout, retries int
+	var tags goflags.StringSlice
+	set := flag.NewFlagSet("nuclei", flag.ContinueOnError)
+	set.IntVar(&rateLimit, "rate-limit", 150, "")
+	set.IntVar(&timeout, "timeout", 5, "")
+	set.IntVar(&retries, "retries", 1, "")
+	set.Var(&tags, "tags", "")
+	// The retries are set on the command line to their default value
+	require.Nil(t, set.Parse([]string{"-timeout", "30", "-retries", "1"}), "could not parse flags")
+
+	require.Nil(t, mergeConfigFiles(set, []string{config, profile}), "could not merge config files")
+	require.Equal(t, 50, rateLimit, "could not apply profile over config")
+	require.Equal(t, 30, timeout, "could not keep command line value")
+	require.Equal(t, 1, retries, "could not keep command line default value")
+	require.Equal(t, goflags.StringSlice{"tech"}, tags, "could not replace config slice with profile")
+}
@@ -0,0 +1,78 @@
+package runner
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/pkg/errors"
+	"github.com/projectdiscovery/gologger"
+	"github.com/projectdiscovery/nuclei/v2/pkg/control"
+	"github.com/projectdiscovery/nuclei/v2/pkg/protocols/common/wafdetect"
+	"github.com/projectdiscovery/nuclei/v2/pkg/templates"
+)
+
+// controlHandler applies the commands of the control socket to the runner
+type controlHandler struct {
+	runner        *Runner
+	excludedHosts sync.Map
+}
+
+// Pause pauses the requests of the scan
+func (h *controlHandler) Pause() {
+	gologger.Info().Msgf("Scan paused")
+	h.runner.limiter.Pause()
+}
+
+// Resume resumes the requests of the scan
+func (h *controlHandler) Resume() {
+	gologger.Info().Msgf("Scan resumed")
+	h.runner.limiter.Resume()
+}
+
+// SetRate sets the maximum number of requests per second
+func (h *controlHandler) SetRate(rate int) {
+	gologger.Info().Msgf("Rate limit set to %d requests per second", rate)
+	h.runner.limiter.SetRate(rate)
+}
+
+// ExcludeHost skips the remaining work for a host
+func (h *controlHandler) ExcludeHost(host string) {
+	gologger.Info().Msgf("Excluding host %s from the scan", host)
+	h.excludedHosts.Store(wafdetect.HostKey(host), struct{}{})
+}
+
+// ReloadTemplates schedules the templates added since the scan started
+func (h *controlHandler) ReloadTemplates() error {
+	_, err := h.runner.reloadTemplates()
+	return err
+}
+
+// skipForControl returns true if the host of the URL was excluded with the control socket
+func (r *Runner) skipForControl(template *templates.Template, URL string) bool {
+	if r.control == nil {
+		return false
+	}
+	if _, ok := r.control.excludedHosts.Load(wafdetect.HostKey(URL)); !ok {
+		return false
+	}
+	r.progress.IncrementSkippedBy(int64(template.TotalRequests))
+	return true
+}
+
+// RunControl sends a command to the control socket of a running scan
+func RunControl(args []string) error {
+	set := flag.NewFlagSet("ctl", flag.ExitOnError)
+	socket := set.String("socket", control.DefaultSocket, "Control socket of the running scan")
+	set.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: nuclei ctl [-socket path] pause|resume|set-rate <rps>|exclude-host <host>|reload-templates\n")
+		set.PrintDefaults()
+	}
+	_ = set.Parse(args)
+	if set.NArg() == 0 {
+		set.Usage()
+		return errors.New("no command provided")
+	}
+	return control.Send(*socket, set.Args())
+}
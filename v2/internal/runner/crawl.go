@@ -0,0 +1,121 @@
+package runner
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/projectdiscovery/gologger"
+	"github.com/projectdiscovery/nuclei/v2/pkg/input/apispec"
+	"github.com/projectdiscovery/nuclei/v2/pkg/input/crawler"
+	"github.com/projectdiscovery/nuclei/v2/pkg/protocols/common/auditlog"
+	"github.com/projectdiscovery/nuclei/v2/pkg/protocols/http/httpclientpool"
+	"github.com/projectdiscovery/nuclei/v2/pkg/templates"
+	"github.com/remeh/sizedwaitgroup"
+)
+
+// crawlInput crawls the http targets of the input and adds the discovered
+// URLs to it. The crawled URLs are only scanned with fuzzing templates,
+// using the requests of the POST forms found for their URLs.
+func (r *Runner) crawlInput() {
+	// The pooled client honors the proxy and source address of the scan
+	client, err := httpclientpool.Get(r.options, &httpclientpool.Configuration{FollowRedirects: true})
+	if err != nil {
+		gologger.Warning().Msgf("Could not get http client for crawling: %s\n", err)
+		return
+	}
+	c := crawler.New(&crawler.Options{
+		Depth:       r.options.CrawlDepth,
+		Scope:       r.options.CrawlScope,
+		MaxURLs:     r.options.CrawlLimit,
+		Timeout:     time.Duration(r.options.Timeout) * time.Second,
+		Concurrency: r.options.BulkSize,
+		Client: &http.Client{
+			Timeout:       client.HTTPClient.Timeout,
+			CheckRedirect: client.HTTPClient.CheckRedirect,
+			Transport: &headerTransport{
+				headers:     r.options.CustomHeaders,
+				ratelimiter: r.ratelimiter,
+				transport:   auditlog.NewTransport(r.auditLog, "crawler", "http", client.HTTPClient.Transport),
+			},
+		},
+	})
+
+	var seeds []string
+	r.hostMap.Scan(func(k, _ []byte) error {
+		if target := string(k); strings.HasPrefix(target, "http://") || strings.HasPrefix(target, "https://") {
+			seeds = append(seeds, target)
+		}
+		return nil
+	})
+
+	// The seeds are crawled in parallel, each with its pages fetched in parallel
+	results := make([]*crawler.Result, len(seeds))
+	wg := sizedwaitgroup.New(r.options.BulkSize)
+	for i, seed := range seeds {
+		wg.Add()
+		go func(i int, seed string) {
+			defer wg.Done()
+			result, err := c.Crawl(seed)
+			if err != nil {
+				gologger.Warning().Msgf("Could not crawl %s: %s\n", seed, err)
+				return
+			}
+			results[i] = result
+		}(i, seed)
+	}
+	wg.Wait()
+
+	r.crawled = make(map[string]struct{})
+	var forms []*apispec.Request
+	for _, result := range results {
+		if result == nil {
+			continue
+		}
+		for _, crawled := range result.URLs {
+			r.addCrawled(crawled)
+		}
+		forms = append(forms, result.Forms...)
+	}
+	if len(forms) > 0 {
+		if r.inputRequests == nil {
+			r.inputRequests = apispec.NewStore()
+		}
+		for _, form := range forms {
+			r.inputRequests.Add(form)
+			r.addCrawled(form.URL)
+		}
+	}
+	gologger.Info().Msgf("Found %d urls by crawling %d targets", len(r.crawled), len(seeds))
+}
+
+// addCrawled adds a crawled URL to the input if it is not already part of it
+func (r *Runner) addCrawled(crawled string) {
+	if _, ok := r.hostMap.Get(crawled); ok {
+		return
+	}
+	r.crawled[crawled] = struct{}{}
+	r.inputCount++
+	// nolint:errcheck // ignoring error
+	r.hostMap.Set(crawled, nil)
+}
+
+// skipForCrawl returns true if the target was found by crawling and the
+// template does not have any fuzzing rules for it.
+func (r *Runner) skipForCrawl(template *templates.Template, URL string) bool {
+	if _, ok := r.crawled[URL]; !ok || hasFuzzingRules(template) {
+		return false
+	}
+	r.progress.IncrementSkippedBy(int64(template.TotalRequests))
+	return true
+}
+
+// hasFuzzingRules returns true if any of the template http requests fuzz the input
+func hasFuzzingRules(template *templates.Template) bool {
+	for _, request := range template.RequestsHTTP {
+		if len(request.Fuzzing) > 0 {
+			return true
+		}
+	}
+	return false
+}
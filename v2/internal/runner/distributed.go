@@ -0,0 +1,105 @@
+package runner
+
+import (
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/projectdiscovery/gologger"
+	"github.com/projectdiscovery/nuclei/v2/pkg/distributed"
+	"github.com/projectdiscovery/nuclei/v2/pkg/output"
+	"go.uber.org/atomic"
+)
+
+// workerLeaveTimeout is the time given to the workers to learn the scan is
+// finished and leave the coordinator in addition to the interactions cooldown.
+const workerLeaveTimeout = time.Minute
+
+// runCoordinator splits the targets and templates into work units and
+// serves them to workers, writing the results streamed back by them.
+func (r *Runner) runCoordinator(templatePaths, workflowPaths []string) {
+	var targets []string
+	r.hostMap.Scan(func(k, _ []byte) error {
+		targets = append(targets, string(k))
+		return nil
+	})
+	planned := plannedRequests(r.parsedTemplates(templatePaths, workflowPaths), r.inputCount)
+	gologger.Info().Msgf("Planned requests: %d (%d templates, %d workflows, %d targets)", planned, len(templatePaths), len(workflowPaths), r.inputCount)
+	if !r.confirmScan(planned) {
+		return
+	}
+	units := distributed.Split(targets, r.relativeTemplatePaths(templatePaths), r.relativeTemplatePaths(workflowPaths), r.options.UnitTargets, r.options.UnitTemplates)
+
+	results := &atomic.Bool{}
+	coordinator, err := distributed.NewCoordinator(&distributed.CoordinatorOptions{
+		Address: r.options.Coordinator,
+		Token:   r.options.DistributedToken,
+		OnResult: func(event *output.ResultEvent) {
+			// Workers don't report issues, they are only created by the coordinator
+			if r.writeResult(event) {
+				results.Store(true)
+			}
+		},
+	}, units)
+	if err != nil {
+		gologger.Fatal().Msgf("Could not create distributed coordinator: %s\n", err)
+	}
+	gologger.Info().Msgf("Coordinating %d work units (%d targets, %d templates, %d workflows) on %s", len(units), len(targets), len(templatePaths), len(workflowPaths), coordinator.Addr())
+
+	r.progress.Init(r.inputCount, len(templatePaths)+len(workflowPaths), 0)
+	// Wait for the workers to send the interactions received after their last unit
+	coordinator.Wait(time.Duration(r.options.InteractionsColldownPeriod)*time.Second + workerLeaveTimeout)
+	r.finishEnumeration(results.Load())
+}
+
+// runWorker pulls work units from the coordinator executing them until
+// the coordinator reports the scan as finished.
+func (r *Runner) runWorker() {
+	results := false
+
+	r.progress.Init(0, 0, 0)
+	for {
+		unit, err := r.worker.Next()
+		if err != nil {
+			gologger.Error().Msgf("Could not get work unit from coordinator: %s\n", err)
+			break
+		}
+		if unit == nil {
+			break
+		}
+		gologger.Info().Msgf("Executing work unit %s (%d targets)", unit.ID, len(unit.Targets))
+
+		if err := r.setInput(unit.Targets); err != nil {
+			gologger.Error().Msgf("Could not create input for work unit %s: %s\n", unit.ID, err)
+			break
+		}
+		finalTemplates, _, totalRequests := r.loadTemplates(r.catalog.GetTemplatesPath(unit.Templates, false), r.catalog.GetTemplatesPath(unit.Workflows, false))
+		r.progress.AddToTotal(totalRequests)
+
+		if r.executeTemplates(finalTemplates) {
+			results = true
+		}
+		if err := r.worker.Complete(unit); err != nil {
+			gologger.Warning().Msgf("Could not complete work unit %s: %s\n", unit.ID, err)
+		}
+	}
+	// The results of the late interactions are sent to the coordinator
+	// while finishing, so the worker leaves afterwards.
+	r.finishEnumeration(results)
+	if err := r.worker.Leave(); err != nil {
+		gologger.Warning().Msgf("Could not leave coordinator: %s\n", err)
+	}
+}
+
+// relativeTemplatePaths returns the paths relative to the templates directory
+// if possible so that workers can resolve them in their own directory.
+func (r *Runner) relativeTemplatePaths(paths []string) []string {
+	relative := make([]string, 0, len(paths))
+	for _, path := range paths {
+		if rel, err := filepath.Rel(r.options.TemplatesDirectory, path); err == nil && !strings.HasPrefix(rel, "..") {
+			path = rel
+		}
+		relative = append(relative, path)
+	}
+	return relative
+}
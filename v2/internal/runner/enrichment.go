@@ -0,0 +1,30 @@
+package runner
+
+import (
+	"os"
+	"path"
+
+	"github.com/projectdiscovery/gologger"
+	"github.com/projectdiscovery/nuclei/v2/pkg/reporting/enrichment"
+	"github.com/projectdiscovery/nuclei/v2/pkg/types"
+)
+
+// loadEnrichment loads the enrichment dataset, downloading it if it
+// is missing or an update was asked.
+func loadEnrichment(options *types.Options) (*enrichment.Dataset, error) {
+	file := options.EnrichmentFile
+	if file == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return nil, err
+		}
+		file = path.Join(home, ".config", "nuclei", "enrichment.json")
+	}
+	if _, err := os.Stat(file); os.IsNotExist(err) || options.UpdateEnrichment {
+		gologger.Info().Msgf("Downloading EPSS/KEV enrichment dataset to %s", file)
+		if err := enrichment.Download(file); err != nil {
+			return nil, err
+		}
+	}
+	return enrichment.Load(file)
+}
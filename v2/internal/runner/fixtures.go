@@ -0,0 +1,221 @@
+package runner
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync"
+
+	"github.com/logrusorgru/aurora"
+	"github.com/pkg/errors"
+	"github.com/projectdiscovery/gologger"
+	"github.com/projectdiscovery/nuclei/v2/pkg/catalog"
+	"github.com/projectdiscovery/nuclei/v2/pkg/operators"
+	"github.com/projectdiscovery/nuclei/v2/pkg/output"
+	"github.com/projectdiscovery/nuclei/v2/pkg/progress"
+	"github.com/projectdiscovery/nuclei/v2/pkg/protocols"
+	"github.com/projectdiscovery/nuclei/v2/pkg/protocols/headless/engine"
+	"github.com/projectdiscovery/nuclei/v2/pkg/templates"
+	"github.com/projectdiscovery/nuclei/v2/pkg/templates/fixture"
+	"github.com/projectdiscovery/nuclei/v2/pkg/types"
+	"go.uber.org/ratelimit"
+)
+
+// RunTemplateTests runs the test fixtures of the templates of a directory
+// against local servers serving their canned responses. The templates are
+// run offline without interactsh, the proxies and the reporting of a scan.
+func RunTemplateTests(options *types.Options) error {
+	progressTracker, err := progress.NewStatsTicker(0, false, false, 0)
+	if err != nil {
+		return errors.Wrap(err, "could not create progress")
+	}
+	outputWriter, err := output.NewStandardWriter(!options.NoColor, options.NoMeta, options.JSON, "", "", false, 0, false, 0)
+	if err != nil {
+		return errors.Wrap(err, "could not create output writer")
+	}
+	defer outputWriter.Close()
+
+	var browser *engine.Browser
+	if options.Headless {
+		if browser, err = engine.New(options); err != nil {
+			return errors.Wrap(err, "could not create browser")
+		}
+		defer browser.Close()
+	}
+
+	// The events of the templates are recorded by the test being run
+	var run *testRun
+	executerOpts := protocols.ExecuterOptions{
+		Output:      outputWriter,
+		Options:     options,
+		Progress:    progressTracker,
+		Catalog:     catalog.New(options.TemplatesDirectory),
+		RateLimiter: ratelimit.NewUnlimited(),
+		Browser:     browser,
+		EventHook: func(req protocols.Request, event *output.InternalWrappedEvent) {
+			run.record(req, event)
+		},
+	}
+	colorizer := aurora.NewAurora(!options.NoColor)
+
+	var passed, failed int
+	for _, path := range executerOpts.Catalog.GetTemplatesPath([]string{options.Test}, false) {
+		suiteFile := fixture.SuiteFile(path)
+		if _, err := os.Stat(suiteFile); err != nil {
+			gologger.Verbose().Msgf("No test fixtures for template %s", path)
+			continue
+		}
+		suite, err := fixture.LoadSuite(suiteFile)
+		if err != nil {
+			gologger.Error().Msgf("Could not load test fixtures %s: %s\n", suiteFile, err)
+			failed++
+			continue
+		}
+		template, err := templates.Parse(path, executerOpts)
+		if err != nil {
+			gologger.Error().Msgf("Could not parse template %s: %s\n", path, err)
+			failed++
+			continue
+		}
+		if template == nil {
+			continue
+		}
+		if reason := untestable(template, options); reason != "" {
+			gologger.Warning().Msgf("Skipping tests of %s as %s", template.ID, reason)
+			continue
+		}
+
+		for _, test := range suite.Tests {
+			run = &testRun{result: &fixture.Result{Matchers: make(map[string]bool)}}
+			if err := run.execute(template, test); err != nil {
+				gologger.Print().Msgf("[%s] %s/%s: %s", colorizer.Red("FAIL"), template.ID, test.Name, err)
+				failed++
+				continue
+			}
+			gologger.Print().Msgf("[%s] %s/%s", colorizer.Green("PASS"), template.ID, test.Name)
+			passed++
+		}
+	}
+	gologger.Info().Msgf("%d template tests passed, %d failed", passed, failed)
+	if failed > 0 {
+		return errors.Errorf("%d template tests failed", failed)
+	}
+	return nil
+}
+
+// offlineTestOptions disables the options sending the requests of the
+// template tests elsewhere than the local servers of the fixtures.
+func offlineTestOptions(options *types.Options) {
+	options.ProxyURL = ""
+	options.ProxySocksURL = ""
+	options.SourceIP = ""
+	options.Interface = ""
+	options.NoInteractsh = true
+}
+
+// untestable returns the reason a template can't be tested against the
+// canned responses of its fixtures, or an empty string.
+func untestable(template *templates.Template, options *types.Options) string {
+	if len(template.RequestsDNS) > 0 || len(template.RequestsFile) > 0 || len(template.RequestsMail) > 0 || len(template.RequestsSSH) > 0 || len(template.RequestsService) > 0 {
+		return "only http, network and headless templates can be tested"
+	}
+	if len(template.RequestsHeadless) > 0 && !options.Headless {
+		return "headless templates are only tested with -headless"
+	}
+	if template.Executer == nil {
+		return "it has no requests"
+	}
+	return ""
+}
+
+// testRun records the results of a template run against a test case
+type testRun struct {
+	mutex  sync.Mutex
+	result *fixture.Result
+}
+
+// compiledOperatorsRequest is implemented by the requests with operators
+type compiledOperatorsRequest interface {
+	GetCompiledOperators() *operators.Operators
+}
+
+// record records the results of an event and the outcome of each named
+// matcher of its request, whatever the matchers condition.
+func (t *testRun) record(req protocols.Request, event *output.InternalWrappedEvent) {
+	request, ok := req.(compiledOperatorsRequest)
+	if !ok || request.GetCompiledOperators() == nil {
+		return
+	}
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	for _, matcher := range request.GetCompiledOperators().Matchers {
+		if matcher.Name == "" {
+			continue
+		}
+		if req.Match(event.InternalEvent, matcher.ResolveValues(event.InternalEvent)) {
+			t.result.Matchers[matcher.Name] = true
+		} else if _, ok := t.result.Matchers[matcher.Name]; !ok {
+			t.result.Matchers[matcher.Name] = false
+		}
+	}
+}
+
+// execute executes a template against a local server serving the
+// responses of a test case and checks its results. The network templates
+// are served over tcp and the others over http.
+func (t *testRun) execute(template *templates.Template, test *fixture.Test) error {
+	server := fixture.NewServer(test.Responses)
+
+	var input string
+	if len(template.RequestsNetwork) > 0 && len(template.RequestsHTTP) == 0 && len(template.RequestsHeadless) == 0 {
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			return errors.Wrap(err, "could not listen")
+		}
+		defer listener.Close()
+		go func() {
+			for {
+				conn, err := listener.Accept()
+				if err != nil {
+					return
+				}
+				go server.ServeConn(conn)
+			}
+		}()
+		input = listener.Addr().String()
+	} else {
+		httpServer := httptest.NewServer(server)
+		defer httpServer.Close()
+		input = httpServer.URL
+	}
+
+	err := template.Executer.ExecuteWithResults(input, func(event *output.InternalWrappedEvent) {
+		t.mutex.Lock()
+		defer t.mutex.Unlock()
+		for _, result := range event.Results {
+			t.result.Matched = true
+			t.result.Extracted = append(t.result.Extracted, result.ExtractedResults...)
+		}
+	})
+	if err != nil {
+		return err
+	}
+	t.result.Unserved = server.Unserved()
+	return test.Check(t.result)
+}
+
+// RunMockServer serves the canned responses of a fixture file until the process is stopped
+func RunMockServer(options *types.Options) error {
+	handler, err := fixture.FileHandler(options.MockServer)
+	if err != nil {
+		return err
+	}
+	logger := http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		gologger.Info().Msgf("%s %s", req.Method, req.URL.RequestURI())
+		handler.ServeHTTP(w, req)
+	})
+	gologger.Info().Msgf("Serving the responses of %s on http://%s", options.MockServer, options.MockServerAddress)
+	return http.ListenAndServe(options.MockServerAddress, logger)
+}
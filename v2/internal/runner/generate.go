@@ -0,0 +1,95 @@
+package runner
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/projectdiscovery/gologger"
+	"github.com/projectdiscovery/nuclei/v2/pkg/templates/generator"
+	"github.com/projectdiscovery/nuclei/v2/pkg/types"
+)
+
+// RunNewTemplate asks for the details of a template and writes its skeleton to a file
+func RunNewTemplate(options *types.Options) error {
+	file := options.NewTemplate
+	if _, err := os.Stat(file); err == nil {
+		return errors.Errorf("template %s already exists", file)
+	}
+	id := strings.TrimSuffix(filepath.Base(file), filepath.Ext(file))
+
+	prompter := &prompter{reader: bufio.NewReader(os.Stdin), out: os.Stderr}
+	generatorOptions := &generator.Options{
+		ID:          prompter.ask("Template id", id, nil),
+		Name:        prompter.ask("Template name", "", nil),
+		Author:      prompter.ask("Author", os.Getenv("USER"), nil),
+		Severity:    prompter.ask("Severity", "info", generator.Severities),
+		Description: prompter.ask("Description", "", nil),
+		Protocol:    prompter.ask("Protocol", "http", generator.Protocols),
+		MatcherType: prompter.ask("Matcher type", "word", generator.MatcherTypes),
+	}
+	for _, tag := range strings.Split(prompter.ask("Tags (comma separated)", "", nil), ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			generatorOptions.Tags = append(generatorOptions.Tags, tag)
+		}
+	}
+	if prompter.err != nil {
+		return errors.Wrap(prompter.err, "could not read answers")
+	}
+
+	data, err := generator.Generate(generatorOptions)
+	if err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(file, data, 0644); err != nil {
+		return errors.Wrap(err, "could not write template")
+	}
+	gologger.Info().Msgf("Template skeleton written to %s, replace the CHANGE_ME placeholders before using it", file)
+	return nil
+}
+
+// prompter asks questions on a terminal
+type prompter struct {
+	reader *bufio.Reader
+	out    io.Writer
+	err    error
+}
+
+// ask asks a question until the answer is one of the choices, returning
+// the default value for empty answers.
+func (p *prompter) ask(question, defaultValue string, choices []string) string {
+	for p.err == nil {
+		prompt := question
+		if len(choices) > 0 {
+			prompt += " (" + strings.Join(choices, "/") + ")"
+		}
+		if defaultValue != "" {
+			prompt += " [" + defaultValue + "]"
+		}
+		fmt.Fprintf(p.out, "%s: ", prompt)
+
+		var answer string
+		answer, p.err = p.reader.ReadString('\n')
+		if p.err == io.EOF && answer != "" {
+			p.err = nil
+		}
+		if answer = strings.TrimSpace(answer); answer == "" {
+			answer = defaultValue
+		}
+		if len(choices) == 0 {
+			return answer
+		}
+		for _, choice := range choices {
+			if strings.EqualFold(choice, answer) {
+				return choice
+			}
+		}
+		fmt.Fprintf(p.out, "Invalid answer %s\n", answer)
+	}
+	return defaultValue
+}
@@ -0,0 +1,82 @@
+package runner
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/projectdiscovery/gologger"
+	"github.com/projectdiscovery/nuclei/v2/pkg/input/apispec"
+	"github.com/projectdiscovery/nuclei/v2/pkg/input/graphql"
+	"github.com/projectdiscovery/nuclei/v2/pkg/protocols/common/auditlog"
+	"github.com/projectdiscovery/nuclei/v2/pkg/protocols/http/httpclientpool"
+	"go.uber.org/ratelimit"
+)
+
+// graphqlInput runs introspection on the http targets of the input storing
+// the requests of the graphql operations found to be used by the fuzzing
+// templates.
+func (r *Runner) graphqlInput() {
+	// The pooled client honors the proxy and source address of the scan
+	pooled, err := httpclientpool.Get(r.options, &httpclientpool.Configuration{FollowRedirects: true})
+	if err != nil {
+		gologger.Warning().Msgf("Could not get http client for graphql introspection: %s\n", err)
+		return
+	}
+	client := &http.Client{
+		Timeout:       pooled.HTTPClient.Timeout,
+		CheckRedirect: pooled.HTTPClient.CheckRedirect,
+		Transport: &headerTransport{
+			headers:     r.options.CustomHeaders,
+			ratelimiter: r.ratelimiter,
+			transport:   auditlog.NewTransport(r.auditLog, "graphql-introspection", "http", pooled.HTTPClient.Transport),
+		},
+	}
+
+	var endpoints []string
+	r.hostMap.Scan(func(k, _ []byte) error {
+		if target := string(k); strings.HasPrefix(target, "http://") || strings.HasPrefix(target, "https://") {
+			endpoints = append(endpoints, target)
+		}
+		return nil
+	})
+
+	if r.inputRequests == nil {
+		r.inputRequests = apispec.NewStore()
+	}
+	var operations int
+	for _, endpoint := range endpoints {
+		schema, err := graphql.Introspect(client, endpoint)
+		if err != nil {
+			gologger.Verbose().Msgf("Could not introspect %s: %s\n", endpoint, err)
+			continue
+		}
+		for _, request := range schema.Requests(endpoint, r.options.GraphQLMutations) {
+			r.inputRequests.Add(request)
+			operations++
+		}
+	}
+	gologger.Info().Msgf("Found %d graphql operations by introspecting %d targets", operations, len(endpoints))
+}
+
+// headerTransport sets the custom headers on each request and sends it
+// within the rate limit of the scan.
+type headerTransport struct {
+	headers     []string
+	ratelimiter ratelimit.Limiter
+	transport   http.RoundTripper
+}
+
+// RoundTrip sets the custom headers and executes the request
+func (t *headerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if t.ratelimiter != nil {
+		t.ratelimiter.Take()
+	}
+	for _, header := range t.headers {
+		parts := strings.SplitN(header, ":", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		req.Header.Set(strings.TrimSpace(parts[0]), strings.TrimSpace(parts[1]))
+	}
+	return t.transport.RoundTrip(req)
+}
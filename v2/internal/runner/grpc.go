@@ -0,0 +1,152 @@
+package runner
+
+import (
+	"context"
+	"net"
+	"time"
+
+	jsoniter "github.com/json-iterator/go"
+	"github.com/projectdiscovery/gologger"
+	"github.com/projectdiscovery/nuclei/v2/pkg/grpcapi"
+	"github.com/projectdiscovery/nuclei/v2/pkg/output"
+	"github.com/projectdiscovery/nuclei/v2/pkg/types"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// grpcProgressInterval is the interval between progress events of a scan stream
+const grpcProgressInterval = 5 * time.Second
+
+// serveGRPC serves the grpc scan service
+func (s *Server) serveGRPC(address string) error {
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return err
+	}
+	server := grpc.NewServer(grpc.StreamInterceptor(s.authorizeGRPC))
+	grpcapi.RegisterNucleiServer(server, &grpcService{server: s})
+
+	gologger.Info().Msgf("Listening for grpc scan requests on %s", address)
+	return server.Serve(listener)
+}
+
+// authorizeGRPC validates the token in the authorization metadata of the
+// grpc calls if one is configured
+func (s *Server) authorizeGRPC(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	if !s.validToken(authorizationMetadata(stream.Context())) {
+		return status.Error(codes.Unauthenticated, "invalid token")
+	}
+	return handler(srv, stream)
+}
+
+// authorizationMetadata returns the authorization metadata of a grpc call
+func authorizationMetadata(ctx context.Context) string {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ""
+	}
+	if values := md.Get("authorization"); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+// grpcService implements the grpc scan service with the scans of the server
+type grpcService struct {
+	grpcapi.UnimplementedNucleiServer
+	server *Server
+}
+
+// Scan starts a scan streaming its results and progress until it is
+// finished. Cancelling the call cancels the scan.
+func (g *grpcService) Scan(request *grpcapi.ScanRequest, stream grpcapi.Nuclei_ScanServer) error {
+	scan, err := g.server.startScan(&ScanRequest{
+		Targets:           request.GetTargets(),
+		Templates:         request.GetTemplates(),
+		Workflows:         request.GetWorkflows(),
+		ExcludedTemplates: request.GetExcludeTemplates(),
+		Tags:              request.GetTags(),
+		ExcludeTags:       request.GetExcludeTags(),
+		Severity:          request.GetSeverity(),
+		RateLimit:         int(request.GetRateLimit()),
+		BulkSize:          int(request.GetBulkSize()),
+		Concurrency:       int(request.GetConcurrency()),
+	})
+	if err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	return streamScan(stream.Context(), scan, stream.Send)
+}
+
+// streamScan sends the results of a scan and its progress in intervals
+// until it is finished. Cancelling the context cancels the scan, the scan
+// keeps running if sending an event fails.
+func streamScan(ctx context.Context, scan *scan, send func(*grpcapi.ScanEvent) error) error {
+	var index int
+	lastProgress := time.Now()
+	for {
+		waitCtx, cancel := context.WithDeadline(ctx, lastProgress.Add(grpcProgressInterval))
+		results, ok := scan.nextResults(waitCtx, index)
+		waitErr := waitCtx.Err()
+		cancel()
+
+		for _, event := range results {
+			if err := send(grpcResultEvent(event)); err != nil {
+				return err
+			}
+		}
+		index += len(results)
+
+		if ctx.Err() != nil {
+			// Cancelling the call cancels the scan
+			scan.cancel()
+			return status.Error(codes.Canceled, "scan cancelled")
+		}
+		if !ok && waitErr == nil {
+			return send(grpcProgressEvent(scan.status()))
+		}
+		if time.Since(lastProgress) >= grpcProgressInterval {
+			if err := send(grpcProgressEvent(scan.status())); err != nil {
+				return err
+			}
+			lastProgress = time.Now()
+		}
+	}
+}
+
+// grpcResultEvent converts a result event to a grpc scan event
+func grpcResultEvent(event *output.ResultEvent) *grpcapi.ScanEvent {
+	data, _ := jsoniter.Marshal(event)
+
+	return &grpcapi.ScanEvent{Event: &grpcapi.ScanEvent_Result{Result: &grpcapi.Result{
+		TemplateId:       event.TemplateID,
+		Name:             types.ToString(event.Info["name"]),
+		Severity:         types.ToString(event.Info["severity"]),
+		Type:             event.Type,
+		Host:             event.Host,
+		Matched:          event.Matched,
+		MatcherName:      event.MatcherName,
+		ExtractorName:    event.ExtractorName,
+		ExtractedResults: event.ExtractedResults,
+		Ip:               event.IP,
+		Timestamp:        event.Timestamp.Unix(),
+		Json:             string(data),
+	}}}
+}
+
+// grpcProgressEvent converts a scan status to a grpc scan event
+func grpcProgressEvent(scanStatus *ScanStatus) *grpcapi.ScanEvent {
+	return &grpcapi.ScanEvent{Event: &grpcapi.ScanEvent_Progress{Progress: &grpcapi.Progress{
+		State:     scanStatus.State,
+		Hosts:     scanStatus.Progress["hosts"],
+		Templates: scanStatus.Progress["templates"],
+		Total:     scanStatus.Progress["total"],
+		Requests:  scanStatus.Progress["requests"],
+		Matched:   scanStatus.Progress["matched"],
+		Errors:    scanStatus.Progress["errors"],
+		Skipped:   scanStatus.Progress["skipped"],
+	}}}
+}
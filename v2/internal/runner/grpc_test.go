@@ -0,0 +1,63 @@
+package runner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/projectdiscovery/nuclei/v2/pkg/grpcapi"
+	"github.com/projectdiscovery/nuclei/v2/pkg/output"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStreamScan(t *testing.T) {
+	scan := newTestScan("running", time.Time{})
+
+	const count = 2000
+	go func() {
+		for i := 0; i < count; i++ {
+			scan.addResult(&output.ResultEvent{TemplateID: fmt.Sprintf("template-%d", i)})
+		}
+		scan.mutex.Lock()
+		scan.state, scan.finished = scanFinished, time.Now()
+		scan.notify()
+		scan.mutex.Unlock()
+	}()
+
+	var results int
+	var progress *grpcapi.Progress
+	err := streamScan(context.Background(), scan, func(event *grpcapi.ScanEvent) error {
+		if result := event.GetResult(); result != nil {
+			require.Equal(t, fmt.Sprintf("template-%d", results), result.TemplateId, "could not stream results in order")
+			results++
+		}
+		progress = event.GetProgress()
+		return nil
+	})
+	require.Nil(t, err, "could not stream scan")
+	require.Equal(t, count, results, "could not stream all results")
+	require.NotNil(t, progress, "could not stream final progress")
+	require.Equal(t, scanFinished, progress.State, "could not stream final state")
+}
+
+func TestStreamScanSendError(t *testing.T) {
+	scan := newTestScan("running", time.Time{})
+	scan.addResult(&output.ResultEvent{TemplateID: "panel"})
+
+	sendErr := errors.New("transport is closing")
+	done := make(chan error, 1)
+	go func() {
+		done <- streamScan(context.Background(), scan, func(*grpcapi.ScanEvent) error {
+			return sendErr
+		})
+	}()
+
+	select {
+	case err := <-done:
+		require.Equal(t, sendErr, err, "could not return send error")
+	case <-time.After(5 * time.Second):
+		t.Fatal("could not stop streaming after send error")
+	}
+}
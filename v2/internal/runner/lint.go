@@ -0,0 +1,48 @@
+package runner
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/projectdiscovery/gologger"
+	"github.com/projectdiscovery/nuclei/v2/pkg/templates/lint"
+)
+
+// lintTemplates writes the issues of the templates to stdout, as json lines
+// with -json, returning false if any template has errors.
+func (r *Runner) lintTemplates() bool {
+	inputs := make([]string, 0, len(r.options.Templates)+len(r.options.Workflows))
+	inputs = append(inputs, r.options.Templates...)
+	inputs = append(inputs, r.options.Workflows...)
+	paths := r.catalog.GetTemplatesPath(inputs, false)
+
+	valid := true
+	var issueCount int
+	for _, path := range paths {
+		issues, err := lint.Lint(path)
+		if err != nil {
+			gologger.Error().Msgf("Could not lint template %s: %s\n", path, err)
+			valid = false
+			continue
+		}
+		for _, issue := range issues {
+			issueCount++
+			if issue.Level == lint.LevelError {
+				valid = false
+			}
+			if r.options.JSON {
+				data, _ := json.Marshal(issue)
+				fmt.Fprintf(os.Stdout, "%s\n", data)
+				continue
+			}
+			level := r.colorizer.Yellow(issue.Level).String()
+			if issue.Level == lint.LevelError {
+				level = r.colorizer.Red(issue.Level).String()
+			}
+			fmt.Fprintf(os.Stdout, "[%s] [%s] %s: %s\n", level, r.colorizer.BrightBlue(issue.Rule).String(), issue.Path, issue.Message)
+		}
+	}
+	gologger.Info().Msgf("Linted %d templates, found %d issues", len(paths), issueCount)
+	return valid
+}
@@ -0,0 +1,72 @@
+package runner
+
+import (
+	"bytes"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+	"github.com/projectdiscovery/gologger/levels"
+	"github.com/projectdiscovery/gologger/writer"
+)
+
+// logLevels are the levels supported by the log level option
+var logLevels = map[string]levels.Level{
+	"fatal":   levels.LevelFatal,
+	"silent":  levels.LevelSilent,
+	"error":   levels.LevelError,
+	"info":    levels.LevelInfo,
+	"warning": levels.LevelWarning,
+	"debug":   levels.LevelDebug,
+	"verbose": levels.LevelVerbose,
+}
+
+var escapeCodesRegex = regexp.MustCompile(`\x1B\[[0-9;]*[a-zA-Z]`)
+
+// parseLogLevel returns the level of a log level option value
+func parseLogLevel(value string) (levels.Level, error) {
+	level, ok := logLevels[strings.ToLower(value)]
+	if !ok {
+		return 0, errors.Errorf("invalid log level %s", value)
+	}
+	return level, nil
+}
+
+// logFileWriter writes the log messages to the screen and, without
+// escape codes, to a log file.
+type logFileWriter struct {
+	cli   writer.Writer
+	mutex sync.Mutex
+	file  *os.File
+}
+
+// newLogFileWriter creates a log writer appending to a file
+func newLogFileWriter(file string) (*logFileWriter, error) {
+	output, err := os.OpenFile(file, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not open log file")
+	}
+	return &logFileWriter{cli: writer.NewCLI(), file: output}, nil
+}
+
+// Write writes a log message to the screen and the log file
+func (w *logFileWriter) Write(data []byte, level levels.Level) {
+	w.cli.Write(data, level)
+
+	data = bytes.TrimRight(escapeCodesRegex.ReplaceAll(data, nil), "\n")
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	_, _ = w.file.Write(data)
+	_, _ = w.file.Write([]byte("\n"))
+}
+
+// isTerminal returns true if the file is a terminal and not redirected
+func isTerminal(file *os.File) bool {
+	stat, err := file.Stat()
+	if err != nil {
+		return false
+	}
+	return (stat.Mode() & os.ModeCharDevice) != 0
+}
@@ -0,0 +1,115 @@
+package runner
+
+import (
+	"os"
+	"strings"
+	"time"
+
+	"github.com/projectdiscovery/gologger"
+	"github.com/projectdiscovery/nuclei/v2/pkg/output"
+)
+
+// secretFlags are the flags whose values are redacted from the scan metadata
+var secretFlags = map[string]struct{}{
+	"H":                 {},
+	"header":            {},
+	"distributed-token": {},
+	"server-token":      {},
+	"proxy-url":         {},
+	"proxy-socks-url":   {},
+}
+
+// redactedValue replaces the secret values of the arguments
+const redactedValue = "<redacted>"
+
+// writeScanMetadata writes the metadata of the scan to the -scan-metadata
+// file and embeds it in the exporters supporting it.
+func (r *Runner) writeScanMetadata(templateCount int) {
+	metadata := &output.ScanMetadata{
+		NucleiVersion: Version,
+		Arguments:     redactArguments(os.Args[1:]),
+		Templates:     templateCount,
+		Targets:       r.inputCount,
+		StartTime:     time.Now(),
+	}
+	if r.templatesConfig != nil {
+		metadata.TemplatesVersion = r.templatesConfig.CurrentVersion
+	}
+	r.scanMetadata = metadata
+	if r.options.ScanMetadataFile != "" {
+		if err := metadata.WriteFile(r.options.ScanMetadataFile); err != nil {
+			gologger.Warning().Msgf("Could not write scan metadata: %s\n", err)
+		}
+	}
+	if r.issuesClient != nil {
+		r.issuesClient.SetScanMetadata(metadata)
+	}
+}
+
+// finishScanMetadata sets the end time of the scan in its metadata and
+// writes it again to the -scan-metadata file.
+func (r *Runner) finishScanMetadata() {
+	if r.scanMetadata == nil {
+		return
+	}
+	r.scanMetadata.Finish()
+	if r.options.ScanMetadataFile != "" {
+		if err := r.scanMetadata.WriteFile(r.options.ScanMetadataFile); err != nil {
+			gologger.Warning().Msgf("Could not write scan metadata: %s\n", err)
+		}
+	}
+}
+
+// redactArguments returns the command line arguments with the values of
+// the secret flags redacted. The names of the headers are kept.
+func redactArguments(args []string) []string {
+	redacted := make([]string, 0, len(args))
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		name := strings.TrimLeft(arg, "-")
+		if !strings.HasPrefix(arg, "-") || name == "" {
+			redacted = append(redacted, arg)
+			continue
+		}
+		value, hasValue := "", false
+		if parts := strings.SplitN(name, "=", 2); len(parts) == 2 {
+			name, value, hasValue = parts[0], parts[1], true
+		}
+		if _, ok := secretFlags[name]; !ok {
+			redacted = append(redacted, arg)
+			continue
+		}
+		if !hasValue {
+			redacted = append(redacted, arg)
+			if i+1 < len(args) {
+				i++
+				redacted = append(redacted, redactValue(name, args[i]))
+			}
+			continue
+		}
+		redacted = append(redacted, arg[:len(arg)-len(value)]+redactValue(name, value))
+	}
+	return redacted
+}
+
+// redactValue redacts the value of a secret flag, keeping the header names
+// and the proxy addresses.
+func redactValue(flag, value string) string {
+	switch flag {
+	case "H", "header":
+		if parts := strings.SplitN(value, ":", 2); len(parts) == 2 {
+			return parts[0] + ": " + redactedValue
+		}
+	case "proxy-url", "proxy-socks-url":
+		// Only the user:pass@ credentials of the proxy url are secret
+		start := 0
+		if scheme := strings.Index(value, "://"); scheme != -1 {
+			start = scheme + len("://")
+		}
+		if end := strings.LastIndex(value, "@"); end >= start {
+			return value[:start] + redactedValue + value[end:]
+		}
+		return value
+	}
+	return redactedValue
+}
@@ -0,0 +1,52 @@
+package runner
+
+import (
+	"bytes"
+	"io/ioutil"
+
+	"github.com/pkg/errors"
+	"github.com/projectdiscovery/gologger"
+	"github.com/projectdiscovery/nuclei/v2/pkg/catalog"
+	"github.com/projectdiscovery/nuclei/v2/pkg/templates"
+	"github.com/projectdiscovery/nuclei/v2/pkg/types"
+)
+
+// RunMigrateTemplates rewrites the deprecated fields of the templates of a
+// directory to the current spec version in place.
+func RunMigrateTemplates(options *types.Options) error {
+	paths := catalog.New(options.TemplatesDirectory).GetTemplatesPath([]string{options.MigrateTemplates}, true)
+	if len(paths) == 0 {
+		return errors.Errorf("no templates found in %s", options.MigrateTemplates)
+	}
+
+	var migrated, failed int
+	for _, path := range paths {
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			gologger.Error().Msgf("Could not read template %s: %s\n", path, err)
+			failed++
+			continue
+		}
+		updated, err := templates.Migrate(data)
+		if err != nil {
+			gologger.Error().Msgf("Could not migrate template %s: %s\n", path, err)
+			failed++
+			continue
+		}
+		if updated = templates.SetSpecVersion(updated); bytes.Equal(updated, data) {
+			continue
+		}
+		if err := ioutil.WriteFile(path, updated, 0644); err != nil {
+			gologger.Error().Msgf("Could not write template %s: %s\n", path, err)
+			failed++
+			continue
+		}
+		gologger.Verbose().Msgf("Migrated template %s", path)
+		migrated++
+	}
+	gologger.Info().Msgf("Migrated %d of %d templates to spec-version %d", migrated, len(paths), templates.CurrentSpecVersion)
+	if failed > 0 {
+		return errors.Errorf("could not migrate %d templates", failed)
+	}
+	return nil
+}
@@ -0,0 +1,42 @@
+package runner
+
+import (
+	"os"
+
+	"github.com/projectdiscovery/nuclei/v2/pkg/input/nmap"
+)
+
+// loadNmapInput adds the open services from a nmap or masscan XML file to the
+// input, routing the services with known names to the templates tagged for
+// them. The number of duplicate targets is returned.
+func (r *Runner) loadNmapInput(file string) (int, error) {
+	input, err := os.Open(file)
+	if err != nil {
+		return 0, err
+	}
+	defer input.Close()
+
+	services, err := nmap.Parse(input)
+	if err != nil {
+		return 0, err
+	}
+	if r.routes == nil {
+		r.routes = make(map[string]*targetRoute)
+	}
+
+	dupeCount := 0
+	for _, service := range services {
+		target := service.Target()
+		if tags := service.Tags(); len(tags) > 0 {
+			r.routes[target] = &targetRoute{Host: target, Tags: tags, templatePaths: make(map[string]struct{})}
+		}
+		if _, ok := r.hostMap.Get(target); ok {
+			dupeCount++
+			continue
+		}
+		r.inputCount++
+		// nolint:errcheck // ignoring error
+		r.hostMap.Set(target, nil)
+	}
+	return dupeCount, nil
+}
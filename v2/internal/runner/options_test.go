@@ -0,0 +1,38 @@
+package runner
+
+import (
+	"testing"
+
+	"github.com/projectdiscovery/nuclei/v2/pkg/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateCoordinatorAddress(t *testing.T) {
+	for address, loopback := range map[string]bool{
+		"127.0.0.1:8822": true,
+		"localhost:8822": true,
+		"[::1]:8822":     true,
+		"0.0.0.0:8822":   false,
+		":8822":          false,
+		"10.0.0.1:8822":  false,
+	} {
+		require.Equal(t, loopback, isLoopbackAddress(address), "could not check loopback address %s", address)
+	}
+
+	options := &types.Options{Coordinator: "0.0.0.0:8822", ScreenshotSeverity: "info"}
+	err := validateOptions(options)
+	require.NotNil(t, err, "could listen for workers on all interfaces without token")
+	require.Contains(t, err.Error(), "distributed token", "could not require token for non-loopback address")
+}
+
+func TestValidateFeatureProtocols(t *testing.T) {
+	require.Nil(t, validateFeatureProtocols(&types.Options{Screenshot: true, ExcludeProtocols: []string{"dns"}}), "could not screenshot without excluded protocol")
+
+	err := validateFeatureProtocols(&types.Options{Screenshot: true, ExcludeProtocols: []string{"headless"}})
+	require.NotNil(t, err, "could screenshot with excluded headless protocol")
+	require.Contains(t, err.Error(), "-screenshot", "could not get feature of excluded protocol")
+
+	require.NotNil(t, validateFeatureProtocols(&types.Options{ServiceDetection: true, Protocols: []string{"http"}}), "could detect services without allowed network protocol")
+	require.NotNil(t, validateFeatureProtocols(&types.Options{Takeover: true, ExcludeProtocols: []string{"dns"}}), "could check takeover with excluded dns protocol")
+	require.Nil(t, validateFeatureProtocols(&types.Options{Takeover: true, Protocols: []string{"dns,http"}}), "could not check takeover with allowed protocols")
+}
@@ -0,0 +1,117 @@
+package runner
+
+import (
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// parsePorts parses a comma separated list of ports and port ranges (eg. 80,443,8080-8090)
+func parsePorts(value string) ([]int, error) {
+	var ports []int
+	seen := make(map[int]struct{})
+
+	for _, item := range strings.Split(value, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		start, end := item, item
+		if parts := strings.SplitN(item, "-", 2); len(parts) == 2 {
+			start, end = parts[0], parts[1]
+		}
+		first, err := parsePort(start)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid port %s", item)
+		}
+		last, err := parsePort(end)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid port %s", item)
+		}
+		if first > last {
+			return nil, errors.Errorf("invalid port range %s", item)
+		}
+		for port := first; port <= last; port++ {
+			if _, ok := seen[port]; ok {
+				continue
+			}
+			seen[port] = struct{}{}
+			ports = append(ports, port)
+		}
+	}
+	if len(ports) == 0 {
+		return nil, errors.New("no ports provided")
+	}
+	return ports, nil
+}
+
+// parsePort parses a single port number
+func parsePort(value string) (int, error) {
+	port, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil {
+		return 0, err
+	}
+	if port < 1 || port > 65535 {
+		return 0, errors.New("port out of range")
+	}
+	return port, nil
+}
+
+// portSchemes are the http schemes of the well-known http and https ports
+var portSchemes = map[int]string{
+	80: "http", 8000: "http", 8008: "http", 8080: "http", 8888: "http",
+	443: "https", 8443: "https", 9443: "https",
+}
+
+// targetWithPorts returns the target combined with each of the ports. Targets
+// already specifying a port are returned as is. The scheme of http URLs is
+// inferred from well-known ports, keeping the original one for other ports.
+func targetWithPorts(target string, ports []int) []string {
+	if strings.Contains(target, "://") {
+		parsed, err := url.Parse(target)
+		if err != nil || parsed.Port() != "" || parsed.Hostname() == "" {
+			return []string{target}
+		}
+		targets := make([]string, 0, len(ports))
+		for _, port := range ports {
+			withPort := *parsed
+			withPort.Host = net.JoinHostPort(parsed.Hostname(), strconv.Itoa(port))
+			if scheme, ok := portSchemes[port]; ok && (parsed.Scheme == "http" || parsed.Scheme == "https") {
+				withPort.Scheme = scheme
+			}
+			targets = append(targets, withPort.String())
+		}
+		return targets
+	}
+
+	if _, _, err := net.SplitHostPort(target); err == nil {
+		return []string{target}
+	}
+	host := strings.TrimSuffix(strings.TrimPrefix(target, "["), "]")
+	targets := make([]string, 0, len(ports))
+	for _, port := range ports {
+		targets = append(targets, net.JoinHostPort(host, strconv.Itoa(port)))
+	}
+	return targets
+}
+
+// expandPorts fans each of the input targets across the ports, keeping
+// the target routes for the expanded targets.
+func (r *Runner) expandPorts(ports []int) error {
+	var targets []string
+	r.hostMap.Scan(func(k, _ []byte) error {
+		target := string(k)
+		route, hasRoute := r.routes[target]
+		for _, expanded := range targetWithPorts(target, ports) {
+			targets = append(targets, expanded)
+			if hasRoute {
+				r.routes[expanded] = route
+			}
+		}
+		return nil
+	})
+	return r.setInput(targets)
+}
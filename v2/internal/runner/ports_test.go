@@ -0,0 +1,30 @@
+package runner
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePorts(t *testing.T) {
+	ports, err := parsePorts("80, 443,8080-8082,443")
+	require.Nil(t, err, "could not parse ports")
+	require.Equal(t, []int{80, 443, 8080, 8081, 8082}, ports, "could not get correct ports")
+
+	for _, value := range []string{"", "0", "65536", "90-80", "http"} {
+		_, err := parsePorts(value)
+		require.NotNil(t, err, "could parse invalid ports %q", value)
+	}
+}
+
+func TestTargetWithPorts(t *testing.T) {
+	ports := []int{80, 8443}
+
+	require.Equal(t, []string{"example.com:80", "example.com:8443"}, targetWithPorts("example.com", ports), "could not expand host")
+	require.Equal(t, []string{"[::1]:80", "[::1]:8443"}, targetWithPorts("::1", ports), "could not expand ipv6 host")
+	require.Equal(t, []string{"example.com:22"}, targetWithPorts("example.com:22", ports), "could expand host with port")
+	require.Equal(t, []string{"http://example.com:80/app", "https://example.com:8443/app"}, targetWithPorts("https://example.com/app", ports), "could not expand url")
+	require.Equal(t, []string{"https://example.com:443", "http://example.com:9000"}, targetWithPorts("http://example.com", []int{443, 9000}), "could not infer url scheme")
+	require.Equal(t, []string{"ws://example.com:443"}, targetWithPorts("ws://example.com", []int{443}), "could change non http scheme")
+	require.Equal(t, []string{"https://example.com:9000"}, targetWithPorts("https://example.com:9000", ports), "could expand url with port")
+}
@@ -0,0 +1,77 @@
+package runner
+
+import (
+	"net"
+	"net/http"
+	"net/http/pprof"
+	"os"
+	"runtime"
+	runtimepprof "runtime/pprof"
+	"strconv"
+
+	"github.com/pkg/errors"
+	"github.com/projectdiscovery/gologger"
+	"github.com/projectdiscovery/nuclei/v2/pkg/types"
+)
+
+// StartProfiling starts the pprof server and cpu profiling if asked. The
+// returned function stops the cpu profile and writes the memory profile.
+func StartProfiling(options *types.Options) (func(), error) {
+	if options.PProf {
+		address := net.JoinHostPort("127.0.0.1", strconv.Itoa(options.PProfPort))
+		mux := http.NewServeMux()
+		mux.HandleFunc("/debug/pprof/", pprof.Index)
+		mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+		mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+		mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+		mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+
+		listener, err := net.Listen("tcp", address)
+		if err != nil {
+			return nil, errors.Wrap(err, "could not listen for pprof server")
+		}
+		gologger.Info().Msgf("Serving pprof diagnostics on http://%s/debug/pprof/", address)
+		go func() {
+			if err := http.Serve(listener, mux); err != nil {
+				gologger.Warning().Msgf("Could not serve pprof diagnostics: %s\n", err)
+			}
+		}()
+	}
+
+	var cpuFile *os.File
+	if options.CPUProfile != "" {
+		file, err := os.Create(options.CPUProfile)
+		if err != nil {
+			return nil, errors.Wrap(err, "could not create cpu profile")
+		}
+		if err := runtimepprof.StartCPUProfile(file); err != nil {
+			file.Close()
+			return nil, errors.Wrap(err, "could not start cpu profile")
+		}
+		cpuFile = file
+	}
+
+	return func() {
+		if cpuFile != nil {
+			runtimepprof.StopCPUProfile()
+			cpuFile.Close()
+		}
+		if options.MemProfile != "" {
+			if err := writeMemProfile(options.MemProfile); err != nil {
+				gologger.Error().Msgf("Could not write memory profile: %s\n", err)
+			}
+		}
+	}, nil
+}
+
+// writeMemProfile writes a heap profile to the file
+func writeMemProfile(path string) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	runtime.GC()
+	return runtimepprof.WriteHeapProfile(file)
+}
@@ -0,0 +1,47 @@
+package runner
+
+import (
+	"os"
+
+	"github.com/pkg/errors"
+	"github.com/projectdiscovery/gologger"
+	"github.com/projectdiscovery/nuclei/v2/pkg/templates"
+)
+
+// confirmRequests returns an error if the planned requests of the scan are
+// above the confirmation threshold and the scan was not confirmed with -yes.
+// A threshold of zero disables the confirmation.
+func confirmRequests(plannedRequests, threshold int64, confirmed bool) error {
+	if threshold <= 0 || plannedRequests <= threshold || confirmed {
+		return nil
+	}
+	return errors.Errorf("the scan would send %d requests (more than the %d of -confirm-threshold), use -yes to run it", plannedRequests, threshold)
+}
+
+// confirmScan returns true if the planned requests of the scan don't need a
+// confirmation with -yes, exiting otherwise unless the runner is shared.
+func (r *Runner) confirmScan(plannedRequests int64) bool {
+	if err := confirmRequests(plannedRequests, int64(r.options.ConfirmThreshold), r.options.Yes); err != nil {
+		gologger.Error().Msgf("Could not start scan: %s\n", err)
+		if !r.shared {
+			r.Close()
+			os.Exit(1)
+		}
+		return false
+	}
+	return true
+}
+
+// plannedRequests returns the estimated requests of the templates on the
+// targets. The requests of workflows are estimated with all their subtemplates.
+func plannedRequests(list []*templates.Template, targets int64) int64 {
+	var requests int64
+	for _, template := range list {
+		if len(template.Workflows) > 0 {
+			requests += int64(template.EstimatedRequests()) * targets
+			continue
+		}
+		requests += int64(template.TotalRequests) * targets
+	}
+	return requests
+}
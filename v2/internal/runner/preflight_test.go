@@ -0,0 +1,41 @@
+package runner
+
+import (
+	"testing"
+
+	"github.com/projectdiscovery/nuclei/v2/pkg/protocols"
+	"github.com/projectdiscovery/nuclei/v2/pkg/templates"
+	"github.com/projectdiscovery/nuclei/v2/pkg/workflows"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfirmRequests(t *testing.T) {
+	require.Nil(t, confirmRequests(1000, 1000, false), "could not run scan at threshold")
+	require.NotNil(t, confirmRequests(1001, 1000, false), "could run scan above threshold")
+	require.Nil(t, confirmRequests(1001, 1000, true), "could not run confirmed scan")
+	require.Nil(t, confirmRequests(5000000, 0, false), "could not run scan without threshold")
+}
+
+type mockExecuter struct {
+	requests int
+}
+
+func (m *mockExecuter) Compile() error                     { return nil }
+func (m *mockExecuter) Requests() int                      { return m.requests }
+func (m *mockExecuter) Execute(input string) (bool, error) { return false, nil }
+func (m *mockExecuter) ExecuteWithResults(input string, callback protocols.OutputEventCallback) error {
+	return nil
+}
+
+func TestPlannedRequests(t *testing.T) {
+	workflow := &templates.Template{ID: "workflow"}
+	workflow.Workflows = []*workflows.WorkflowTemplate{{
+		Executers: []*workflows.ProtocolExecuterPair{{Executer: &mockExecuter{requests: 2}}},
+		Subtemplates: []*workflows.WorkflowTemplate{{
+			Executers: []*workflows.ProtocolExecuterPair{{Executer: &mockExecuter{requests: 3}}},
+		}},
+	}}
+	list := []*templates.Template{{ID: "template", TotalRequests: 4}, workflow}
+
+	require.Equal(t, int64(27), plannedRequests(list, 3), "could not get planned requests with workflow subtemplates")
+}
@@ -1,8 +1,14 @@
 package runner
 
 import (
+	"net"
+	"strings"
+	"time"
+
 	"github.com/projectdiscovery/gologger"
+	"github.com/projectdiscovery/nuclei/v2/pkg/protocols/common/servicedetect"
 	"github.com/projectdiscovery/nuclei/v2/pkg/templates"
+	"github.com/projectdiscovery/nuclei/v2/pkg/types"
 	"github.com/remeh/sizedwaitgroup"
 	"go.uber.org/atomic"
 )
@@ -11,20 +17,28 @@ import (
 func (r *Runner) processTemplateWithList(template *templates.Template) bool {
 	results := &atomic.Bool{}
 	wg := sizedwaitgroup.New(r.options.BulkSize)
-	r.hostMap.Scan(func(k, _ []byte) error {
-		URL := string(k)
+	r.scanInput(template.ID, func(URL string) {
+		if r.stopped.Load() || r.skipForBudget(template, URL) || r.skipForControl(template, URL) || r.skipForRoute(template, URL) || r.skipForCrawl(template, URL) || r.skipForWaf(template, URL) || r.skipForRequires(template, URL) {
+			return
+		}
 
 		wg.Add()
 		go func(URL string) {
 			defer wg.Done()
 
+			if r.skipForService(template, URL) {
+				return
+			}
+			r.dashboard.TemplateStarted(template.ID)
+			start := time.Now()
 			match, err := template.Executer.Execute(URL)
+			r.dashboard.HostScanned(URL, time.Since(start))
+			r.dashboard.TemplateFinished(template.ID)
 			if err != nil {
 				gologger.Warning().Msgf("[%s] Could not execute step: %s\n", r.colorizer.BrightBlue(template.ID), err)
 			}
 			results.CAS(false, match)
 		}(URL)
-		return nil
 	})
 	wg.Wait()
 	return results.Load()
@@ -35,16 +49,102 @@ func (r *Runner) processWorkflowWithList(template *templates.Template) bool {
 	results := &atomic.Bool{}
 	wg := sizedwaitgroup.New(r.options.BulkSize)
 
-	r.hostMap.Scan(func(k, _ []byte) error {
-		URL := string(k)
+	r.scanInput(template.ID, func(URL string) {
+		if r.stopped.Load() || r.skipForBudget(template, URL) || r.skipForControl(template, URL) || r.skipForRoute(template, URL) || r.skipForCrawl(template, URL) || r.skipForRequires(template, URL) {
+			return
+		}
 		wg.Add()
 		go func(URL string) {
 			defer wg.Done()
+			r.dashboard.TemplateStarted(template.ID)
+			start := time.Now()
 			match := template.CompiledWorkflow.RunWorkflow(URL)
+			r.dashboard.HostScanned(URL, time.Since(start))
+			r.dashboard.TemplateFinished(template.ID)
 			results.CAS(false, match)
 		}(URL)
-		return nil
 	})
 	wg.Wait()
 	return results.Load()
 }
+
+// intrusiveTags contains the tags for templates that are skipped on hosts
+// detected behind a WAF or rate-limiting.
+var intrusiveTags = []string{"intrusive", "fuzz", "dos", "bruteforce"}
+
+// skipForWaf returns true if the template is intrusive and the host was
+// detected as blocking requests by the WAF detector.
+func (r *Runner) skipForWaf(template *templates.Template, URL string) bool {
+	if r.wafDetector == nil {
+		return false
+	}
+	reason, blocked := r.wafDetector.IsBlocked(URL)
+	if !blocked || !isIntrusiveTemplate(template) {
+		return false
+	}
+	gologger.Verbose().Msgf("[%s] Skipping intrusive template for %s (%s detected)\n", template.ID, URL, reason)
+	r.progress.IncrementSkippedBy(int64(template.TotalRequests))
+	return true
+}
+
+// isIntrusiveTemplate returns true if the template has any intrusive tags
+func isIntrusiveTemplate(template *templates.Template) bool {
+	tags, ok := template.Info["tags"]
+	if !ok {
+		return false
+	}
+	for _, tag := range strings.Split(types.ToString(tags), ",") {
+		tag = strings.TrimSpace(tag)
+		for _, intrusive := range intrusiveTags {
+			if strings.EqualFold(tag, intrusive) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// skipForService returns true if the template is a network template for a
+// service and a different service was detected on the host:port input.
+func (r *Runner) skipForService(template *templates.Template, URL string) bool {
+	if r.serviceDetector == nil || len(template.RequestsNetwork) == 0 || strings.Contains(URL, "://") {
+		return false
+	}
+	if _, _, err := net.SplitHostPort(URL); err != nil {
+		return false
+	}
+	tags := templateServiceTags(template)
+	if len(tags) == 0 {
+		return false
+	}
+	service := r.serviceDetector.Detect(URL)
+	if service == "" {
+		return false
+	}
+	for _, tag := range tags {
+		if tag == service {
+			return false
+		}
+	}
+	gologger.Verbose().Msgf("[%s] Skipping template for %s (%s service detected)\n", template.ID, URL, service)
+	r.progress.IncrementSkippedBy(int64(template.TotalRequests))
+	return true
+}
+
+// templateServiceTags returns the tags of the template naming a detectable service
+func templateServiceTags(template *templates.Template) []string {
+	tags, ok := template.Info["tags"]
+	if !ok {
+		return nil
+	}
+	var services []string
+	for _, tag := range strings.Split(types.ToString(tags), ",") {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		for _, service := range servicedetect.Services {
+			if tag == service {
+				services = append(services, tag)
+			}
+		}
+	}
+	return services
+}
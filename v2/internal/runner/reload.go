@@ -0,0 +1,176 @@
+package runner
+
+import (
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/projectdiscovery/gologger"
+	"github.com/projectdiscovery/nuclei/v2/pkg/templates"
+)
+
+// templateWatchInterval is the interval at which the template paths are
+// checked for new templates with -watch-templates.
+const templateWatchInterval = 30 * time.Second
+
+// templateReloader tracks the templates loaded by the scan and schedules
+// the templates added while it is running.
+type templateReloader struct {
+	mutex     sync.Mutex
+	started   bool
+	done      bool
+	loaded    map[string]struct{}
+	planned   int64
+	scheduler *fairScheduler
+	pending   []*templates.Template
+	stop      chan struct{}
+}
+
+// newTemplateReloader creates a reloader for a scan that has not started
+func newTemplateReloader() *templateReloader {
+	return &templateReloader{loaded: make(map[string]struct{}), stop: make(chan struct{})}
+}
+
+// startTemplateReloader records the templates loaded by the scan along with
+// its planned requests and starts watching the template paths if requested.
+func (r *Runner) startTemplateReloader(templatePaths, workflowPaths []string, plannedRequests int64) {
+	reloader := r.reloader
+	reloader.mutex.Lock()
+	for _, paths := range [][]string{templatePaths, workflowPaths} {
+		for _, path := range paths {
+			reloader.loaded[path] = struct{}{}
+		}
+	}
+	reloader.planned = plannedRequests
+	reloader.started = true
+	reloader.mutex.Unlock()
+
+	if !r.options.WatchTemplates {
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(templateWatchInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				if _, err := r.reloadTemplates(); err != nil {
+					gologger.Warning().Msgf("Could not reload templates: %s\n", err)
+				}
+			case <-reloader.stop:
+				return
+			}
+		}
+	}()
+}
+
+// reloadTemplates schedules the templates added to the template paths since
+// the scan started against all the input, returning the number of new templates.
+func (r *Runner) reloadTemplates() (int, error) {
+	reloader := r.reloader
+	reloader.mutex.Lock()
+	defer reloader.mutex.Unlock()
+	if !reloader.started {
+		return 0, errors.New("scan has not started")
+	}
+	if reloader.done {
+		return 0, errors.New("scan has finished")
+	}
+
+	templatePaths, workflowPaths := r.resolveTemplatePaths()
+	newTemplatePaths := reloader.unloaded(templatePaths)
+	newWorkflowPaths := reloader.unloaded(workflowPaths)
+	if len(newTemplatePaths) == 0 && len(newWorkflowPaths) == 0 {
+		return 0, nil
+	}
+
+	newTemplates, _ := r.getParsedTemplatesFor(newTemplatePaths, r.options.Severity, false)
+	newWorkflows, _ := r.getParsedTemplatesFor(newWorkflowPaths, r.options.Severity, true)
+	finalTemplates := make([]*templates.Template, 0, len(newTemplates)+len(newWorkflows))
+	for _, template := range newTemplates {
+		finalTemplates = append(finalTemplates, template)
+	}
+	for _, workflow := range newWorkflows {
+		finalTemplates = append(finalTemplates, workflow)
+	}
+	if len(finalTemplates) == 0 {
+		return 0, nil
+	}
+
+	var totalRequests int64
+	for _, template := range finalTemplates {
+		if len(template.Workflows) == 0 {
+			totalRequests += int64(template.TotalRequests) * r.inputCount
+		}
+	}
+	if err := reloader.schedule(finalTemplates, plannedRequests(finalTemplates, r.inputCount), int64(r.options.ConfirmThreshold), r.options.Yes); err != nil {
+		return 0, err
+	}
+	r.progress.AddToTotal(totalRequests)
+	gologger.Info().Msgf("Scheduling %d new templates", len(finalTemplates))
+	return len(finalTemplates), nil
+}
+
+// schedule checks the requests of the scan with the new templates against
+// the confirmation threshold and queues the templates on the running
+// scheduler, keeping them pending if it has no templates left.
+//
+// The caller must hold the reloader mutex.
+func (t *templateReloader) schedule(list []*templates.Template, requests, threshold int64, confirmed bool) error {
+	if err := confirmRequests(t.planned+requests, threshold, confirmed); err != nil {
+		return err
+	}
+	t.planned += requests
+	if !t.scheduler.add(list) {
+		t.pending = append(t.pending, list...)
+	}
+	return nil
+}
+
+// setScheduler sets the scheduler running the templates of the scan
+func (t *templateReloader) setScheduler(scheduler *fairScheduler) {
+	if t == nil {
+		return
+	}
+	t.mutex.Lock()
+	t.scheduler = scheduler
+	t.mutex.Unlock()
+}
+
+// unloaded returns the paths not loaded yet, marking them as loaded
+func (t *templateReloader) unloaded(paths []string) []string {
+	var unloaded []string
+	for _, path := range paths {
+		if _, ok := t.loaded[path]; ok {
+			continue
+		}
+		t.loaded[path] = struct{}{}
+		unloaded = append(unloaded, path)
+	}
+	return unloaded
+}
+
+// finish stops accepting new templates, returning the pending ones
+func (t *templateReloader) finish() []*templates.Template {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	if !t.done {
+		t.done = true
+		close(t.stop)
+	}
+	pending := t.pending
+	t.pending = nil
+	return pending
+}
+
+// waitTemplateReloads stops accepting new templates and runs the reloaded
+// templates the scheduler could not take, returning true if any results
+// were found.
+func (r *Runner) waitTemplateReloads() bool {
+	pending := r.reloader.finish()
+	if len(pending) == 0 {
+		return false
+	}
+	return r.executeTemplates(pending)
+}
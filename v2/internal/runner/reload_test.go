@@ -0,0 +1,36 @@
+package runner
+
+import (
+	"testing"
+
+	"github.com/projectdiscovery/nuclei/v2/pkg/templates"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTemplateReloaderSchedule(t *testing.T) {
+	reloader := newTemplateReloader()
+	reloader.planned = 50
+	scheduler := newFairScheduler([]*templates.Template{{ID: "panel"}}, 2)
+	reloader.setScheduler(scheduler)
+
+	err := reloader.schedule([]*templates.Template{{ID: "wordlist"}}, 100, 100, false)
+	require.NotNil(t, err, "could schedule templates over the confirmation threshold")
+	require.Equal(t, int64(50), reloader.planned, "could count requests of rejected templates")
+
+	err = reloader.schedule([]*templates.Template{{ID: "version"}}, 10, 100, false)
+	require.Nil(t, err, "could not schedule templates under the confirmation threshold")
+	require.Equal(t, int64(60), reloader.planned, "could not count requests of scheduled templates")
+	template, _ := scheduler.next()
+	require.Equal(t, "panel", template.ID, "could not run scan templates first")
+	template, _ = scheduler.next()
+	require.Equal(t, "version", template.ID, "could not add template to running scheduler")
+	template, _ = scheduler.next()
+	require.Nil(t, template, "could get template after all were scheduled")
+
+	err = reloader.schedule([]*templates.Template{{ID: "wordlist"}}, 100, 100, true)
+	require.Nil(t, err, "could not schedule confirmed templates")
+	pending := reloader.finish()
+	require.Len(t, pending, 1, "could not keep templates of finished scheduler pending")
+	require.Equal(t, "wordlist", pending[0].ID, "could not keep pending template")
+	require.Empty(t, reloader.finish(), "could return pending templates twice")
+}
@@ -0,0 +1,219 @@
+package runner
+
+import (
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/projectdiscovery/gologger"
+	"github.com/projectdiscovery/nuclei/v2/pkg/output"
+	"github.com/projectdiscovery/nuclei/v2/pkg/templates"
+)
+
+// dependencies tracks the hosts matched by the templates required by other
+// templates, so that the dependent templates only run on the hosts where
+// all of their required templates matched.
+type dependencies struct {
+	mutex sync.RWMutex
+	// scheduled are the ids of the templates scheduled in the scan
+	scheduled map[string]struct{}
+	// required are the ids of the templates required by other templates
+	required map[string]struct{}
+	// matched are the inputs matched by each required template
+	matched map[string]map[string]struct{}
+}
+
+func newDependencies() *dependencies {
+	return &dependencies{
+		scheduled: make(map[string]struct{}),
+		required:  make(map[string]struct{}),
+		matched:   make(map[string]map[string]struct{}),
+	}
+}
+
+// hasDependencies returns true if the template requires or is required by
+// other templates of the list. Such templates are not clustered as their
+// matches are tracked individually.
+func hasDependencies(template *templates.Template, required map[string]struct{}) bool {
+	if len(template.Requires) > 0 {
+		return true
+	}
+	_, ok := required[template.ID]
+	return ok
+}
+
+// storedNames returns the names of the values stored for the host by the
+// templates of the lists.
+func storedNames(lists ...map[string]*templates.Template) map[string]struct{} {
+	stored := make(map[string]struct{})
+	for _, list := range lists {
+		for _, template := range list {
+			for _, name := range template.StoredValues() {
+				stored[name] = struct{}{}
+			}
+		}
+	}
+	return stored
+}
+
+// sharesValues returns true if the template stores values for the host or
+// references stored values. Such templates are not clustered as they are
+// scheduled after the templates storing the values they reference.
+func sharesValues(template *templates.Template, stored map[string]struct{}) bool {
+	if len(template.StoredValues()) > 0 {
+		return true
+	}
+	for name := range template.References {
+		if _, ok := stored[name]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// requiredIDs returns the ids of the templates required by the templates of the lists
+func requiredIDs(lists ...map[string]*templates.Template) map[string]struct{} {
+	required := make(map[string]struct{})
+	for _, list := range lists {
+		for _, template := range list {
+			for _, id := range template.Requires {
+				required[id] = struct{}{}
+			}
+		}
+	}
+	return required
+}
+
+// schedule orders the templates in stages, each stage containing the
+// templates whose required templates and templates storing the values they
+// reference ran in the previous stages. Templates with circular requirements
+// are skipped, while circular references to stored values are ignored.
+func (d *dependencies) schedule(list []*templates.Template) [][]*templates.Template {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	byID := make(map[string]*templates.Template, len(list))
+	producers := make(map[string][]*templates.Template)
+	for _, template := range list {
+		byID[template.ID] = template
+		for _, name := range template.StoredValues() {
+			producers[name] = append(producers[name], template)
+		}
+	}
+	for _, template := range list {
+		for _, id := range template.Requires {
+			d.required[id] = struct{}{}
+			if _, ok := byID[id]; ok {
+				continue
+			}
+			if _, ok := d.scheduled[id]; !ok {
+				gologger.Warning().Msgf("[%s] Required template %s is not loaded, template will not run\n", template.ID, id)
+			}
+		}
+	}
+
+	// stages contains the stage of each template, -1 while it is visited
+	stages := make(map[string]int, len(list))
+	var stageOf func(template *templates.Template) (int, bool)
+	stageOf = func(template *templates.Template) (int, bool) {
+		if stage, ok := stages[template.ID]; ok {
+			return stage, stage != -1
+		}
+		stages[template.ID] = -1
+		stage := 0
+		for _, id := range template.Requires {
+			required, ok := byID[id]
+			if !ok {
+				continue
+			}
+			requiredStage, ok := stageOf(required)
+			if !ok {
+				return 0, false
+			}
+			if requiredStage+1 > stage {
+				stage = requiredStage + 1
+			}
+		}
+		for name := range template.References {
+			for _, producer := range producers[name] {
+				if producer == template || stages[producer.ID] == -1 {
+					continue
+				}
+				if producerStage, ok := stageOf(producer); ok && producerStage+1 > stage {
+					stage = producerStage + 1
+				}
+			}
+		}
+		stages[template.ID] = stage
+		return stage, true
+	}
+
+	var scheduled [][]*templates.Template
+	for _, template := range list {
+		stage, ok := stageOf(template)
+		if !ok {
+			gologger.Warning().Msgf("[%s] Circular template requirements (%s), template will not run\n", template.ID, strings.Join(template.Requires, ","))
+			continue
+		}
+		for len(scheduled) <= stage {
+			scheduled = append(scheduled, nil)
+		}
+		scheduled[stage] = append(scheduled[stage], template)
+		d.scheduled[template.ID] = struct{}{}
+	}
+	return scheduled
+}
+
+// matchResult records the match of the template and the workflow of a
+// result written for an input, including the out-of-band results.
+func (d *dependencies) matchResult(input string, result *output.ResultEvent) {
+	d.match(result.TemplateID, input)
+	if result.WorkflowID != "" {
+		d.match(result.WorkflowID, input)
+	}
+}
+
+// match records a match of a template on an input if it is required
+func (d *dependencies) match(templateID, input string) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	if _, ok := d.required[templateID]; !ok {
+		return
+	}
+	inputs, ok := d.matched[templateID]
+	if !ok {
+		inputs = make(map[string]struct{})
+		d.matched[templateID] = inputs
+	}
+	inputs[input] = struct{}{}
+}
+
+// unmet returns the sorted ids of the required templates of a template
+// that did not match an input.
+func (d *dependencies) unmet(template *templates.Template, input string) []string {
+	d.mutex.RLock()
+	defer d.mutex.RUnlock()
+	var unmet []string
+	for _, id := range template.Requires {
+		if _, ok := d.matched[id][input]; !ok {
+			unmet = append(unmet, id)
+		}
+	}
+	sort.Strings(unmet)
+	return unmet
+}
+
+// skipForRequires returns true if any of the templates required by the
+// template did not match the input.
+func (r *Runner) skipForRequires(template *templates.Template, URL string) bool {
+	if len(template.Requires) == 0 {
+		return false
+	}
+	unmet := r.dependencies.unmet(template, URL)
+	if len(unmet) == 0 {
+		return false
+	}
+	gologger.Verbose().Msgf("[%s] Skipping template for %s (required %s did not match)\n", template.ID, URL, strings.Join(unmet, ","))
+	r.progress.IncrementSkippedBy(int64(template.TotalRequests))
+	return true
+}
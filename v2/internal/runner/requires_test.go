@@ -0,0 +1,86 @@
+package runner
+
+import (
+	"testing"
+
+	"github.com/projectdiscovery/nuclei/v2/pkg/operators"
+	"github.com/projectdiscovery/nuclei/v2/pkg/operators/extractors"
+	"github.com/projectdiscovery/nuclei/v2/pkg/output"
+	"github.com/projectdiscovery/nuclei/v2/pkg/protocols/http"
+	"github.com/projectdiscovery/nuclei/v2/pkg/templates"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDependenciesSchedule(t *testing.T) {
+	list := []*templates.Template{
+		{ID: "use-token", Requires: []string{"get-token", "detect"}},
+		{ID: "get-token", Requires: []string{"detect"}},
+		{ID: "detect"},
+		{ID: "cycle-a", Requires: []string{"cycle-b"}},
+		{ID: "cycle-b", Requires: []string{"cycle-a"}},
+		{ID: "missing", Requires: []string{"not-loaded"}},
+	}
+	stages := newDependencies().schedule(list)
+
+	ids := make([][]string, 0, len(stages))
+	for _, stage := range stages {
+		var stageIDs []string
+		for _, template := range stage {
+			stageIDs = append(stageIDs, template.ID)
+		}
+		ids = append(ids, stageIDs)
+	}
+	require.Equal(t, [][]string{{"detect", "missing"}, {"get-token"}, {"use-token"}}, ids, "could not schedule templates")
+}
+
+func TestDependenciesScheduleStoredValues(t *testing.T) {
+	storing := func(id string, stored string, references ...string) *templates.Template {
+		template := &templates.Template{ID: id, References: make(map[string]struct{})}
+		if stored != "" {
+			template.RequestsHTTP = []*http.Request{{Operators: operators.Operators{Extractors: []*extractors.Extractor{{Name: stored, Store: true}}}}}
+		}
+		for _, name := range references {
+			template.References[name] = struct{}{}
+		}
+		return template
+	}
+	list := []*templates.Template{
+		storing("use-token", "", "token", "BaseURL"),
+		storing("login", "token"),
+		storing("loop-a", "a", "b"),
+		storing("loop-b", "b", "a"),
+	}
+	stages := newDependencies().schedule(list)
+
+	ids := make([][]string, 0, len(stages))
+	for _, stage := range stages {
+		var stageIDs []string
+		for _, template := range stage {
+			stageIDs = append(stageIDs, template.ID)
+		}
+		ids = append(ids, stageIDs)
+	}
+	require.Equal(t, [][]string{{"login", "loop-b"}, {"use-token", "loop-a"}}, ids, "could not schedule templates after the templates storing their values")
+
+	stored := storedNames(map[string]*templates.Template{"login": list[1]})
+	require.True(t, sharesValues(list[0], stored), "could not get template referencing stored value")
+	require.True(t, sharesValues(list[1], stored), "could not get template storing value")
+	require.False(t, sharesValues(storing("other", "", "BaseURL"), stored), "could get template without stored values")
+}
+
+func TestDependenciesUnmet(t *testing.T) {
+	detect := &templates.Template{ID: "detect"}
+	other := &templates.Template{ID: "other"}
+	dependent := &templates.Template{ID: "dependent", Requires: []string{"detect", "other"}}
+
+	deps := newDependencies()
+	deps.schedule([]*templates.Template{detect, other, dependent})
+	deps.matchResult("https://a.example.com", &output.ResultEvent{TemplateID: "detect"})
+	// The results of the workflow templates match the workflow
+	deps.matchResult("https://a.example.com", &output.ResultEvent{TemplateID: "sub-template", WorkflowID: "other"})
+	deps.matchResult("https://b.example.com", &output.ResultEvent{TemplateID: "detect"})
+
+	require.Empty(t, deps.unmet(dependent, "https://a.example.com"), "could not get met requirements")
+	require.Equal(t, []string{"other"}, deps.unmet(dependent, "https://b.example.com"), "could not get unmet requirements")
+	require.Equal(t, []string{"detect", "other"}, deps.unmet(dependent, "https://c.example.com"), "could not get unmatched host requirements")
+}
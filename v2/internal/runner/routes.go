@@ -0,0 +1,102 @@
+package runner
+
+import (
+	"bufio"
+	"os"
+	"strings"
+
+	jsoniter "github.com/json-iterator/go"
+	"github.com/pkg/errors"
+	"github.com/projectdiscovery/gologger"
+	"github.com/projectdiscovery/nuclei/v2/pkg/templates"
+	"github.com/projectdiscovery/nuclei/v2/pkg/types"
+)
+
+// targetRoute restricts the templates executed on a target to the ones
+// having any of the tags or matching any of the template paths.
+type targetRoute struct {
+	Host      string   `json:"host"`
+	Tags      []string `json:"tags,omitempty"`
+	Templates []string `json:"templates,omitempty"`
+
+	templatePaths map[string]struct{}
+}
+
+// loadTargetRoutes reads a JSON lines file of target routes adding the
+// targets to the input. The number of duplicate targets is returned.
+func (r *Runner) loadTargetRoutes(file string) (int, error) {
+	input, err := os.Open(file)
+	if err != nil {
+		return 0, err
+	}
+	defer input.Close()
+
+	if r.routes == nil {
+		r.routes = make(map[string]*targetRoute)
+	}
+
+	dupeCount := 0
+	scanner := bufio.NewScanner(input)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		route := &targetRoute{}
+		if err := jsoniter.UnmarshalFromString(line, route); err != nil {
+			return 0, errors.Wrap(err, "could not decode target route")
+		}
+		route.Host = strings.TrimSpace(route.Host)
+		if route.Host == "" {
+			return 0, errors.New("no host provided for target route")
+		}
+		route.templatePaths = make(map[string]struct{})
+		for _, path := range r.catalog.GetTemplatesPath(route.Templates, false) {
+			route.templatePaths[path] = struct{}{}
+		}
+		r.routes[route.Host] = route
+
+		if _, ok := r.hostMap.Get(route.Host); ok {
+			dupeCount++
+			continue
+		}
+		r.inputCount++
+		// nolint:errcheck // ignoring error
+		r.hostMap.Set(route.Host, nil)
+	}
+	return dupeCount, scanner.Err()
+}
+
+// skipForRoute returns true if the target has a route not matching the template
+func (r *Runner) skipForRoute(template *templates.Template, URL string) bool {
+	route, ok := r.routes[URL]
+	if !ok || route.matches(template) {
+		return false
+	}
+	gologger.Debug().Msgf("[%s] Skipping template not routed to %s\n", template.ID, URL)
+	r.progress.IncrementSkippedBy(int64(template.TotalRequests))
+	return true
+}
+
+// matches returns true if a template should be executed for the route
+func (route *targetRoute) matches(template *templates.Template) bool {
+	if len(route.Tags) == 0 && len(route.Templates) == 0 {
+		return true
+	}
+	if _, ok := route.templatePaths[template.Path]; ok {
+		return true
+	}
+	tags, ok := template.Info["tags"]
+	if !ok {
+		return false
+	}
+	for _, tag := range strings.Split(types.ToString(tags), ",") {
+		tag = strings.TrimSpace(tag)
+		for _, routeTag := range route.Tags {
+			if strings.EqualFold(tag, routeTag) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,28 @@
+package runner
+
+import (
+	"testing"
+
+	"github.com/projectdiscovery/nuclei/v2/pkg/templates"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTargetRouteMatches(t *testing.T) {
+	template := &templates.Template{
+		ID:   "wordpress-login",
+		Path: "/templates/exposed-panels/wordpress-login.yaml",
+		Info: map[string]interface{}{"tags": "panel, wordpress"},
+	}
+
+	route := &targetRoute{}
+	require.True(t, route.matches(template), "could not match route without filters")
+
+	route = &targetRoute{Tags: []string{"WordPress"}}
+	require.True(t, route.matches(template), "could not match route by tag")
+
+	route = &targetRoute{Tags: []string{"jira"}, templatePaths: map[string]struct{}{template.Path: {}}}
+	require.True(t, route.matches(template), "could not match route by template path")
+
+	route = &targetRoute{Tags: []string{"jira"}, Templates: []string{"cves/"}}
+	require.False(t, route.matches(template), "could match route with other tags")
+}
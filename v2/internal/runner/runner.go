@@ -2,31 +2,52 @@ package runner
 
 import (
 	"bufio"
+	"context"
 	"fmt"
+	"io/ioutil"
+	"net/http"
 	"os"
 	"path"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/logrusorgru/aurora"
+	"github.com/pkg/errors"
 	"github.com/projectdiscovery/gologger"
 	"github.com/projectdiscovery/hmap/store/hybrid"
 	"github.com/projectdiscovery/nuclei/v2/internal/colorizer"
 	"github.com/projectdiscovery/nuclei/v2/pkg/catalog"
+	"github.com/projectdiscovery/nuclei/v2/pkg/control"
+	"github.com/projectdiscovery/nuclei/v2/pkg/dashboard"
+	"github.com/projectdiscovery/nuclei/v2/pkg/distributed"
+	"github.com/projectdiscovery/nuclei/v2/pkg/input/apispec"
 	"github.com/projectdiscovery/nuclei/v2/pkg/output"
 	"github.com/projectdiscovery/nuclei/v2/pkg/progress"
 	"github.com/projectdiscovery/nuclei/v2/pkg/projectfile"
 	"github.com/projectdiscovery/nuclei/v2/pkg/protocols"
+	"github.com/projectdiscovery/nuclei/v2/pkg/protocols/common/auditlog"
+	"github.com/projectdiscovery/nuclei/v2/pkg/protocols/common/authprovider"
 	"github.com/projectdiscovery/nuclei/v2/pkg/protocols/common/clusterer"
+	"github.com/projectdiscovery/nuclei/v2/pkg/protocols/common/contextstore"
+	"github.com/projectdiscovery/nuclei/v2/pkg/protocols/common/csrf"
+	"github.com/projectdiscovery/nuclei/v2/pkg/protocols/common/globalmatchers"
 	"github.com/projectdiscovery/nuclei/v2/pkg/protocols/common/interactsh"
+	"github.com/projectdiscovery/nuclei/v2/pkg/protocols/common/profiler"
 	"github.com/projectdiscovery/nuclei/v2/pkg/protocols/common/protocolinit"
+	"github.com/projectdiscovery/nuclei/v2/pkg/protocols/common/responsestore"
+	"github.com/projectdiscovery/nuclei/v2/pkg/protocols/common/servicedetect"
+	"github.com/projectdiscovery/nuclei/v2/pkg/protocols/common/wafdetect"
 	"github.com/projectdiscovery/nuclei/v2/pkg/protocols/headless/engine"
+	"github.com/projectdiscovery/nuclei/v2/pkg/protocols/http/httpclientpool"
 	"github.com/projectdiscovery/nuclei/v2/pkg/reporting"
+	"github.com/projectdiscovery/nuclei/v2/pkg/reporting/enrichment"
 	"github.com/projectdiscovery/nuclei/v2/pkg/reporting/exporters/disk"
 	"github.com/projectdiscovery/nuclei/v2/pkg/reporting/exporters/sarif"
+	"github.com/projectdiscovery/nuclei/v2/pkg/reporting/triage"
 	"github.com/projectdiscovery/nuclei/v2/pkg/templates"
+	"github.com/projectdiscovery/nuclei/v2/pkg/tracing"
 	"github.com/projectdiscovery/nuclei/v2/pkg/types"
-	"github.com/remeh/sizedwaitgroup"
 	"github.com/rs/xid"
 	"go.uber.org/atomic"
 	"go.uber.org/ratelimit"
@@ -49,14 +70,63 @@ type Runner struct {
 	severityColors  *colorizer.Colorizer
 	browser         *engine.Browser
 	ratelimiter     ratelimit.Limiter
+	wafDetector     *wafdetect.Detector
+	serviceDetector *servicedetect.Detector
+	globalMatchers  *globalmatchers.Storage
+	tracer          *tracing.Tracer
+	responseStore   *responsestore.Store
+	profiler        *profiler.Profiler
+	scanMetadata    *output.ScanMetadata
+	worker          *distributed.Worker
+	stopped         *atomic.Bool
+	ctx             context.Context
+	cancel          context.CancelFunc
+	scanTimeout     time.Duration
+	scanTimedOut    *atomic.Bool
+	hostBudget      *hostBudget
+	budgetSkipped   *atomic.Int64
+	routes          map[string]*targetRoute
+	crawled         map[string]struct{}
+	inputRequests   *apispec.Store
+	authProvider    *authprovider.Provider
+	csrf            *csrf.Store
+	contextStore    *contextstore.Store
+	cookieJar       http.CookieJar
+	dependencies    *dependencies
+	triage          *triage.Rules
+	conditions      []*templates.Condition
+	auditLog        *auditlog.Logger
+	dashboard       *dashboard.Dashboard
+	limiter         *control.Limiter
+	control         *controlHandler
+	controlServer   *control.Server
+	reloader        *templateReloader
+	// seed is the seed of the random streams of the shuffling and jitter
+	seed int64
+	// shared is true if the protocol state is shared with other runners
+	shared bool
 }
 
 // New creates a new client for running enumeration process.
 func New(options *types.Options) (*Runner, error) {
+	return newRunner(options, nil, nil)
+}
+
+// newRunner creates a new runner, using the provided output writer and
+// progress instead of creating them from the options if not nil.
+func newRunner(options *types.Options, outputWriter output.Writer, progressTracker progress.Progress) (*Runner, error) {
 	runner := &Runner{
-		options: options,
-	}
-	if options.Headless {
+		options:        options,
+		globalMatchers: globalmatchers.New(),
+		contextStore:   contextstore.New(),
+		dependencies:   newDependencies(),
+		stopped:        &atomic.Bool{},
+		scanTimedOut:   &atomic.Bool{},
+		budgetSkipped:  &atomic.Int64{},
+		reloader:       newTemplateReloader(),
+	}
+	runner.ctx, runner.cancel = context.WithCancel(context.Background())
+	if options.Headless || options.Screenshot {
 		browser, err := engine.New(options)
 		if err != nil {
 			return nil, err
@@ -75,17 +145,15 @@ func New(options *types.Options) (*Runner, error) {
 	}
 	var reportingOptions *reporting.Options
 	if options.ReportingConfig != "" {
-		file, err := os.Open(options.ReportingConfig)
+		data, err := ioutil.ReadFile(options.ReportingConfig)
 		if err != nil {
 			gologger.Fatal().Msgf("Could not open reporting config file: %s\n", err)
 		}
 
 		reportingOptions = &reporting.Options{}
-		if parseErr := yaml.NewDecoder(file).Decode(reportingOptions); parseErr != nil {
-			file.Close()
+		if parseErr := yaml.Unmarshal(types.ExpandEnv(data), reportingOptions); parseErr != nil {
 			gologger.Fatal().Msgf("Could not parse reporting config file: %s\n", parseErr)
 		}
-		file.Close()
 	}
 	if options.DiskExportDirectory != "" {
 		if reportingOptions != nil {
@@ -103,7 +171,11 @@ func New(options *types.Options) (*Runner, error) {
 			reportingOptions.SarifExporter = &sarif.Options{File: options.SarifExport}
 		}
 	}
-	if reportingOptions != nil {
+	// The issues of workers are reported by the coordinator
+	if reportingOptions != nil && options.Worker == "" {
+		if options.GroupIssues {
+			reportingOptions.GroupByTemplate = true
+		}
 		if client, err := reporting.New(reportingOptions, options.ReportingDB); err != nil {
 			gologger.Fatal().Msgf("Could not create issue reporting client: %s\n", err)
 		} else {
@@ -120,6 +192,12 @@ func New(options *types.Options) (*Runner, error) {
 		runner.listAvailableTemplates()
 		os.Exit(0)
 	}
+	if options.Lint {
+		if !runner.lintTemplates() {
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
 
 	if (len(options.Templates) == 0 || !options.NewTemplates || (options.Targets == "" && !options.Stdin && options.Target == "")) && options.UpdateTemplates {
 		os.Exit(0)
@@ -181,23 +259,169 @@ func New(options *types.Options) (*Runner, error) {
 		input.Close()
 	}
 
+	// Handle target routes file
+	if options.TargetRoutes != "" {
+		routesDupeCount, err := runner.loadTargetRoutes(options.TargetRoutes)
+		if err != nil {
+			gologger.Fatal().Msgf("Could not read target routes file '%s': %s\n", options.TargetRoutes, err)
+		}
+		dupeCount += routesDupeCount
+	}
+
+	// Handle targets found by uncover
+	if options.Uncover {
+		uncoverDupeCount, err := runner.loadUncoverInput()
+		if err != nil {
+			gologger.Fatal().Msgf("Could not load uncover targets: %s\n", err)
+		}
+		dupeCount += uncoverDupeCount
+	}
+
+	// Handle nmap or masscan output file
+	if options.NmapInput != "" {
+		nmapDupeCount, err := runner.loadNmapInput(options.NmapInput)
+		if err != nil {
+			gologger.Fatal().Msgf("Could not read nmap input file '%s': %s\n", options.NmapInput, err)
+		}
+		dupeCount += nmapDupeCount
+	}
+
+	// Handle OpenAPI specification or Postman collection file
+	if options.APISpec != "" {
+		apiDupeCount, err := runner.loadAPISpecInput(options.APISpec)
+		if err != nil {
+			gologger.Fatal().Msgf("Could not read api specification file '%s': %s\n", options.APISpec, err)
+		}
+		dupeCount += apiDupeCount
+	}
+
+	// Handle proxy traffic export file
+	if options.TrafficInput != "" {
+		trafficDupeCount, err := runner.loadTrafficInput(options.TrafficInput)
+		if err != nil {
+			gologger.Fatal().Msgf("Could not read traffic file '%s': %s\n", options.TrafficInput, err)
+		}
+		dupeCount += trafficDupeCount
+	}
+
 	if dupeCount > 0 {
 		gologger.Info().Msgf("Supplied input was automatically deduplicated (%d removed).", dupeCount)
 	}
 
+	// Fan the targets across the ports if asked
+	if options.Ports != "" {
+		ports, err := parsePorts(options.Ports)
+		if err != nil {
+			gologger.Fatal().Msgf("Could not parse ports '%s': %s\n", options.Ports, err)
+		}
+		if err := runner.expandPorts(ports); err != nil {
+			gologger.Fatal().Msgf("Could not expand targets with ports: %s\n", err)
+		}
+	}
+
+	// Only keep the targets of the shard if asked
+	if options.Shard != "" {
+		index, total, err := parseShard(options.Shard)
+		if err != nil {
+			gologger.Fatal().Msgf("Could not parse shard '%s': %s\n", options.Shard, err)
+		}
+		if err := runner.filterShard(index, total); err != nil {
+			gologger.Fatal().Msgf("Could not shard targets: %s\n", err)
+		}
+		gologger.Info().Msgf("Running on %d targets of shard %d/%d", runner.inputCount, index, total)
+	}
+
+	// The rate limiter is created before the input discovery to limit its requests too.
+	// Its rate can be changed and the requests paused with the control socket
+	runner.limiter = control.NewLimiter(options.RateLimit)
+	runner.ratelimiter = runner.limiter
+
+	seed := int64(options.Seed)
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	runner.seed = seed
+	if options.Shuffle || options.Jitter > 0 {
+		gologger.Info().Msgf("Using random seed %d (use -seed to reproduce)", seed)
+	}
+	if options.Jitter > 0 {
+		runner.ratelimiter = &jitterLimiter{Limiter: runner.ratelimiter, max: time.Duration(options.Jitter) * time.Millisecond, random: newLockedRand(seed, "jitter")}
+	}
+
+	// The audit log is created first to record the input discovery requests
+	if options.AuditLog != "" {
+		auditLog, err := auditlog.New(options.AuditLog)
+		if err != nil {
+			gologger.Fatal().Msgf("Could not create audit log '%s': %s\n", options.AuditLog, err)
+		}
+		runner.auditLog = auditLog
+	}
+
+	// Discover the graphql operations of the targets for fuzzing templates if asked
+	if options.GraphQL {
+		runner.graphqlInput()
+	}
+
+	// Discover the urls of the targets for fuzzing templates if asked
+	if options.Crawl {
+		runner.crawlInput()
+	}
+
 	// Create the output file if asked
-	outputWriter, err := output.NewStandardWriter(!options.NoColor, options.NoMeta, options.JSON, options.Output, options.TraceLogFile)
-	if err != nil {
-		gologger.Fatal().Msgf("Could not create output file '%s': %s\n", options.Output, err)
+	if outputWriter == nil {
+		standardWriter, err := output.NewStandardWriter(!options.NoColor, options.NoMeta, options.JSON, options.Output, options.TraceLogFile, false, options.OutputBufferSize*1024*1024, options.Table, options.TableWidth)
+		if err != nil {
+			gologger.Fatal().Msgf("Could not create output file '%s': %s\n", options.Output, err)
+		}
+		// The findings are shown in the dashboard instead of the screen
+		if options.Dashboard {
+			standardWriter.DisableScreen()
+		}
+		outputWriter = standardWriter
 	}
 	runner.output = outputWriter
 
+	if options.Dashboard {
+		runner.dashboard = dashboard.New(os.Stdout, time.Second, outputWriter.Colorizer())
+		runner.output = dashboard.NewWriter(runner.output, runner.dashboard)
+	}
+
+	// Annotate the findings of CVE templates with the EPSS/KEV data if asked
+	if options.Enrich || options.UpdateEnrichment {
+		dataset, err := loadEnrichment(options)
+		if err != nil {
+			gologger.Fatal().Msgf("Could not load enrichment dataset: %s\n", err)
+		}
+		if options.Enrich {
+			runner.output = enrichment.NewWriter(runner.output, dataset)
+		}
+	}
+
+	// Stream the results to the coordinator if running as a worker
+	if options.Worker != "" {
+		worker, err := distributed.NewWorker(&distributed.WorkerOptions{Coordinator: options.Worker, Token: options.DistributedToken})
+		if err != nil {
+			gologger.Fatal().Msgf("Could not create distributed worker: %s\n", err)
+		}
+		runner.worker = worker
+		runner.output = distributed.NewWriter(runner.output, worker)
+	}
+
+	// Count the requests sent by each template if profiling
+	if options.ProfileTemplates {
+		runner.profiler = profiler.New()
+		runner.output = profiler.NewWriter(runner.output, runner.profiler)
+	}
+
 	// Creates the progress tracking object
-	var progressErr error
-	runner.progress, progressErr = progress.NewStatsTicker(options.StatsInterval, options.EnableProgressBar, options.Metrics, options.MetricsPort)
-	if progressErr != nil {
-		return nil, progressErr
+	if progressTracker == nil {
+		var progressErr error
+		progressTracker, progressErr = progress.NewStatsTicker(options.StatsInterval, options.EnableProgressBar, options.Metrics, options.MetricsPort)
+		if progressErr != nil {
+			return nil, progressErr
+		}
 	}
+	runner.progress = progressTracker
 
 	// create project file if requested or load existing one
 	if options.Project {
@@ -208,7 +432,24 @@ func New(options *types.Options) (*Runner, error) {
 		}
 	}
 
-	if !options.NoInteractsh {
+	conditions, err := templates.ParseConditions(options.TemplateConditions)
+	if err != nil {
+		return nil, err
+	}
+	runner.conditions = conditions
+
+	if options.TriageFile != "" {
+		rules, err := triage.New(options.TriageFile)
+		if err != nil {
+			gologger.Fatal().Msgf("Could not load triage file '%s': %s\n", options.TriageFile, err)
+		}
+		for _, suppression := range rules.Expired() {
+			gologger.Warning().Msgf("Suppression of %s expired on %s (%s)", suppression.Template, suppression.Expires, suppression.Reason)
+		}
+		runner.triage = rules
+	}
+
+	if !options.NoInteractsh && options.Coordinator == "" {
 		interactshClient, err := interactsh.New(&interactsh.Options{
 			ServerURL:      options.InteractshURL,
 			CacheSize:      int64(options.InteractionsCacheSize),
@@ -218,6 +459,8 @@ func New(options *types.Options) (*Runner, error) {
 			Output:         runner.output,
 			IssuesClient:   runner.issuesClient,
 			Progress:       runner.progress,
+			Triage:         runner.triage,
+			ResultHook:     runner.dependencies.matchResult,
 		})
 		if err != nil {
 			gologger.Error().Msgf("Could not create interactsh client: %s", err)
@@ -226,16 +469,85 @@ func New(options *types.Options) (*Runner, error) {
 		}
 	}
 
-	if options.RateLimit > 0 {
-		runner.ratelimiter = ratelimit.New(options.RateLimit)
-	} else {
-		runner.ratelimiter = ratelimit.NewUnlimited()
+	if options.Control {
+		runner.control = &controlHandler{runner: runner}
+		server, err := control.Listen(options.ControlSocket, runner.control)
+		if err != nil {
+			gologger.Fatal().Msgf("Could not create control socket: %s\n", err)
+		}
+		runner.controlServer = server
+		gologger.Info().Msgf("Listening for control commands on %s", options.ControlSocket)
+	}
+
+	if options.WafDetection {
+		wafOptions := wafdetect.DefaultOptions
+		wafOptions.Threshold = options.WafThreshold
+		runner.wafDetector = wafdetect.New(wafOptions)
+	}
+	if options.CSRF {
+		store, err := csrf.New(options.CSRFPatterns)
+		if err != nil {
+			gologger.Fatal().Msgf("Could not create csrf token store: %s\n", err)
+		}
+		runner.csrf = store
+	}
+	if options.ServiceDetection {
+		runner.serviceDetector = servicedetect.New(time.Duration(options.Timeout)*time.Second, runner.auditLog)
+	}
+
+	scanTimeout, err := parseBudget(options.ScanTimeout)
+	if err != nil {
+		return nil, err
+	}
+	runner.scanTimeout = scanTimeout
+	hostTimeout, err := parseBudget(options.HostTimeout)
+	if err != nil {
+		return nil, err
+	}
+	if hostTimeout > 0 {
+		runner.hostBudget = newHostBudget(hostTimeout)
+	}
+
+	if options.ReuseCookies {
+		cookieJar, err := httpclientpool.NewCookieJar()
+		if err != nil {
+			gologger.Fatal().Msgf("Could not create cookie jar: %s\n", err)
+		}
+		runner.cookieJar = cookieJar
+	}
+	// Headless templates also store the screenshots of their matches in it
+	if options.StoreResponse || options.StoreResponseAll || options.Screenshot {
+		store, err := responsestore.New(options.StoreResponseDir, options.StoreResponseAll)
+		if err != nil {
+			gologger.Fatal().Msgf("Could not create response store: %s\n", err)
+		}
+		store.ScreenshotsOnly = !options.StoreResponse && !options.StoreResponseAll
+		runner.responseStore = store
+	}
+
+	if options.OTLPEndpoint != "" || options.StatsDAddress != "" {
+		tracer, err := tracing.New(&tracing.Options{Endpoint: options.OTLPEndpoint, StatsDAddress: options.StatsDAddress})
+		if err != nil {
+			gologger.Error().Msgf("Could not create tracer: %s", err)
+		} else {
+			runner.tracer = tracer
+		}
+	}
+
+	// The login templates of dynamic secrets run the first time a matching host is scanned
+	if options.SecretsFile != "" {
+		provider, err := authprovider.New(options.SecretsFile, runner.loginTemplate)
+		if err != nil {
+			gologger.Fatal().Msgf("Could not load secrets file '%s': %s\n", options.SecretsFile, err)
+		}
+		runner.authProvider = provider
 	}
 	return runner, nil
 }
 
 // Close releases all the resources and cleans up
 func (r *Runner) Close() {
+	r.cancel()
 	if r.output != nil {
 		r.output.Close()
 	}
@@ -243,16 +555,103 @@ func (r *Runner) Close() {
 	if r.projectFile != nil {
 		r.projectFile.Close()
 	}
-	protocolinit.Close()
+	r.tracer.Close()
+	r.controlServer.Close()
+	if err := r.auditLog.Close(); err != nil {
+		gologger.Warning().Msgf("Could not close audit log: %s\n", err)
+	}
+	if !r.shared {
+		protocolinit.Close()
+	}
+}
+
+// Stop stops the execution of the remaining templates and targets,
+// cancelling the requests being executed.
+func (r *Runner) Stop() {
+	r.stopped.Store(true)
+	r.cancel()
+}
+
+// setInput replaces the input of the runner with a list of targets
+func (r *Runner) setInput(targets []string) error {
+	hm, err := hybrid.New(hybrid.DefaultDiskOptions)
+	if err != nil {
+		return err
+	}
+	r.hostMap.Close()
+	r.hostMap = hm
+
+	r.inputCount = 0
+	for _, target := range targets {
+		r.inputCount++
+		// nolint:errcheck // ignoring error
+		r.hostMap.Set(target, nil)
+	}
+	return nil
 }
 
 // RunEnumeration sets up the input layer for giving input nuclei.
 // binary and runs the actual enumeration
-func (r *Runner) RunEnumeration() {
+func (r *Runner) RunEnumeration() error {
 	defer r.Close()
 
-	// If we have no templates, run on whole template directory with provided tags
-	if len(r.options.Templates) == 0 && len(r.options.Workflows) == 0 && !r.options.NewTemplates && (len(r.options.Tags) > 0 || len(r.options.ExcludeTags) > 0) {
+	if r.options.Worker != "" {
+		r.runWorker()
+		return nil
+	}
+	if r.options.Takeover {
+		r.finishEnumeration(r.runTakeover())
+		return nil
+	}
+
+	allTemplates, workflowPaths := r.templatePaths()
+
+	if r.options.TemplateStats {
+		r.printTemplateStats(allTemplates, workflowPaths)
+		return nil
+	}
+	if r.options.Coordinator != "" {
+		r.runCoordinator(allTemplates, workflowPaths)
+		return nil
+	}
+
+	// The scan timeout starts before the templates are loaded for their
+	// executers to be cancelled at its deadline.
+	stopScanBudget := r.startScanBudget()
+	defer stopScanBudget()
+	finalTemplates, templateCount, totalRequests := r.loadTemplates(allTemplates, workflowPaths)
+
+	// 0 matches means no templates were found in directory
+	if templateCount == 0 {
+		return errors.New("no templates were found")
+	}
+	// Workflow requests are added to the progress while running, they are
+	// only estimated for the confirmation of the scan
+	planned := plannedRequests(finalTemplates, r.inputCount)
+	gologger.Info().Msgf("Planned requests: %d (%d templates, %d targets)", planned, templateCount, r.inputCount)
+	if !r.confirmScan(planned) {
+		return nil
+	}
+
+	// tracks global progress and captures stdout/stderr until p.Wait finishes
+	r.progress.Init(r.inputCount, templateCount, totalRequests)
+	r.writeScanMetadata(templateCount)
+
+	r.dashboard.Start()
+	r.startTemplateReloader(allTemplates, workflowPaths, planned)
+	results := r.executeTemplates(finalTemplates)
+	if r.waitTemplateReloads() {
+		results = true
+	}
+	r.finishEnumeration(results)
+	return nil
+}
+
+// templatePaths returns the paths of the templates and workflows to run
+// after applying the template and exclusion filters.
+func (r *Runner) templatePaths() ([]string, []string) {
+	// If we have no templates, run on whole template directory with provided tags or target routes
+	if len(r.options.Templates) == 0 && len(r.options.Workflows) == 0 && !r.options.NewTemplates && (len(r.options.Tags) > 0 || len(r.options.ExcludeTags) > 0 || len(r.routes) > 0 || r.options.NmapInput != "") {
 		r.options.Templates = append(r.options.Templates, r.options.TemplatesDirectory)
 	}
 	if r.options.NewTemplates {
@@ -262,6 +661,12 @@ func (r *Runner) RunEnumeration() {
 		}
 		r.options.Templates = append(r.options.Templates, templatesLoaded...)
 	}
+	return r.resolveTemplatePaths()
+}
+
+// resolveTemplatePaths returns the paths of the templates and workflows
+// of the options without the excluded templates.
+func (r *Runner) resolveTemplatePaths() ([]string, []string) {
 	includedTemplates := r.catalog.GetTemplatesPath(r.options.Templates, false)
 	excludedTemplates := r.catalog.GetTemplatesPath(r.options.ExcludedTemplates, true)
 	// defaults to all templates
@@ -283,12 +688,17 @@ func (r *Runner) RunEnumeration() {
 			}
 		}
 	}
+	workflowPaths := r.catalog.GetTemplatesPath(r.options.Workflows, false)
+	return allTemplates, workflowPaths
+}
 
+// loadTemplates parses and clusters the templates and workflows returning
+// the final templates to execute with total template and request counts.
+func (r *Runner) loadTemplates(templatePaths, workflowPaths []string) ([]*templates.Template, int, int64) {
 	// pre-parse all the templates, apply filters
 	finalTemplates := []*templates.Template{}
 
-	workflowPaths := r.catalog.GetTemplatesPath(r.options.Workflows, false)
-	availableTemplates, _ := r.getParsedTemplatesFor(allTemplates, r.options.Severity, false)
+	availableTemplates, _ := r.getParsedTemplatesFor(templatePaths, r.options.Severity, false)
 	availableWorkflows, workflowCount := r.getParsedTemplatesFor(workflowPaths, r.options.Severity, true)
 
 	var unclusteredRequests int64
@@ -303,9 +713,23 @@ func (r *Runner) RunEnumeration() {
 
 	originalTemplatesCount := len(availableTemplates)
 	clusterCount := 0
+
+	// Templates with requirements are not clustered as their matches are tracked per
+	// template, nor the templates verifying their findings as they are re-executed
+	// and the templates sharing stored values as they are scheduled in order.
+	required := requiredIDs(availableTemplates, availableWorkflows)
+	stored := storedNames(availableTemplates, availableWorkflows)
+	for key, template := range availableTemplates {
+		if hasDependencies(template, required) || isVerified(template) || sharesValues(template, stored) {
+			delete(availableTemplates, key)
+			finalTemplates = append(finalTemplates, template)
+		}
+	}
 	clusters := clusterer.Cluster(availableTemplates)
 	for _, cluster := range clusters {
-		if len(cluster) > 1 && !r.options.OfflineHTTP {
+		// Templates are not clustered with target routes as they are selected per target
+		// and when profiling as the statistics are recorded per template.
+		if len(cluster) > 1 && !r.options.OfflineHTTP && len(r.routes) == 0 && r.profiler == nil {
 			executerOpts := protocols.ExecuterOptions{
 				Output:       r.output,
 				Options:      r.options,
@@ -316,6 +740,21 @@ func (r *Runner) RunEnumeration() {
 				Browser:      r.browser,
 				ProjectFile:  r.projectFile,
 				Interactsh:   r.interactsh,
+				WafDetector:  r.wafDetector,
+
+				GlobalMatchers: r.globalMatchers,
+				Tracer:         r.tracer,
+				ResponseStore:  r.responseStore,
+				Profiler:       r.profiler,
+				InputRequests:  r.inputRequests,
+				AuthProvider:   r.authProvider,
+				CSRF:           r.csrf,
+				Triage:         r.triage,
+				AuditLog:       r.auditLog,
+				ContextStore:   r.contextStore,
+				CookieJar:      r.cookieJar,
+				Context:        r.ctx,
+				ResultHook:     r.dependencies.matchResult,
 			}
 			clusterID := fmt.Sprintf("cluster-%s", xid.New().String())
 
@@ -346,53 +785,99 @@ func (r *Runner) RunEnumeration() {
 	}
 	templateCount := originalTemplatesCount + len(availableWorkflows)
 
-	// 0 matches means no templates were found in directory
-	if templateCount == 0 {
-		gologger.Fatal().Msgf("Error, no templates were found.\n")
-	}
-
 	gologger.Info().Msgf("Using %s rules (%s templates, %s workflows)",
 		r.colorizer.Bold(templateCount).String(),
 		r.colorizer.Bold(templateCount-workflowCount).String(),
 		r.colorizer.Bold(workflowCount).String())
 
-	results := &atomic.Bool{}
-	wgtemplates := sizedwaitgroup.New(r.options.TemplateThreads)
+	return finalTemplates, templateCount, totalRequests
+}
 
-	// tracks global progress and captures stdout/stderr until p.Wait finishes
-	r.progress.Init(r.inputCount, templateCount, totalRequests)
+// isVerified returns true if the findings of a template are verified by
+// re-executing it for each input.
+func isVerified(template *templates.Template) bool {
+	executer, ok := template.Executer.(interface{ Verified() bool })
+	return ok && executer.Verified()
+}
 
-	for _, t := range finalTemplates {
-		wgtemplates.Add()
-		go func(template *templates.Template) {
-			defer wgtemplates.Done()
+// executeTemplates executes the templates on the input returning true if
+// any results were found.
+func (r *Runner) executeTemplates(finalTemplates []*templates.Template) bool {
+	results := &atomic.Bool{}
 
+	// The templates run in stages so that the required templates
+	// have matched before the templates requiring them run.
+	stages := r.dependencies.schedule(finalTemplates)
+	for i, stage := range stages {
+		// The out-of-band results of the previous stage are required
+		// to run the templates requiring them.
+		if i > 0 && r.interactsh != nil {
+			r.interactsh.WaitPending()
+		}
+		if r.options.Shuffle {
+			newStream(r.seed, "stage-"+strconv.Itoa(i)).Shuffle(len(stage), func(i, j int) {
+				stage[i], stage[j] = stage[j], stage[i]
+			})
+		}
+		scheduler := newFairScheduler(stage, r.options.TemplateThreads)
+		r.reloader.setScheduler(scheduler)
+		scheduler.run(r.options.TemplateThreads, func(template *templates.Template) {
+			if r.stopped.Load() {
+				return
+			}
 			if len(template.Workflows) > 0 {
 				results.CAS(false, r.processWorkflowWithList(template))
 			} else {
 				results.CAS(false, r.processTemplateWithList(template))
 			}
-		}(t)
+		})
+		r.reloader.setScheduler(nil)
+	}
+	return results.Load()
+}
+
+// writeResult writes a result found outside of the template executers to
+// the output and issue tracker, returning false if it was suppressed.
+func (r *Runner) writeResult(event *output.ResultEvent) bool {
+	if !r.triage.Apply(event) {
+		return false
 	}
-	wgtemplates.Wait()
+	if r.issuesClient != nil {
+		if err := r.issuesClient.CreateIssue(event); err != nil {
+			gologger.Warning().Msgf("Could not create issue on tracker: %s", err)
+		}
+	}
+	_ = r.output.Write(event)
+	r.progress.IncrementMatched()
+	return true
+}
 
+// finishEnumeration waits for pending interactions and closes the scan
+func (r *Runner) finishEnumeration(results bool) {
 	if r.interactsh != nil {
 		matched := r.interactsh.Close()
 		if matched {
-			results.CAS(false, true)
+			results = true
 		}
 	}
+	r.dashboard.Stop()
 	r.progress.Stop()
+	r.finishScanMetadata()
 
 	if r.issuesClient != nil {
 		r.issuesClient.Close()
 	}
-	if !results.Load() {
+	if !results {
 		gologger.Info().Msgf("No results found. Better luck next time!")
 	}
 	if r.browser != nil {
 		r.browser.Close()
 	}
+	r.reportBudget()
+	if r.profiler != nil {
+		gologger.Info().Msgf("Template profile sorted by time spent:")
+		r.profiler.Print(os.Stderr)
+	}
 }
 
 // readNewTemplatesFile reads newly added templates from directory if it exists
@@ -0,0 +1,103 @@
+package runner
+
+import (
+	"os"
+	"path"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/projectdiscovery/gologger"
+	"github.com/projectdiscovery/nuclei/v2/pkg/output"
+	"github.com/projectdiscovery/nuclei/v2/pkg/reporting/dedupe"
+	"github.com/projectdiscovery/nuclei/v2/pkg/schedule"
+	"github.com/projectdiscovery/nuclei/v2/pkg/types"
+	"go.uber.org/atomic"
+)
+
+// RunScheduled reruns the scan described by the options on the cron schedule,
+// only writing and reporting findings not seen in any of the previous runs.
+func RunScheduled(options *types.Options) error {
+	cron, err := schedule.Parse(options.Schedule)
+	if err != nil {
+		return err
+	}
+	if options.ScheduleState == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return errors.Wrap(err, "could not get home directory")
+		}
+		options.ScheduleState = path.Join(home, ".config", "nuclei", "schedule")
+	}
+	if err := os.MkdirAll(options.ScheduleState, os.ModePerm); err != nil {
+		return errors.Wrap(err, "could not create schedule state directory")
+	}
+	// Persist the reporting deduplication across runs so only new findings are notified
+	if options.ReportingDB == "" {
+		options.ReportingDB = path.Join(options.ScheduleState, "reporting")
+	}
+
+	for {
+		next := cron.Next(time.Now())
+		if next.IsZero() {
+			return errors.New("no next run found for schedule")
+		}
+		gologger.Info().Msgf("Next scheduled scan at %s", next.Format(time.RFC1123))
+		time.Sleep(time.Until(next))
+
+		if err := runScheduledScan(options); err != nil {
+			gologger.Error().Msgf("Could not run scheduled scan: %s\n", err)
+		}
+	}
+}
+
+// runScheduledScan runs a single scan of the schedule
+func runScheduledScan(options *types.Options) error {
+	seen, err := dedupe.New(path.Join(options.ScheduleState, "findings"))
+	if err != nil {
+		return errors.Wrap(err, "could not open seen findings")
+	}
+	// The findings of the previous runs are kept in the output files
+	standardWriter, err := output.NewStandardWriter(!options.NoColor, options.NoMeta, options.JSON, options.Output, options.TraceLogFile, true, options.OutputBufferSize*1024*1024, options.Table, options.TableWidth)
+	if err != nil {
+		seen.Close()
+		return errors.Wrap(err, "could not create output writer")
+	}
+	writer := &newFindingsWriter{Writer: standardWriter, seen: seen, count: &atomic.Int64{}}
+
+	scanOptions := *options
+	runner, err := newRunner(&scanOptions, writer, nil)
+	if err != nil {
+		writer.Close()
+		return err
+	}
+	runner.shared = true
+	if err := runner.RunEnumeration(); err != nil {
+		return err
+	}
+
+	gologger.Info().Msgf("Scheduled scan finished with %d new findings", writer.count.Load())
+	return nil
+}
+
+// newFindingsWriter is an output writer only writing findings not seen before
+type newFindingsWriter struct {
+	output.Writer
+	seen  *dedupe.Storage
+	count *atomic.Int64
+}
+
+// Write writes the event if it was not seen in a previous scan
+func (w *newFindingsWriter) Write(event *output.ResultEvent) error {
+	unique, err := w.seen.Index(event)
+	if err == nil && !unique {
+		return nil
+	}
+	w.count.Inc()
+	return w.Writer.Write(event)
+}
+
+// Close closes the wrapped writer and the seen findings storage
+func (w *newFindingsWriter) Close() {
+	w.Writer.Close()
+	w.seen.Close()
+}
@@ -0,0 +1,112 @@
+package runner
+
+import (
+	"sync"
+
+	"github.com/projectdiscovery/nuclei/v2/pkg/templates"
+)
+
+// heavyTemplateRequests is the number of requests per host above which a
+// template is scheduled as a heavy template.
+const heavyTemplateRequests = 100
+
+// fairScheduler runs the templates on a fixed number of workers, reserving
+// half of them for the light templates so that payload heavy templates
+// can't monopolize the workers while the one-request templates wait. The
+// heavy templates use all the workers once no light template is left.
+type fairScheduler struct {
+	mutex        sync.Mutex
+	light        []*templates.Template
+	heavy        []*templates.Template
+	heavyRunning int
+	maxHeavy     int
+	finished     bool
+}
+
+// newFairScheduler creates a scheduler for the templates in their order
+func newFairScheduler(list []*templates.Template, workers int) *fairScheduler {
+	scheduler := &fairScheduler{maxHeavy: workers / 2}
+	scheduler.queue(list)
+	return scheduler
+}
+
+// add queues templates on a running scheduler, returning false if the
+// scheduler is nil or all its templates have already been scheduled.
+func (s *fairScheduler) add(list []*templates.Template) bool {
+	if s == nil {
+		return false
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.finished {
+		return false
+	}
+	s.queue(list)
+	return true
+}
+
+// queue appends the templates to the light or heavy queue
+func (s *fairScheduler) queue(list []*templates.Template) {
+	for _, template := range list {
+		if template.TotalRequests > heavyTemplateRequests {
+			s.heavy = append(s.heavy, template)
+		} else {
+			s.light = append(s.light, template)
+		}
+	}
+}
+
+// next returns the next template to run and whether it is heavy, nil once
+// all the templates have been scheduled.
+func (s *fairScheduler) next() (*templates.Template, bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if len(s.heavy) > 0 && (s.heavyRunning < s.maxHeavy || len(s.light) == 0) {
+		template := s.heavy[0]
+		s.heavy = s.heavy[1:]
+		s.heavyRunning++
+		return template, true
+	}
+	if len(s.light) > 0 {
+		template := s.light[0]
+		s.light = s.light[1:]
+		return template, false
+	}
+	s.finished = true
+	return nil, false
+}
+
+// done marks a template returned by next as finished
+func (s *fairScheduler) done(heavy bool) {
+	if !heavy {
+		return
+	}
+	s.mutex.Lock()
+	s.heavyRunning--
+	s.mutex.Unlock()
+}
+
+// run executes the templates on the workers, returning once all of them finished
+func (s *fairScheduler) run(workers int, execute func(template *templates.Template)) {
+	if workers < 1 {
+		workers = 1
+	}
+	wg := &sync.WaitGroup{}
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				template, heavy := s.next()
+				if template == nil {
+					return
+				}
+				execute(template)
+				s.done(heavy)
+			}
+		}()
+	}
+	wg.Wait()
+}
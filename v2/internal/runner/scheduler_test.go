@@ -0,0 +1,68 @@
+package runner
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/projectdiscovery/nuclei/v2/pkg/templates"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFairSchedulerOrder(t *testing.T) {
+	list := []*templates.Template{
+		{ID: "wordlist-1", TotalRequests: 100000},
+		{ID: "wordlist-2", TotalRequests: 5000},
+		{ID: "panel", TotalRequests: 1},
+		{ID: "version", TotalRequests: 2},
+	}
+	scheduler := newFairScheduler(list, 2)
+
+	template, heavy := scheduler.next()
+	require.Equal(t, "wordlist-1", template.ID, "could not start heavy template")
+	require.True(t, heavy, "could not get heavy template")
+	template, heavy = scheduler.next()
+	require.Equal(t, "panel", template.ID, "could not reserve worker for light templates")
+	require.False(t, heavy, "could get light template as heavy")
+
+	scheduler.done(false)
+	template, _ = scheduler.next()
+	require.Equal(t, "version", template.ID, "could start heavy template over light one")
+
+	template, _ = scheduler.next()
+	require.Equal(t, "wordlist-2", template.ID, "could not run heavy templates without light ones")
+	template, _ = scheduler.next()
+	require.Nil(t, template, "could get template after all were scheduled")
+}
+
+func TestFairSchedulerRun(t *testing.T) {
+	var list []*templates.Template
+	for i := 0; i < 50; i++ {
+		list = append(list, &templates.Template{TotalRequests: i * 10})
+	}
+
+	mutex := &sync.Mutex{}
+	var executed int
+	newFairScheduler(list, 4).run(4, func(template *templates.Template) {
+		mutex.Lock()
+		executed++
+		mutex.Unlock()
+	})
+	require.Equal(t, len(list), executed, "could not run all templates")
+}
+
+func TestFairSchedulerAdd(t *testing.T) {
+	scheduler := newFairScheduler([]*templates.Template{{ID: "panel", TotalRequests: 1}}, 2)
+
+	require.True(t, scheduler.add([]*templates.Template{{ID: "wordlist", TotalRequests: 1000}}), "could not add template to running scheduler")
+	template, heavy := scheduler.next()
+	require.Equal(t, "wordlist", template.ID, "could not schedule added template")
+	require.True(t, heavy, "could not get added heavy template")
+	template, _ = scheduler.next()
+	require.Equal(t, "panel", template.ID, "could not keep scheduled template")
+	template, _ = scheduler.next()
+	require.Nil(t, template, "could get template after all were scheduled")
+
+	require.False(t, scheduler.add([]*templates.Template{{ID: "version"}}), "could add template to finished scheduler")
+	var nilScheduler *fairScheduler
+	require.False(t, nilScheduler.add([]*templates.Template{{ID: "version"}}), "could add template to nil scheduler")
+}
@@ -0,0 +1,21 @@
+package runner
+
+import (
+	"os"
+
+	"github.com/pkg/errors"
+	"github.com/projectdiscovery/nuclei/v2/pkg/templates/schema"
+)
+
+// WriteTemplateSchema writes the JSON Schema of the templates to stdout
+func WriteTemplateSchema() error {
+	data, err := schema.Generate()
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+	if _, err := os.Stdout.Write(data); err != nil {
+		return errors.Wrap(err, "could not write template schema")
+	}
+	return nil
+}
@@ -0,0 +1,523 @@
+package runner
+
+import (
+	"context"
+	"crypto/subtle"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	jsoniter "github.com/json-iterator/go"
+	"github.com/logrusorgru/aurora"
+	"github.com/projectdiscovery/goflags"
+	"github.com/projectdiscovery/gologger"
+	"github.com/projectdiscovery/nuclei/v2/pkg/output"
+	"github.com/projectdiscovery/nuclei/v2/pkg/progress"
+	"github.com/projectdiscovery/nuclei/v2/pkg/types"
+	"github.com/rs/xid"
+	"go.uber.org/atomic"
+)
+
+// Scan states reported by the api server
+const (
+	scanRunning   = "running"
+	scanFinished  = "finished"
+	scanCancelled = "cancelled"
+	scanFailed    = "failed"
+)
+
+// Retention of the finished scans and their results by the api server
+var (
+	// finishedScanTTL is the duration the finished scans are kept for
+	finishedScanTTL = 24 * time.Hour
+	// maxFinishedScans is the number of finished scans kept, the oldest ones are evicted first
+	maxFinishedScans = 100
+)
+
+// Server is a REST api server for submitting and managing scans
+type Server struct {
+	options *types.Options
+
+	mutex sync.RWMutex
+	scans map[string]*scan
+}
+
+// ScanRequest is a request to start a new scan
+type ScanRequest struct {
+	Targets           []string `json:"targets"`
+	Templates         []string `json:"templates,omitempty"`
+	Workflows         []string `json:"workflows,omitempty"`
+	ExcludedTemplates []string `json:"exclude_templates,omitempty"`
+	Tags              []string `json:"tags,omitempty"`
+	ExcludeTags       []string `json:"exclude_tags,omitempty"`
+	Severity          []string `json:"severity,omitempty"`
+	RateLimit         int      `json:"rate_limit,omitempty"`
+	BulkSize          int      `json:"bulk_size,omitempty"`
+	Concurrency       int      `json:"concurrency,omitempty"`
+}
+
+// ScanStatus is the status of a scan reported by the api server
+type ScanStatus struct {
+	ID         string           `json:"id"`
+	State      string           `json:"state"`
+	Error      string           `json:"error,omitempty"`
+	StartedAt  time.Time        `json:"started_at"`
+	FinishedAt *time.Time       `json:"finished_at,omitempty"`
+	Progress   map[string]int64 `json:"progress"`
+}
+
+// scan is a scan submitted to the api server
+type scan struct {
+	id       string
+	runner   *Runner
+	progress *scanProgress
+	started  time.Time
+
+	mutex    sync.Mutex
+	state    string
+	err      string
+	finished time.Time
+	results  []*output.ResultEvent
+	// updated is closed and replaced when new results are stored
+	// or the scan is finished, waking up the result streams.
+	updated chan struct{}
+}
+
+// RunServer runs the REST api server until the process is stopped
+func RunServer(options *types.Options) error {
+	server := &Server{options: options, scans: make(map[string]*scan)}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/scans", server.authorize(server.handleScans))
+	mux.HandleFunc("/api/v1/scans/", server.authorize(server.handleScan))
+
+	if options.GRPCAddress != "" {
+		go func() {
+			if err := server.serveGRPC(options.GRPCAddress); err != nil {
+				gologger.Fatal().Msgf("Could not run grpc server: %s\n", err)
+			}
+		}()
+	}
+	gologger.Info().Msgf("Listening for scan requests on %s", options.ServerAddress)
+	return http.ListenAndServe(options.ServerAddress, mux)
+}
+
+// authorize validates the token of api requests if one is configured
+func (s *Server) authorize(handler http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		if !s.validToken(req.Header.Get("Authorization")) {
+			writeError(w, http.StatusUnauthorized, "invalid token")
+			return
+		}
+		handler(w, req)
+	}
+}
+
+// validToken returns true if the authorization value of a request carries
+// the server token if any
+func (s *Server) validToken(authorization string) bool {
+	if s.options.ServerToken == "" {
+		return true
+	}
+	token := strings.TrimPrefix(authorization, "Bearer ")
+	return subtle.ConstantTimeCompare([]byte(token), []byte(s.options.ServerToken)) == 1
+}
+
+// handleScans lists the scans or submits a new scan
+func (s *Server) handleScans(w http.ResponseWriter, req *http.Request) {
+	switch req.Method {
+	case http.MethodGet:
+		s.mutex.RLock()
+		statuses := make([]*ScanStatus, 0, len(s.scans))
+		for _, scan := range s.scans {
+			statuses = append(statuses, scan.status())
+		}
+		s.mutex.RUnlock()
+		writeJSON(w, http.StatusOK, statuses)
+	case http.MethodPost:
+		data, err := ioutil.ReadAll(req.Body)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		request := &ScanRequest{}
+		if err := jsoniter.Unmarshal(data, request); err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("could not decode scan request: %s", err))
+			return
+		}
+		scan, err := s.startScan(request)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		writeJSON(w, http.StatusCreated, scan.status())
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+	}
+}
+
+// handleScan returns the status, results or cancels a single scan
+func (s *Server) handleScan(w http.ResponseWriter, req *http.Request) {
+	parts := strings.Split(strings.TrimPrefix(req.URL.Path, "/api/v1/scans/"), "/")
+
+	s.mutex.RLock()
+	scan, ok := s.scans[parts[0]]
+	s.mutex.RUnlock()
+	if !ok {
+		writeError(w, http.StatusNotFound, "scan not found")
+		return
+	}
+
+	switch {
+	case len(parts) == 1 && req.Method == http.MethodGet:
+		writeJSON(w, http.StatusOK, scan.status())
+	case len(parts) == 1 && req.Method == http.MethodDelete:
+		scan.cancel()
+		writeJSON(w, http.StatusOK, scan.status())
+	case len(parts) == 2 && parts[1] == "results" && req.Method == http.MethodGet:
+		if strings.Contains(req.Header.Get("Accept"), "text/event-stream") || req.URL.Query().Get("stream") == "true" {
+			scan.streamResults(w, req)
+			return
+		}
+		scan.mutex.Lock()
+		results := append([]*output.ResultEvent{}, scan.results...)
+		scan.mutex.Unlock()
+		writeJSON(w, http.StatusOK, results)
+	default:
+		writeError(w, http.StatusNotFound, "not found")
+	}
+}
+
+// startScan creates a runner for a scan request and starts executing it
+func (s *Server) startScan(request *ScanRequest) (*scan, error) {
+	if len(request.Targets) == 0 {
+		return nil, fmt.Errorf("no targets provided")
+	}
+	if len(request.Templates) == 0 && len(request.Workflows) == 0 && len(request.Tags) == 0 {
+		return nil, fmt.Errorf("no templates, workflows or tags provided")
+	}
+
+	options := *s.options
+	options.Target, options.Targets, options.Stdin = "", "", false
+	options.Templates = goflags.StringSlice(request.Templates)
+	options.Workflows = goflags.StringSlice(request.Workflows)
+	options.ExcludedTemplates = goflags.StringSlice(request.ExcludedTemplates)
+	options.Tags = goflags.StringSlice(request.Tags)
+	options.ExcludeTags = goflags.StringSlice(request.ExcludeTags)
+	options.Severity = goflags.StringSlice(request.Severity)
+	if request.RateLimit > 0 {
+		options.RateLimit = request.RateLimit
+	}
+	if request.BulkSize > 0 {
+		options.BulkSize = request.BulkSize
+	}
+	if request.Concurrency > 0 {
+		options.TemplateThreads = request.Concurrency
+	}
+
+	scan := &scan{
+		id:       xid.New().String(),
+		progress: &scanProgress{},
+		started:  time.Now(),
+		state:    scanRunning,
+		updated:  make(chan struct{}),
+	}
+	runner, err := newRunner(&options, &scanWriter{scan: scan}, scan.progress)
+	if err != nil {
+		return nil, err
+	}
+	runner.shared = true
+	if err := runner.setInput(request.Targets); err != nil {
+		runner.Close()
+		return nil, err
+	}
+	scan.runner = runner
+
+	s.mutex.Lock()
+	s.evictScans(time.Now())
+	s.scans[scan.id] = scan
+	s.mutex.Unlock()
+
+	go scan.run()
+	return scan, nil
+}
+
+// evictScans removes the scans finished more than the retention ago and
+// the oldest finished scans above the maximum count along with their
+// results. The caller must hold the write lock of the server.
+func (s *Server) evictScans(now time.Time) {
+	var finished []*scan
+	for id, scan := range s.scans {
+		finishedAt, ok := scan.finishedAt()
+		if !ok {
+			continue
+		}
+		if now.Sub(finishedAt) > finishedScanTTL {
+			delete(s.scans, id)
+			continue
+		}
+		finished = append(finished, scan)
+	}
+	if len(finished) <= maxFinishedScans {
+		return
+	}
+	sort.Slice(finished, func(i, j int) bool {
+		first, _ := finished[i].finishedAt()
+		second, _ := finished[j].finishedAt()
+		return first.Before(second)
+	})
+	for _, scan := range finished[:len(finished)-maxFinishedScans] {
+		delete(s.scans, scan.id)
+	}
+}
+
+// run executes the scan with its runner
+func (s *scan) run() {
+	defer s.runner.Close()
+
+	var err error
+	templatePaths, workflowPaths := s.runner.templatePaths()
+	finalTemplates, templateCount, totalRequests := s.runner.loadTemplates(templatePaths, workflowPaths)
+	if templateCount == 0 {
+		err = fmt.Errorf("no templates were found")
+	} else {
+		s.runner.progress.Init(s.runner.inputCount, templateCount, totalRequests)
+		s.runner.finishEnumeration(s.runner.executeTemplates(finalTemplates))
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	switch {
+	case err != nil:
+		s.state, s.err = scanFailed, err.Error()
+	case s.runner.stopped.Load():
+		s.state = scanCancelled
+	default:
+		s.state = scanFinished
+	}
+	s.finished = time.Now()
+	s.notify()
+}
+
+// finishedAt returns the time the scan finished at, false while it runs
+func (s *scan) finishedAt() (time.Time, bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.finished, !s.finished.IsZero()
+}
+
+// cancel stops the execution of the scan, aborting the running requests
+func (s *scan) cancel() {
+	s.runner.Stop()
+}
+
+// status returns the current status of the scan
+func (s *scan) status() *ScanStatus {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	status := &ScanStatus{ID: s.id, State: s.state, Error: s.err, StartedAt: s.started, Progress: s.progress.snapshot()}
+	if !s.finished.IsZero() {
+		finished := s.finished
+		status.FinishedAt = &finished
+	}
+	return status
+}
+
+// addResult stores a result and wakes up the result streams
+func (s *scan) addResult(event *output.ResultEvent) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.results = append(s.results, event)
+	s.notify()
+}
+
+// notify wakes up the result streams. The caller must hold the lock of the scan.
+func (s *scan) notify() {
+	close(s.updated)
+	s.updated = make(chan struct{})
+}
+
+// nextResults returns the results stored after the index, waiting for new
+// results while the scan is running. It returns false once the scan is
+// finished and all of its results were returned or the context is done.
+func (s *scan) nextResults(ctx context.Context, index int) ([]*output.ResultEvent, bool) {
+	for {
+		s.mutex.Lock()
+		results := append([]*output.ResultEvent{}, s.results[index:]...)
+		finished := !s.finished.IsZero()
+		updated := s.updated
+		s.mutex.Unlock()
+
+		if len(results) > 0 {
+			return results, true
+		}
+		if finished {
+			return nil, false
+		}
+		select {
+		case <-updated:
+		case <-ctx.Done():
+			return nil, false
+		}
+	}
+}
+
+// streamResults streams the results of the scan as server-sent events
+// until the scan is finished or the client disconnects.
+func (s *scan) streamResults(w http.ResponseWriter, req *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, http.StatusInternalServerError, "streaming not supported")
+		return
+	}
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	var index int
+	for {
+		results, ok := s.nextResults(req.Context(), index)
+		if !ok {
+			break
+		}
+		for _, event := range results {
+			writeEvent(w, "result", event)
+		}
+		index += len(results)
+		flusher.Flush()
+	}
+	if req.Context().Err() == nil {
+		writeEvent(w, "status", s.status())
+		flusher.Flush()
+	}
+}
+
+// writeEvent writes a server-sent event with json data
+func writeEvent(w http.ResponseWriter, name string, value interface{}) {
+	data, err := jsoniter.Marshal(value)
+	if err != nil {
+		return
+	}
+	_, _ = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", name, data)
+}
+
+// writeJSON writes a json response with a status code
+func writeJSON(w http.ResponseWriter, code int, value interface{}) {
+	data, err := jsoniter.Marshal(value)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_, _ = w.Write(data)
+}
+
+// writeError writes a json error response with a status code
+func writeError(w http.ResponseWriter, code int, message string) {
+	writeJSON(w, code, map[string]string{"error": message})
+}
+
+// scanWriter is an output writer storing the results of a scan
+type scanWriter struct {
+	scan *scan
+}
+
+var _ output.Writer = &scanWriter{}
+
+// Close closes the output writer interface
+func (w *scanWriter) Close() {}
+
+// Colorizer returns the colorizer instance for writer
+func (w *scanWriter) Colorizer() aurora.Aurora {
+	return aurora.NewAurora(false)
+}
+
+// Write stores the event in the scan results
+func (w *scanWriter) Write(event *output.ResultEvent) error {
+	event.Timestamp = time.Now()
+	w.scan.addResult(event)
+	return nil
+}
+
+// Request logs a request in the trace log
+func (w *scanWriter) Request(templateID, url, requestType string, err error) {}
+
+// scanProgress is a progress tracker recording the counters of a scan
+type scanProgress struct {
+	hosts     atomic.Int64
+	templates atomic.Int64
+	total     atomic.Int64
+	requests  atomic.Int64
+	matched   atomic.Int64
+	errors    atomic.Int64
+	skipped   atomic.Int64
+}
+
+var _ progress.Progress = &scanProgress{}
+
+// Stop stops the progress recorder.
+func (p *scanProgress) Stop() {}
+
+// Init inits the progress bar with initial details for scan
+func (p *scanProgress) Init(hostCount int64, rulesCount int, requestCount int64) {
+	p.hosts.Store(hostCount)
+	p.templates.Store(int64(rulesCount))
+	p.total.Store(requestCount)
+}
+
+// AddToTotal adds a value to the total request count
+func (p *scanProgress) AddToTotal(delta int64) {
+	p.total.Add(delta)
+}
+
+// IncrementRequests increments the requests counter by 1.
+func (p *scanProgress) IncrementRequests() {
+	p.requests.Inc()
+}
+
+// IncrementMatched increments the matched counter by 1.
+func (p *scanProgress) IncrementMatched() {
+	p.matched.Inc()
+}
+
+// IncrementErrorsBy increments the error counter by count.
+func (p *scanProgress) IncrementErrorsBy(count int64) {
+	p.errors.Add(count)
+}
+
+// IncrementFailedRequestsBy increments the number of requests counter by count
+// along with errors.
+func (p *scanProgress) IncrementFailedRequestsBy(count int64) {
+	p.requests.Add(count)
+	p.errors.Add(count)
+}
+
+// IncrementSkippedBy increments the skipped counter by count, marking
+// the requests as completed.
+func (p *scanProgress) IncrementSkippedBy(count int64) {
+	p.requests.Add(count)
+	p.skipped.Add(count)
+}
+
+// snapshot returns the current values of the counters
+func (p *scanProgress) snapshot() map[string]int64 {
+	return map[string]int64{
+		"hosts":     p.hosts.Load(),
+		"templates": p.templates.Load(),
+		"total":     p.total.Load(),
+		"requests":  p.requests.Load(),
+		"matched":   p.matched.Load(),
+		"errors":    p.errors.Load(),
+		"skipped":   p.skipped.Load(),
+	}
+}
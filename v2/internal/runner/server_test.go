@@ -0,0 +1,144 @@
+package runner
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	jsoniter "github.com/json-iterator/go"
+	"github.com/projectdiscovery/nuclei/v2/pkg/output"
+	"github.com/projectdiscovery/nuclei/v2/pkg/types"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestScan returns a scan without runner, finished at the time if set
+func newTestScan(id string, finished time.Time) *scan {
+	state := scanRunning
+	if !finished.IsZero() {
+		state = scanFinished
+	}
+	return &scan{
+		id:       id,
+		progress: &scanProgress{},
+		started:  time.Now(),
+		state:    state,
+		finished: finished,
+		updated:  make(chan struct{}),
+	}
+}
+
+func TestServerHandlers(t *testing.T) {
+	server := &Server{options: &types.Options{ServerToken: "token"}, scans: make(map[string]*scan)}
+	finished := newTestScan("finished", time.Now())
+	finished.results = []*output.ResultEvent{{TemplateID: "panel", Host: "https://example.com"}}
+	server.scans[finished.id] = finished
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/scans", server.authorize(server.handleScans))
+	mux.HandleFunc("/api/v1/scans/", server.authorize(server.handleScan))
+	request := func(method, path, body, token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		recorder := httptest.NewRecorder()
+		mux.ServeHTTP(recorder, req)
+		return recorder
+	}
+
+	require.Equal(t, http.StatusUnauthorized, request(http.MethodGet, "/api/v1/scans", "", "").Code, "could access api without token")
+	require.Equal(t, http.StatusUnauthorized, request(http.MethodGet, "/api/v1/scans", "", "invalid").Code, "could access api with invalid token")
+
+	response := request(http.MethodGet, "/api/v1/scans", "", "token")
+	require.Equal(t, http.StatusOK, response.Code, "could not list scans")
+	var statuses []*ScanStatus
+	require.Nil(t, jsoniter.Unmarshal(response.Body.Bytes(), &statuses), "could not decode scans")
+	require.Len(t, statuses, 1, "could not get scans")
+	require.Equal(t, scanFinished, statuses[0].State, "could not get scan state")
+
+	response = request(http.MethodGet, "/api/v1/scans/finished", "", "token")
+	require.Equal(t, http.StatusOK, response.Code, "could not get scan")
+	status := &ScanStatus{}
+	require.Nil(t, jsoniter.Unmarshal(response.Body.Bytes(), status), "could not decode scan")
+	require.Equal(t, "finished", status.ID, "could not get scan id")
+	require.NotNil(t, status.FinishedAt, "could not get scan finish time")
+
+	response = request(http.MethodGet, "/api/v1/scans/finished/results", "", "token")
+	require.Equal(t, http.StatusOK, response.Code, "could not get scan results")
+	var results []*output.ResultEvent
+	require.Nil(t, jsoniter.Unmarshal(response.Body.Bytes(), &results), "could not decode scan results")
+	require.Len(t, results, 1, "could not get scan results")
+	require.Equal(t, "panel", results[0].TemplateID, "could not get scan result")
+
+	response = request(http.MethodGet, "/api/v1/scans/finished/results?stream=true", "", "token")
+	require.Equal(t, "text/event-stream", response.Header().Get("Content-Type"), "could not stream scan results")
+	require.Contains(t, response.Body.String(), "event: result\ndata: ", "could not stream previous results")
+	require.Contains(t, response.Body.String(), "event: status\ndata: ", "could not stream final status")
+
+	require.Equal(t, http.StatusNotFound, request(http.MethodGet, "/api/v1/scans/missing", "", "token").Code, "could get missing scan")
+	require.Equal(t, http.StatusNotFound, request(http.MethodGet, "/api/v1/scans/finished/other", "", "token").Code, "could get unknown scan resource")
+	require.Equal(t, http.StatusMethodNotAllowed, request(http.MethodPut, "/api/v1/scans", "", "token").Code, "could use unsupported method")
+	require.Equal(t, http.StatusBadRequest, request(http.MethodPost, "/api/v1/scans", "{", "token").Code, "could submit invalid scan request")
+	require.Equal(t, http.StatusBadRequest, request(http.MethodPost, "/api/v1/scans", `{"templates":["cves/"]}`, "token").Code, "could submit scan without targets")
+	require.Equal(t, http.StatusBadRequest, request(http.MethodPost, "/api/v1/scans", `{"targets":["https://example.com"]}`, "token").Code, "could submit scan without templates")
+}
+
+func TestServerEvictScans(t *testing.T) {
+	previousCount := maxFinishedScans
+	maxFinishedScans = 2
+	defer func() { maxFinishedScans = previousCount }()
+
+	now := time.Now()
+	server := &Server{options: &types.Options{}, scans: make(map[string]*scan)}
+	server.scans["running"] = newTestScan("running", time.Time{})
+	server.scans["expired"] = newTestScan("expired", now.Add(-finishedScanTTL-time.Minute))
+	for i := 1; i <= 3; i++ {
+		id := fmt.Sprintf("finished-%d", i)
+		server.scans[id] = newTestScan(id, now.Add(-time.Duration(4-i)*time.Minute))
+	}
+
+	server.evictScans(now)
+	require.Len(t, server.scans, 3, "could not evict finished scans")
+	require.Contains(t, server.scans, "running", "could evict running scan")
+	require.NotContains(t, server.scans, "expired", "could not evict expired scan")
+	require.NotContains(t, server.scans, "finished-1", "could not evict oldest finished scan")
+	require.Contains(t, server.scans, "finished-3", "could evict newest finished scan")
+}
+
+func TestScanNextResults(t *testing.T) {
+	scan := newTestScan("running", time.Time{})
+
+	const count = 5000
+	go func() {
+		for i := 0; i < count; i++ {
+			scan.addResult(&output.ResultEvent{TemplateID: fmt.Sprintf("template-%d", i)})
+		}
+		scan.mutex.Lock()
+		scan.finished = time.Now()
+		scan.notify()
+		scan.mutex.Unlock()
+	}()
+
+	var received []*output.ResultEvent
+	for {
+		results, ok := scan.nextResults(context.Background(), len(received))
+		if !ok {
+			break
+		}
+		received = append(received, results...)
+	}
+	require.Len(t, received, count, "could not receive all results")
+	for i, result := range received {
+		require.Equal(t, fmt.Sprintf("template-%d", i), result.TemplateID, "could not receive results in order")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	running := newTestScan("running", time.Time{})
+	_, ok := running.nextResults(ctx, 0)
+	require.False(t, ok, "could wait for results with cancelled context")
+}
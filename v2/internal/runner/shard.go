@@ -0,0 +1,49 @@
+package runner
+
+import (
+	"hash/fnv"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// parseShard parses a shard specification of the form index/total (eg. 3/10)
+func parseShard(value string) (int, int, error) {
+	parts := strings.SplitN(value, "/", 2)
+	if len(parts) != 2 {
+		return 0, 0, errors.Errorf("invalid shard %s (it should be index/total)", value)
+	}
+	index, err := strconv.Atoi(strings.TrimSpace(parts[0]))
+	if err != nil {
+		return 0, 0, errors.Wrapf(err, "invalid shard index %s", parts[0])
+	}
+	total, err := strconv.Atoi(strings.TrimSpace(parts[1]))
+	if err != nil {
+		return 0, 0, errors.Wrapf(err, "invalid shard total %s", parts[1])
+	}
+	if total < 1 || index < 1 || index > total {
+		return 0, 0, errors.Errorf("invalid shard %s (index should be between 1 and total)", value)
+	}
+	return index, total, nil
+}
+
+// inShard returns true if the target belongs to the shard. Targets are
+// assigned using a hash so every machine computes the same split.
+func inShard(target string, index, total int) bool {
+	hasher := fnv.New32a()
+	_, _ = hasher.Write([]byte(target))
+	return int(hasher.Sum32()%uint32(total)) == index-1
+}
+
+// filterShard removes the targets not belonging to the shard from the input
+func (r *Runner) filterShard(index, total int) error {
+	var targets []string
+	r.hostMap.Scan(func(k, _ []byte) error {
+		if target := string(k); inShard(target, index, total) {
+			targets = append(targets, target)
+		}
+		return nil
+	})
+	return r.setInput(targets)
+}
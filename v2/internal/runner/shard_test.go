@@ -0,0 +1,40 @@
+package runner
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseShard(t *testing.T) {
+	index, total, err := parseShard("3/10")
+	require.Nil(t, err, "could not parse shard")
+	require.Equal(t, 3, index, "could not get shard index")
+	require.Equal(t, 10, total, "could not get shard total")
+
+	for _, value := range []string{"3", "0/10", "11/10", "a/10", "1/0"} {
+		_, _, err := parseShard(value)
+		require.NotNil(t, err, "could parse invalid shard %s", value)
+	}
+}
+
+func TestInShard(t *testing.T) {
+	const total = 4
+	counts := make(map[int]int)
+	for i := 0; i < 1000; i++ {
+		target := fmt.Sprintf("https://%d.example.com", i)
+
+		shards := 0
+		for index := 1; index <= total; index++ {
+			if inShard(target, index, total) {
+				shards++
+				counts[index]++
+			}
+		}
+		require.Equal(t, 1, shards, "target %s not in exactly one shard", target)
+	}
+	for index := 1; index <= total; index++ {
+		require.Greater(t, counts[index], 150, "shard %d has too few targets", index)
+	}
+}
@@ -0,0 +1,77 @@
+package runner
+
+import (
+	"hash/fnv"
+	"math/rand"
+	"sync"
+	"time"
+
+	"go.uber.org/ratelimit"
+)
+
+// lockedRand is a random source safe for concurrent use
+type lockedRand struct {
+	mutex  sync.Mutex
+	random *rand.Rand
+}
+
+// newLockedRand creates a random source with the stream of the seed for a use
+func newLockedRand(seed int64, use string) *lockedRand {
+	return &lockedRand{random: newStream(seed, use)}
+}
+
+// newStream returns the random stream of the seed for a use. Each use draws
+// from its own stream so that the values drawn do not depend on the order
+// in which the concurrent uses draw them.
+func newStream(seed int64, use string) *rand.Rand {
+	hasher := fnv.New64a()
+	_, _ = hasher.Write([]byte(use))
+	return rand.New(rand.NewSource(seed ^ int64(hasher.Sum64())))
+}
+
+// duration returns a random duration in [0, max)
+func (l *lockedRand) duration(max time.Duration) time.Duration {
+	if max <= 0 {
+		return 0
+	}
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	return time.Duration(l.random.Int63n(int64(max)))
+}
+
+// scanInput calls the callback for each of the input targets, in random
+// order if shuffling of targets was asked. The order is drawn from the
+// stream of the use so that it is reproduced with the same seed.
+func (r *Runner) scanInput(use string, callback func(URL string)) {
+	if !r.options.Shuffle {
+		r.hostMap.Scan(func(k, _ []byte) error {
+			callback(string(k))
+			return nil
+		})
+		return
+	}
+	targets := make([]string, 0, r.inputCount)
+	r.hostMap.Scan(func(k, _ []byte) error {
+		targets = append(targets, string(k))
+		return nil
+	})
+	newStream(r.seed, use).Shuffle(len(targets), func(i, j int) {
+		targets[i], targets[j] = targets[j], targets[i]
+	})
+	for _, target := range targets {
+		callback(target)
+	}
+}
+
+// jitterLimiter is a rate limiter adding a random delay before each request
+type jitterLimiter struct {
+	ratelimit.Limiter
+	max    time.Duration
+	random *lockedRand
+}
+
+// Take waits for a random jitter and then for the wrapped limiter
+func (j *jitterLimiter) Take() time.Time {
+	time.Sleep(j.random.duration(j.max))
+	return j.Limiter.Take()
+}
@@ -0,0 +1,30 @@
+package runner
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestStreamSeed(t *testing.T) {
+	shuffled := func(seed int64, use string) []int {
+		items := []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}
+		newStream(seed, use).Shuffle(len(items), func(i, j int) {
+			items[i], items[j] = items[j], items[i]
+		})
+		return items
+	}
+	require.Equal(t, shuffled(42, "template"), shuffled(42, "template"), "could not reproduce order with seed")
+	require.NotEqual(t, shuffled(42, "template"), shuffled(43, "template"), "could get same order with different seeds")
+	require.NotEqual(t, shuffled(42, "template"), shuffled(42, "other-template"), "could get same stream for different uses")
+}
+
+func TestLockedRandDuration(t *testing.T) {
+	random := newLockedRand(1, "jitter")
+	require.Equal(t, time.Duration(0), random.duration(0), "could get jitter without max")
+	for i := 0; i < 100; i++ {
+		duration := random.duration(time.Second)
+		require.True(t, duration >= 0 && duration < time.Second, "could get jitter out of range")
+	}
+}
@@ -0,0 +1,156 @@
+package runner
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/projectdiscovery/gologger"
+	"github.com/projectdiscovery/nuclei/v2/pkg/templates"
+	"github.com/projectdiscovery/nuclei/v2/pkg/types"
+)
+
+// maxStatsRows is the number of tags and authors printed in the statistics
+const maxStatsRows = 20
+
+// templateStats are the statistics of the selected templates
+type templateStats struct {
+	Templates  int            `json:"templates"`
+	Workflows  int            `json:"workflows"`
+	Protocols  map[string]int `json:"protocols"`
+	Severities map[string]int `json:"severities"`
+	Tags       map[string]int `json:"tags"`
+	Authors    map[string]int `json:"authors"`
+	// RequestsPerTarget is the estimated number of requests sent to each
+	// target, counting all the subtemplates of the workflows.
+	RequestsPerTarget int64 `json:"requests_per_target"`
+	// Targets is the number of targets of the input if any
+	Targets int64 `json:"targets,omitempty"`
+	// TotalRequests is the estimated number of requests of the scan of the targets
+	TotalRequests int64 `json:"total_requests,omitempty"`
+}
+
+// newTemplateStats returns the statistics of the templates and workflows
+func newTemplateStats(list []*templates.Template, targets int64) *templateStats {
+	stats := &templateStats{
+		Protocols:  make(map[string]int),
+		Severities: make(map[string]int),
+		Tags:       make(map[string]int),
+		Authors:    make(map[string]int),
+		Targets:    targets,
+	}
+	for _, template := range list {
+		if len(template.Workflows) > 0 {
+			stats.Workflows++
+			stats.RequestsPerTarget += int64(template.EstimatedRequests())
+			continue
+		}
+		stats.Templates++
+		for _, protocol := range template.Protocols() {
+			stats.Protocols[protocol]++
+		}
+		if severity := strings.ToLower(types.ToString(template.Info["severity"])); severity != "" {
+			stats.Severities[severity]++
+		}
+		for _, tag := range infoValues(template.Info["tags"]) {
+			stats.Tags[strings.ToLower(tag)]++
+		}
+		for _, author := range infoValues(template.Info["author"]) {
+			stats.Authors[author]++
+		}
+		stats.RequestsPerTarget += int64(template.TotalRequests)
+	}
+	stats.TotalRequests = stats.RequestsPerTarget * targets
+	return stats
+}
+
+// infoValues returns the values of a comma separated or list info field
+func infoValues(value interface{}) []string {
+	var items []string
+	if list, ok := value.([]interface{}); ok {
+		for _, item := range list {
+			items = append(items, types.ToString(item))
+		}
+	} else if value != nil {
+		items = strings.Split(types.ToString(value), ",")
+	}
+
+	values := make([]string, 0, len(items))
+	for _, item := range items {
+		if item = strings.TrimSpace(item); item != "" {
+			values = append(values, item)
+		}
+	}
+	return values
+}
+
+// printTemplateStats prints the statistics of the selected templates to
+// stdout, as a json object with -json.
+func (r *Runner) printTemplateStats(templatePaths, workflowPaths []string) {
+	stats := newTemplateStats(r.parsedTemplates(templatePaths, workflowPaths), r.inputCount)
+
+	if r.options.JSON {
+		data, err := json.Marshal(stats)
+		if err != nil {
+			gologger.Error().Msgf("Could not marshal template statistics: %s\n", err)
+			return
+		}
+		fmt.Fprintf(os.Stdout, "%s\n", data)
+		return
+	}
+	fmt.Fprintf(os.Stdout, "Templates: %d\nWorkflows: %d\n", stats.Templates, stats.Workflows)
+	r.printStatsCounts("Protocols", stats.Protocols, 0)
+	r.printStatsCounts("Severities", stats.Severities, 0)
+	r.printStatsCounts("Tags", stats.Tags, maxStatsRows)
+	r.printStatsCounts("Authors", stats.Authors, maxStatsRows)
+	fmt.Fprintf(os.Stdout, "\nEstimated requests per target: %d\n", stats.RequestsPerTarget)
+	if stats.Targets > 0 {
+		fmt.Fprintf(os.Stdout, "Estimated requests for %d targets: %d\n", stats.Targets, stats.TotalRequests)
+	}
+}
+
+// parsedTemplates returns the parsed templates and workflows of the paths
+// matching the filters of the options
+func (r *Runner) parsedTemplates(templatePaths, workflowPaths []string) []*templates.Template {
+	availableTemplates, _ := r.getParsedTemplatesFor(templatePaths, r.options.Severity, false)
+	availableWorkflows, _ := r.getParsedTemplatesFor(workflowPaths, r.options.Severity, true)
+
+	list := make([]*templates.Template, 0, len(availableTemplates)+len(availableWorkflows))
+	for _, template := range availableTemplates {
+		list = append(list, template)
+	}
+	for _, workflow := range availableWorkflows {
+		list = append(list, workflow)
+	}
+	return list
+}
+
+// printStatsCounts prints the counts sorted by count, limited to max rows if not zero
+func (r *Runner) printStatsCounts(title string, counts map[string]int, max int) {
+	keys := sortedByCount(counts)
+	fmt.Fprintf(os.Stdout, "\n%s (%d):\n", r.colorizer.Bold(title).String(), len(keys))
+	for i, key := range keys {
+		if max > 0 && i == max {
+			fmt.Fprintf(os.Stdout, "  ... %d more\n", len(keys)-max)
+			break
+		}
+		fmt.Fprintf(os.Stdout, "  %-24s %d\n", key, counts[key])
+	}
+}
+
+// sortedByCount returns the keys sorted by descending count and name
+func sortedByCount(counts map[string]int) []string {
+	keys := make([]string, 0, len(counts))
+	for key := range counts {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if counts[keys[i]] != counts[keys[j]] {
+			return counts[keys[i]] > counts[keys[j]]
+		}
+		return keys[i] < keys[j]
+	})
+	return keys
+}
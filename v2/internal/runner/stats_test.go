@@ -0,0 +1,40 @@
+package runner
+
+import (
+	"testing"
+
+	"github.com/projectdiscovery/nuclei/v2/pkg/protocols/dns"
+	"github.com/projectdiscovery/nuclei/v2/pkg/protocols/http"
+	"github.com/projectdiscovery/nuclei/v2/pkg/templates"
+	"github.com/projectdiscovery/nuclei/v2/pkg/workflows"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTemplateStats(t *testing.T) {
+	list := []*templates.Template{
+		{
+			Info:          map[string]interface{}{"severity": "High", "tags": "cve, wordpress", "author": "alice,bob"},
+			RequestsHTTP:  []*http.Request{{}},
+			TotalRequests: 1,
+		},
+		{
+			Info:          map[string]interface{}{"severity": "info", "tags": []interface{}{"dns", "CVE"}, "author": "alice"},
+			RequestsDNS:   []*dns.Request{{}},
+			TotalRequests: 2,
+		},
+		{Workflow: workflows.Workflow{Workflows: []*workflows.WorkflowTemplate{{
+			Subtemplates: []*workflows.WorkflowTemplate{{Executers: []*workflows.ProtocolExecuterPair{{Executer: &mockExecuter{requests: 4}}}}},
+		}}}},
+	}
+	stats := newTemplateStats(list, 10)
+	require.Equal(t, 2, stats.Templates, "could not count templates")
+	require.Equal(t, 1, stats.Workflows, "could not count workflows")
+	require.Equal(t, map[string]int{"http": 1, "dns": 1}, stats.Protocols, "could not count protocols")
+	require.Equal(t, map[string]int{"high": 1, "info": 1}, stats.Severities, "could not count severities")
+	require.Equal(t, 2, stats.Tags["cve"], "could not count tags")
+	require.Equal(t, 2, stats.Authors["alice"], "could not count authors")
+	require.Equal(t, int64(7), stats.RequestsPerTarget, "could not estimate requests per target")
+	require.Equal(t, int64(70), stats.TotalRequests, "could not estimate total requests")
+
+	require.Equal(t, []string{"cve", "dns", "wordpress"}, sortedByCount(stats.Tags), "could not sort tags by count")
+}
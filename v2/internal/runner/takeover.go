@@ -0,0 +1,117 @@
+package runner
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/miekg/dns"
+	"github.com/projectdiscovery/gologger"
+	"github.com/projectdiscovery/nuclei/v2/pkg/output"
+	"github.com/projectdiscovery/nuclei/v2/pkg/protocols/common/auditlog"
+	"github.com/projectdiscovery/nuclei/v2/pkg/protocols/common/protocolstate"
+	"github.com/projectdiscovery/nuclei/v2/pkg/protocols/common/takeover"
+	"github.com/projectdiscovery/nuclei/v2/pkg/protocols/common/wafdetect"
+	"github.com/projectdiscovery/nuclei/v2/pkg/protocols/dns/dnsclientpool"
+	"github.com/projectdiscovery/nuclei/v2/pkg/protocols/http/httpclientpool"
+	"github.com/remeh/sizedwaitgroup"
+	"go.uber.org/atomic"
+)
+
+// runTakeover checks the input subdomains for takeover, writing the
+// vulnerable ones as results and returning true if any were found.
+func (r *Runner) runTakeover() bool {
+	fingerprints := takeover.DefaultFingerprints
+	if r.options.TakeoverFingerprints != "" {
+		loaded, err := takeover.LoadFingerprints(r.options.TakeoverFingerprints)
+		if err != nil {
+			gologger.Fatal().Msgf("Could not load takeover fingerprints: %s\n", err)
+		}
+		fingerprints = loaded
+	}
+	dnsClient, err := dnsclientpool.Get(r.options, &dnsclientpool.Configuration{Retries: r.options.Retries})
+	if err != nil {
+		gologger.Fatal().Msgf("Could not get dns client: %s\n", err)
+	}
+	httpClient, err := httpclientpool.Get(r.options, &httpclientpool.Configuration{})
+	if err != nil {
+		gologger.Fatal().Msgf("Could not get http client: %s\n", err)
+	}
+	exchange := func(msg *dns.Msg) (*dns.Msg, error) {
+		if protocolstate.DNSDataset != nil {
+			return protocolstate.DNSDataset.Exchange(msg), nil
+		}
+		var resp *dns.Msg
+		var err error
+		if protocolstate.Resolver != nil && protocolstate.Resolver.Handles(msg) {
+			resp, err = protocolstate.Resolver.Do(msg)
+		} else {
+			resp, err = dnsClient.Do(msg)
+		}
+		var target string
+		if len(msg.Question) > 0 {
+			target = strings.TrimSuffix(msg.Question[0].Name, ".")
+		}
+		var received int
+		if resp != nil {
+			received = resp.Len()
+		}
+		r.auditLog.Log("takeover", "dns", target, msg.Len(), received, err)
+		return resp, err
+	}
+	checker := takeover.New(fingerprints, exchange, &http.Client{
+		Timeout:       httpClient.HTTPClient.Timeout,
+		CheckRedirect: httpClient.HTTPClient.CheckRedirect,
+		Transport:     auditlog.NewTransport(r.auditLog, "takeover", "http", httpClient.HTTPClient.Transport),
+	})
+
+	gologger.Info().Msgf("Checking %d targets for subdomain takeover with %d fingerprints", r.inputCount, len(fingerprints))
+	r.progress.Init(r.inputCount, 0, 0)
+	results := &atomic.Bool{}
+	wg := sizedwaitgroup.New(r.options.BulkSize)
+	r.scanInput("takeover", func(URL string) {
+		if r.stopped.Load() {
+			return
+		}
+		wg.Add()
+		go func(host string) {
+			defer wg.Done()
+
+			r.ratelimiter.Take()
+			result, err := checker.Check(host)
+			if err != nil {
+				gologger.Warning().Msgf("[takeover] Could not check %s: %s\n", host, err)
+				return
+			}
+			if result == nil {
+				return
+			}
+			if r.writeResult(takeoverResultEvent(result)) {
+				results.Store(true)
+			}
+		}(wafdetect.HostKey(URL))
+	})
+	wg.Wait()
+	return results.Load()
+}
+
+// takeoverResultEvent returns the result event of a takeover
+func takeoverResultEvent(result *takeover.Result) *output.ResultEvent {
+	return &output.ResultEvent{
+		TemplateID: "takeover-" + result.Service,
+		Info: map[string]interface{}{
+			"name":        result.Service + " subdomain takeover",
+			"author":      "nuclei",
+			"severity":    "high",
+			"tags":        "takeover," + result.Service,
+			"description": result.Evidence,
+		},
+		MatcherName:      result.Service,
+		Type:             result.Protocol,
+		Host:             result.Host,
+		Matched:          result.Host,
+		ExtractedResults: result.CNAMEs,
+		Metadata:         map[string]interface{}{"evidence": result.Evidence, "cname": strings.Join(result.CNAMEs, " -> ")},
+		Timestamp:        time.Now(),
+	}
+}
@@ -0,0 +1,59 @@
+package runner
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/projectdiscovery/nuclei/v2/pkg/output"
+	"github.com/projectdiscovery/nuclei/v2/pkg/progress"
+	"github.com/projectdiscovery/nuclei/v2/pkg/protocols/common/takeover"
+	"github.com/projectdiscovery/nuclei/v2/pkg/reporting/triage"
+	"github.com/stretchr/testify/require"
+)
+
+type resultsWriter struct {
+	output.Writer
+	events []*output.ResultEvent
+}
+
+func (w *resultsWriter) Write(event *output.ResultEvent) error {
+	w.events = append(w.events, event)
+	return nil
+}
+
+func TestTakeoverResultTriage(t *testing.T) {
+	directory, err := ioutil.TempDir("", "nuclei-triage-*")
+	require.Nil(t, err, "could not create temporary directory")
+	defer os.RemoveAll(directory)
+
+	file := filepath.Join(directory, "triage.yaml")
+	rules := `suppressions:
+  - template: takeover-azure
+    host: app.example.com
+    reason: claimed by the team
+overrides:
+  - template: takeover-github-pages
+    severity: low
+`
+	require.Nil(t, ioutil.WriteFile(file, []byte(rules), 0644), "could not write triage file")
+	triageRules, err := triage.New(file)
+	require.Nil(t, err, "could not load triage file")
+
+	progress, err := progress.NewStatsTicker(0, false, false, 0)
+	require.Nil(t, err, "could not create progress")
+	progress.Init(0, 0, 0)
+
+	writer := &resultsWriter{}
+	r := &Runner{output: writer, progress: progress, triage: triageRules}
+
+	suppressed := takeoverResultEvent(&takeover.Result{Host: "app.example.com", Service: "azure", Protocol: "dns"})
+	require.False(t, r.writeResult(suppressed), "could write suppressed takeover result")
+
+	overridden := takeoverResultEvent(&takeover.Result{Host: "docs.example.com", Service: "github-pages", Protocol: "http"})
+	require.True(t, r.writeResult(overridden), "could not write takeover result")
+	require.Len(t, writer.events, 1, "could not write only unsuppressed results")
+	require.Equal(t, "low", writer.events[0].Info["severity"], "could not override takeover severity")
+	require.Equal(t, "http", writer.events[0].Type, "could not get evidence protocol")
+}
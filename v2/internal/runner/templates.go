@@ -3,56 +3,119 @@ package runner
 import (
 	"fmt"
 	"os"
+	"runtime"
 	"strings"
+	"sync"
 
 	"github.com/karrick/godirwalk"
 	"github.com/projectdiscovery/gologger"
 	"github.com/projectdiscovery/nuclei/v2/pkg/protocols"
 	"github.com/projectdiscovery/nuclei/v2/pkg/templates"
 	"github.com/projectdiscovery/nuclei/v2/pkg/types"
+	"github.com/remeh/sizedwaitgroup"
 )
 
 // getParsedTemplatesFor parse the specified templates and returns a slice of the parsable ones, optionally filtered
 // by severity, along with a flag indicating if workflows are present.
+//
+// Templates are loaded lazily, only the headers are parsed to apply the filters and
+// the requests are compiled for the selected templates. If several templates have
+// the same id, the first one in the order of the paths is used.
 func (r *Runner) getParsedTemplatesFor(templatePaths, severities []string, workflows bool) (parsedTemplates map[string]*templates.Template, workflowCount int) {
-	filterBySeverity := len(severities) > 0
-
 	if !workflows {
 		gologger.Info().Msgf("Loading templates...")
 	} else {
 		gologger.Info().Msgf("Loading workflows...")
 	}
 
-	parsedTemplates = make(map[string]*templates.Template)
-	for _, match := range templatePaths {
-		t, err := r.parseTemplateFile(match)
-		if err != nil {
-			gologger.Warning().Msgf("Could not parse file '%s': %s\n", match, err)
+	// Templates are parsed in parallel as parsing and compiling thousands
+	// of templates one by one takes a long time on startup.
+	headers := make([]*templates.Header, len(templatePaths))
+	swg := sizedwaitgroup.New(runtime.NumCPU())
+	for i, match := range templatePaths {
+		swg.Add()
+		go func(i int, match string) {
+			defer swg.Done()
+
+			header, err := templates.ParseHeader(match, r.options)
+			if err != nil {
+				gologger.Warning().Msgf("Could not parse file '%s': %s\n", match, err)
+				return
+			}
+			headers[i] = header
+		}(i, match)
+	}
+	swg.Wait()
+
+	selected := make(map[string]string)
+	var selectedPaths []string
+	for i, header := range headers {
+		if header == nil || r.skipTemplateHeader(header, severities, workflows) {
 			continue
 		}
-		if t == nil {
+		if path, ok := selected[header.ID]; ok {
+			gologger.Warning().Msgf("Duplicate template id %s in '%s', using '%s'\n", header.ID, templatePaths[i], path)
 			continue
 		}
-		if len(t.Workflows) == 0 && workflows {
-			continue // don't print if user only wants to run workflows
-		}
-		if len(t.Workflows) > 0 && !workflows {
-			continue // don't print workflow if user only wants to run templates
-		}
-		if len(t.Workflows) > 0 {
-			workflowCount++
-		}
-		sev := strings.ToLower(types.ToString(t.Info["severity"]))
-		if !filterBySeverity || hasMatchingSeverity(sev, severities) {
+		selected[header.ID] = templatePaths[i]
+		selectedPaths = append(selectedPaths, templatePaths[i])
+	}
+
+	parsedTemplates = make(map[string]*templates.Template)
+	mutex := &sync.Mutex{}
+	for _, match := range selectedPaths {
+		swg.Add()
+		go func(match string) {
+			defer swg.Done()
+
+			t, err := r.parseTemplateFile(match)
+			if err != nil {
+				gologger.Warning().Msgf("Could not parse file '%s': %s\n", match, err)
+				return
+			}
+			if t == nil {
+				return
+			}
+			sev := strings.ToLower(types.ToString(t.Info["severity"]))
+			if t.GlobalMatchers {
+				// Global matchers don't send requests, their matchers
+				// are evaluated on the responses of other templates.
+				t.AddGlobalMatchers(r.globalMatchers)
+				gologger.Info().Msgf("%s [global-matchers]\n", r.templateLogMsg(t.ID, types.ToString(t.Info["name"]), types.ToString(t.Info["author"]), sev))
+				return
+			}
+
+			mutex.Lock()
+			if len(t.Workflows) > 0 {
+				workflowCount++
+			}
 			parsedTemplates[t.ID] = t
+			mutex.Unlock()
 			gologger.Info().Msgf("%s\n", r.templateLogMsg(t.ID, types.ToString(t.Info["name"]), types.ToString(t.Info["author"]), sev))
-		} else {
-			gologger.Warning().Msgf("Excluding template %s due to severity filter (%s not in [%s])", t.ID, sev, severities)
-		}
+		}(match)
 	}
+	swg.Wait()
 	return parsedTemplates, workflowCount
 }
 
+// skipTemplateHeader returns true if the template is excluded by the
+// severity or condition filters, or is not of the requested kind.
+func (r *Runner) skipTemplateHeader(header *templates.Header, severities []string, workflows bool) bool {
+	if header.Workflow != workflows {
+		return true // don't print workflows if user only wants to run templates and vice versa
+	}
+	sev := strings.ToLower(types.ToString(header.Info["severity"]))
+	if len(severities) > 0 && !hasMatchingSeverity(sev, severities) {
+		gologger.Warning().Msgf("Excluding template %s due to severity filter (%s not in [%s])", header.ID, sev, severities)
+		return true
+	}
+	if !templates.MatchConditions(r.conditions, header.Info) {
+		gologger.Warning().Msgf("Excluding template %s due to template condition filter", header.ID)
+		return true
+	}
+	return false
+}
+
 // parseTemplateFile returns the parsed template file
 func (r *Runner) parseTemplateFile(file string) (*templates.Template, error) {
 	executerOpts := protocols.ExecuterOptions{
@@ -63,8 +126,23 @@ func (r *Runner) parseTemplateFile(file string) (*templates.Template, error) {
 		IssuesClient: r.issuesClient,
 		RateLimiter:  r.ratelimiter,
 		Interactsh:   r.interactsh,
+		WafDetector:  r.wafDetector,
 		ProjectFile:  r.projectFile,
 		Browser:      r.browser,
+
+		GlobalMatchers: r.globalMatchers,
+		Tracer:         r.tracer,
+		ResponseStore:  r.responseStore,
+		Profiler:       r.profiler,
+		InputRequests:  r.inputRequests,
+		AuthProvider:   r.authProvider,
+		CSRF:           r.csrf,
+		Triage:         r.triage,
+		AuditLog:       r.auditLog,
+		ContextStore:   r.contextStore,
+		CookieJar:      r.cookieJar,
+		Context:        r.ctx,
+		ResultHook:     r.dependencies.matchResult,
 	}
 	template, err := templates.Parse(file, executerOpts)
 	if err != nil {
@@ -0,0 +1,47 @@
+package runner
+
+import (
+	"os"
+	"path"
+
+	"github.com/pkg/errors"
+	"github.com/projectdiscovery/gologger"
+	"github.com/projectdiscovery/nuclei/v2/pkg/input/uncover"
+)
+
+// loadUncoverInput adds the targets found by the uncover query to the input.
+// The number of duplicate targets is returned.
+func (r *Runner) loadUncoverInput() (int, error) {
+	configFile := r.options.UncoverConfig
+	if configFile == "" {
+		if home, err := os.UserHomeDir(); err == nil {
+			configFile = path.Join(home, ".config", "nuclei", "uncover-config.yaml")
+		}
+	}
+	keys, err := uncover.LoadKeys(configFile)
+	if err != nil {
+		return 0, err
+	}
+	targets, err := uncover.Search(&uncover.Options{
+		Query:   r.options.UncoverQuery,
+		Engines: r.options.UncoverEngine,
+		Limit:   r.options.UncoverLimit,
+		Keys:    keys,
+	})
+	if err != nil {
+		return 0, errors.Wrap(err, "could not run uncover query")
+	}
+	gologger.Info().Msgf("Found %d targets with uncover query", len(targets))
+
+	dupeCount := 0
+	for _, target := range targets {
+		if _, ok := r.hostMap.Get(target); ok {
+			dupeCount++
+			continue
+		}
+		r.inputCount++
+		// nolint:errcheck // ignoring error
+		r.hostMap.Set(target, nil)
+	}
+	return dupeCount, nil
+}
@@ -441,7 +441,12 @@ func writeTemplatesChecksum(file string, checksum map[string]string) error {
 	return nil
 }
 
+// printUpdateChangelog prints the changelog of the templates update on
+// stderr, keeping stdout for the results.
 func (r *Runner) printUpdateChangelog(results *templateUpdateResults, version string) {
+	if r.options != nil && r.options.Silent {
+		return
+	}
 	if len(results.additions) > 0 {
 		gologger.Print().Msgf("\nNewly added templates: \n\n")
 
@@ -454,7 +459,7 @@ func (r *Runner) printUpdateChangelog(results *templateUpdateResults, version st
 	data := [][]string{
 		{strconv.Itoa(results.totalCount), strconv.Itoa(len(results.additions)), strconv.Itoa(len(results.deletions))},
 	}
-	table := tablewriter.NewWriter(os.Stdout)
+	table := tablewriter.NewWriter(os.Stderr)
 	table.SetHeader([]string{"Total", "Added", "Removed"})
 	for _, v := range data {
 		table.Append(v)
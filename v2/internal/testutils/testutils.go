@@ -43,6 +43,7 @@ var DefaultOptions = &types.Options{
 	Timeout:            5,
 	Retries:            1,
 	RateLimit:          150,
+	RandomAgent:        false,
 	ProjectPath:        "",
 	Severity:           []string{},
 	Target:             "",
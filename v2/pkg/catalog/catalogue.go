@@ -1,5 +1,9 @@
 package catalog
 
+// TestSuffix is the suffix of the test fixtures of the templates. The
+// fixtures next to their template are skipped when walking directories.
+const TestSuffix = "_test.yaml"
+
 // Catalog is a template catalog helper implementation
 type Catalog struct {
 	ignoreFiles        []string
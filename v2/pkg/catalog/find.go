@@ -141,7 +141,7 @@ func (c *Catalog) findDirectoryMatches(absPath string, processed map[string]stru
 			return godirwalk.SkipNode
 		},
 		Callback: func(path string, d *godirwalk.Dirent) error {
-			if !d.IsDir() && strings.HasSuffix(path, ".yaml") {
+			if !d.IsDir() && strings.HasSuffix(path, ".yaml") && !isTestFixture(path) {
 				if _, ok := processed[path]; !ok {
 					results = append(results, path)
 					processed[path] = struct{}{}
@@ -152,3 +152,12 @@ func (c *Catalog) findDirectoryMatches(absPath string, processed map[string]stru
 	})
 	return results, err
 }
+
+// isTestFixture returns true if a file is the test fixtures of a template
+func isTestFixture(path string) bool {
+	if !strings.HasSuffix(path, TestSuffix) {
+		return false
+	}
+	_, err := os.Stat(strings.TrimSuffix(path, TestSuffix) + ".yaml")
+	return err == nil
+}
@@ -0,0 +1,23 @@
+package catalog
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetTemplatesPathFixtures(t *testing.T) {
+	directory, err := ioutil.TempDir("", "nuclei-catalog-*")
+	require.Nil(t, err, "could not create temporary directory")
+	defer os.RemoveAll(directory)
+
+	for _, name := range []string{"panel.yaml", "panel_test.yaml", "unit_test.yaml"} {
+		require.Nil(t, ioutil.WriteFile(filepath.Join(directory, name), []byte("id: test"), 0644), "could not write template")
+	}
+
+	paths := New("").GetTemplatesPath([]string{directory}, true)
+	require.ElementsMatch(t, []string{filepath.Join(directory, "panel.yaml"), filepath.Join(directory, "unit_test.yaml")}, paths, "could not skip template fixtures")
+}
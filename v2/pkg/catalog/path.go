@@ -43,3 +43,49 @@ func (c *Catalog) ResolvePath(templateName, second string) (string, error) {
 	}
 	return "", fmt.Errorf("no such path found: %s", templateName)
 }
+
+// IsSandboxed returns true if the path is inside the templates directory
+// or the directory of the template it is referenced from. Symbolic links
+// are resolved so that links inside the directories cannot escape them.
+func (c *Catalog) IsSandboxed(filePath, templatePath string) bool {
+	directories := []string{filepath.Dir(templatePath)}
+	if c.templatesDirectory != "" {
+		directories = append(directories, c.templatesDirectory)
+	}
+	absPath, err := resolvePath(filePath)
+	if err != nil {
+		return false
+	}
+	for _, directory := range directories {
+		absDirectory, err := resolvePath(directory)
+		if err != nil {
+			continue
+		}
+		rel, err := filepath.Rel(absDirectory, absPath)
+		if err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return true
+		}
+	}
+	return false
+}
+
+// resolvePath returns the absolute path with the symbolic links of its
+// existing part resolved.
+func resolvePath(path string) (string, error) {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+	if resolved, err := filepath.EvalSymlinks(absPath); err == nil {
+		return resolved, nil
+	}
+	parent := filepath.Dir(absPath)
+	if parent == absPath {
+		return absPath, nil
+	}
+	resolvedParent, err := resolvePath(parent)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(resolvedParent, filepath.Base(absPath)), nil
+}
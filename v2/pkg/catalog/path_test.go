@@ -0,0 +1,37 @@
+package catalog
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsSandboxed(t *testing.T) {
+	c := New("/root/nuclei-templates")
+
+	require.True(t, c.IsSandboxed("/root/nuclei-templates/helpers/wordlists/users.txt", "/tmp/custom/template.yaml"), "could not allow templates directory file")
+	require.True(t, c.IsSandboxed("/tmp/custom/users.txt", "/tmp/custom/template.yaml"), "could not allow template directory file")
+	require.False(t, c.IsSandboxed("/etc/passwd", "/tmp/custom/template.yaml"), "could allow local file")
+	require.False(t, c.IsSandboxed("/root/nuclei-templates/../.ssh/id_rsa", "/root/nuclei-templates/cves/template.yaml"), "could allow path traversal")
+}
+
+func TestIsSandboxedSymlink(t *testing.T) {
+	dir, err := ioutil.TempDir("", "nuclei-sandbox-*")
+	require.Nil(t, err, "could not create temporary directory")
+	defer os.RemoveAll(dir)
+
+	templates := filepath.Join(dir, "templates")
+	require.Nil(t, os.Mkdir(templates, 0755), "could not create templates directory")
+	require.Nil(t, ioutil.WriteFile(filepath.Join(dir, "secret.txt"), []byte("secret"), 0644), "could not write outside file")
+	require.Nil(t, os.Symlink(filepath.Join(dir, "secret.txt"), filepath.Join(templates, "link.txt")), "could not create file link")
+	require.Nil(t, os.Symlink(dir, filepath.Join(templates, "parent")), "could not create directory link")
+
+	c := New(templates)
+	template := filepath.Join(templates, "template.yaml")
+	require.False(t, c.IsSandboxed(filepath.Join(templates, "link.txt"), template), "could allow file link escaping the sandbox")
+	require.False(t, c.IsSandboxed(filepath.Join(templates, "parent", "secret.txt"), template), "could allow directory link escaping the sandbox")
+	require.True(t, c.IsSandboxed(filepath.Join(templates, "missing.txt"), template), "could not allow missing file inside the sandbox")
+}
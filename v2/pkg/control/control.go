@@ -0,0 +1,251 @@
+// Package control exposes a local unix socket accepting commands to
+// pause, resume or adjust a running scan and to reload its templates.
+package control
+
+import (
+	"bufio"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/projectdiscovery/gologger"
+	"go.uber.org/ratelimit"
+)
+
+// DefaultSocket is the default path of the control socket, in the
+// runtime directory of the user.
+var DefaultSocket = filepath.Join(runtimeDir(), "nuclei-control.sock")
+
+// runtimeDir returns the runtime directory of the user, or a directory
+// of the user in the temporary directory if there is none.
+func runtimeDir() string {
+	if dir := os.Getenv("XDG_RUNTIME_DIR"); dir != "" {
+		return dir
+	}
+	return filepath.Join(os.TempDir(), "nuclei-"+strconv.Itoa(os.Getuid()))
+}
+
+// privateDir creates the directory of the default socket only accessible
+// to the user, and checks an existing one is not accessible to others.
+func privateDir(dir string) error {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return errors.Wrap(err, "could not create control socket directory")
+	}
+	info, err := os.Lstat(dir)
+	if err != nil {
+		return errors.Wrap(err, "could not stat control socket directory")
+	}
+	if !info.IsDir() || info.Mode().Perm()&0077 != 0 {
+		return errors.Errorf("control socket directory %s is accessible to other users", dir)
+	}
+	return nil
+}
+
+// Handler applies the commands to the scan
+type Handler interface {
+	// Pause pauses the requests of the scan
+	Pause()
+	// Resume resumes the requests of the scan
+	Resume()
+	// SetRate sets the maximum number of requests per second, 0 for no limit
+	SetRate(rate int)
+	// ExcludeHost skips the remaining work for a host
+	ExcludeHost(host string)
+	// ReloadTemplates schedules the templates added since the scan started
+	ReloadTemplates() error
+}
+
+// Server accepts the commands of the control socket
+type Server struct {
+	listener net.Listener
+	handler  Handler
+}
+
+// Listen starts accepting commands on a unix socket only accessible to the user
+func Listen(socket string, handler Handler) (*Server, error) {
+	if filepath.Dir(socket) == filepath.Dir(DefaultSocket) {
+		if err := privateDir(filepath.Dir(socket)); err != nil {
+			return nil, err
+		}
+	}
+	// Remove the socket of a previous scan that did not exit cleanly
+	if conn, err := net.Dial("unix", socket); err == nil {
+		conn.Close()
+		return nil, errors.Errorf("control socket %s is used by another scan", socket)
+	}
+	if info, err := os.Lstat(socket); err == nil {
+		if info.Mode()&os.ModeSocket == 0 {
+			return nil, errors.Errorf("control socket path %s exists and is not a socket", socket)
+		}
+		_ = os.Remove(socket)
+	}
+
+	listener, err := net.Listen("unix", socket)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not listen on control socket")
+	}
+	if err := os.Chmod(socket, 0600); err != nil {
+		listener.Close()
+		return nil, errors.Wrap(err, "could not set control socket permissions")
+	}
+	server := &Server{listener: listener, handler: handler}
+	go server.serve()
+	return server, nil
+}
+
+// Close stops accepting commands and removes the socket
+func (s *Server) Close() {
+	if s == nil {
+		return
+	}
+	s.listener.Close()
+}
+
+func (s *Server) serve() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		go s.handle(conn)
+	}
+}
+
+// handle executes the commands of a connection, one per line,
+// replying with ok or the error of the command.
+func (s *Server) handle(conn net.Conn) {
+	defer conn.Close()
+
+	scanner := bufio.NewScanner(conn)
+	for scanner.Scan() {
+		reply := "ok"
+		if err := s.execute(strings.Fields(scanner.Text())); err != nil {
+			reply = "error: " + err.Error()
+		}
+		if _, err := conn.Write([]byte(reply + "\n")); err != nil {
+			return
+		}
+	}
+}
+
+// execute executes a command with its arguments
+func (s *Server) execute(args []string) error {
+	if len(args) == 0 {
+		return errors.New("no command provided")
+	}
+	switch args[0] {
+	case "pause":
+		s.handler.Pause()
+	case "resume":
+		s.handler.Resume()
+	case "set-rate":
+		if len(args) != 2 {
+			return errors.New("set-rate requires the number of requests per second")
+		}
+		rate, err := strconv.Atoi(args[1])
+		if err != nil || rate < 0 {
+			return errors.Errorf("invalid rate %s", args[1])
+		}
+		s.handler.SetRate(rate)
+	case "exclude-host":
+		if len(args) != 2 {
+			return errors.New("exclude-host requires a host")
+		}
+		s.handler.ExcludeHost(args[1])
+	case "reload-templates":
+		if err := s.handler.ReloadTemplates(); err != nil {
+			return err
+		}
+	default:
+		return errors.Errorf("unknown command %s", args[0])
+	}
+	gologger.Info().Msgf("Executed control command: %s", strings.Join(args, " "))
+	return nil
+}
+
+// Send sends a command to the control socket of a running scan
+func Send(socket string, args []string) error {
+	conn, err := net.DialTimeout("unix", socket, 5*time.Second)
+	if err != nil {
+		return errors.Wrap(err, "could not connect to control socket")
+	}
+	defer conn.Close()
+
+	if _, err := conn.Write([]byte(strings.Join(args, " ") + "\n")); err != nil {
+		return errors.Wrap(err, "could not send command")
+	}
+	reply, err := bufio.NewReader(conn).ReadString('\n')
+	if err != nil {
+		return errors.Wrap(err, "could not read reply")
+	}
+	reply = strings.TrimSpace(reply)
+	if reply != "ok" {
+		return errors.New(strings.TrimPrefix(reply, "error: "))
+	}
+	return nil
+}
+
+// Limiter is a rate limiter whose rate can be changed and that
+// blocks the requests while paused.
+type Limiter struct {
+	mutex   sync.RWMutex
+	limiter ratelimit.Limiter
+	paused  chan struct{}
+}
+
+// NewLimiter creates a new limiter with an initial rate, 0 for no limit
+func NewLimiter(rate int) *Limiter {
+	limiter := &Limiter{}
+	limiter.SetRate(rate)
+	return limiter
+}
+
+// Take blocks while the limiter is paused and then until the next request is allowed
+func (l *Limiter) Take() time.Time {
+	l.mutex.RLock()
+	paused, limiter := l.paused, l.limiter
+	l.mutex.RUnlock()
+
+	if paused != nil {
+		<-paused
+		return l.Take()
+	}
+	return limiter.Take()
+}
+
+// Pause blocks the requests until resumed
+func (l *Limiter) Pause() {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	if l.paused == nil {
+		l.paused = make(chan struct{})
+	}
+}
+
+// Resume unblocks the paused requests
+func (l *Limiter) Resume() {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	if l.paused != nil {
+		close(l.paused)
+		l.paused = nil
+	}
+}
+
+// SetRate sets the maximum number of requests per second, 0 for no limit
+func (l *Limiter) SetRate(rate int) {
+	var limiter ratelimit.Limiter
+	if rate > 0 {
+		limiter = ratelimit.New(rate)
+	} else {
+		limiter = ratelimit.NewUnlimited()
+	}
+	l.mutex.Lock()
+	l.limiter = limiter
+	l.mutex.Unlock()
+}
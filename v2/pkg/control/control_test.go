@@ -0,0 +1,108 @@
+package control
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type mockHandler struct {
+	mutex    sync.Mutex
+	commands []string
+}
+
+func (m *mockHandler) record(command string) {
+	m.mutex.Lock()
+	m.commands = append(m.commands, command)
+	m.mutex.Unlock()
+}
+
+func (m *mockHandler) Pause()                  { m.record("pause") }
+func (m *mockHandler) Resume()                 { m.record("resume") }
+func (m *mockHandler) SetRate(rate int)        { m.record("set-rate") }
+func (m *mockHandler) ExcludeHost(host string) { m.record("exclude-host " + host) }
+func (m *mockHandler) ReloadTemplates() error {
+	m.record("reload-templates")
+	return nil
+}
+
+func TestControlSocket(t *testing.T) {
+	dir, err := ioutil.TempDir("", "nuclei-control-*")
+	require.Nil(t, err, "could not create temporary directory")
+	defer os.RemoveAll(dir)
+
+	socket := filepath.Join(dir, "control.sock")
+	handler := &mockHandler{}
+	server, err := Listen(socket, handler)
+	require.Nil(t, err, "could not listen on control socket")
+	defer server.Close()
+
+	info, err := os.Stat(socket)
+	require.Nil(t, err, "could not stat control socket")
+	require.Equal(t, os.FileMode(0600), info.Mode().Perm(), "could not restrict control socket permissions")
+
+	_, err = Listen(socket, handler)
+	require.NotNil(t, err, "could listen on used control socket")
+
+	require.Nil(t, Send(socket, []string{"pause"}), "could not send pause")
+	require.Nil(t, Send(socket, []string{"set-rate", "50"}), "could not send set-rate")
+	require.Nil(t, Send(socket, []string{"exclude-host", "example.com"}), "could not send exclude-host")
+	require.Nil(t, Send(socket, []string{"reload-templates"}), "could not send reload-templates")
+	require.NotNil(t, Send(socket, []string{"set-rate", "fast"}), "could send invalid rate")
+	require.NotNil(t, Send(socket, []string{"restart"}), "could send unknown command")
+	require.Equal(t, []string{"pause", "set-rate", "exclude-host example.com", "reload-templates"}, handler.commands, "could not execute commands")
+}
+
+func TestListenExistingFile(t *testing.T) {
+	file, err := ioutil.TempFile("", "nuclei-control-*")
+	require.Nil(t, err, "could not create temporary file")
+	defer os.Remove(file.Name())
+	file.Close()
+
+	_, err = Listen(file.Name(), &mockHandler{})
+	require.NotNil(t, err, "could listen on path of regular file")
+	_, err = os.Stat(file.Name())
+	require.Nil(t, err, "could remove regular file")
+}
+
+func TestPrivateDir(t *testing.T) {
+	dir, err := ioutil.TempDir("", "nuclei-control-*")
+	require.Nil(t, err, "could not create temporary directory")
+	defer os.RemoveAll(dir)
+
+	private := filepath.Join(dir, "private")
+	require.Nil(t, privateDir(private), "could not create private directory")
+	info, err := os.Stat(private)
+	require.Nil(t, err, "could not stat private directory")
+	require.Equal(t, os.FileMode(0700), info.Mode().Perm(), "could not create directory only accessible to the user")
+
+	require.Nil(t, os.Chmod(dir, 0755), "could not change directory permissions")
+	require.NotNil(t, privateDir(dir), "could use directory accessible to other users")
+}
+
+func TestLimiterPause(t *testing.T) {
+	limiter := NewLimiter(0)
+	limiter.Pause()
+
+	taken := make(chan struct{})
+	go func() {
+		limiter.Take()
+		close(taken)
+	}()
+	select {
+	case <-taken:
+		t.Fatal("could take from paused limiter")
+	case <-time.After(100 * time.Millisecond):
+	}
+	limiter.Resume()
+	select {
+	case <-taken:
+	case <-time.After(time.Second):
+		t.Fatal("could not take from resumed limiter")
+	}
+}
@@ -0,0 +1,281 @@
+// Package dashboard renders a live terminal view of a running scan with
+// the findings per severity, the running templates, the slowest hosts
+// and the latest findings.
+package dashboard
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/logrusorgru/aurora"
+	"github.com/projectdiscovery/nuclei/v2/internal/colorizer"
+	"github.com/projectdiscovery/nuclei/v2/pkg/output"
+	"github.com/projectdiscovery/nuclei/v2/pkg/reporting/format"
+	"github.com/projectdiscovery/nuclei/v2/pkg/types"
+)
+
+// Sizes of the panes of the dashboard
+const (
+	runningPaneSize  = 10
+	hostsPaneSize    = 5
+	findingsPaneSize = 15
+)
+
+// maxTrackedHosts is the maximum number of hosts whose durations are
+// tracked, the fastest hosts are dropped beyond it.
+const maxTrackedHosts = 1000
+
+// severities are the severities of the counters in display order
+var severities = []string{"critical", "high", "medium", "low", "info"}
+
+// Dashboard tracks the state of a scan and renders it periodically
+type Dashboard struct {
+	out            io.Writer
+	interval       time.Duration
+	aurora         aurora.Aurora
+	severityColors *colorizer.Colorizer
+
+	mutex    sync.Mutex
+	start    time.Time
+	counts   map[string]int
+	total    int
+	running  map[string]int
+	hosts    map[string]time.Duration
+	findings []string
+
+	// lifecycle serializes starting and stopping the rendering
+	lifecycle sync.Mutex
+	stop      chan struct{}
+	done      chan struct{}
+	signals   chan os.Signal
+}
+
+// New creates a new dashboard rendered to out at each interval
+func New(out io.Writer, interval time.Duration, colors aurora.Aurora) *Dashboard {
+	return &Dashboard{
+		out:            out,
+		interval:       interval,
+		aurora:         colors,
+		severityColors: colorizer.New(colors),
+		counts:         make(map[string]int),
+		running:        make(map[string]int),
+		hosts:          make(map[string]time.Duration),
+	}
+}
+
+// Start switches to the alternate screen and starts rendering the dashboard.
+// The screen is restored if the process is interrupted before Stop.
+func (d *Dashboard) Start() {
+	if d == nil {
+		return
+	}
+	d.lifecycle.Lock()
+	defer d.lifecycle.Unlock()
+
+	d.start = time.Now()
+	stop := make(chan struct{})
+	d.stop = stop
+	d.done = make(chan struct{})
+	d.signals = make(chan os.Signal, 1)
+	signal.Notify(d.signals, os.Interrupt, syscall.SIGTERM)
+	fmt.Fprint(d.out, "\x1b[?1049h\x1b[?25l")
+
+	go func(signals chan os.Signal) {
+		select {
+		case <-signals:
+			d.Stop()
+			os.Exit(1)
+		case <-stop:
+		}
+	}(d.signals)
+
+	go func() {
+		defer close(d.done)
+
+		ticker := time.NewTicker(d.interval)
+		defer ticker.Stop()
+		for {
+			d.render()
+			select {
+			case <-ticker.C:
+			case <-d.stop:
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops rendering the dashboard, restoring the screen and
+// writing a summary of the findings.
+func (d *Dashboard) Stop() {
+	if d == nil {
+		return
+	}
+	d.lifecycle.Lock()
+	defer d.lifecycle.Unlock()
+	if d.stop == nil {
+		return
+	}
+	signal.Stop(d.signals)
+	close(d.stop)
+	<-d.done
+	d.stop = nil
+	fmt.Fprint(d.out, "\x1b[?1049l\x1b[?25h")
+
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	fmt.Fprintf(d.out, "%s\n", d.summary())
+}
+
+// TemplateStarted records a template starting on a host
+func (d *Dashboard) TemplateStarted(templateID string) {
+	if d == nil {
+		return
+	}
+	d.mutex.Lock()
+	d.running[templateID]++
+	d.mutex.Unlock()
+}
+
+// TemplateFinished records a template finishing on a host
+func (d *Dashboard) TemplateFinished(templateID string) {
+	if d == nil {
+		return
+	}
+	d.mutex.Lock()
+	if d.running[templateID]--; d.running[templateID] <= 0 {
+		delete(d.running, templateID)
+	}
+	d.mutex.Unlock()
+}
+
+// HostScanned adds the time a template took on a host to the host
+func (d *Dashboard) HostScanned(host string, duration time.Duration) {
+	if d == nil {
+		return
+	}
+	d.mutex.Lock()
+	d.hosts[host] += duration
+	if len(d.hosts) > maxTrackedHosts {
+		d.pruneHosts()
+	}
+	d.mutex.Unlock()
+}
+
+// pruneHosts keeps only the slowest half of the tracked hosts
+func (d *Dashboard) pruneHosts() {
+	for _, host := range d.slowestHosts()[maxTrackedHosts/2:] {
+		delete(d.hosts, host)
+	}
+}
+
+// slowestHosts returns the tracked hosts from the slowest to the fastest
+func (d *Dashboard) slowestHosts() []string {
+	hosts := make([]string, 0, len(d.hosts))
+	for host := range d.hosts {
+		hosts = append(hosts, host)
+	}
+	sort.Slice(hosts, func(i, j int) bool { return d.hosts[hosts[i]] > d.hosts[hosts[j]] })
+	return hosts
+}
+
+// Found records a finding of the scan
+func (d *Dashboard) Found(event *output.ResultEvent) {
+	if d == nil {
+		return
+	}
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	severity := types.ToString(event.Info["severity"])
+	d.counts[severity]++
+	d.total++
+
+	finding := fmt.Sprintf("[%s] [%s] [%s] %s", time.Now().Format("15:04:05"), d.aurora.BrightGreen(format.GetMatchedTemplate(event)), d.severity(severity), event.Matched)
+	d.findings = append(d.findings, finding)
+	if len(d.findings) > findingsPaneSize {
+		d.findings = d.findings[len(d.findings)-findingsPaneSize:]
+	}
+}
+
+// render clears the screen and draws the dashboard
+func (d *Dashboard) render() {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	builder := &strings.Builder{}
+	builder.WriteString("\x1b[H\x1b[2J")
+	fmt.Fprintf(builder, "%s | elapsed %s\n\n", d.aurora.Bold("nuclei dashboard"), time.Since(d.start).Truncate(time.Second))
+	builder.WriteString(d.summary())
+
+	fmt.Fprintf(builder, "\n\n%s\n", d.aurora.Bold(fmt.Sprintf("Running templates (%d)", len(d.running))))
+	running := make([]string, 0, len(d.running))
+	for templateID := range d.running {
+		running = append(running, templateID)
+	}
+	sort.Strings(running)
+	for i, templateID := range running {
+		if i == runningPaneSize {
+			fmt.Fprintf(builder, "  ... and %d more\n", len(running)-runningPaneSize)
+			break
+		}
+		fmt.Fprintf(builder, "  %s (%d hosts)\n", templateID, d.running[templateID])
+	}
+
+	fmt.Fprintf(builder, "\n%s\n", d.aurora.Bold("Slowest hosts"))
+	for i, host := range d.slowestHosts() {
+		if i == hostsPaneSize {
+			break
+		}
+		fmt.Fprintf(builder, "  %-50s %s\n", host, d.hosts[host].Truncate(time.Millisecond))
+	}
+
+	fmt.Fprintf(builder, "\n%s\n", d.aurora.Bold("Latest findings"))
+	for _, finding := range d.findings {
+		builder.WriteString("  ")
+		builder.WriteString(finding)
+		builder.WriteRune('\n')
+	}
+	fmt.Fprint(d.out, builder.String())
+}
+
+// summary returns the counters of the findings per severity
+func (d *Dashboard) summary() string {
+	parts := make([]string, 0, len(severities)+1)
+	for _, severity := range severities {
+		parts = append(parts, fmt.Sprintf("%s %d", d.severity(severity), d.counts[severity]))
+	}
+	parts = append(parts, fmt.Sprintf("total %d", d.total))
+	return "Findings: " + strings.Join(parts, " | ")
+}
+
+func (d *Dashboard) severity(severity string) string {
+	if colored, ok := d.severityColors.Data[severity]; ok {
+		return colored
+	}
+	return severity
+}
+
+// Writer is an output writer adding the findings to the dashboard
+type Writer struct {
+	output.Writer
+	dashboard *Dashboard
+}
+
+// NewWriter creates a new writer adding the findings to a dashboard
+func NewWriter(writer output.Writer, dashboard *Dashboard) *Writer {
+	return &Writer{Writer: writer, dashboard: dashboard}
+}
+
+// Write adds the event to the dashboard and writes it to the wrapped writer
+func (w *Writer) Write(event *output.ResultEvent) error {
+	w.dashboard.Found(event)
+	return w.Writer.Write(event)
+}
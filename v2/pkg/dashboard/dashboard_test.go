@@ -0,0 +1,68 @@
+package dashboard
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/logrusorgru/aurora"
+	"github.com/projectdiscovery/nuclei/v2/pkg/output"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDashboardRender(t *testing.T) {
+	buffer := &bytes.Buffer{}
+	dashboard := New(buffer, time.Hour, aurora.NewAurora(false))
+	dashboard.start = time.Now()
+
+	dashboard.TemplateStarted("tech-detect")
+	dashboard.TemplateStarted("tech-detect")
+	dashboard.TemplateStarted("cve-2021-1234")
+	dashboard.TemplateFinished("cve-2021-1234")
+	dashboard.HostScanned("https://slow.example.com", 2*time.Second)
+	dashboard.HostScanned("https://fast.example.com", time.Millisecond)
+	dashboard.Found(&output.ResultEvent{TemplateID: "tech-detect", Info: map[string]interface{}{"severity": "high"}, Matched: "https://slow.example.com"})
+	dashboard.render()
+
+	rendered := buffer.String()
+	require.Contains(t, rendered, "Findings: critical 0 | high 1 | medium 0 | low 0 | info 0 | total 1", "could not render summary")
+	require.Contains(t, rendered, "Running templates (1)", "could not render running templates")
+	require.Contains(t, rendered, "tech-detect (2 hosts)", "could not render running template hosts")
+	require.NotContains(t, rendered, "cve-2021-1234", "could render finished template")
+	require.Less(t, strings.Index(rendered, "slow.example.com"), strings.Index(rendered, "fast.example.com"), "could not sort slowest hosts")
+}
+
+func TestDashboardPruneHosts(t *testing.T) {
+	dashboard := New(&bytes.Buffer{}, time.Hour, aurora.NewAurora(false))
+	dashboard.HostScanned("https://slowest.example.com", time.Hour)
+	for i := 0; i < maxTrackedHosts; i++ {
+		dashboard.HostScanned(fmt.Sprintf("https://%d.example.com", i), time.Duration(i)*time.Millisecond)
+	}
+	require.LessOrEqual(t, len(dashboard.hosts), maxTrackedHosts, "could not cap tracked hosts")
+	require.Contains(t, dashboard.hosts, "https://slowest.example.com", "could not keep slowest host")
+}
+
+func TestDashboardStop(t *testing.T) {
+	buffer := &bytes.Buffer{}
+	dashboard := New(buffer, time.Hour, aurora.NewAurora(false))
+	dashboard.Start()
+	dashboard.Stop()
+	dashboard.Stop()
+	require.Equal(t, 1, strings.Count(buffer.String(), "\x1b[?1049l\x1b[?25h"), "could not restore screen once")
+
+	// The dashboard is restarted by the scheduled scans
+	dashboard.Start()
+	dashboard.Stop()
+	require.Equal(t, 2, strings.Count(buffer.String(), "\x1b[?1049l\x1b[?25h"), "could not restore screen after restart")
+}
+
+func TestDashboardNil(t *testing.T) {
+	var dashboard *Dashboard
+	dashboard.Start()
+	dashboard.TemplateStarted("tech-detect")
+	dashboard.HostScanned("https://example.com", time.Second)
+	dashboard.Found(&output.ResultEvent{})
+	dashboard.Stop()
+}
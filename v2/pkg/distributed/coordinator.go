@@ -0,0 +1,271 @@
+package distributed
+
+import (
+	"context"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	jsoniter "github.com/json-iterator/go"
+	"github.com/pkg/errors"
+	"github.com/projectdiscovery/nuclei/v2/pkg/output"
+)
+
+// Coordinator hands out work units to workers over a REST api and
+// collects the results streamed back by them.
+type Coordinator struct {
+	options  *CoordinatorOptions
+	server   *http.Server
+	listener net.Listener
+
+	mutex     sync.Mutex
+	pending   []*WorkUnit
+	leased    map[string]*lease
+	remaining int
+	done      chan struct{}
+	// workers are the workers which leased units and didn't leave yet
+	workers map[string]struct{}
+	left    chan struct{}
+}
+
+// CoordinatorOptions contains configuration options for the coordinator
+type CoordinatorOptions struct {
+	// Address is the host:port to listen for workers on
+	Address string
+	// Token is the optional token workers must authenticate with
+	Token string
+	// LeaseTimeout is the time after which a unit not completed by
+	// a worker is handed out again to another worker.
+	LeaseTimeout time.Duration
+	// OnResult is called for each result received from the workers
+	OnResult func(*output.ResultEvent)
+}
+
+// lease is a work unit handed out to a worker
+type lease struct {
+	unit    *WorkUnit
+	expires time.Time
+}
+
+// NewCoordinator creates a new coordinator for a list of work units
+func NewCoordinator(options *CoordinatorOptions, units []*WorkUnit) (*Coordinator, error) {
+	if options.LeaseTimeout == 0 {
+		options.LeaseTimeout = 30 * time.Minute
+	}
+	listener, err := net.Listen("tcp", options.Address)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not listen for workers")
+	}
+	coordinator := &Coordinator{
+		options:   options,
+		listener:  listener,
+		pending:   units,
+		leased:    make(map[string]*lease),
+		remaining: len(units),
+		done:      make(chan struct{}),
+		workers:   make(map[string]struct{}),
+		left:      make(chan struct{}, 1),
+	}
+	if len(units) == 0 {
+		close(coordinator.done)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(leasePath, coordinator.handleLease)
+	mux.HandleFunc(completePath, coordinator.handleComplete)
+	mux.HandleFunc(leavePath, coordinator.handleLeave)
+	mux.HandleFunc(resultsPath, coordinator.handleResults)
+	coordinator.server = &http.Server{Handler: mux}
+
+	go func() {
+		_ = coordinator.server.Serve(listener)
+	}()
+	return coordinator, nil
+}
+
+// Addr returns the address the coordinator is listening on
+func (c *Coordinator) Addr() string {
+	return c.listener.Addr().String()
+}
+
+// Wait waits for all the work units to be completed and stops the coordinator.
+//
+// The workers which leased units keep sending the results of their late
+// interactions after the units are completed, so the coordinator waits
+// for them to leave for at most the timeout.
+func (c *Coordinator) Wait(timeout time.Duration) {
+	<-c.done
+	c.waitWorkers(timeout)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	_ = c.server.Shutdown(ctx)
+}
+
+// waitWorkers waits for all the workers to leave for at most the timeout
+func (c *Coordinator) waitWorkers(timeout time.Duration) {
+	deadline := time.NewTimer(timeout)
+	defer deadline.Stop()
+
+	for c.Workers() > 0 {
+		select {
+		case <-c.left:
+		case <-deadline.C:
+			return
+		}
+	}
+}
+
+// Workers returns the number of workers which leased units and didn't leave yet
+func (c *Coordinator) Workers() int {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return len(c.workers)
+}
+
+// Remaining returns the number of work units not completed yet
+func (c *Coordinator) Remaining() int {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return c.remaining
+}
+
+// next returns the next unit to be leased. If no unit is returned,
+// finished reports whether all the units have been completed.
+func (c *Coordinator) next() (unit *WorkUnit, finished bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	now := time.Now()
+	for id, leased := range c.leased {
+		if now.After(leased.expires) {
+			c.pending = append(c.pending, leased.unit)
+			delete(c.leased, id)
+		}
+	}
+	if len(c.pending) == 0 {
+		return nil, c.remaining == 0
+	}
+	unit = c.pending[0]
+	c.pending = c.pending[1:]
+	c.leased[unit.ID] = &lease{unit: unit, expires: now.Add(c.options.LeaseTimeout)}
+	return unit, false
+}
+
+// complete marks a leased unit as completed
+func (c *Coordinator) complete(id string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if _, ok := c.leased[id]; !ok {
+		return
+	}
+	delete(c.leased, id)
+	c.remaining--
+	if c.remaining == 0 {
+		close(c.done)
+	}
+}
+
+// join records a worker as active until it leaves
+func (c *Coordinator) join(worker string) {
+	if worker == "" {
+		return
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.workers[worker] = struct{}{}
+}
+
+// leave removes a worker from the active workers
+func (c *Coordinator) leave(worker string) {
+	c.mutex.Lock()
+	delete(c.workers, worker)
+	c.mutex.Unlock()
+
+	select {
+	case c.left <- struct{}{}:
+	default:
+	}
+}
+
+// handleLease leases the next work unit to a worker
+func (c *Coordinator) handleLease(w http.ResponseWriter, req *http.Request) {
+	if !c.authorize(w, req) {
+		return
+	}
+	unit, finished := c.next()
+	if unit == nil {
+		if finished {
+			w.WriteHeader(http.StatusGone)
+		} else {
+			w.WriteHeader(http.StatusNoContent)
+		}
+		return
+	}
+	c.join(req.Header.Get(workerHeader))
+	data, err := jsoniter.Marshal(unit)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(data)
+}
+
+// handleComplete marks a work unit as completed by a worker
+func (c *Coordinator) handleComplete(w http.ResponseWriter, req *http.Request) {
+	if !c.authorize(w, req) {
+		return
+	}
+	c.complete(req.URL.Query().Get("id"))
+	w.WriteHeader(http.StatusOK)
+}
+
+// handleLeave removes a worker done sending results
+func (c *Coordinator) handleLeave(w http.ResponseWriter, req *http.Request) {
+	if !c.authorize(w, req) {
+		return
+	}
+	c.leave(req.Header.Get(workerHeader))
+	w.WriteHeader(http.StatusOK)
+}
+
+// handleResults receives a result found by a worker
+func (c *Coordinator) handleResults(w http.ResponseWriter, req *http.Request) {
+	if !c.authorize(w, req) {
+		return
+	}
+	data, err := ioutil.ReadAll(req.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	event := &output.ResultEvent{}
+	if err := jsoniter.Unmarshal(data, event); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if c.options.OnResult != nil {
+		c.options.OnResult(event)
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+// authorize validates the method and token of a worker request
+func (c *Coordinator) authorize(w http.ResponseWriter, req *http.Request) bool {
+	if req.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return false
+	}
+	if !validToken(req, c.options.Token) {
+		w.WriteHeader(http.StatusUnauthorized)
+		return false
+	}
+	return true
+}
@@ -0,0 +1,82 @@
+package distributed
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// WorkUnit is a unit of work leased by a worker from the coordinator,
+// consisting of a set of targets to run a set of templates on.
+type WorkUnit struct {
+	// ID is the unique identifier of the work unit
+	ID string `json:"id"`
+	// Targets is the list of targets to scan
+	Targets []string `json:"targets"`
+	// Templates is the list of templates to run on targets
+	Templates []string `json:"templates,omitempty"`
+	// Workflows is the list of workflows to run on targets
+	Workflows []string `json:"workflows,omitempty"`
+}
+
+const (
+	leasePath    = "/v1/work/lease"
+	completePath = "/v1/work/complete"
+	leavePath    = "/v1/work/leave"
+	resultsPath  = "/v1/results"
+)
+
+// workerHeader is the header identifying the worker sending a request
+const workerHeader = "X-Nuclei-Worker"
+
+// Split splits the targets and templates into work units with at most
+// targetsPerUnit targets and templatesPerUnit templates or workflows each.
+func Split(targets, templates, workflows []string, targetsPerUnit, templatesPerUnit int) []*WorkUnit {
+	targetChunks := chunk(targets, targetsPerUnit)
+	templateChunks := chunk(templates, templatesPerUnit)
+	workflowChunks := chunk(workflows, templatesPerUnit)
+
+	var units []*WorkUnit
+	for _, targetChunk := range targetChunks {
+		for _, templateChunk := range templateChunks {
+			units = append(units, &WorkUnit{ID: strconv.Itoa(len(units) + 1), Targets: targetChunk, Templates: templateChunk})
+		}
+		for _, workflowChunk := range workflowChunks {
+			units = append(units, &WorkUnit{ID: strconv.Itoa(len(units) + 1), Targets: targetChunk, Workflows: workflowChunk})
+		}
+	}
+	return units
+}
+
+// chunk splits a list into chunks of at most size items
+func chunk(items []string, size int) [][]string {
+	if size <= 0 {
+		size = len(items)
+	}
+	var chunks [][]string
+	for len(items) > 0 {
+		if len(items) < size {
+			size = len(items)
+		}
+		chunks = append(chunks, items[:size])
+		items = items[size:]
+	}
+	return chunks
+}
+
+// setToken sets the authentication token on a request if any
+func setToken(req *http.Request, token string) {
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+}
+
+// validToken returns true if the request carries the expected token
+func validToken(req *http.Request, token string) bool {
+	if token == "" {
+		return true
+	}
+	value := strings.TrimPrefix(req.Header.Get("Authorization"), "Bearer ")
+	return subtle.ConstantTimeCompare([]byte(value), []byte(token)) == 1
+}
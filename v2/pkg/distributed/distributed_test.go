@@ -0,0 +1,99 @@
+package distributed
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/projectdiscovery/nuclei/v2/pkg/output"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSplit(t *testing.T) {
+	units := Split([]string{"a", "b", "c"}, []string{"t1", "t2", "t3", "t4"}, []string{"w1"}, 2, 3)
+	require.Len(t, units, 6, "could not split into correct units")
+
+	require.Equal(t, []string{"a", "b"}, units[0].Targets, "could not get correct targets")
+	require.Equal(t, []string{"t1", "t2", "t3"}, units[0].Templates, "could not get correct templates")
+	require.Equal(t, []string{"t4"}, units[1].Templates, "could not get remaining templates")
+	require.Equal(t, []string{"w1"}, units[2].Workflows, "could not get workflows")
+	require.Equal(t, []string{"c"}, units[3].Targets, "could not get remaining targets")
+}
+
+func TestCoordinatorWorker(t *testing.T) {
+	var mutex sync.Mutex
+	var results []*output.ResultEvent
+
+	coordinator, err := NewCoordinator(&CoordinatorOptions{
+		Address: "127.0.0.1:0",
+		Token:   "secret",
+		OnResult: func(event *output.ResultEvent) {
+			mutex.Lock()
+			results = append(results, event)
+			mutex.Unlock()
+		},
+	}, Split([]string{"a", "b"}, []string{"t1"}, nil, 1, 1))
+	require.Nil(t, err, "could not create coordinator")
+
+	unauthorized, err := NewWorker(&WorkerOptions{Coordinator: coordinator.Addr(), Token: "invalid"})
+	require.Nil(t, err, "could not create worker")
+	_, err = unauthorized.Next()
+	require.NotNil(t, err, "could lease work with invalid token")
+
+	worker, err := NewWorker(&WorkerOptions{Coordinator: coordinator.Addr(), Token: "secret", PollInterval: 10 * time.Millisecond})
+	require.Nil(t, err, "could not create worker")
+
+	for {
+		unit, err := worker.Next()
+		require.Nil(t, err, "could not lease work unit")
+		if unit == nil {
+			break
+		}
+		err = worker.SendResult(&output.ResultEvent{TemplateID: unit.Templates[0], Host: unit.Targets[0]})
+		require.Nil(t, err, "could not send result")
+		require.Nil(t, worker.Complete(unit), "could not complete work unit")
+	}
+	require.Equal(t, 1, coordinator.Workers(), "could not track worker")
+
+	waited := make(chan struct{})
+	go func() {
+		coordinator.Wait(time.Minute)
+		close(waited)
+	}()
+	// Results of late interactions are sent after the units are completed
+	err = worker.SendResult(&output.ResultEvent{TemplateID: "t1", Host: "interaction"})
+	require.Nil(t, err, "could not send result after completing units")
+	require.Nil(t, worker.Leave(), "could not leave coordinator")
+	select {
+	case <-waited:
+	case <-time.After(10 * time.Second):
+		t.Fatal("could not stop coordinator after worker left")
+	}
+
+	require.Equal(t, 0, coordinator.Remaining(), "could not complete all units")
+	require.Equal(t, 0, coordinator.Workers(), "could not remove worker")
+	require.Len(t, results, 3, "could not receive all results")
+	require.Equal(t, "t1", results[0].TemplateID, "could not get correct result")
+}
+
+func TestCoordinatorLeaseExpiry(t *testing.T) {
+	coordinator, err := NewCoordinator(&CoordinatorOptions{Address: "127.0.0.1:0", LeaseTimeout: 10 * time.Millisecond}, Split([]string{"a"}, []string{"t1"}, nil, 1, 1))
+	require.Nil(t, err, "could not create coordinator")
+	defer coordinator.Wait(0)
+
+	unit, finished := coordinator.next()
+	require.NotNil(t, unit, "could not lease unit")
+	require.False(t, finished, "could finish with leased unit")
+
+	unit, finished = coordinator.next()
+	require.Nil(t, unit, "could lease unit twice")
+	require.False(t, finished, "could finish with leased unit")
+
+	time.Sleep(20 * time.Millisecond)
+	unit, _ = coordinator.next()
+	require.NotNil(t, unit, "could not lease expired unit again")
+
+	coordinator.complete(unit.ID)
+	_, finished = coordinator.next()
+	require.True(t, finished, "could not finish after completing unit")
+}
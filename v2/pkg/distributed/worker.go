@@ -0,0 +1,160 @@
+package distributed
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	jsoniter "github.com/json-iterator/go"
+	"github.com/pkg/errors"
+	"github.com/projectdiscovery/nuclei/v2/pkg/output"
+	"github.com/rs/xid"
+)
+
+// Worker pulls work units from a coordinator and streams results back to it
+type Worker struct {
+	id         string
+	options    *WorkerOptions
+	httpClient *http.Client
+}
+
+// WorkerOptions contains configuration options for the worker
+type WorkerOptions struct {
+	// Coordinator is the url of the coordinator (eg. http://10.0.0.1:8822)
+	Coordinator string
+	// Token is the optional token to authenticate with the coordinator
+	Token string
+	// PollInterval is the time to wait before asking for work again
+	// when the coordinator has no units available.
+	PollInterval time.Duration
+}
+
+// NewWorker creates a new worker for a coordinator
+func NewWorker(options *WorkerOptions) (*Worker, error) {
+	if !strings.Contains(options.Coordinator, "://") {
+		options.Coordinator = "http://" + options.Coordinator
+	}
+	if _, err := url.Parse(options.Coordinator); err != nil {
+		return nil, errors.Wrap(err, "could not parse coordinator url")
+	}
+	options.Coordinator = strings.TrimSuffix(options.Coordinator, "/")
+	if options.PollInterval == 0 {
+		options.PollInterval = 5 * time.Second
+	}
+	return &Worker{id: xid.New().String(), options: options, httpClient: &http.Client{Timeout: 30 * time.Second}}, nil
+}
+
+// Next returns the next work unit to process, waiting for the coordinator
+// to have one available. A nil unit is returned once the scan is finished.
+func (w *Worker) Next() (*WorkUnit, error) {
+	for {
+		resp, err := w.post(leasePath, nil)
+		if err != nil {
+			return nil, err
+		}
+		data, err := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return nil, errors.Wrap(err, "could not read work unit")
+		}
+
+		switch resp.StatusCode {
+		case http.StatusOK:
+			unit := &WorkUnit{}
+			if err := jsoniter.Unmarshal(data, unit); err != nil {
+				return nil, errors.Wrap(err, "could not decode work unit")
+			}
+			return unit, nil
+		case http.StatusNoContent:
+			time.Sleep(w.options.PollInterval)
+		case http.StatusGone:
+			return nil, nil
+		default:
+			return nil, fmt.Errorf("unexpected status code from coordinator: %d", resp.StatusCode)
+		}
+	}
+}
+
+// Complete marks a work unit as completed on the coordinator
+func (w *Worker) Complete(unit *WorkUnit) error {
+	resp, err := w.post(completePath+"?id="+url.QueryEscape(unit.ID), nil)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status code from coordinator: %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// Leave tells the coordinator the worker won't send any more results
+func (w *Worker) Leave() error {
+	resp, err := w.post(leavePath, nil)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status code from coordinator: %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// SendResult streams a result event back to the coordinator
+func (w *Worker) SendResult(event *output.ResultEvent) error {
+	data, err := jsoniter.Marshal(event)
+	if err != nil {
+		return errors.Wrap(err, "could not marshal result")
+	}
+	resp, err := w.post(resultsPath, data)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status code from coordinator: %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// post sends a post request with optional body to the coordinator
+func (w *Worker) post(path string, body []byte) (*http.Response, error) {
+	req, err := http.NewRequest(http.MethodPost, w.options.Coordinator+path, bytes.NewReader(body))
+	if err != nil {
+		return nil, errors.Wrap(err, "could not create request")
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(workerHeader, w.id)
+	setToken(req, w.options.Token)
+
+	resp, err := w.httpClient.Do(req)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not connect to coordinator")
+	}
+	return resp, nil
+}
+
+// Writer is an output writer which streams results to the coordinator
+// in addition to writing them with the wrapped writer.
+type Writer struct {
+	output.Writer
+	worker *Worker
+}
+
+// NewWriter creates a new writer streaming results with a worker
+func NewWriter(writer output.Writer, worker *Worker) *Writer {
+	return &Writer{Writer: writer, worker: worker}
+}
+
+// Write writes the event to the wrapped writer and sends it to the coordinator.
+func (w *Writer) Write(event *output.ResultEvent) error {
+	if err := w.Writer.Write(event); err != nil {
+		return err
+	}
+	return w.worker.SendResult(event)
+}
@@ -0,0 +1,5 @@
+// Package grpcapi contains the messages and the service of the grpc scan
+// api generated from nuclei.proto.
+package grpcapi
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative nuclei.proto
@@ -0,0 +1,56 @@
+package grpcapi
+
+import (
+	"context"
+	"io"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// testServer streams a result and the final progress of each scan
+type testServer struct {
+	UnimplementedNucleiServer
+	request *ScanRequest
+}
+
+func (s *testServer) Scan(request *ScanRequest, stream Nuclei_ScanServer) error {
+	s.request = request
+	if err := stream.Send(&ScanEvent{Event: &ScanEvent_Result{Result: &Result{TemplateId: "test", Timestamp: 1}}}); err != nil {
+		return err
+	}
+	return stream.Send(&ScanEvent{Event: &ScanEvent_Progress{Progress: &Progress{State: "finished", Matched: 1}}})
+}
+
+func TestScanStream(t *testing.T) {
+	listener := bufconn.Listen(1024 * 1024)
+	server := grpc.NewServer()
+	service := &testServer{}
+	RegisterNucleiServer(server, service)
+	go func() { _ = server.Serve(listener) }()
+	defer server.Stop()
+
+	conn, err := grpc.Dial("bufnet", grpc.WithInsecure(), grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) {
+		return listener.Dial()
+	}))
+	require.Nil(t, err, "could not dial server")
+	defer conn.Close()
+
+	stream, err := NewNucleiClient(conn).Scan(context.Background(), &ScanRequest{Targets: []string{"https://example.com"}, Tags: []string{"tech"}, RateLimit: 150})
+	require.Nil(t, err, "could not start scan")
+
+	event, err := stream.Recv()
+	require.Nil(t, err, "could not receive result event")
+	require.Equal(t, "test", event.GetResult().GetTemplateId(), "could not get result")
+	event, err = stream.Recv()
+	require.Nil(t, err, "could not receive progress event")
+	require.Equal(t, "finished", event.GetProgress().GetState(), "could not get progress")
+	_, err = stream.Recv()
+	require.Equal(t, io.EOF, err, "could not finish stream")
+
+	require.Equal(t, []string{"https://example.com"}, service.request.GetTargets(), "could not get request targets")
+	require.Equal(t, int64(150), service.request.GetRateLimit(), "could not get request rate limit")
+}
@@ -0,0 +1,132 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+
+package grpcapi
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+// NucleiClient is the client API for Nuclei service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type NucleiClient interface {
+	// Scan starts a scan streaming its results and progress until it is
+	// finished. Cancelling the call cancels the scan.
+	Scan(ctx context.Context, in *ScanRequest, opts ...grpc.CallOption) (Nuclei_ScanClient, error)
+}
+
+type nucleiClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewNucleiClient(cc grpc.ClientConnInterface) NucleiClient {
+	return &nucleiClient{cc}
+}
+
+func (c *nucleiClient) Scan(ctx context.Context, in *ScanRequest, opts ...grpc.CallOption) (Nuclei_ScanClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Nuclei_ServiceDesc.Streams[0], "/nuclei.v1.Nuclei/Scan", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &nucleiScanClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Nuclei_ScanClient interface {
+	Recv() (*ScanEvent, error)
+	grpc.ClientStream
+}
+
+type nucleiScanClient struct {
+	grpc.ClientStream
+}
+
+func (x *nucleiScanClient) Recv() (*ScanEvent, error) {
+	m := new(ScanEvent)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// NucleiServer is the server API for Nuclei service.
+// All implementations must embed UnimplementedNucleiServer
+// for forward compatibility
+type NucleiServer interface {
+	// Scan starts a scan streaming its results and progress until it is
+	// finished. Cancelling the call cancels the scan.
+	Scan(*ScanRequest, Nuclei_ScanServer) error
+	mustEmbedUnimplementedNucleiServer()
+}
+
+// UnimplementedNucleiServer must be embedded to have forward compatible implementations.
+type UnimplementedNucleiServer struct {
+}
+
+func (UnimplementedNucleiServer) Scan(*ScanRequest, Nuclei_ScanServer) error {
+	return status.Errorf(codes.Unimplemented, "method Scan not implemented")
+}
+func (UnimplementedNucleiServer) mustEmbedUnimplementedNucleiServer() {}
+
+// UnsafeNucleiServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to NucleiServer will
+// result in compilation errors.
+type UnsafeNucleiServer interface {
+	mustEmbedUnimplementedNucleiServer()
+}
+
+func RegisterNucleiServer(s grpc.ServiceRegistrar, srv NucleiServer) {
+	s.RegisterService(&Nuclei_ServiceDesc, srv)
+}
+
+func _Nuclei_Scan_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ScanRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(NucleiServer).Scan(m, &nucleiScanServer{stream})
+}
+
+type Nuclei_ScanServer interface {
+	Send(*ScanEvent) error
+	grpc.ServerStream
+}
+
+type nucleiScanServer struct {
+	grpc.ServerStream
+}
+
+func (x *nucleiScanServer) Send(m *ScanEvent) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+// Nuclei_ServiceDesc is the grpc.ServiceDesc for Nuclei service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Nuclei_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "nuclei.v1.Nuclei",
+	HandlerType: (*NucleiServer)(nil),
+	Methods:     []grpc.MethodDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Scan",
+			Handler:       _Nuclei_Scan_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "nuclei.proto",
+}
@@ -0,0 +1,133 @@
+// Package apispec parses OpenAPI 3 specifications and Postman collections
+// into concrete requests to be used as scan targets.
+package apispec
+
+import (
+	"io/ioutil"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+	"github.com/projectdiscovery/nuclei/v2/pkg/types"
+	"gopkg.in/yaml.v2"
+)
+
+// Request is a concrete request of a documented API operation
+type Request struct {
+	// Method is the method of the request
+	Method string
+	// URL is the full URL of the request including the query
+	URL string
+	// Headers are the headers of the request
+	Headers map[string]string
+	// Body is the body of the request
+	Body string
+}
+
+// Load parses an OpenAPI 3 specification or Postman collection file into
+// requests. Variables are used for parameter values and collection variables.
+func Load(file string, variables map[string]string) ([]*Request, error) {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	var document interface{}
+	if err := yaml.Unmarshal(data, &document); err != nil {
+		return nil, errors.Wrap(err, "could not decode api specification")
+	}
+	spec, ok := normalize(document).(map[string]interface{})
+	if !ok {
+		return nil, errors.New("invalid api specification")
+	}
+
+	if _, ok := spec["openapi"]; ok {
+		return parseOpenAPI(spec, variables)
+	}
+	if _, ok := spec["item"]; ok {
+		return parsePostman(spec, variables)
+	}
+	return nil, errors.New("unknown api specification format (it should be openapi 3 or postman collection)")
+}
+
+// Store contains the requests of the API operations for each target URL
+type Store struct {
+	mutex    sync.RWMutex
+	requests map[string][]*Request
+}
+
+// NewStore creates a new store of requests
+func NewStore() *Store {
+	return &Store{requests: make(map[string][]*Request)}
+}
+
+// Add adds a request to the store
+func (s *Store) Add(request *Request) {
+	s.mutex.Lock()
+	s.requests[request.URL] = append(s.requests[request.URL], request)
+	s.mutex.Unlock()
+}
+
+// Get returns the requests for a target URL. It is safe to call on a nil store.
+func (s *Store) Get(URL string) []*Request {
+	if s == nil {
+		return nil
+	}
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return s.requests[URL]
+}
+
+// normalize converts the decoded yaml maps to string keyed maps
+func normalize(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[interface{}]interface{}:
+		normalized := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			normalized[types.ToString(key)] = normalize(item)
+		}
+		return normalized
+	case map[string]interface{}:
+		for key, item := range v {
+			v[key] = normalize(item)
+		}
+		return v
+	case []interface{}:
+		for i, item := range v {
+			v[i] = normalize(item)
+		}
+		return v
+	}
+	return value
+}
+
+func asMap(value interface{}) map[string]interface{} {
+	m, _ := value.(map[string]interface{})
+	return m
+}
+
+func asSlice(value interface{}) []interface{} {
+	s, _ := value.([]interface{})
+	return s
+}
+
+// sortedKeys returns the keys of a map in order
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// encodeQuery encodes query parameters keeping their order
+func encodeQuery(query [][2]string) string {
+	parts := make([]string, 0, len(query))
+	for _, param := range query {
+		parts = append(parts, url.QueryEscape(param[0])+"="+url.QueryEscape(param[1]))
+	}
+	return strings.Join(parts, "&")
+}
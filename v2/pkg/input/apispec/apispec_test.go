@@ -0,0 +1,84 @@
+package apispec
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func writeSpec(t *testing.T, data string) string {
+	file, err := ioutil.TempFile("", "apispec-*.json")
+	require.Nil(t, err, "could not create spec file")
+	_, err = file.WriteString(data)
+	require.Nil(t, err, "could not write spec file")
+	file.Close()
+	return file.Name()
+}
+
+func TestLoadOpenAPI(t *testing.T) {
+	file := writeSpec(t, `{
+  "openapi": "3.0.0",
+  "servers": [{"url": "https://{env}.example.com/v1", "variables": {"env": {"default": "api"}}}],
+  "paths": {
+    "/users/{id}": {
+      "parameters": [{"name": "id", "in": "path", "schema": {"type": "integer"}}],
+      "get": {"parameters": [{"name": "fields", "in": "query", "schema": {"type": "string", "enum": ["name", "email"]}}]},
+      "put": {
+        "parameters": [{"name": "X-Token", "in": "header", "example": "secret"}],
+        "requestBody": {"content": {"application/json": {"schema": {"$ref": "#/components/schemas/User"}}}}
+      }
+    }
+  },
+  "components": {"schemas": {"User": {"type": "object", "properties": {"name": {"type": "string", "example": "admin"}, "admin": {"type": "boolean"}}}}}
+}`)
+	defer os.Remove(file)
+
+	requests, err := Load(file, map[string]string{"id": "42"})
+	require.Nil(t, err, "could not load openapi spec")
+	require.Len(t, requests, 2, "could not get all operations")
+
+	require.Equal(t, "GET", requests[0].Method, "could not get operation method")
+	require.Equal(t, "https://api.example.com/v1/users/42?fields=name", requests[0].URL, "could not build operation url")
+
+	require.Equal(t, "PUT", requests[1].Method, "could not get operation method")
+	require.Equal(t, "secret", requests[1].Headers["X-Token"], "could not get header parameter")
+	require.Equal(t, "application/json", requests[1].Headers["Content-Type"], "could not get body content type")
+	require.Equal(t, `{"admin":true,"name":"admin"}`, requests[1].Body, "could not generate body from schema")
+
+	_, err = Load(file, map[string]string{"BaseURL": "http://localhost:8080/"})
+	require.Nil(t, err, "could not load openapi spec with base url")
+}
+
+func TestLoadPostman(t *testing.T) {
+	file := writeSpec(t, `{
+  "info": {"name": "test"},
+  "variable": [{"key": "host", "value": "https://example.com"}, {"key": "token", "value": "default"}],
+  "item": [
+    {"name": "folder", "item": [
+      {"name": "login", "request": {
+        "method": "post",
+        "url": {"raw": "{{host}}/login"},
+        "header": [{"key": "Authorization", "value": "Bearer {{token}}"}, {"key": "X-Debug", "value": "1", "disabled": true}],
+        "body": {"mode": "urlencoded", "urlencoded": [{"key": "user", "value": "admin"}]}
+      }}
+    ]},
+    {"name": "status", "request": "{{host}}/status"}
+  ]
+}`)
+	defer os.Remove(file)
+
+	requests, err := Load(file, map[string]string{"token": "supplied"})
+	require.Nil(t, err, "could not load postman collection")
+	require.Len(t, requests, 2, "could not get all requests")
+
+	require.Equal(t, "POST", requests[0].Method, "could not get request method")
+	require.Equal(t, "https://example.com/login", requests[0].URL, "could not replace collection variables")
+	require.Equal(t, "Bearer supplied", requests[0].Headers["Authorization"], "could not replace supplied variables")
+	require.NotContains(t, requests[0].Headers, "X-Debug", "could not skip disabled header")
+	require.Equal(t, "user=admin", requests[0].Body, "could not encode form body")
+
+	require.Equal(t, "GET", requests[1].Method, "could not get default method")
+	require.Equal(t, "https://example.com/status", requests[1].URL, "could not get string request url")
+}
@@ -0,0 +1,193 @@
+package apispec
+
+import (
+	"encoding/json"
+	"net/url"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/projectdiscovery/nuclei/v2/pkg/types"
+)
+
+// openAPIMethods are the operation methods of an OpenAPI path item
+var openAPIMethods = []string{"get", "put", "post", "delete", "options", "head", "patch"}
+
+// maxSchemaDepth is the maximum depth of nested schemas generated for bodies
+const maxSchemaDepth = 5
+
+type openAPI struct {
+	spec      map[string]interface{}
+	variables map[string]string
+}
+
+// parseOpenAPI parses the operations of an OpenAPI 3 specification
+func parseOpenAPI(spec map[string]interface{}, variables map[string]string) ([]*Request, error) {
+	parser := &openAPI{spec: spec, variables: variables}
+
+	baseURL, err := parser.baseURL()
+	if err != nil {
+		return nil, err
+	}
+
+	var requests []*Request
+	paths := asMap(spec["paths"])
+	for _, path := range sortedKeys(paths) {
+		item := asMap(parser.resolve(paths[path]))
+		for _, method := range openAPIMethods {
+			operation := asMap(item[method])
+			if operation == nil {
+				continue
+			}
+			parameters := append(asSlice(item["parameters"]), asSlice(operation["parameters"])...)
+			requests = append(requests, parser.request(baseURL, path, strings.ToUpper(method), parameters, operation))
+		}
+	}
+	return requests, nil
+}
+
+// baseURL returns the base URL of the API from the BaseURL variable or the first server
+func (o *openAPI) baseURL() (string, error) {
+	if baseURL, ok := o.variables["BaseURL"]; ok {
+		return strings.TrimSuffix(baseURL, "/"), nil
+	}
+	servers := asSlice(o.spec["servers"])
+	if len(servers) == 0 {
+		return "", errors.New("no servers in specification, provide the BaseURL variable")
+	}
+	server := asMap(servers[0])
+	serverURL := types.ToString(server["url"])
+	for name, variable := range asMap(server["variables"]) {
+		value, ok := o.variables[name]
+		if !ok {
+			value = types.ToString(asMap(variable)["default"])
+		}
+		serverURL = strings.ReplaceAll(serverURL, "{"+name+"}", value)
+	}
+	if parsed, err := url.Parse(serverURL); err != nil || parsed.Host == "" {
+		return "", errors.Errorf("server url %s is not absolute, provide the BaseURL variable", serverURL)
+	}
+	return strings.TrimSuffix(serverURL, "/"), nil
+}
+
+// request builds the request of an operation with example values
+func (o *openAPI) request(baseURL, path, method string, parameters []interface{}, operation map[string]interface{}) *Request {
+	request := &Request{Method: method, Headers: make(map[string]string)}
+
+	var query [][2]string
+	for _, item := range parameters {
+		parameter := asMap(o.resolve(item))
+		name := types.ToString(parameter["name"])
+		value := o.parameterValue(parameter)
+
+		switch parameter["in"] {
+		case "path":
+			path = strings.ReplaceAll(path, "{"+name+"}", url.PathEscape(value))
+		case "query":
+			query = append(query, [2]string{name, value})
+		case "header":
+			request.Headers[name] = value
+		}
+	}
+	request.URL = baseURL + path
+	if len(query) > 0 {
+		request.URL += "?" + encodeQuery(query)
+	}
+
+	body := asMap(o.resolve(operation["requestBody"]))
+	content := asMap(body["content"])
+	for _, contentType := range sortedKeys(content) {
+		media := asMap(content[contentType])
+		value := media["example"]
+		if value == nil {
+			value = o.example(media["schema"], 0)
+		}
+		switch {
+		case strings.Contains(contentType, "json"):
+			data, _ := json.Marshal(value)
+			request.Body = string(data)
+		case contentType == "application/x-www-form-urlencoded":
+			var form [][2]string
+			object := asMap(value)
+			for _, key := range sortedKeys(object) {
+				form = append(form, [2]string{key, types.ToString(object[key])})
+			}
+			request.Body = encodeQuery(form)
+		default:
+			continue
+		}
+		request.Headers["Content-Type"] = contentType
+		break
+	}
+	return request
+}
+
+// parameterValue returns the value of a parameter from the variables or its examples
+func (o *openAPI) parameterValue(parameter map[string]interface{}) string {
+	if value, ok := o.variables[types.ToString(parameter["name"])]; ok {
+		return value
+	}
+	if example, ok := parameter["example"]; ok {
+		return types.ToString(example)
+	}
+	return types.ToString(o.example(parameter["schema"], 0))
+}
+
+// example returns an example value of a schema
+func (o *openAPI) example(value interface{}, depth int) interface{} {
+	schema := asMap(o.resolve(value))
+	if schema == nil {
+		return "test"
+	}
+	for _, key := range []string{"example", "default"} {
+		if example, ok := schema[key]; ok {
+			return example
+		}
+	}
+	if enum := asSlice(schema["enum"]); len(enum) > 0 {
+		return enum[0]
+	}
+	if depth > maxSchemaDepth {
+		return nil
+	}
+
+	switch schema["type"] {
+	case "integer", "number":
+		return 1
+	case "boolean":
+		return true
+	case "array":
+		return []interface{}{o.example(schema["items"], depth+1)}
+	case "object":
+	default:
+		if _, ok := schema["properties"]; !ok {
+			return "test"
+		}
+	}
+	object := make(map[string]interface{})
+	properties := asMap(schema["properties"])
+	for name, property := range properties {
+		if value, ok := o.variables[name]; ok {
+			object[name] = value
+			continue
+		}
+		object[name] = o.example(property, depth+1)
+	}
+	return object
+}
+
+// resolve resolves a local $ref of the specification
+func (o *openAPI) resolve(value interface{}) interface{} {
+	for i := 0; i < maxSchemaDepth; i++ {
+		ref, ok := asMap(value)["$ref"].(string)
+		if !ok || !strings.HasPrefix(ref, "#/") {
+			return value
+		}
+		var current interface{} = o.spec
+		for _, part := range strings.Split(strings.TrimPrefix(ref, "#/"), "/") {
+			part = strings.ReplaceAll(strings.ReplaceAll(part, "~1", "/"), "~0", "~")
+			current = asMap(current)[part]
+		}
+		value = current
+	}
+	return value
+}
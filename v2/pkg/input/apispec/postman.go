@@ -0,0 +1,98 @@
+package apispec
+
+import (
+	"strings"
+
+	"github.com/projectdiscovery/nuclei/v2/pkg/types"
+)
+
+// parsePostman parses the requests of a Postman v2 collection
+func parsePostman(collection map[string]interface{}, variables map[string]string) ([]*Request, error) {
+	values := make(map[string]string)
+	for _, item := range asSlice(collection["variable"]) {
+		variable := asMap(item)
+		values[types.ToString(variable["key"])] = types.ToString(variable["value"])
+	}
+	// Supplied variables take precedence over the collection ones
+	for key, value := range variables {
+		values[key] = value
+	}
+
+	var requests []*Request
+	var walk func(items []interface{})
+	walk = func(items []interface{}) {
+		for _, value := range items {
+			item := asMap(value)
+			if children, ok := item["item"]; ok {
+				walk(asSlice(children))
+				continue
+			}
+			if request := postmanRequest(item["request"], values); request != nil {
+				requests = append(requests, request)
+			}
+		}
+	}
+	walk(asSlice(collection["item"]))
+	return requests, nil
+}
+
+// postmanRequest converts a postman request to a request
+func postmanRequest(value interface{}, variables map[string]string) *Request {
+	replace := func(data string) string {
+		for key, value := range variables {
+			data = strings.ReplaceAll(data, "{{"+key+"}}", value)
+		}
+		return data
+	}
+
+	// Requests can be just the URL string
+	if rawURL, ok := value.(string); ok {
+		return &Request{Method: "GET", URL: replace(rawURL), Headers: make(map[string]string)}
+	}
+	item := asMap(value)
+	if item == nil {
+		return nil
+	}
+
+	request := &Request{Method: strings.ToUpper(types.ToString(item["method"])), Headers: make(map[string]string)}
+	if request.Method == "" {
+		request.Method = "GET"
+	}
+	switch requestURL := item["url"].(type) {
+	case string:
+		request.URL = replace(requestURL)
+	case map[string]interface{}:
+		request.URL = replace(types.ToString(requestURL["raw"]))
+	}
+	if request.URL == "" {
+		return nil
+	}
+
+	for _, value := range asSlice(item["header"]) {
+		header := asMap(value)
+		if disabled, _ := header["disabled"].(bool); disabled {
+			continue
+		}
+		request.Headers[types.ToString(header["key"])] = replace(types.ToString(header["value"]))
+	}
+
+	body := asMap(item["body"])
+	switch body["mode"] {
+	case "raw":
+		request.Body = replace(types.ToString(body["raw"]))
+	case "urlencoded":
+		var form [][2]string
+		for _, value := range asSlice(body["urlencoded"]) {
+			param := asMap(value)
+			if disabled, _ := param["disabled"].(bool); disabled {
+				continue
+			}
+			form = append(form, [2]string{types.ToString(param["key"]), replace(types.ToString(param["value"]))})
+		}
+		request.Body = encodeQuery(form)
+		if _, ok := request.Headers["Content-Type"]; !ok {
+			request.Headers["Content-Type"] = "application/x-www-form-urlencoded"
+		}
+	}
+	return request
+}
@@ -0,0 +1,299 @@
+// Package crawler discovers the in-scope URLs, forms and parameters of
+// seed targets to be scanned by fuzzing templates.
+package crawler
+
+import (
+	"crypto/tls"
+	"html"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/projectdiscovery/nuclei/v2/pkg/input/apispec"
+	"github.com/remeh/sizedwaitgroup"
+)
+
+// Scopes for the crawled URLs
+const (
+	// ScopeHost only crawls URLs on the host of the seed
+	ScopeHost = "host"
+	// ScopeSubdomain crawls URLs on the host of the seed and its subdomains
+	ScopeSubdomain = "subdomain"
+)
+
+// maxBodySize is the maximum size of a page read for links
+const maxBodySize = 2 * 1024 * 1024
+
+var (
+	linkRegex  = regexp.MustCompile(`(?i)<(?:a|area|link|iframe|frame|script)\b[^>]*?\s(?:href|src)\s*=\s*(?:"([^"]*)"|'([^']*)'|([^\s>]+))`)
+	formRegex  = regexp.MustCompile(`(?is)<form\b([^>]*)>(.*?)</form>`)
+	inputRegex = regexp.MustCompile(`(?i)<(?:input|select|textarea)\b[^>]*>`)
+	attrRegex  = regexp.MustCompile(`(?i)\s([a-z-]+)\s*=\s*(?:"([^"]*)"|'([^']*)'|([^\s>]+))`)
+)
+
+// staticExtensions are the extensions of files that are neither crawled nor returned
+var staticExtensions = map[string]struct{}{
+	".png": {}, ".jpg": {}, ".jpeg": {}, ".gif": {}, ".svg": {}, ".ico": {}, ".webp": {}, ".bmp": {},
+	".css": {}, ".woff": {}, ".woff2": {}, ".ttf": {}, ".eot": {}, ".otf": {},
+	".mp3": {}, ".mp4": {}, ".avi": {}, ".webm": {}, ".pdf": {}, ".zip": {}, ".gz": {},
+}
+
+// Options contains the configuration options for the crawler
+type Options struct {
+	// Depth is the maximum number of links followed from the seed
+	Depth int
+	// Scope is the scope of the crawled URLs - host or subdomain
+	Scope string
+	// MaxURLs is the maximum number of URLs discovered per seed
+	MaxURLs int
+	// Timeout is the timeout of each request
+	Timeout time.Duration
+	// Concurrency is the number of pages of a seed fetched in parallel
+	Concurrency int
+	// Client is the http client to crawl with (a default one is used if nil)
+	Client *http.Client
+}
+
+// Result contains the URLs and forms discovered by crawling a seed
+type Result struct {
+	// URLs are the in-scope URLs including the URLs of GET forms
+	URLs []string
+	// Forms are the requests of the POST forms with their parameters
+	Forms []*apispec.Request
+}
+
+// Crawler is a simple crawler discovering URLs from pages
+type Crawler struct {
+	options *Options
+	client  *http.Client
+}
+
+// New creates a new crawler with options
+func New(options *Options) *Crawler {
+	client := options.Client
+	if client == nil {
+		client = &http.Client{
+			Timeout: options.Timeout,
+			Transport: &http.Transport{
+				Proxy:           http.ProxyFromEnvironment,
+				TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+			},
+		}
+	}
+	return &Crawler{options: options, client: client}
+}
+
+// ValidateScope returns an error if the scope is not supported
+func ValidateScope(scope string) error {
+	switch scope {
+	case "", ScopeHost, ScopeSubdomain:
+		return nil
+	}
+	return errors.Errorf("invalid crawl scope %s (it should be host or subdomain)", scope)
+}
+
+// Crawl crawls the seed URL returning the in-scope URLs discovered,
+// including the URLs of GET forms with their parameters, and the
+// requests of the POST forms.
+func (c *Crawler) Crawl(seed string) (*Result, error) {
+	seedURL, err := url.Parse(seed)
+	if err != nil || seedURL.Host == "" {
+		return nil, errors.Errorf("invalid seed url %s", seed)
+	}
+
+	seen := map[string]struct{}{normalize(seedURL): {}}
+	result := &Result{}
+	limitReached := func() bool {
+		return c.options.MaxURLs > 0 && len(result.URLs)+len(result.Forms) >= c.options.MaxURLs
+	}
+	queue := []*url.URL{seedURL}
+	for depth := 0; depth < c.options.Depth && len(queue) > 0; depth++ {
+		var next []*url.URL
+		for _, page := range c.fetch(queue) {
+			for _, link := range page.links {
+				if !c.inScope(seedURL, link) || isStatic(link) {
+					continue
+				}
+				key := normalize(link)
+				if _, ok := seen[key]; ok {
+					continue
+				}
+				seen[key] = struct{}{}
+				result.URLs = append(result.URLs, key)
+				if limitReached() {
+					return result, nil
+				}
+				next = append(next, link)
+			}
+			for _, form := range page.forms {
+				link, _ := url.Parse(form.URL)
+				if !c.inScope(seedURL, link) {
+					continue
+				}
+				key := form.Method + " " + form.URL + " " + form.Body
+				if _, ok := seen[key]; ok {
+					continue
+				}
+				seen[key] = struct{}{}
+				result.Forms = append(result.Forms, form)
+				if limitReached() {
+					return result, nil
+				}
+			}
+		}
+		queue = next
+	}
+	return result, nil
+}
+
+// crawledPage contains the links and POST forms found on a page
+type crawledPage struct {
+	links []*url.URL
+	forms []*apispec.Request
+}
+
+// fetch fetches the pages in parallel returning them in the same order
+func (c *Crawler) fetch(queue []*url.URL) []crawledPage {
+	concurrency := c.options.Concurrency
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+	pages := make([]crawledPage, len(queue))
+	wg := sizedwaitgroup.New(concurrency)
+	for i, link := range queue {
+		wg.Add()
+		go func(i int, link *url.URL) {
+			defer wg.Done()
+			links, forms := c.links(link)
+			pages[i] = crawledPage{links: links, forms: forms}
+		}(i, link)
+	}
+	wg.Wait()
+	return pages
+}
+
+// links returns the links, GET form URLs and POST forms found on a page
+func (c *Crawler) links(page *url.URL) ([]*url.URL, []*apispec.Request) {
+	resp, err := c.client.Get(page.String())
+	if err != nil {
+		return nil, nil
+	}
+	defer resp.Body.Close()
+	if !strings.Contains(resp.Header.Get("Content-Type"), "html") {
+		return nil, nil
+	}
+	data, err := ioutil.ReadAll(io.LimitReader(resp.Body, maxBodySize))
+	if err != nil {
+		return nil, nil
+	}
+	// Relative links are resolved against the final URL after redirects
+	base := resp.Request.URL
+	return extractLinks(base, string(data))
+}
+
+// extractLinks extracts the links, GET form URLs and POST forms of a page
+func extractLinks(base *url.URL, body string) ([]*url.URL, []*apispec.Request) {
+	var links []*url.URL
+	for _, match := range linkRegex.FindAllStringSubmatch(body, -1) {
+		if link := resolve(base, match[1]+match[2]+match[3]); link != nil {
+			links = append(links, link)
+		}
+	}
+
+	var forms []*apispec.Request
+	for _, match := range formRegex.FindAllStringSubmatch(body, -1) {
+		formAttributes := attributes(match[1])
+		method := strings.ToUpper(formAttributes["method"])
+		if method != "" && method != http.MethodGet && method != http.MethodPost {
+			continue
+		}
+		link := resolve(base, formAttributes["action"])
+		if link == nil {
+			continue
+		}
+		values := url.Values{}
+		for _, input := range inputRegex.FindAllString(match[2], -1) {
+			inputAttributes := attributes(input)
+			name := inputAttributes["name"]
+			if name == "" || strings.EqualFold(inputAttributes["type"], "submit") {
+				continue
+			}
+			value := inputAttributes["value"]
+			if value == "" {
+				value = "1"
+			}
+			values.Set(name, value)
+		}
+
+		if method == http.MethodPost {
+			// Forms without parameters have nothing to fuzz in the body
+			if len(values) == 0 {
+				continue
+			}
+			forms = append(forms, &apispec.Request{
+				Method:  http.MethodPost,
+				URL:     normalize(link),
+				Headers: map[string]string{"Content-Type": "application/x-www-form-urlencoded"},
+				Body:    values.Encode(),
+			})
+			continue
+		}
+		query := link.Query()
+		for name := range values {
+			query.Set(name, values.Get(name))
+		}
+		link.RawQuery = query.Encode()
+		links = append(links, link)
+	}
+	return links, forms
+}
+
+// attributes returns the lowercased attributes of a tag
+func attributes(tag string) map[string]string {
+	values := make(map[string]string)
+	for _, match := range attrRegex.FindAllStringSubmatch(tag, -1) {
+		values[strings.ToLower(match[1])] = html.UnescapeString(match[2] + match[3] + match[4])
+	}
+	return values
+}
+
+// resolve resolves a link on a page returning nil for non http links
+func resolve(base *url.URL, link string) *url.URL {
+	link = strings.TrimSpace(html.UnescapeString(link))
+	parsed, err := url.Parse(link)
+	if err != nil {
+		return nil
+	}
+	resolved := base.ResolveReference(parsed)
+	if resolved.Scheme != "http" && resolved.Scheme != "https" {
+		return nil
+	}
+	resolved.Fragment = ""
+	return resolved
+}
+
+// inScope returns true if the link is in the scope of the seed
+func (c *Crawler) inScope(seed, link *url.URL) bool {
+	if c.options.Scope == ScopeSubdomain {
+		host := link.Hostname()
+		return host == seed.Hostname() || strings.HasSuffix(host, "."+seed.Hostname())
+	}
+	return link.Host == seed.Host
+}
+
+func isStatic(link *url.URL) bool {
+	_, ok := staticExtensions[strings.ToLower(path.Ext(link.Path))]
+	return ok
+}
+
+func normalize(link *url.URL) string {
+	normalized := *link
+	normalized.Fragment = ""
+	return normalized.String()
+}
@@ -0,0 +1,62 @@
+package crawler
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCrawl(t *testing.T) {
+	pages := map[string]string{
+		"/":          `<a href="/about">About</a> <a href='https://other.example.com/'>Other</a> <img src="/logo.png"> <a href="/style.css">css</a>`,
+		"/about":     `<a href="team?id=5#top">Team</a> <form action="/search"><input type="text" name="q"><input type="submit" name="go" value="Go"></form> <form method="POST" action="/login"><input name="user" value="admin"><input type="password" name="pass"></form> <form method="post" action="/empty"></form>`,
+		"/team":      `<a href="/deep">Deep</a>`,
+		"/deep":      `<a href="/deeper">Deeper</a>`,
+		"/search":    ``,
+		"/deeper":    ``,
+		"/style.css": ``,
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		fmt.Fprint(w, pages[r.URL.Path])
+	}))
+	defer server.Close()
+
+	result, err := New(&Options{Depth: 3, Scope: ScopeHost, Concurrency: 2}).Crawl(server.URL + "/")
+	require.Nil(t, err, "could not crawl seed")
+	urls := result.URLs
+	sort.Strings(urls)
+	require.Equal(t, []string{
+		server.URL + "/about",
+		server.URL + "/deep",
+		server.URL + "/search?q=1",
+		server.URL + "/team?id=5",
+	}, urls, "could not get crawled urls")
+	require.Len(t, result.Forms, 1, "could not get post forms")
+	require.Equal(t, "POST", result.Forms[0].Method, "could not get post form method")
+	require.Equal(t, server.URL+"/login", result.Forms[0].URL, "could not get post form url")
+	require.Equal(t, "pass=1&user=admin", result.Forms[0].Body, "could not get post form body")
+	require.Equal(t, "application/x-www-form-urlencoded", result.Forms[0].Headers["Content-Type"], "could not get post form content type")
+
+	result, err = New(&Options{Depth: 3, MaxURLs: 2}).Crawl(server.URL + "/")
+	require.Nil(t, err, "could not crawl seed")
+	require.Len(t, result.URLs, 2, "could not limit crawled urls")
+}
+
+func TestInScope(t *testing.T) {
+	seed := mustParse("https://example.com/")
+	crawler := New(&Options{Scope: ScopeSubdomain})
+	require.True(t, crawler.inScope(seed, mustParse("https://api.example.com/v1")), "could not allow subdomain")
+	require.False(t, crawler.inScope(seed, mustParse("https://badexample.com/")), "could allow other domain")
+	require.False(t, New(&Options{Scope: ScopeHost}).inScope(seed, mustParse("https://api.example.com/v1")), "could allow subdomain for host scope")
+}
+
+func mustParse(rawURL string) *url.URL {
+	parsed, _ := url.Parse(rawURL)
+	return parsed
+}
@@ -0,0 +1,310 @@
+// Package graphql discovers the operations of GraphQL endpoints with
+// introspection and builds example requests for each of them.
+package graphql
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/projectdiscovery/nuclei/v2/pkg/input/apispec"
+)
+
+// IntrospectionQuery is the query returning the schema of a GraphQL endpoint
+const IntrospectionQuery = `query IntrospectionQuery { __schema { queryType { name } mutationType { name } types { kind name enumValues { name } inputFields { name type { ...TypeRef } } fields { name args { name type { ...TypeRef } } type { ...TypeRef } } } } } fragment TypeRef on __Type { kind name ofType { kind name ofType { kind name ofType { kind name ofType { kind name } } } } }`
+
+// maxResponseSize is the maximum size of an introspection response
+const maxResponseSize = 10 * 1024 * 1024
+
+// maxDepth is the maximum depth of generated selections and input objects
+const maxDepth = 3
+
+// TypeRef is a reference to a type of a field or argument
+type TypeRef struct {
+	Kind   string   `json:"kind"`
+	Name   string   `json:"name"`
+	OfType *TypeRef `json:"ofType"`
+}
+
+// InputValue is an argument or input object field
+type InputValue struct {
+	Name string  `json:"name"`
+	Type TypeRef `json:"type"`
+}
+
+// Field is a field of an object type
+type Field struct {
+	Name string       `json:"name"`
+	Args []InputValue `json:"args"`
+	Type TypeRef      `json:"type"`
+}
+
+// Type is a type of the schema
+type Type struct {
+	Kind        string       `json:"kind"`
+	Name        string       `json:"name"`
+	Fields      []Field      `json:"fields"`
+	InputFields []InputValue `json:"inputFields"`
+	EnumValues  []struct {
+		Name string `json:"name"`
+	} `json:"enumValues"`
+}
+
+// Schema is the introspected schema of a GraphQL endpoint
+type Schema struct {
+	QueryType *struct {
+		Name string `json:"name"`
+	} `json:"queryType"`
+	MutationType *struct {
+		Name string `json:"name"`
+	} `json:"mutationType"`
+	Types []Type `json:"types"`
+
+	types map[string]*Type
+}
+
+// Operation is a query or mutation of the schema
+type Operation struct {
+	// Type is the type of the operation - query or mutation
+	Type string
+	// Name is the name of the root field of the operation
+	Name string
+	// Query is the document of the operation
+	Query string
+	// Variables are the example values of the operation arguments
+	Variables map[string]interface{}
+}
+
+// Introspect runs the introspection query on the endpoint returning its schema
+func Introspect(client *http.Client, endpoint string) (*Schema, error) {
+	body, err := Body(IntrospectionQuery, nil)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequest(http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	data, err := ioutil.ReadAll(io.LimitReader(resp.Body, maxResponseSize))
+	if err != nil {
+		return nil, err
+	}
+	return ParseSchema(data)
+}
+
+// ParseSchema parses the response of an introspection query
+func ParseSchema(data []byte) (*Schema, error) {
+	var response struct {
+		Data struct {
+			Schema *Schema `json:"__schema"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(data, &response); err != nil {
+		return nil, errors.Wrap(err, "could not decode introspection response")
+	}
+	schema := response.Data.Schema
+	if schema == nil || len(schema.Types) == 0 {
+		return nil, errors.New("introspection is not enabled")
+	}
+	schema.types = make(map[string]*Type, len(schema.Types))
+	for i := range schema.Types {
+		schema.types[schema.Types[i].Name] = &schema.Types[i]
+	}
+	return schema, nil
+}
+
+// Operations returns the queries and mutations of the schema with example values
+func (s *Schema) Operations() []*Operation {
+	var operations []*Operation
+	if s.QueryType != nil {
+		operations = append(operations, s.operations("query", s.QueryType.Name)...)
+	}
+	if s.MutationType != nil {
+		operations = append(operations, s.operations("mutation", s.MutationType.Name)...)
+	}
+	return operations
+}
+
+// Requests returns the requests of the operations of the schema for the
+// endpoint. Mutations change the state of the target and are only
+// included if asked.
+func (s *Schema) Requests(endpoint string, mutations bool) []*apispec.Request {
+	var requests []*apispec.Request
+	for _, operation := range s.Operations() {
+		if operation.Type == "mutation" && !mutations {
+			continue
+		}
+		body, err := Body(operation.Query, operation.Variables)
+		if err != nil {
+			continue
+		}
+		requests = append(requests, &apispec.Request{
+			Method:  http.MethodPost,
+			URL:     endpoint,
+			Headers: map[string]string{"Content-Type": "application/json"},
+			Body:    string(body),
+		})
+	}
+	return requests
+}
+
+// Body returns the json body of a GraphQL request
+func Body(query string, variables map[string]interface{}) ([]byte, error) {
+	request := map[string]interface{}{"query": query}
+	if len(variables) > 0 {
+		request["variables"] = variables
+	}
+	return json.Marshal(request)
+}
+
+// operations returns the operations of the fields of a root type
+func (s *Schema) operations(operationType, rootType string) []*Operation {
+	root, ok := s.types[rootType]
+	if !ok {
+		return nil
+	}
+
+	var operations []*Operation
+	for _, field := range root.Fields {
+		if strings.HasPrefix(field.Name, "__") {
+			continue
+		}
+		variables := make(map[string]interface{})
+		var definitions, arguments []string
+		for _, arg := range field.Args {
+			definitions = append(definitions, "$"+arg.Name+": "+typeString(&arg.Type))
+			arguments = append(arguments, arg.Name+": $"+arg.Name)
+			variables[arg.Name] = s.example(&arg.Type, 0)
+		}
+
+		query := &strings.Builder{}
+		query.WriteString(operationType + " " + field.Name)
+		if len(definitions) > 0 {
+			query.WriteString("(" + strings.Join(definitions, ", ") + ")")
+		}
+		query.WriteString(" { " + field.Name)
+		if len(arguments) > 0 {
+			query.WriteString("(" + strings.Join(arguments, ", ") + ")")
+		}
+		query.WriteString(s.selection(&field.Type, 0) + " }")
+
+		operations = append(operations, &Operation{Type: operationType, Name: field.Name, Query: query.String(), Variables: variables})
+	}
+	return operations
+}
+
+// selection returns the selection set of the fields for a type
+func (s *Schema) selection(ref *TypeRef, depth int) string {
+	named, ok := s.types[namedType(ref).Name]
+	if !ok || (named.Kind != "OBJECT" && named.Kind != "INTERFACE") {
+		if ok && named.Kind == "UNION" {
+			return " { __typename }"
+		}
+		return ""
+	}
+
+	var fields []string
+	for _, field := range named.Fields {
+		if hasRequiredArgs(field) {
+			continue
+		}
+		fieldType, ok := s.types[namedType(&field.Type).Name]
+		if !ok || fieldType.Kind == "SCALAR" || fieldType.Kind == "ENUM" {
+			fields = append(fields, field.Name)
+			continue
+		}
+		if depth+1 < maxDepth {
+			if selection := s.selection(&field.Type, depth+1); selection != "" && selection != " { __typename }" {
+				fields = append(fields, field.Name+selection)
+			}
+		}
+	}
+	if len(fields) == 0 {
+		fields = append(fields, "__typename")
+	}
+	return " { " + strings.Join(fields, " ") + " }"
+}
+
+// example returns an example value for a type
+func (s *Schema) example(ref *TypeRef, depth int) interface{} {
+	if ref == nil {
+		return "test"
+	}
+	switch ref.Kind {
+	case "NON_NULL":
+		return s.example(ref.OfType, depth)
+	case "LIST":
+		return []interface{}{s.example(ref.OfType, depth)}
+	}
+
+	named, ok := s.types[ref.Name]
+	if !ok {
+		return "test"
+	}
+	switch named.Kind {
+	case "ENUM":
+		if len(named.EnumValues) > 0 {
+			return named.EnumValues[0].Name
+		}
+	case "INPUT_OBJECT":
+		object := make(map[string]interface{})
+		if depth < maxDepth {
+			for _, field := range named.InputFields {
+				object[field.Name] = s.example(&field.Type, depth+1)
+			}
+		}
+		return object
+	}
+
+	switch ref.Name {
+	case "Int":
+		return 1
+	case "Float":
+		return 1.5
+	case "Boolean":
+		return true
+	}
+	return "test"
+}
+
+// typeString returns the GraphQL notation of a type reference
+func typeString(ref *TypeRef) string {
+	if ref == nil {
+		return ""
+	}
+	switch ref.Kind {
+	case "NON_NULL":
+		return typeString(ref.OfType) + "!"
+	case "LIST":
+		return "[" + typeString(ref.OfType) + "]"
+	}
+	return ref.Name
+}
+
+// namedType returns the named type of a wrapped type reference
+func namedType(ref *TypeRef) *TypeRef {
+	for ref.OfType != nil && (ref.Kind == "NON_NULL" || ref.Kind == "LIST") {
+		ref = ref.OfType
+	}
+	return ref
+}
+
+func hasRequiredArgs(field Field) bool {
+	for _, arg := range field.Args {
+		if arg.Type.Kind == "NON_NULL" {
+			return true
+		}
+	}
+	return false
+}
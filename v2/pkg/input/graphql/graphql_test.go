@@ -0,0 +1,70 @@
+package graphql
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const testSchema = `{"data":{"__schema":{
+  "queryType":{"name":"Query"},"mutationType":{"name":"Mutation"},
+  "types":[
+    {"kind":"OBJECT","name":"Query","fields":[
+      {"name":"user","args":[{"name":"id","type":{"kind":"NON_NULL","ofType":{"kind":"SCALAR","name":"ID"}}}],"type":{"kind":"OBJECT","name":"User"}},
+      {"name":"__schema","args":[],"type":{"kind":"OBJECT","name":"__Schema"}}
+    ]},
+    {"kind":"OBJECT","name":"Mutation","fields":[
+      {"name":"login","args":[{"name":"input","type":{"kind":"NON_NULL","ofType":{"kind":"INPUT_OBJECT","name":"LoginInput"}}}],"type":{"kind":"SCALAR","name":"String"}}
+    ]},
+    {"kind":"OBJECT","name":"User","fields":[
+      {"name":"name","args":[],"type":{"kind":"SCALAR","name":"String"}},
+      {"name":"role","args":[],"type":{"kind":"ENUM","name":"Role"}},
+      {"name":"friends","args":[],"type":{"kind":"LIST","ofType":{"kind":"OBJECT","name":"User"}}}
+    ]},
+    {"kind":"INPUT_OBJECT","name":"LoginInput","inputFields":[
+      {"name":"username","type":{"kind":"SCALAR","name":"String"}},
+      {"name":"remember","type":{"kind":"SCALAR","name":"Boolean"}}
+    ]},
+    {"kind":"ENUM","name":"Role","enumValues":[{"name":"ADMIN"}]},
+    {"kind":"SCALAR","name":"String"},{"kind":"SCALAR","name":"ID"},{"kind":"SCALAR","name":"Boolean"}
+  ]}}}`
+
+func TestIntrospect(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := ioutil.ReadAll(r.Body)
+		var request map[string]interface{}
+		_ = json.Unmarshal(data, &request)
+		if request["query"] != IntrospectionQuery {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		_, _ = w.Write([]byte(testSchema))
+	}))
+	defer ts.Close()
+
+	schema, err := Introspect(ts.Client(), ts.URL)
+	require.Nil(t, err, "could not introspect endpoint")
+
+	operations := schema.Operations()
+	require.Len(t, operations, 2, "could not get operations")
+	require.Equal(t, "query user($id: ID!) { user(id: $id) { name role friends { name role friends { name role } } } }", operations[0].Query, "could not build query")
+	require.Equal(t, map[string]interface{}{"id": "test"}, operations[0].Variables, "could not build query variables")
+	require.Equal(t, "mutation login($input: LoginInput!) { login(input: $input) }", operations[1].Query, "could not build mutation")
+
+	requests := schema.Requests(ts.URL, false)
+	require.Len(t, requests, 1, "could get mutation requests by default")
+	require.Equal(t, `{"query":"query user($id: ID!) { user(id: $id) { name role friends { name role friends { name role } } } }","variables":{"id":"test"}}`, requests[0].Body, "could not build query request body")
+
+	requests = schema.Requests(ts.URL, true)
+	require.Len(t, requests, 2, "could not get requests")
+	require.Equal(t, `{"query":"mutation login($input: LoginInput!) { login(input: $input) }","variables":{"input":{"remember":true,"username":"test"}}}`, requests[1].Body, "could not build request body")
+}
+
+func TestParseSchemaDisabled(t *testing.T) {
+	_, err := ParseSchema([]byte(`{"errors":[{"message":"introspection is disabled"}]}`))
+	require.NotNil(t, err, "could not detect disabled introspection")
+}
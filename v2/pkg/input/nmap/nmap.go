@@ -0,0 +1,155 @@
+// Package nmap parses nmap and masscan XML output into services usable as
+// scan targets.
+package nmap
+
+import (
+	"encoding/xml"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Service is an open port of a host discovered by the port scanner
+type Service struct {
+	Host     string
+	Port     int
+	Protocol string
+	// Name is the service name detected by the scanner if any
+	Name string
+	// TLS is true if the service was detected behind a ssl tunnel
+	TLS bool
+}
+
+// run is the root element of nmap and masscan XML output
+type run struct {
+	Hosts []struct {
+		Addresses []struct {
+			Addr     string `xml:"addr,attr"`
+			AddrType string `xml:"addrtype,attr"`
+		} `xml:"address"`
+		Hostnames []struct {
+			Name string `xml:"name,attr"`
+			Type string `xml:"type,attr"`
+		} `xml:"hostnames>hostname"`
+		Ports []struct {
+			Protocol string `xml:"protocol,attr"`
+			PortID   int    `xml:"portid,attr"`
+			State    struct {
+				State string `xml:"state,attr"`
+			} `xml:"state"`
+			Service struct {
+				Name   string `xml:"name,attr"`
+				Tunnel string `xml:"tunnel,attr"`
+			} `xml:"service"`
+		} `xml:"ports>port"`
+	} `xml:"host"`
+}
+
+// Parse parses nmap or masscan XML output returning the open services
+func Parse(reader io.Reader) ([]*Service, error) {
+	result := &run{}
+	if err := xml.NewDecoder(reader).Decode(result); err != nil {
+		return nil, errors.Wrap(err, "could not decode xml")
+	}
+
+	var services []*Service
+	for _, host := range result.Hosts {
+		address := ""
+		for _, addr := range host.Addresses {
+			if addr.AddrType == "" || addr.AddrType == "ipv4" || addr.AddrType == "ipv6" {
+				address = addr.Addr
+				break
+			}
+		}
+		// Prefer the hostname the user scanned for virtual hosting
+		for _, hostname := range host.Hostnames {
+			if hostname.Type == "user" && hostname.Name != "" {
+				address = hostname.Name
+				break
+			}
+		}
+		if address == "" {
+			continue
+		}
+		for _, port := range host.Ports {
+			if port.State.State != "open" {
+				continue
+			}
+			services = append(services, &Service{
+				Host:     address,
+				Port:     port.PortID,
+				Protocol: port.Protocol,
+				Name:     strings.ToLower(port.Service.Name),
+				TLS:      port.Service.Tunnel == "ssl",
+			})
+		}
+	}
+	return services, nil
+}
+
+// httpServices are the service names identified as web servers
+var httpServices = map[string]struct{}{
+	"http":       {},
+	"https":      {},
+	"http-alt":   {},
+	"http-proxy": {},
+	"https-alt":  {},
+}
+
+// serviceTags maps the service names to the tags of the templates for them
+var serviceTags = map[string]string{
+	"ssh":           "ssh",
+	"ftp":           "ftp",
+	"telnet":        "telnet",
+	"smtp":          "smtp",
+	"imap":          "imap",
+	"pop3":          "pop3",
+	"domain":        "dns",
+	"ldap":          "ldap",
+	"microsoft-ds":  "smb",
+	"netbios-ssn":   "smb",
+	"ms-wbt-server": "rdp",
+	"ms-sql-s":      "mssql",
+	"mysql":         "mysql",
+	"postgresql":    "postgres",
+	"oracle-tns":    "oracle",
+	"redis":         "redis",
+	"mongodb":       "mongodb",
+	"memcache":      "memcached",
+	"vnc":           "vnc",
+	"rsync":         "rsync",
+	"snmp":          "snmp",
+	"java-rmi":      "rmi",
+	"ajp13":         "ajp",
+}
+
+// IsHTTP returns true if the service was identified as a web server
+func (s *Service) IsHTTP() bool {
+	_, ok := httpServices[s.Name]
+	return ok
+}
+
+// Target returns the input for the service, a URL for web servers
+// and host:port for the other services.
+func (s *Service) Target() string {
+	hostPort := net.JoinHostPort(s.Host, strconv.Itoa(s.Port))
+	if !s.IsHTTP() {
+		return hostPort
+	}
+	if s.TLS || s.Name == "https" || s.Name == "https-alt" || s.Port == 443 {
+		return "https://" + hostPort
+	}
+	return "http://" + hostPort
+}
+
+// Tags returns the template tags to run for the service. No tags are returned
+// for web servers and unknown services so all the templates are run on them.
+func (s *Service) Tags() []string {
+	if tag, ok := serviceTags[s.Name]; ok {
+		return []string{tag}
+	}
+	return nil
+}
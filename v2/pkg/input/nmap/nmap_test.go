@@ -0,0 +1,50 @@
+package nmap
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseNmap(t *testing.T) {
+	data := `<?xml version="1.0"?>
+<nmaprun scanner="nmap">
+<host><status state="up"/>
+<address addr="192.168.1.10" addrtype="ipv4"/>
+<address addr="00:11:22:33:44:55" addrtype="mac"/>
+<hostnames><hostname name="example.com" type="user"/></hostnames>
+<ports>
+<port protocol="tcp" portid="22"><state state="open"/><service name="ssh"/></port>
+<port protocol="tcp" portid="25"><state state="closed"/><service name="smtp"/></port>
+<port protocol="tcp" portid="8443"><state state="open"/><service name="http" tunnel="ssl"/></port>
+<port protocol="tcp" portid="9999"><state state="open"/><service name="abyss"/></port>
+</ports>
+</host>
+</nmaprun>`
+
+	services, err := Parse(strings.NewReader(data))
+	require.Nil(t, err, "could not parse nmap output")
+	require.Len(t, services, 3, "could not get open services")
+
+	require.Equal(t, "example.com:22", services[0].Target(), "could not get ssh target")
+	require.Equal(t, []string{"ssh"}, services[0].Tags(), "could not get ssh tags")
+	require.Equal(t, "https://example.com:8443", services[1].Target(), "could not get https target")
+	require.Nil(t, services[1].Tags(), "could get tags for http service")
+	require.Equal(t, "example.com:9999", services[2].Target(), "could not get unknown service target")
+	require.Nil(t, services[2].Tags(), "could get tags for unknown service")
+}
+
+func TestParseMasscan(t *testing.T) {
+	data := `<?xml version="1.0"?>
+<nmaprun scanner="masscan">
+<host endtime="1"><address addr="10.0.0.1" addrtype="ipv4"/><ports><port protocol="tcp" portid="80"><state state="open" reason="syn-ack"/></port></ports></host>
+<host endtime="1"><address addr="10.0.0.1" addrtype="ipv4"/><ports><port protocol="tcp" portid="3306"><state state="open" reason="syn-ack"/></port></ports></host>
+</nmaprun>`
+
+	services, err := Parse(strings.NewReader(data))
+	require.Nil(t, err, "could not parse masscan output")
+	require.Len(t, services, 2, "could not get open services")
+	require.Equal(t, "10.0.0.1:80", services[0].Target(), "could not get target")
+	require.Equal(t, "10.0.0.1:3306", services[1].Target(), "could not get target")
+}
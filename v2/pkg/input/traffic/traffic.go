@@ -0,0 +1,161 @@
+// Package traffic parses the requests of proxy traffic exports, Burp Suite
+// XML exports and HAR files, to be used as scan targets.
+package traffic
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/xml"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/projectdiscovery/nuclei/v2/pkg/input/apispec"
+)
+
+// skippedHeaders are the headers not replayed from the traffic as they are
+// set by the client for each request.
+var skippedHeaders = map[string]struct{}{
+	"Host":              {},
+	"Content-Length":    {},
+	"Transfer-Encoding": {},
+	"Connection":        {},
+}
+
+// Load parses a Burp Suite XML export or HAR file into requests. The
+// duplicate requests of the traffic are only returned once.
+func Load(file string) ([]*apispec.Request, error) {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	var requests []*apispec.Request
+	switch trimmed := bytes.TrimSpace(data); {
+	case bytes.HasPrefix(trimmed, []byte("<")):
+		requests, err = parseBurp(trimmed)
+	case bytes.HasPrefix(trimmed, []byte("{")):
+		requests, err = parseHAR(trimmed)
+	default:
+		return nil, errors.New("unknown traffic format (it should be burp xml export or har)")
+	}
+	if err != nil {
+		return nil, err
+	}
+	return unique(requests), nil
+}
+
+type burpItems struct {
+	Items []struct {
+		URL     string `xml:"url"`
+		Request struct {
+			Base64 bool   `xml:"base64,attr"`
+			Data   string `xml:",chardata"`
+		} `xml:"request"`
+	} `xml:"item"`
+}
+
+// parseBurp parses the items of a Burp Suite XML export
+func parseBurp(data []byte) ([]*apispec.Request, error) {
+	items := &burpItems{}
+	if err := xml.Unmarshal(data, items); err != nil {
+		return nil, errors.Wrap(err, "could not decode burp export")
+	}
+
+	var requests []*apispec.Request
+	for _, item := range items.Items {
+		raw := []byte(item.Request.Data)
+		if item.Request.Base64 {
+			decoded, err := base64.StdEncoding.DecodeString(strings.TrimSpace(item.Request.Data))
+			if err != nil {
+				return nil, errors.Wrapf(err, "could not decode request of %s", item.URL)
+			}
+			raw = decoded
+		}
+		parsed, err := http.ReadRequest(bufio.NewReader(bytes.NewReader(raw)))
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not parse request of %s", item.URL)
+		}
+		body, err := ioutil.ReadAll(parsed.Body)
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not read request body of %s", item.URL)
+		}
+
+		request := &apispec.Request{Method: parsed.Method, URL: item.URL, Headers: make(map[string]string), Body: string(body)}
+		for key := range parsed.Header {
+			addHeader(request, key, parsed.Header.Get(key))
+		}
+		requests = append(requests, request)
+	}
+	return requests, nil
+}
+
+type harLog struct {
+	Log struct {
+		Entries []struct {
+			Request struct {
+				Method  string `json:"method"`
+				URL     string `json:"url"`
+				Headers []struct {
+					Name  string `json:"name"`
+					Value string `json:"value"`
+				} `json:"headers"`
+				PostData *struct {
+					Text string `json:"text"`
+				} `json:"postData"`
+			} `json:"request"`
+		} `json:"entries"`
+	} `json:"log"`
+}
+
+// parseHAR parses the entries of a HAR file
+func parseHAR(data []byte) ([]*apispec.Request, error) {
+	har := &harLog{}
+	if err := json.Unmarshal(data, har); err != nil {
+		return nil, errors.Wrap(err, "could not decode har")
+	}
+
+	var requests []*apispec.Request
+	for _, entry := range har.Log.Entries {
+		request := &apispec.Request{Method: entry.Request.Method, URL: entry.Request.URL, Headers: make(map[string]string)}
+		for _, header := range entry.Request.Headers {
+			// The http/2 pseudo headers are part of the request line
+			if strings.HasPrefix(header.Name, ":") {
+				continue
+			}
+			addHeader(request, header.Name, header.Value)
+		}
+		if entry.Request.PostData != nil {
+			request.Body = entry.Request.PostData.Text
+		}
+		requests = append(requests, request)
+	}
+	return requests, nil
+}
+
+// addHeader adds a header of the traffic to a request unless skipped
+func addHeader(request *apispec.Request, key, value string) {
+	key = http.CanonicalHeaderKey(key)
+	if _, ok := skippedHeaders[key]; ok {
+		return
+	}
+	request.Headers[key] = value
+}
+
+// unique returns the requests without the duplicates of method, URL and body
+func unique(requests []*apispec.Request) []*apispec.Request {
+	seen := make(map[string]struct{}, len(requests))
+	result := make([]*apispec.Request, 0, len(requests))
+	for _, request := range requests {
+		key := strings.Join([]string{request.Method, request.URL, request.Body}, "\x00")
+		if _, ok := seen[key]; ok {
+			continue
+		}
+		seen[key] = struct{}{}
+		result = append(result, request)
+	}
+	return result
+}
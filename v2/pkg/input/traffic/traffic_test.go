@@ -0,0 +1,67 @@
+package traffic
+
+import (
+	"encoding/base64"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func writeTraffic(t *testing.T, data string) string {
+	file, err := ioutil.TempFile("", "traffic-*")
+	require.Nil(t, err, "could not create traffic file")
+	_, err = file.WriteString(data)
+	require.Nil(t, err, "could not write traffic file")
+	file.Close()
+	return file.Name()
+}
+
+func TestLoadBurp(t *testing.T) {
+	raw := "POST /login?next=%2F HTTP/1.1\r\nHost: example.com\r\nContent-Type: application/x-www-form-urlencoded\r\nContent-Length: 14\r\nCookie: session=1\r\n\r\nusername=admin"
+	file := writeTraffic(t, `<?xml version="1.0"?>
+<items burpVersion="2021.8">
+  <item>
+    <url><![CDATA[https://example.com/login?next=%2F]]></url>
+    <method><![CDATA[POST]]></method>
+    <request base64="true"><![CDATA[`+base64.StdEncoding.EncodeToString([]byte(raw))+`]]></request>
+  </item>
+  <item>
+    <url><![CDATA[https://example.com/login?next=%2F]]></url>
+    <request base64="true"><![CDATA[`+base64.StdEncoding.EncodeToString([]byte(raw))+`]]></request>
+  </item>
+</items>`)
+	defer os.Remove(file)
+
+	requests, err := Load(file)
+	require.Nil(t, err, "could not load burp export")
+	require.Len(t, requests, 1, "could not deduplicate requests")
+	require.Equal(t, "POST", requests[0].Method, "could not get method")
+	require.Equal(t, "https://example.com/login?next=%2F", requests[0].URL, "could not get url")
+	require.Equal(t, "username=admin", requests[0].Body, "could not get body")
+	require.Equal(t, map[string]string{"Content-Type": "application/x-www-form-urlencoded", "Cookie": "session=1"}, requests[0].Headers, "could not get headers")
+}
+
+func TestLoadHAR(t *testing.T) {
+	file := writeTraffic(t, `{"log": {"entries": [
+  {"request": {"method": "GET", "url": "https://example.com/search?q=test", "headers": [{"name": ":authority", "value": "example.com"}, {"name": "accept", "value": "*/*"}]}},
+  {"request": {"method": "PUT", "url": "https://example.com/api/users/1", "headers": [{"name": "content-type", "value": "application/json"}], "postData": {"mimeType": "application/json", "text": "{\"name\":\"admin\"}"}}}
+]}}`)
+	defer os.Remove(file)
+
+	requests, err := Load(file)
+	require.Nil(t, err, "could not load har")
+	require.Len(t, requests, 2, "could not get all entries")
+	require.Equal(t, map[string]string{"Accept": "*/*"}, requests[0].Headers, "could not skip pseudo headers")
+	require.Equal(t, "PUT", requests[1].Method, "could not get method")
+	require.Equal(t, `{"name":"admin"}`, requests[1].Body, "could not get body")
+}
+
+func TestLoadUnknown(t *testing.T) {
+	file := writeTraffic(t, "GET / HTTP/1.1")
+	defer os.Remove(file)
+
+	_, err := Load(file)
+	require.NotNil(t, err, "could load unknown traffic format")
+}
@@ -0,0 +1,179 @@
+package uncover
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	jsoniter "github.com/json-iterator/go"
+	"github.com/pkg/errors"
+)
+
+// API endpoints of the search engines
+var (
+	shodanURL = "https://api.shodan.io/shodan/host/search"
+	censysURL = "https://search.censys.io/api/v2/hosts/search"
+	fofaURL   = "https://fofa.info/api/v1/search/all"
+)
+
+// pageSize is the number of results requested per page
+const pageSize = 100
+
+type shodanResponse struct {
+	Total   int `json:"total"`
+	Matches []struct {
+		IP   string `json:"ip_str"`
+		Port int    `json:"port"`
+	} `json:"matches"`
+}
+
+// searchShodan searches the query on Shodan
+func searchShodan(client *http.Client, keys *Keys, query string, limit int) ([]string, error) {
+	if keys.Shodan == "" {
+		return nil, errors.New("no shodan api key provided")
+	}
+	var results []string
+	for page := 1; len(results) < limit; page++ {
+		values := url.Values{"key": {keys.Shodan}, "query": {query}, "page": {strconv.Itoa(page)}}
+		req, err := http.NewRequest(http.MethodGet, shodanURL+"?"+values.Encode(), nil)
+		if err != nil {
+			return nil, err
+		}
+		response := &shodanResponse{}
+		if err := doJSON(client, req, response); err != nil {
+			return nil, err
+		}
+		for _, match := range response.Matches {
+			results = append(results, hostPort(match.IP, match.Port))
+		}
+		if len(response.Matches) == 0 || len(results) >= response.Total {
+			break
+		}
+	}
+	return truncate(results, limit), nil
+}
+
+type censysResponse struct {
+	Result struct {
+		Hits []struct {
+			IP       string `json:"ip"`
+			Services []struct {
+				Port int `json:"port"`
+			} `json:"services"`
+		} `json:"hits"`
+		Links struct {
+			Next string `json:"next"`
+		} `json:"links"`
+	} `json:"result"`
+}
+
+// searchCensys searches the query on Censys
+func searchCensys(client *http.Client, keys *Keys, query string, limit int) ([]string, error) {
+	if keys.CensysID == "" || keys.CensysSecret == "" {
+		return nil, errors.New("no censys api credentials provided")
+	}
+	var results []string
+	cursor := ""
+	for len(results) < limit {
+		values := url.Values{"q": {query}, "per_page": {strconv.Itoa(pageSize)}}
+		if cursor != "" {
+			values.Set("cursor", cursor)
+		}
+		req, err := http.NewRequest(http.MethodGet, censysURL+"?"+values.Encode(), nil)
+		if err != nil {
+			return nil, err
+		}
+		req.SetBasicAuth(keys.CensysID, keys.CensysSecret)
+		response := &censysResponse{}
+		if err := doJSON(client, req, response); err != nil {
+			return nil, err
+		}
+		for _, hit := range response.Result.Hits {
+			for _, service := range hit.Services {
+				results = append(results, hostPort(hit.IP, service.Port))
+			}
+		}
+		cursor = response.Result.Links.Next
+		if len(response.Result.Hits) == 0 || cursor == "" {
+			break
+		}
+	}
+	return truncate(results, limit), nil
+}
+
+type fofaResponse struct {
+	Error   bool       `json:"error"`
+	ErrMsg  string     `json:"errmsg"`
+	Size    int        `json:"size"`
+	Results [][]string `json:"results"`
+}
+
+// searchFofa searches the query on FOFA
+func searchFofa(client *http.Client, keys *Keys, query string, limit int) ([]string, error) {
+	if keys.FofaEmail == "" || keys.FofaKey == "" {
+		return nil, errors.New("no fofa api credentials provided")
+	}
+	var results []string
+	for page := 1; len(results) < limit; page++ {
+		values := url.Values{
+			"email":   {keys.FofaEmail},
+			"key":     {keys.FofaKey},
+			"qbase64": {base64.StdEncoding.EncodeToString([]byte(query))},
+			"fields":  {"ip,port"},
+			"size":    {strconv.Itoa(pageSize)},
+			"page":    {strconv.Itoa(page)},
+		}
+		req, err := http.NewRequest(http.MethodGet, fofaURL+"?"+values.Encode(), nil)
+		if err != nil {
+			return nil, err
+		}
+		response := &fofaResponse{}
+		if err := doJSON(client, req, response); err != nil {
+			return nil, err
+		}
+		if response.Error {
+			return nil, errors.New(response.ErrMsg)
+		}
+		for _, result := range response.Results {
+			if len(result) < 2 {
+				continue
+			}
+			port, err := strconv.Atoi(result[1])
+			if err != nil {
+				continue
+			}
+			results = append(results, hostPort(result[0], port))
+		}
+		if len(response.Results) == 0 || len(results) >= response.Size {
+			break
+		}
+	}
+	return truncate(results, limit), nil
+}
+
+// doJSON performs the request decoding the JSON response
+func doJSON(client *http.Client, req *http.Request, v interface{}) error {
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+	if err := jsoniter.NewDecoder(resp.Body).Decode(v); err != nil {
+		return errors.Wrap(err, "could not decode response")
+	}
+	return nil
+}
+
+// truncate limits the results to the limit
+func truncate(results []string, limit int) []string {
+	if len(results) > limit {
+		return results[:limit]
+	}
+	return results
+}
@@ -0,0 +1,111 @@
+// Package uncover discovers scan targets by querying internet wide search
+// engines like Shodan, Censys and FOFA.
+package uncover
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v2"
+)
+
+// Keys contains the API keys for the search engines
+type Keys struct {
+	// Shodan is the Shodan API key
+	Shodan string `yaml:"shodan"`
+	// CensysID and CensysSecret are the Censys API credentials
+	CensysID     string `yaml:"censys-id"`
+	CensysSecret string `yaml:"censys-secret"`
+	// FofaEmail and FofaKey are the FOFA API credentials
+	FofaEmail string `yaml:"fofa-email"`
+	FofaKey   string `yaml:"fofa-key"`
+}
+
+// Options contains the configuration options for the search
+type Options struct {
+	// Query is the search query to run on each engine
+	Query string
+	// Engines are the search engines to query
+	Engines []string
+	// Limit is the maximum number of results per engine
+	Limit int
+	// Keys are the API keys for the engines
+	Keys *Keys
+}
+
+// engines are the supported search engines
+var engines = map[string]func(client *http.Client, keys *Keys, query string, limit int) ([]string, error){
+	"shodan": searchShodan,
+	"censys": searchCensys,
+	"fofa":   searchFofa,
+}
+
+// LoadKeys reads the API keys from a yaml file if it exists, falling back
+// to the environment variables for keys missing in the file.
+func LoadKeys(file string) (*Keys, error) {
+	keys := &Keys{}
+	if file != "" {
+		data, err := ioutil.ReadFile(file)
+		if err != nil && !os.IsNotExist(err) {
+			return nil, errors.Wrap(err, "could not read uncover config")
+		}
+		if err == nil {
+			if err := yaml.Unmarshal(data, keys); err != nil {
+				return nil, errors.Wrap(err, "could not decode uncover config")
+			}
+		}
+	}
+	setFromEnv(&keys.Shodan, "SHODAN_API_KEY")
+	setFromEnv(&keys.CensysID, "CENSYS_API_ID")
+	setFromEnv(&keys.CensysSecret, "CENSYS_API_SECRET")
+	setFromEnv(&keys.FofaEmail, "FOFA_EMAIL")
+	setFromEnv(&keys.FofaKey, "FOFA_KEY")
+	return keys, nil
+}
+
+func setFromEnv(value *string, name string) {
+	if *value == "" {
+		*value = os.Getenv(name)
+	}
+}
+
+// Search runs the query on the engines returning the deduplicated targets
+func Search(options *Options) ([]string, error) {
+	if options.Query == "" {
+		return nil, errors.New("no uncover query provided")
+	}
+	client := &http.Client{Timeout: 30 * time.Second}
+
+	var targets []string
+	seen := make(map[string]struct{})
+	for _, name := range options.Engines {
+		search, ok := engines[strings.ToLower(strings.TrimSpace(name))]
+		if !ok {
+			return nil, fmt.Errorf("unsupported uncover engine %s", name)
+		}
+		results, err := search(client, options.Keys, options.Query, options.Limit)
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not search %s", name)
+		}
+		for _, result := range results {
+			if _, ok := seen[result]; ok {
+				continue
+			}
+			seen[result] = struct{}{}
+			targets = append(targets, result)
+		}
+	}
+	return targets, nil
+}
+
+// hostPort returns the host:port target for a result
+func hostPort(host string, port int) string {
+	return net.JoinHostPort(host, strconv.Itoa(port))
+}
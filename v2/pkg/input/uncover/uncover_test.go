@@ -0,0 +1,44 @@
+package uncover
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSearchShodan(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "test-key", r.URL.Query().Get("key"), "could not get api key")
+		require.Equal(t, `ssl:"example.com"`, r.URL.Query().Get("query"), "could not get query")
+		fmt.Fprint(w, `{"total":3,"matches":[{"ip_str":"1.1.1.1","port":443},{"ip_str":"1.1.1.1","port":443},{"ip_str":"2.2.2.2","port":8443}]}`)
+	}))
+	defer ts.Close()
+	shodanURL = ts.URL
+
+	targets, err := Search(&Options{Query: `ssl:"example.com"`, Engines: []string{"shodan"}, Limit: 10, Keys: &Keys{Shodan: "test-key"}})
+	require.Nil(t, err, "could not search shodan")
+	require.Equal(t, []string{"1.1.1.1:443", "2.2.2.2:8443"}, targets, "could not get deduplicated targets")
+}
+
+func TestSearchFofaLimit(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"error":false,"size":3,"results":[["1.1.1.1","80"],["2.2.2.2","8080"],["3.3.3.3","443"]]}`)
+	}))
+	defer ts.Close()
+	fofaURL = ts.URL
+
+	targets, err := Search(&Options{Query: `title="test"`, Engines: []string{"fofa"}, Limit: 2, Keys: &Keys{FofaEmail: "a@example.com", FofaKey: "key"}})
+	require.Nil(t, err, "could not search fofa")
+	require.Equal(t, []string{"1.1.1.1:80", "2.2.2.2:8080"}, targets, "could not limit targets")
+}
+
+func TestSearchErrors(t *testing.T) {
+	_, err := Search(&Options{Query: "test", Engines: []string{"unknown"}, Keys: &Keys{}})
+	require.NotNil(t, err, "could search unknown engine")
+
+	_, err = Search(&Options{Query: "test", Engines: []string{"censys"}, Limit: 10, Keys: &Keys{}})
+	require.NotNil(t, err, "could search without credentials")
+}
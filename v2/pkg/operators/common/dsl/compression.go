@@ -0,0 +1,79 @@
+package dsl
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"io/ioutil"
+
+	"github.com/Knetic/govaluate"
+	"github.com/projectdiscovery/nuclei/v2/pkg/types"
+)
+
+// addCompressionFunctions adds the compression helper functions.
+func addCompressionFunctions(functions map[string]govaluate.ExpressionFunction) {
+	functions["gzip"] = func(args ...interface{}) (interface{}, error) {
+		buffer := &bytes.Buffer{}
+		writer := gzip.NewWriter(buffer)
+		return compress(writer, buffer, types.ToString(args[0]))
+	}
+
+	functions["gzip_decode"] = func(args ...interface{}) (interface{}, error) {
+		reader, err := gzip.NewReader(bytes.NewReader([]byte(types.ToString(args[0]))))
+		if err != nil {
+			return nil, err
+		}
+		return decompress(reader)
+	}
+
+	functions["zlib"] = func(args ...interface{}) (interface{}, error) {
+		buffer := &bytes.Buffer{}
+		writer := zlib.NewWriter(buffer)
+		return compress(writer, buffer, types.ToString(args[0]))
+	}
+
+	functions["zlib_decode"] = func(args ...interface{}) (interface{}, error) {
+		reader, err := zlib.NewReader(bytes.NewReader([]byte(types.ToString(args[0]))))
+		if err != nil {
+			return nil, err
+		}
+		return decompress(reader)
+	}
+
+	functions["deflate"] = func(args ...interface{}) (interface{}, error) {
+		buffer := &bytes.Buffer{}
+		writer, err := flate.NewWriter(buffer, flate.DefaultCompression)
+		if err != nil {
+			return nil, err
+		}
+		return compress(writer, buffer, types.ToString(args[0]))
+	}
+
+	functions["inflate"] = func(args ...interface{}) (interface{}, error) {
+		return decompress(flate.NewReader(bytes.NewReader([]byte(types.ToString(args[0])))))
+	}
+}
+
+// compress writes data to a compressing writer and returns the buffer contents
+func compress(writer io.WriteCloser, buffer *bytes.Buffer, data string) (interface{}, error) {
+	if _, err := writer.Write([]byte(data)); err != nil {
+		return nil, err
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+	return buffer.String(), nil
+}
+
+// decompress reads all the data from a decompressing reader
+func decompress(reader io.ReadCloser) (interface{}, error) {
+	defer reader.Close()
+
+	data, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
@@ -0,0 +1,249 @@
+package dsl
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/des"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha1"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+
+	"github.com/Knetic/govaluate"
+	"github.com/projectdiscovery/nuclei/v2/pkg/types"
+)
+
+// addCryptoFunctions adds the encryption and padding helper functions.
+//
+// All binary values are passed and returned as raw strings, so they
+// can be combined with encoding helpers like base64 or hex_encode.
+func addCryptoFunctions(functions map[string]govaluate.ExpressionFunction) {
+	// padding
+	functions["pkcs7_pad"] = func(args ...interface{}) (interface{}, error) {
+		if len(args) < 2 {
+			return nil, errors.New("pkcs7_pad requires data and block size arguments")
+		}
+		return string(pkcs7Pad([]byte(types.ToString(args[0])), toInt(args[1]))), nil
+	}
+
+	functions["pkcs7_unpad"] = func(args ...interface{}) (interface{}, error) {
+		data, err := pkcs7Unpad([]byte(types.ToString(args[0])))
+		if err != nil {
+			return nil, err
+		}
+		return string(data), nil
+	}
+
+	functions["zero_pad"] = func(args ...interface{}) (interface{}, error) {
+		if len(args) < 2 {
+			return nil, errors.New("zero_pad requires data and block size arguments")
+		}
+		data := []byte(types.ToString(args[0]))
+		blockSize := toInt(args[1])
+		if blockSize <= 0 {
+			return nil, errors.New("invalid block size")
+		}
+		if remainder := len(data) % blockSize; remainder != 0 {
+			data = append(data, make([]byte, blockSize-remainder)...)
+		}
+		return string(data), nil
+	}
+
+	// symmetric
+	functions["aes_cbc"] = func(args ...interface{}) (interface{}, error) {
+		if len(args) < 3 {
+			return nil, errors.New("aes_cbc requires data, key and iv arguments")
+		}
+		block, err := aes.NewCipher([]byte(types.ToString(args[1])))
+		if err != nil {
+			return nil, err
+		}
+		return cbcEncrypt(block, []byte(types.ToString(args[0])), []byte(types.ToString(args[2])))
+	}
+
+	functions["aes_cbc_decrypt"] = func(args ...interface{}) (interface{}, error) {
+		if len(args) < 3 {
+			return nil, errors.New("aes_cbc_decrypt requires data, key and iv arguments")
+		}
+		block, err := aes.NewCipher([]byte(types.ToString(args[1])))
+		if err != nil {
+			return nil, err
+		}
+		return cbcDecrypt(block, []byte(types.ToString(args[0])), []byte(types.ToString(args[2])))
+	}
+
+	functions["aes_gcm"] = func(args ...interface{}) (interface{}, error) {
+		if len(args) < 3 {
+			return nil, errors.New("aes_gcm requires data, key and nonce arguments")
+		}
+		aead, err := newGCM([]byte(types.ToString(args[1])))
+		if err != nil {
+			return nil, err
+		}
+		nonce := []byte(types.ToString(args[2]))
+		if len(nonce) != aead.NonceSize() {
+			return nil, fmt.Errorf("invalid nonce size %d, expected %d", len(nonce), aead.NonceSize())
+		}
+		return string(aead.Seal(nil, nonce, []byte(types.ToString(args[0])), nil)), nil
+	}
+
+	functions["aes_gcm_decrypt"] = func(args ...interface{}) (interface{}, error) {
+		if len(args) < 3 {
+			return nil, errors.New("aes_gcm_decrypt requires data, key and nonce arguments")
+		}
+		aead, err := newGCM([]byte(types.ToString(args[1])))
+		if err != nil {
+			return nil, err
+		}
+		nonce := []byte(types.ToString(args[2]))
+		if len(nonce) != aead.NonceSize() {
+			return nil, fmt.Errorf("invalid nonce size %d, expected %d", len(nonce), aead.NonceSize())
+		}
+		data, err := aead.Open(nil, nonce, []byte(types.ToString(args[0])), nil)
+		if err != nil {
+			return nil, err
+		}
+		return string(data), nil
+	}
+
+	functions["des_cbc"] = func(args ...interface{}) (interface{}, error) {
+		if len(args) < 3 {
+			return nil, errors.New("des_cbc requires data, key and iv arguments")
+		}
+		block, err := newDESCipher([]byte(types.ToString(args[1])))
+		if err != nil {
+			return nil, err
+		}
+		return cbcEncrypt(block, []byte(types.ToString(args[0])), []byte(types.ToString(args[2])))
+	}
+
+	functions["des_cbc_decrypt"] = func(args ...interface{}) (interface{}, error) {
+		if len(args) < 3 {
+			return nil, errors.New("des_cbc_decrypt requires data, key and iv arguments")
+		}
+		block, err := newDESCipher([]byte(types.ToString(args[1])))
+		if err != nil {
+			return nil, err
+		}
+		return cbcDecrypt(block, []byte(types.ToString(args[0])), []byte(types.ToString(args[2])))
+	}
+
+	// asymmetric
+	functions["rsa_encrypt"] = func(args ...interface{}) (interface{}, error) {
+		if len(args) < 2 {
+			return nil, errors.New("rsa_encrypt requires data and public key arguments")
+		}
+		publicKey, err := parseRSAPublicKey(types.ToString(args[1]))
+		if err != nil {
+			return nil, err
+		}
+		data := []byte(types.ToString(args[0]))
+		if len(args) >= 3 && types.ToString(args[2]) == "oaep" {
+			encrypted, err := rsa.EncryptOAEP(sha1.New(), rand.Reader, publicKey, data, nil)
+			if err != nil {
+				return nil, err
+			}
+			return string(encrypted), nil
+		}
+		encrypted, err := rsa.EncryptPKCS1v15(rand.Reader, publicKey, data)
+		if err != nil {
+			return nil, err
+		}
+		return string(encrypted), nil
+	}
+}
+
+// cbcEncrypt encrypts pkcs7 padded data in CBC mode
+func cbcEncrypt(block cipher.Block, data, iv []byte) (string, error) {
+	if len(iv) != block.BlockSize() {
+		return "", fmt.Errorf("invalid iv size %d, expected %d", len(iv), block.BlockSize())
+	}
+	data = pkcs7Pad(data, block.BlockSize())
+	encrypted := make([]byte, len(data))
+	cipher.NewCBCEncrypter(block, iv).CryptBlocks(encrypted, data)
+	return string(encrypted), nil
+}
+
+// cbcDecrypt decrypts data in CBC mode removing pkcs7 padding
+func cbcDecrypt(block cipher.Block, data, iv []byte) (string, error) {
+	if len(iv) != block.BlockSize() {
+		return "", fmt.Errorf("invalid iv size %d, expected %d", len(iv), block.BlockSize())
+	}
+	if len(data) == 0 || len(data)%block.BlockSize() != 0 {
+		return "", errors.New("ciphertext is not a multiple of the block size")
+	}
+	decrypted := make([]byte, len(data))
+	cipher.NewCBCDecrypter(block, iv).CryptBlocks(decrypted, data)
+	unpadded, err := pkcs7Unpad(decrypted)
+	if err != nil {
+		return "", err
+	}
+	return string(unpadded), nil
+}
+
+// newGCM returns an AES-GCM cipher for the key
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// newDESCipher returns a DES cipher for 8 byte keys and 3DES for 24 byte keys
+func newDESCipher(key []byte) (cipher.Block, error) {
+	if len(key) == 24 {
+		return des.NewTripleDESCipher(key)
+	}
+	return des.NewCipher(key)
+}
+
+// parseRSAPublicKey parses a PEM encoded PKIX or PKCS1 rsa public key
+func parseRSAPublicKey(data string) (*rsa.PublicKey, error) {
+	block, _ := pem.Decode([]byte(data))
+	if block == nil {
+		return nil, errors.New("could not decode pem public key")
+	}
+	if key, err := x509.ParsePKCS1PublicKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	parsed, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	key, ok := parsed.(*rsa.PublicKey)
+	if !ok {
+		return nil, errors.New("public key is not an rsa key")
+	}
+	return key, nil
+}
+
+// pkcs7Pad pads data to a multiple of block size
+func pkcs7Pad(data []byte, blockSize int) []byte {
+	if blockSize <= 0 {
+		return data
+	}
+	padding := blockSize - len(data)%blockSize
+	return append(data, bytes.Repeat([]byte{byte(padding)}, padding)...)
+}
+
+// pkcs7Unpad removes pkcs7 padding from data
+func pkcs7Unpad(data []byte) ([]byte, error) {
+	if len(data) == 0 {
+		return nil, errors.New("invalid padding on empty data")
+	}
+	padding := int(data[len(data)-1])
+	if padding == 0 || padding > len(data) {
+		return nil, errors.New("invalid pkcs7 padding")
+	}
+	for _, b := range data[len(data)-padding:] {
+		if int(b) != padding {
+			return nil, errors.New("invalid pkcs7 padding")
+		}
+	}
+	return data[:len(data)-padding], nil
+}
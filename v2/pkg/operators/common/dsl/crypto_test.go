@@ -0,0 +1,50 @@
+package dsl
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"testing"
+
+	"github.com/Knetic/govaluate"
+	"github.com/stretchr/testify/require"
+)
+
+func evaluateExpression(t *testing.T, expression string, parameters map[string]interface{}) interface{} {
+	compiled, err := govaluate.NewEvaluableExpressionWithFunctions(expression, HelperFunctions())
+	require.Nil(t, err, "could not compile expression %s", expression)
+
+	result, err := compiled.Evaluate(parameters)
+	require.Nil(t, err, "could not evaluate expression %s", expression)
+	return result
+}
+
+func TestCryptoRoundTrip(t *testing.T) {
+	items := []string{
+		`aes_cbc_decrypt(aes_cbc("secret data", "0123456789abcdef", "abcdef9876543210"), "0123456789abcdef", "abcdef9876543210")`,
+		`aes_gcm_decrypt(aes_gcm("secret data", "0123456789abcdef", "abcdef987654"), "0123456789abcdef", "abcdef987654")`,
+		`des_cbc_decrypt(des_cbc("secret data", "01234567", "abcdef98"), "01234567", "abcdef98")`,
+		`des_cbc_decrypt(des_cbc("secret data", "0123456789abcdef01234567", "abcdef98"), "0123456789abcdef01234567", "abcdef98")`,
+		`pkcs7_unpad(pkcs7_pad("secret data", 16))`,
+	}
+	for _, item := range items {
+		require.Equal(t, "secret data", evaluateExpression(t, item, nil), "could not get correct round trip for %s", item)
+	}
+	require.Len(t, evaluateExpression(t, `pkcs7_pad("secret data", 16)`, nil), 16, "could not get correct padded length")
+	require.Len(t, evaluateExpression(t, `zero_pad("secret data", 8)`, nil), 16, "could not get correct zero padded length")
+}
+
+func TestRSAEncrypt(t *testing.T) {
+	privateKey, err := rsa.GenerateKey(rand.Reader, 1024)
+	require.Nil(t, err, "could not generate rsa key")
+
+	publicKey, err := x509.MarshalPKIXPublicKey(&privateKey.PublicKey)
+	require.Nil(t, err, "could not marshal rsa public key")
+	publicKeyPEM := string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicKey}))
+
+	result := evaluateExpression(t, `rsa_encrypt("secret data", key)`, map[string]interface{}{"key": publicKeyPEM})
+	decrypted, err := rsa.DecryptPKCS1v15(rand.Reader, privateKey, []byte(result.(string)))
+	require.Nil(t, err, "could not decrypt rsa data")
+	require.Equal(t, "secret data", string(decrypted), "could not get correct decrypted data")
+}
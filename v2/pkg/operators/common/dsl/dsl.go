@@ -2,17 +2,24 @@ package dsl
 
 import (
 	"bytes"
+	"crypto/hmac"
 	"crypto/md5"
 	"crypto/sha1"
 	"crypto/sha256"
+	"crypto/sha512"
 	"encoding/base64"
 	"encoding/hex"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
 	"fmt"
+	"hash"
 	"html"
 	"math"
 	"math/rand"
 	"net/url"
 	"regexp"
+	"strconv"
 	"strings"
 	"time"
 
@@ -100,7 +107,11 @@ func HelperFunctions() map[string]govaluate.ExpressionFunction {
 	}
 
 	functions["base64_decode"] = func(args ...interface{}) (interface{}, error) {
-		return base64.StdEncoding.DecodeString(types.ToString(args[0]))
+		data, err := base64.StdEncoding.DecodeString(types.ToString(args[0]))
+		if err != nil {
+			return nil, err
+		}
+		return string(data), nil
 	}
 
 	functions["url_encode"] = func(args ...interface{}) (interface{}, error) {
@@ -111,6 +122,22 @@ func HelperFunctions() map[string]govaluate.ExpressionFunction {
 		return url.PathUnescape(types.ToString(args[0]))
 	}
 
+	functions["url_encode_all"] = func(args ...interface{}) (interface{}, error) {
+		return urlEncodeAll(types.ToString(args[0])), nil
+	}
+
+	functions["double_url_encode"] = func(args ...interface{}) (interface{}, error) {
+		return url.PathEscape(url.PathEscape(types.ToString(args[0]))), nil
+	}
+
+	functions["double_url_decode"] = func(args ...interface{}) (interface{}, error) {
+		decoded, err := url.PathUnescape(types.ToString(args[0]))
+		if err != nil {
+			return nil, err
+		}
+		return url.PathUnescape(decoded)
+	}
+
 	functions["hex_encode"] = func(args ...interface{}) (interface{}, error) {
 		return hex.EncodeToString([]byte(types.ToString(args[0]))), nil
 	}
@@ -128,6 +155,18 @@ func HelperFunctions() map[string]govaluate.ExpressionFunction {
 		return html.UnescapeString(types.ToString(args[0])), nil
 	}
 
+	functions["xml_escape"] = func(args ...interface{}) (interface{}, error) {
+		buffer := &bytes.Buffer{}
+		if err := xml.EscapeText(buffer, []byte(types.ToString(args[0]))); err != nil {
+			return nil, err
+		}
+		return buffer.String(), nil
+	}
+
+	functions["xml_unescape"] = func(args ...interface{}) (interface{}, error) {
+		return html.UnescapeString(types.ToString(args[0])), nil
+	}
+
 	// hashing
 	functions["md5"] = func(args ...interface{}) (interface{}, error) {
 		hash := md5.Sum([]byte(types.ToString(args[0])))
@@ -159,6 +198,51 @@ func HelperFunctions() map[string]govaluate.ExpressionFunction {
 		return fmt.Sprintf("%d", int32(murmur3.Sum32WithSeed([]byte(types.ToString(args[0])), 0))), nil
 	}
 
+	functions["hmac"] = func(args ...interface{}) (interface{}, error) {
+		if len(args) < 3 {
+			return nil, errors.New("hmac requires algorithm, data and secret arguments")
+		}
+		hashFunc, ok := hmacAlgorithms[strings.ToLower(types.ToString(args[0]))]
+		if !ok {
+			return nil, fmt.Errorf("unknown hmac algorithm: %s", types.ToString(args[0]))
+		}
+		h := hmac.New(hashFunc, []byte(types.ToString(args[2])))
+		_, _ = h.Write([]byte(types.ToString(args[1])))
+		return hex.EncodeToString(h.Sum(nil)), nil
+	}
+
+	// jwt
+	functions["generate_jwt"] = func(args ...interface{}) (interface{}, error) {
+		if len(args) < 1 {
+			return nil, errors.New("generate_jwt requires a claims argument")
+		}
+		payload, err := jwtClaims(args[0])
+		if err != nil {
+			return nil, err
+		}
+		algorithm := "none"
+		secret := ""
+		if len(args) >= 2 {
+			algorithm = types.ToString(args[1])
+		}
+		if len(args) >= 3 {
+			secret = types.ToString(args[2])
+		}
+		return generateJWT(payload, algorithm, secret)
+	}
+
+	functions["jwt_decode"] = func(args ...interface{}) (interface{}, error) {
+		parts := strings.Split(types.ToString(args[0]), ".")
+		if len(parts) < 2 {
+			return nil, errors.New("invalid jwt token provided")
+		}
+		payload, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[1], "="))
+		if err != nil {
+			return nil, err
+		}
+		return string(payload), nil
+	}
+
 	// search
 	functions["contains"] = func(args ...interface{}) (interface{}, error) {
 		return strings.Contains(types.ToString(args[0]), types.ToString(args[1])), nil
@@ -192,7 +276,7 @@ func HelperFunctions() map[string]govaluate.ExpressionFunction {
 		base := letters + numbers
 
 		if len(args) >= 1 {
-			l = args[0].(int)
+			l = toInt(args[0])
 		}
 		if len(args) >= withCutSetArgsSize {
 			bad = types.ToString(args[1])
@@ -210,7 +294,7 @@ func HelperFunctions() map[string]govaluate.ExpressionFunction {
 		chars := letters + numbers
 
 		if len(args) >= 1 {
-			l = args[0].(int)
+			l = toInt(args[0])
 		}
 		if len(args) >= withCutSetArgsSize {
 			bad = types.ToString(args[1])
@@ -225,7 +309,7 @@ func HelperFunctions() map[string]govaluate.ExpressionFunction {
 		chars := letters
 
 		if len(args) >= 1 {
-			l = args[0].(int)
+			l = toInt(args[0])
 		}
 		if len(args) >= withCutSetArgsSize {
 			bad = types.ToString(args[1])
@@ -240,7 +324,7 @@ func HelperFunctions() map[string]govaluate.ExpressionFunction {
 		chars := numbers
 
 		if len(args) >= 1 {
-			l = args[0].(int)
+			l = toInt(args[0])
 		}
 		if len(args) >= withCutSetArgsSize {
 			bad = types.ToString(args[1])
@@ -254,23 +338,190 @@ func HelperFunctions() map[string]govaluate.ExpressionFunction {
 		max := math.MaxInt32
 
 		if len(args) >= 1 {
-			min = args[0].(int)
+			min = toInt(args[0])
 		}
 		if len(args) >= withMaxRandArgsSize {
-			max = args[1].(int)
+			max = toInt(args[1])
+		}
+		if max <= min {
+			return min, nil
 		}
 		return rand.Intn(max-min) + min, nil
 	}
 
 	// Time Functions
+	functions["unix_time"] = func(args ...interface{}) (interface{}, error) {
+		offset := 0
+		if len(args) >= 1 {
+			offset = toInt(args[0])
+		}
+		return float64(time.Now().Unix() + int64(offset)), nil
+	}
+
+	functions["date_time"] = func(args ...interface{}) (interface{}, error) {
+		if len(args) < 1 {
+			return nil, errors.New("date_time requires a format argument")
+		}
+		return timeFromArgs(args[1:]).Format(toTimeLayout(types.ToString(args[0]))), nil
+	}
+
+	functions["rfc3339"] = func(args ...interface{}) (interface{}, error) {
+		return timeFromArgs(args).Format(time.RFC3339), nil
+	}
+
 	functions["waitfor"] = func(args ...interface{}) (interface{}, error) {
 		seconds := args[0].(float64)
 		time.Sleep(time.Duration(seconds) * time.Second)
 		return true, nil
 	}
+
+	addCryptoFunctions(functions)
+	addCompressionFunctions(functions)
+	addGraphQLFunctions(functions)
+	addSimilarityFunctions(functions)
 	return functions
 }
 
+// hmacAlgorithms contains the hash functions supported for hmac helpers
+var hmacAlgorithms = map[string]func() hash.Hash{
+	"md5":    md5.New,
+	"sha1":   sha1.New,
+	"sha256": sha256.New,
+	"sha384": sha512.New384,
+	"sha512": sha512.New,
+}
+
+// jwtAlgorithms maps jwt hmac algorithm names to their hash functions
+var jwtAlgorithms = map[string]func() hash.Hash{
+	"HS256": sha256.New,
+	"HS384": sha512.New384,
+	"HS512": sha512.New,
+}
+
+// jwtClaims returns the json payload of the claims of a jwt. The claims are
+// either a map, a json object or key=value pairs separated by & (ex.
+// sub=admin&admin=true) which can be used inline in {{ }} expressions.
+func jwtClaims(value interface{}) (string, error) {
+	var claims map[string]interface{}
+	switch v := value.(type) {
+	case map[string]interface{}:
+		claims = v
+	case map[interface{}]interface{}:
+		claims = make(map[string]interface{}, len(v))
+		for key, item := range v {
+			claims[types.ToString(key)] = item
+		}
+	default:
+		payload := strings.TrimSpace(types.ToString(value))
+		if strings.HasPrefix(payload, "{") {
+			return payload, nil
+		}
+		query, err := url.ParseQuery(payload)
+		if err != nil {
+			return "", fmt.Errorf("could not parse jwt claims: %s", err)
+		}
+		claims = make(map[string]interface{}, len(query))
+		for key, values := range query {
+			claims[key] = claimValue(values[len(values)-1])
+		}
+	}
+	data, err := json.Marshal(claims)
+	if err != nil {
+		return "", fmt.Errorf("could not encode jwt claims: %s", err)
+	}
+	return string(data), nil
+}
+
+// claimValue returns the integer or boolean value of a claim if it is one
+func claimValue(value string) interface{} {
+	if number, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return number
+	}
+	if boolean, err := strconv.ParseBool(value); err == nil {
+		return boolean
+	}
+	return value
+}
+
+// generateJWT creates a signed jwt token for the json payload. The
+// none algorithm creates an unsigned token.
+func generateJWT(payload, algorithm, secret string) (string, error) {
+	if strings.EqualFold(algorithm, "none") {
+		algorithm = "none"
+	} else {
+		algorithm = strings.ToUpper(algorithm)
+	}
+	hashFunc, ok := jwtAlgorithms[algorithm]
+	if !ok && algorithm != "none" {
+		return "", fmt.Errorf("unsupported jwt algorithm: %s", algorithm)
+	}
+
+	header := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"` + algorithm + `","typ":"JWT"}`))
+	unsigned := header + "." + base64.RawURLEncoding.EncodeToString([]byte(payload))
+	if algorithm == "none" {
+		return unsigned + ".", nil
+	}
+	h := hmac.New(hashFunc, []byte(secret))
+	_, _ = h.Write([]byte(unsigned))
+	return unsigned + "." + base64.RawURLEncoding.EncodeToString(h.Sum(nil)), nil
+}
+
+// strftimeReplacer converts strftime style directives to go time layouts
+var strftimeReplacer = strings.NewReplacer(
+	"%Y", "2006",
+	"%y", "06",
+	"%m", "01",
+	"%d", "02",
+	"%H", "15",
+	"%M", "04",
+	"%S", "05",
+	"%b", "Jan",
+	"%a", "Mon",
+	"%Z", "MST",
+	"%z", "-0700",
+	"%%", "%",
+)
+
+// toTimeLayout returns a go time layout for a format which can either
+// be a go layout or use strftime style directives (%Y-%m-%d).
+func toTimeLayout(format string) string {
+	if strings.Contains(format, "%") {
+		return strftimeReplacer.Replace(format)
+	}
+	return format
+}
+
+// timeFromArgs returns the time from an optional unix timestamp argument
+// or the current time in UTC if not provided.
+func timeFromArgs(args []interface{}) time.Time {
+	if len(args) >= 1 {
+		return time.Unix(int64(toInt(args[0])), 0).UTC()
+	}
+	return time.Now().UTC()
+}
+
+// toInt converts a numeric dsl argument to int. Numbers are
+// passed as float64 by the expression engine.
+func toInt(value interface{}) int {
+	switch v := value.(type) {
+	case int:
+		return v
+	case float64:
+		return int(v)
+	}
+	parsed, _ := strconv.Atoi(types.ToString(value))
+	return parsed
+}
+
+// urlEncodeAll percent-encodes every byte of the string
+func urlEncodeAll(s string) string {
+	var builder strings.Builder
+	for i := 0; i < len(s); i++ {
+		fmt.Fprintf(&builder, "%%%02X", s[i])
+	}
+	return builder.String()
+}
+
 func reverseString(s string) string {
 	runes := []rune(s)
 	for i, j := 0, len(runes)-1; i < j; i, j = i+1, j-1 {
@@ -0,0 +1,87 @@
+package dsl
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/Knetic/govaluate"
+	"github.com/projectdiscovery/nuclei/v2/pkg/types"
+)
+
+// maxBatchSize is the maximum number of operations of a batched graphql body
+const maxBatchSize = 1000
+
+// graphqlIntrospectionQuery is the query returning the schema of a graphql endpoint
+const graphqlIntrospectionQuery = `query IntrospectionQuery { __schema { queryType { name } mutationType { name } types { kind name enumValues { name } inputFields { name type { ...TypeRef } } fields { name args { name type { ...TypeRef } } type { ...TypeRef } } } } } fragment TypeRef on __Type { kind name ofType { kind name ofType { kind name ofType { kind name ofType { kind name } } } } }`
+
+// addGraphQLFunctions adds the helper functions building graphql request bodies.
+func addGraphQLFunctions(functions map[string]govaluate.ExpressionFunction) {
+	// graphql_query(query, [variables]) returns the json body of a query
+	// with optional variables as a json object.
+	functions["graphql_query"] = func(args ...interface{}) (interface{}, error) {
+		if len(args) == 0 {
+			return nil, errors.New("graphql_query requires a query argument")
+		}
+		variables, err := graphqlVariables(args[1:]...)
+		if err != nil {
+			return nil, err
+		}
+		body, err := graphqlBody(types.ToString(args[0]), variables)
+		if err != nil {
+			return nil, err
+		}
+		return string(body), nil
+	}
+
+	// graphql_batch(query, count) returns a json array body with the query repeated
+	functions["graphql_batch"] = func(args ...interface{}) (interface{}, error) {
+		if len(args) < 2 {
+			return nil, errors.New("graphql_batch requires query and count arguments")
+		}
+		count := toInt(args[1])
+		if count <= 0 || count > maxBatchSize {
+			return nil, fmt.Errorf("invalid graphql batch count: %d", count)
+		}
+		body, err := graphqlBody(types.ToString(args[0]), nil)
+		if err != nil {
+			return nil, err
+		}
+		bodies := make([]string, count)
+		for i := range bodies {
+			bodies[i] = string(body)
+		}
+		return "[" + strings.Join(bodies, ",") + "]", nil
+	}
+
+	// graphql_introspection() returns the json body of the introspection query
+	functions["graphql_introspection"] = func(args ...interface{}) (interface{}, error) {
+		body, err := graphqlBody(graphqlIntrospectionQuery, nil)
+		if err != nil {
+			return nil, err
+		}
+		return string(body), nil
+	}
+}
+
+// graphqlVariables decodes the optional json object of variables
+func graphqlVariables(args ...interface{}) (map[string]interface{}, error) {
+	if len(args) == 0 || types.ToString(args[0]) == "" {
+		return nil, nil
+	}
+	variables := make(map[string]interface{})
+	if err := json.Unmarshal([]byte(types.ToString(args[0])), &variables); err != nil {
+		return nil, fmt.Errorf("could not decode graphql variables: %s", err)
+	}
+	return variables, nil
+}
+
+// graphqlBody returns the json body of a graphql request
+func graphqlBody(query string, variables map[string]interface{}) ([]byte, error) {
+	request := map[string]interface{}{"query": query}
+	if len(variables) > 0 {
+		request["variables"] = variables
+	}
+	return json.Marshal(request)
+}
@@ -0,0 +1,168 @@
+package dsl
+
+import (
+	"fmt"
+	"hash/fnv"
+	"math/bits"
+	"strconv"
+	"strings"
+
+	"github.com/Knetic/govaluate"
+	"github.com/pkg/errors"
+	"github.com/projectdiscovery/nuclei/v2/pkg/types"
+)
+
+// maxLevenshteinCells is the maximum size of the edit distance matrix, the
+// similarity of larger inputs is computed from their words instead.
+const maxLevenshteinCells = 4 * 1024 * 1024
+
+// addSimilarityFunctions adds the similarity helper functions.
+func addSimilarityFunctions(functions map[string]govaluate.ExpressionFunction) {
+	functions["similarity"] = func(args ...interface{}) (interface{}, error) {
+		if len(args) != 2 {
+			return nil, errors.New("similarity requires two arguments")
+		}
+		return Similarity(types.ToString(args[0]), types.ToString(args[1])), nil
+	}
+
+	functions["levenshtein"] = func(args ...interface{}) (interface{}, error) {
+		if len(args) != 2 {
+			return nil, errors.New("levenshtein requires two arguments")
+		}
+		first, second := []rune(types.ToString(args[0])), []rune(types.ToString(args[1]))
+		if len(first)*len(second) > maxLevenshteinCells {
+			return nil, errors.New("levenshtein inputs are too large, use similarity instead")
+		}
+		return float64(levenshtein(first, second)), nil
+	}
+
+	functions["simhash"] = func(args ...interface{}) (interface{}, error) {
+		if len(args) != 1 {
+			return nil, errors.New("simhash requires one argument")
+		}
+		return fmt.Sprintf("%016x", Simhash(types.ToString(args[0]))), nil
+	}
+
+	functions["simhash_distance"] = func(args ...interface{}) (interface{}, error) {
+		if len(args) != 2 {
+			return nil, errors.New("simhash_distance requires two arguments")
+		}
+		first, err := strconv.ParseUint(types.ToString(args[0]), 16, 64)
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid simhash")
+		}
+		second, err := strconv.ParseUint(types.ToString(args[1]), 16, 64)
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid simhash")
+		}
+		return float64(bits.OnesCount64(first ^ second)), nil
+	}
+}
+
+// Similarity returns the similarity ratio (0-1) of two strings, computed
+// from their levenshtein distance, or from their common words for large
+// strings where the edit distance is too expensive.
+func Similarity(a, b string) float64 {
+	if a == b {
+		return 1
+	}
+	first, second := []rune(a), []rune(b)
+	if len(first) == 0 || len(second) == 0 {
+		return 0
+	}
+	if len(first)*len(second) > maxLevenshteinCells {
+		return wordsSimilarity(a, b)
+	}
+	longest := len(first)
+	if len(second) > longest {
+		longest = len(second)
+	}
+	return 1 - float64(levenshtein(first, second))/float64(longest)
+}
+
+// levenshtein returns the edit distance of two strings
+func levenshtein(a, b []rune) int {
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = minimum(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(b)]
+}
+
+// wordsSimilarity returns the dice coefficient of the words of two strings
+func wordsSimilarity(a, b string) float64 {
+	counts := make(map[string]int)
+	first := strings.Fields(a)
+	for _, word := range first {
+		counts[word]++
+	}
+	second := strings.Fields(b)
+	var common int
+	for _, word := range second {
+		if counts[word] > 0 {
+			counts[word]--
+			common++
+		}
+	}
+	if len(first)+len(second) == 0 {
+		return 0
+	}
+	return 2 * float64(common) / float64(len(first)+len(second))
+}
+
+// Simhash returns the 64-bit simhash of the word shingles of a string.
+// The hashes of similar strings differ in few bits.
+func Simhash(value string) uint64 {
+	words := strings.Fields(strings.ToLower(value))
+	if len(words) == 0 {
+		return 0
+	}
+	const shingleSize = 3
+
+	var weights [64]int
+	for i := 0; i == 0 || i+shingleSize <= len(words); i++ {
+		end := i + shingleSize
+		if end > len(words) {
+			end = len(words)
+		}
+		hasher := fnv.New64a()
+		_, _ = hasher.Write([]byte(strings.Join(words[i:end], " ")))
+		hash := hasher.Sum64()
+		for bit := 0; bit < 64; bit++ {
+			if hash&(1<<uint(bit)) != 0 {
+				weights[bit]++
+			} else {
+				weights[bit]--
+			}
+		}
+	}
+	var result uint64
+	for bit, weight := range weights {
+		if weight > 0 {
+			result |= 1 << uint(bit)
+		}
+	}
+	return result
+}
+
+func minimum(values ...int) int {
+	result := values[0]
+	for _, value := range values[1:] {
+		if value < result {
+			result = value
+		}
+	}
+	return result
+}
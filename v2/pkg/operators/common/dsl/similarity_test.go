@@ -0,0 +1,61 @@
+package dsl
+
+import (
+	"math/bits"
+	"strings"
+	"testing"
+
+	"github.com/Knetic/govaluate"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSimilarity(t *testing.T) {
+	require.Equal(t, float64(1), Similarity("same", "same"), "could not get similarity of equal strings")
+	require.Equal(t, float64(0), Similarity("", "value"), "could not get similarity of empty string")
+	require.InDelta(t, 0.75, Similarity("test", "text"), 0.001, "could not get levenshtein similarity")
+	require.InDelta(t, 0.0, Similarity("abc", "xyz"), 0.001, "could not get similarity of different strings")
+
+	// Large inputs are compared by their words
+	first := strings.Repeat("alpha beta gamma ", 1000)
+	second := strings.Repeat("alpha beta delta ", 1000)
+	require.InDelta(t, 2.0/3.0, Similarity(first, second), 0.001, "could not get words similarity")
+}
+
+func TestSimhash(t *testing.T) {
+	page := "<html><body><h1>Access denied</h1><p>You are not allowed to access this page, contact the administrator</p></body></html>"
+	similar := strings.Replace(page, "administrator", "administrators", 1)
+	different := "<html><body><h1>Admin dashboard</h1><table><tr><td>users</td><td>settings</td></tr></table></body></html>"
+
+	distance := bits.OnesCount64(Simhash(page) ^ Simhash(similar))
+	require.Less(t, distance, bits.OnesCount64(Simhash(page)^Simhash(different)), "could not get closer simhash for similar page")
+	require.Equal(t, Simhash(page), Simhash(strings.ToUpper(page)), "could not ignore case in simhash")
+}
+
+func TestSimilarityHelperFunctions(t *testing.T) {
+	items := []struct {
+		expression string
+		expected   interface{}
+	}{
+		{expression: `similarity("test", "text")`, expected: 0.75},
+		{expression: `similarity("same", "same") > 0.9`, expected: true},
+		{expression: `levenshtein("kitten", "sitting")`, expected: float64(3)},
+		{expression: `simhash_distance(simhash("a b c d"), simhash("a b c d"))`, expected: float64(0)},
+		{expression: `simhash_distance("ff", "0f")`, expected: float64(4)},
+	}
+	for _, item := range items {
+		compiled, err := govaluate.NewEvaluableExpressionWithFunctions(item.expression, HelperFunctions())
+		require.Nil(t, err, "could not compile %s", item.expression)
+		value, err := compiled.Evaluate(nil)
+		require.Nil(t, err, "could not evaluate %s", item.expression)
+		require.Equal(t, item.expected, value, "could not get correct value for %s", item.expression)
+	}
+}
+
+func TestSimilarityHelperArguments(t *testing.T) {
+	for _, expression := range []string{`simhash()`, `simhash("a", "b")`, `similarity("a")`, `levenshtein("a")`, `simhash_distance("ff")`} {
+		compiled, err := govaluate.NewEvaluableExpressionWithFunctions(expression, HelperFunctions())
+		require.Nil(t, err, "could not compile %s", expression)
+		_, err = compiled.Evaluate(nil)
+		require.NotNil(t, err, "could not reject arguments of %s", expression)
+	}
+}
@@ -4,6 +4,10 @@ import (
 	"fmt"
 	"regexp"
 	"strings"
+
+	"github.com/Knetic/govaluate"
+	"github.com/projectdiscovery/nuclei/v2/pkg/operators/common/dsl"
+	"github.com/projectdiscovery/nuclei/v2/pkg/protocols/common/replacer"
 )
 
 // CompileExtractors performs the initial setup operation on a extractor
@@ -24,6 +28,17 @@ func (e *Extractor) CompileExtractors() error {
 		e.regexCompiled = append(e.regexCompiled, compiled)
 	}
 
+	// Compile the dsl expressions
+	for _, expr := range e.DSL {
+		compiled, err := govaluate.NewEvaluableExpressionWithFunctions(expr, dsl.HelperFunctions())
+		if err != nil {
+			return fmt.Errorf("could not compile dsl: %s", expr)
+		}
+		e.dslCompiled = append(e.dslCompiled, compiled)
+	}
+
+	e.placeholders = replacer.Placeholders(append(append([]string{}, e.Regex...), e.DSL...)...)
+
 	for i, kval := range e.KVal {
 		e.KVal[i] = strings.ToLower(kval)
 	}
@@ -34,3 +49,42 @@ func (e *Extractor) CompileExtractors() error {
 	}
 	return nil
 }
+
+// ResolveValues returns a copy of the extractor with the placeholders of its
+// regexes and dsl expressions replaced by the values of the data, such as the
+// random auto-variables generated for the request. The extractor itself is
+// returned if none of its placeholders has a value.
+func (e *Extractor) ResolveValues(data map[string]interface{}) *Extractor {
+	var values map[string]interface{}
+	for _, name := range e.placeholders {
+		if value, ok := data[name]; ok {
+			if values == nil {
+				values = make(map[string]interface{})
+			}
+			values[name] = value
+		}
+	}
+	if len(values) == 0 {
+		return e
+	}
+
+	resolved := &Extractor{
+		Name:       e.Name,
+		Type:       e.Type,
+		RegexGroup: e.RegexGroup,
+		KVal:       e.KVal,
+		Part:       e.Part,
+		Internal:   e.Internal,
+		Store:      e.Store,
+	}
+	for _, regex := range e.Regex {
+		resolved.Regex = append(resolved.Regex, replacer.Replace(regex, values))
+	}
+	for _, expr := range e.DSL {
+		resolved.DSL = append(resolved.DSL, replacer.Replace(expr, values))
+	}
+	if err := resolved.CompileExtractors(); err != nil {
+		return e
+	}
+	return resolved
+}
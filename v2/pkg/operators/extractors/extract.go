@@ -42,3 +42,23 @@ func (e *Extractor) ExtractKval(data map[string]interface{}) map[string]struct{}
 	}
 	return results
 }
+
+// ExtractDSL evaluates dsl expressions on a data map and returns the results
+func (e *Extractor) ExtractDSL(data map[string]interface{}) map[string]struct{} {
+	results := make(map[string]struct{})
+
+	for _, expression := range e.dslCompiled {
+		result, err := expression.Evaluate(data)
+		if err != nil || result == nil {
+			continue
+		}
+		resultString := types.ToString(result)
+		if resultString == "" {
+			continue
+		}
+		if _, ok := results[resultString]; !ok {
+			results[resultString] = struct{}{}
+		}
+	}
+	return results
+}
@@ -1,6 +1,10 @@
 package extractors
 
-import "regexp"
+import (
+	"regexp"
+
+	"github.com/Knetic/govaluate"
+)
 
 // Extractor is used to extract part of response using a regex.
 type Extractor struct {
@@ -21,12 +25,22 @@ type Extractor struct {
 	// KVal are the kval to be present in the response headers/cookies
 	KVal []string `yaml:"kval,omitempty"`
 
+	// DSL are the dsl expressions to evaluate for extraction
+	DSL []string `yaml:"dsl,omitempty"`
+	// dslCompiled is the compiled variant
+	dslCompiled []*govaluate.EvaluableExpression
+	// placeholders are the {{name}} placeholders of the regexes and dsl expressions
+	placeholders []string
+
 	// Part is the part of the request to match
 	//
 	// By default, matching is performed in request body.
 	Part string `yaml:"part,omitempty"`
 	// Internal defines if this is used internally
 	Internal bool `yaml:"internal,omitempty"`
+	// Store defines if the first extracted value is stored for the host
+	// to be used by the later templates of the scan.
+	Store bool `yaml:"store,omitempty"`
 }
 
 // ExtractorType is the type of the extractor specified
@@ -37,12 +51,15 @@ const (
 	RegexExtractor ExtractorType = iota + 1
 	// KValExtractor extracts responses with key:value
 	KValExtractor
+	// DSLExtractor extracts responses by evaluating dsl expressions
+	DSLExtractor
 )
 
 // ExtractorTypes is an table for conversion of extractor type from string.
 var ExtractorTypes = map[string]ExtractorType{
 	"regex": RegexExtractor,
 	"kval":  KValExtractor,
+	"dsl":   DSLExtractor,
 }
 
 // GetType returns the type of the matcher
@@ -0,0 +1,107 @@
+package matchers
+
+// minAutomatonWords is the minimum number of words for which word
+// matchers are compiled into an Aho-Corasick automaton.
+const minAutomatonWords = 4
+
+// ahoCorasick is an Aho-Corasick automaton matching a set of patterns
+// against a corpus in a single pass regardless of the number of patterns.
+type ahoCorasick struct {
+	nodes    []acNode
+	patterns int
+}
+
+// acNode is a single state of the automaton
+type acNode struct {
+	next   map[byte]int32
+	fail   int32
+	output []int
+}
+
+// newAhoCorasick builds an automaton for the patterns. Empty patterns are not supported.
+func newAhoCorasick(patterns []string) *ahoCorasick {
+	a := &ahoCorasick{nodes: []acNode{{next: make(map[byte]int32)}}, patterns: len(patterns)}
+
+	for i, pattern := range patterns {
+		state := int32(0)
+		for j := 0; j < len(pattern); j++ {
+			next, ok := a.nodes[state].next[pattern[j]]
+			if !ok {
+				a.nodes = append(a.nodes, acNode{next: make(map[byte]int32)})
+				next = int32(len(a.nodes) - 1)
+				a.nodes[state].next[pattern[j]] = next
+			}
+			state = next
+		}
+		a.nodes[state].output = append(a.nodes[state].output, i)
+	}
+
+	// Build the failure links breadth first merging the outputs of
+	// the failure states so that each state lists all patterns ending there.
+	queue := make([]int32, 0, len(a.nodes))
+	for _, child := range a.nodes[0].next {
+		queue = append(queue, child)
+	}
+	for len(queue) > 0 {
+		state := queue[0]
+		queue = queue[1:]
+
+		for char, child := range a.nodes[state].next {
+			fail := a.nodes[state].fail
+			for {
+				if next, ok := a.nodes[fail].next[char]; ok && next != child {
+					a.nodes[child].fail = next
+					break
+				}
+				if fail == 0 {
+					break
+				}
+				fail = a.nodes[fail].fail
+			}
+			failOutput := a.nodes[a.nodes[child].fail].output
+			if len(failOutput) > 0 {
+				a.nodes[child].output = append(a.nodes[child].output, failOutput...)
+			}
+			queue = append(queue, child)
+		}
+	}
+	return a
+}
+
+// Match returns true if any of the patterns are found in the corpus, or
+// all of them if matchAll is true. Scanning stops as soon as the result is known.
+func (a *ahoCorasick) Match(corpus string, matchAll bool) bool {
+	var found []bool
+	var count int
+	if matchAll {
+		found = make([]bool, a.patterns)
+	}
+
+	state := int32(0)
+	for i := 0; i < len(corpus); i++ {
+		char := corpus[i]
+		for {
+			if next, ok := a.nodes[state].next[char]; ok {
+				state = next
+				break
+			}
+			if state == 0 {
+				break
+			}
+			state = a.nodes[state].fail
+		}
+		for _, pattern := range a.nodes[state].output {
+			if !matchAll {
+				return true
+			}
+			if !found[pattern] {
+				found[pattern] = true
+				count++
+			}
+		}
+		if matchAll && count == a.patterns {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,52 @@
+package matchers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAhoCorasick(t *testing.T) {
+	automaton := newAhoCorasick([]string{"he", "she", "his", "hers"})
+
+	require.True(t, automaton.Match("ushers", false), "could not match any pattern")
+	require.False(t, automaton.Match("xyz", false), "could match invalid corpus")
+	require.True(t, automaton.Match("she said his hers", true), "could not match all patterns")
+	require.False(t, automaton.Match("ushers", true), "could match all patterns with missing one")
+
+	// Overlapping patterns found through failure links
+	automaton = newAhoCorasick([]string{"abcd", "bc", "c", "bcx"})
+	require.True(t, automaton.Match("xabcx abcd", true), "could not match overlapping patterns")
+}
+
+func TestWordsAutomaton(t *testing.T) {
+	m := &Matcher{Type: "word", Words: []string{"tomcat", "apache", "nginx", "iis"}}
+	err := m.CompileMatchers()
+	require.Nil(t, err, "could not compile matcher")
+	require.NotNil(t, m.wordsCompiled, "could not compile words automaton")
+
+	require.True(t, m.MatchWords("Server: nginx/1.19"), "could not match valid OR condition")
+	require.False(t, m.MatchWords("Server: caddy"), "could match invalid OR condition")
+
+	m = &Matcher{Type: "word", Condition: "and", Words: []string{"a", "b", "c", "d"}}
+	err = m.CompileMatchers()
+	require.Nil(t, err, "could not compile matcher")
+	require.True(t, m.MatchWords("d c b a"), "could not match valid AND condition")
+	require.False(t, m.MatchWords("a b c"), "could match invalid AND condition")
+
+	m = &Matcher{Type: "word", Words: []string{"a", "b", "c", "{{randstr}}"}}
+	err = m.CompileMatchers()
+	require.Nil(t, err, "could not compile matcher")
+	require.Nil(t, m.wordsCompiled, "could compile words automaton with dynamic words")
+}
+
+func TestRegexPrefix(t *testing.T) {
+	m := &Matcher{Type: "regex", Regex: []string{`root:.*:0:0:`, `[a-z]+[0-9]`}}
+	err := m.CompileMatchers()
+	require.Nil(t, err, "could not compile matcher")
+	require.Equal(t, []string{"root:", ""}, m.regexPrefixes, "could not get regex prefixes")
+
+	require.True(t, m.MatchRegex("root:x:0:0:root"), "could not match regex with prefix")
+	require.True(t, m.MatchRegex("abc1"), "could not match regex without prefix")
+	require.False(t, m.MatchRegex("ROOT"), "could match invalid regex")
+}
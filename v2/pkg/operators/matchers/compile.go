@@ -4,9 +4,12 @@ import (
 	"encoding/hex"
 	"fmt"
 	"regexp"
+	"strings"
 
 	"github.com/Knetic/govaluate"
 	"github.com/projectdiscovery/nuclei/v2/pkg/operators/common/dsl"
+	"github.com/projectdiscovery/nuclei/v2/pkg/protocols/common/replacer"
+	"github.com/projectdiscovery/nuclei/v2/pkg/types"
 )
 
 // CompileMatchers performs the initial setup operation on a matcher
@@ -39,6 +42,15 @@ func (m *Matcher) CompileMatchers() error {
 			return fmt.Errorf("could not compile regex: %s", regex)
 		}
 		m.regexCompiled = append(m.regexCompiled, compiled)
+
+		// Literal prefixes are used to quickly discard non-matching corpuses
+		prefix, _ := compiled.LiteralPrefix()
+		m.regexPrefixes = append(m.regexPrefixes, prefix)
+	}
+
+	// Compile large word lists into an automaton matching them all in one pass
+	if len(m.Words) >= minAutomatonWords && !hasDynamicWords(m.Words) {
+		m.wordsCompiled = newAhoCorasick(m.Words)
 	}
 
 	// Compile the dsl expressions
@@ -50,6 +62,14 @@ func (m *Matcher) CompileMatchers() error {
 		m.dslCompiled = append(m.dslCompiled, compiled)
 	}
 
+	if m.matcherType == DiffMatcher {
+		if err := m.compileDiff(); err != nil {
+			return err
+		}
+	}
+
+	m.placeholders = replacer.Placeholders(append(append(append(append([]string{}, m.Words...), m.Regex...), m.Binary...), m.DSL...)...)
+
 	// Setup the condition type, if any.
 	if m.Condition != "" {
 		m.condition, ok = ConditionTypes[m.Condition]
@@ -61,3 +81,110 @@ func (m *Matcher) CompileMatchers() error {
 	}
 	return nil
 }
+
+// ResolveValues returns a copy of the matcher with the placeholders of its words,
+// regexes, binaries and dsl expressions replaced by the values of the data, such
+// as the random auto-variables generated for the request. The matcher itself is
+// returned if none of its placeholders has a value.
+func (m *Matcher) ResolveValues(data map[string]interface{}) *Matcher {
+	values := placeholderValues(m.placeholders, data)
+	if len(values) == 0 {
+		return m
+	}
+	hexValues := make(map[string]interface{}, len(values))
+	for k, v := range values {
+		hexValues[k] = hex.EncodeToString([]byte(types.ToString(v)))
+	}
+
+	resolved := &Matcher{
+		Type:      m.Type,
+		Condition: m.Condition,
+		Part:      m.Part,
+		Negative:  m.Negative,
+		Name:      m.Name,
+		Status:    m.Status,
+		Size:      m.Size,
+		Words:     replaceAll(m.Words, values),
+		Regex:     replaceAll(m.Regex, values),
+		Binary:    replaceAll(m.Binary, hexValues),
+		DSL:       replaceAll(m.DSL, values),
+		Compare:   m.Compare,
+		Diff:      m.Diff,
+		Threshold: m.Threshold,
+	}
+	if err := resolved.CompileMatchers(); err != nil {
+		return m
+	}
+	return resolved
+}
+
+// placeholderValues returns the values of the placeholders present in the data
+func placeholderValues(placeholders []string, data map[string]interface{}) map[string]interface{} {
+	var values map[string]interface{}
+	for _, name := range placeholders {
+		value, ok := data[name]
+		if !ok {
+			continue
+		}
+		if values == nil {
+			values = make(map[string]interface{})
+		}
+		values[name] = value
+	}
+	return values
+}
+
+// replaceAll replaces the placeholders of the items with their values
+func replaceAll(items []string, values map[string]interface{}) []string {
+	if len(items) == 0 {
+		return items
+	}
+	replaced := make([]string, len(items))
+	for i, item := range items {
+		replaced[i] = replacer.Replace(item, values)
+	}
+	return replaced
+}
+
+// hasDynamicWords returns true if any of the words is empty or contains
+// dynamic values which are replaced at match time.
+func hasDynamicWords(words []string) bool {
+	for _, word := range words {
+		if word == "" || strings.Contains(word, replacer.MarkerParenthesisOpen) {
+			return true
+		}
+	}
+	return false
+}
+
+// compileDiff validates the compared requests and properties of a diff
+// matcher, setting up their default values.
+func (m *Matcher) compileDiff() error {
+	if len(m.Compare) == 0 {
+		m.Compare = []int{1, 2}
+	}
+	if len(m.Compare) != 2 || m.Compare[0] < 1 || m.Compare[1] < 1 || m.Compare[0] == m.Compare[1] {
+		return fmt.Errorf("diff matcher requires two different request numbers to compare: %v", m.Compare)
+	}
+	if len(m.Diff) == 0 {
+		m.Diff = DiffProperties
+	}
+	for _, property := range m.Diff {
+		valid := false
+		for _, value := range DiffProperties {
+			if property == value {
+				valid = true
+			}
+		}
+		if !valid {
+			return fmt.Errorf("unknown diff property specified: %s", property)
+		}
+	}
+	if m.Threshold == 0 {
+		m.Threshold = 0.9
+	}
+	if m.Threshold < 0 || m.Threshold > 1 {
+		return fmt.Errorf("diff matcher threshold must be between 0 and 1: %v", m.Threshold)
+	}
+	return nil
+}
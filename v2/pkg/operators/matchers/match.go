@@ -3,6 +3,8 @@ package matchers
 import (
 	"encoding/hex"
 	"strings"
+
+	"github.com/projectdiscovery/nuclei/v2/pkg/operators/common/dsl"
 )
 
 // MatchStatusCode matches a status code check against a corpus
@@ -39,6 +41,9 @@ func (m *Matcher) MatchSize(length int) bool {
 
 // MatchWords matches a word check against a corpus.
 func (m *Matcher) MatchWords(corpus string) bool {
+	if m.wordsCompiled != nil {
+		return m.wordsCompiled.Match(corpus, m.condition == ANDCondition)
+	}
 	// Iterate over all the words accepted as valid
 	for i, word := range m.Words {
 		// Continue if the word doesn't match
@@ -69,8 +74,9 @@ func (m *Matcher) MatchWords(corpus string) bool {
 func (m *Matcher) MatchRegex(corpus string) bool {
 	// Iterate over all the regexes accepted as valid
 	for i, regex := range m.regexCompiled {
-		// Continue if the regex doesn't match
-		if !regex.MatchString(corpus) {
+		// Continue if the regex doesn't match, the literal prefix
+		// is checked first as it is much cheaper than the regex.
+		if !m.hasRegexPrefix(i, corpus) || !regex.MatchString(corpus) {
 			// If we are in an AND request and a match failed,
 			// return false as the AND condition fails on any single mismatch.
 			if m.condition == ANDCondition {
@@ -93,6 +99,14 @@ func (m *Matcher) MatchRegex(corpus string) bool {
 	return false
 }
 
+// hasRegexPrefix returns true if the corpus contains the literal prefix of a regex
+func (m *Matcher) hasRegexPrefix(index int, corpus string) bool {
+	if index >= len(m.regexPrefixes) || m.regexPrefixes[index] == "" {
+		return true
+	}
+	return strings.Contains(corpus, m.regexPrefixes[index])
+}
+
 // MatchBinary matches a binary check against a corpus
 func (m *Matcher) MatchBinary(corpus string) bool {
 	// Iterate over all the words accepted as valid
@@ -157,3 +171,53 @@ func (m *Matcher) MatchDSL(data map[string]interface{}) bool {
 	}
 	return false
 }
+
+// MatchDiff matches the parts of the responses of two requests, comparing
+// their status codes, sizes and similarity. The sizes differ when their
+// relative difference is above the complement of the threshold.
+func (m *Matcher) MatchDiff(firstStatus, secondStatus int, first, second string) bool {
+	for i, property := range m.Diff {
+		var differs bool
+		switch property {
+		case "status":
+			differs = firstStatus != secondStatus
+		case "size":
+			differs = sizeDiffers(len(first), len(second), m.Threshold)
+		case "similarity":
+			differs = dsl.Similarity(first, second) < m.Threshold
+		}
+		if !differs {
+			// If we are in an AND request and a match failed,
+			// return false as the AND condition fails on any single mismatch.
+			if m.condition == ANDCondition {
+				return false
+			}
+			// Continue with the flow since its an OR Condition.
+			continue
+		}
+
+		// If the condition was an OR, return on the first match.
+		if m.condition == ORCondition {
+			return true
+		}
+
+		// If we are at the end of the properties, return with true
+		if len(m.Diff)-1 == i {
+			return true
+		}
+	}
+	return false
+}
+
+// sizeDiffers returns true if the relative difference of two sizes is
+// above the complement of the threshold.
+func sizeDiffers(first, second int, threshold float64) bool {
+	if first == second {
+		return false
+	}
+	largest, difference := first, first-second
+	if second > first {
+		largest, difference = second, second-first
+	}
+	return float64(difference)/float64(largest) > 1-threshold
+}
@@ -37,3 +37,45 @@ func TestHexEncoding(t *testing.T) {
 	matched := m.MatchWords("PING")
 	require.True(t, matched, "Could not match valid Hex condition")
 }
+
+func TestMatchDiff(t *testing.T) {
+	m := &Matcher{Type: "diff", Diff: []string{"status"}}
+	require.Nil(t, m.CompileMatchers(), "could not compile diff matcher")
+	require.Equal(t, []int{1, 2}, m.Compare, "could not get default compared requests")
+
+	require.True(t, m.MatchDiff(403, 200, "forbidden", "welcome admin"), "could not match different status")
+	require.False(t, m.MatchDiff(403, 403, "forbidden", "welcome admin"), "could match same status")
+
+	m = &Matcher{Type: "diff", Diff: []string{"size", "similarity"}, Condition: "and"}
+	require.Nil(t, m.CompileMatchers(), "could not compile diff matcher")
+	require.False(t, m.MatchDiff(200, 200, "<html>home page</html>", "<html>home page!</html>"), "could match similar responses")
+	require.True(t, m.MatchDiff(200, 200, "<html>home page</html>", "<html><h1>admin panel</h1><table>users</table></html>"), "could not match different responses")
+
+	for _, invalid := range []*Matcher{
+		{Type: "diff", Compare: []int{1}},
+		{Type: "diff", Compare: []int{2, 2}},
+		{Type: "diff", Diff: []string{"headers"}},
+		{Type: "diff", Threshold: 2},
+	} {
+		require.NotNil(t, invalid.CompileMatchers(), "could compile invalid diff matcher")
+	}
+}
+
+func TestResolveValues(t *testing.T) {
+	data := map[string]interface{}{"randstr": "abc123"}
+
+	m := &Matcher{Type: "binary", Binary: []string{"3c{{randstr}}3e"}}
+	err := m.CompileMatchers()
+	require.Nil(t, err, "could not compile binary matcher")
+	require.True(t, m.ResolveValues(data).MatchBinary("<abc123>"), "could not match binary with random value")
+
+	m = &Matcher{Type: "dsl", DSL: []string{`contains(body, "{{randstr}}")`}}
+	err = m.CompileMatchers()
+	require.Nil(t, err, "could not compile dsl matcher")
+	require.True(t, m.ResolveValues(data).MatchDSL(map[string]interface{}{"body": "<abc123>"}), "could not match dsl with random value")
+
+	m = &Matcher{Type: "word", Words: []string{"static"}}
+	err = m.CompileMatchers()
+	require.Nil(t, err, "could not compile word matcher")
+	require.Same(t, m, m.ResolveValues(data), "could not reuse matcher without placeholders")
+}
@@ -39,11 +39,24 @@ type Matcher struct {
 	// Encoding specifies the encoding for the word content if any.
 	Encoding string `yaml:"encoding,omitempty"`
 
+	// Compare are the numbers of the two requests whose responses are
+	// compared by diff matchers, requiring req-condition. (Default 1, 2)
+	Compare []int `yaml:"compare,omitempty"`
+	// Diff are the properties of the responses which must differ for diff
+	// matchers: status, size and similarity. (Default all of them)
+	Diff []string `yaml:"diff,omitempty"`
+	// Threshold is the similarity ratio (0-1) of the parts of the responses
+	// below which they differ for diff matchers. (Default 0.9)
+	Threshold float64 `yaml:"threshold,omitempty"`
+
 	// cached data for the compiled matcher
 	condition     ConditionType
 	matcherType   MatcherType
 	regexCompiled []*regexp.Regexp
+	regexPrefixes []string
 	dslCompiled   []*govaluate.EvaluableExpression
+	wordsCompiled *ahoCorasick
+	placeholders  []string
 }
 
 // MatcherType is the type of the matcher specified
@@ -62,6 +75,8 @@ const (
 	SizeMatcher
 	// DSLMatcher matches based upon dsl syntax
 	DSLMatcher
+	// DiffMatcher matches responses differing from the response of another request
+	DiffMatcher
 )
 
 // MatcherTypes is an table for conversion of matcher type from string.
@@ -72,8 +87,12 @@ var MatcherTypes = map[string]MatcherType{
 	"regex":  RegexMatcher,
 	"binary": BinaryMatcher,
 	"dsl":    DSLMatcher,
+	"diff":   DiffMatcher,
 }
 
+// DiffProperties are the properties of the responses compared by diff matchers
+var DiffProperties = []string{"status", "size", "similarity"}
+
 // ConditionType is the type of condition for matcher
 type ConditionType int
 
@@ -1,6 +1,9 @@
 package operators
 
 import (
+	"sort"
+	"strings"
+
 	"github.com/pkg/errors"
 	"github.com/projectdiscovery/nuclei/v2/pkg/operators/extractors"
 	"github.com/projectdiscovery/nuclei/v2/pkg/operators/matchers"
@@ -63,6 +66,26 @@ type Result struct {
 	DynamicValues map[string]interface{}
 	// PayloadValues contains payload values provided by user. (Optional)
 	PayloadValues map[string]interface{}
+	// StoredValues contains the values of the extractors to store for the host
+	StoredValues map[string]interface{}
+
+	// matchersCondition is the condition of the matchers that created the result
+	matchersCondition matchers.ConditionType
+}
+
+// MatcherNames returns the names of the matched matchers for which a result
+// event should be created. Matchers combined with AND condition form a single
+// finding so their names are joined together.
+func (r *Result) MatcherNames() []string {
+	names := make([]string, 0, len(r.Matches))
+	for name := range r.Matches {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	if r.matchersCondition == matchers.ANDCondition && len(names) > 1 {
+		return []string{strings.Join(names, ",")}
+	}
+	return names
 }
 
 // Merge merges a result structure into the other.
@@ -73,6 +96,9 @@ func (r *Result) Merge(result *Result) {
 	if !r.Extracted && result.Extracted {
 		r.Extracted = result.Extracted
 	}
+	if r.matchersCondition == 0 {
+		r.matchersCondition = result.matchersCondition
+	}
 
 	for k, v := range result.Matches {
 		r.Matches[k] = v
@@ -87,6 +113,12 @@ func (r *Result) Merge(result *Result) {
 	for k, v := range result.PayloadValues {
 		r.PayloadValues[k] = v
 	}
+	for k, v := range result.StoredValues {
+		if r.StoredValues == nil {
+			r.StoredValues = make(map[string]interface{})
+		}
+		r.StoredValues[k] = v
+	}
 }
 
 // MatchFunc performs matching operation for a matcher on model and returns true or false.
@@ -101,18 +133,30 @@ func (r *Operators) Execute(data map[string]interface{}, match MatchFunc, extrac
 
 	var matches bool
 	result := &Result{
-		Matches:       make(map[string]struct{}),
-		Extracts:      make(map[string][]string),
-		DynamicValues: make(map[string]interface{}),
+		Matches:           make(map[string]struct{}),
+		Extracts:          make(map[string][]string),
+		DynamicValues:     make(map[string]interface{}),
+		StoredValues:      make(map[string]interface{}),
+		matchersCondition: matcherCondition,
 	}
 
 	// Start with the extractors first and evaluate them.
+	//
+	// The placeholders of the operators are replaced with the values
+	// of the data, such as the random auto-variables of the request.
 	for _, extractor := range r.Extractors {
 		var extractorResults []string
 
+		extractor = extractor.ResolveValues(data)
+
 		for match := range extract(data, extractor) {
 			extractorResults = append(extractorResults, match)
 
+			if extractor.Store && extractor.Name != "" {
+				if _, ok := result.StoredValues[extractor.Name]; !ok {
+					result.StoredValues[extractor.Name] = match
+				}
+			}
 			if extractor.Internal {
 				if _, ok := result.DynamicValues[extractor.Name]; !ok {
 					result.DynamicValues[extractor.Name] = match
@@ -128,7 +172,7 @@ func (r *Operators) Execute(data map[string]interface{}, match MatchFunc, extrac
 
 	for _, matcher := range r.Matchers {
 		// Check if the matcher matched
-		if !match(data, matcher) {
+		if !match(data, matcher.ResolveValues(data)) {
 			// If the condition is AND we haven't matched, try next request.
 			if matcherCondition == matchers.ANDCondition {
 				if len(result.DynamicValues) > 0 {
@@ -137,9 +181,9 @@ func (r *Operators) Execute(data map[string]interface{}, match MatchFunc, extrac
 				return nil, false
 			}
 		} else {
-			// If the matcher has matched, and its an OR
-			// write the first output then move to next matcher.
-			if matcherCondition == matchers.ORCondition && matcher.Name != "" {
+			// Record the name of every matched matcher so that it can be
+			// used in the output, workflows and later requests.
+			if matcher.Name != "" {
 				result.Matches[matcher.Name] = struct{}{}
 			}
 			matches = true
@@ -0,0 +1,68 @@
+package operators
+
+import (
+	"testing"
+
+	"github.com/projectdiscovery/nuclei/v2/pkg/operators/extractors"
+	"github.com/projectdiscovery/nuclei/v2/pkg/operators/matchers"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatcherNames(t *testing.T) {
+	match := func(data map[string]interface{}, matcher *matchers.Matcher) bool { return true }
+	extract := func(data map[string]interface{}, extractor *extractors.Extractor) map[string]struct{} { return nil }
+
+	operators := &Operators{Matchers: []*matchers.Matcher{{Name: "b"}, {Name: "a"}, {}}}
+	operators.matchersCondition = matchers.ORCondition
+	result, ok := operators.Execute(nil, match, extract)
+	require.True(t, ok, "could not execute or operators")
+	require.Equal(t, []string{"a", "b"}, result.MatcherNames(), "could not get or matcher names")
+
+	operators.matchersCondition = matchers.ANDCondition
+	result, ok = operators.Execute(nil, match, extract)
+	require.True(t, ok, "could not execute and operators")
+	require.Len(t, result.Matches, 2, "could not record and matcher names")
+	require.Equal(t, []string{"a,b"}, result.MatcherNames(), "could not get and matcher names")
+}
+
+func TestStoredValues(t *testing.T) {
+	match := func(data map[string]interface{}, matcher *matchers.Matcher) bool { return true }
+	extract := func(data map[string]interface{}, extractor *extractors.Extractor) map[string]struct{} {
+		return map[string]struct{}{"value": {}}
+	}
+
+	operators := &Operators{Extractors: []*extractors.Extractor{
+		{Name: "token", Store: true},
+		{Name: "session", Internal: true, Store: true},
+		{Name: "version"},
+	}}
+	result, ok := operators.Execute(nil, match, extract)
+	require.True(t, ok, "could not execute operators")
+	require.Equal(t, map[string]interface{}{"token": "value", "session": "value"}, result.StoredValues, "could not get stored values")
+}
+
+func TestRandomValuesOperators(t *testing.T) {
+	operators := &Operators{
+		Matchers:   []*matchers.Matcher{{Type: "regex", Regex: []string{`id={{randstr}}-[0-9]+`}}},
+		Extractors: []*extractors.Extractor{{Name: "suffix", Type: "regex", Regex: []string{`{{randstr}}-([0-9]+)`}, RegexGroup: 1}},
+	}
+	err := operators.Compile()
+	require.Nil(t, err, "could not compile operators")
+
+	match := func(data map[string]interface{}, matcher *matchers.Matcher) bool {
+		return matcher.MatchRegex(data["body"].(string))
+	}
+	extract := func(data map[string]interface{}, extractor *extractors.Extractor) map[string]struct{} {
+		return extractor.ExtractRegex(data["body"].(string))
+	}
+
+	data := map[string]interface{}{"randstr": "1vHSkLxOGHb2xMs4wEWJuzBzKHs", "body": "id=1vHSkLxOGHb2xMs4wEWJuzBzKHs-42"}
+	result, ok := operators.Execute(data, match, extract)
+	require.True(t, ok, "could not execute operators")
+	require.True(t, result.Matched, "could not match random value with regex")
+	require.Equal(t, []string{"42"}, result.Extracts["suffix"], "could not extract with random value")
+
+	data["body"] = "id=1vHSkLxOGHb2xMs4wEWJuzBzKHt"
+	_, ok = operators.Execute(data, match, extract)
+	require.False(t, ok, "could match other random value with regex")
+}
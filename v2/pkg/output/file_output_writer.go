@@ -10,8 +10,12 @@ type fileWriter struct {
 }
 
 // NewFileOutputWriter creates a new buffered writer for a file
-func newFileOutputWriter(file string) (*fileWriter, error) {
-	output, err := os.Create(file)
+func newFileOutputWriter(file string, appendFile bool) (*fileWriter, error) {
+	flags := os.O_CREATE | os.O_WRONLY | os.O_TRUNC
+	if appendFile {
+		flags = os.O_CREATE | os.O_WRONLY | os.O_APPEND
+	}
+	output, err := os.OpenFile(file, flags, 0666)
 	if err != nil {
 		return nil, err
 	}
@@ -33,8 +33,29 @@ func (w *StandardWriter) formatScreen(output *ResultEvent) []byte {
 		builder.WriteString("[")
 		builder.WriteString(w.severityColors.Data[types.ToString(output.Info["severity"])])
 		builder.WriteString("] ")
+
+		if confidence, ok := output.Info["confidence"]; ok {
+			builder.WriteString("[")
+			builder.WriteString(w.aurora.BrightYellow("confidence:" + types.ToString(confidence)).String())
+			builder.WriteString("] ")
+		}
+		if output.WorkflowID != "" {
+			builder.WriteString("[")
+			builder.WriteString(w.aurora.BrightBlue("workflow:" + output.WorkflowID).String())
+			builder.WriteString("] ")
+		}
 	}
 	builder.WriteString(output.Matched)
+	if output.Reproduced != "" {
+		builder.WriteString(" [")
+		builder.WriteString(w.aurora.BrightMagenta("reproduced:" + output.Reproduced).String())
+		builder.WriteString("]")
+	}
+	if output.Fingerprint != "" {
+		builder.WriteString(" [")
+		builder.WriteString(w.aurora.BrightBlue("fingerprint:" + ShortFingerprint(output.Fingerprint)).String())
+		builder.WriteString("]")
+	}
 
 	// If any extractors, write the results
 	if len(output.ExtractedResults) > 0 {
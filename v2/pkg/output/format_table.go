@@ -0,0 +1,101 @@
+package output
+
+import (
+	"bytes"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/projectdiscovery/nuclei/v2/pkg/types"
+)
+
+// Widths of the fixed size columns of the table output
+const (
+	severityColumnWidth = 8
+	typeColumnWidth     = 8
+)
+
+// formatTable formats the output as a row of aligned columns, writing
+// the header of the table before the first row.
+func (w *StandardWriter) formatTable(output *ResultEvent) []byte {
+	builder := &bytes.Buffer{}
+
+	w.tableHeader.Do(func() {
+		header := &bytes.Buffer{}
+		writeColumn(header, "SEVERITY", severityColumnWidth)
+		writeColumn(header, "TEMPLATE", w.tableWidth)
+		writeColumn(header, "TYPE", typeColumnWidth)
+		writeColumn(header, "MATCHED", 2*w.tableWidth)
+		header.WriteString("EXTRA")
+		builder.WriteString(w.aurora.Bold(strings.TrimRight(header.String(), " ")).String())
+		builder.WriteRune('\n')
+	})
+
+	severity := types.ToString(output.Info["severity"])
+	builder.WriteString(w.severityColors.Data[severity])
+	builder.WriteString(strings.Repeat(" ", padding(severity, severityColumnWidth)))
+
+	template := truncate(templateName(output), w.tableWidth)
+	builder.WriteString(w.aurora.BrightGreen(template).String())
+	builder.WriteString(strings.Repeat(" ", padding(template, w.tableWidth)))
+
+	writeColumn(builder, output.Type, typeColumnWidth)
+
+	matched := truncate(output.Matched, 2*w.tableWidth)
+	builder.WriteString(matched)
+
+	var extra []string
+	extra = append(extra, output.ExtractedResults...)
+	if !w.noMetadata {
+		for name, value := range output.Metadata {
+			extra = append(extra, name+"="+types.ToString(value))
+		}
+	}
+	if output.Reproduced != "" {
+		extra = append(extra, "reproduced:"+output.Reproduced)
+	}
+	if output.Fingerprint != "" {
+		extra = append(extra, "fingerprint:"+ShortFingerprint(output.Fingerprint))
+	}
+	if len(extra) > 0 {
+		builder.WriteString(strings.Repeat(" ", padding(matched, 2*w.tableWidth)))
+		builder.WriteString(w.aurora.BrightCyan(truncate(strings.Join(extra, ","), w.tableWidth)).String())
+	}
+	return builder.Bytes()
+}
+
+// templateName returns the template id of the result with the matcher or extractor name
+func templateName(output *ResultEvent) string {
+	if output.MatcherName != "" {
+		return output.TemplateID + ":" + output.MatcherName
+	}
+	if output.ExtractorName != "" {
+		return output.TemplateID + ":" + output.ExtractorName
+	}
+	return output.TemplateID
+}
+
+// writeColumn writes a value truncated and padded to the width of the column
+func writeColumn(builder *bytes.Buffer, value string, width int) {
+	value = truncate(value, width)
+	builder.WriteString(value)
+	builder.WriteString(strings.Repeat(" ", padding(value, width)))
+}
+
+// truncate truncates a value to a width, ending it with dots if truncated
+func truncate(value string, width int) string {
+	if utf8.RuneCountInString(value) <= width {
+		return value
+	}
+	if width <= 3 {
+		return string([]rune(value)[:width])
+	}
+	return string([]rune(value)[:width-3]) + "..."
+}
+
+// padding returns the number of spaces separating a value from the next column
+func padding(value string, width int) int {
+	if count := utf8.RuneCountInString(value); count < width {
+		return width - count + 1
+	}
+	return 1
+}
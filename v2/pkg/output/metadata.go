@@ -0,0 +1,49 @@
+package output
+
+import (
+	"io/ioutil"
+	"time"
+
+	jsoniter "github.com/json-iterator/go"
+	"github.com/pkg/errors"
+)
+
+// ScanMetadata describes the scan the results were found in for
+// auditing and reproducing the scan.
+type ScanMetadata struct {
+	// NucleiVersion is the version of nuclei running the scan
+	NucleiVersion string `json:"nuclei_version"`
+	// TemplatesVersion is the version of the installed nuclei-templates
+	TemplatesVersion string `json:"templates_version,omitempty"`
+	// Arguments are the command line arguments of the scan with secrets redacted
+	Arguments []string `json:"arguments"`
+	// Templates is the number of templates loaded for the scan
+	Templates int `json:"templates"`
+	// Targets is the number of targets of the scan
+	Targets int64 `json:"targets"`
+	// StartTime is the time the scan started at
+	StartTime time.Time `json:"start_time"`
+	// EndTime is the time the scan finished at, nil while the scan runs
+	EndTime *time.Time `json:"end_time,omitempty"`
+}
+
+// Finish sets the end time of the scan if it is not set yet
+func (m *ScanMetadata) Finish() {
+	if m.EndTime == nil {
+		now := time.Now()
+		m.EndTime = &now
+	}
+}
+
+// WriteFile writes the scan metadata as json to a file, replacing the
+// metadata written previously.
+func (m *ScanMetadata) WriteFile(file string) error {
+	data, err := jsoniter.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return errors.Wrap(err, "could not marshal scan metadata")
+	}
+	if err := ioutil.WriteFile(file, data, 0644); err != nil {
+		return errors.Wrap(err, "could not write scan metadata")
+	}
+	return nil
+}
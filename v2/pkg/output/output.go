@@ -1,14 +1,18 @@
 package output
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"os"
 	"regexp"
+	"strings"
 	"sync"
 	"time"
 
 	jsoniter "github.com/json-iterator/go"
 	"github.com/logrusorgru/aurora"
 	"github.com/pkg/errors"
+	"github.com/projectdiscovery/gologger"
 	"github.com/projectdiscovery/interactsh/pkg/server"
 	"github.com/projectdiscovery/nuclei/v2/internal/colorizer"
 	"github.com/projectdiscovery/nuclei/v2/pkg/operators"
@@ -36,6 +40,12 @@ type StandardWriter struct {
 	traceFile      *fileWriter
 	traceMutex     *sync.Mutex
 	severityColors *colorizer.Colorizer
+	buffer         *spillBuffer
+	bufferDone     chan struct{}
+	table          bool
+	tableWidth     int
+	tableHeader    sync.Once
+	noScreen       bool
 }
 
 var decolorizerRegex = regexp.MustCompile(`\x1B\[[0-9;]*[a-zA-Z]`)
@@ -48,6 +58,8 @@ type InternalWrappedEvent struct {
 	InternalEvent   InternalEvent
 	Results         []*ResultEvent
 	OperatorsResult *operators.Result
+	// Started is the time the request of the event was started at.
+	Started time.Time
 }
 
 // ResultEvent is a wrapped result event for a single nuclei output.
@@ -84,17 +96,44 @@ type ResultEvent struct {
 	Timestamp time.Time `json:"timestamp"`
 	// Interaction is the full details of interactsh interaction.
 	Interaction *server.Interaction `json:"interaction,omitempty"`
+	// ResponseHash is the SHA-256 of the raw matched response.
+	ResponseHash string `json:"response_hash,omitempty"`
+	// Fingerprint identifies the finding across scans.
+	Fingerprint string `json:"fingerprint,omitempty"`
+	// StoredResponse is the path of the stored response file if any.
+	StoredResponse string `json:"stored_response,omitempty"`
+	// Screenshot is the path of the stored screenshot of the match if any.
+	Screenshot string `json:"screenshot,omitempty"`
+	// AffectedHosts are the hosts of the findings of the template grouped in the result.
+	AffectedHosts []string `json:"affected_hosts,omitempty"`
+	// Reproduced is the number of verification runs the result was reproduced in (ex. 2/3).
+	Reproduced string `json:"reproduced,omitempty"`
+	// EPSSScore is the EPSS score of the CVE of the template if enriched.
+	EPSSScore float64 `json:"epss_score,omitempty"`
+	// EPSSPercentile is the EPSS percentile of the CVE of the template if enriched.
+	EPSSPercentile float64 `json:"epss_percentile,omitempty"`
+	// KEV is true if the CVE of the template is in the CISA KEV catalog.
+	KEV bool `json:"kev,omitempty"`
+	// WorkflowID is the id of the workflow that ran the template of the result.
+	WorkflowID string `json:"workflow_id,omitempty"`
+	// WorkflowInfo contains the information block of the workflow of the result.
+	WorkflowInfo map[string]interface{} `json:"workflow_info,omitempty"`
 
 	FileToIndexPosition map[string]int `json:"-"`
 }
 
-// NewStandardWriter creates a new output writer based on user configurations
-func NewStandardWriter(colors, noMetadata, json bool, file, traceFile string) (*StandardWriter, error) {
+// NewStandardWriter creates a new output writer based on user configurations.
+//
+// If appendFiles is true, the output and trace files are appended to instead of truncated.
+// If bufferSize is greater than zero, findings are written asynchronously from
+// a buffer holding at most bufferSize bytes in memory, spilling to disk after.
+// If table is true, findings are written as aligned columns truncated at tableWidth.
+func NewStandardWriter(colors, noMetadata, json bool, file, traceFile string, appendFiles bool, bufferSize int, table bool, tableWidth int) (*StandardWriter, error) {
 	auroraColorizer := aurora.NewAurora(colors)
 
 	var outputFile *fileWriter
 	if file != "" {
-		output, err := newFileOutputWriter(file)
+		output, err := newFileOutputWriter(file, appendFiles)
 		if err != nil {
 			return nil, errors.Wrap(err, "could not create output file")
 		}
@@ -102,7 +141,7 @@ func NewStandardWriter(colors, noMetadata, json bool, file, traceFile string) (*
 	}
 	var traceOutput *fileWriter
 	if traceFile != "" {
-		output, err := newFileOutputWriter(traceFile)
+		output, err := newFileOutputWriter(traceFile, appendFiles)
 		if err != nil {
 			return nil, errors.Wrap(err, "could not create output file")
 		}
@@ -117,13 +156,40 @@ func NewStandardWriter(colors, noMetadata, json bool, file, traceFile string) (*
 		traceFile:      traceOutput,
 		traceMutex:     &sync.Mutex{},
 		severityColors: colorizer.New(auroraColorizer),
+		table:          table,
+		tableWidth:     tableWidth,
+	}
+	if bufferSize > 0 {
+		writer.buffer = newSpillBuffer(bufferSize)
+		writer.bufferDone = make(chan struct{})
+		go writer.drainBuffer()
 	}
 	return writer, nil
 }
 
+// drainBuffer writes the buffered findings until the buffer is closed
+func (w *StandardWriter) drainBuffer() {
+	defer close(w.bufferDone)
+
+	for {
+		data, ok, err := w.buffer.Pop()
+		if !ok {
+			return
+		}
+		if err != nil {
+			gologger.Warning().Msgf("Could not read buffered finding: %s\n", err)
+			continue
+		}
+		_ = w.writeData(data)
+	}
+}
+
 // Write writes the event to file and/or screen.
 func (w *StandardWriter) Write(event *ResultEvent) error {
 	event.Timestamp = time.Now()
+	if event.Fingerprint == "" {
+		event.Fingerprint = Fingerprint(event)
+	}
 
 	var data []byte
 	var err error
@@ -139,14 +205,44 @@ func (w *StandardWriter) Write(event *ResultEvent) error {
 	if len(data) == 0 {
 		return nil
 	}
-	_, _ = os.Stdout.Write(data)
-	_, _ = os.Stdout.Write([]byte("\n"))
+	// The table is only written on screen as its columns are truncated
+	if w.screenTable() && !w.noScreen {
+		w.outputMutex.Lock()
+		_, _ = os.Stdout.Write(w.formatTable(event))
+		_, _ = os.Stdout.Write([]byte("\n"))
+		w.outputMutex.Unlock()
+	}
+	if w.buffer != nil {
+		return w.buffer.Push(data)
+	}
+	return w.writeData(data)
+}
+
+// screenTable returns true if the findings are written on screen as a table
+func (w *StandardWriter) screenTable() bool {
+	return w.table && !w.json
+}
+
+// DisableScreen stops writing the results on screen, only writing them to the output file
+func (w *StandardWriter) DisableScreen() {
+	w.noScreen = true
+}
+
+// writeData writes formatted event data to screen and output file
+func (w *StandardWriter) writeData(data []byte) error {
+	w.outputMutex.Lock()
+	defer w.outputMutex.Unlock()
+
+	if !w.noScreen && !w.screenTable() {
+		_, _ = os.Stdout.Write(data)
+		_, _ = os.Stdout.Write([]byte("\n"))
+	}
 	if w.outputFile != nil {
 		if !w.json {
 			data = decolorizerRegex.ReplaceAll(data, []byte(""))
 		}
 		if writeErr := w.outputFile.Write(data); writeErr != nil {
-			return errors.Wrap(err, "could not write to output")
+			return errors.Wrap(writeErr, "could not write to output")
 		}
 	}
 	return nil
@@ -192,6 +288,11 @@ func (w *StandardWriter) Colorizer() aurora.Aurora {
 
 // Close closes the output writing interface
 func (w *StandardWriter) Close() {
+	if w.buffer != nil {
+		w.buffer.Close()
+		<-w.bufferDone
+		w.buffer.Cleanup()
+	}
 	if w.outputFile != nil {
 		w.outputFile.Close()
 	}
@@ -199,3 +300,35 @@ func (w *StandardWriter) Close() {
 		w.traceFile.Close()
 	}
 }
+
+// ResponseHash returns the hex encoded SHA-256 of a response for
+// verifying the integrity of the evidence later.
+func ResponseHash(response string) string {
+	if response == "" {
+		return ""
+	}
+	hash := sha256.Sum256([]byte(response))
+	return hex.EncodeToString(hash[:])
+}
+
+// Fingerprint returns the hex encoded SHA-256 of the template, host,
+// matcher and matched location of a result. It is stable across scans
+// for tracking the lifecycle of a finding.
+func Fingerprint(event *ResultEvent) string {
+	name := event.MatcherName
+	if name == "" {
+		name = event.ExtractorName
+	}
+	hash := sha256.Sum256([]byte(strings.Join([]string{event.TemplateID, event.Host, name, event.Matched}, "\x00")))
+	return hex.EncodeToString(hash[:])
+}
+
+// ShortFingerprint returns the prefix of the fingerprint shown on screen
+func ShortFingerprint(fingerprint string) string {
+	if len(fingerprint) > shortFingerprintLength {
+		return fingerprint[:shortFingerprintLength]
+	}
+	return fingerprint
+}
+
+const shortFingerprintLength = 12
@@ -0,0 +1,97 @@
+package output
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestResponseHash(t *testing.T) {
+	require.Equal(t, "", ResponseHash(""), "could get hash of empty response")
+	require.Equal(t, "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824", ResponseHash("hello"), "could not get sha256 of response")
+}
+
+func TestTableColumns(t *testing.T) {
+	require.Equal(t, "https://...", truncate("https://example.com/admin", 11), "could not truncate value")
+	require.Equal(t, "http", truncate("http", 8), "could truncate short value")
+	require.Equal(t, 5, padding("http", 8), "could not get padding of short value")
+	require.Equal(t, 1, padding("https://...", 11), "could not get padding of truncated value")
+}
+
+func TestFingerprint(t *testing.T) {
+	event := &ResultEvent{TemplateID: "git-config", Host: "https://example.com", MatcherName: "config", Matched: "https://example.com/.git/config"}
+	fingerprint := Fingerprint(event)
+	require.Len(t, fingerprint, 64, "could not get sha256 fingerprint")
+
+	rescanned := *event
+	rescanned.IP = "127.0.0.1"
+	rescanned.Response = "HTTP/1.1 200 OK"
+	require.Equal(t, fingerprint, Fingerprint(&rescanned), "could not get stable fingerprint")
+
+	other := *event
+	other.Matched = "https://example.com/app/.git/config"
+	require.NotEqual(t, fingerprint, Fingerprint(&other), "could get same fingerprint for other location")
+	require.Equal(t, fingerprint[:12], ShortFingerprint(fingerprint), "could not get short fingerprint")
+}
+
+func TestScanMetadataWriteFile(t *testing.T) {
+	directory, err := ioutil.TempDir("", "nuclei-scan-metadata-*")
+	require.Nil(t, err, "could not create temporary directory")
+	defer os.RemoveAll(directory)
+
+	file := filepath.Join(directory, "metadata.json")
+	metadata := &ScanMetadata{NucleiVersion: "2.3.0", Arguments: []string{"-json"}, Templates: 2, Targets: 1}
+	require.Nil(t, metadata.WriteFile(file), "could not write scan metadata")
+	metadata.Finish()
+	require.Nil(t, metadata.WriteFile(file), "could not rewrite scan metadata")
+
+	data, err := ioutil.ReadFile(file)
+	require.Nil(t, err, "could not read scan metadata")
+	written := &ScanMetadata{}
+	require.Nil(t, json.Unmarshal(data, written), "could not parse scan metadata")
+	require.Equal(t, "2.3.0", written.NucleiVersion, "could not write nuclei version")
+	require.NotNil(t, written.EndTime, "could not write end time")
+}
+
+func TestStandardWriterAppend(t *testing.T) {
+	directory, err := ioutil.TempDir("", "nuclei-output-*")
+	require.Nil(t, err, "could not create temporary directory")
+	defer os.RemoveAll(directory)
+
+	file := filepath.Join(directory, "results.txt")
+	require.Nil(t, ioutil.WriteFile(file, []byte("previous\n"), 0644), "could not write previous results")
+
+	writer, err := NewStandardWriter(false, true, false, file, "", true, 0, false, 0)
+	require.Nil(t, err, "could not create writer")
+	require.Nil(t, writer.Write(&ResultEvent{TemplateID: "panel", Host: "https://example.com", Matched: "https://example.com", Info: map[string]interface{}{}}), "could not write result")
+	writer.Close()
+
+	data, err := ioutil.ReadFile(file)
+	require.Nil(t, err, "could not read results")
+	require.Contains(t, string(data), "previous\n", "could not keep previous results")
+	require.Contains(t, string(data), "https://example.com", "could not append result")
+}
+
+func TestStandardWriterTableFile(t *testing.T) {
+	directory, err := ioutil.TempDir("", "nuclei-output-*")
+	require.Nil(t, err, "could not create temporary directory")
+	defer os.RemoveAll(directory)
+
+	file := filepath.Join(directory, "results.txt")
+	writer, err := NewStandardWriter(false, true, false, file, "", false, 0, true, 10)
+	require.Nil(t, err, "could not create writer")
+	writer.DisableScreen()
+
+	matched := "https://example.com/a/very/long/path/to/the/admin/panel"
+	require.Nil(t, writer.Write(&ResultEvent{TemplateID: "panel", Host: "https://example.com", Matched: matched, Info: map[string]interface{}{}}), "could not write result")
+	writer.Close()
+
+	data, err := ioutil.ReadFile(file)
+	require.Nil(t, err, "could not read results")
+	require.Contains(t, string(data), matched, "could not write untruncated result to file")
+	require.NotContains(t, string(data), "SEVERITY", "could write table to file")
+}
@@ -0,0 +1,139 @@
+package output
+
+import (
+	"encoding/binary"
+	"io/ioutil"
+	"os"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// spillBuffer is a FIFO queue of formatted output entries bounded by a
+// memory budget. Entries pushed while the budget is exhausted are spilled
+// to a temporary file and read back in order once memory entries are consumed.
+type spillBuffer struct {
+	mutex   sync.Mutex
+	cond    *sync.Cond
+	maxSize int
+	size    int
+	items   [][]byte
+	closed  bool
+
+	spill       *os.File
+	spilled     int
+	readOffset  int64
+	writeOffset int64
+}
+
+// spillHeaderSize is the size of the length prefix of a spilled entry
+const spillHeaderSize = 4
+
+// newSpillBuffer creates a new spill buffer with a memory budget of maxSize bytes
+func newSpillBuffer(maxSize int) *spillBuffer {
+	buffer := &spillBuffer{maxSize: maxSize}
+	buffer.cond = sync.NewCond(&buffer.mutex)
+	return buffer
+}
+
+// Push adds an entry to the buffer spilling it to disk if the memory budget is exhausted.
+func (b *spillBuffer) Push(data []byte) error {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	// Entries go to disk while spilled entries are pending to preserve ordering
+	if b.spilled == 0 && (len(b.items) == 0 || b.size+len(data) <= b.maxSize) {
+		b.items = append(b.items, data)
+		b.size += len(data)
+		b.cond.Signal()
+		return nil
+	}
+	if b.spill == nil {
+		file, err := ioutil.TempFile("", "nuclei-output-*")
+		if err != nil {
+			return errors.Wrap(err, "could not create output spill file")
+		}
+		b.spill = file
+	}
+	entry := make([]byte, spillHeaderSize+len(data))
+	binary.BigEndian.PutUint32(entry, uint32(len(data)))
+	copy(entry[spillHeaderSize:], data)
+	if _, err := b.spill.WriteAt(entry, b.writeOffset); err != nil {
+		return errors.Wrap(err, "could not write to output spill file")
+	}
+	b.writeOffset += int64(len(entry))
+	b.spilled++
+	b.cond.Signal()
+	return nil
+}
+
+// Pop returns the next entry from the buffer blocking until one is available.
+// False is returned if the buffer was closed and no entries are left. The
+// entries which can't be read back from the spill file are dropped and
+// returned as an error, the next entries can still be popped.
+func (b *spillBuffer) Pop() ([]byte, bool, error) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	for len(b.items) == 0 && b.spilled == 0 && !b.closed {
+		b.cond.Wait()
+	}
+	if len(b.items) > 0 {
+		data := b.items[0]
+		b.items[0] = nil
+		b.items = b.items[1:]
+		b.size -= len(data)
+		return data, true, nil
+	}
+	if b.spilled == 0 {
+		return nil, false, nil
+	}
+
+	header := make([]byte, spillHeaderSize)
+	if _, err := b.spill.ReadAt(header, b.readOffset); err != nil {
+		// The size of the next entries is unknown, the spilled entries are dropped
+		dropped := b.spilled
+		b.resetSpill()
+		return nil, true, errors.Wrapf(err, "could not read from output spill file, dropped %d entries", dropped)
+	}
+	data := make([]byte, binary.BigEndian.Uint32(header))
+	_, err := b.spill.ReadAt(data, b.readOffset+spillHeaderSize)
+	b.readOffset += int64(spillHeaderSize + len(data))
+	b.spilled--
+
+	// Reuse the spill file from start once all the spilled entries are consumed.
+	if b.spilled == 0 {
+		b.resetSpill()
+	}
+	if err != nil {
+		return nil, true, errors.Wrap(err, "could not read from output spill file, dropped 1 entry")
+	}
+	return data, true, nil
+}
+
+// resetSpill empties the spill file to reuse it from start
+func (b *spillBuffer) resetSpill() {
+	_ = b.spill.Truncate(0)
+	b.spilled = 0
+	b.readOffset, b.writeOffset = 0, 0
+}
+
+// Close marks the buffer as closed waking up any waiting consumers
+func (b *spillBuffer) Close() {
+	b.mutex.Lock()
+	b.closed = true
+	b.cond.Broadcast()
+	b.mutex.Unlock()
+}
+
+// Cleanup removes the spill file of the buffer if any was created
+func (b *spillBuffer) Cleanup() {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if b.spill != nil {
+		_ = b.spill.Close()
+		_ = os.Remove(b.spill.Name())
+		b.spill = nil
+	}
+}
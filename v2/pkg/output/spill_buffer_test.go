@@ -0,0 +1,62 @@
+package output
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSpillBuffer(t *testing.T) {
+	buffer := newSpillBuffer(16)
+	defer buffer.Cleanup()
+
+	for i := 0; i < 10; i++ {
+		err := buffer.Push([]byte(fmt.Sprintf("finding-%d", i)))
+		require.Nil(t, err, "could not push entry")
+	}
+	require.NotNil(t, buffer.spill, "could not spill entries over budget")
+	require.LessOrEqual(t, buffer.size, 16, "could not keep memory budget")
+
+	for i := 0; i < 10; i++ {
+		data, ok, err := buffer.Pop()
+		require.Nil(t, err, "could not pop entry")
+		require.True(t, ok, "could not get entry")
+		require.Equal(t, fmt.Sprintf("finding-%d", i), string(data), "could not preserve entry order")
+	}
+
+	require.Nil(t, buffer.Push([]byte("after-spill")), "could not push entry after spill")
+	data, ok, err := buffer.Pop()
+	require.Nil(t, err, "could not pop entry after spill")
+	require.True(t, ok, "could not get entry after spill")
+	require.Equal(t, "after-spill", string(data), "could not get correct entry after spill")
+
+	buffer.Close()
+	_, ok, _ = buffer.Pop()
+	require.False(t, ok, "could get entry from closed empty buffer")
+}
+
+func TestSpillBufferReadError(t *testing.T) {
+	buffer := newSpillBuffer(16)
+	defer buffer.Cleanup()
+
+	for i := 0; i < 3; i++ {
+		require.Nil(t, buffer.Push([]byte(fmt.Sprintf("finding-%d", i))), "could not push entry")
+	}
+	// The spilled entries can't be read back once the file is closed
+	require.Nil(t, buffer.spill.Close(), "could not close spill file")
+
+	data, ok, err := buffer.Pop()
+	require.Nil(t, err, "could not pop memory entry")
+	require.Equal(t, "finding-0", string(data), "could not get memory entry")
+
+	_, ok, err = buffer.Pop()
+	require.NotNil(t, err, "could pop unreadable spilled entry")
+	require.True(t, ok, "could not continue after unreadable spilled entry")
+
+	require.Nil(t, buffer.Push([]byte("finding-3")), "could not push entry after read error")
+	data, ok, err = buffer.Pop()
+	require.Nil(t, err, "could not pop entry after read error")
+	require.True(t, ok, "could not get entry after read error")
+	require.Equal(t, "finding-3", string(data), "could not get entry after read error")
+}
@@ -0,0 +1,82 @@
+package output
+
+import (
+	"strings"
+
+	"github.com/projectdiscovery/nuclei/v2/pkg/types"
+)
+
+// Workflow is a workflow whose info is inherited by the findings of its templates.
+type Workflow struct {
+	id   string
+	info map[string]interface{}
+}
+
+// NewWorkflow creates a new workflow attaching its info to the findings
+func NewWorkflow(id string, info map[string]interface{}) *Workflow {
+	return &Workflow{id: id, info: info}
+}
+
+// Apply attaches the workflow to a finding not attached to a nested workflow.
+// The severity of the workflow overrides the severity of the template, its tags
+// are added to the template tags and its metadata fills the missing metadata.
+func (w *Workflow) Apply(event *ResultEvent) {
+	if w == nil || event.WorkflowID != "" {
+		return
+	}
+	event.WorkflowID = w.id
+	event.WorkflowInfo = w.info
+
+	info := make(map[string]interface{}, len(event.Info))
+	for k, v := range event.Info {
+		info[k] = v
+	}
+	if severity := types.ToString(w.info["severity"]); severity != "" {
+		info["severity"] = severity
+	}
+	if workflowTags := splitTags(w.info["tags"]); len(workflowTags) > 0 {
+		tags := splitTags(info["tags"])
+		for _, tag := range workflowTags {
+			if !contains(tags, tag) {
+				tags = append(tags, tag)
+			}
+		}
+		info["tags"] = strings.Join(tags, ",")
+	}
+	if workflowMetadata := types.ToStringMap(w.info["metadata"]); len(workflowMetadata) > 0 {
+		metadata := make(map[string]interface{}, len(workflowMetadata))
+		for k, v := range workflowMetadata {
+			metadata[k] = v
+		}
+		for k, v := range types.ToStringMap(info["metadata"]) {
+			metadata[k] = v
+		}
+		info["metadata"] = metadata
+	}
+	event.Info = info
+}
+
+// splitTags returns the tags of an info block declared as a comma
+// separated string or a list.
+func splitTags(value interface{}) []string {
+	var tags []string
+	values := types.ToStringSlice(value)
+	if tagString, ok := value.(string); ok {
+		values = strings.Split(tagString, ",")
+	}
+	for _, tag := range values {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
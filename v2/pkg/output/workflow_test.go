@@ -0,0 +1,40 @@
+package output
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWorkflowApply(t *testing.T) {
+	workflowInfo := map[string]interface{}{
+		"name":     "WordPress workflow",
+		"severity": "high",
+		"tags":     "wordpress,cms",
+		"metadata": map[string]interface{}{"team": "appsec", "owner": "workflow"},
+	}
+	templateInfo := map[string]interface{}{
+		"name":     "WordPress plugin",
+		"severity": "medium",
+		"tags":     "wordpress,plugin",
+		"metadata": map[string]interface{}{"owner": "template"},
+	}
+	event := &ResultEvent{TemplateID: "wp-plugin", Info: templateInfo}
+	NewWorkflow("wordpress-workflow", workflowInfo).Apply(event)
+
+	require.Equal(t, "wordpress-workflow", event.WorkflowID, "could not attach workflow")
+	require.Equal(t, "high", event.Info["severity"], "could not inherit severity")
+	require.Equal(t, "wordpress,plugin,cms", event.Info["tags"], "could not inherit tags")
+	require.Equal(t, map[string]interface{}{"team": "appsec", "owner": "template"}, event.Info["metadata"], "could not inherit metadata")
+	require.Equal(t, "WordPress plugin", event.Info["name"], "could inherit name")
+	require.Equal(t, "medium", templateInfo["severity"], "could modify template info")
+
+	nested := &ResultEvent{Info: templateInfo, WorkflowID: "inner"}
+	NewWorkflow("outer-workflow", map[string]interface{}{"severity": "critical"}).Apply(nested)
+	require.Equal(t, "inner", nested.WorkflowID, "could override nested workflow")
+
+	var noWorkflow *Workflow
+	standalone := &ResultEvent{Info: templateInfo}
+	noWorkflow.Apply(standalone)
+	require.Empty(t, standalone.WorkflowID, "could attach missing workflow")
+}
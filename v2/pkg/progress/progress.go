@@ -32,6 +32,9 @@ type Progress interface {
 	// IncrementFailedRequestsBy increments the number of requests counter by count
 	// along with errors.
 	IncrementFailedRequestsBy(count int64)
+	// IncrementSkippedBy increments the skipped counter by count, marking
+	// the requests as completed.
+	IncrementSkippedBy(count int64)
 }
 
 var _ Progress = &StatsTicker{}
@@ -89,6 +92,7 @@ func (p *StatsTicker) Init(hostCount int64, rulesCount int, requestCount int64)
 	p.stats.AddCounter("requests", uint64(0))
 	p.stats.AddCounter("errors", uint64(0))
 	p.stats.AddCounter("matched", uint64(0))
+	p.stats.AddCounter("skipped", uint64(0))
 	p.stats.AddCounter("total", uint64(requestCount))
 
 	if p.active {
@@ -125,6 +129,12 @@ func (p *StatsTicker) IncrementFailedRequestsBy(count int64) {
 	p.stats.IncrementCounter("errors", int(count))
 }
 
+// IncrementSkippedBy increments the skipped counter by count, marking the requests as completed.
+func (p *StatsTicker) IncrementSkippedBy(count int64) {
+	p.stats.IncrementCounter("requests", int(count))
+	p.stats.IncrementCounter("skipped", int(count))
+}
+
 func printCallback(stats clistats.StatisticsClient) {
 	builder := &strings.Builder{}
 	builder.WriteRune('[')
@@ -155,6 +165,11 @@ func printCallback(stats clistats.StatisticsClient) {
 	builder.WriteString(" | Errors: ")
 	builder.WriteString(clistats.String(errors))
 
+	if skipped, _ := stats.GetCounter("skipped"); skipped > 0 {
+		builder.WriteString(" | Skipped: ")
+		builder.WriteString(clistats.String(skipped))
+	}
+
 	builder.WriteString(" | Requests: ")
 	builder.WriteString(clistats.String(requests))
 	builder.WriteRune('/')
@@ -192,6 +207,8 @@ func (p *StatsTicker) getMetrics() map[string]interface{} {
 	results["rps"] = clistats.String(uint64(float64(requests) / duration.Seconds()))
 	errors, _ := p.stats.GetCounter("errors")
 	results["errors"] = clistats.String(errors)
+	skipped, _ := p.stats.GetCounter("skipped")
+	results["skipped"] = clistats.String(skipped)
 
 	//nolint:gomnd // this is not a magic number
 	percentData := (float64(requests) * float64(100)) / float64(total)
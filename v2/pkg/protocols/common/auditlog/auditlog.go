@@ -0,0 +1,126 @@
+// Package auditlog records every outbound request of the scan to a
+// JSONL file, providing a complete manifest of the traffic sent.
+package auditlog
+
+import (
+	"bufio"
+	"net"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	jsoniter "github.com/json-iterator/go"
+	"github.com/pkg/errors"
+)
+
+// Event is an outbound request recorded in the audit log
+type Event struct {
+	Timestamp     time.Time `json:"timestamp"`
+	TemplateID    string    `json:"template_id"`
+	Protocol      string    `json:"protocol"`
+	Target        string    `json:"target"`
+	BytesSent     int       `json:"bytes_sent"`
+	BytesReceived int       `json:"bytes_received"`
+	Error         string    `json:"error,omitempty"`
+}
+
+// Logger writes the audit events to a file
+type Logger struct {
+	mutex  sync.Mutex
+	file   *os.File
+	writer *bufio.Writer
+}
+
+// New creates a new audit logger appending to a file
+func New(file string) (*Logger, error) {
+	output, err := os.OpenFile(file, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not open audit log")
+	}
+	return &Logger{file: output, writer: bufio.NewWriter(output)}, nil
+}
+
+// Log records an outbound request of a template to a target with the number
+// of bytes sent and received, and the error of the request if any.
+func (l *Logger) Log(templateID, protocol, target string, sent, received int, err error) {
+	if l == nil {
+		return
+	}
+	event := &Event{
+		Timestamp:     time.Now(),
+		TemplateID:    templateID,
+		Protocol:      protocol,
+		Target:        target,
+		BytesSent:     sent,
+		BytesReceived: received,
+	}
+	if err != nil {
+		event.Error = err.Error()
+	}
+	data, err := jsoniter.Marshal(event)
+	if err != nil {
+		return
+	}
+
+	// The events are flushed as they are written so the log is complete
+	// even if the scan is interrupted.
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	_, _ = l.writer.Write(data)
+	_, _ = l.writer.WriteString("\n")
+	_ = l.writer.Flush()
+}
+
+// Close flushes and closes the audit log
+func (l *Logger) Close() error {
+	if l == nil {
+		return nil
+	}
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if err := l.writer.Flush(); err != nil {
+		l.file.Close()
+		return errors.Wrap(err, "could not flush audit log")
+	}
+	return l.file.Close()
+}
+
+// Counter counts the bytes sent and received on connections
+type Counter struct {
+	sent     int64
+	received int64
+}
+
+// Wrap returns a connection adding the bytes sent and received to the counter
+func (c *Counter) Wrap(conn net.Conn) net.Conn {
+	return &countingConn{Conn: conn, counter: c}
+}
+
+// Sent returns the number of bytes sent on the connections
+func (c *Counter) Sent() int {
+	return int(atomic.LoadInt64(&c.sent))
+}
+
+// Received returns the number of bytes received on the connections
+func (c *Counter) Received() int {
+	return int(atomic.LoadInt64(&c.received))
+}
+
+type countingConn struct {
+	net.Conn
+	counter *Counter
+}
+
+func (c *countingConn) Read(b []byte) (int, error) {
+	n, err := c.Conn.Read(b)
+	atomic.AddInt64(&c.counter.received, int64(n))
+	return n, err
+}
+
+func (c *countingConn) Write(b []byte) (int, error) {
+	n, err := c.Conn.Write(b)
+	atomic.AddInt64(&c.counter.sent, int64(n))
+	return n, err
+}
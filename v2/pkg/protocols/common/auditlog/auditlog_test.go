@@ -0,0 +1,144 @@
+package auditlog
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path"
+	"strings"
+	"testing"
+
+	jsoniter "github.com/json-iterator/go"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuditLog(t *testing.T) {
+	dir, err := ioutil.TempDir("", "nuclei-audit-*")
+	require.Nil(t, err, "could not create temporary directory")
+	defer os.RemoveAll(dir)
+
+	file := path.Join(dir, "audit.jsonl")
+	logger, err := New(file)
+	require.Nil(t, err, "could not create audit log")
+	logger.Log("tech-detect", "http", "https://example.com", 120, 4096, nil)
+	logger.Log("tech-detect", "http", "https://example.org", 120, 0, errors.New("connection refused"))
+	require.Nil(t, logger.Close(), "could not close audit log")
+
+	data, err := ioutil.ReadFile(file)
+	require.Nil(t, err, "could not read audit log")
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	require.Len(t, lines, 2, "could not write all requests")
+
+	event := &Event{}
+	require.Nil(t, jsoniter.Unmarshal([]byte(lines[0]), event), "could not unmarshal event")
+	require.Equal(t, "https://example.com", event.Target, "could not write target")
+	require.Equal(t, 4096, event.BytesReceived, "could not write bytes received")
+	require.Nil(t, jsoniter.Unmarshal([]byte(lines[1]), event), "could not unmarshal event")
+	require.Equal(t, "connection refused", event.Error, "could not write error")
+
+	var nilLogger *Logger
+	nilLogger.Log("tech-detect", "http", "https://example.com", 0, 0, nil)
+	require.Nil(t, nilLogger.Close(), "could not close nil audit log")
+}
+
+func TestCounter(t *testing.T) {
+	client, server := net.Pipe()
+	defer server.Close()
+
+	counter := &Counter{}
+	conn := counter.Wrap(client)
+	go func() {
+		buffer := make([]byte, 5)
+		_, _ = server.Read(buffer)
+		_, _ = server.Write([]byte("pong!!"))
+	}()
+	_, err := conn.Write([]byte("ping!"))
+	require.Nil(t, err, "could not write to connection")
+	buffer := make([]byte, 6)
+	_, err = conn.Read(buffer)
+	require.Nil(t, err, "could not read from connection")
+	conn.Close()
+
+	require.Equal(t, 5, counter.Sent(), "could not count bytes sent")
+	require.Equal(t, 6, counter.Received(), "could not count bytes received")
+}
+
+func TestTransport(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("response body"))
+	}))
+	defer ts.Close()
+
+	dir, err := ioutil.TempDir("", "nuclei-audit-*")
+	require.Nil(t, err, "could not create temporary directory")
+	defer os.RemoveAll(dir)
+
+	file := path.Join(dir, "audit.jsonl")
+	logger, err := New(file)
+	require.Nil(t, err, "could not create audit log")
+	defer logger.Close()
+
+	client := &http.Client{Transport: NewTransport(logger, "crawler", "http", http.DefaultTransport)}
+	resp, err := client.Get(ts.URL + "/path")
+	require.Nil(t, err, "could not send request")
+	_, _ = ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+
+	// The events are flushed without closing the logger
+	data, err := ioutil.ReadFile(file)
+	require.Nil(t, err, "could not read audit log")
+	event := &Event{}
+	require.Nil(t, jsoniter.Unmarshal(data, event), "could not unmarshal event")
+	require.Equal(t, "crawler", event.TemplateID, "could not write template id")
+	require.Equal(t, ts.URL+"/path", event.Target, "could not write target")
+	require.Greater(t, event.BytesSent, len("GET /path HTTP/1.1\r\n"), "could not count bytes sent")
+	require.Greater(t, event.BytesReceived, len("response body"), "could not count bytes received")
+
+	require.True(t, NewTransport(nil, "crawler", "http", http.DefaultTransport) == http.DefaultTransport, "could wrap transport without logger")
+}
+
+func TestContextTransport(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/redirect" {
+			http.Redirect(w, r, "/path", http.StatusFound)
+			return
+		}
+		_, _ = w.Write([]byte("response body"))
+	}))
+	defer ts.Close()
+
+	dir, err := ioutil.TempDir("", "nuclei-audit-*")
+	require.Nil(t, err, "could not create temporary directory")
+	defer os.RemoveAll(dir)
+
+	file := path.Join(dir, "audit.jsonl")
+	logger, err := New(file)
+	require.Nil(t, err, "could not create audit log")
+	defer logger.Close()
+
+	client := &http.Client{Transport: NewContextTransport("http", http.DefaultTransport)}
+	send := func(ctx context.Context) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, ts.URL+"/redirect", nil)
+		require.Nil(t, err, "could not create request")
+		resp, err := client.Do(req)
+		require.Nil(t, err, "could not send request")
+		_, _ = ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+	}
+	send(context.Background())
+	send(WithTemplate(context.Background(), logger, "redirect-template"))
+
+	// The redirect hops are recorded for the requests of the template only
+	data, err := ioutil.ReadFile(file)
+	require.Nil(t, err, "could not read audit log")
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	require.Len(t, lines, 2, "could not record redirect hops")
+	event := &Event{}
+	require.Nil(t, jsoniter.Unmarshal([]byte(lines[1]), event), "could not unmarshal event")
+	require.Equal(t, "redirect-template", event.TemplateID, "could not write template id of context")
+	require.Equal(t, ts.URL+"/path", event.Target, "could not write redirect target")
+}
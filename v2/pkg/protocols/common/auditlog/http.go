@@ -0,0 +1,145 @@
+package auditlog
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+)
+
+// transport records the requests of a round tripper in the audit log
+type transport struct {
+	logger     *Logger
+	templateID string
+	protocol   string
+	transport  http.RoundTripper
+}
+
+// NewTransport returns a round tripper recording the requests sent with the
+// transport as made by the template ID. The transport is returned as is if
+// the logger is nil.
+func NewTransport(logger *Logger, templateID, protocol string, rt http.RoundTripper) http.RoundTripper {
+	if logger == nil {
+		return rt
+	}
+	if rt == nil {
+		rt = http.DefaultTransport
+	}
+	return &transport{logger: logger, templateID: templateID, protocol: protocol, transport: rt}
+}
+
+// NewContextTransport returns a round tripper recording the requests sent
+// with a context returned by WithTemplate. It is used by the pooled clients
+// shared between the templates.
+func NewContextTransport(protocol string, rt http.RoundTripper) http.RoundTripper {
+	if rt == nil {
+		rt = http.DefaultTransport
+	}
+	return &transport{protocol: protocol, transport: rt}
+}
+
+// Unwrap returns the round tripper wrapped by an audit transport, or the
+// round tripper as is if it is not an audit transport.
+func Unwrap(rt http.RoundTripper) http.RoundTripper {
+	if audited, ok := rt.(*transport); ok {
+		return audited.transport
+	}
+	return rt
+}
+
+type contextKey struct{}
+
+// templateContext is the logger and template of the requests of a context
+type templateContext struct {
+	logger     *Logger
+	templateID string
+}
+
+// WithTemplate returns a context recording the requests sent with it as
+// made by the template ID. The context is returned as is if the logger is nil.
+func WithTemplate(ctx context.Context, logger *Logger, templateID string) context.Context {
+	if logger == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, contextKey{}, &templateContext{logger: logger, templateID: templateID})
+}
+
+// RoundTrip sends the request recording it once the response body is closed
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	logger, templateID := t.logger, t.templateID
+	if value, ok := req.Context().Value(contextKey{}).(*templateContext); ok && logger == nil {
+		logger, templateID = value.logger, value.templateID
+	}
+	if logger == nil {
+		return t.transport.RoundTrip(req)
+	}
+
+	target := req.URL.String()
+	sent := RequestSize(req, int(req.ContentLength))
+	resp, err := t.transport.RoundTrip(req)
+	if err != nil {
+		logger.Log(templateID, t.protocol, target, sent, 0, err)
+		return nil, err
+	}
+	headerSize := ResponseHeaderSize(resp)
+	resp.Body = &countingBody{ReadCloser: resp.Body, onClose: func(read int) {
+		logger.Log(templateID, t.protocol, target, sent, headerSize+read, nil)
+	}}
+	return resp, nil
+}
+
+// RequestSize returns the size of the request line, headers and body of a
+// http/1.1 request on the wire.
+func RequestSize(req *http.Request, bodySize int) int {
+	host := req.Host
+	if host == "" {
+		host = req.URL.Host
+	}
+	counter := &countingWriter{}
+	fmt.Fprintf(counter, "%s %s HTTP/1.1\r\nHost: %s\r\n", req.Method, req.URL.RequestURI(), host)
+	_ = req.Header.Write(counter)
+	if bodySize < 0 {
+		bodySize = 0
+	}
+	return counter.written + len("\r\n") + bodySize
+}
+
+// ResponseHeaderSize returns the size of the status line and headers of a
+// http/1.1 response on the wire.
+func ResponseHeaderSize(resp *http.Response) int {
+	counter := &countingWriter{}
+	fmt.Fprintf(counter, "%s %s\r\n", resp.Proto, resp.Status)
+	_ = resp.Header.Write(counter)
+	return counter.written + len("\r\n")
+}
+
+type countingWriter struct {
+	written int
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	c.written += len(p)
+	return len(p), nil
+}
+
+// countingBody counts the bytes read from a response body calling
+// onClose with their number once the body is closed.
+type countingBody struct {
+	io.ReadCloser
+	read    int
+	once    sync.Once
+	onClose func(read int)
+}
+
+func (c *countingBody) Read(p []byte) (int, error) {
+	n, err := c.ReadCloser.Read(p)
+	c.read += n
+	return n, err
+}
+
+func (c *countingBody) Close() error {
+	err := c.ReadCloser.Close()
+	c.once.Do(func() { c.onClose(c.read) })
+	return err
+}
@@ -0,0 +1,534 @@
+// Package authprovider provides the credentials of a secrets file to the
+// requests made to the matching domains. Static secrets contain the
+// credentials, while dynamic secrets obtain them for each host by running a
+// login template and run it again when the responses signal that the session
+// has expired.
+//
+// The credentials are only injected in the http requests, the requests of
+// the other protocols are sent without them.
+package authprovider
+
+import (
+	"encoding/base64"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/projectdiscovery/gologger"
+	"github.com/projectdiscovery/nuclei/v2/pkg/protocols/common/replacer"
+	"go.uber.org/multierr"
+	"gopkg.in/yaml.v2"
+)
+
+// Types of the secrets
+const (
+	TypeHeader = "header"
+	TypeCookie = "cookie"
+	TypeBearer = "bearer"
+	TypeBasic  = "basic"
+	TypeQuery  = "query"
+)
+
+// Secrets is the secrets file with the static and dynamic secrets
+type Secrets struct {
+	Static  []*Secret        `yaml:"static"`
+	Dynamic []*DynamicSecret `yaml:"dynamic"`
+}
+
+// KV is a key value pair of a secret
+type KV struct {
+	Key   string `yaml:"key"`
+	Value string `yaml:"value"`
+}
+
+// Secret contains the credentials injected in the requests of the domains
+type Secret struct {
+	// Type is the type of the secret - header, cookie, bearer, basic or query
+	Type string `yaml:"type"`
+	// Domains are the hosts the secret is used for, optionally with a port
+	Domains []string `yaml:"domains"`
+	// DomainsRegex are the patterns of the hosts the secret is used for
+	DomainsRegex []string `yaml:"domains-regex"`
+
+	Headers  []KV   `yaml:"headers"`
+	Cookies  []KV   `yaml:"cookies"`
+	Params   []KV   `yaml:"params"`
+	Token    string `yaml:"token"`
+	Username string `yaml:"username"`
+	Password string `yaml:"password"`
+
+	domainsRegex []*regexp.Regexp
+	// dynamic is the dynamic secret the secret was resolved from
+	dynamic *DynamicSecret
+}
+
+// DynamicSecret is a secret whose values are extracted by a login template.
+// The values of the secret can reference the extracted values as {{name}}.
+type DynamicSecret struct {
+	Secret `yaml:",inline"`
+	// Template is the path of the login template
+	Template string `yaml:"template"`
+	// Input is the target of the login template, whose session is then shared
+	// by all the hosts. The scanned target is used if empty, logging in to
+	// each host separately.
+	Input string `yaml:"input"`
+	// Variables are passed to the login template (eg. username and password)
+	Variables []KV `yaml:"variables"`
+	// RefreshStatus are the status codes of the responses signalling an
+	// expired session (default 401 and 403).
+	RefreshStatus []int `yaml:"refresh-status"`
+	// RefreshBody are the patterns of the response bodies signalling an expired session
+	RefreshBody []string `yaml:"refresh-body"`
+	// RefreshInterval is the minimum number of seconds between two logins
+	// for the same session (default 60), so that responses signalling an
+	// expired session on protected paths don't hammer the login endpoint.
+	RefreshInterval int `yaml:"refresh-interval"`
+
+	refreshBody []*regexp.Regexp
+	mutex       sync.Mutex
+	sessions    map[string]*session
+}
+
+// session is the result of a login of a dynamic secret. The fields are
+// set once the login is finished and done is closed.
+type session struct {
+	resolved *Secret
+	err      error
+	fetched  time.Time
+	// failures is the number of consecutive failed logins
+	failures int
+	done     chan struct{}
+}
+
+// Backoff between the retries of failed logins, doubled for each failure
+const (
+	loginBackoff    = 5 * time.Second
+	maxLoginBackoff = 5 * time.Minute
+)
+
+// defaultRefreshStatus are the status codes signalling an expired session
+var defaultRefreshStatus = []int{http.StatusUnauthorized, http.StatusForbidden}
+
+// defaultRefreshInterval is the default minimum number of seconds between
+// two logins for the same session.
+const defaultRefreshInterval = 60
+
+// LoginFunc runs a login template for the input with the variables and
+// returns the values extracted by it.
+type LoginFunc func(template, input string, variables map[string]interface{}) (map[string]interface{}, error)
+
+// Provider provides the secrets for the hosts of the requests
+type Provider struct {
+	secrets *Secrets
+	login   LoginFunc
+}
+
+// New creates a new provider from the secrets file
+func New(file string, login LoginFunc) (*Provider, error) {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not read secrets file")
+	}
+	secrets := &Secrets{}
+	if err := yaml.Unmarshal(data, secrets); err != nil {
+		return nil, errors.Wrap(err, "could not parse secrets file")
+	}
+	for _, secret := range secrets.Static {
+		if err := secret.compile(); err != nil {
+			return nil, err
+		}
+	}
+	for _, secret := range secrets.Dynamic {
+		if secret.Template == "" {
+			return nil, errors.New("no login template for dynamic secret")
+		}
+		if err := secret.compile(); err != nil {
+			return nil, err
+		}
+		secret.sessions = make(map[string]*session)
+		if len(secret.RefreshStatus) == 0 {
+			secret.RefreshStatus = defaultRefreshStatus
+		}
+		if secret.RefreshInterval <= 0 {
+			secret.RefreshInterval = defaultRefreshInterval
+		}
+		for _, pattern := range secret.RefreshBody {
+			compiled, err := regexp.Compile(pattern)
+			if err != nil {
+				return nil, errors.Wrapf(err, "could not compile refresh body regex %s", pattern)
+			}
+			secret.refreshBody = append(secret.refreshBody, compiled)
+		}
+	}
+	return &Provider{secrets: secrets, login: login}, nil
+}
+
+// compile validates the secret and compiles the domain patterns
+func (s *Secret) compile() error {
+	s.Type = strings.ToLower(s.Type)
+	switch s.Type {
+	case TypeHeader, TypeCookie, TypeBearer, TypeBasic, TypeQuery:
+	default:
+		return errors.Errorf("invalid secret type %s", s.Type)
+	}
+	if len(s.Domains) == 0 && len(s.DomainsRegex) == 0 {
+		return errors.Errorf("no domains for %s secret", s.Type)
+	}
+	for _, pattern := range s.DomainsRegex {
+		compiled, err := regexp.Compile(pattern)
+		if err != nil {
+			return errors.Wrapf(err, "could not compile domain regex %s", pattern)
+		}
+		s.domainsRegex = append(s.domainsRegex, compiled)
+	}
+	return nil
+}
+
+// Matches returns true if the secret is used for the host (with optional port)
+func (s *Secret) Matches(host string) bool {
+	host = strings.ToLower(host)
+	hostname := host
+	if value, _, err := net.SplitHostPort(host); err == nil {
+		hostname = value
+	}
+	for _, domain := range s.Domains {
+		if domain = strings.ToLower(domain); domain == host || domain == hostname {
+			return true
+		}
+	}
+	for _, pattern := range s.domainsRegex {
+		if pattern.MatchString(host) || pattern.MatchString(hostname) {
+			return true
+		}
+	}
+	return false
+}
+
+// Lookup returns the secrets for the host of the target url. The dynamic
+// secrets are resolved by running their login template the first time
+// for each host, the errors of the failed logins being returned along
+// with the other secrets.
+func (p *Provider) Lookup(target string) ([]*Secret, error) {
+	if p == nil {
+		return nil, nil
+	}
+	host, base, ok := parseTarget(target)
+	if !ok {
+		return nil, nil
+	}
+
+	var secrets []*Secret
+	for _, secret := range p.secrets.Static {
+		if secret.Matches(host) {
+			secrets = append(secrets, secret)
+		}
+	}
+	var lookupErr error
+	for _, secret := range p.secrets.Dynamic {
+		if !secret.Matches(host) {
+			continue
+		}
+		resolved, err := p.resolve(secret, base)
+		if err != nil {
+			lookupErr = multierr.Append(lookupErr, err)
+			continue
+		}
+		secrets = append(secrets, resolved)
+	}
+	return secrets, lookupErr
+}
+
+// Expired returns true if the response signals that the session of one of
+// the dynamic secrets used by the request has expired.
+func (p *Provider) Expired(secrets []*Secret, statusCode int, body []byte) bool {
+	for _, secret := range secrets {
+		if secret.dynamic != nil && secret.dynamic.expired(statusCode, body) {
+			return true
+		}
+	}
+	return false
+}
+
+// Refresh runs again the login templates of the dynamic secrets used by a
+// request, returning true if new credentials are available for the request.
+// Sessions already refreshed by concurrent requests are reused, while the
+// sessions logged in less than the refresh interval ago are kept.
+func (p *Provider) Refresh(target string, secrets []*Secret) (bool, error) {
+	if p == nil {
+		return false, nil
+	}
+	_, base, ok := parseTarget(target)
+	if !ok {
+		return false, nil
+	}
+	var refreshed bool
+	var refreshErr error
+	for _, secret := range secrets {
+		if secret.dynamic == nil {
+			continue
+		}
+		dynamic := secret.dynamic
+		key := dynamic.sessionKey(base)
+		dynamic.mutex.Lock()
+		current, ok := dynamic.sessions[key]
+		if !ok {
+			dynamic.mutex.Unlock()
+			continue
+		}
+		if !current.finished() || current.resolved != secret {
+			// The session is refreshed by a concurrent request
+			dynamic.mutex.Unlock()
+			<-current.done
+			refreshed = refreshed || current.resolved != nil
+			continue
+		}
+		if time.Since(current.fetched) < time.Duration(dynamic.RefreshInterval)*time.Second {
+			dynamic.mutex.Unlock()
+			continue
+		}
+		current = dynamic.newSession(key, current)
+		dynamic.mutex.Unlock()
+
+		p.fetch(dynamic, base, current)
+		if current.err != nil {
+			refreshErr = multierr.Append(refreshErr, current.err)
+		} else {
+			refreshed = true
+		}
+	}
+	return refreshed, refreshErr
+}
+
+// resolve returns the secret with the values extracted by the login template
+// of the dynamic secret for the target. The login runs once per session with
+// the concurrent lookups waiting for it, failed logins are retried with backoff.
+func (p *Provider) resolve(secret *DynamicSecret, target string) (*Secret, error) {
+	key := secret.sessionKey(target)
+	secret.mutex.Lock()
+	current, ok := secret.sessions[key]
+	if !ok || (current.finished() && current.err != nil && time.Now().After(current.retryAt())) {
+		current = secret.newSession(key, current)
+		secret.mutex.Unlock()
+		p.fetch(secret, target, current)
+		return current.resolved, current.err
+	}
+	secret.mutex.Unlock()
+
+	<-current.done
+	return current.resolved, current.err
+}
+
+// newSession stores a new session logging in for the key, keeping the failed
+// logins of the finished previous session. The mutex must be held by the caller.
+func (s *DynamicSecret) newSession(key string, previous *session) *session {
+	current := &session{done: make(chan struct{})}
+	if previous != nil {
+		current.failures = previous.failures
+	}
+	s.sessions[key] = current
+	return current
+}
+
+// finished returns true if the login of the session is finished
+func (s *session) finished() bool {
+	select {
+	case <-s.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// retryAt returns the time after which a failed login is retried
+func (s *session) retryAt() time.Time {
+	backoff := maxLoginBackoff
+	if s.failures <= 10 {
+		if value := loginBackoff << uint(s.failures-1); value < maxLoginBackoff {
+			backoff = value
+		}
+	}
+	return s.fetched.Add(backoff)
+}
+
+// sessionKey returns the key of the session of the secret for a target,
+// the sessions of secrets with a fixed login input are shared.
+func (s *DynamicSecret) sessionKey(target string) string {
+	if s.Input != "" {
+		return ""
+	}
+	return strings.ToLower(target)
+}
+
+// fetch runs the login template of the dynamic secret for the target
+// finishing the session. It is called without holding the mutex of the secret.
+func (p *Provider) fetch(secret *DynamicSecret, target string, current *session) {
+	defer close(current.done)
+	defer func() {
+		current.fetched = time.Now()
+		if current.err == nil {
+			current.failures = 0
+			return
+		}
+		current.failures++
+		gologger.Warning().Msgf("Could not login for %s: %s\n", target, current.err)
+	}()
+
+	if p.login == nil {
+		current.err = errors.New("no login function configured")
+		return
+	}
+
+	input := secret.Input
+	if input == "" {
+		input = target
+	}
+	variables := make(map[string]interface{}, len(secret.Variables))
+	for _, variable := range secret.Variables {
+		variables[variable.Key] = variable.Value
+	}
+	values, err := p.login(secret.Template, input, variables)
+	if err != nil {
+		current.err = errors.Wrapf(err, "could not run login template %s", secret.Template)
+		return
+	}
+	for k, v := range variables {
+		if _, ok := values[k]; !ok {
+			values[k] = v
+		}
+	}
+	current.resolved = secret.Secret.replace(values)
+	current.resolved.dynamic = secret
+}
+
+// expired returns true if the response matches the session expiry signals of the secret
+func (s *DynamicSecret) expired(statusCode int, body []byte) bool {
+	for _, status := range s.RefreshStatus {
+		if status == statusCode {
+			return true
+		}
+	}
+	for _, pattern := range s.refreshBody {
+		if pattern.Match(body) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseTarget returns the host and the base url of a target url
+func parseTarget(target string) (host, base string, ok bool) {
+	parsed, err := url.Parse(target)
+	if err != nil || parsed.Host == "" {
+		return "", "", false
+	}
+	return parsed.Host, parsed.Scheme + "://" + parsed.Host, true
+}
+
+// replace returns a copy of the secret with the values replaced
+func (s *Secret) replace(values map[string]interface{}) *Secret {
+	replaceKVs := func(items []KV) []KV {
+		replaced := make([]KV, 0, len(items))
+		for _, item := range items {
+			replaced = append(replaced, KV{Key: item.Key, Value: replacer.Replace(item.Value, values)})
+		}
+		return replaced
+	}
+	return &Secret{
+		Type:         s.Type,
+		Domains:      s.Domains,
+		DomainsRegex: s.DomainsRegex,
+		Headers:      replaceKVs(s.Headers),
+		Cookies:      replaceKVs(s.Cookies),
+		Params:       replaceKVs(s.Params),
+		Token:        replacer.Replace(s.Token, values),
+		Username:     replacer.Replace(s.Username, values),
+		Password:     replacer.Replace(s.Password, values),
+		domainsRegex: s.domainsRegex,
+	}
+}
+
+// headers returns the headers set by the secret
+func (s *Secret) headers() map[string]string {
+	headers := make(map[string]string)
+	switch s.Type {
+	case TypeHeader:
+		for _, header := range s.Headers {
+			headers[header.Key] = header.Value
+		}
+	case TypeBearer:
+		headers["Authorization"] = "Bearer " + s.Token
+	case TypeBasic:
+		headers["Authorization"] = "Basic " + base64.StdEncoding.EncodeToString([]byte(s.Username+":"+s.Password))
+	}
+	return headers
+}
+
+// cookie returns the cookie header value of the secret
+func (s *Secret) cookie() string {
+	if s.Type != TypeCookie {
+		return ""
+	}
+	cookies := make([]string, 0, len(s.Cookies))
+	for _, cookie := range s.Cookies {
+		cookies = append(cookies, cookie.Key+"="+cookie.Value)
+	}
+	return strings.Join(cookies, "; ")
+}
+
+// query returns the url encoded query parameters of the secret
+func (s *Secret) query() string {
+	if s.Type != TypeQuery {
+		return ""
+	}
+	values := make([]string, 0, len(s.Params))
+	for _, param := range s.Params {
+		values = append(values, url.QueryEscape(param.Key)+"="+url.QueryEscape(param.Value))
+	}
+	return strings.Join(values, "&")
+}
+
+// ApplyToRequest injects the credentials of the secret in the request
+func (s *Secret) ApplyToRequest(req *http.Request) {
+	for k, v := range s.headers() {
+		req.Header.Set(k, v)
+	}
+	if cookie := s.cookie(); cookie != "" {
+		if existing := req.Header.Get("Cookie"); existing != "" {
+			cookie = existing + "; " + cookie
+		}
+		req.Header.Set("Cookie", cookie)
+	}
+	if query := s.query(); query != "" {
+		if req.URL.RawQuery != "" {
+			query = req.URL.RawQuery + "&" + query
+		}
+		req.URL.RawQuery = query
+	}
+}
+
+// ApplyToRaw injects the credentials of the secret in the headers and the
+// path of a raw request, returning the new path.
+func (s *Secret) ApplyToRaw(headers map[string]string, path string) string {
+	for k, v := range s.headers() {
+		headers[k] = v
+	}
+	if cookie := s.cookie(); cookie != "" {
+		if existing := headers["Cookie"]; existing != "" {
+			cookie = existing + "; " + cookie
+		}
+		headers["Cookie"] = cookie
+	}
+	if query := s.query(); query != "" {
+		if strings.Contains(path, "?") {
+			return path + "&" + query
+		}
+		return path + "?" + query
+	}
+	return path
+}
@@ -0,0 +1,261 @@
+package authprovider
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+const testSecrets = `
+static:
+  - type: bearer
+    domains: [api.example.com]
+    token: static-token
+  - type: cookie
+    domains-regex: ['.*\.example\.org$']
+    cookies:
+      - key: session
+        value: abc
+dynamic:
+  - type: header
+    domains: [app.example.com:8443]
+    template: login.yaml
+    variables:
+      - key: username
+        value: admin
+    headers:
+      - key: X-Auth
+        value: "{{token}}-{{username}}"
+`
+
+func TestProviderLookup(t *testing.T) {
+	file, err := ioutil.TempFile("", "secrets-*.yaml")
+	require.Nil(t, err, "could not create secrets file")
+	defer os.Remove(file.Name())
+	_, _ = file.WriteString(testSecrets)
+	file.Close()
+
+	logins := 0
+	provider, err := New(file.Name(), func(template, input string, variables map[string]interface{}) (map[string]interface{}, error) {
+		logins++
+		require.Equal(t, "login.yaml", template, "could not get login template")
+		require.Equal(t, "https://app.example.com:8443", input, "could not get login input")
+		require.Equal(t, "admin", variables["username"], "could not get login variables")
+		return map[string]interface{}{"token": "dynamic"}, nil
+	})
+	require.Nil(t, err, "could not create provider")
+
+	secrets := lookup(t, provider, "https://api.example.com/v1/users")
+	require.Len(t, secrets, 1, "could not lookup static secret")
+	req, _ := http.NewRequest(http.MethodGet, "https://api.example.com/v1/users", nil)
+	secrets[0].ApplyToRequest(req)
+	require.Equal(t, "Bearer static-token", req.Header.Get("Authorization"), "could not apply bearer token")
+
+	secrets = lookup(t, provider, "http://www.example.org/")
+	require.Len(t, secrets, 1, "could not lookup regex secret")
+	headers := map[string]string{"Cookie": "a=b"}
+	path := secrets[0].ApplyToRaw(headers, "/")
+	require.Equal(t, "a=b; session=abc", headers["Cookie"], "could not apply cookie")
+	require.Equal(t, "/", path, "could not keep path")
+
+	for i := 0; i < 2; i++ {
+		secrets = lookup(t, provider, "https://app.example.com:8443/admin")
+		require.Len(t, secrets, 1, "could not lookup dynamic secret")
+		require.Equal(t, "dynamic-admin", secrets[0].Headers[0].Value, "could not replace extracted values")
+	}
+	require.Equal(t, 1, logins, "could not run login template once")
+
+	require.Empty(t, lookup(t, provider, "https://app.example.com/"), "could match host with different port")
+}
+
+func TestProviderRefresh(t *testing.T) {
+	file, err := ioutil.TempFile("", "secrets-*.yaml")
+	require.Nil(t, err, "could not create secrets file")
+	defer os.Remove(file.Name())
+	_, _ = file.WriteString(`
+dynamic:
+  - type: bearer
+    domains: [app.example.com]
+    template: login.yaml
+    token: "{{token}}"
+    refresh-body: ['session expired']
+`)
+	file.Close()
+
+	logins := 0
+	provider, err := New(file.Name(), func(template, input string, variables map[string]interface{}) (map[string]interface{}, error) {
+		logins++
+		return map[string]interface{}{"token": fmt.Sprintf("token-%d", logins)}, nil
+	})
+	require.Nil(t, err, "could not create provider")
+
+	secrets := lookup(t, provider, "https://app.example.com/")
+	require.Len(t, secrets, 1, "could not lookup dynamic secret")
+	require.Equal(t, "token-1", secrets[0].Token, "could not get login token")
+
+	require.False(t, provider.Expired(secrets, 200, []byte("ok")), "could detect expiry of valid session")
+	require.True(t, provider.Expired(secrets, 401, nil), "could not detect expiry by default status")
+	require.True(t, provider.Expired(secrets, 200, []byte("your session expired")), "could not detect expiry by body")
+
+	// Sessions are not refreshed again within the refresh interval
+	refreshed, err := provider.Refresh("https://app.example.com/admin", secrets)
+	require.Nil(t, err, "could not refresh session")
+	require.False(t, refreshed, "could get new credentials within refresh interval")
+	require.Equal(t, 1, logins, "could refresh session within refresh interval")
+	provider.secrets.Dynamic[0].sessions["https://app.example.com"].fetched = time.Now().Add(-time.Minute)
+
+	// Concurrent requests failing with the same session refresh it only once
+	refreshed, _ = provider.Refresh("https://app.example.com/admin", secrets)
+	require.True(t, refreshed, "could not get new credentials")
+	refreshed, _ = provider.Refresh("https://app.example.com/admin", secrets)
+	require.True(t, refreshed, "could not get credentials refreshed by concurrent request")
+	require.Equal(t, 2, logins, "could not refresh session once")
+
+	secrets = lookup(t, provider, "https://app.example.com/")
+	require.Equal(t, "token-2", secrets[0].Token, "could not get refreshed token")
+}
+
+func TestProviderLookupPerHost(t *testing.T) {
+	file, err := ioutil.TempFile("", "secrets-*.yaml")
+	require.Nil(t, err, "could not create secrets file")
+	defer os.Remove(file.Name())
+	_, _ = file.WriteString(`
+dynamic:
+  - type: bearer
+    domains-regex: ['.*\.example\.com$']
+    template: login.yaml
+    token: "{{token}}"
+  - type: header
+    domains-regex: ['.*\.example\.org$']
+    template: sso.yaml
+    input: https://sso.example.net
+    headers:
+      - key: X-SSO
+        value: "{{token}}"
+`)
+	file.Close()
+
+	var inputs []string
+	provider, err := New(file.Name(), func(template, input string, variables map[string]interface{}) (map[string]interface{}, error) {
+		inputs = append(inputs, input)
+		return map[string]interface{}{"token": "token-" + input}, nil
+	})
+	require.Nil(t, err, "could not create provider")
+
+	first := lookup(t, provider, "https://a.example.com/admin")
+	second := lookup(t, provider, "https://b.example.com/admin")
+	require.Equal(t, "token-https://a.example.com", first[0].Token, "could not login to first host")
+	require.Equal(t, "token-https://b.example.com", second[0].Token, "could not login to second host")
+	require.Equal(t, "token-https://a.example.com", lookup(t, provider, "https://a.example.com/")[0].Token, "could not reuse session of host")
+
+	provider.secrets.Dynamic[0].sessions["https://b.example.com"].fetched = time.Now().Add(-time.Minute)
+	provider.Refresh("https://b.example.com/", second)
+	require.Equal(t, "token-https://a.example.com", lookup(t, provider, "https://a.example.com/")[0].Token, "could refresh session of other host")
+
+	first = lookup(t, provider, "https://a.example.org/")
+	second = lookup(t, provider, "https://b.example.org/")
+	require.Equal(t, "token-https://sso.example.net", second[0].Headers[0].Value, "could not login to fixed input")
+	require.Equal(t, first[0], second[0], "could not share session of fixed input")
+	require.Equal(t, []string{"https://a.example.com", "https://b.example.com", "https://b.example.com", "https://sso.example.net"}, inputs, "could not login once per host")
+}
+
+func TestProviderRefreshError(t *testing.T) {
+	file, err := ioutil.TempFile("", "secrets-*.yaml")
+	require.Nil(t, err, "could not create secrets file")
+	defer os.Remove(file.Name())
+	_, _ = file.WriteString(`
+dynamic:
+  - type: bearer
+    domains: [app.example.com]
+    template: login.yaml
+    token: "{{token}}"
+`)
+	file.Close()
+
+	logins := 0
+	provider, err := New(file.Name(), func(template, input string, variables map[string]interface{}) (map[string]interface{}, error) {
+		logins++
+		if logins > 1 {
+			return nil, errors.New("invalid credentials")
+		}
+		return map[string]interface{}{"token": "token"}, nil
+	})
+	require.Nil(t, err, "could not create provider")
+
+	secrets := lookup(t, provider, "https://app.example.com/")
+	provider.secrets.Dynamic[0].sessions["https://app.example.com"].fetched = time.Now().Add(-time.Minute)
+	refreshed, err := provider.Refresh("https://app.example.com/", secrets)
+	require.NotNil(t, err, "could not get login error")
+	require.False(t, refreshed, "could get new credentials after failed login")
+}
+
+// lookup returns the secrets of a target failing the test on error
+func lookup(t *testing.T, provider *Provider, target string) []*Secret {
+	secrets, err := provider.Lookup(target)
+	require.Nil(t, err, "could not lookup secrets")
+	return secrets
+}
+
+func TestProviderLoginRetry(t *testing.T) {
+	file, err := ioutil.TempFile("", "secrets-*.yaml")
+	require.Nil(t, err, "could not create secrets file")
+	defer os.Remove(file.Name())
+	_, _ = file.WriteString(`
+dynamic:
+  - type: bearer
+    domains-regex: ['.*\.example\.com$']
+    template: login.yaml
+    token: "{{token}}"
+`)
+	file.Close()
+
+	var mutex sync.Mutex
+	logins := make(map[string]int)
+	release := make(chan struct{})
+	provider, err := New(file.Name(), func(template, input string, variables map[string]interface{}) (map[string]interface{}, error) {
+		mutex.Lock()
+		logins[input]++
+		count := logins[input]
+		mutex.Unlock()
+		if input == "https://slow.example.com" {
+			<-release
+		}
+		if input == "https://down.example.com" && count == 1 {
+			return nil, errors.New("connection refused")
+		}
+		return map[string]interface{}{"token": input}, nil
+	})
+	require.Nil(t, err, "could not create provider")
+
+	// Logins of other hosts don't wait for a slow login
+	slow := make(chan []*Secret, 2)
+	for i := 0; i < 2; i++ {
+		go func() {
+			secrets, _ := provider.Lookup("https://slow.example.com/")
+			slow <- secrets
+		}()
+	}
+	secrets, err := provider.Lookup("https://down.example.com/")
+	require.NotNil(t, err, "could not get login error")
+	require.Empty(t, secrets, "could get secrets of failed login")
+
+	// Failed logins are retried after the backoff
+	_, err = provider.Lookup("https://down.example.com/")
+	require.NotNil(t, err, "could retry failed login before backoff")
+	provider.secrets.Dynamic[0].sessions["https://down.example.com"].fetched = time.Now().Add(-time.Minute)
+	secrets = lookup(t, provider, "https://down.example.com/")
+	require.Equal(t, "https://down.example.com", secrets[0].Token, "could not retry failed login")
+
+	close(release)
+	for i := 0; i < 2; i++ {
+		require.Equal(t, "https://slow.example.com", (<-slow)[0].Token, "could not wait for concurrent login")
+	}
+	require.Equal(t, map[string]int{"https://slow.example.com": 1, "https://down.example.com": 2}, logins, "could not login once per session")
+}
@@ -16,7 +16,8 @@ func Cluster(list map[string]*templates.Template) [][]*templates.Template {
 	for key, template := range list {
 		// We only cluster http requests as of now.
 		// Take care of requests that can't be clustered first.
-		if len(template.RequestsHTTP) == 0 {
+		// Templates with variables have per-template request values.
+		if len(template.RequestsHTTP) == 0 || len(template.Variables) > 0 {
 			delete(list, key)
 			final = append(final, []*templates.Template{template})
 			continue
@@ -29,7 +30,7 @@ func Cluster(list map[string]*templates.Template) [][]*templates.Template {
 			cluster := []*templates.Template{}
 
 			for otherKey, other := range list {
-				if len(other.RequestsHTTP) == 0 {
+				if len(other.RequestsHTTP) == 0 || len(other.Variables) > 0 {
 					continue
 				}
 				if template.RequestsHTTP[0].CanCluster(other.RequestsHTTP[0]) {
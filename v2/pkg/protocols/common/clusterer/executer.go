@@ -1,6 +1,8 @@
 package clusterer
 
 import (
+	"strconv"
+
 	"github.com/projectdiscovery/gologger"
 	"github.com/projectdiscovery/nuclei/v2/pkg/operators"
 	"github.com/projectdiscovery/nuclei/v2/pkg/output"
@@ -64,7 +66,19 @@ func (e *Executer) Execute(input string) (bool, error) {
 
 	previous := make(map[string]interface{})
 	dynamicValues := make(map[string]interface{})
+	for k, v := range e.options.ContextStore.Get(input) {
+		dynamicValues[k] = v
+		previous[k] = v
+	}
+	span := e.options.Tracer.Start("cluster", nil, "templates", strconv.Itoa(len(e.operators)), "host", input)
+	defer span.End()
+	defer e.options.CookieJars.Release(input)
+
 	err := e.requests.ExecuteWithResults(input, dynamicValues, previous, func(event *output.InternalWrappedEvent) {
+		e.options.Tracer.StartAt("request", span, event.Started, "host", input, "protocol", "http").End()
+		e.options.GlobalMatchers.Match(e.requests, event, func(result *output.ResultEvent) {
+			e.writeResult(input, result)
+		})
 		for _, operator := range e.operators {
 			result, matched := operator.operator.Execute(event.InternalEvent, e.requests.Match, e.requests.Extract)
 			if matched && result != nil {
@@ -73,26 +87,34 @@ func (e *Executer) Execute(input string) (bool, error) {
 				event.InternalEvent["template-path"] = operator.templatePath
 				event.InternalEvent["template-info"] = operator.templateInfo
 				event.Results = e.requests.MakeResultEvent(event)
+				e.options.ResponseStore.SaveEvent(input, operator.templateID, event)
+				e.options.ContextStore.Set(input, result.StoredValues)
 				results = true
 				for _, r := range event.Results {
-					if e.options.IssuesClient != nil {
-						if err := e.options.IssuesClient.CreateIssue(r); err != nil {
-							gologger.Warning().Msgf("Could not create issue on tracker: %s", err)
-						}
-					}
-					_ = e.options.Output.Write(r)
-					e.options.Progress.IncrementMatched()
+					e.writeResult(input, r)
 				}
 			}
 		}
 	})
+	span.SetError(err)
 	return results, err
 }
 
 // ExecuteWithResults executes the protocol requests and returns results instead of writing them.
 func (e *Executer) ExecuteWithResults(input string, callback protocols.OutputEventCallback) error {
-	dynamicValues := make(map[string]interface{})
+	dynamicValues := e.options.ContextStore.Get(input)
+	if dynamicValues == nil {
+		dynamicValues = make(map[string]interface{})
+	}
+	span := e.options.Tracer.Start("cluster", nil, "templates", strconv.Itoa(len(e.operators)), "host", input)
+	defer span.End()
+	defer e.options.CookieJars.Release(input)
+
 	err := e.requests.ExecuteWithResults(input, dynamicValues, nil, func(event *output.InternalWrappedEvent) {
+		e.options.Tracer.StartAt("request", span, event.Started, "host", input, "protocol", "http").End()
+		e.options.GlobalMatchers.Match(e.requests, event, func(result *output.ResultEvent) {
+			e.writeResult(input, result)
+		})
 		for _, operator := range e.operators {
 			result, matched := operator.operator.Execute(event.InternalEvent, e.requests.Match, e.requests.Extract)
 			if matched && result != nil {
@@ -101,9 +123,29 @@ func (e *Executer) ExecuteWithResults(input string, callback protocols.OutputEve
 				event.InternalEvent["template-path"] = operator.templatePath
 				event.InternalEvent["template-info"] = operator.templateInfo
 				event.Results = e.requests.MakeResultEvent(event)
+				e.options.ResponseStore.SaveEvent(input, operator.templateID, event)
+				e.options.ContextStore.Set(input, result.StoredValues)
 				callback(event)
 			}
 		}
 	})
+	span.SetError(err)
 	return err
 }
+
+// writeResult writes a result event of an input to the output and issue tracker
+func (e *Executer) writeResult(input string, result *output.ResultEvent) {
+	if !e.options.Triage.Apply(result) {
+		return
+	}
+	if e.options.IssuesClient != nil {
+		if err := e.options.IssuesClient.CreateIssue(result); err != nil {
+			gologger.Warning().Msgf("Could not create issue on tracker: %s", err)
+		}
+	}
+	_ = e.options.Output.Write(result)
+	e.options.Progress.IncrementMatched()
+	if e.options.ResultHook != nil {
+		e.options.ResultHook(input, result)
+	}
+}
@@ -0,0 +1,84 @@
+// Package contextstore stores the values extracted for each host during a
+// scan so they can be reused by the later templates run on the host.
+package contextstore
+
+import (
+	"net"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+// Store contains the stored values of each host
+type Store struct {
+	mutex  sync.RWMutex
+	values map[string]map[string]interface{}
+}
+
+// New creates a new context store
+func New() *Store {
+	return &Store{values: make(map[string]map[string]interface{})}
+}
+
+// Set stores the values for the host of an input, replacing the
+// previous values with the same names.
+func (s *Store) Set(input string, values map[string]interface{}) {
+	if s == nil || len(values) == 0 {
+		return
+	}
+	key := hostKey(input)
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	host, ok := s.values[key]
+	if !ok {
+		host = make(map[string]interface{})
+		s.values[key] = host
+	}
+	for k, v := range values {
+		host[k] = v
+	}
+}
+
+// Get returns a copy of the stored values for the host of an input
+func (s *Store) Get(input string) map[string]interface{} {
+	if s == nil {
+		return nil
+	}
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	host, ok := s.values[hostKey(input)]
+	if !ok {
+		return nil
+	}
+	values := make(map[string]interface{}, len(host))
+	for k, v := range host {
+		values[k] = v
+	}
+	return values
+}
+
+// defaultPorts are the ports of the url schemes without an explicit port
+var defaultPorts = map[string]string{"http": "80", "https": "443"}
+
+// hostKey returns the normalized host and port of an input as the services
+// on the other ports of a host don't share the same values.
+func hostKey(input string) string {
+	if strings.Contains(input, "://") {
+		if parsed, err := url.Parse(input); err == nil && parsed.Hostname() != "" {
+			port := parsed.Port()
+			if port == "" {
+				port = defaultPorts[strings.ToLower(parsed.Scheme)]
+			}
+			if port == "" {
+				return strings.ToLower(parsed.Host)
+			}
+			return strings.ToLower(net.JoinHostPort(parsed.Hostname(), port))
+		}
+	}
+	host := input
+	if i := strings.IndexAny(host, "/?#"); i != -1 {
+		host = host[:i]
+	}
+	return strings.ToLower(host)
+}
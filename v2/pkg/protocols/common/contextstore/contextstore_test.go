@@ -0,0 +1,30 @@
+package contextstore
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestStore(t *testing.T) {
+	store := New()
+	store.Set("https://Example.com/login", map[string]interface{}{"token": "first"})
+	store.Set("example.com:443", map[string]interface{}{"token": "second", "user": "admin"})
+	store.Set("https://example.com:8443", map[string]interface{}{"token": "other-port"})
+	store.Set("other.com", map[string]interface{}{"token": "other"})
+
+	values := store.Get("https://example.com:443/api")
+	require.Equal(t, map[string]interface{}{"token": "second", "user": "admin"}, values, "could not get host values")
+	require.Equal(t, "other-port", store.Get("example.com:8443/api")["token"], "could not keep port of host")
+	require.Nil(t, store.Get("http://example.com"), "could get values of other port")
+
+	values["token"] = "changed"
+	require.Equal(t, "second", store.Get("https://example.com")["token"], "could change stored values")
+	require.Nil(t, store.Get("unknown.com"), "could get values of unknown host")
+	require.Equal(t, "[::1]:80", hostKey("http://[::1]/path"), "could not get ipv6 host key")
+	require.Equal(t, "[::1]:80", hostKey("[::1]:80"), "could not get ipv6 host key")
+
+	var disabled *Store
+	disabled.Set("example.com", map[string]interface{}{"token": "value"})
+	require.Nil(t, disabled.Get("example.com"), "could get values of nil store")
+}
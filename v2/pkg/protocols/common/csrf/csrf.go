@@ -0,0 +1,211 @@
+// Package csrf tracks the csrf tokens found in the responses of each host
+// and injects them in the subsequent state changing requests to the host.
+package csrf
+
+import (
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+
+	jsoniter "github.com/json-iterator/go"
+	"github.com/pkg/errors"
+	"github.com/projectdiscovery/nuclei/v2/pkg/protocols/common/wafdetect"
+)
+
+// DefaultHeader is the header of the tokens found without a form field name
+const DefaultHeader = "X-CSRF-Token"
+
+// Token is a csrf token found in a response
+type Token struct {
+	// Name is the name of the form field or the header of the token
+	Name  string
+	Value string
+	// Header is true if the token is sent as a header instead of a form field
+	Header bool
+}
+
+// pattern extracts tokens from the response bodies. The value group
+// contains the value of the token, and the optional name group the form
+// field name. Tokens without a name are sent as the default header.
+type pattern struct {
+	regex *regexp.Regexp
+	name  int
+	value int
+}
+
+// defaultPatterns are the patterns of common hidden form fields and meta tags
+var defaultPatterns = []string{
+	`(?i)<input[^>]+name=["']?(?P<name>[\w.\-\[\]]*(?:csrf|xsrf|authenticity_token|requestverificationtoken|_token|nonce)[\w.\-\[\]]*)["']?[^>]*value=["'](?P<value>[^"']+)["']`,
+	`(?i)<input[^>]+value=["'](?P<value>[^"']+)["'][^>]*name=["']?(?P<name>[\w.\-\[\]]*(?:csrf|xsrf|authenticity_token|requestverificationtoken|_token|nonce)[\w.\-\[\]]*)["']?`,
+	`(?i)<meta[^>]+name=["'](?:csrf-token|csrf_token|_csrf|xsrf-token)["'][^>]*content=["'](?P<value>[^"']+)["']`,
+	`(?i)<meta[^>]+content=["'](?P<value>[^"']+)["'][^>]*name=["'](?:csrf-token|csrf_token|_csrf|xsrf-token)["']`,
+}
+
+// cookieHeaders are the headers set from the values of common csrf cookies
+var cookieHeaders = map[string]string{
+	"xsrf-token": "X-XSRF-TOKEN",
+	"csrftoken":  "X-CSRFToken",
+	"csrf-token": DefaultHeader,
+}
+
+// stateChangingMethods are the methods of the requests the tokens are injected in
+var stateChangingMethods = map[string]struct{}{
+	http.MethodPost:   {},
+	http.MethodPut:    {},
+	http.MethodPatch:  {},
+	http.MethodDelete: {},
+}
+
+// Store contains the latest csrf tokens found for each host
+type Store struct {
+	patterns []*pattern
+
+	mutex  sync.RWMutex
+	tokens map[string]map[string]Token
+}
+
+// New creates a new csrf token store. The patterns are regular expressions
+// with a value group and an optional name group, used along the default ones.
+func New(patterns []string) (*Store, error) {
+	store := &Store{tokens: make(map[string]map[string]Token)}
+	for _, value := range append(append([]string{}, defaultPatterns...), patterns...) {
+		regex, err := regexp.Compile(value)
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not compile csrf pattern %s", value)
+		}
+		compiled := &pattern{regex: regex, name: regex.SubexpIndex("name"), value: regex.SubexpIndex("value")}
+		if compiled.value == -1 {
+			if regex.NumSubexp() != 1 {
+				return nil, errors.Errorf("no value group in csrf pattern %s", value)
+			}
+			compiled.value = 1
+		}
+		store.patterns = append(store.patterns, compiled)
+	}
+	return store, nil
+}
+
+// Record records the csrf tokens found in the cookies and body of a response for the input
+func (s *Store) Record(input string, headers http.Header, body []byte) {
+	var tokens []Token
+	for _, pattern := range s.patterns {
+		for _, match := range pattern.regex.FindAllSubmatch(body, -1) {
+			token := Token{Value: string(match[pattern.value])}
+			if pattern.name != -1 && len(match[pattern.name]) > 0 {
+				token.Name = string(match[pattern.name])
+			} else {
+				token.Name, token.Header = DefaultHeader, true
+			}
+			tokens = append(tokens, token)
+		}
+	}
+	response := http.Response{Header: headers}
+	for _, cookie := range response.Cookies() {
+		if header, ok := cookieHeaders[strings.ToLower(cookie.Name)]; ok && cookie.Value != "" {
+			value, err := url.QueryUnescape(cookie.Value)
+			if err != nil {
+				value = cookie.Value
+			}
+			tokens = append(tokens, Token{Name: header, Value: value, Header: true})
+		}
+	}
+	if len(tokens) == 0 {
+		return
+	}
+
+	key := wafdetect.HostKey(input)
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	hostTokens, ok := s.tokens[key]
+	if !ok {
+		hostTokens = make(map[string]Token)
+		s.tokens[key] = hostTokens
+	}
+	for _, token := range tokens {
+		hostTokens[token.Name] = token
+	}
+}
+
+// Tokens returns the latest csrf tokens recorded for the host of the input
+func (s *Store) Tokens(input string) []Token {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	hostTokens := s.tokens[wafdetect.HostKey(input)]
+	tokens := make([]Token, 0, len(hostTokens))
+	for _, token := range hostTokens {
+		tokens = append(tokens, token)
+	}
+	return tokens
+}
+
+// Inject injects the tokens recorded for the host of the input in a state
+// changing request, returning the new body. Header tokens are set with
+// setHeader, and form tokens are set in url encoded and json bodies. The
+// headers and fields already set by the template are kept.
+func (s *Store) Inject(input, method, body string, header func(key string) string, setHeader func(key, value string)) string {
+	if s == nil {
+		return body
+	}
+	if _, ok := stateChangingMethods[strings.ToUpper(method)]; !ok {
+		return body
+	}
+	contentType := strings.ToLower(strings.TrimSpace(header("Content-Type")))
+	form := strings.HasPrefix(contentType, "application/x-www-form-urlencoded")
+	json := strings.HasPrefix(contentType, "application/json") || strings.Contains(contentType, "+json")
+	for _, token := range s.Tokens(input) {
+		switch {
+		case token.Header:
+			if header(token.Name) == "" {
+				setHeader(token.Name, token.Value)
+			}
+		case form:
+			body = setFormValue(body, token.Name, token.Value)
+		case json:
+			body = setJSONValue(body, token.Name, token.Value)
+		}
+	}
+	return body
+}
+
+// setFormValue appends a field to a url encoded form body keeping the other
+// fields as they are. Bodies with the field are kept.
+func setFormValue(body, name, value string) string {
+	field := url.QueryEscape(name) + "=" + url.QueryEscape(value)
+	if body == "" {
+		return field
+	}
+	for _, part := range strings.Split(body, "&") {
+		key := part
+		if index := strings.Index(part, "="); index != -1 {
+			key = part[:index]
+		}
+		if unescaped, err := url.QueryUnescape(key); err == nil && unescaped == name {
+			return body
+		}
+	}
+	return body + "&" + field
+}
+
+// setJSONValue adds a field to a json object body keeping the other fields
+// as they are. Bodies with the field or which are not objects are kept.
+func setJSONValue(body, name, value string) string {
+	var fields map[string]jsoniter.RawMessage
+	if err := jsoniter.Unmarshal([]byte(body), &fields); err != nil || fields == nil {
+		return body
+	}
+	if _, ok := fields[name]; ok {
+		return body
+	}
+	key, _ := jsoniter.Marshal(name)
+	encoded, _ := jsoniter.Marshal(value)
+	field := string(key) + ":" + string(encoded)
+	if len(fields) > 0 {
+		field += ","
+	}
+	start := strings.Index(body, "{")
+	return body[:start+1] + field + body[start+1:]
+}
@@ -0,0 +1,53 @@
+package csrf
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestStoreInject(t *testing.T) {
+	store, err := New([]string{`data-token="([^"]+)"`})
+	require.Nil(t, err, "could not create store")
+
+	body := `<form><input type="hidden" name="authenticity_token" value="form-token"></form>
+<meta name="csrf-token" content="meta-token"><div data-token="custom-token"></div>`
+	headers := http.Header{"Set-Cookie": []string{"XSRF-TOKEN=cookie%3Dtoken; Path=/"}}
+	store.Record("https://example.com/login", headers, []byte(body))
+
+	setHeaders := http.Header{}
+	form := http.Header{"Content-Type": []string{"application/x-www-form-urlencoded"}}
+
+	injected := store.Inject("https://example.com:443/update", "POST", "a=1", form.Get, setHeaders.Set)
+	require.Equal(t, "a=1&authenticity_token=form-token", injected, "could not append form token")
+	require.Equal(t, "custom-token", setHeaders.Get(DefaultHeader), "could not set custom pattern token header")
+	require.Equal(t, "cookie=token", setHeaders.Get("X-XSRF-TOKEN"), "could not set cookie token header")
+
+	injected = store.Inject("https://example.com/update", "POST", "a=1&authenticity_token=template", form.Get, setHeaders.Set)
+	require.Equal(t, "a=1&authenticity_token=template", injected, "could replace form token set by template")
+
+	set := http.Header{"Content-Type": []string{"application/x-www-form-urlencoded"}, "X-Csrf-Token": []string{"template"}}
+	store.Inject("https://example.com/update", "POST", "", set.Get, set.Set)
+	require.Equal(t, "template", set.Get(DefaultHeader), "could replace header set by template")
+
+	json := http.Header{"Content-Type": []string{"application/json; charset=utf-8"}}
+	injected = store.Inject("https://example.com/update", "POST", `{"a":1}`, json.Get, setHeaders.Set)
+	require.Equal(t, `{"authenticity_token":"form-token","a":1}`, injected, "could not add token to json body")
+	injected = store.Inject("https://example.com/update", "POST", `{"authenticity_token":"template"}`, json.Get, setHeaders.Set)
+	require.Equal(t, `{"authenticity_token":"template"}`, injected, "could replace json token set by template")
+	injected = store.Inject("https://example.com/update", "POST", `[1]`, json.Get, setHeaders.Set)
+	require.Equal(t, `[1]`, injected, "could add token to json body without object")
+
+	setHeaders = http.Header{}
+	injected = store.Inject("https://example.com/", "GET", "", http.Header{}.Get, setHeaders.Set)
+	require.Empty(t, injected, "could inject token in get request")
+	require.Empty(t, setHeaders, "could set headers of get request")
+
+	require.Empty(t, store.Tokens("https://other.com/"), "could get tokens of other host")
+}
+
+func TestStoreInvalidPattern(t *testing.T) {
+	_, err := New([]string{`token=\w+`})
+	require.NotNil(t, err, "could create store with pattern without value group")
+}
@@ -1,11 +1,17 @@
 package executer
 
 import (
+	"fmt"
 	"strings"
+	"time"
 
 	"github.com/projectdiscovery/gologger"
+	"github.com/projectdiscovery/nuclei/v2/pkg/operators"
 	"github.com/projectdiscovery/nuclei/v2/pkg/output"
 	"github.com/projectdiscovery/nuclei/v2/pkg/protocols"
+	"github.com/projectdiscovery/nuclei/v2/pkg/protocols/common/interactsh"
+	"github.com/projectdiscovery/nuclei/v2/pkg/protocols/http"
+	"github.com/projectdiscovery/nuclei/v2/pkg/tracing"
 )
 
 // Executer executes a group of requests for a protocol
@@ -43,74 +49,258 @@ func (e *Executer) Requests() int {
 
 // Execute executes the protocol group and returns true or false if results were found.
 func (e *Executer) Execute(input string) (bool, error) {
+	var errored int
+	defer func(start time.Time) {
+		e.options.Profiler.Record(e.options.TemplateID, time.Since(start), errored)
+	}(time.Now())
+
+	span := e.options.Tracer.Start("template", nil, "template.id", e.options.TemplateID, "host", input)
+	defer span.End()
+
+	write := func(result *output.ResultEvent) {
+		e.writeResult(input, result)
+	}
+	if !e.Verified() {
+		var results bool
+		errored = e.execute(input, span, write, func(result *output.ResultEvent) {
+			results = true
+			write(result)
+		})
+		return results, nil
+	}
+
+	var found []*output.ResultEvent
+	errored = e.execute(input, span, write, func(result *output.ResultEvent) {
+		found = append(found, result)
+	})
+	if len(found) == 0 {
+		return false, nil
+	}
+
+	// Re-execute the requests with fresh values counting the runs
+	// each of the results is reproduced in.
+	reproduced := make(map[string]int)
+	for i := 0; i < e.options.Options.Verify && !e.options.Cancelled(); i++ {
+		matched := make(map[string]struct{})
+		runErrored := e.execute(input, span, func(*output.ResultEvent) {}, func(result *output.ResultEvent) {
+			matched[verifyKey(result)] = struct{}{}
+		})
+		errored += runErrored
+		for key := range matched {
+			reproduced[key]++
+		}
+	}
+
 	var results bool
+	for _, result := range found {
+		count := reproduced[verifyKey(result)]
+		if count == 0 {
+			gologger.Verbose().Msgf("[%s] Discarding unverified result for %s\n", e.options.TemplateID, input)
+			continue
+		}
+		results = true
+		result.Reproduced = fmt.Sprintf("%d/%d", count, e.options.Options.Verify)
+		write(result)
+	}
+	return results, nil
+}
 
-	dynamicValues := make(map[string]interface{})
-	previous := make(map[string]interface{})
+// execute executes the requests once for an input, calling onResult for each
+// result and global for the results of the global matchers. It returns the
+// number of requests failing to execute.
+func (e *Executer) execute(input string, span *tracing.Span, global, onResult func(*output.ResultEvent)) int {
+	var errored int
+	defer e.options.CookieJars.Release(input)
+
+	dynamicValues, previous, err := e.initialValues(input)
+	if err != nil {
+		gologger.Warning().Msgf("[%s] Could not execute template for %s: %s\n", e.options.TemplateID, input, err)
+		return 1
+	}
 	for _, req := range e.requests {
 		req := req
+		if e.options.Cancelled() {
+			break
+		}
 
+		protocolSpan := e.options.Tracer.Start("protocol", span, "template.id", e.options.TemplateID, "host", input, "protocol", protocolName(req))
+		e.takeRateLimit(req)
 		err := req.ExecuteWithResults(input, dynamicValues, previous, func(event *output.InternalWrappedEvent) {
-			ID := req.GetID()
-			if ID != "" {
-				builder := &strings.Builder{}
-				for k, v := range event.InternalEvent {
-					builder.WriteString(ID)
-					builder.WriteString("_")
-					builder.WriteString(k)
-					previous[builder.String()] = v
-					builder.Reset()
-				}
-			}
+			e.traceRequest(protocolSpan, input, req, event)
+			storePrevious(req.GetID(), event, previous)
+			e.options.ResponseStore.SaveEvent(input, e.options.TemplateID, event)
+			e.options.GlobalMatchers.Match(req, event, global)
 			if event.OperatorsResult == nil {
 				return
 			}
+			e.options.ContextStore.Set(input, event.OperatorsResult.StoredValues)
 			for _, result := range event.Results {
-				if e.options.IssuesClient != nil {
-					if err := e.options.IssuesClient.CreateIssue(result); err != nil {
-						gologger.Warning().Msgf("Could not create issue on tracker: %s", err)
-					}
-				}
-				results = true
-				_ = e.options.Output.Write(result)
-				e.options.Progress.IncrementMatched()
+				onResult(result)
 			}
 		})
+		protocolSpan.SetError(err)
+		protocolSpan.End()
 		if err != nil {
+			errored++
 			gologger.Warning().Msgf("[%s] Could not execute request for %s: %s\n", e.options.TemplateID, input, err)
 		}
 	}
-	return results, nil
+	return errored
+}
+
+// traceRequest records the span of the request sent for an event
+func (e *Executer) traceRequest(parent *tracing.Span, input string, req protocols.Request, event *output.InternalWrappedEvent) {
+	e.options.Tracer.StartAt("request", parent, event.Started, "template.id", e.options.TemplateID, "host", input, "protocol", protocolName(req)).End()
+}
+
+// Verified returns true if the findings of the executer are verified by
+// re-executing its requests.
+//
+// Out-of-band results are written by the interactsh client as the
+// interactions arrive, so they can't be correlated across runs.
+func (e *Executer) Verified() bool {
+	return e.options.Options.Verify > 0 && !e.hasInteractshMatchers()
+}
+
+// verifyKey returns the key identifying a result across verification runs.
+// The matched values are not part of the key as they differ between runs
+// with random values, such as random auto-variables and interactsh urls.
+func verifyKey(result *output.ResultEvent) string {
+	return strings.Join([]string{result.TemplateID, result.Host, result.MatcherName}, ":")
+}
+
+// compiledOperatorsRequest is implemented by the requests able to use interactsh
+type compiledOperatorsRequest interface {
+	GetCompiledOperators() *operators.Operators
+}
+
+// hasInteractshMatchers returns true if any request matches on interactions
+func (e *Executer) hasInteractshMatchers() bool {
+	for _, req := range e.requests {
+		if request, ok := req.(compiledOperatorsRequest); ok && interactsh.HasMatchers(request.GetCompiledOperators()) {
+			return true
+		}
+	}
+	return false
 }
 
 // ExecuteWithResults executes the protocol requests and returns results instead of writing them.
 func (e *Executer) ExecuteWithResults(input string, callback protocols.OutputEventCallback) error {
-	dynamicValues := make(map[string]interface{})
-	previous := make(map[string]interface{})
+	span := e.options.Tracer.Start("template", nil, "template.id", e.options.TemplateID, "host", input)
+	defer span.End()
+	defer e.options.CookieJars.Release(input)
+
+	dynamicValues, previous, err := e.initialValues(input)
+	if err != nil {
+		return err
+	}
 
 	for _, req := range e.requests {
 		req := req
+		if e.options.Cancelled() {
+			break
+		}
 
+		protocolSpan := e.options.Tracer.Start("protocol", span, "template.id", e.options.TemplateID, "host", input, "protocol", protocolName(req))
+		e.takeRateLimit(req)
 		err := req.ExecuteWithResults(input, dynamicValues, previous, func(event *output.InternalWrappedEvent) {
-			ID := req.GetID()
-			if ID != "" {
-				builder := &strings.Builder{}
-				for k, v := range event.InternalEvent {
-					builder.WriteString(ID)
-					builder.WriteString("_")
-					builder.WriteString(k)
-					previous[builder.String()] = v
-					builder.Reset()
-				}
+			e.traceRequest(protocolSpan, input, req, event)
+			storePrevious(req.GetID(), event, previous)
+			e.options.ResponseStore.SaveEvent(input, e.options.TemplateID, event)
+			e.options.GlobalMatchers.Match(req, event, func(result *output.ResultEvent) {
+				e.writeResult(input, result)
+			})
+			if e.options.EventHook != nil {
+				e.options.EventHook(req, event)
 			}
 			if event.OperatorsResult == nil {
 				return
 			}
+			e.options.ContextStore.Set(input, event.OperatorsResult.StoredValues)
 			callback(event)
 		})
+		protocolSpan.SetError(err)
+		protocolSpan.End()
 		if err != nil {
 			gologger.Warning().Msgf("[%s] Could not execute request for %s: %s\n", e.options.TemplateID, input, err)
 		}
 	}
 	return nil
 }
+
+// initialValues returns the dynamic values and previous events for an input,
+// seeded with the values stored for the host by the previous templates and
+// the template variables evaluated for the input.
+func (e *Executer) initialValues(input string) (map[string]interface{}, map[string]interface{}, error) {
+	dynamicValues := make(map[string]interface{})
+	previous := make(map[string]interface{})
+	for k, v := range e.options.ContextStore.Get(input) {
+		dynamicValues[k] = v
+		previous[k] = v
+	}
+	evaluated, err := e.options.Variables.Evaluate(input)
+	if err != nil {
+		return nil, nil, err
+	}
+	for k, v := range evaluated {
+		dynamicValues[k] = v
+		previous[k] = v
+	}
+	return dynamicValues, previous, nil
+}
+
+// takeRateLimit waits for the rate limiter before the requests of the protocols
+// not taking it for each of their requests, so pausing and changing the rate
+// of the scan with the control socket applies to all the protocols.
+func (e *Executer) takeRateLimit(req protocols.Request) {
+	if _, ok := req.(*http.Request); ok || e.options.RateLimiter == nil {
+		return
+	}
+	e.options.RateLimiter.Take()
+}
+
+// protocolName returns the name of the protocol of a request
+func protocolName(req protocols.Request) string {
+	name := strings.TrimPrefix(fmt.Sprintf("%T", req), "*")
+	return strings.TrimSuffix(name, ".Request")
+}
+
+// writeResult writes a result event of an input to the output and issue tracker
+func (e *Executer) writeResult(input string, result *output.ResultEvent) {
+	e.options.Workflow.Apply(result)
+	if !e.options.Triage.Apply(result) {
+		return
+	}
+	if e.options.IssuesClient != nil {
+		if err := e.options.IssuesClient.CreateIssue(result); err != nil {
+			gologger.Warning().Msgf("Could not create issue on tracker: %s", err)
+		}
+	}
+	_ = e.options.Output.Write(result)
+	e.options.Progress.IncrementMatched()
+	if e.options.ResultHook != nil {
+		e.options.ResultHook(input, result)
+	}
+}
+
+// storePrevious stores the values of an event for use in later requests.
+// Values are prefixed by the request ID if any and the names of the matched
+// matchers are stored as matcher_<name> variables.
+func storePrevious(ID string, event *output.InternalWrappedEvent, previous map[string]interface{}) {
+	if ID != "" {
+		builder := &strings.Builder{}
+		for k, v := range event.InternalEvent {
+			builder.WriteString(ID)
+			builder.WriteString("_")
+			builder.WriteString(k)
+			previous[builder.String()] = v
+			builder.Reset()
+		}
+	}
+	if event.OperatorsResult == nil {
+		return
+	}
+	for name := range event.OperatorsResult.Matches {
+		previous["matcher_"+name] = true
+	}
+}
@@ -0,0 +1,115 @@
+package executer
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/projectdiscovery/nuclei/v2/internal/testutils"
+	"github.com/projectdiscovery/nuclei/v2/pkg/operators"
+	"github.com/projectdiscovery/nuclei/v2/pkg/operators/extractors"
+	"github.com/projectdiscovery/nuclei/v2/pkg/operators/matchers"
+	"github.com/projectdiscovery/nuclei/v2/pkg/output"
+	"github.com/projectdiscovery/nuclei/v2/pkg/protocols"
+	"github.com/stretchr/testify/require"
+)
+
+// mockRequest returns the results of the run number of each execution
+type mockRequest struct {
+	runs      int
+	results   func(run int) []*output.ResultEvent
+	operators *operators.Operators
+}
+
+func (m *mockRequest) Compile(options *protocols.ExecuterOptions) error { return nil }
+func (m *mockRequest) Requests() int                                    { return 1 }
+func (m *mockRequest) GetID() string                                    { return "" }
+func (m *mockRequest) Match(data map[string]interface{}, matcher *matchers.Matcher) bool {
+	return false
+}
+func (m *mockRequest) Extract(data map[string]interface{}, extractor *extractors.Extractor) map[string]struct{} {
+	return nil
+}
+func (m *mockRequest) GetCompiledOperators() *operators.Operators { return m.operators }
+
+func (m *mockRequest) ExecuteWithResults(input string, dynamicValues, previous output.InternalEvent, callback protocols.OutputEventCallback) error {
+	m.runs++
+	callback(&output.InternalWrappedEvent{
+		InternalEvent:   output.InternalEvent{},
+		OperatorsResult: &operators.Result{},
+		Results:         m.results(m.runs),
+	})
+	return nil
+}
+
+func newVerifyExecuter(request *mockRequest, verify int) (*Executer, *[]*output.ResultEvent) {
+	options := *testutils.DefaultOptions
+	options.Verify = verify
+	executerOpts := testutils.NewMockExecuterOptions(&options, &testutils.TemplateInfo{ID: "verify-test"})
+
+	var written []*output.ResultEvent
+	writer := testutils.NewMockOutputWriter()
+	writer.WriteCallback = func(result *output.ResultEvent) {
+		written = append(written, result)
+	}
+	executerOpts.Output = writer
+	return NewExecuter([]protocols.Request{request}, executerOpts), &written
+}
+
+func TestExecuteVerify(t *testing.T) {
+	request := &mockRequest{results: func(run int) []*output.ResultEvent {
+		// The matched value contains random values differing for each run
+		results := []*output.ResultEvent{{Type: "http", Host: "https://example.com", MatcherName: "stable", Matched: fmt.Sprintf("https://example.com/?r=%d", run)}}
+		if run == 1 {
+			results = append(results, &output.ResultEvent{Type: "http", Host: "https://example.com", MatcherName: "flaky", Matched: "https://example.com/admin"})
+		}
+		if run != 2 {
+			results = append(results, &output.ResultEvent{Type: "http", Host: "https://example.com", MatcherName: "partial", Matched: "https://example.com/other"})
+		}
+		return results
+	}}
+	executer, written := newVerifyExecuter(request, 2)
+
+	found, err := executer.Execute("https://example.com")
+	require.Nil(t, err, "could not execute requests")
+	require.True(t, found, "could not get verified results")
+	require.Equal(t, 3, request.runs, "could not re-execute requests")
+	require.Len(t, *written, 2, "could not discard unverified result")
+	require.Equal(t, "https://example.com/?r=1", (*written)[0].Matched, "could not get verified result")
+	require.Equal(t, "2/2", (*written)[0].Reproduced, "could not verify result with random values")
+	require.Equal(t, "partial", (*written)[1].MatcherName, "could not key results by matcher name")
+	require.Equal(t, "1/2", (*written)[1].Reproduced, "could not count reproduced runs")
+}
+
+func TestExecuteVerifyInteractsh(t *testing.T) {
+	request := &mockRequest{
+		operators: &operators.Operators{Matchers: []*matchers.Matcher{{Type: "word", Part: "interactsh_protocol", Words: []string{"dns"}}}},
+		results: func(run int) []*output.ResultEvent {
+			return []*output.ResultEvent{{Type: "http", Host: "https://example.com", MatcherName: "status"}}
+		},
+	}
+	executer, written := newVerifyExecuter(request, 2)
+
+	found, err := executer.Execute("https://example.com")
+	require.Nil(t, err, "could not execute requests")
+	require.True(t, found, "could not get results")
+	require.Equal(t, 1, request.runs, "could not skip verification of interactsh template")
+	require.Len(t, *written, 1, "could not write result")
+	require.Empty(t, (*written)[0].Reproduced, "could not skip verification of interactsh template")
+}
+
+func TestExecuteCancelled(t *testing.T) {
+	request := &mockRequest{results: func(run int) []*output.ResultEvent {
+		return []*output.ResultEvent{{Type: "http", Host: "https://example.com"}}
+	}}
+	executer, written := newVerifyExecuter(request, 0)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	executer.options.Context = ctx
+
+	found, err := executer.Execute("https://example.com")
+	require.Nil(t, err, "could not execute requests")
+	require.False(t, found, "could get results of cancelled scan")
+	require.Equal(t, 0, request.runs, "could execute requests of cancelled scan")
+	require.Empty(t, *written, "could write results of cancelled scan")
+}
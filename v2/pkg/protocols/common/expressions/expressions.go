@@ -4,6 +4,7 @@ import (
 	"regexp"
 
 	"github.com/Knetic/govaluate"
+	"github.com/pkg/errors"
 	"github.com/projectdiscovery/nuclei/v2/pkg/operators/common/dsl"
 	"github.com/projectdiscovery/nuclei/v2/pkg/protocols/common/generators"
 	"github.com/projectdiscovery/nuclei/v2/pkg/protocols/common/replacer"
@@ -18,6 +19,17 @@ var templateExpressionRegex = regexp.MustCompile(`(?m)\{\{[^}]+\}\}["'\)\}]*`)
 // The provided keys from finalValues will be used as variable names
 // for substitution inside the expression.
 func Evaluate(data string, base map[string]interface{}) (string, error) {
+	return evaluate(data, base, false)
+}
+
+// EvaluateStrict evaluates the expressions like Evaluate but returns an error
+// for the expressions failing with all their variables known. Expressions
+// referencing unknown values are kept as they are for a later evaluation.
+func EvaluateStrict(data string, base map[string]interface{}) (string, error) {
+	return evaluate(data, base, true)
+}
+
+func evaluate(data string, base map[string]interface{}, strict bool) (string, error) {
 	data = replacer.Replace(data, base)
 
 	dynamicValues := make(map[string]interface{})
@@ -30,6 +42,9 @@ func Evaluate(data string, base map[string]interface{}) (string, error) {
 		}
 		result, err := compiled.Evaluate(base)
 		if err != nil {
+			if strict && hasVariables(compiled, base) {
+				return "", errors.Wrapf(err, "could not evaluate expression %s", expr)
+			}
 			continue
 		}
 		dynamicValues[expr] = result
@@ -37,3 +52,13 @@ func Evaluate(data string, base map[string]interface{}) (string, error) {
 	// Replacer dynamic values if any in raw request and parse  it
 	return replacer.Replace(data, dynamicValues), nil
 }
+
+// hasVariables returns true if all the variables of an expression are known
+func hasVariables(compiled *govaluate.EvaluableExpression, base map[string]interface{}) bool {
+	for _, name := range compiled.Vars() {
+		if _, ok := base[name]; !ok {
+			return false
+		}
+	}
+	return true
+}
@@ -26,3 +26,16 @@ func TestEvaluate(t *testing.T) {
 		require.Equal(t, item.expected, value, "could not get correct expression")
 	}
 }
+
+func TestEvaluateStrict(t *testing.T) {
+	value, err := EvaluateStrict("{{hex_encode(Item)}}/{{someTestData}}", map[string]interface{}{"Item": "PING"})
+	require.Nil(t, err, "could not evaluate expression with unknown values")
+	require.Equal(t, "50494e47/{{someTestData}}", value, "could not keep unknown values")
+
+	_, err = EvaluateStrict("{{Item * 2}}", map[string]interface{}{"Item": "PING"})
+	require.NotNil(t, err, "could evaluate invalid expression")
+
+	value, err = Evaluate("{{Item * 2}}", map[string]interface{}{"Item": "PING"})
+	require.Nil(t, err, "could not evaluate invalid expression leniently")
+	require.Equal(t, "{{Item * 2}}", value, "could not keep invalid expression")
+}
@@ -0,0 +1,241 @@
+package generators
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/pkg/errors"
+	"github.com/projectdiscovery/nuclei/v2/pkg/types"
+)
+
+// maxGeneratedPayloads is the maximum number of values a generated payload can have
+const maxGeneratedPayloads = 1000000
+
+// maxCasePermutationLength is the maximum length of a value to generate case permutations for
+const maxCasePermutationLength = 16
+
+// generatedPayload returns the declaration of a generated payload if the value is one.
+// Generated payloads are declared as a mapping with a type and its arguments
+// (eg. {type: range, from: 1, to: 100}) instead of a list or file.
+func generatedPayload(value interface{}) (map[string]interface{}, bool) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		return v, true
+	case map[interface{}]interface{}:
+		declaration := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			declaration[types.ToString(key)] = item
+		}
+		return declaration, true
+	}
+	return nil, false
+}
+
+// generatePayloads generates the values of a generated payload declaration.
+// The lookup function returns the values of other payloads for concatenations.
+func generatePayloads(declaration map[string]interface{}, lookup func(name string) ([]string, error)) ([]string, error) {
+	var values []string
+	var err error
+
+	switch generatorType := types.ToString(declaration["type"]); generatorType {
+	case "range":
+		values, err = generateRange(declaration)
+	case "date-range":
+		values, err = generateDateRange(declaration)
+	case "case":
+		values, err = generateCasePermutations(declaration)
+	case "concat":
+		values, err = generateConcat(declaration, lookup)
+	default:
+		return nil, errors.Errorf("invalid payload generator type %s (it should be range, date-range, case or concat)", generatorType)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return transformPayloads(values, types.ToStringSlice(declaration["transform"]))
+}
+
+// generateRange generates numbers from a start to an end with a step and optional format
+func generateRange(declaration map[string]interface{}) ([]string, error) {
+	from, to, step, err := rangeArguments(declaration)
+	if err != nil {
+		return nil, err
+	}
+	format := "%d"
+	if value, ok := declaration["format"]; ok {
+		format = types.ToString(value)
+	}
+	if (to-from)/step+1 > maxGeneratedPayloads {
+		return nil, errors.Errorf("range generates more than %d payloads", maxGeneratedPayloads)
+	}
+
+	var values []string
+	for i := from; (step > 0 && i <= to) || (step < 0 && i >= to); i += step {
+		values = append(values, fmt.Sprintf(format, i))
+	}
+	return values, nil
+}
+
+func rangeArguments(declaration map[string]interface{}) (from, to, step int, err error) {
+	if from, err = toInt(declaration["from"]); err != nil {
+		return 0, 0, 0, errors.Wrap(err, "invalid range start")
+	}
+	if to, err = toInt(declaration["to"]); err != nil {
+		return 0, 0, 0, errors.Wrap(err, "invalid range end")
+	}
+	step = 1
+	if to < from {
+		step = -1
+	}
+	if value, ok := declaration["step"]; ok {
+		if step, err = toInt(value); err != nil {
+			return 0, 0, 0, errors.Wrap(err, "invalid range step")
+		}
+	}
+	if step == 0 || (to-from)*step < 0 {
+		return 0, 0, 0, errors.New("range step does not reach the range end")
+	}
+	return from, to, step, nil
+}
+
+// generateDateRange generates the dates from a start to an end date with
+// a step in days, formatted with a go time layout (2006-01-02 by default).
+func generateDateRange(declaration map[string]interface{}) ([]string, error) {
+	from, err := time.Parse("2006-01-02", types.ToString(declaration["from"]))
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid date range start")
+	}
+	to, err := time.Parse("2006-01-02", types.ToString(declaration["to"]))
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid date range end")
+	}
+	step := 1
+	if value, ok := declaration["step"]; ok {
+		if step, err = toInt(value); err != nil || step <= 0 {
+			return nil, errors.New("invalid date range step")
+		}
+	}
+	format := "2006-01-02"
+	if value, ok := declaration["format"]; ok {
+		format = types.ToString(value)
+	}
+
+	var values []string
+	for date := from; !date.After(to); date = date.AddDate(0, 0, step) {
+		if len(values) >= maxGeneratedPayloads {
+			return nil, errors.Errorf("date range generates more than %d payloads", maxGeneratedPayloads)
+		}
+		values = append(values, date.Format(format))
+	}
+	return values, nil
+}
+
+// generateCasePermutations generates all the upper/lower case permutations of values
+func generateCasePermutations(declaration map[string]interface{}) ([]string, error) {
+	var values []string
+	for _, value := range types.ToStringSlice(declaration["values"]) {
+		if len(value) > maxCasePermutationLength {
+			return nil, errors.Errorf("value %s is too long for case permutations", value)
+		}
+		permutations := []string{""}
+		for _, char := range value {
+			lower, upper := string(unicode.ToLower(char)), string(unicode.ToUpper(char))
+
+			next := make([]string, 0, len(permutations)*2)
+			for _, permutation := range permutations {
+				next = append(next, permutation+lower)
+				if upper != lower {
+					next = append(next, permutation+upper)
+				}
+			}
+			permutations = next
+		}
+		values = append(values, permutations...)
+	}
+	return values, nil
+}
+
+// generateConcat generates the concatenations of all the combinations of other payloads
+func generateConcat(declaration map[string]interface{}, lookup func(name string) ([]string, error)) ([]string, error) {
+	names := types.ToStringSlice(declaration["payloads"])
+	if len(names) == 0 {
+		return nil, errors.New("no payloads to concat provided")
+	}
+	separator := types.ToString(declaration["separator"])
+
+	values := []string{""}
+	for i, name := range names {
+		items, err := lookup(name)
+		if err != nil {
+			return nil, err
+		}
+		if len(values)*len(items) > maxGeneratedPayloads {
+			return nil, errors.Errorf("concat generates more than %d payloads", maxGeneratedPayloads)
+		}
+		next := make([]string, 0, len(values)*len(items))
+		for _, value := range values {
+			for _, item := range items {
+				if i > 0 {
+					next = append(next, value+separator+item)
+				} else {
+					next = append(next, item)
+				}
+			}
+		}
+		values = next
+	}
+	return values, nil
+}
+
+// transformPayloads applies the transformations to the values in order
+func transformPayloads(values, transforms []string) ([]string, error) {
+	for _, transform := range transforms {
+		var fn func(string) string
+		switch transform {
+		case "lower":
+			fn = strings.ToLower
+		case "upper":
+			fn = strings.ToUpper
+		case "url":
+			fn = url.QueryEscape
+		case "base64":
+			fn = func(value string) string { return base64.StdEncoding.EncodeToString([]byte(value)) }
+		case "reverse":
+			fn = reverse
+		default:
+			return nil, errors.Errorf("invalid payload transform %s (it should be lower, upper, url, base64 or reverse)", transform)
+		}
+		for i, value := range values {
+			values[i] = fn(value)
+		}
+	}
+	return values, nil
+}
+
+func reverse(value string) string {
+	runes := []rune(value)
+	for i, j := 0, len(runes)-1; i < j; i, j = i+1, j-1 {
+		runes[i], runes[j] = runes[j], runes[i]
+	}
+	return string(runes)
+}
+
+func toInt(value interface{}) (int, error) {
+	switch v := value.(type) {
+	case int:
+		return v, nil
+	case float64:
+		return int(v), nil
+	case nil:
+		return 0, errors.New("no value provided")
+	}
+	var number int
+	if _, err := fmt.Sscanf(types.ToString(value), "%d", &number); err != nil {
+		return 0, err
+	}
+	return number, nil
+}
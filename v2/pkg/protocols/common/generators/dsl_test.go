@@ -0,0 +1,37 @@
+package generators
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGeneratedPayloads(t *testing.T) {
+	payloads := map[string]interface{}{
+		"ids":      map[interface{}]interface{}{"type": "range", "from": 8, "to": 12, "step": 2, "format": "%03d"},
+		"dates":    map[string]interface{}{"type": "date-range", "from": "2021-12-30", "to": "2022-01-02", "format": "20060102"},
+		"users":    map[interface{}]interface{}{"type": "case", "values": []interface{}{"ab"}},
+		"prefixes": []interface{}{"api", "v1"},
+		"pages":    map[interface{}]interface{}{"type": "range", "from": 8, "to": 12, "step": 2, "format": "%03d"},
+		"names":    map[interface{}]interface{}{"type": "case", "values": []interface{}{"ab"}},
+		"paths":    map[interface{}]interface{}{"type": "concat", "payloads": []interface{}{"prefixes", "pages"}, "separator": "/"},
+		"encoded":  map[interface{}]interface{}{"type": "concat", "payloads": "names", "transform": []interface{}{"upper", "base64"}},
+	}
+	loaded, err := loadPayloads(payloads)
+	require.Nil(t, err, "could not load generated payloads")
+
+	require.Equal(t, []string{"008", "010", "012"}, loaded["ids"], "could not generate range")
+	require.Equal(t, []string{"20211230", "20211231", "20220101", "20220102"}, loaded["dates"], "could not generate date range")
+	require.Equal(t, []string{"ab", "aB", "Ab", "AB"}, loaded["users"], "could not generate case permutations")
+	require.Equal(t, []string{"api/008", "api/010", "api/012", "v1/008", "v1/010", "v1/012"}, loaded["paths"], "could not generate concat")
+	require.Equal(t, []string{"QUI=", "QUI=", "QUI=", "QUI="}, loaded["encoded"], "could not transform payloads")
+	for _, source := range []string{"prefixes", "pages", "names"} {
+		require.NotContains(t, loaded, source, "could keep concat source as a payload")
+	}
+
+	_, err = loadPayloads(map[string]interface{}{"loop": map[interface{}]interface{}{"type": "concat", "payloads": "loop"}})
+	require.NotNil(t, err, "could generate self referencing payload")
+
+	_, err = loadPayloads(map[string]interface{}{"ids": map[interface{}]interface{}{"type": "range", "from": 1, "to": 10, "step": -1}})
+	require.NotNil(t, err, "could generate endless range")
+}
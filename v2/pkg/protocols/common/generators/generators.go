@@ -48,7 +48,7 @@ func New(payloads map[string]interface{}, payloadType Type, templatePath string)
 	// Validate the payload types
 	if payloadType == PitchFork {
 		var totalLength int
-		for v := range compiled {
+		for _, v := range compiled {
 			if totalLength != 0 && totalLength != len(v) {
 				return nil, errors.New("pitchfork payloads must be of equal number")
 			}
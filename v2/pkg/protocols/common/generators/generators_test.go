@@ -46,6 +46,29 @@ func TestPitchforkGenerator(t *testing.T) {
 	require.Equal(t, len(passwords), count, "could not get correct pitchfork counts")
 }
 
+func TestPitchforkGeneratorConcat(t *testing.T) {
+	payloads := map[string]interface{}{
+		"hosts":    []interface{}{"api", "www"},
+		"suffixes": []interface{}{"internal"},
+		"names":    map[interface{}]interface{}{"type": "concat", "payloads": []interface{}{"hosts", "suffixes"}, "separator": "."},
+		"tokens":   []interface{}{"a", "b"},
+	}
+	generator, err := New(payloads, PitchFork, "")
+	require.Nil(t, err, "could not create generator with concat")
+
+	iterator := generator.NewIterator()
+	var names []interface{}
+	for {
+		value, ok := iterator.Value()
+		if !ok {
+			break
+		}
+		require.Len(t, value, 2, "could iterate concat sources")
+		names = append(names, value["names"])
+	}
+	require.Equal(t, []interface{}{"api.internal", "www.internal"}, names, "could not get concat values")
+}
+
 func TestClusterbombGenerator(t *testing.T) {
 	usernames := []string{"admin"}
 	passwords := []string{"admin", "password", "token"}
@@ -79,3 +102,14 @@ func TestClusterbombGenerator(t *testing.T) {
 	}
 	require.Equal(t, 3, count, "could not get correct clusterbomb counts")
 }
+
+func TestRandomVariables(t *testing.T) {
+	variables := RandomVariables("/{{randstr}}/{{randstr_1}}", "{{randstr}}", "{{BaseURL}}")
+	require.Equal(t, []string{"randstr", "randstr_1"}, variables, "could not get correct random variables")
+
+	first := RandomValues(variables)
+	second := RandomValues(variables)
+	require.Len(t, first, 2, "could not get correct random values")
+	require.NotEqual(t, first["randstr"], second["randstr"], "could not get unique random values")
+	require.Nil(t, RandomValues(nil), "could not get nil values for no variables")
+}
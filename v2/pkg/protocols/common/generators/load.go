@@ -3,7 +3,9 @@ package generators
 import (
 	"bufio"
 	"io"
+	"io/ioutil"
 	"os"
+	"path/filepath"
 	"strings"
 
 	"github.com/pkg/errors"
@@ -14,7 +16,12 @@ import (
 func loadPayloads(payloads map[string]interface{}) (map[string][]string, error) {
 	loadedPayloads := make(map[string][]string)
 
+	generated := make(map[string]map[string]interface{})
 	for name, payload := range payloads {
+		if declaration, ok := generatedPayload(payload); ok {
+			generated[name] = declaration
+			continue
+		}
 		switch pt := payload.(type) {
 		case string:
 			elements := strings.Split(pt, "\n")
@@ -32,14 +39,60 @@ func loadPayloads(payloads map[string]interface{}) (map[string][]string, error)
 			loadedPayloads[name] = cast.ToStringSlice(pt)
 		}
 	}
+
+	// Generated payloads are evaluated last as concatenations can
+	// reference any other payloads, including generated ones.
+	generating := make(map[string]bool)
+	sources := make(map[string]struct{})
+	var lookup func(name string) ([]string, error)
+	lookup = func(name string) ([]string, error) {
+		if values, ok := loadedPayloads[name]; ok {
+			return values, nil
+		}
+		declaration, ok := generated[name]
+		if !ok {
+			return nil, errors.Errorf("payload %s does not exist", name)
+		}
+		if generating[name] {
+			return nil, errors.Errorf("payload %s references itself", name)
+		}
+		generating[name] = true
+		values, err := generatePayloads(declaration, func(source string) ([]string, error) {
+			sources[source] = struct{}{}
+			return lookup(source)
+		})
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not generate payload %s", name)
+		}
+		loadedPayloads[name] = values
+		return values, nil
+	}
+	for name := range generated {
+		if _, err := lookup(name); err != nil {
+			return nil, err
+		}
+	}
+	// The sources of concatenations are merged into them instead of
+	// being iterated on their own by the attack types.
+	for source := range sources {
+		delete(loadedPayloads, source)
+	}
 	return loadedPayloads, nil
 }
 
-// loadPayloadsFromFile loads a file to a string slice
-func loadPayloadsFromFile(filepath string) ([]string, error) {
+// loadPayloadsFromFile loads a file or all the files of a directory to a string slice
+func loadPayloadsFromFile(path string) ([]string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if info.IsDir() {
+		return loadPayloadsFromDirectory(path)
+	}
+
 	var lines []string
 
-	file, err := os.Open(filepath)
+	file, err := os.Open(path)
 	if err != nil {
 		return nil, err
 	}
@@ -58,3 +111,23 @@ func loadPayloadsFromFile(filepath string) ([]string, error) {
 	}
 	return lines, nil
 }
+
+// loadPayloadsFromDirectory loads the files of a directory in name order to a string slice
+func loadPayloadsFromDirectory(directory string) ([]string, error) {
+	files, err := ioutil.ReadDir(directory)
+	if err != nil {
+		return nil, err
+	}
+	var lines []string
+	for _, file := range files {
+		if file.IsDir() {
+			continue
+		}
+		fileLines, err := loadPayloadsFromFile(filepath.Join(directory, file.Name()))
+		if err != nil {
+			return nil, err
+		}
+		lines = append(lines, fileLines...)
+	}
+	return lines, nil
+}
@@ -0,0 +1,31 @@
+package generators
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadPayloadsFromDirectory(t *testing.T) {
+	directory, err := ioutil.TempDir("", "payloads-*")
+	require.Nil(t, err, "could not create temporary directory")
+	defer os.RemoveAll(directory)
+
+	err = ioutil.WriteFile(filepath.Join(directory, "a.txt"), []byte("admin\nroot\n"), 0644)
+	require.Nil(t, err, "could not write payload file")
+	err = ioutil.WriteFile(filepath.Join(directory, "b.txt"), []byte("guest\n"), 0644)
+	require.Nil(t, err, "could not write payload file")
+
+	payloads := map[string]interface{}{"users": directory, "passwords": []interface{}{"test", "12345"}}
+	generator := &Generator{}
+	err = generator.validate(payloads, "")
+	require.Nil(t, err, "could not validate payload directory")
+
+	loaded, err := loadPayloads(payloads)
+	require.Nil(t, err, "could not load payloads")
+	require.Equal(t, []string{"admin", "root", "guest"}, loaded["users"], "could not load payload directory")
+	require.Equal(t, []string{"test", "12345"}, loaded["passwords"], "could not load inline payloads")
+}
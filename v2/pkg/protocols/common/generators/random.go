@@ -0,0 +1,39 @@
+package generators
+
+import (
+	"regexp"
+
+	"github.com/segmentio/ksuid"
+)
+
+var randomVariableRegex = regexp.MustCompile(`\{\{(randstr(?:_[a-zA-Z0-9]+)?)\}\}`)
+
+// RandomVariables returns the unique random auto-variables (randstr, randstr_N)
+// referenced in the provided data.
+func RandomVariables(data ...string) []string {
+	var variables []string
+	found := make(map[string]struct{})
+
+	for _, item := range data {
+		for _, match := range randomVariableRegex.FindAllStringSubmatch(item, -1) {
+			if _, ok := found[match[1]]; ok {
+				continue
+			}
+			found[match[1]] = struct{}{}
+			variables = append(variables, match[1])
+		}
+	}
+	return variables
+}
+
+// RandomValues generates a new random value for each of the random variables.
+func RandomValues(variables []string) map[string]interface{} {
+	if len(variables) == 0 {
+		return nil
+	}
+	values := make(map[string]interface{}, len(variables))
+	for _, variable := range variables {
+		values[variable] = ksuid.New().String()
+	}
+	return values
+}
@@ -13,6 +13,10 @@ import (
 // validate validates the payloads if any.
 func (g *Generator) validate(payloads map[string]interface{}, templatePath string) error {
 	for name, payload := range payloads {
+		// generated payloads are validated when generating them
+		if _, ok := generatedPayload(payload); ok {
+			continue
+		}
 		switch pt := payload.(type) {
 		case string:
 			// check if it's a multiline string list
@@ -20,8 +24,8 @@ func (g *Generator) validate(payloads map[string]interface{}, templatePath strin
 				return errors.New("invalid number of lines in payload")
 			}
 
-			// check if it's a worldlist file and try to load it
-			if fileExists(pt) {
+			// check if it's a worldlist file or directory and try to load it
+			if pathExists(pt) {
 				continue
 			}
 
@@ -30,7 +34,7 @@ func (g *Generator) validate(payloads map[string]interface{}, templatePath strin
 
 			for i := range pathTokens {
 				tpath := path.Join(strings.Join(pathTokens[:i], "/"), pt)
-				if fileExists(tpath) {
+				if pathExists(tpath) {
 					payloads[name] = tpath
 					changed = true
 					break
@@ -51,14 +55,11 @@ func (g *Generator) validate(payloads map[string]interface{}, templatePath strin
 	return nil
 }
 
-// fileExists checks if a file exists and is not a directory
-func fileExists(filename string) bool {
+// pathExists checks if a file or directory exists
+func pathExists(filename string) bool {
 	info, err := os.Stat(filename)
 	if os.IsNotExist(err) {
 		return false
 	}
-	if info == nil {
-		return false
-	}
-	return !info.IsDir()
+	return info != nil
 }
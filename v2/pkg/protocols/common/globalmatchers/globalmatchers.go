@@ -0,0 +1,104 @@
+package globalmatchers
+
+import (
+	"reflect"
+	"sync"
+
+	"github.com/projectdiscovery/nuclei/v2/pkg/operators"
+	"github.com/projectdiscovery/nuclei/v2/pkg/operators/extractors"
+	"github.com/projectdiscovery/nuclei/v2/pkg/operators/matchers"
+	"github.com/projectdiscovery/nuclei/v2/pkg/output"
+)
+
+// Request is a protocol request whose operators are used as global matchers
+type Request interface {
+	// Match performs matching operation for a matcher on model and returns true or false.
+	Match(data map[string]interface{}, matcher *matchers.Matcher) bool
+	// Extract performs extracting operation for a extractor on model and returns true or false.
+	Extract(data map[string]interface{}, matcher *extractors.Extractor) map[string]struct{}
+	// MakeResultEvent creates a result event from internal wrapped event
+	MakeResultEvent(wrapped *output.InternalWrappedEvent) []*output.ResultEvent
+}
+
+// Storage contains the global matchers of templates flagged with
+// global-matchers which are evaluated on responses of all other templates.
+type Storage struct {
+	mutex sync.RWMutex
+	items []*item
+}
+
+// item is a single global matchers request along with its template metadata
+type item struct {
+	templateID   string
+	templatePath string
+	templateInfo map[string]interface{}
+	operators    *operators.Operators
+	request      Request
+	requestType  reflect.Type
+}
+
+// New creates a new global matchers storage
+func New() *Storage {
+	return &Storage{}
+}
+
+// Add adds the operators of a template request to the global matchers.
+func (s *Storage) Add(templateID, templatePath string, templateInfo map[string]interface{}, compiled *operators.Operators, request Request) {
+	if compiled == nil {
+		return
+	}
+	s.mutex.Lock()
+	s.items = append(s.items, &item{
+		templateID:   templateID,
+		templatePath: templatePath,
+		templateInfo: templateInfo,
+		operators:    compiled,
+		request:      request,
+		requestType:  reflect.TypeOf(request),
+	})
+	s.mutex.Unlock()
+}
+
+// HasMatchers returns true if any global matchers were added
+func (s *Storage) HasMatchers() bool {
+	if s == nil {
+		return false
+	}
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return len(s.items) > 0
+}
+
+// Match evaluates the global matchers of the same protocol as the source
+// request on an event, calling callback for each of the results found.
+func (s *Storage) Match(source interface{}, event *output.InternalWrappedEvent, callback func(result *output.ResultEvent)) {
+	if s == nil || event == nil || event.InternalEvent == nil {
+		return
+	}
+	sourceType := reflect.TypeOf(source)
+	sourceTemplateID, _ := event.InternalEvent["template-id"].(string)
+
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	for _, item := range s.items {
+		if item.requestType != sourceType || item.templateID == sourceTemplateID {
+			continue
+		}
+		data := make(output.InternalEvent, len(event.InternalEvent))
+		for k, v := range event.InternalEvent {
+			data[k] = v
+		}
+		data["template-id"] = item.templateID
+		data["template-path"] = item.templatePath
+		data["template-info"] = item.templateInfo
+
+		result, ok := item.operators.Execute(data, item.request.Match, item.request.Extract)
+		if !ok || result == nil {
+			continue
+		}
+		for _, value := range item.request.MakeResultEvent(&output.InternalWrappedEvent{InternalEvent: data, OperatorsResult: result}) {
+			callback(value)
+		}
+	}
+}
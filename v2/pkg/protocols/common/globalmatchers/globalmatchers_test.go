@@ -0,0 +1,58 @@
+package globalmatchers
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/projectdiscovery/nuclei/v2/pkg/operators"
+	"github.com/projectdiscovery/nuclei/v2/pkg/operators/extractors"
+	"github.com/projectdiscovery/nuclei/v2/pkg/operators/matchers"
+	"github.com/projectdiscovery/nuclei/v2/pkg/output"
+	"github.com/projectdiscovery/nuclei/v2/pkg/types"
+	"github.com/stretchr/testify/require"
+)
+
+type mockRequest struct{}
+
+func (m *mockRequest) Match(data map[string]interface{}, matcher *matchers.Matcher) bool {
+	return strings.Contains(types.ToString(data["body"]), matcher.Words[0])
+}
+
+func (m *mockRequest) Extract(data map[string]interface{}, extractor *extractors.Extractor) map[string]struct{} {
+	return nil
+}
+
+func (m *mockRequest) MakeResultEvent(wrapped *output.InternalWrappedEvent) []*output.ResultEvent {
+	return []*output.ResultEvent{{TemplateID: types.ToString(wrapped.InternalEvent["template-id"])}}
+}
+
+type otherRequest struct{ mockRequest }
+
+func TestStorageMatch(t *testing.T) {
+	compiled := &operators.Operators{Matchers: []*matchers.Matcher{{Type: "word", Words: []string{"stacktrace"}}}}
+	require.Nil(t, compiled.Compile(), "could not compile operators")
+
+	storage := New()
+	require.False(t, storage.HasMatchers(), "could get matchers for empty storage")
+	storage.Add("stacktrace-leak", "stacktrace-leak.yaml", map[string]interface{}{}, compiled, &mockRequest{})
+	require.True(t, storage.HasMatchers(), "could not get matchers for storage")
+
+	var results []*output.ResultEvent
+	callback := func(result *output.ResultEvent) {
+		results = append(results, result)
+	}
+	event := &output.InternalWrappedEvent{InternalEvent: map[string]interface{}{"template-id": "tech-detect", "body": "java stacktrace"}}
+
+	storage.Match(&mockRequest{}, event, callback)
+	require.Len(t, results, 1, "could not match global matchers")
+	require.Equal(t, "stacktrace-leak", results[0].TemplateID, "could not get global template id")
+	require.Equal(t, "tech-detect", event.InternalEvent["template-id"], "original event was modified")
+
+	results = nil
+	storage.Match(&otherRequest{}, event, callback)
+	require.Empty(t, results, "could match request of other protocol")
+
+	var nilStorage *Storage
+	nilStorage.Match(&mockRequest{}, event, callback)
+	require.Empty(t, results, "could match with nil storage")
+}
@@ -3,6 +3,7 @@ package interactsh
 import (
 	"net/url"
 	"strings"
+	"sync"
 	"sync/atomic"
 	"time"
 
@@ -15,6 +16,7 @@ import (
 	"github.com/projectdiscovery/nuclei/v2/pkg/output"
 	"github.com/projectdiscovery/nuclei/v2/pkg/progress"
 	"github.com/projectdiscovery/nuclei/v2/pkg/reporting"
+	"github.com/projectdiscovery/nuclei/v2/pkg/reporting/triage"
 	"github.com/valyala/fasttemplate"
 )
 
@@ -34,7 +36,8 @@ type Client struct {
 	cooldownDuration time.Duration
 
 	generated uint32 // decide to wait if we have a generated url
-	matched   bool
+	matched   uint32
+	pending   int64 // requests waiting for an interaction
 }
 
 var (
@@ -63,6 +66,10 @@ type Options struct {
 	IssuesClient *reporting.Client
 	// Progress is the nuclei progress bar implementation.
 	Progress progress.Progress
+	// Triage applies the severity overrides and suppressions of the triage file to the results
+	Triage *triage.Rules
+	// ResultHook is called with every result written for an input. (Optional)
+	ResultHook func(input string, result *output.ResultEvent)
 }
 
 const defaultMaxInteractionsCount = 5000
@@ -81,10 +88,6 @@ func New(options *Options) (*Client, error) {
 	if err != nil {
 		return nil, errors.Wrap(err, "could not create client")
 	}
-	configure := ccache.Configure()
-	configure = configure.MaxSize(options.CacheSize)
-	cache := ccache.New(configure)
-
 	interactionsCfg := ccache.Configure()
 	interactionsCfg = interactionsCfg.MaxSize(defaultMaxInteractionsCount)
 	interactionsCache := ccache.New(interactionsCfg)
@@ -95,10 +98,12 @@ func New(options *Options) (*Client, error) {
 		interactions:     interactionsCache,
 		dotHostname:      "." + parsed.Host,
 		options:          options,
-		requests:         cache,
 		pollDuration:     options.PollDuration,
 		cooldownDuration: options.ColldownPeriod,
 	}
+	configure := ccache.Configure()
+	configure = configure.MaxSize(options.CacheSize).OnDelete(interactClient.requestDeleted)
+	interactClient.requests = ccache.New(configure)
 
 	interactClient.interactsh.StartPolling(interactClient.pollDuration, func(interaction *server.Interaction) {
 		item := interactClient.requests.Get(interaction.UniqueID)
@@ -125,6 +130,14 @@ func New(options *Options) (*Client, error) {
 
 // processInteractionForRequest processes an interaction for a request
 func (c *Client) processInteractionForRequest(interaction *server.Interaction, data *RequestData) bool {
+	// Interactions can be processed concurrently by the poller and the
+	// request event, only the first match for a request is reported.
+	data.mutex.Lock()
+	defer data.mutex.Unlock()
+	if data.processed {
+		return true
+	}
+
 	data.Event.InternalEvent["interactsh_protocol"] = interaction.Protocol
 	data.Event.InternalEvent["interactsh_request"] = interaction.RawRequest
 	data.Event.InternalEvent["interactsh_response"] = interaction.RawResponse
@@ -133,6 +146,10 @@ func (c *Client) processInteractionForRequest(interaction *server.Interaction, d
 		return false // if we don't match, return
 	}
 	c.requests.Delete(interaction.UniqueID)
+	data.processed = true
+	if data.registered {
+		c.release(data)
+	}
 
 	if data.Event.OperatorsResult != nil {
 		data.Event.OperatorsResult.Merge(result)
@@ -143,10 +160,12 @@ func (c *Client) processInteractionForRequest(interaction *server.Interaction, d
 
 	for _, result := range data.Event.Results {
 		result.Interaction = interaction
-		_ = c.options.Output.Write(result)
-		if !c.matched {
-			c.matched = true
+		data.Workflow.Apply(result)
+		if !c.options.Triage.Apply(result) {
+			continue
 		}
+		_ = c.options.Output.Write(result)
+		atomic.StoreUint32(&c.matched, 1)
 		c.options.Progress.IncrementMatched()
 
 		if c.options.IssuesClient != nil {
@@ -154,6 +173,9 @@ func (c *Client) processInteractionForRequest(interaction *server.Interaction, d
 				gologger.Warning().Msgf("Could not create issue on tracker: %s", err)
 			}
 		}
+		if c.options.ResultHook != nil {
+			c.options.ResultHook(data.Input, result)
+		}
 	}
 	return true
 }
@@ -165,13 +187,32 @@ func (c *Client) URL() string {
 }
 
 // Close closes the interactsh clients after waiting for cooldown period.
+//
+// An additional poll interval is waited after the cooldown so interactions
+// received at the end of the cooldown are processed before polling stops.
 func (c *Client) Close() bool {
 	if c.cooldownDuration > 0 && atomic.LoadUint32(&c.generated) == 1 {
-		time.Sleep(c.cooldownDuration)
+		time.Sleep(c.cooldownDuration + c.pollDuration)
 	}
 	c.interactsh.StopPolling()
 	c.interactsh.Close()
-	return c.matched
+
+	if pending := atomic.LoadInt64(&c.pending); pending > 0 {
+		gologger.Verbose().Msgf("No interactions received for %d requests after cooldown", pending)
+	}
+	return atomic.LoadUint32(&c.matched) == 1
+}
+
+// WaitPending waits for the interactions of the pending requests for up to
+// the cooldown period, so that their results are written before returning.
+func (c *Client) WaitPending() {
+	if atomic.LoadUint32(&c.generated) == 0 {
+		return
+	}
+	deadline := time.Now().Add(c.cooldownDuration + c.pollDuration)
+	for atomic.LoadInt64(&c.pending) > 0 && time.Now().Before(deadline) {
+		time.Sleep(100 * time.Millisecond)
+	}
 }
 
 // ReplaceMarkers replaces the {{interactsh-url}} placeholders to actual
@@ -199,6 +240,15 @@ type RequestData struct {
 	Operators      *operators.Operators
 	MatchFunc      operators.MatchFunc
 	ExtractFunc    operators.ExtractFunc
+	// Workflow is the workflow running the template of the request if any
+	Workflow *output.Workflow
+	// Input is the input the request was sent to
+	Input string
+
+	mutex      sync.Mutex
+	processed  bool
+	registered bool
+	released   uint32
 }
 
 // RequestEvent is the event for a network request sent by nuclei.
@@ -210,7 +260,7 @@ func (c *Client) RequestEvent(interactshURL string, data *RequestData) {
 		// If we have previous interactions, get them and process them.
 		interactions, ok := interaction.Value().([]*server.Interaction)
 		if !ok {
-			c.requests.Set(id, data, c.eviction)
+			c.registerRequest(id, data)
 			return
 		}
 		matched := false
@@ -222,9 +272,34 @@ func (c *Client) RequestEvent(interactshURL string, data *RequestData) {
 		}
 		if matched {
 			c.interactions.Delete(id)
+			return
 		}
-	} else {
-		c.requests.Set(id, data, c.eviction)
+	}
+	c.registerRequest(id, data)
+}
+
+// registerRequest stores the request to be matched with later interactions
+func (c *Client) registerRequest(id string, data *RequestData) {
+	data.mutex.Lock()
+	data.registered = true
+	data.mutex.Unlock()
+
+	atomic.AddInt64(&c.pending, 1)
+	c.requests.Set(id, data, c.eviction)
+}
+
+// requestDeleted releases the requests deleted from the cache, either
+// matched, replaced or evicted when the cache is full.
+func (c *Client) requestDeleted(item *ccache.Item) {
+	if data, ok := item.Value().(*RequestData); ok {
+		c.release(data)
+	}
+}
+
+// release decrements the pending requests once for a registered request
+func (c *Client) release(data *RequestData) {
+	if atomic.CompareAndSwapUint32(&data.released, 0, 1) {
+		atomic.AddInt64(&c.pending, -1)
 	}
 }
 
@@ -0,0 +1,86 @@
+package interactsh
+
+import (
+	"fmt"
+	"sync/atomic"
+	"testing"
+
+	"github.com/karlseguin/ccache"
+	"github.com/projectdiscovery/interactsh/pkg/server"
+	"github.com/projectdiscovery/nuclei/v2/pkg/operators"
+	"github.com/projectdiscovery/nuclei/v2/pkg/operators/matchers"
+	"github.com/projectdiscovery/nuclei/v2/pkg/output"
+	"github.com/projectdiscovery/nuclei/v2/pkg/progress"
+	"github.com/projectdiscovery/nuclei/v2/pkg/types"
+	"github.com/stretchr/testify/require"
+)
+
+type mockWriter struct {
+	output.Writer
+	events []*output.ResultEvent
+}
+
+func (m *mockWriter) Write(event *output.ResultEvent) error {
+	m.events = append(m.events, event)
+	return nil
+}
+
+func newTestClient(size int64) *Client {
+	client := &Client{}
+	client.requests = ccache.New(ccache.Configure().MaxSize(size).ItemsToPrune(1).OnDelete(client.requestDeleted))
+	return client
+}
+
+func TestPendingRequestsEviction(t *testing.T) {
+	client := newTestClient(2)
+	for i := 0; i < 5; i++ {
+		client.registerRequest(fmt.Sprintf("request-%d", i), &RequestData{})
+	}
+	client.requests.Stop()
+
+	require.Equal(t, int64(2), atomic.LoadInt64(&client.pending), "could not release evicted requests")
+}
+
+func TestPendingRequestsRelease(t *testing.T) {
+	client := newTestClient(10)
+	data := &RequestData{}
+	client.registerRequest("request", data)
+	client.registerRequest("other", &RequestData{})
+
+	// Matched requests are released and deleted from the cache
+	client.release(data)
+	client.requests.Delete("request")
+	client.requests.Stop()
+
+	require.Equal(t, int64(1), atomic.LoadInt64(&client.pending), "could not release matched request once")
+}
+
+func TestInteractionWorkflowInheritance(t *testing.T) {
+	writer := &mockWriter{}
+	progress, err := progress.NewStatsTicker(0, false, false, 0)
+	require.Nil(t, err, "could not create progress")
+	progress.Init(0, 0, 0)
+
+	client := newTestClient(10)
+	client.options = &Options{Output: writer, Progress: progress}
+	defer client.requests.Stop()
+
+	matcher := &matchers.Matcher{Type: "word", Part: "interactsh_protocol", Words: []string{"dns"}}
+	require.Nil(t, matcher.CompileMatchers(), "could not compile matcher")
+	data := &RequestData{
+		Event:     &output.InternalWrappedEvent{InternalEvent: output.InternalEvent{}},
+		Operators: &operators.Operators{Matchers: []*matchers.Matcher{matcher}},
+		MatchFunc: func(data map[string]interface{}, matcher *matchers.Matcher) bool {
+			return matcher.MatchWords(types.ToString(data[matcher.Part]))
+		},
+		MakeResultFunc: func(wrapped *output.InternalWrappedEvent) []*output.ResultEvent {
+			return []*output.ResultEvent{{TemplateID: "oob", Info: map[string]interface{}{"severity": "low"}}}
+		},
+		Workflow: output.NewWorkflow("oob-workflow", map[string]interface{}{"severity": "high"}),
+	}
+	require.True(t, client.processInteractionForRequest(&server.Interaction{Protocol: "dns", UniqueID: "request"}, data), "could not match interaction")
+
+	require.Len(t, writer.events, 1, "could not write interaction result")
+	require.Equal(t, "oob-workflow", writer.events[0].WorkflowID, "could not attach workflow to interaction result")
+	require.Equal(t, "high", writer.events[0].Info["severity"], "could not inherit workflow severity")
+}
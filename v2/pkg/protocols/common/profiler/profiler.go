@@ -0,0 +1,122 @@
+// Package profiler records the time, requests and errors of each template
+// to identify slow or broken templates.
+package profiler
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/projectdiscovery/nuclei/v2/pkg/output"
+)
+
+// Profiler records the execution statistics of the templates
+type Profiler struct {
+	mutex sync.Mutex
+	stats map[string]*Stats
+}
+
+// Stats are the execution statistics of a template
+type Stats struct {
+	TemplateID string
+	// Executions is the number of inputs the template was executed on
+	Executions int
+	// Duration is the total time spent executing the template
+	Duration time.Duration
+	// Requests is the number of requests sent by the template, retries included
+	Requests int
+	Errors   int
+}
+
+// New creates a new template profiler
+func New() *Profiler {
+	return &Profiler{stats: make(map[string]*Stats)}
+}
+
+// Record records an execution of the template. It is a no-op on nil profilers.
+func (p *Profiler) Record(templateID string, duration time.Duration, errors int) {
+	if p == nil {
+		return
+	}
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	stats := p.get(templateID)
+	stats.Executions++
+	stats.Duration += duration
+	stats.Errors += errors
+}
+
+// Request records a request sent by the template. It is a no-op on nil profilers.
+func (p *Profiler) Request(templateID string) {
+	if p == nil {
+		return
+	}
+	p.mutex.Lock()
+	p.get(templateID).Requests++
+	p.mutex.Unlock()
+}
+
+// get returns the statistics of a template, creating them if needed
+func (p *Profiler) get(templateID string) *Stats {
+	stats, ok := p.stats[templateID]
+	if !ok {
+		stats = &Stats{TemplateID: templateID}
+		p.stats[templateID] = stats
+	}
+	return stats
+}
+
+// Stats returns the statistics of the templates sorted by the time spent
+func (p *Profiler) Stats() []*Stats {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	stats := make([]*Stats, 0, len(p.stats))
+	for _, stat := range p.stats {
+		copied := *stat
+		stats = append(stats, &copied)
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].Duration == stats[j].Duration {
+			return stats[i].TemplateID < stats[j].TemplateID
+		}
+		return stats[i].Duration > stats[j].Duration
+	})
+	return stats
+}
+
+// Print writes a report of the template statistics sorted by time spent
+func (p *Profiler) Print(writer io.Writer) {
+	w := tabwriter.NewWriter(writer, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "TEMPLATE\tTIME\tAVG TIME\tEXECUTIONS\tREQUESTS\tERRORS")
+	for _, stat := range p.Stats() {
+		var average time.Duration
+		if stat.Executions > 0 {
+			average = stat.Duration / time.Duration(stat.Executions)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%d\t%d\n", stat.TemplateID, stat.Duration.Round(time.Millisecond), average.Round(time.Millisecond), stat.Executions, stat.Requests, stat.Errors)
+	}
+	w.Flush()
+}
+
+// Writer is an output writer counting the requests sent by the templates.
+// All the protocols log each request they send, retries included.
+type Writer struct {
+	output.Writer
+	profiler *Profiler
+}
+
+// NewWriter creates a new writer counting the requests in a profiler
+func NewWriter(writer output.Writer, profiler *Profiler) *Writer {
+	return &Writer{Writer: writer, profiler: profiler}
+}
+
+// Request counts the request of the template and logs it with the wrapped writer
+func (w *Writer) Request(templateID, url, requestType string, err error) {
+	w.profiler.Request(templateID)
+	w.Writer.Request(templateID, url, requestType, err)
+}
@@ -0,0 +1,49 @@
+package profiler
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/projectdiscovery/nuclei/v2/pkg/output"
+	"github.com/stretchr/testify/require"
+)
+
+type requestWriter struct {
+	output.Writer
+}
+
+func (r *requestWriter) Request(templateID, url, requestType string, err error) {}
+
+func TestProfiler(t *testing.T) {
+	profiler := New()
+	profiler.Record("fast", 10*time.Millisecond, 0)
+	profiler.Record("slow", 2*time.Second, 1)
+	profiler.Record("slow", 1*time.Second, 2)
+	writer := NewWriter(&requestWriter{}, profiler)
+	writer.Request("fast", "https://example.com", "http", nil)
+	for i := 0; i < 10; i++ {
+		writer.Request("slow", "https://example.com", "http", errors.New("timeout"))
+	}
+
+	stats := profiler.Stats()
+	require.Len(t, stats, 2, "could not get template stats")
+	require.Equal(t, &Stats{TemplateID: "slow", Executions: 2, Duration: 3 * time.Second, Requests: 10, Errors: 3}, stats[0], "could not get slowest template first")
+	require.Equal(t, "fast", stats[1].TemplateID, "could not get fastest template last")
+
+	builder := &strings.Builder{}
+	profiler.Print(builder)
+	lines := strings.Split(strings.TrimSpace(builder.String()), "\n")
+	require.Len(t, lines, 3, "could not print report")
+	require.True(t, strings.HasPrefix(lines[1], "slow "), "could not print sorted report")
+
+	profiler.Request("workflow")
+	builder.Reset()
+	profiler.Print(builder)
+	require.Contains(t, builder.String(), "workflow", "could not print template without executions")
+
+	var nilProfiler *Profiler
+	nilProfiler.Record("test", time.Second, 0)
+	nilProfiler.Request("test")
+}
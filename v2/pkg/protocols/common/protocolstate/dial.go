@@ -0,0 +1,39 @@
+package protocolstate
+
+import (
+	"context"
+	"net"
+	"net/url"
+	"strings"
+
+	"github.com/projectdiscovery/fastdialer/fastdialer"
+)
+
+// Dial connects to a tcp address with the shared resolver if configured
+// or else with the dialer, over tls if asked.
+func Dial(ctx context.Context, dialer *fastdialer.Dialer, address string, useTLS bool) (net.Conn, error) {
+	if Resolver != nil {
+		if useTLS {
+			return Resolver.DialTLS(ctx, "tcp", address)
+		}
+		return Resolver.Dial(ctx, "tcp", address)
+	}
+	if useTLS {
+		return dialer.DialTLS(ctx, "tcp", address)
+	}
+	return dialer.Dial(ctx, "tcp", address)
+}
+
+// HostPort returns the host and the optional port of an input. The port
+// of urls is ignored as it is the port of the url scheme.
+func HostPort(input string) (string, string) {
+	if strings.Contains(input, "://") {
+		if parsed, err := url.Parse(input); err == nil {
+			return parsed.Hostname(), ""
+		}
+	}
+	if host, port, err := net.SplitHostPort(input); err == nil {
+		return host, port
+	}
+	return input, ""
+}
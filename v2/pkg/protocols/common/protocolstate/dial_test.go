@@ -0,0 +1,25 @@
+package protocolstate
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestHostPort(t *testing.T) {
+	host, port := HostPort("https://example.com:8443/path")
+	require.Equal(t, "example.com", host, "could not get host of url")
+	require.Equal(t, "", port, "could get port of url")
+
+	host, port = HostPort("example.com:25")
+	require.Equal(t, "example.com", host, "could not get host of address")
+	require.Equal(t, "25", port, "could not get port of address")
+
+	host, port = HostPort("[::1]:22")
+	require.Equal(t, "::1", host, "could not get host of ipv6 address")
+	require.Equal(t, "22", port, "could not get port of ipv6 address")
+
+	host, port = HostPort("example.com")
+	require.Equal(t, "example.com", host, "could not get host")
+	require.Equal(t, "", port, "could get port without port")
+}
@@ -0,0 +1,88 @@
+package protocolstate
+
+import (
+	"context"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/projectdiscovery/nuclei/v2/pkg/protocols/common/resolver"
+)
+
+// sourceAddress returns the local address the connections originate from.
+// The first ipv4 address of the interface is used if no source ip is
+// provided, and nil is returned if neither of them are configured.
+func sourceAddress(iface, sourceIP string) (net.IP, error) {
+	var ip net.IP
+	if sourceIP != "" {
+		if ip = net.ParseIP(sourceIP); ip == nil {
+			return nil, errors.Errorf("invalid source ip %s", sourceIP)
+		}
+	}
+	if iface == "" {
+		return ip, nil
+	}
+
+	netInterface, err := net.InterfaceByName(iface)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not get interface %s", iface)
+	}
+	addrs, err := netInterface.Addrs()
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not get addresses of interface %s", iface)
+	}
+	var candidates []net.IP
+	for _, addr := range addrs {
+		if ipNet, ok := addr.(*net.IPNet); ok {
+			candidates = append(candidates, ipNet.IP)
+		}
+	}
+	for _, candidate := range candidates {
+		if ip != nil && candidate.Equal(ip) {
+			return ip, nil
+		}
+	}
+	if ip != nil {
+		return nil, errors.Errorf("source ip %s is not an address of interface %s", sourceIP, iface)
+	}
+	for _, candidate := range candidates {
+		if candidate.To4() != nil {
+			return candidate, nil
+		}
+	}
+	if len(candidates) > 0 {
+		return candidates[0], nil
+	}
+	return nil, errors.Errorf("no address found for interface %s", iface)
+}
+
+// sourceDial returns a dial function binding the connections to the source ip
+func sourceDial(ip net.IP, timeout time.Duration) resolver.DialFunc {
+	return func(ctx context.Context, network, address string) (net.Conn, error) {
+		return SourceDialer(ip, network, timeout).DialContext(ctx, network, address)
+	}
+}
+
+// SourceDialer returns a dialer binding the connections of a network to
+// the source ip. The local address is left unset if the ip is nil.
+func SourceDialer(ip net.IP, network string, timeout time.Duration) *net.Dialer {
+	dialer := &net.Dialer{Timeout: timeout}
+	if ip == nil {
+		return dialer
+	}
+	if strings.HasPrefix(network, "udp") {
+		dialer.LocalAddr = &net.UDPAddr{IP: ip}
+	} else {
+		dialer.LocalAddr = &net.TCPAddr{IP: ip}
+	}
+	return dialer
+}
+
+// ipVersion returns the version (4 or 6) of an ip address
+func ipVersion(ip net.IP) string {
+	if ip.To4() != nil {
+		return "4"
+	}
+	return "6"
+}
@@ -0,0 +1,49 @@
+package protocolstate
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSourceAddress(t *testing.T) {
+	ip, err := sourceAddress("", "")
+	require.Nil(t, err, "could not get empty source address")
+	require.Nil(t, ip, "could get source address without configuration")
+
+	ip, err = sourceAddress("", "127.0.0.1")
+	require.Nil(t, err, "could not get source ip")
+	require.Equal(t, "127.0.0.1", ip.String(), "could not get correct source ip")
+
+	_, err = sourceAddress("", "invalid")
+	require.NotNil(t, err, "could use invalid source ip")
+
+	_, err = sourceAddress("nonexistent-interface0", "")
+	require.NotNil(t, err, "could use unknown interface")
+}
+
+func TestSourceDial(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.Nil(t, err, "could not listen")
+	defer listener.Close()
+
+	go func() {
+		if conn, err := listener.Accept(); err == nil {
+			conn.Close()
+		}
+	}()
+
+	conn, err := sourceDial(net.ParseIP("127.0.0.1"), time.Second)(context.Background(), "tcp", listener.Addr().String())
+	require.Nil(t, err, "could not dial from source ip")
+	defer conn.Close()
+	require.Equal(t, "127.0.0.1", conn.LocalAddr().(*net.TCPAddr).IP.String(), "could not bind source ip")
+}
+
+func TestSourceDialer(t *testing.T) {
+	require.Nil(t, SourceDialer(nil, "udp", time.Second).LocalAddr, "could set local address without source ip")
+	require.Equal(t, &net.UDPAddr{IP: net.ParseIP("127.0.0.1")}, SourceDialer(net.ParseIP("127.0.0.1"), "udp", time.Second).LocalAddr, "could not bind udp source ip")
+	require.Equal(t, &net.TCPAddr{IP: net.ParseIP("127.0.0.1")}, SourceDialer(net.ParseIP("127.0.0.1"), "tcp", time.Second).LocalAddr, "could not bind tcp source ip")
+}
@@ -1,21 +1,39 @@
 package protocolstate
 
 import (
+	"net"
+	"strings"
+	"time"
+
 	"github.com/pkg/errors"
 	"github.com/projectdiscovery/fastdialer/fastdialer"
+	"github.com/projectdiscovery/nuclei/v2/pkg/protocols/common/resolver"
+	"github.com/projectdiscovery/nuclei/v2/pkg/protocols/dns/dnsdataset"
 	"github.com/projectdiscovery/nuclei/v2/pkg/types"
 )
 
 // Dialer is a shared fastdialer instance for host DNS resolution
 var Dialer *fastdialer.Dialer
 
+// Resolver is a shared resolver for static host mappings and DNS-over-HTTPS.
+// It is nil if neither of them are configured.
+var Resolver *resolver.Resolver
+
+// SourceIP is the local address the connections originate from. It is
+// nil if neither an interface nor a source ip are configured.
+var SourceIP net.IP
+
+// DNSDataset answers the dns requests of the templates without live
+// queries. It is nil if no dataset is configured.
+var DNSDataset *dnsdataset.Dataset
+
 // Init creates the Dialer instance based on user configuration
 func Init(options *types.Options) error {
 	opts := fastdialer.DefaultOptions
 	if options.SystemResolvers {
 		opts.EnableFallback = true
 	}
-	if options.ResolversFile != "" {
+	if options.ResolversFile != "" && len(options.InternalResolversList) > 0 {
 		opts.BaseResolvers = options.InternalResolversList
 	}
 	dialer, err := fastdialer.NewDialer(opts)
@@ -23,9 +41,82 @@ func Init(options *types.Options) error {
 		return errors.Wrap(err, "could not create dialer")
 	}
 	Dialer = dialer
+
+	if options.DNSDataset != "" {
+		dataset, err := dnsdataset.Load(options.DNSDataset)
+		if err != nil {
+			return err
+		}
+		DNSDataset = dataset
+	}
+
+	sourceIP, err := sourceAddress(options.Interface, options.SourceIP)
+	if err != nil {
+		return err
+	}
+	SourceIP = sourceIP
+
+	if options.HostsFile != "" || len(options.InternalDoHResolvers) > 0 || len(options.IPVersion) > 0 || sourceIP != nil {
+		resolverOptions := &resolver.Options{
+			DoHServers: options.InternalDoHResolvers,
+			IPVersion:  options.IPVersion,
+			Timeout:    time.Duration(options.Timeout) * time.Second,
+			Dial:       dialer.Dial,
+			LookupHost: LookupHost,
+		}
+		// The connections are bound to the source address, dialing the
+		// addresses of the same ip version first.
+		if sourceIP != nil {
+			resolverOptions.Dial = sourceDial(sourceIP, time.Duration(options.Timeout)*time.Second)
+			resolverOptions.PreferIPVersion = ipVersion(sourceIP)
+		}
+		if options.HostsFile != "" {
+			hosts, err := resolver.ParseHostsFile(options.HostsFile)
+			if err != nil {
+				return errors.Wrap(err, "could not parse hosts file")
+			}
+			resolverOptions.Hosts = hosts
+		}
+		Resolver = resolver.New(resolverOptions)
+	}
 	return nil
 }
 
+// ResolveIP returns the preferred ip address for a host, honoring the static
+// mappings and ip version preferences. An empty string is returned on failure.
+func ResolveIP(host string) string {
+	if ip := net.ParseIP(strings.Trim(host, "[]")); ip != nil {
+		return ip.String()
+	}
+	if Resolver != nil {
+		if ips, err := Resolver.Lookup(host); err == nil && len(ips) > 0 {
+			return ips[0]
+		}
+	}
+	addrs, err := LookupHost(host)
+	if err != nil {
+		return ""
+	}
+	return addrs[0]
+}
+
+// LookupHost returns the addresses of a host resolved with the resolvers
+// of the dialer, the ipv4 addresses being returned first.
+func LookupHost(host string) ([]string, error) {
+	if Dialer == nil {
+		return nil, errors.New("dialer is not initialized")
+	}
+	data, err := Dialer.GetDNSData(host)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not resolve host")
+	}
+	addrs := append(append([]string{}, data.A...), data.AAAA...)
+	if len(addrs) == 0 {
+		return nil, errors.Errorf("no address found for %s", host)
+	}
+	return addrs, nil
+}
+
 // Close closes the global shared fastdialer
 func Close() {
 	if Dialer != nil {
@@ -1,6 +1,7 @@
 package replacer
 
 import (
+	"regexp"
 	"strings"
 
 	"github.com/projectdiscovery/nuclei/v2/pkg/types"
@@ -13,6 +14,28 @@ const (
 	MarkerParenthesisClose = "}}"
 )
 
+var placeholderRegex = regexp.MustCompile(`\{\{([a-zA-Z0-9_-]+)\}\}`)
+
+// Placeholders returns the unique names of the {{name}} placeholders in the data.
+func Placeholders(data ...string) []string {
+	var names []string
+	found := make(map[string]struct{})
+
+	for _, item := range data {
+		if !strings.Contains(item, MarkerParenthesisOpen) {
+			continue
+		}
+		for _, match := range placeholderRegex.FindAllStringSubmatch(item, -1) {
+			if _, ok := found[match[1]]; ok {
+				continue
+			}
+			found[match[1]] = struct{}{}
+			names = append(names, match[1])
+		}
+	}
+	return names
+}
+
 // Replace replaces placeholders in template with values on the fly.
 func Replace(template string, values map[string]interface{}) string {
 	var replacerItems []string
@@ -0,0 +1,367 @@
+package resolver
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/tls"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/miekg/dns"
+	"github.com/pkg/errors"
+)
+
+// DialFunc is a function used to dial a network address
+type DialFunc func(ctx context.Context, network, address string) (net.Conn, error)
+
+// LookupFunc is a function returning the addresses of a host
+type LookupFunc func(host string) ([]string, error)
+
+// Resolver is a shared resolver for all protocols providing static
+// host mappings and DNS-over-HTTPS resolution on top of the standard dialer.
+type Resolver struct {
+	hosts      map[string][]string
+	dohServers []string
+	ipVersion  []string
+	preferIP   string
+	httpClient *http.Client
+	dial       DialFunc
+	lookupHost LookupFunc
+
+	cacheMutex sync.RWMutex
+	cache      map[string][]string
+}
+
+// Options contains configuration options for the resolver
+type Options struct {
+	// Hosts contains static host to ip mappings
+	Hosts map[string][]string
+	// DoHServers contains DNS-over-HTTPS server URLs
+	DoHServers []string
+	// IPVersion is the ordered list of allowed ip versions (4, 6) for
+	// resolved addresses. All versions are allowed if empty.
+	IPVersion []string
+	// PreferIPVersion is the ip version (4 or 6) whose addresses are dialed
+	// first, keeping the addresses of the other version. It is ignored if
+	// IPVersion is set.
+	PreferIPVersion string
+	// Timeout is the timeout for DNS-over-HTTPS requests
+	Timeout time.Duration
+	// Dial is the dialer used for the connections after resolution
+	Dial DialFunc
+	// LookupHost resolves the hosts without static mapping if no
+	// DNS-over-HTTPS server is configured. The system resolver is used if nil.
+	LookupHost LookupFunc
+}
+
+// New creates a new resolver from the provided options
+func New(options *Options) *Resolver {
+	hosts := make(map[string][]string, len(options.Hosts))
+	for host, ips := range options.Hosts {
+		hosts[normalize(host)] = ips
+	}
+	transport := &http.Transport{
+		TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+	}
+	// The DNS-over-HTTPS connections originate from the same address
+	if options.Dial != nil {
+		transport.DialContext = options.Dial
+	}
+	lookupHost := options.LookupHost
+	if lookupHost == nil {
+		lookupHost = systemLookupHost
+	}
+	return &Resolver{
+		hosts:      hosts,
+		dohServers: options.DoHServers,
+		ipVersion:  options.IPVersion,
+		preferIP:   options.PreferIPVersion,
+		httpClient: &http.Client{
+			Timeout:   options.Timeout,
+			Transport: transport,
+		},
+		dial:       options.Dial,
+		lookupHost: lookupHost,
+		cache:      make(map[string][]string),
+	}
+}
+
+// systemLookupHost resolves a host with the system resolver
+func systemLookupHost(host string) ([]string, error) {
+	return net.DefaultResolver.LookupHost(context.Background(), host)
+}
+
+// ParseHostsFile parses an /etc/hosts style file returning host to ip mappings
+func ParseHostsFile(path string) (map[string][]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not open hosts file")
+	}
+	defer file.Close()
+
+	hosts := make(map[string][]string)
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if i := strings.Index(line, "#"); i != -1 {
+			line = line[:i]
+		}
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+		if net.ParseIP(fields[0]) == nil {
+			continue
+		}
+		for _, host := range fields[1:] {
+			host = normalize(host)
+			hosts[host] = append(hosts[host], fields[0])
+		}
+	}
+	return hosts, scanner.Err()
+}
+
+// Lookup returns the addresses for a host from the static mappings or
+// DNS-over-HTTPS servers filtered by the allowed ip versions. Nil is
+// returned if the host should be resolved by the standard dialer.
+func (r *Resolver) Lookup(host string) ([]string, error) {
+	host = normalize(host)
+	if ips, ok := r.hosts[host]; ok {
+		return r.filterIPs(host, ips)
+	}
+	if ip := net.ParseIP(strings.Trim(host, "[]")); ip != nil {
+		if len(r.ipVersion) > 0 && !versionAllowed(ip, r.ipVersion) {
+			return nil, errors.Errorf("address %s does not match ip version", host)
+		}
+		return nil, nil
+	}
+	if len(r.dohServers) == 0 && len(r.ipVersion) == 0 && r.preferIP == "" {
+		return nil, nil
+	}
+
+	r.cacheMutex.RLock()
+	ips, ok := r.cache[host]
+	r.cacheMutex.RUnlock()
+	if ok {
+		return ips, nil
+	}
+
+	if len(r.dohServers) > 0 {
+		for _, question := range []uint16{dns.TypeA, dns.TypeAAAA} {
+			msg := new(dns.Msg)
+			msg.SetQuestion(dns.Fqdn(host), question)
+			resp, err := r.exchangeDoH(msg)
+			if err != nil {
+				return nil, err
+			}
+			for _, answer := range resp.Answer {
+				switch record := answer.(type) {
+				case *dns.A:
+					ips = append(ips, record.A.String())
+				case *dns.AAAA:
+					ips = append(ips, record.AAAA.String())
+				}
+			}
+		}
+	} else {
+		addrs, err := r.lookupHost(host)
+		if err != nil {
+			return nil, err
+		}
+		ips = addrs
+	}
+	ips, err := r.filterIPs(host, ips)
+	if err != nil {
+		return nil, err
+	}
+	r.cacheMutex.Lock()
+	r.cache[host] = ips
+	r.cacheMutex.Unlock()
+	return ips, nil
+}
+
+// filterIPs filters and orders the ips based on the allowed ip versions
+func (r *Resolver) filterIPs(host string, ips []string) ([]string, error) {
+	if versions := r.versions(); len(versions) > 0 {
+		var filtered []string
+		for _, version := range versions {
+			for _, value := range ips {
+				if ip := net.ParseIP(value); ip != nil && versionAllowed(ip, []string{version}) {
+					filtered = append(filtered, value)
+				}
+			}
+		}
+		ips = filtered
+	}
+	if len(ips) == 0 {
+		return nil, errors.Errorf("no address found for %s", host)
+	}
+	return ips, nil
+}
+
+// versions returns the ordered ip versions of the resolved addresses,
+// putting the preferred version first if no versions are configured.
+func (r *Resolver) versions() []string {
+	switch {
+	case len(r.ipVersion) > 0 || r.preferIP == "":
+		return r.ipVersion
+	case r.preferIP == "6":
+		return []string{"6", "4"}
+	default:
+		return []string{"4", "6"}
+	}
+}
+
+// versionAllowed returns true if the ip belongs to any of the ip versions
+func versionAllowed(ip net.IP, versions []string) bool {
+	isV4 := ip.To4() != nil
+	for _, version := range versions {
+		if (version == "4" && isV4) || (version == "6" && !isV4) {
+			return true
+		}
+	}
+	return false
+}
+
+// Dial dials an address resolving the host with static mappings or
+// DNS-over-HTTPS first, falling back to the standard dialer.
+func (r *Resolver) Dial(ctx context.Context, network, address string) (net.Conn, error) {
+	host, port, err := net.SplitHostPort(address)
+	if err != nil {
+		return r.dial(ctx, network, address)
+	}
+	ips, err := r.Lookup(host)
+	if err != nil {
+		return nil, err
+	}
+	if len(ips) == 0 {
+		return r.dial(ctx, network, address)
+	}
+	var conn net.Conn
+	for _, ip := range ips {
+		if conn, err = r.dial(ctx, network, net.JoinHostPort(ip, port)); err == nil {
+			return conn, nil
+		}
+	}
+	return nil, err
+}
+
+// DialTLS dials an address with TLS preserving the original host as SNI.
+func (r *Resolver) DialTLS(ctx context.Context, network, address string) (net.Conn, error) {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return nil, err
+	}
+	conn, err := r.Dial(ctx, network, address)
+	if err != nil {
+		return nil, err
+	}
+	tlsConn := tls.Client(conn, &tls.Config{InsecureSkipVerify: true, ServerName: host})
+	if err := tlsConn.Handshake(); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return tlsConn, nil
+}
+
+// Handles returns true if the DNS message should be resolved by the resolver
+func (r *Resolver) Handles(msg *dns.Msg) bool {
+	if len(r.dohServers) > 0 {
+		return true
+	}
+	if len(msg.Question) == 0 {
+		return false
+	}
+	_, ok := r.hosts[normalize(msg.Question[0].Name)]
+	return ok
+}
+
+// Do performs a DNS query using the static mappings or DNS-over-HTTPS servers
+func (r *Resolver) Do(msg *dns.Msg) (*dns.Msg, error) {
+	if len(msg.Question) > 0 {
+		question := msg.Question[0]
+		if ips, ok := r.hosts[normalize(question.Name)]; ok {
+			return staticReply(msg, question, ips), nil
+		}
+	}
+	if len(r.dohServers) == 0 {
+		return nil, errors.New("no dns-over-https servers configured")
+	}
+	return r.exchangeDoH(msg)
+}
+
+// exchangeDoH sends a DNS message to the DNS-over-HTTPS servers (RFC 8484)
+func (r *Resolver) exchangeDoH(msg *dns.Msg) (*dns.Msg, error) {
+	packed, err := msg.Pack()
+	if err != nil {
+		return nil, errors.Wrap(err, "could not pack dns message")
+	}
+
+	var lastErr error
+	for _, server := range r.dohServers {
+		req, err := http.NewRequest(http.MethodPost, server, bytes.NewReader(packed))
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		req.Header.Set("Content-Type", "application/dns-message")
+		req.Header.Set("Accept", "application/dns-message")
+
+		resp, err := r.httpClient.Do(req)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		data, err := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		if resp.StatusCode != http.StatusOK {
+			lastErr = errors.Errorf("unexpected status code %d from %s", resp.StatusCode, server)
+			continue
+		}
+		reply := new(dns.Msg)
+		if err := reply.Unpack(data); err != nil {
+			lastErr = errors.Wrap(err, "could not unpack dns message")
+			continue
+		}
+		return reply, nil
+	}
+	return nil, lastErr
+}
+
+// staticReply creates a DNS reply for a question from static mappings
+func staticReply(msg *dns.Msg, question dns.Question, ips []string) *dns.Msg {
+	reply := new(dns.Msg)
+	reply.SetReply(msg)
+	reply.Authoritative = true
+
+	header := dns.RR_Header{Name: question.Name, Class: dns.ClassINET, Ttl: 0}
+	for _, value := range ips {
+		ip := net.ParseIP(value)
+		if ip == nil {
+			continue
+		}
+		if ipv4 := ip.To4(); ipv4 != nil && question.Qtype == dns.TypeA {
+			header.Rrtype = dns.TypeA
+			reply.Answer = append(reply.Answer, &dns.A{Hdr: header, A: ipv4})
+		} else if ipv4 == nil && question.Qtype == dns.TypeAAAA {
+			header.Rrtype = dns.TypeAAAA
+			reply.Answer = append(reply.Answer, &dns.AAAA{Hdr: header, AAAA: ip})
+		}
+	}
+	return reply
+}
+
+// normalize returns the normalized form of a hostname
+func normalize(host string) string {
+	return strings.ToLower(strings.TrimSuffix(host, "."))
+}
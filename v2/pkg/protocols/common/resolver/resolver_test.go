@@ -0,0 +1,91 @@
+package resolver
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseHostsFile(t *testing.T) {
+	file, err := ioutil.TempFile("", "hosts-*")
+	require.Nil(t, err, "could not create temporary file")
+	defer os.Remove(file.Name())
+
+	_, _ = file.WriteString("# comment\n10.0.0.1 internal.corp app.internal.corp\n::1 localhost6 # inline\ninvalid line\n")
+	file.Close()
+
+	hosts, err := ParseHostsFile(file.Name())
+	require.Nil(t, err, "could not parse hosts file")
+	require.Equal(t, []string{"10.0.0.1"}, hosts["internal.corp"], "could not get correct mapping")
+	require.Equal(t, []string{"10.0.0.1"}, hosts["app.internal.corp"], "could not get correct mapping")
+	require.Equal(t, []string{"::1"}, hosts["localhost6"], "could not get correct mapping")
+	require.Len(t, hosts, 3, "could not get correct number of hosts")
+}
+
+func TestResolverStatic(t *testing.T) {
+	resolver := New(&Options{Hosts: map[string][]string{"Internal.Corp": {"10.0.0.1"}}})
+
+	ips, err := resolver.Lookup("internal.corp.")
+	require.Nil(t, err, "could not lookup static host")
+	require.Equal(t, []string{"10.0.0.1"}, ips, "could not get correct static ips")
+
+	ips, err = resolver.Lookup("example.com")
+	require.Nil(t, err, "could not lookup unknown host")
+	require.Nil(t, ips, "got ips for host without mapping")
+
+	msg := new(dns.Msg)
+	msg.SetQuestion("internal.corp.", dns.TypeA)
+	require.True(t, resolver.Handles(msg), "static host not handled")
+
+	reply, err := resolver.Do(msg)
+	require.Nil(t, err, "could not resolve static host")
+	require.Len(t, reply.Answer, 1, "could not get static answer")
+	require.Equal(t, "10.0.0.1", reply.Answer[0].(*dns.A).A.String(), "could not get correct answer")
+}
+
+func TestResolverIPVersion(t *testing.T) {
+	hosts := map[string][]string{"dual.corp": {"10.0.0.1", "fd00::1"}, "v4.corp": {"10.0.0.2"}}
+
+	resolver := New(&Options{Hosts: hosts, IPVersion: []string{"6", "4"}})
+	ips, err := resolver.Lookup("dual.corp")
+	require.Nil(t, err, "could not lookup dual stack host")
+	require.Equal(t, []string{"fd00::1", "10.0.0.1"}, ips, "could not get ips in preferred order")
+
+	resolver = New(&Options{Hosts: hosts, IPVersion: []string{"6"}})
+	_, err = resolver.Lookup("v4.corp")
+	require.NotNil(t, err, "could lookup v4 only host with v6 version")
+
+	_, err = resolver.Lookup("[::1]")
+	require.Nil(t, err, "could not lookup v6 literal")
+	_, err = resolver.Lookup("127.0.0.1")
+	require.NotNil(t, err, "could lookup v4 literal with v6 version")
+
+	resolver = New(&Options{Hosts: hosts, PreferIPVersion: "6"})
+	ips, err = resolver.Lookup("dual.corp")
+	require.Nil(t, err, "could not lookup dual stack host with preference")
+	require.Equal(t, []string{"fd00::1", "10.0.0.1"}, ips, "could not get preferred ips first")
+	ips, err = resolver.Lookup("v4.corp")
+	require.Nil(t, err, "could not lookup v4 only host with v6 preference")
+	require.Equal(t, []string{"10.0.0.2"}, ips, "could not keep ips of other version")
+	_, err = resolver.Lookup("127.0.0.1")
+	require.Nil(t, err, "could not lookup v4 literal with v6 preference")
+}
+
+func TestResolverLookupHost(t *testing.T) {
+	var looked []string
+	lookupHost := func(host string) ([]string, error) {
+		looked = append(looked, host)
+		return []string{"10.0.0.3", "fd00::3"}, nil
+	}
+	resolver := New(&Options{IPVersion: []string{"6", "4"}, LookupHost: lookupHost})
+	ips, err := resolver.Lookup("dual.example.com")
+	require.Nil(t, err, "could not lookup host with configured resolvers")
+	require.Equal(t, []string{"fd00::3", "10.0.0.3"}, ips, "could not get resolved ips in preferred order")
+
+	_, err = resolver.Lookup("dual.example.com")
+	require.Nil(t, err, "could not lookup cached host")
+	require.Equal(t, []string{"dual.example.com"}, looked, "could not cache resolved host")
+}
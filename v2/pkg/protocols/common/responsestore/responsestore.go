@@ -0,0 +1,137 @@
+// Package responsestore saves the requests and responses of the scan to
+// a per host directory structure for later analysis.
+package responsestore
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/projectdiscovery/gologger"
+	"github.com/projectdiscovery/nuclei/v2/pkg/output"
+	"github.com/projectdiscovery/nuclei/v2/pkg/types"
+)
+
+// Store saves requests and responses to a directory
+type Store struct {
+	directory string
+	// All is true if all the responses are stored instead of only the matched ones
+	All bool
+	// ScreenshotsOnly is true if only the screenshots of matches are stored
+	ScreenshotsOnly bool
+}
+
+// New creates a new response store for the directory
+func New(directory string, all bool) (*Store, error) {
+	if err := os.MkdirAll(directory, os.ModePerm); err != nil {
+		return nil, errors.Wrap(err, "could not create response directory")
+	}
+	return &Store{directory: directory, All: all}, nil
+}
+
+// requestKeys and responseKeys are the keys of the request and response in
+// the internal events of the protocols in order of preference.
+var (
+	requestKeys  = []string{"request", "req"}
+	responseKeys = []string{"response", "raw", "data"}
+)
+
+// Save writes the request and response of the event for the input to
+// <directory>/<host>/<template-id>-<hash>.txt returning the file path.
+// Identical responses for a template are only stored once.
+func (s *Store) Save(input, templateID string, event output.InternalEvent) (string, error) {
+	request := firstValue(event, requestKeys)
+	response := firstValue(event, responseKeys)
+	if request == "" && response == "" {
+		return "", nil
+	}
+
+	directory := filepath.Join(s.directory, sanitize(input))
+	if err := os.MkdirAll(directory, os.ModePerm); err != nil {
+		return "", errors.Wrap(err, "could not create host directory")
+	}
+	hash := sha256.Sum256([]byte(request + response))
+	file := filepath.Join(directory, sanitize(templateID)+"-"+hex.EncodeToString(hash[:8])+".txt")
+	if _, err := os.Stat(file); err == nil {
+		return file, nil
+	}
+
+	builder := &strings.Builder{}
+	builder.WriteString(request)
+	if request != "" && !strings.HasSuffix(request, "\n") {
+		builder.WriteString("\n")
+	}
+	builder.WriteString("\n")
+	builder.WriteString(response)
+	if err := ioutil.WriteFile(file, []byte(builder.String()), 0644); err != nil {
+		return "", errors.Wrap(err, "could not write response file")
+	}
+	return file, nil
+}
+
+// SaveEvent saves the event if it matched or all responses are stored, setting
+// the stored file path on the results of the event. It is a no-op on nil stores.
+func (s *Store) SaveEvent(input, templateID string, event *output.InternalWrappedEvent) {
+	if s == nil || s.ScreenshotsOnly || (event.OperatorsResult == nil && !s.All) {
+		return
+	}
+	file, err := s.Save(input, templateID, event.InternalEvent)
+	if err != nil {
+		gologger.Warning().Msgf("[%s] Could not store response for %s: %s\n", templateID, input, err)
+		return
+	}
+	for _, result := range event.Results {
+		result.StoredResponse = file
+	}
+}
+
+// ScreenshotFile returns the path of the png screenshot of a matched url for
+// the input at <directory>/<host>/<template-id>-<hash>.png.
+func (s *Store) ScreenshotFile(input, templateID, matched string) string {
+	hash := sha256.Sum256([]byte(matched))
+	return filepath.Join(s.directory, sanitize(input), sanitize(templateID)+"-"+hex.EncodeToString(hash[:8])+".png")
+}
+
+// SaveScreenshot writes the png screenshot data to the file of a screenshot
+func (s *Store) SaveScreenshot(file string, data []byte) error {
+	if s == nil {
+		return errors.New("no response store configured")
+	}
+	if err := os.MkdirAll(filepath.Dir(file), os.ModePerm); err != nil {
+		return errors.Wrap(err, "could not create host directory")
+	}
+	if err := ioutil.WriteFile(file, data, 0644); err != nil {
+		return errors.Wrap(err, "could not write screenshot file")
+	}
+	return nil
+}
+
+func firstValue(event output.InternalEvent, keys []string) string {
+	for _, key := range keys {
+		if value, ok := event[key]; ok {
+			if str := types.ToString(value); str != "" {
+				return str
+			}
+		}
+	}
+	return ""
+}
+
+var unsafeCharacters = regexp.MustCompile(`[^a-zA-Z0-9._-]+`)
+
+// sanitize returns a name safe to use as a file name
+func sanitize(name string) string {
+	if index := strings.Index(name, "://"); index != -1 {
+		name = name[index+3:]
+	}
+	name = strings.Trim(unsafeCharacters.ReplaceAllString(name, "_"), "_.")
+	if name == "" {
+		return "unknown"
+	}
+	return name
+}
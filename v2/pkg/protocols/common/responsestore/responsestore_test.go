@@ -0,0 +1,61 @@
+package responsestore
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/projectdiscovery/nuclei/v2/pkg/output"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStoreSave(t *testing.T) {
+	directory, err := ioutil.TempDir("", "responses")
+	require.Nil(t, err, "could not create temporary directory")
+	defer os.RemoveAll(directory)
+
+	store, err := New(directory, false)
+	require.Nil(t, err, "could not create store")
+
+	event := output.InternalEvent{"request": "GET / HTTP/1.1\r\nHost: example.com\r\n\r\n", "response": "HTTP/1.1 200 OK\r\n\r\nbody"}
+	file, err := store.Save("https://example.com:8443/path", "test-template", event)
+	require.Nil(t, err, "could not save response")
+	require.Equal(t, filepath.Join(directory, "example.com_8443_path"), filepath.Dir(file), "could not get host directory")
+
+	data, err := ioutil.ReadFile(file)
+	require.Nil(t, err, "could not read stored response")
+	require.Equal(t, "GET / HTTP/1.1\r\nHost: example.com\r\n\r\n\nHTTP/1.1 200 OK\r\n\r\nbody", string(data), "could not get stored response")
+
+	second, err := store.Save("https://example.com:8443/path", "test-template", event)
+	require.Nil(t, err, "could not save response")
+	require.Equal(t, file, second, "could not deduplicate identical response")
+
+	file, err = store.Save("example.com", "test-template", output.InternalEvent{"host": "example.com"})
+	require.Nil(t, err, "could not save event without response")
+	require.Empty(t, file, "could store event without response")
+}
+
+func TestStoreSaveScreenshot(t *testing.T) {
+	directory, err := ioutil.TempDir("", "responses")
+	require.Nil(t, err, "could not create temporary directory")
+	defer os.RemoveAll(directory)
+
+	store, err := New(directory, false)
+	require.Nil(t, err, "could not create store")
+	store.ScreenshotsOnly = true
+
+	file := store.ScreenshotFile("https://example.com", "test-template", "https://example.com/login")
+	require.NotEqual(t, file, store.ScreenshotFile("https://example.com", "test-template", "https://example.com/admin"), "could get same file for other matched url")
+	err = store.SaveScreenshot(file, []byte("\x89PNG"))
+	require.Nil(t, err, "could not save screenshot")
+	_, err = os.Stat(file)
+	require.Nil(t, err, "could not write screenshot file")
+	require.Equal(t, filepath.Join(directory, "example.com"), filepath.Dir(file), "could not get host directory")
+	require.Equal(t, ".png", filepath.Ext(file), "could not get screenshot extension")
+
+	event := &output.InternalWrappedEvent{InternalEvent: output.InternalEvent{"response": "body"}, Results: []*output.ResultEvent{{}}}
+	store.All = true
+	store.SaveEvent("https://example.com", "test-template", event)
+	require.Empty(t, event.Results[0].StoredResponse, "could store response with screenshots only")
+}
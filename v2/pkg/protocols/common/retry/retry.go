@@ -0,0 +1,85 @@
+package retry
+
+import (
+	"math"
+	"math/rand"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/projectdiscovery/nuclei/v2/pkg/types"
+)
+
+// Policy is a retry policy with exponential backoff and jitter
+// shared by all the protocol executers.
+type Policy struct {
+	// Retries is the number of times to retry a failed operation
+	Retries int
+	// Backoff is the base delay for the exponential backoff
+	Backoff time.Duration
+	// MaxBackoff is the maximum delay between two attempts
+	MaxBackoff time.Duration
+}
+
+var (
+	randMutex = &sync.Mutex{}
+	random    = rand.New(rand.NewSource(time.Now().UnixNano()))
+)
+
+// NewPolicy returns a retry policy from the options with an optional
+// template level override of retries. Overrides less than or equal
+// to zero use the global retries value.
+func NewPolicy(options *types.Options, override int) *Policy {
+	retries := options.Retries
+	if override > 0 {
+		retries = override
+	}
+	return &Policy{
+		Retries:    retries,
+		Backoff:    time.Duration(options.RetryBackoff) * time.Millisecond,
+		MaxBackoff: time.Duration(options.RetryMaxBackoff) * time.Millisecond,
+	}
+}
+
+// Wait returns the delay before the attempt number specified.
+//
+// The delay grows exponentially from the base backoff up to the maximum,
+// and half of it is randomized to avoid synchronized retries.
+func (p *Policy) Wait(attempt int) time.Duration {
+	return jitterBackoff(p.Backoff, p.MaxBackoff, attempt)
+}
+
+// Do runs the function until it succeeds or the retries are exhausted,
+// waiting between each failed attempt. The last error is returned.
+func (p *Policy) Do(fn func() error) error {
+	var err error
+	for attempt := 0; attempt <= p.Retries; attempt++ {
+		if attempt > 0 {
+			time.Sleep(p.Wait(attempt))
+		}
+		if err = fn(); err == nil {
+			return nil
+		}
+	}
+	return err
+}
+
+// HTTPBackoff is a backoff function compatible with retryablehttp clients
+func HTTPBackoff(min, max time.Duration, attemptNum int, resp *http.Response) time.Duration {
+	return jitterBackoff(min, max, attemptNum)
+}
+
+// jitterBackoff returns an exponential backoff delay with full jitter
+func jitterBackoff(base, max time.Duration, attempt int) time.Duration {
+	if base <= 0 || attempt <= 0 {
+		return 0
+	}
+	delay := float64(base) * math.Pow(2, float64(attempt-1))
+	if max > 0 && delay > float64(max) {
+		delay = float64(max)
+	}
+	randMutex.Lock()
+	jitter := random.Int63n(int64(delay) + 1)
+	randMutex.Unlock()
+	return time.Duration(jitter/2 + int64(delay)/2)
+}
@@ -0,0 +1,45 @@
+package retry
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPolicyWait(t *testing.T) {
+	policy := &Policy{Retries: 3, Backoff: 100 * time.Millisecond, MaxBackoff: 300 * time.Millisecond}
+
+	require.Equal(t, time.Duration(0), policy.Wait(0), "could not get zero wait for first attempt")
+	for i := 0; i < 20; i++ {
+		wait := policy.Wait(1)
+		require.True(t, wait >= 50*time.Millisecond && wait <= 100*time.Millisecond, "invalid wait for first retry")
+
+		wait = policy.Wait(5)
+		require.True(t, wait >= 150*time.Millisecond && wait <= 300*time.Millisecond, "wait not capped by max backoff")
+	}
+}
+
+func TestPolicyDo(t *testing.T) {
+	policy := &Policy{Retries: 2, Backoff: time.Millisecond, MaxBackoff: time.Millisecond}
+
+	attempts := 0
+	err := policy.Do(func() error {
+		attempts++
+		return errors.New("failed")
+	})
+	require.NotNil(t, err, "could not get error for failed operation")
+	require.Equal(t, 3, attempts, "could not get correct attempts")
+
+	attempts = 0
+	err = policy.Do(func() error {
+		attempts++
+		if attempts == 2 {
+			return nil
+		}
+		return errors.New("failed")
+	})
+	require.Nil(t, err, "could not get nil error for successful retry")
+	require.Equal(t, 2, attempts, "could not get correct attempts")
+}
@@ -0,0 +1,138 @@
+// Package servicedetect fingerprints the service listening on a port by
+// grabbing its banner so network templates can be dispatched only to the
+// ports running the service they target.
+package servicedetect
+
+import (
+	"bytes"
+	"context"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/projectdiscovery/nuclei/v2/pkg/protocols/common/auditlog"
+	"github.com/projectdiscovery/nuclei/v2/pkg/protocols/common/protocolstate"
+)
+
+// Services are the service names the detector can identify. The names
+// are matched against the tags of the templates.
+var Services = []string{"ssh", "ftp", "smtp", "pop3", "imap", "telnet", "mysql", "redis", "vnc", "rsync", "http"}
+
+// httpProbe is sent to services not sending a banner on connect
+var httpProbe = []byte("GET / HTTP/1.0\r\n\r\n")
+
+// Detector detects and caches the services running on addresses
+type Detector struct {
+	timeout  time.Duration
+	auditLog *auditlog.Logger
+
+	mutex    sync.Mutex
+	services map[string]*result
+}
+
+// result is the cached detection result of an address
+type result struct {
+	once    sync.Once
+	service string
+}
+
+// New creates a new service detector with the timeout for each probe,
+// recording the probes in the audit log if it is not nil.
+func New(timeout time.Duration, auditLog *auditlog.Logger) *Detector {
+	return &Detector{timeout: timeout, auditLog: auditLog, services: make(map[string]*result)}
+}
+
+// Detect returns the name of the service running on the host:port address.
+// An empty string is returned if the service could not be identified.
+func (d *Detector) Detect(address string) string {
+	d.mutex.Lock()
+	cached, ok := d.services[address]
+	if !ok {
+		cached = &result{}
+		d.services[address] = cached
+	}
+	d.mutex.Unlock()
+
+	cached.once.Do(func() {
+		cached.service = Fingerprint(d.grabBanner(address))
+	})
+	return cached.service
+}
+
+// grabBanner reads the banner sent by the service on connect, sending
+// a http probe if the service waits for the client to talk first.
+func (d *Detector) grabBanner(address string) []byte {
+	ctx, cancel := context.WithTimeout(context.Background(), d.timeout)
+	defer cancel()
+	dialed, err := dial(ctx, address)
+	if err != nil {
+		d.auditLog.Log("service-detect", "network", address, 0, 0, err)
+		return nil
+	}
+	counter := &auditlog.Counter{}
+	conn := counter.Wrap(dialed)
+	defer func() {
+		conn.Close()
+		d.auditLog.Log("service-detect", "network", address, counter.Sent(), counter.Received(), nil)
+	}()
+
+	buffer := make([]byte, 1024)
+	_ = conn.SetReadDeadline(time.Now().Add(d.timeout))
+	if n, _ := conn.Read(buffer); n > 0 {
+		return buffer[:n]
+	}
+	_ = conn.SetDeadline(time.Now().Add(d.timeout))
+	if _, err := conn.Write(httpProbe); err != nil {
+		return nil
+	}
+	n, _ := conn.Read(buffer)
+	return buffer[:n]
+}
+
+// dial connects to the address with the shared dialer of the protocols to
+// honor the resolvers, proxy and source ip of the scan.
+func dial(ctx context.Context, address string) (net.Conn, error) {
+	if protocolstate.Dialer == nil {
+		return protocolstate.SourceDialer(protocolstate.SourceIP, "tcp", 0).DialContext(ctx, "tcp", address)
+	}
+	return protocolstate.Dial(ctx, protocolstate.Dialer, address, false)
+}
+
+// Fingerprint returns the name of the service sending the banner
+func Fingerprint(banner []byte) string {
+	if len(banner) == 0 {
+		return ""
+	}
+	lower := bytes.ToLower(banner)
+
+	switch {
+	case bytes.HasPrefix(banner, []byte("SSH-")):
+		return "ssh"
+	case bytes.HasPrefix(banner, []byte("HTTP/")):
+		return "http"
+	case bytes.HasPrefix(banner, []byte("+OK")):
+		return "pop3"
+	case bytes.HasPrefix(banner, []byte("* OK")):
+		return "imap"
+	case bytes.HasPrefix(banner, []byte("RFB ")):
+		return "vnc"
+	case bytes.HasPrefix(banner, []byte("@RSYNCD")):
+		return "rsync"
+	case bytes.HasPrefix(banner, []byte("-ERR")), bytes.HasPrefix(banner, []byte("-NOAUTH")), bytes.HasPrefix(banner, []byte("-DENIED")):
+		return "redis"
+	case banner[0] == 0xff:
+		// Telnet servers start with IAC option negotiation
+		return "telnet"
+	case bytes.HasPrefix(banner, []byte("220")):
+		if bytes.Contains(lower, []byte("smtp")) || bytes.Contains(lower, []byte("mail")) {
+			return "smtp"
+		}
+		if bytes.Contains(lower, []byte("ftp")) {
+			return "ftp"
+		}
+	case len(banner) > 5 && banner[4] == 0x0a, bytes.Contains(lower, []byte("mysql")), bytes.Contains(lower, []byte("mariadb")):
+		// MySQL handshake packets have the protocol version 10 after the header
+		return "mysql"
+	}
+	return ""
+}
@@ -0,0 +1,56 @@
+package servicedetect
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/projectdiscovery/nuclei/v2/pkg/protocols/common/protocolstate"
+	"github.com/projectdiscovery/nuclei/v2/pkg/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFingerprint(t *testing.T) {
+	items := map[string]string{
+		"SSH-2.0-OpenSSH_8.2p1 Ubuntu-4ubuntu0.1\r\n":    "ssh",
+		"220 mail.example.com ESMTP Postfix\r\n":         "smtp",
+		"220 (vsFTPd 3.0.3)\r\n":                         "ftp",
+		"+OK Dovecot ready.\r\n":                         "pop3",
+		"* OK [CAPABILITY IMAP4rev1] Dovecot ready.\r\n": "imap",
+		"HTTP/1.1 400 Bad Request\r\n":                   "http",
+		"-ERR wrong number of arguments for 'get'\r\n":   "redis",
+		"J\x00\x00\x00\x0a5.7.33\x00":                    "mysql",
+		"RFB 003.008\n":                                  "vnc",
+		"\xff\xfd\x18\xff\xfd\x20":                       "telnet",
+		"unknown banner":                                 "",
+	}
+	for banner, service := range items {
+		require.Equal(t, service, Fingerprint([]byte(banner)), "could not fingerprint %q", banner)
+	}
+}
+
+func TestDetect(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.Nil(t, err, "could not listen")
+	defer listener.Close()
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			_, _ = conn.Write([]byte("SSH-2.0-OpenSSH_8.2\r\n"))
+			conn.Close()
+		}
+	}()
+
+	detector := New(2*time.Second, nil)
+	require.Equal(t, "ssh", detector.Detect(listener.Addr().String()), "could not detect service")
+	require.Equal(t, "ssh", detector.Detect(listener.Addr().String()), "could not get cached service")
+
+	// The probes are dialed with the shared dialer once initialized
+	require.Nil(t, protocolstate.Init(&types.Options{}), "could not initialize protocol state")
+	defer protocolstate.Close()
+	require.Equal(t, "ssh", New(2*time.Second, nil).Detect(listener.Addr().String()), "could not detect service with shared dialer")
+}
@@ -0,0 +1,221 @@
+// Package takeover detects subdomains vulnerable to takeover by following
+// their CNAME chains to third-party services and confirming the unclaimed
+// resource with the dns and http fingerprints of the service.
+package takeover
+
+import (
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/miekg/dns"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v2"
+)
+
+// maxCNAMEChain is the maximum number of CNAME records followed for a host
+const maxCNAMEChain = 10
+
+// maxBodySize is the maximum size of the response bodies read for fingerprints
+const maxBodySize = 1024 * 1024
+
+// Fingerprint identifies the unclaimed resources of a service
+type Fingerprint struct {
+	// Service is the name of the service
+	Service string `yaml:"service"`
+	// CNAMEs are the domain suffixes of the CNAME targets of the service
+	CNAMEs []string `yaml:"cname"`
+	// Body are the response body strings of the unclaimed resources
+	Body []string `yaml:"body,omitempty"`
+	// NXDomain is true if the unclaimed resources of the service don't resolve
+	NXDomain bool `yaml:"nxdomain,omitempty"`
+}
+
+// DefaultFingerprints are the fingerprints of the services known to be
+// vulnerable to takeover.
+var DefaultFingerprints = []*Fingerprint{
+	{Service: "github-pages", CNAMEs: []string{"github.io"}, Body: []string{"There isn't a GitHub Pages site here."}},
+	{Service: "heroku", CNAMEs: []string{"herokuapp.com", "herokudns.com"}, Body: []string{"No such app", "herokucdn.com/error-pages/no-such-app.html"}},
+	{Service: "aws-s3", CNAMEs: []string{"s3.amazonaws.com", "s3-website.us-east-1.amazonaws.com"}, Body: []string{"The specified bucket does not exist"}},
+	{Service: "aws-elastic-beanstalk", CNAMEs: []string{"elasticbeanstalk.com"}, NXDomain: true},
+	{Service: "azure", CNAMEs: []string{"azurewebsites.net", "cloudapp.net", "cloudapp.azure.com", "trafficmanager.net", "blob.core.windows.net", "azureedge.net", "azure-api.net"}, NXDomain: true},
+	{Service: "shopify", CNAMEs: []string{"myshopify.com"}, Body: []string{"Sorry, this shop is currently unavailable."}},
+	{Service: "fastly", CNAMEs: []string{"fastly.net"}, Body: []string{"Fastly error: unknown domain"}},
+	{Service: "ghost", CNAMEs: []string{"ghost.io"}, Body: []string{"The thing you were looking for is no longer here, or never was"}},
+	{Service: "pantheon", CNAMEs: []string{"pantheonsite.io"}, Body: []string{"The gods are wise, but do not know of the site which you seek."}},
+	{Service: "tumblr", CNAMEs: []string{"domains.tumblr.com"}, Body: []string{"Whatever you were looking for doesn't currently exist at this address."}},
+	{Service: "zendesk", CNAMEs: []string{"zendesk.com"}, Body: []string{"Help Center Closed"}},
+	{Service: "surge", CNAMEs: []string{"surge.sh"}, Body: []string{"project not found"}},
+	{Service: "bitbucket", CNAMEs: []string{"bitbucket.io"}, Body: []string{"Repository not found"}},
+	{Service: "readme", CNAMEs: []string{"readme.io"}, Body: []string{"Project doesnt exist... yet!"}},
+	{Service: "helpscout", CNAMEs: []string{"helpscoutdocs.com"}, Body: []string{"No settings were found for this company:"}},
+	{Service: "agilecrm", CNAMEs: []string{"agilecrm.com"}, Body: []string{"Sorry, this page is no longer available."}},
+	{Service: "netlify", CNAMEs: []string{"netlify.app", "netlify.com"}, Body: []string{"Not Found - Request ID:"}},
+}
+
+// LoadFingerprints loads the fingerprints of a yaml file
+func LoadFingerprints(path string) ([]*Fingerprint, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not read takeover fingerprints")
+	}
+	var fingerprints []*Fingerprint
+	if err := yaml.Unmarshal(data, &fingerprints); err != nil {
+		return nil, errors.Wrap(err, "could not parse takeover fingerprints")
+	}
+	for _, fingerprint := range fingerprints {
+		if fingerprint.Service == "" || len(fingerprint.CNAMEs) == 0 {
+			return nil, errors.New("takeover fingerprints require a service and cname")
+		}
+		if len(fingerprint.Body) == 0 && !fingerprint.NXDomain {
+			return nil, errors.Errorf("takeover fingerprint %s requires a body or nxdomain", fingerprint.Service)
+		}
+	}
+	return fingerprints, nil
+}
+
+// ExchangeFunc sends a DNS message returning the reply
+type ExchangeFunc func(msg *dns.Msg) (*dns.Msg, error)
+
+// Result is a subdomain vulnerable to takeover
+type Result struct {
+	// Host is the vulnerable subdomain
+	Host string
+	// Service is the service of the unclaimed resource
+	Service string
+	// CNAMEs is the CNAME chain of the subdomain
+	CNAMEs []string
+	// Evidence describes the dns or http evidence of the unclaimed resource
+	Evidence string
+	// Protocol is the protocol of the evidence, dns or http
+	Protocol string
+}
+
+// Checker checks subdomains for takeover
+type Checker struct {
+	fingerprints []*Fingerprint
+	exchange     ExchangeFunc
+	client       *http.Client
+}
+
+// New creates a new takeover checker
+func New(fingerprints []*Fingerprint, exchange ExchangeFunc, client *http.Client) *Checker {
+	return &Checker{fingerprints: fingerprints, exchange: exchange, client: client}
+}
+
+// ResolveCNAMEChain returns the CNAME chain of a host and the response code
+// of the address query of the host, NXDOMAIN for dangling records.
+func (c *Checker) ResolveCNAMEChain(host string) ([]string, int, error) {
+	var chain []string
+	seen := make(map[string]struct{})
+
+	name := dns.Fqdn(strings.ToLower(host))
+	for len(chain) < maxCNAMEChain {
+		reply, err := c.query(name, dns.TypeCNAME)
+		if err != nil {
+			return nil, 0, err
+		}
+		var target string
+		for _, answer := range reply.Answer {
+			if cname, ok := answer.(*dns.CNAME); ok && strings.EqualFold(cname.Hdr.Name, name) {
+				target = strings.ToLower(cname.Target)
+				break
+			}
+		}
+		if _, ok := seen[target]; target == "" || ok {
+			break
+		}
+		seen[target] = struct{}{}
+		chain = append(chain, strings.TrimSuffix(target, "."))
+		name = target
+	}
+
+	reply, err := c.query(dns.Fqdn(host), dns.TypeA)
+	if err != nil {
+		return nil, 0, err
+	}
+	return chain, reply.Rcode, nil
+}
+
+// query sends a question for a name
+func (c *Checker) query(name string, qtype uint16) (*dns.Msg, error) {
+	msg := new(dns.Msg)
+	msg.SetQuestion(name, qtype)
+	msg.RecursionDesired = true
+	reply, err := c.exchange(msg)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not resolve %s", name)
+	}
+	return reply, nil
+}
+
+// Check returns the takeover of a host, nil if the host is not vulnerable.
+// A host is vulnerable if its CNAME chain points to a service and the dns
+// or http fingerprint of an unclaimed resource of the service matches.
+func (c *Checker) Check(host string) (*Result, error) {
+	chain, rcode, err := c.ResolveCNAMEChain(host)
+	if err != nil {
+		return nil, err
+	}
+	fingerprint := c.match(chain)
+	if fingerprint == nil {
+		return nil, nil
+	}
+	result := &Result{Host: host, Service: fingerprint.Service, CNAMEs: chain}
+
+	if rcode == dns.RcodeNameError {
+		if !fingerprint.NXDomain {
+			return nil, nil
+		}
+		result.Evidence = "dangling cname " + chain[len(chain)-1]
+		result.Protocol = "dns"
+		return result, nil
+	}
+	if len(fingerprint.Body) == 0 || c.client == nil {
+		return nil, nil
+	}
+	for _, scheme := range []string{"https", "http"} {
+		body, err := c.fetch(scheme + "://" + host + "/")
+		if err != nil {
+			continue
+		}
+		for _, value := range fingerprint.Body {
+			if strings.Contains(body, value) {
+				result.Evidence = "response body contains \"" + value + "\""
+				result.Protocol = "http"
+				return result, nil
+			}
+		}
+	}
+	return nil, nil
+}
+
+// match returns the fingerprint of the service the chain points to
+func (c *Checker) match(chain []string) *Fingerprint {
+	for _, target := range chain {
+		for _, fingerprint := range c.fingerprints {
+			for _, suffix := range fingerprint.CNAMEs {
+				suffix = strings.ToLower(strings.TrimSuffix(suffix, "."))
+				if target == suffix || strings.HasSuffix(target, "."+suffix) {
+					return fingerprint
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// fetch returns the response body of a URL
+func (c *Checker) fetch(URL string) (string, error) {
+	resp, err := c.client.Get(URL)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	data, err := ioutil.ReadAll(io.LimitReader(resp.Body, maxBodySize))
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
@@ -0,0 +1,94 @@
+package takeover
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/require"
+)
+
+// records are the CNAME records and the existing names of a mock resolver
+type records struct {
+	cnames   map[string]string
+	existing map[string]struct{}
+}
+
+func (r *records) exchange(msg *dns.Msg) (*dns.Msg, error) {
+	reply := new(dns.Msg)
+	reply.SetReply(msg)
+	question := msg.Question[0]
+
+	name := question.Name
+	for {
+		target, ok := r.cnames[name]
+		if !ok {
+			break
+		}
+		header := dns.RR_Header{Name: name, Rrtype: dns.TypeCNAME, Class: dns.ClassINET}
+		reply.Answer = append(reply.Answer, &dns.CNAME{Hdr: header, Target: target})
+		if question.Qtype == dns.TypeCNAME {
+			return reply, nil
+		}
+		name = target
+	}
+	if _, ok := r.existing[name]; !ok && question.Qtype != dns.TypeCNAME {
+		reply.Rcode = dns.RcodeNameError
+	}
+	return reply, nil
+}
+
+func TestCheckDanglingCNAME(t *testing.T) {
+	resolver := &records{cnames: map[string]string{
+		"app.example.com.":         "app.azurewebsites.net.",
+		"www.example.com.":         "example.com.",
+		"unclaimed.example.com.":   "unclaimed.herokudns.com.",
+		"unclaimed.herokudns.com.": "gone.herokudns.com.",
+	}, existing: map[string]struct{}{"example.com.": {}}}
+	checker := New(DefaultFingerprints, resolver.exchange, nil)
+
+	chain, rcode, err := checker.ResolveCNAMEChain("unclaimed.example.com")
+	require.Nil(t, err, "could not resolve cname chain")
+	require.Equal(t, []string{"unclaimed.herokudns.com", "gone.herokudns.com"}, chain, "could not get cname chain")
+	require.Equal(t, dns.RcodeNameError, rcode, "could not get nxdomain")
+
+	result, err := checker.Check("app.example.com")
+	require.Nil(t, err, "could not check host")
+	require.NotNil(t, result, "could not detect dangling cname")
+	require.Equal(t, "azure", result.Service, "could not get service")
+	require.Equal(t, "dns", result.Protocol, "could not get dns evidence protocol")
+
+	result, err = checker.Check("unclaimed.example.com")
+	require.Nil(t, err, "could not check host")
+	require.Nil(t, result, "could detect nxdomain takeover for service without nxdomain fingerprint")
+
+	result, err = checker.Check("www.example.com")
+	require.Nil(t, err, "could not check host")
+	require.Nil(t, result, "could detect takeover without service cname")
+}
+
+func TestCheckBodyFingerprint(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte("<html><body>There isn't a GitHub Pages site here.</body></html>"))
+	}))
+	defer ts.Close()
+	parsed, _ := url.Parse(ts.URL)
+
+	resolver := &records{
+		cnames:   map[string]string{"docs.example.com.": "example.github.io."},
+		existing: map[string]struct{}{"example.github.io.": {}},
+	}
+	// The requests of the checker are sent to the test server
+	client := &http.Client{Transport: &http.Transport{Proxy: http.ProxyURL(parsed)}}
+	checker := New(DefaultFingerprints, resolver.exchange, client)
+
+	result, err := checker.Check("docs.example.com")
+	require.Nil(t, err, "could not check host")
+	require.NotNil(t, result, "could not detect body fingerprint")
+	require.Equal(t, "github-pages", result.Service, "could not get service")
+	require.Equal(t, []string{"example.github.io"}, result.CNAMEs, "could not get cname chain")
+	require.Equal(t, "http", result.Protocol, "could not get http evidence protocol")
+}
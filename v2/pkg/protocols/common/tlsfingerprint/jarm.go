@@ -0,0 +1,374 @@
+package tlsfingerprint
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// probe is a JARM client hello configuration
+type probe struct {
+	version        string
+	ciphers        string
+	cipherOrder    string
+	grease         bool
+	rareALPN       bool
+	supportVersion string
+	extensionOrder string
+}
+
+// probes are the 10 client hellos sent for computing the JARM hash
+var probes = []probe{
+	{version: "TLS_1.2", ciphers: "ALL", cipherOrder: "FORWARD", supportVersion: "1.2_SUPPORT", extensionOrder: "REVERSE"},
+	{version: "TLS_1.2", ciphers: "ALL", cipherOrder: "REVERSE", supportVersion: "1.2_SUPPORT", extensionOrder: "FORWARD"},
+	{version: "TLS_1.2", ciphers: "ALL", cipherOrder: "TOP_HALF", supportVersion: "NO_SUPPORT", extensionOrder: "FORWARD"},
+	{version: "TLS_1.2", ciphers: "ALL", cipherOrder: "BOTTOM_HALF", rareALPN: true, supportVersion: "NO_SUPPORT", extensionOrder: "FORWARD"},
+	{version: "TLS_1.2", ciphers: "ALL", cipherOrder: "MIDDLE_OUT", grease: true, rareALPN: true, supportVersion: "NO_SUPPORT", extensionOrder: "REVERSE"},
+	{version: "TLS_1.1", ciphers: "ALL", cipherOrder: "FORWARD", supportVersion: "NO_SUPPORT", extensionOrder: "FORWARD"},
+	{version: "TLS_1.3", ciphers: "ALL", cipherOrder: "FORWARD", supportVersion: "1.3_SUPPORT", extensionOrder: "REVERSE"},
+	{version: "TLS_1.3", ciphers: "ALL", cipherOrder: "REVERSE", supportVersion: "1.3_SUPPORT", extensionOrder: "FORWARD"},
+	{version: "TLS_1.3", ciphers: "NO1.3", cipherOrder: "FORWARD", supportVersion: "1.3_SUPPORT", extensionOrder: "FORWARD"},
+	{version: "TLS_1.3", ciphers: "ALL", cipherOrder: "MIDDLE_OUT", grease: true, supportVersion: "1.3_SUPPORT", extensionOrder: "REVERSE"},
+}
+
+var allCiphers = [][]byte{
+	{0x00, 0x16}, {0x00, 0x33}, {0x00, 0x67}, {0xc0, 0x9e}, {0xc0, 0xa2}, {0x00, 0x9e}, {0x00, 0x39}, {0x00, 0x6b},
+	{0xc0, 0x9f}, {0xc0, 0xa3}, {0x00, 0x9f}, {0x00, 0x45}, {0x00, 0xbe}, {0x00, 0x88}, {0x00, 0xc4}, {0x00, 0x9a},
+	{0xc0, 0x08}, {0xc0, 0x09}, {0xc0, 0x23}, {0xc0, 0xac}, {0xc0, 0xae}, {0xc0, 0x2b}, {0xc0, 0x0a}, {0xc0, 0x24},
+	{0xc0, 0xad}, {0xc0, 0xaf}, {0xc0, 0x2c}, {0xc0, 0x72}, {0xc0, 0x73}, {0xcc, 0xa9}, {0x13, 0x02}, {0x13, 0x01},
+	{0xcc, 0x14}, {0xc0, 0x07}, {0xc0, 0x12}, {0xc0, 0x13}, {0xc0, 0x27}, {0xc0, 0x2f}, {0xc0, 0x14}, {0xc0, 0x28},
+	{0xc0, 0x30}, {0xc0, 0x60}, {0xc0, 0x61}, {0xc0, 0x76}, {0xc0, 0x77}, {0xcc, 0xa8}, {0x13, 0x05}, {0x13, 0x04},
+	{0x13, 0x03}, {0xcc, 0x13}, {0xc0, 0x11}, {0x00, 0x0a}, {0x00, 0x2f}, {0x00, 0x3c}, {0xc0, 0x9c}, {0xc0, 0xa0},
+	{0x00, 0x9c}, {0x00, 0x35}, {0x00, 0x3d}, {0xc0, 0x9d}, {0xc0, 0xa1}, {0x00, 0x9d}, {0x00, 0x41}, {0x00, 0xba},
+	{0x00, 0x84}, {0x00, 0xc0}, {0x00, 0x07}, {0x00, 0x04}, {0x00, 0x05},
+}
+
+// hashCiphers is the ordered cipher list used for the fuzzy part of the hash
+var hashCiphers = [][]byte{
+	{0x00, 0x04}, {0x00, 0x05}, {0x00, 0x07}, {0x00, 0x0a}, {0x00, 0x16}, {0x00, 0x2f}, {0x00, 0x33}, {0x00, 0x35},
+	{0x00, 0x39}, {0x00, 0x3c}, {0x00, 0x3d}, {0x00, 0x41}, {0x00, 0x45}, {0x00, 0x67}, {0x00, 0x6b}, {0x00, 0x84},
+	{0x00, 0x88}, {0x00, 0x9a}, {0x00, 0x9c}, {0x00, 0x9d}, {0x00, 0x9e}, {0x00, 0x9f}, {0x00, 0xba}, {0x00, 0xbe},
+	{0x00, 0xc0}, {0x00, 0xc4}, {0xc0, 0x07}, {0xc0, 0x08}, {0xc0, 0x09}, {0xc0, 0x0a}, {0xc0, 0x11}, {0xc0, 0x12},
+	{0xc0, 0x13}, {0xc0, 0x14}, {0xc0, 0x23}, {0xc0, 0x24}, {0xc0, 0x27}, {0xc0, 0x28}, {0xc0, 0x2b}, {0xc0, 0x2c},
+	{0xc0, 0x2f}, {0xc0, 0x30}, {0xc0, 0x60}, {0xc0, 0x61}, {0xc0, 0x72}, {0xc0, 0x73}, {0xc0, 0x76}, {0xc0, 0x77},
+	{0xc0, 0x9c}, {0xc0, 0x9d}, {0xc0, 0x9e}, {0xc0, 0x9f}, {0xc0, 0xa0}, {0xc0, 0xa1}, {0xc0, 0xa2}, {0xc0, 0xa3},
+	{0xc0, 0xac}, {0xc0, 0xad}, {0xc0, 0xae}, {0xc0, 0xaf}, {0xcc, 0x13}, {0xcc, 0x14}, {0xcc, 0xa8}, {0xcc, 0xa9},
+	{0x13, 0x01}, {0x13, 0x02}, {0x13, 0x03}, {0x13, 0x04}, {0x13, 0x05},
+}
+
+var (
+	alpns = [][]byte{
+		[]byte("\x08http/0.9"), []byte("\x08http/1.0"), []byte("\x08http/1.1"), []byte("\x06spdy/1"),
+		[]byte("\x06spdy/2"), []byte("\x06spdy/3"), []byte("\x02h2"), []byte("\x03h2c"), []byte("\x02hq"),
+	}
+	rareALPNs = [][]byte{
+		[]byte("\x08http/0.9"), []byte("\x08http/1.0"), []byte("\x06spdy/1"), []byte("\x06spdy/2"),
+		[]byte("\x06spdy/3"), []byte("\x03h2c"), []byte("\x02hq"),
+	}
+)
+
+// emptyJARM is the hash of a target not answering any of the probes
+var emptyJARM = strings.Repeat("0", 62)
+
+// buildProbe builds the client hello packet of the probe for the host
+func buildProbe(p probe, host string) []byte {
+	recordVersion, helloVersion := []byte{0x03, 0x03}, []byte{0x03, 0x03}
+	switch p.version {
+	case "TLS_1.3":
+		recordVersion = []byte{0x03, 0x01}
+	case "TLS_1.1":
+		recordVersion, helloVersion = []byte{0x03, 0x02}, []byte{0x03, 0x02}
+	}
+
+	hello := append([]byte{}, helloVersion...)
+	hello = append(hello, randomBytes(32)...)
+	hello = append(hello, 32)
+	hello = append(hello, randomBytes(32)...)
+
+	ciphers := probeCiphers(p)
+	hello = appendUint16(hello, len(ciphers))
+	hello = append(hello, ciphers...)
+	hello = append(hello, 0x01, 0x00) // compression methods
+	hello = append(hello, probeExtensions(p, host)...)
+
+	handshake := []byte{0x01, byte(len(hello) >> 16), byte(len(hello) >> 8), byte(len(hello))}
+	handshake = append(handshake, hello...)
+
+	packet := append([]byte{0x16}, recordVersion...)
+	packet = appendUint16(packet, len(handshake))
+	return append(packet, handshake...)
+}
+
+func probeCiphers(p probe) []byte {
+	var list [][]byte
+	for _, cipher := range allCiphers {
+		if p.ciphers == "NO1.3" && cipher[0] == 0x13 {
+			continue
+		}
+		list = append(list, cipher)
+	}
+	if p.cipherOrder != "FORWARD" {
+		list = mung(list, p.cipherOrder)
+	}
+	if p.grease {
+		list = append([][]byte{randomGrease()}, list...)
+	}
+	return join(list)
+}
+
+func probeExtensions(p probe, host string) []byte {
+	var extensions []byte
+	if p.grease {
+		extensions = append(extensions, randomGrease()...)
+		extensions = append(extensions, 0x00, 0x00)
+	}
+
+	// server name
+	extensions = append(extensions, 0x00, 0x00)
+	extensions = appendUint16(extensions, len(host)+5)
+	extensions = appendUint16(extensions, len(host)+3)
+	extensions = append(extensions, 0x00)
+	extensions = appendUint16(extensions, len(host))
+	extensions = append(extensions, host...)
+
+	extensions = append(extensions, 0x00, 0x17, 0x00, 0x00)                                                             // extended master secret
+	extensions = append(extensions, 0x00, 0x01, 0x00, 0x01, 0x01)                                                       // max fragment length
+	extensions = append(extensions, 0xff, 0x01, 0x00, 0x01, 0x00)                                                       // renegotiation info
+	extensions = append(extensions, 0x00, 0x0a, 0x00, 0x0a, 0x00, 0x08, 0x00, 0x1d, 0x00, 0x17, 0x00, 0x18, 0x00, 0x19) // supported groups
+	extensions = append(extensions, 0x00, 0x0b, 0x00, 0x02, 0x01, 0x00)                                                 // ec point formats
+	extensions = append(extensions, 0x00, 0x23, 0x00, 0x00)                                                             // session ticket
+
+	// application layer protocol negotiation
+	protocols := alpns
+	if p.rareALPN {
+		protocols = rareALPNs
+	}
+	if p.extensionOrder != "FORWARD" {
+		protocols = mung(protocols, p.extensionOrder)
+	}
+	allProtocols := join(protocols)
+	extensions = append(extensions, 0x00, 0x10)
+	extensions = appendUint16(extensions, len(allProtocols)+2)
+	extensions = appendUint16(extensions, len(allProtocols))
+	extensions = append(extensions, allProtocols...)
+
+	// signature algorithms
+	extensions = append(extensions, 0x00, 0x0d, 0x00, 0x14, 0x00, 0x12, 0x04, 0x03, 0x08, 0x04, 0x04, 0x01, 0x05, 0x03, 0x08, 0x05, 0x05, 0x01, 0x08, 0x06, 0x06, 0x01, 0x02, 0x01)
+
+	// key share
+	var share []byte
+	if p.grease {
+		share = append(share, randomGrease()...)
+		share = append(share, 0x00, 0x01, 0x00)
+	}
+	share = append(share, 0x00, 0x1d, 0x00, 0x20)
+	share = append(share, randomBytes(32)...)
+	extensions = append(extensions, 0x00, 0x33)
+	extensions = appendUint16(extensions, len(share)+2)
+	extensions = appendUint16(extensions, len(share))
+	extensions = append(extensions, share...)
+
+	extensions = append(extensions, 0x00, 0x2d, 0x00, 0x02, 0x01, 0x01) // psk key exchange modes
+
+	// supported versions
+	if p.version == "TLS_1.3" || p.supportVersion == "1.2_SUPPORT" {
+		versions := [][]byte{{0x03, 0x01}, {0x03, 0x02}, {0x03, 0x03}}
+		if p.supportVersion != "1.2_SUPPORT" {
+			versions = append(versions, []byte{0x03, 0x04})
+		}
+		if p.extensionOrder != "FORWARD" {
+			versions = mung(versions, p.extensionOrder)
+		}
+		if p.grease {
+			versions = append([][]byte{randomGrease()}, versions...)
+		}
+		allVersions := join(versions)
+		extensions = append(extensions, 0x00, 0x2b)
+		extensions = appendUint16(extensions, len(allVersions)+1)
+		extensions = append(extensions, byte(len(allVersions)))
+		extensions = append(extensions, allVersions...)
+	}
+	return appendUint16(nil, len(extensions), extensions...)
+}
+
+// mung reorders the items as specified by the JARM probe
+func mung(items [][]byte, order string) [][]byte {
+	var output [][]byte
+	middle := len(items) / 2
+
+	switch order {
+	case "REVERSE":
+		for i := len(items) - 1; i >= 0; i-- {
+			output = append(output, items[i])
+		}
+	case "BOTTOM_HALF":
+		if len(items)%2 == 1 {
+			output = append(output, items[middle+1:]...)
+		} else {
+			output = append(output, items[middle:]...)
+		}
+	case "TOP_HALF":
+		if len(items)%2 == 1 {
+			output = append(output, items[middle])
+		}
+		output = append(output, mung(mung(items, "REVERSE"), "BOTTOM_HALF")...)
+	case "MIDDLE_OUT":
+		if len(items)%2 == 1 {
+			output = append(output, items[middle])
+			for i := 1; i <= middle; i++ {
+				output = append(output, items[middle+i], items[middle-i])
+			}
+		} else {
+			for i := 1; i <= middle; i++ {
+				output = append(output, items[middle-1+i], items[middle-i])
+			}
+		}
+	default:
+		output = items
+	}
+	return output
+}
+
+// serverHello contains the parsed fields of a server hello message
+type serverHello struct {
+	version    []byte
+	cipher     []byte
+	alpn       string
+	extensions [][]byte
+}
+
+// parseServerHello parses the server hello from the data read after a
+// client hello. Nil is returned if no server hello was found.
+func parseServerHello(data []byte) (hello *serverHello) {
+	defer func() {
+		// Truncated packets are treated as no response like the reference implementation
+		if recover() != nil {
+			hello = nil
+		}
+	}()
+	if len(data) < 44 || data[0] != 0x16 || data[5] != 0x02 {
+		return nil
+	}
+	serverHelloLength := int(binary.BigEndian.Uint16(data[3:5]))
+	counter := int(data[43])
+
+	hello = &serverHello{
+		version: data[9:11],
+		cipher:  data[counter+44 : counter+46],
+	}
+	if data[counter+47] == 11 {
+		return hello
+	}
+	if string(data[counter+50:counter+53]) == "\x0e\xac\x0b" || (len(data) >= 85 && string(data[82:85]) == "\x0f\xf0\x0b") {
+		return hello
+	}
+	if counter+42 >= serverHelloLength {
+		return hello
+	}
+
+	count := 49 + counter
+	maximum := int(binary.BigEndian.Uint16(data[counter+47:counter+49])) + count - 1
+	for count < maximum {
+		extensionType := data[count : count+2]
+		length := int(binary.BigEndian.Uint16(data[count+2 : count+4]))
+		value := data[count+4 : count+4+length]
+		if string(extensionType) == "\x00\x10" && length > 3 && hello.alpn == "" {
+			hello.alpn = string(value[3:])
+		}
+		hello.extensions = append(hello.extensions, extensionType)
+		count += length + 4
+	}
+	return hello
+}
+
+// jarmResult returns the raw JARM result of a server hello
+func (h *serverHello) jarmResult() string {
+	if h == nil {
+		return "|||"
+	}
+	extensions := make([]string, 0, len(h.extensions))
+	for _, extension := range h.extensions {
+		extensions = append(extensions, hex.EncodeToString(extension))
+	}
+	return fmt.Sprintf("%s|%s|%s|%s", hex.EncodeToString(h.cipher), hex.EncodeToString(h.version), h.alpn, strings.Join(extensions, "-"))
+}
+
+// jarmHash computes the JARM hash from the raw results of the probes
+func jarmHash(results []string) string {
+	empty := true
+	for _, result := range results {
+		if result != "|||" {
+			empty = false
+		}
+	}
+	if empty {
+		return emptyJARM
+	}
+
+	fuzzy := &strings.Builder{}
+	alpnsAndExtensions := &strings.Builder{}
+	for _, result := range results {
+		components := strings.Split(result, "|")
+		fuzzy.WriteString(cipherByte(components[0]))
+		fuzzy.WriteString(versionByte(components[1]))
+		alpnsAndExtensions.WriteString(components[2])
+		alpnsAndExtensions.WriteString(components[3])
+	}
+	hash := sha256.Sum256([]byte(alpnsAndExtensions.String()))
+	fuzzy.WriteString(hex.EncodeToString(hash[:])[:32])
+	return fuzzy.String()
+}
+
+func cipherByte(cipher string) string {
+	if cipher == "" {
+		return "00"
+	}
+	count := 1
+	for _, item := range hashCiphers {
+		if hex.EncodeToString(item) == cipher {
+			break
+		}
+		count++
+	}
+	return fmt.Sprintf("%02x", count)
+}
+
+func versionByte(version string) string {
+	if len(version) < 4 {
+		return "0"
+	}
+	index := int(version[3] - '0')
+	if index < 0 || index >= len("abcdef") {
+		return "0"
+	}
+	return string("abcdef"[index])
+}
+
+func join(items [][]byte) []byte {
+	var output []byte
+	for _, item := range items {
+		output = append(output, item...)
+	}
+	return output
+}
+
+func appendUint16(b []byte, value int, data ...byte) []byte {
+	b = append(b, byte(value>>8), byte(value))
+	return append(b, data...)
+}
+
+func randomBytes(n int) []byte {
+	b := make([]byte, n)
+	_, _ = rand.Read(b)
+	return b
+}
+
+func randomGrease() []byte {
+	n, _ := rand.Int(rand.Reader, big.NewInt(16))
+	value := byte(n.Int64())<<4 | 0x0a
+	return []byte{value, value}
+}
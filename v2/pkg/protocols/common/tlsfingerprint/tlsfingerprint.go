@@ -0,0 +1,139 @@
+// Package tlsfingerprint computes the JARM and JA3S fingerprints of tls servers.
+package tlsfingerprint
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/binary"
+	"encoding/hex"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DialFunc dials a plain tcp connection to an address
+type DialFunc func(ctx context.Context, network, address string) (net.Conn, error)
+
+// Fingerprint contains the tls fingerprints of a server
+type Fingerprint struct {
+	// JARM is the JARM hash of the server
+	JARM string
+	// JA3S is the raw JA3S string of the server
+	JA3S string
+	// JA3SHash is the md5 hash of the JA3S string
+	JA3SHash string
+}
+
+// Variables returns the fingerprint as template variables
+func (f *Fingerprint) Variables() map[string]interface{} {
+	return map[string]interface{}{
+		"jarm_hash": f.JARM,
+		"ja3s":      f.JA3S,
+		"ja3s_hash": f.JA3SHash,
+	}
+}
+
+// readSize is the number of bytes read for the server hello of a probe
+const readSize = 1484
+
+// Fingerprinter computes and caches tls fingerprints per address
+type Fingerprinter struct {
+	dial    DialFunc
+	timeout time.Duration
+
+	mutex sync.Mutex
+	cache map[string]*entry
+}
+
+type entry struct {
+	once        sync.Once
+	fingerprint *Fingerprint
+}
+
+// New creates a new fingerprinter dialing connections with dial
+func New(dial DialFunc, timeout time.Duration) *Fingerprinter {
+	return &Fingerprinter{dial: dial, timeout: timeout, cache: make(map[string]*entry)}
+}
+
+// Get returns the fingerprint of an address, computing it only once
+// even when requested concurrently by multiple templates.
+func (f *Fingerprinter) Get(address string) *Fingerprint {
+	f.mutex.Lock()
+	item, ok := f.cache[address]
+	if !ok {
+		item = &entry{}
+		f.cache[address] = item
+	}
+	f.mutex.Unlock()
+
+	item.once.Do(func() {
+		item.fingerprint = f.compute(address)
+	})
+	return item.fingerprint
+}
+
+func (f *Fingerprinter) compute(address string) *Fingerprint {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		host = address
+	}
+
+	fingerprint := &Fingerprint{}
+	results := make([]string, 0, len(probes))
+	for i, p := range probes {
+		hello := parseServerHello(f.send(address, buildProbe(p, host)))
+		results = append(results, hello.jarmResult())
+
+		// The first probe is a regular tls 1.2 hello used for JA3S
+		if i == 0 && hello != nil {
+			fingerprint.JA3S = hello.ja3s()
+			sum := md5.Sum([]byte(fingerprint.JA3S))
+			fingerprint.JA3SHash = hex.EncodeToString(sum[:])
+		}
+	}
+	fingerprint.JARM = jarmHash(results)
+	return fingerprint
+}
+
+// send writes a probe to the address and returns the response read
+func (f *Fingerprinter) send(address string, packet []byte) []byte {
+	ctx, cancel := context.WithTimeout(context.Background(), f.timeout)
+	defer cancel()
+
+	conn, err := f.dial(ctx, "tcp", address)
+	if err != nil {
+		return nil
+	}
+	defer conn.Close()
+	_ = conn.SetDeadline(time.Now().Add(f.timeout))
+
+	if _, err := conn.Write(packet); err != nil {
+		return nil
+	}
+	buffer := make([]byte, readSize)
+	n, _ := conn.Read(buffer)
+	return buffer[:n]
+}
+
+// ja3s returns the JA3S string of the server hello
+func (h *serverHello) ja3s() string {
+	extensions := make([]string, 0, len(h.extensions))
+	for _, extension := range h.extensions {
+		extensions = append(extensions, strconv.Itoa(int(binary.BigEndian.Uint16(extension))))
+	}
+	return strconv.Itoa(int(binary.BigEndian.Uint16(h.version))) + "," +
+		strconv.Itoa(int(binary.BigEndian.Uint16(h.cipher))) + "," +
+		strings.Join(extensions, "-")
+}
+
+// IsReferenced returns true if any of the parts or expressions use the fingerprint variables
+func IsReferenced(items ...string) bool {
+	for _, item := range items {
+		if strings.Contains(item, "jarm_hash") || strings.Contains(item, "ja3s") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,71 @@
+package tlsfingerprint
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMung(t *testing.T) {
+	items := [][]byte{{1}, {2}, {3}, {4}, {5}}
+
+	require.Equal(t, [][]byte{{5}, {4}, {3}, {2}, {1}}, mung(items, "REVERSE"), "could not reverse items")
+	require.Equal(t, [][]byte{{4}, {5}}, mung(items, "BOTTOM_HALF"), "could not get bottom half")
+	require.Equal(t, [][]byte{{3}, {2}, {1}}, mung(items, "TOP_HALF"), "could not get top half")
+	require.Equal(t, [][]byte{{3}, {4}, {2}, {5}, {1}}, mung(items, "MIDDLE_OUT"), "could not get middle out")
+}
+
+func TestJARMHash(t *testing.T) {
+	empty := make([]string, len(probes))
+	for i := range empty {
+		empty[i] = "|||"
+	}
+	require.Equal(t, emptyJARM, jarmHash(empty), "could not get empty hash")
+
+	results := append([]string{}, empty...)
+	results[0] = "c030|0303|h2|ff01-0010"
+	hash := jarmHash(results)
+	require.Len(t, hash, 62, "could not get hash of correct length")
+	require.True(t, strings.HasPrefix(hash, "2ad"+strings.Repeat("000", 9)), "could not get fuzzy hash")
+}
+
+func TestParseServerHello(t *testing.T) {
+	hello := []byte{0x03, 0x03}
+	hello = append(hello, make([]byte, 32)...) // random
+	hello = append(hello, 0x00)                // session id
+	hello = append(hello, 0xc0, 0x2f, 0x00)    // cipher and compression
+	extensions := []byte{0xff, 0x01, 0x00, 0x01, 0x00, 0x00, 0x10, 0x00, 0x05, 0x00, 0x03, 0x02, 'h', '2'}
+	hello = appendUint16(hello, len(extensions), extensions...)
+
+	handshake := append([]byte{0x02, 0x00, byte(len(hello) >> 8), byte(len(hello))}, hello...)
+	record := appendUint16([]byte{0x16, 0x03, 0x03}, len(handshake), handshake...)
+
+	parsed := parseServerHello(record)
+	require.NotNil(t, parsed, "could not parse server hello")
+	require.Equal(t, "c02f|0303|h2|ff01-0010", parsed.jarmResult(), "could not get jarm result")
+	require.Equal(t, "771,49199,65281-16", parsed.ja3s(), "could not get ja3s")
+
+	require.Nil(t, parseServerHello([]byte{0x15, 0x03, 0x03, 0x00, 0x02, 0x02, 0x28}), "could parse alert")
+	require.Nil(t, parseServerHello(append([]byte{}, record[:50]...)), "could parse truncated hello")
+}
+
+func TestFingerprinter(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	dialer := &net.Dialer{}
+	fingerprinter := New(dialer.DialContext, 5*time.Second)
+	address := strings.TrimPrefix(server.URL, "https://")
+
+	fingerprint := fingerprinter.Get(address)
+	require.Len(t, fingerprint.JARM, 62, "could not get jarm hash")
+	require.NotEqual(t, emptyJARM, fingerprint.JARM, "could not fingerprint server")
+	require.True(t, strings.HasPrefix(fingerprint.JA3S, "771,"), "could not get ja3s")
+	require.Len(t, fingerprint.JA3SHash, 32, "could not get ja3s hash")
+	require.True(t, fingerprint == fingerprinter.Get(address), "could not cache fingerprint")
+}
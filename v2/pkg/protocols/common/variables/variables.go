@@ -0,0 +1,70 @@
+// Package variables implements the template-level variables block.
+package variables
+
+import (
+	"net/url"
+
+	"github.com/pkg/errors"
+	"github.com/projectdiscovery/nuclei/v2/pkg/protocols/common/expressions"
+	"github.com/projectdiscovery/nuclei/v2/pkg/types"
+	"gopkg.in/yaml.v2"
+)
+
+// Variable is an ordered list of template variables. Values may contain
+// DSL expressions and can reference the previously declared variables.
+type Variable []Item
+
+// Item is a single variable of a template
+type Item struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// UnmarshalYAML decodes the variables mapping keeping the declaration order
+func (v *Variable) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var items yaml.MapSlice
+	if err := unmarshal(&items); err != nil {
+		return err
+	}
+	for _, item := range items {
+		name, ok := item.Key.(string)
+		if !ok || name == "" {
+			return errors.Errorf("invalid variable name %v", item.Key)
+		}
+		*v = append(*v, Item{Name: name, Value: types.ToString(item.Value)})
+	}
+	return nil
+}
+
+// ExpandEnv returns a copy of the variables with the ${NAME} references
+// to environment variables expanded in their values.
+func (v Variable) ExpandEnv() Variable {
+	expanded := make(Variable, len(v))
+	for i, item := range v {
+		expanded[i] = Item{Name: item.Name, Value: string(types.ExpandEnv([]byte(item.Value)))}
+	}
+	return expanded
+}
+
+// Evaluate evaluates the variables for an input in declaration order and
+// returns their values. Nothing is evaluated if no variables are declared.
+func (v Variable) Evaluate(input string) (map[string]interface{}, error) {
+	if len(v) == 0 {
+		return nil, nil
+	}
+	values := map[string]interface{}{"BaseURL": input, "Hostname": input}
+	if parsed, err := url.Parse(input); err == nil && parsed.Host != "" {
+		values["Hostname"] = parsed.Host
+	}
+
+	evaluated := make(map[string]interface{}, len(v))
+	for _, item := range v {
+		value, err := expressions.EvaluateStrict(item.Value, values)
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not evaluate variable %s", item.Name)
+		}
+		values[item.Name] = value
+		evaluated[item.Name] = value
+	}
+	return evaluated, nil
+}
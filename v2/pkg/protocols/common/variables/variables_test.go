@@ -0,0 +1,52 @@
+package variables
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v2"
+)
+
+func TestVariables(t *testing.T) {
+	data := `
+host: "{{Hostname}}"
+encoded: "{{base64(host)}}"
+path: "/{{encoded}}/admin"
+`
+	var variables Variable
+	err := yaml.Unmarshal([]byte(data), &variables)
+	require.Nil(t, err, "could not unmarshal variables")
+	require.Len(t, variables, 3, "could not get all variables")
+	require.Equal(t, "path", variables[2].Name, "could not keep variables order")
+
+	values, err := variables.Evaluate("https://example.com:8443/test")
+	require.Nil(t, err, "could not evaluate variables")
+	require.Equal(t, map[string]interface{}{
+		"host":    "example.com:8443",
+		"encoded": "ZXhhbXBsZS5jb206ODQ0Mw==",
+		"path":    "/ZXhhbXBsZS5jb206ODQ0Mw==/admin",
+	}, values, "could not evaluate variables")
+
+	values, err = Variable(nil).Evaluate("example.com")
+	require.Nil(t, err, "could not evaluate empty variables")
+	require.Nil(t, values, "could evaluate empty variables")
+
+	values, err = Variable{{Name: "callback", Value: "{{interactsh-url}}"}}.Evaluate("example.com")
+	require.Nil(t, err, "could not evaluate variable with unknown value")
+	require.Equal(t, "{{interactsh-url}}", values["callback"], "could not keep unknown value for later")
+
+	_, err = Variable{{Name: "port", Value: "{{Hostname * 2}}"}}.Evaluate("example.com")
+	require.NotNil(t, err, "could evaluate invalid expression")
+}
+
+func TestVariablesExpandEnv(t *testing.T) {
+	os.Setenv("NUCLEI_TEST_TOKEN", "secret")
+	defer os.Unsetenv("NUCLEI_TEST_TOKEN")
+
+	variables := Variable{{Name: "token", Value: "Bearer ${NUCLEI_TEST_TOKEN}"}, {Name: "payload", Value: "${jndi:ldap}"}}
+	expanded := variables.ExpandEnv()
+	require.Equal(t, "Bearer secret", expanded[0].Value, "could not expand environment variable")
+	require.Equal(t, "${jndi:ldap}", expanded[1].Value, "could not keep unknown reference")
+	require.Equal(t, "Bearer ${NUCLEI_TEST_TOKEN}", variables[0].Value, "could not keep original variables")
+}
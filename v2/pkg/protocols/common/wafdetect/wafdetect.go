@@ -0,0 +1,187 @@
+package wafdetect
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Detector tracks WAF and rate-limit responses per host and decides
+// whether the scan for that host should be slowed down or if intrusive
+// templates should be skipped for it.
+type Detector struct {
+	threshold int
+	baseDelay time.Duration
+	maxDelay  time.Duration
+
+	mutex sync.RWMutex
+	hosts map[string]*hostState
+}
+
+// hostState is the detection state for a single host
+type hostState struct {
+	hits    int
+	blocked bool
+	reason  string
+}
+
+// Options contains configuration options for the detector
+type Options struct {
+	// Threshold is the number of blocking responses after which the host
+	// is marked as blocked.
+	Threshold int
+	// BaseDelay is the delay added per blocking response seen for a host.
+	BaseDelay time.Duration
+	// MaxDelay is the maximum delay added before each request to a host.
+	MaxDelay time.Duration
+}
+
+// DefaultOptions are the default options for the detector
+var DefaultOptions = Options{
+	Threshold: 5,
+	BaseDelay: 200 * time.Millisecond,
+	MaxDelay:  5 * time.Second,
+}
+
+// fingerprint is a known WAF response signature
+type fingerprint struct {
+	name    string
+	headers []string
+	body    []string
+}
+
+// fingerprints contains signatures for commonly encountered WAFs.
+var fingerprints = []fingerprint{
+	{name: "cloudflare", headers: []string{"cf-ray", "cf-mitigated"}, body: []string{"attention required! | cloudflare", "cf-error-details"}},
+	{name: "akamai", headers: []string{"akamai-grn"}, body: []string{"access denied</title>", "reference&#32;&#35;"}},
+	{name: "imperva", headers: []string{"x-iinfo"}, body: []string{"incapsula incident id", "_incapsula_resource"}},
+	{name: "sucuri", headers: []string{"x-sucuri-id", "x-sucuri-block"}, body: []string{"sucuri website firewall"}},
+	{name: "aws-waf", headers: []string{"x-amzn-waf-action"}, body: []string{"request blocked. we can't connect to the server"}},
+	{name: "modsecurity", body: []string{"mod_security", "this error was generated by mod_security"}},
+	{name: "f5-bigip", headers: []string{"x-wa-info"}, body: []string{"the requested url was rejected. please consult with your administrator"}},
+}
+
+// New creates a new WAF detector with the provided options
+func New(options Options) *Detector {
+	if options.Threshold <= 0 {
+		options.Threshold = DefaultOptions.Threshold
+	}
+	if options.BaseDelay <= 0 {
+		options.BaseDelay = DefaultOptions.BaseDelay
+	}
+	if options.MaxDelay <= 0 {
+		options.MaxDelay = DefaultOptions.MaxDelay
+	}
+	return &Detector{
+		threshold: options.Threshold,
+		baseDelay: options.BaseDelay,
+		maxDelay:  options.MaxDelay,
+		hosts:     make(map[string]*hostState),
+	}
+}
+
+// Record records a response for the input and returns true if the
+// response was detected as a WAF block or rate-limit response.
+func (d *Detector) Record(input string, statusCode int, headers http.Header, body []byte) bool {
+	reason, ok := Detect(statusCode, headers, body)
+	if !ok {
+		return false
+	}
+	key := HostKey(input)
+
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	state, ok := d.hosts[key]
+	if !ok {
+		state = &hostState{}
+		d.hosts[key] = state
+	}
+	state.hits++
+	state.reason = reason
+	if state.hits >= d.threshold {
+		state.blocked = true
+	}
+	return true
+}
+
+// IsBlocked returns true if the host for input was marked as blocked along
+// with the reason for the decision.
+func (d *Detector) IsBlocked(input string) (string, bool) {
+	d.mutex.RLock()
+	defer d.mutex.RUnlock()
+
+	state, ok := d.hosts[HostKey(input)]
+	if !ok || !state.blocked {
+		return "", false
+	}
+	return state.reason, true
+}
+
+// Delay returns the adaptive delay to wait before sending a request to the input.
+func (d *Detector) Delay(input string) time.Duration {
+	d.mutex.RLock()
+	state, ok := d.hosts[HostKey(input)]
+	var hits int
+	if ok {
+		hits = state.hits
+	}
+	d.mutex.RUnlock()
+
+	if hits == 0 {
+		return 0
+	}
+	delay := time.Duration(hits) * d.baseDelay
+	if delay > d.maxDelay {
+		delay = d.maxDelay
+	}
+	return delay
+}
+
+// Detect returns the name of the protection if the response matches
+// a known WAF or rate-limit fingerprint.
+func Detect(statusCode int, headers http.Header, body []byte) (string, bool) {
+	if statusCode == http.StatusTooManyRequests {
+		return "rate-limit", true
+	}
+	if statusCode != http.StatusForbidden && statusCode != http.StatusNotAcceptable && statusCode != http.StatusServiceUnavailable {
+		return "", false
+	}
+
+	lowerBody := strings.ToLower(string(body))
+	for _, f := range fingerprints {
+		for _, header := range f.headers {
+			if headers.Get(header) != "" {
+				return f.name, true
+			}
+		}
+		for _, value := range f.body {
+			if strings.Contains(lowerBody, value) {
+				return f.name, true
+			}
+		}
+	}
+	if statusCode == http.StatusServiceUnavailable && headers.Get("Retry-After") != "" {
+		return "rate-limit", true
+	}
+	return "", false
+}
+
+// HostKey returns the normalized host key for an input
+func HostKey(input string) string {
+	if strings.Contains(input, "://") {
+		if parsed, err := url.Parse(input); err == nil && parsed.Hostname() != "" {
+			return strings.ToLower(parsed.Hostname())
+		}
+	}
+	host := input
+	if i := strings.IndexAny(host, "/?#"); i != -1 {
+		host = host[:i]
+	}
+	if i := strings.LastIndex(host, ":"); i != -1 && !strings.Contains(host[i:], "]") {
+		host = host[:i]
+	}
+	return strings.ToLower(strings.Trim(host, "[]"))
+}
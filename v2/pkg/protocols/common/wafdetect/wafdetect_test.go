@@ -0,0 +1,52 @@
+package wafdetect
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetect(t *testing.T) {
+	headers := http.Header{}
+	headers.Set("CF-Ray", "1234-AMS")
+
+	name, ok := Detect(http.StatusForbidden, headers, nil)
+	require.True(t, ok, "could not detect cloudflare header")
+	require.Equal(t, "cloudflare", name, "could not get correct waf")
+
+	name, ok = Detect(http.StatusTooManyRequests, http.Header{}, nil)
+	require.True(t, ok, "could not detect rate-limit")
+	require.Equal(t, "rate-limit", name, "could not get correct waf")
+
+	_, ok = Detect(http.StatusOK, headers, nil)
+	require.False(t, ok, "could detect waf on valid response")
+
+	_, ok = Detect(http.StatusForbidden, http.Header{}, []byte("forbidden"))
+	require.False(t, ok, "could detect waf on plain forbidden response")
+}
+
+func TestDetectorThreshold(t *testing.T) {
+	detector := New(Options{Threshold: 2, BaseDelay: time.Second, MaxDelay: 3 * time.Second})
+
+	require.True(t, detector.Record("https://example.com/a", http.StatusTooManyRequests, http.Header{}, nil))
+	_, blocked := detector.IsBlocked("example.com:443")
+	require.False(t, blocked, "host blocked before threshold")
+	require.Equal(t, time.Second, detector.Delay("http://example.com"), "could not get correct delay")
+
+	detector.Record("https://example.com/b", http.StatusTooManyRequests, http.Header{}, nil)
+	reason, blocked := detector.IsBlocked("example.com")
+	require.True(t, blocked, "host not blocked after threshold")
+	require.Equal(t, "rate-limit", reason, "could not get correct reason")
+
+	_, blocked = detector.IsBlocked("other.com")
+	require.False(t, blocked, "unrelated host blocked")
+}
+
+func TestHostKey(t *testing.T) {
+	require.Equal(t, "example.com", HostKey("https://Example.com:8443/path"))
+	require.Equal(t, "example.com", HostKey("example.com:80"))
+	require.Equal(t, "example.com", HostKey("example.com/test"))
+	require.Equal(t, "::1", HostKey("[::1]:80"))
+}
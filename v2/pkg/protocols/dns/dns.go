@@ -8,9 +8,10 @@ import (
 	"github.com/pkg/errors"
 	"github.com/projectdiscovery/nuclei/v2/pkg/operators"
 	"github.com/projectdiscovery/nuclei/v2/pkg/protocols"
+	"github.com/projectdiscovery/nuclei/v2/pkg/protocols/common/generators"
 	"github.com/projectdiscovery/nuclei/v2/pkg/protocols/common/replacer"
+	"github.com/projectdiscovery/nuclei/v2/pkg/protocols/common/retry"
 	"github.com/projectdiscovery/nuclei/v2/pkg/protocols/dns/dnsclientpool"
-	"github.com/projectdiscovery/retryabledns"
 )
 
 // Request contains a DNS protocol request to be made from a template
@@ -30,12 +31,14 @@ type Request struct {
 	Retries int `yaml:"retries"`
 
 	CompiledOperators *operators.Operators
-	dnsClient         *retryabledns.Client
+	dnsClient         dnsclientpool.Client
+	retryPolicy       *retry.Policy
 	options           *protocols.ExecuterOptions
 
 	// cache any variables that may be needed for operation.
-	class    uint16
-	question uint16
+	class      uint16
+	question   uint16
+	randomVars []string
 
 	// Recursion specifies whether to recurse all the answers.
 	Recursion bool `yaml:"recursion"`
@@ -48,14 +51,13 @@ func (r *Request) GetID() string {
 
 // Compile compiles the protocol request for further execution.
 func (r *Request) Compile(options *protocols.ExecuterOptions) error {
-	// Create a dns client for the class
-	client, err := dnsclientpool.Get(options.Options, &dnsclientpool.Configuration{
-		Retries: r.Retries,
-	})
+	// Create a dns client for the class, retries are handled by the retry policy.
+	client, err := dnsclientpool.Get(options.Options, &dnsclientpool.Configuration{})
 	if err != nil {
 		return errors.Wrap(err, "could not get dns client")
 	}
 	r.dnsClient = client
+	r.retryPolicy = retry.NewPolicy(options.Options, r.Retries)
 
 	if len(r.Matchers) > 0 || len(r.Extractors) > 0 {
 		compiled := &r.Operators
@@ -67,6 +69,7 @@ func (r *Request) Compile(options *protocols.ExecuterOptions) error {
 	r.class = classToInt(r.Class)
 	r.options = options
 	r.question = questionTypeToInt(r.Type)
+	r.randomVars = generators.RandomVariables(r.Name)
 	return nil
 }
 
@@ -76,7 +79,7 @@ func (r *Request) Requests() int {
 }
 
 // Make returns the request to be sent for the protocol
-func (r *Request) Make(domain string) (*dns.Msg, error) {
+func (r *Request) Make(domain string, randomValues map[string]interface{}) (*dns.Msg, error) {
 	if r.question != dns.TypePTR && net.ParseIP(domain) != nil {
 		return nil, errors.New("cannot use IP address as DNS input")
 	}
@@ -89,7 +92,8 @@ func (r *Request) Make(domain string) (*dns.Msg, error) {
 
 	var q dns.Question
 
-	final := replacer.Replace(r.Name, map[string]interface{}{"FQDN": domain})
+	values := generators.MergeMaps(randomValues, map[string]interface{}{"FQDN": domain})
+	final := replacer.Replace(r.Name, values)
 
 	q.Name = dns.Fqdn(final)
 	q.Qclass = r.class
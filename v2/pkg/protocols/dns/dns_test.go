@@ -27,7 +27,7 @@ func TestDNSCompileMake(t *testing.T) {
 	err := request.Compile(executerOpts)
 	require.Nil(t, err, "could not compile dns request")
 
-	req, err := request.Make("one.one.one.one")
+	req, err := request.Make("one.one.one.one", nil)
 	require.Nil(t, err, "could not make dns request")
 	require.Equal(t, "one.one.one.one.", req.Question[0].Name, "could not get correct dns question")
 }
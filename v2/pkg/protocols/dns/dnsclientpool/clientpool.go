@@ -1,18 +1,29 @@
 package dnsclientpool
 
 import (
+	"math/rand"
 	"strconv"
 	"strings"
 	"sync"
+	"time"
 
+	"github.com/miekg/dns"
+	"github.com/pkg/errors"
+	"github.com/projectdiscovery/nuclei/v2/pkg/protocols/common/protocolstate"
 	"github.com/projectdiscovery/nuclei/v2/pkg/types"
 	"github.com/projectdiscovery/retryabledns"
 )
 
+// Client sends the dns queries to the resolvers
+type Client interface {
+	// Do sends a dns query returning the response
+	Do(msg *dns.Msg) (*dns.Msg, error)
+}
+
 var (
 	poolMutex    *sync.RWMutex
-	normalClient *retryabledns.Client
-	clientPool   map[string]*retryabledns.Client
+	normalClient Client
+	clientPool   map[string]Client
 )
 
 // defaultResolvers contains the list of resolvers known to be trusted.
@@ -30,13 +41,13 @@ func Init(options *types.Options) error {
 		return nil
 	}
 	poolMutex = &sync.RWMutex{}
-	clientPool = make(map[string]*retryabledns.Client)
+	clientPool = make(map[string]Client)
 
 	resolvers := defaultResolvers
-	if options.ResolversFile != "" {
+	if options.ResolversFile != "" && len(options.InternalResolversList) > 0 {
 		resolvers = options.InternalResolversList
 	}
-	normalClient = retryabledns.New(resolvers, 1)
+	normalClient = newClient(resolvers, 1)
 	return nil
 }
 
@@ -57,7 +68,7 @@ func (c *Configuration) Hash() string {
 }
 
 // Get creates or gets a client for the protocol based on custom configuration
-func Get(options *types.Options, configuration *Configuration) (*retryabledns.Client, error) {
+func Get(options *types.Options, configuration *Configuration) (Client, error) {
 	if !(configuration.Retries > 1) {
 		return normalClient, nil
 	}
@@ -70,13 +81,46 @@ func Get(options *types.Options, configuration *Configuration) (*retryabledns.Cl
 	poolMutex.RUnlock()
 
 	resolvers := defaultResolvers
-	if options.ResolversFile != "" {
+	if options.ResolversFile != "" && len(options.InternalResolversList) > 0 {
 		resolvers = options.InternalResolversList
 	}
-	client := retryabledns.New(resolvers, configuration.Retries)
+	client := newClient(resolvers, configuration.Retries)
 
 	poolMutex.Lock()
 	clientPool[hash] = client
 	poolMutex.Unlock()
 	return client, nil
 }
+
+// newClient returns a client for the resolvers, binding the queries to the
+// source address of the connections if one is configured.
+func newClient(resolvers []string, retries int) Client {
+	if protocolstate.SourceIP == nil {
+		return retryabledns.New(resolvers, retries)
+	}
+	return &sourceClient{
+		resolvers: resolvers,
+		retries:   retries,
+		client:    &dns.Client{Net: "udp", Dialer: protocolstate.SourceDialer(protocolstate.SourceIP, "udp", 5*time.Second)},
+	}
+}
+
+// sourceClient sends the dns queries from the source address to random resolvers
+type sourceClient struct {
+	resolvers []string
+	retries   int
+	client    *dns.Client
+}
+
+// Do sends a dns query returning the response
+func (c *sourceClient) Do(msg *dns.Msg) (*dns.Msg, error) {
+	err := errors.New("no resolvers configured")
+	for i := 0; i < c.retries && len(c.resolvers) > 0; i++ {
+		resolver := c.resolvers[rand.Intn(len(c.resolvers))]
+		var resp *dns.Msg
+		if resp, _, err = c.client.Exchange(msg, resolver); err == nil {
+			return resp, nil
+		}
+	}
+	return nil, err
+}
@@ -0,0 +1,209 @@
+// Package dnsdataset answers the DNS requests of the templates from a
+// zone file or passive DNS dataset instead of sending live queries.
+package dnsdataset
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/miekg/dns"
+	"github.com/pkg/errors"
+)
+
+// maxCNAMEChain is the maximum number of CNAME records followed for a name
+const maxCNAMEChain = 8
+
+// Dataset contains the DNS records of the dataset keyed by the fully
+// qualified lowercase name. The dataset is authoritative for the zones of
+// its SOA records, where the names absent from the dataset don't exist.
+type Dataset struct {
+	records map[string][]dns.RR
+	zones   map[string]struct{}
+}
+
+// passiveRecord is a record of a passive DNS dataset in the common output
+// format, with the rdata as a string or a list of strings.
+type passiveRecord struct {
+	RRName string      `json:"rrname"`
+	RRType string      `json:"rrtype"`
+	RData  interface{} `json:"rdata"`
+}
+
+// Load loads a dataset from a zone file, or a passive DNS file of JSON lines
+// if the file has a .json or .jsonl extension.
+func Load(path string) (*Dataset, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not open dns dataset")
+	}
+	defer file.Close()
+
+	dataset := &Dataset{records: make(map[string][]dns.RR), zones: make(map[string]struct{})}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json", ".jsonl":
+		err = dataset.loadPassive(file)
+	default:
+		err = dataset.loadZone(file, path)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return dataset, nil
+}
+
+// loadZone loads the records of a zone file
+func (d *Dataset) loadZone(file *os.File, path string) error {
+	parser := dns.NewZoneParser(file, "", path)
+	for rr, ok := parser.Next(); ok; rr, ok = parser.Next() {
+		d.Add(rr)
+	}
+	if err := parser.Err(); err != nil {
+		return errors.Wrap(err, "could not parse zone file")
+	}
+	return nil
+}
+
+// loadPassive loads the records of a passive DNS file
+func (d *Dataset) loadPassive(file *os.File) error {
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+		record := &passiveRecord{}
+		if err := json.Unmarshal([]byte(text), record); err != nil {
+			return errors.Wrapf(err, "could not parse passive dns record on line %d", line)
+		}
+		var values []string
+		switch rdata := record.RData.(type) {
+		case string:
+			values = []string{rdata}
+		case []interface{}:
+			for _, value := range rdata {
+				if value, ok := value.(string); ok {
+					values = append(values, value)
+				}
+			}
+		}
+		for _, value := range values {
+			rr, err := dns.NewRR(dns.Fqdn(record.RRName) + " IN " + strings.ToUpper(record.RRType) + " " + value)
+			if err != nil {
+				return errors.Wrapf(err, "could not parse passive dns record on line %d", line)
+			}
+			if rr != nil {
+				d.Add(rr)
+			}
+		}
+	}
+	return scanner.Err()
+}
+
+// Add adds a record to the dataset
+func (d *Dataset) Add(rr dns.RR) {
+	name := normalize(rr.Header().Name)
+	d.records[name] = append(d.records[name], rr)
+	if rr.Header().Rrtype == dns.TypeSOA {
+		d.zones[name] = struct{}{}
+	}
+}
+
+// Exchange answers a DNS message from the records of the dataset. CNAME
+// records are followed inside the dataset, a CNAME to a missing name of
+// a zone of the dataset returning NXDOMAIN as a dangling record does.
+//
+// The dataset can't tell whether the names outside of its zones exist, the
+// questions for such names are refused and the CNAME chains leaving the
+// zones are answered with SERVFAIL.
+func (d *Dataset) Exchange(msg *dns.Msg) *dns.Msg {
+	reply := new(dns.Msg)
+	reply.SetReply(msg)
+	if len(msg.Question) == 0 {
+		reply.Rcode = dns.RcodeFormatError
+		return reply
+	}
+	question := msg.Question[0]
+
+	name := question.Name
+	for i := 0; i < maxCNAMEChain; i++ {
+		records, ok := d.lookup(name)
+		if !ok {
+			switch {
+			case d.authoritative(name):
+				reply.Rcode = dns.RcodeNameError
+			case i == 0:
+				reply.Rcode = dns.RcodeRefused
+			default:
+				reply.Rcode = dns.RcodeServerFailure
+			}
+			return reply
+		}
+		var cname *dns.CNAME
+		var answered bool
+		for _, rr := range records {
+			if rr.Header().Rrtype == question.Qtype || question.Qtype == dns.TypeANY {
+				reply.Answer = append(reply.Answer, withName(rr, name))
+				answered = true
+			} else if record, ok := rr.(*dns.CNAME); ok && cname == nil {
+				cname = record
+			}
+		}
+		if answered || cname == nil {
+			return reply
+		}
+		reply.Answer = append(reply.Answer, withName(cname, name))
+		name = cname.Target
+	}
+	return reply
+}
+
+// lookup returns the records of a name, using the wildcard records of the
+// closest parent domain if the name has no records.
+func (d *Dataset) lookup(name string) ([]dns.RR, bool) {
+	name = normalize(name)
+	if records, ok := d.records[name]; ok {
+		return records, true
+	}
+	labels := dns.SplitDomainName(name)
+	for i := 1; i < len(labels); i++ {
+		parent := strings.Join(labels[i:], ".") + "."
+		if records, ok := d.records["*."+parent]; ok {
+			return records, true
+		}
+		if _, ok := d.records[parent]; ok {
+			break
+		}
+	}
+	return nil, false
+}
+
+// authoritative returns true if the name is in a zone of the dataset
+func (d *Dataset) authoritative(name string) bool {
+	labels := dns.SplitDomainName(normalize(name))
+	for i := range labels {
+		if _, ok := d.zones[strings.Join(labels[i:], ".")+"."]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// withName returns a copy of a record with the name of the question,
+// used for the answers synthesized from wildcard records.
+func withName(rr dns.RR, name string) dns.RR {
+	if strings.EqualFold(rr.Header().Name, name) {
+		return rr
+	}
+	copied := dns.Copy(rr)
+	copied.Header().Name = name
+	return copied
+}
+
+// normalize returns the normalized form of a record name
+func normalize(name string) string {
+	return strings.ToLower(dns.Fqdn(name))
+}
@@ -0,0 +1,89 @@
+package dnsdataset
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/require"
+)
+
+const zone = `$ORIGIN example.com.
+$TTL 3600
+@        IN SOA   ns1.example.com. admin.example.com. 1 7200 3600 1209600 3600
+@        IN A     192.0.2.1
+www      IN CNAME example.com.
+*.apps   IN A     192.0.2.10
+blog     IN CNAME example-blog.s3.amazonaws.com.
+old      IN CNAME removed.example.com.
+`
+
+const passive = `{"rrname":"api.example.org","rrtype":"A","rdata":["198.51.100.1","198.51.100.2"]}
+{"rrname":"mail.example.org","rrtype":"MX","rdata":"10 mx.example.org."}
+`
+
+func writeDataset(t *testing.T, name, data string) *Dataset {
+	tempDir, err := ioutil.TempDir("", "test-*")
+	require.Nil(t, err, "could not create temporary directory")
+	t.Cleanup(func() { os.RemoveAll(tempDir) })
+
+	path := filepath.Join(tempDir, name)
+	require.Nil(t, ioutil.WriteFile(path, []byte(data), 0644), "could not write dataset")
+	dataset, err := Load(path)
+	require.Nil(t, err, "could not load dataset")
+	return dataset
+}
+
+func query(dataset *Dataset, name string, qtype uint16) *dns.Msg {
+	msg := new(dns.Msg)
+	msg.SetQuestion(dns.Fqdn(name), qtype)
+	return dataset.Exchange(msg)
+}
+
+func TestZoneDataset(t *testing.T) {
+	dataset := writeDataset(t, "example.com.zone", zone)
+
+	reply := query(dataset, "WWW.example.com", dns.TypeA)
+	require.Equal(t, dns.RcodeSuccess, reply.Rcode, "could not resolve cname")
+	require.Len(t, reply.Answer, 2, "could not follow cname")
+	require.Equal(t, "192.0.2.1", reply.Answer[1].(*dns.A).A.String(), "could not get cname target address")
+
+	reply = query(dataset, "random.apps.example.com", dns.TypeA)
+	require.Len(t, reply.Answer, 1, "could not resolve wildcard")
+	require.Equal(t, "random.apps.example.com.", reply.Answer[0].Header().Name, "could not synthesize wildcard name")
+
+	reply = query(dataset, "old.example.com", dns.TypeA)
+	require.Equal(t, dns.RcodeNameError, reply.Rcode, "could not get nxdomain for dangling cname")
+	require.Len(t, reply.Answer, 1, "could not get dangling cname")
+
+	// The names outside of the zone are not known to be missing
+	reply = query(dataset, "blog.example.com", dns.TypeA)
+	require.Equal(t, dns.RcodeServerFailure, reply.Rcode, "could not get servfail for cname outside of zone")
+	require.Len(t, reply.Answer, 1, "could not get cname outside of zone")
+
+	reply = query(dataset, "example.net", dns.TypeA)
+	require.Equal(t, dns.RcodeRefused, reply.Rcode, "could not refuse name outside of zone")
+
+	reply = query(dataset, "missing.example.com", dns.TypeA)
+	require.Equal(t, dns.RcodeNameError, reply.Rcode, "could resolve missing name")
+
+	reply = query(dataset, "example.com", dns.TypeAAAA)
+	require.Equal(t, dns.RcodeSuccess, reply.Rcode, "could not get existing name without records")
+	require.Empty(t, reply.Answer, "could get records of another type")
+}
+
+func TestPassiveDataset(t *testing.T) {
+	dataset := writeDataset(t, "passive.jsonl", passive)
+
+	reply := query(dataset, "api.example.org", dns.TypeA)
+	require.Len(t, reply.Answer, 2, "could not get passive records")
+
+	reply = query(dataset, "mail.example.org", dns.TypeMX)
+	require.Len(t, reply.Answer, 1, "could not get passive mx record")
+	require.Equal(t, "mx.example.org.", reply.Answer[0].(*dns.MX).Mx, "could not parse passive mx record")
+
+	reply = query(dataset, "missing.example.org", dns.TypeA)
+	require.Equal(t, dns.RcodeRefused, reply.Rcode, "could get nxdomain without zone")
+}
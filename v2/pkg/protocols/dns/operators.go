@@ -60,6 +60,8 @@ func (r *Request) Extract(data map[string]interface{}, extractor *extractors.Ext
 		return extractor.ExtractRegex(itemStr)
 	case extractors.KValExtractor:
 		return extractor.ExtractKval(data)
+	case extractors.DSLExtractor:
+		return extractor.ExtractDSL(data)
 	}
 	return nil
 }
@@ -116,7 +118,7 @@ func (r *Request) MakeResultEvent(wrapped *output.InternalWrappedEvent) []*outpu
 
 	// If we have multiple matchers with names, write each of them separately.
 	if len(wrapped.OperatorsResult.Matches) > 0 {
-		for k := range wrapped.OperatorsResult.Matches {
+		for _, k := range wrapped.OperatorsResult.MatcherNames() {
 			data := r.makeResultEventItem(wrapped)
 			data.MatcherName = k
 			results = append(results, data)
@@ -145,6 +147,7 @@ func (r *Request) makeResultEventItem(wrapped *output.InternalWrappedEvent) *out
 		Matched:          types.ToString(wrapped.InternalEvent["matched"]),
 		ExtractedResults: wrapped.OperatorsResult.OutputExtracts,
 		Timestamp:        time.Now(),
+		ResponseHash:     output.ResponseHash(types.ToString(wrapped.InternalEvent["raw"])),
 	}
 	if r.options.Options.JSONRequests {
 		data.Request = types.ToString(wrapped.InternalEvent["request"])
@@ -2,17 +2,22 @@ package dns
 
 import (
 	"net/url"
+	"time"
 
+	"github.com/miekg/dns"
 	"github.com/pkg/errors"
 	"github.com/projectdiscovery/gologger"
 	"github.com/projectdiscovery/nuclei/v2/pkg/output"
 	"github.com/projectdiscovery/nuclei/v2/pkg/protocols"
+	"github.com/projectdiscovery/nuclei/v2/pkg/protocols/common/generators"
+	"github.com/projectdiscovery/nuclei/v2/pkg/protocols/common/protocolstate"
 )
 
 var _ protocols.Request = &Request{}
 
 // ExecuteWithResults executes the protocol requests and returns results instead of writing them.
 func (r *Request) ExecuteWithResults(input string, metadata, previous output.InternalEvent, callback protocols.OutputEventCallback) error {
+	started := time.Now()
 	// Parse the URL and return domain if URL.
 	var domain string
 	if isURL(input) {
@@ -22,7 +27,8 @@ func (r *Request) ExecuteWithResults(input string, metadata, previous output.Int
 	}
 
 	// Compile each request for the template based on the URL
-	compiledRequest, err := r.Make(domain)
+	randomValues := generators.RandomValues(r.randomVars)
+	compiledRequest, err := r.Make(domain, generators.MergeMaps(metadata, randomValues))
 	if err != nil {
 		r.options.Output.Request(r.options.TemplateID, domain, "dns", err)
 		r.options.Progress.IncrementFailedRequestsBy(1)
@@ -34,10 +40,22 @@ func (r *Request) ExecuteWithResults(input string, metadata, previous output.Int
 		gologger.Print().Msgf("%s", compiledRequest.String())
 	}
 
-	// Send the request to the target servers
-	resp, err := r.dnsClient.Do(compiledRequest)
+	// Send the request to the target servers, or answer it from the dataset
+	var resp *dns.Msg
+	err = r.retryPolicy.Do(func() error {
+		var doErr error
+		if protocolstate.DNSDataset != nil {
+			resp = protocolstate.DNSDataset.Exchange(compiledRequest)
+		} else if protocolstate.Resolver != nil && protocolstate.Resolver.Handles(compiledRequest) {
+			resp, doErr = protocolstate.Resolver.Do(compiledRequest)
+		} else {
+			resp, doErr = r.dnsClient.Do(compiledRequest)
+		}
+		return doErr
+	})
 	if err != nil {
 		r.options.Output.Request(r.options.TemplateID, domain, "dns", err)
+		r.options.AuditLog.Log(r.options.TemplateID, "dns", domain, compiledRequest.Len(), 0, err)
 		r.options.Progress.IncrementFailedRequestsBy(1)
 	}
 	if resp == nil {
@@ -46,6 +64,7 @@ func (r *Request) ExecuteWithResults(input string, metadata, previous output.Int
 	r.options.Progress.IncrementRequests()
 
 	r.options.Output.Request(r.options.TemplateID, domain, "dns", err)
+	r.options.AuditLog.Log(r.options.TemplateID, "dns", domain, compiledRequest.Len(), resp.Len(), nil)
 	gologger.Verbose().Msgf("[%s] Sent DNS request to %s", r.options.TemplateID, domain)
 
 	if r.options.Options.Debug || r.options.Options.DebugResponse {
@@ -56,8 +75,11 @@ func (r *Request) ExecuteWithResults(input string, metadata, previous output.Int
 	for k, v := range previous {
 		outputEvent[k] = v
 	}
+	for k, v := range randomValues {
+		outputEvent[k] = v
+	}
 
-	event := &output.InternalWrappedEvent{InternalEvent: outputEvent}
+	event := &output.InternalWrappedEvent{InternalEvent: outputEvent, Started: started}
 	if r.CompiledOperators != nil {
 		result, ok := r.CompiledOperators.Execute(outputEvent, r.Match, r.Extract)
 		if ok && result != nil {
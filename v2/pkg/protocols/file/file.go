@@ -29,6 +29,7 @@ type Request struct {
 	options           *protocols.ExecuterOptions
 	extensions        map[string]struct{}
 	extensionDenylist map[string]struct{}
+	sandbox           *Sandbox
 
 	// NoRecursive specifies whether to not do recursive checks if folders are provided.
 	NoRecursive bool `yaml:"no-recursive"`
@@ -59,6 +60,12 @@ func (r *Request) Compile(options *protocols.ExecuterOptions) error {
 	}
 	r.options = options
 
+	sandbox, err := NewSandbox(options.Options.FileAllowlist)
+	if err != nil {
+		return errors.Wrap(err, "could not create file sandbox")
+	}
+	r.sandbox = sandbox
+
 	r.extensions = make(map[string]struct{})
 	r.extensionDenylist = make(map[string]struct{})
 
@@ -53,7 +53,7 @@ func (r *Request) findGlobPathMatches(absPath string, processed map[string]struc
 		return errors.Errorf("wildcard found, but unable to glob: %s\n", err)
 	}
 	for _, match := range matches {
-		if !r.validatePath(match) {
+		if !r.validatePath(match, absPath) {
 			continue
 		}
 		if _, ok := processed[match]; !ok {
@@ -75,7 +75,7 @@ func (r *Request) findFileMatches(absPath string, processed map[string]struct{},
 		return false, nil
 	}
 	if _, ok := processed[absPath]; !ok {
-		if !r.validatePath(absPath) {
+		if !r.validatePath(absPath, absPath) {
 			return false, nil
 		}
 		processed[absPath] = struct{}{}
@@ -95,7 +95,7 @@ func (r *Request) findDirectoryMatches(absPath string, processed map[string]stru
 			if d.IsDir() {
 				return nil
 			}
-			if !r.validatePath(path) {
+			if !r.validatePath(path, absPath) {
 				return nil
 			}
 			if _, ok := processed[path]; !ok {
@@ -108,8 +108,8 @@ func (r *Request) findDirectoryMatches(absPath string, processed map[string]stru
 	return err
 }
 
-// validatePath validates a file path for blacklist and whitelist options
-func (r *Request) validatePath(item string) bool {
+// validatePath validates a file path found for the input for blacklist and whitelist options
+func (r *Request) validatePath(item, input string) bool {
 	extension := path.Ext(item)
 
 	if len(r.extensions) > 0 {
@@ -123,5 +123,9 @@ func (r *Request) validatePath(item string) bool {
 		gologger.Verbose().Msgf("Ignoring path %s due to denylist item %s\n", item, extension)
 		return false
 	}
+	if !r.sandbox.Allowed(item, input) {
+		gologger.Verbose().Msgf("Ignoring path %s outside of the file sandbox\n", item)
+		return false
+	}
 	return true
 }
@@ -59,6 +59,8 @@ func (r *Request) Extract(data map[string]interface{}, extractor *extractors.Ext
 		return extractor.ExtractRegex(itemStr)
 	case extractors.KValExtractor:
 		return extractor.ExtractKval(data)
+	case extractors.DSLExtractor:
+		return extractor.ExtractDSL(data)
 	}
 	return nil
 }
@@ -86,7 +88,7 @@ func (r *Request) MakeResultEvent(wrapped *output.InternalWrappedEvent) []*outpu
 
 	// If we have multiple matchers with names, write each of them separately.
 	if len(wrapped.OperatorsResult.Matches) > 0 {
-		for k := range wrapped.OperatorsResult.Matches {
+		for _, k := range wrapped.OperatorsResult.MatcherNames() {
 			data := r.makeResultEventItem(wrapped)
 			data.MatcherName = k
 			results = append(results, data)
@@ -143,6 +145,7 @@ func (r *Request) makeResultEventItem(wrapped *output.InternalWrappedEvent) *out
 		Host:             types.ToString(wrapped.InternalEvent["matched"]),
 		ExtractedResults: wrapped.OperatorsResult.OutputExtracts,
 		Timestamp:        time.Now(),
+		ResponseHash:     output.ResponseHash(types.ToString(wrapped.InternalEvent["raw"])),
 	}
 	if r.options.Options.JSONRequests {
 		data.Response = types.ToString(wrapped.InternalEvent["raw"])
@@ -1,8 +1,9 @@
 package file
 
 import (
+	"io"
 	"io/ioutil"
-	"os"
+	"time"
 
 	"github.com/pkg/errors"
 	"github.com/projectdiscovery/gologger"
@@ -23,8 +24,9 @@ func (r *Request) ExecuteWithResults(input string, metadata, previous output.Int
 
 		go func(data string) {
 			defer wg.Done()
+			started := time.Now()
 
-			file, err := os.Open(data)
+			file, err := r.sandbox.Open(data, input)
 			if err != nil {
 				gologger.Error().Msgf("Could not open file path %s: %s\n", data, err)
 				return
@@ -41,7 +43,8 @@ func (r *Request) ExecuteWithResults(input string, metadata, previous output.Int
 				return
 			}
 
-			buffer, err := ioutil.ReadAll(file)
+			// The read is bounded as the file may have grown since it was checked
+			buffer, err := ioutil.ReadAll(io.LimitReader(file, int64(r.MaxSize)))
 			if err != nil {
 				gologger.Error().Msgf("Could not read file path %s: %s\n", data, err)
 				return
@@ -57,7 +60,7 @@ func (r *Request) ExecuteWithResults(input string, metadata, previous output.Int
 				outputEvent[k] = v
 			}
 
-			event := &output.InternalWrappedEvent{InternalEvent: outputEvent}
+			event := &output.InternalWrappedEvent{InternalEvent: outputEvent, Started: started}
 			if r.CompiledOperators != nil {
 				result, ok := r.CompiledOperators.Execute(outputEvent, r.Match, r.Extract)
 				if ok && result != nil {
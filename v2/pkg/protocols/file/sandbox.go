@@ -0,0 +1,173 @@
+package file
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// deniedPaths are the sensitive system paths the file templates can't read
+// unless a directory inside them is explicitly allowed.
+var deniedPaths = []string{"/proc", "/sys", "/dev", "/etc/shadow", "/etc/gshadow", "/etc/sudoers", "/etc/ssl/private"}
+
+// deniedHomePaths are the sensitive paths of the home directory
+var deniedHomePaths = []string{".ssh", ".aws", ".azure", ".gnupg", ".docker", ".kube", ".netrc", ".config/gcloud", ".config/nuclei"}
+
+// Sandbox restricts the files read by the file templates to the allowed
+// directories, or to the input paths of the scan if none are allowed,
+// outside of the sensitive system paths. Symbolic links are resolved so
+// they can't be used to escape the sandbox.
+type Sandbox struct {
+	allowed []string
+	denied  []string
+}
+
+// NewSandbox creates a new sandbox for the allowed directories. Only the
+// input paths are allowed if no directories are provided.
+func NewSandbox(allowed []string) (*Sandbox, error) {
+	sandbox := &Sandbox{}
+	for _, value := range allowed {
+		for _, item := range strings.Split(value, ",") {
+			item = strings.TrimSpace(item)
+			if item == "" {
+				continue
+			}
+			resolved, err := resolvePath(item)
+			if err != nil {
+				return nil, errors.Wrapf(err, "could not resolve allowed path %s", item)
+			}
+			sandbox.allowed = append(sandbox.allowed, resolved)
+		}
+	}
+
+	denied := append([]string{}, deniedPaths...)
+	if home, err := os.UserHomeDir(); err == nil {
+		for _, item := range deniedHomePaths {
+			denied = append(denied, filepath.Join(home, item))
+		}
+	}
+	for _, item := range denied {
+		if resolved, err := resolvePath(item); err == nil {
+			item = resolved
+		}
+		sandbox.denied = append(sandbox.denied, item)
+	}
+	return sandbox, nil
+}
+
+// Allowed returns true if the file of a path found for the input can be
+// read by the templates. Only regular files are allowed as devices and
+// pipes may block forever.
+func (s *Sandbox) Allowed(path, input string) bool {
+	if s == nil {
+		return true
+	}
+	resolved, err := resolvePath(path)
+	if err != nil {
+		return false
+	}
+	if info, err := os.Stat(resolved); err != nil || !info.Mode().IsRegular() {
+		return false
+	}
+	return s.allowedPath(resolved, input)
+}
+
+// Open opens the file of a path found for the input if it can be read by
+// the templates. The resolved path is opened and checked to still be the
+// same file once opened, so that the path can't be swapped for a symbolic
+// link escaping the sandbox after it was checked.
+func (s *Sandbox) Open(path, input string) (*os.File, error) {
+	if s == nil {
+		return os.Open(path)
+	}
+	resolved, err := resolvePath(path)
+	if err != nil {
+		return nil, err
+	}
+	if !s.allowedPath(resolved, input) {
+		return nil, errors.Errorf("path %s is outside of the file sandbox", path)
+	}
+	file, err := os.Open(resolved)
+	if err != nil {
+		return nil, err
+	}
+	opened, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	current, err := os.Stat(resolved)
+	if err != nil || !opened.Mode().IsRegular() || !os.SameFile(opened, current) {
+		file.Close()
+		return nil, errors.Errorf("path %s changed while being opened", path)
+	}
+	if resolvedAgain, err := resolvePath(path); err != nil || resolvedAgain != resolved {
+		file.Close()
+		return nil, errors.Errorf("path %s changed while being opened", path)
+	}
+	return file, nil
+}
+
+// allowedPath returns true if the resolved path is within the allowed
+// directories, or the input if none are allowed, and not denied.
+func (s *Sandbox) allowedPath(resolved, input string) bool {
+	if len(s.allowed) > 0 {
+		if !s.inAllowed(resolved, "") {
+			return false
+		}
+	} else {
+		root, err := resolvePath(inputRoot(input))
+		if err != nil || !isWithin(resolved, root) {
+			return false
+		}
+	}
+	for _, denied := range s.denied {
+		if isWithin(resolved, denied) && !s.inAllowed(resolved, denied) {
+			return false
+		}
+	}
+	return true
+}
+
+// inputRoot returns the path the files of an input are found in, which is
+// the directory before the first wildcard of glob inputs.
+func inputRoot(input string) string {
+	if index := strings.IndexAny(input, "*?["); index >= 0 {
+		return filepath.Dir(input[:index])
+	}
+	return input
+}
+
+// inAllowed returns true if the path is within an allowed directory,
+// which itself is within the parent directory if any.
+func (s *Sandbox) inAllowed(path, parent string) bool {
+	for _, allowed := range s.allowed {
+		if parent != "" && !isWithin(allowed, parent) {
+			continue
+		}
+		if isWithin(path, allowed) {
+			return true
+		}
+	}
+	return false
+}
+
+// resolvePath returns the absolute path with the symbolic links resolved
+func resolvePath(path string) (string, error) {
+	absolute, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+	return filepath.EvalSymlinks(absolute)
+}
+
+// isWithin returns true if the path is the directory or is inside it
+func isWithin(path, directory string) bool {
+	relative, err := filepath.Rel(directory, path)
+	if err != nil {
+		return false
+	}
+	return relative == "." || (relative != ".." && !strings.HasPrefix(relative, ".."+string(filepath.Separator)))
+}
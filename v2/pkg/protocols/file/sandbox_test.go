@@ -0,0 +1,49 @@
+package file
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSandbox(t *testing.T) {
+	tempDir, err := ioutil.TempDir("", "test-*")
+	require.Nil(t, err, "could not create temporary directory")
+	defer os.RemoveAll(tempDir)
+
+	allowedDir := filepath.Join(tempDir, "allowed")
+	otherDir := filepath.Join(tempDir, "other")
+	require.Nil(t, os.Mkdir(allowedDir, 0755), "could not create allowed directory")
+	require.Nil(t, os.Mkdir(otherDir, 0755), "could not create other directory")
+	require.Nil(t, ioutil.WriteFile(filepath.Join(allowedDir, "config.yaml"), []byte("TEST"), 0644), "could not write allowed file")
+	require.Nil(t, ioutil.WriteFile(filepath.Join(otherDir, "secret.yaml"), []byte("TEST"), 0644), "could not write other file")
+	require.Nil(t, os.Symlink(filepath.Join(otherDir, "secret.yaml"), filepath.Join(allowedDir, "link.yaml")), "could not create symlink")
+
+	sandbox, err := NewSandbox([]string{allowedDir})
+	require.Nil(t, err, "could not create sandbox")
+	require.True(t, sandbox.Allowed(filepath.Join(allowedDir, "config.yaml"), tempDir), "could not read allowed file")
+	require.False(t, sandbox.Allowed(filepath.Join(otherDir, "secret.yaml"), tempDir), "could read file outside of allowed directory")
+	require.False(t, sandbox.Allowed(filepath.Join(allowedDir, "link.yaml"), tempDir), "could escape allowed directory with symlink")
+	require.False(t, sandbox.Allowed(allowedDir, tempDir), "could read directory")
+
+	file, err := sandbox.Open(filepath.Join(allowedDir, "config.yaml"), tempDir)
+	require.Nil(t, err, "could not open allowed file")
+	file.Close()
+	_, err = sandbox.Open(filepath.Join(allowedDir, "link.yaml"), tempDir)
+	require.NotNil(t, err, "could open file escaping allowed directory with symlink")
+
+	// Only the input paths are allowed by default
+	sandbox, err = NewSandbox(nil)
+	require.Nil(t, err, "could not create default sandbox")
+	require.True(t, sandbox.Allowed(filepath.Join(otherDir, "secret.yaml"), otherDir), "could not read file of input with default sandbox")
+	require.True(t, sandbox.Allowed(filepath.Join(otherDir, "secret.yaml"), filepath.Join(otherDir, "*.yaml")), "could not read file of glob input with default sandbox")
+	require.False(t, sandbox.Allowed(filepath.Join(allowedDir, "link.yaml"), allowedDir), "could escape input with symlink")
+	require.False(t, sandbox.Allowed(filepath.Join(otherDir, "secret.yaml"), allowedDir), "could read file outside of input with default sandbox")
+	require.False(t, sandbox.Allowed("/proc/self/environ", "/proc"), "could read denied system path")
+
+	_, err = NewSandbox([]string{filepath.Join(tempDir, "missing")})
+	require.NotNil(t, err, "could create sandbox with missing allowed directory")
+}
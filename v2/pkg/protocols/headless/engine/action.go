@@ -50,6 +50,8 @@ const (
 	ActionDebug
 	// ActionSleep executes a sleep for a specified duration
 	ActionSleep
+	// ActionBlock blocks the requests of the page matching a url pattern
+	ActionBlock
 )
 
 // ActionStringToAction converts an action from string to internal representation
@@ -75,6 +77,7 @@ var ActionStringToAction = map[string]ActionType{
 	"keyboard":     ActionKeyboard,
 	"debug":        ActionDebug,
 	"sleep":        ActionSleep,
+	"block":        ActionBlock,
 }
 
 // ActionToActionString converts an action from  internal representation to string
@@ -100,6 +103,7 @@ var ActionToActionString = map[ActionType]string{
 	ActionKeyboard:     "keyboard",
 	ActionDebug:        "debug",
 	ActionSleep:        "sleep",
+	ActionBlock:        "block",
 }
 
 // Action is an action taken by the browser to reach a navigation
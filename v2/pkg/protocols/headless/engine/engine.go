@@ -6,30 +6,88 @@ import (
 	"net/http"
 	"os"
 	"strings"
+	"sync"
 
-	"github.com/corpix/uarand"
 	"github.com/go-rod/rod"
 	"github.com/go-rod/rod/lib/launcher"
 	ps "github.com/mitchellh/go-ps"
 	"github.com/pkg/errors"
+	"github.com/projectdiscovery/gologger"
+	"github.com/projectdiscovery/nuclei/v2/pkg/protocols/http/httpclientpool"
 	"github.com/projectdiscovery/nuclei/v2/pkg/types"
 )
 
-// Browser is a browser structure for nuclei headless module
+// defaultMaxTabs is the default maximum number of concurrently open tabs
+const defaultMaxTabs = 10
+
+// Browser is a browser structure for nuclei headless module.
+//
+// The browser is a pool of tabs limited to a maximum number of concurrently
+// open tabs. The browser process is restarted if it crashes.
 type Browser struct {
 	customAgent  string
-	tempDir      string
 	previouspids map[int]struct{} // track already running pids
-	engine       *rod.Browser
 	httpclient   *http.Client
 	options      *types.Options
+	// session is applied to all the pages and sessions are the persisted sessions of the hosts
+	session  *Session
+	sessions *sessionStore
+	// tabs limits the number of concurrently open tabs
+	tabs chan struct{}
+
+	mutex   sync.RWMutex
+	engine  *rod.Browser
+	tempDir string
+	// screenshots are the screenshots being taken in the background
+	screenshots sync.WaitGroup
 }
 
 // New creates a new nuclei headless browser module
 func New(options *types.Options) (*Browser, error) {
+	customAgent := ""
+	for _, option := range options.CustomHeaders {
+		parts := strings.SplitN(option, ":", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		if strings.EqualFold(parts[0], "User-Agent") {
+			customAgent = parts[1]
+		}
+	}
+	maxTabs := options.HeadlessMaxTabs
+	if maxTabs <= 0 {
+		maxTabs = defaultMaxTabs
+	}
+	httpclient := newhttpClient(options)
+	engine := &Browser{
+		customAgent: customAgent,
+		httpclient:  httpclient,
+		options:     options,
+		tabs:        make(chan struct{}, maxTabs),
+	}
+	engine.previouspids = engine.findChromeProcesses()
+	if err := engine.launch(); err != nil {
+		return nil, err
+	}
+
+	if options.HeadlessSession != "" {
+		var err error
+		if engine.session, err = LoadSession(options.HeadlessSession); err != nil {
+			engine.Close()
+			return nil, err
+		}
+	}
+	if options.HeadlessPersistSession {
+		engine.sessions = &sessionStore{sessions: make(map[string]*Session)}
+	}
+	return engine, nil
+}
+
+// launch launches a new browser process connecting to it
+func (b *Browser) launch() error {
 	dataStore, err := ioutil.TempDir("", "nuclei-*")
 	if err != nil {
-		return nil, errors.Wrap(err, "could not create temporary directory")
+		return errors.Wrap(err, "could not create temporary directory")
 	}
 	chromeLauncher := launcher.New().
 		Leakless(false).
@@ -46,50 +104,60 @@ func New(options *types.Options) (*Browser, error) {
 		Delete("use-mock-keychain").
 		UserDataDir(dataStore)
 
-	if options.ShowBrowser {
+	if b.options.ShowBrowser {
 		chromeLauncher = chromeLauncher.Headless(false)
 	} else {
 		chromeLauncher = chromeLauncher.Headless(true)
 	}
-	if options.ProxyURL != "" {
-		chromeLauncher = chromeLauncher.Proxy(options.ProxyURL)
+	if b.options.ProxyURL != "" {
+		chromeLauncher = chromeLauncher.Proxy(b.options.ProxyURL)
 	}
 	launcherURL, err := chromeLauncher.Launch()
 	if err != nil {
-		return nil, err
+		os.RemoveAll(dataStore)
+		return err
 	}
 
 	browser := rod.New().ControlURL(launcherURL)
 	if browserErr := browser.Connect(); browserErr != nil {
-		return nil, browserErr
+		os.RemoveAll(dataStore)
+		return browserErr
 	}
-	customAgent := ""
-	for _, option := range options.CustomHeaders {
-		parts := strings.SplitN(option, ":", 2)
-		if len(parts) != 2 {
-			continue
-		}
-		if strings.EqualFold(parts[0], "User-Agent") {
-			customAgent = parts[1]
-		}
-	}
-	if customAgent == "" {
-		customAgent = uarand.GetRandom()
-	}
-	httpclient := newhttpClient(options)
-	engine := &Browser{
-		tempDir:     dataStore,
-		customAgent: customAgent,
-		engine:      browser,
-		httpclient:  httpclient,
-		options:     options,
+	b.engine = browser
+	b.tempDir = dataStore
+	return nil
+}
+
+// restart restarts the browser process if the crashed engine is still the
+// current one. Concurrent callers with the same crashed engine restart it once.
+func (b *Browser) restart(crashed *rod.Browser) error {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if b.engine != crashed {
+		return nil
 	}
-	engine.previouspids = engine.findChromeProcesses()
-	return engine, nil
+	gologger.Warning().Msgf("Headless browser crashed, restarting it\n")
+	_ = b.engine.Close()
+	os.RemoveAll(b.tempDir)
+	b.killChromeProcesses()
+	return b.launch()
 }
 
-// Close closes the browser engine
+// getEngine returns the current browser engine
+func (b *Browser) getEngine() *rod.Browser {
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+	return b.engine
+}
+
+// Close closes the browser engine once the screenshots being taken are done
 func (b *Browser) Close() {
+	b.screenshots.Wait()
+
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
 	b.engine.Close()
 	os.RemoveAll(b.tempDir)
 	b.killChromeProcesses()
@@ -120,3 +188,12 @@ func (b *Browser) findChromeProcesses() map[int]struct{} {
 	}
 	return list
 }
+
+// userAgent returns the user agent of a page, the agent of the custom
+// headers or the agent of the http requests otherwise.
+func (b *Browser) userAgent() string {
+	if b.customAgent != "" {
+		return b.customAgent
+	}
+	return httpclientpool.UserAgent()
+}
@@ -11,9 +11,12 @@ import (
 
 // newhttpClient creates a new http client for headless communication with a timeout
 func newhttpClient(options *types.Options) *http.Client {
-	dialer := protocolstate.Dialer
+	dialContext := protocolstate.Dialer.Dial
+	if protocolstate.Resolver != nil {
+		dialContext = protocolstate.Resolver.Dial
+	}
 	transport := &http.Transport{
-		DialContext:         dialer.Dial,
+		DialContext:         dialContext,
 		MaxIdleConns:        500,
 		MaxIdleConnsPerHost: 500,
 		MaxConnsPerHost:     500,
@@ -2,17 +2,30 @@ package engine
 
 import (
 	"context"
-	"errors"
+	"sync"
 	"time"
 
 	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
 	"github.com/go-rod/rod/lib/utils"
+	"github.com/pkg/errors"
+	"github.com/projectdiscovery/nuclei/v2/pkg/protocols/common/auditlog"
 )
 
+// crashCheckTimeout is the time the browser has to respond to be alive
+const crashCheckTimeout = 5 * time.Second
+
 // Instance is an isolated browser instance opened for doing operations with it.
 type Instance struct {
 	browser *Browser
+	// root is the browser engine the incognito engine was created from
+	root    *rod.Browser
 	engine  *rod.Browser
+	session *Session
+	release sync.Once
+
+	auditLog   *auditlog.Logger
+	templateID string
 }
 
 // NewInstance creates a new instance for the current browser.
@@ -22,21 +35,80 @@ type Instance struct {
 //
 // Users can also choose to run the login->actions process again
 // which uses a new incognito browser instance to run actions.
+//
+// Creating an instance waits for a free tab of the browser pool, which
+// is released by closing the instance.
 func (b *Browser) NewInstance() (*Instance, error) {
-	browser, err := b.engine.Incognito()
+	b.tabs <- struct{}{}
+
+	root, browser, err := b.incognito()
 	if err != nil {
+		<-b.tabs
 		return nil, err
 	}
+	return &Instance{browser: b, root: root, engine: browser}, nil
+}
+
+// incognito creates an incognito engine returning it with the engine it was
+// created from. The browser process is restarted once if it crashed.
+func (b *Browser) incognito() (*rod.Browser, *rod.Browser, error) {
+	current := b.getEngine()
+	browser, err := current.Incognito()
+	if err != nil {
+		if restartErr := b.restart(current); restartErr != nil {
+			return nil, nil, errors.Wrap(restartErr, "could not restart browser")
+		}
+		current = b.getEngine()
+		if browser, err = current.Incognito(); err != nil {
+			return nil, nil, err
+		}
+	}
 
 	// We use a custom sleeper that sleeps from 100ms to 500 ms waiting
 	// for an interaction. Used throughout rod for clicking, etc.
 	browser = browser.Sleeper(func() utils.Sleeper { return maxBackoffSleeper(10) })
-	return &Instance{browser: b, engine: browser}, nil
+	return current, browser, nil
+}
+
+// recover restarts the browser if the engine of the instance crashed,
+// replacing the engine of the instance. It returns false if the browser
+// did not crash.
+func (i *Instance) recover() (bool, error) {
+	if !unresponsive(i.root) {
+		return false, nil
+	}
+	if err := i.browser.restart(i.root); err != nil {
+		return true, errors.Wrap(err, "could not restart browser")
+	}
+	root, browser, err := i.browser.incognito()
+	if err != nil {
+		return true, err
+	}
+	i.root, i.engine = root, browser
+	return true, nil
+}
+
+// unresponsive returns true if the browser engine does not respond anymore
+func unresponsive(engine *rod.Browser) bool {
+	ctx, cancel := context.WithTimeout(context.Background(), crashCheckTimeout)
+	defer cancel()
+
+	_, err := proto.BrowserGetVersion{}.Call(engine.Context(ctx))
+	return err != nil
+}
+
+// SetAuditLog records the requests made by the pages of the instance
+// in the audit log as made by the template ID.
+func (i *Instance) SetAuditLog(auditLog *auditlog.Logger, templateID string) {
+	i.auditLog = auditLog
+	i.templateID = templateID
 }
 
 // Close closes all the tabs and pages for a browser instance
 func (i *Instance) Close() error {
-	return i.engine.Close()
+	err := i.engine.Close()
+	i.release.Do(func() { <-i.browser.tabs })
+	return err
 }
 
 // maxBackoffSleeper is a backoff sleeper respecting max backoff values
@@ -0,0 +1,70 @@
+package engine
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/projectdiscovery/nuclei/v2/pkg/protocols/common/protocolstate"
+	"github.com/projectdiscovery/nuclei/v2/pkg/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInstanceCrashRecovery(t *testing.T) {
+	_ = protocolstate.Init(&types.Options{})
+
+	browser, err := New(&types.Options{ShowBrowser: false})
+	require.Nil(t, err, "could not create browser")
+	defer browser.Close()
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintln(w, `<html><head><title>Nuclei Test Page</title></head><body>Nuclei Test</body></html>`)
+	}))
+	defer ts.Close()
+	parsed, err := url.Parse(ts.URL)
+	require.Nil(t, err, "could not parse URL")
+	actions := []*Action{{ActionType: "navigate", Data: map[string]string{"url": "{{BaseURL}}"}}, {ActionType: "waitload"}}
+
+	t.Run("navigation", func(t *testing.T) {
+		instance, err := browser.NewInstance()
+		require.Nil(t, err, "could not create browser instance")
+		defer instance.Close()
+		require.False(t, unresponsive(instance.root), "could not detect running browser")
+
+		crashed := browser.getEngine()
+		_ = crashed.Close()
+		require.True(t, unresponsive(crashed), "could not detect crashed browser")
+
+		_, page, err := instance.Run(parsed, actions, 20*time.Second)
+		require.Nil(t, err, "could not run page actions after crash")
+		defer page.Close()
+		require.Equal(t, "Nuclei Test Page", page.Page().MustInfo().Title, "could not navigate after crash")
+		require.True(t, browser.getEngine() != crashed, "could not restart browser")
+	})
+
+	t.Run("new-instance", func(t *testing.T) {
+		_ = browser.getEngine().Close()
+
+		instance, err := browser.NewInstance()
+		require.Nil(t, err, "could not create browser instance after crash")
+		defer instance.Close()
+
+		_, page, err := instance.Run(parsed, actions, 20*time.Second)
+		require.Nil(t, err, "could not run page actions")
+		defer page.Close()
+	})
+
+	t.Run("action-error", func(t *testing.T) {
+		instance, err := browser.NewInstance()
+		require.Nil(t, err, "could not create browser instance")
+		defer instance.Close()
+
+		running := browser.getEngine()
+		_, _, err = instance.Run(parsed, append(actions, &Action{ActionType: "script", Data: map[string]string{}}), 20*time.Second)
+		require.NotNil(t, err, "could run script without code")
+		require.True(t, browser.getEngine() == running, "could restart running browser on action error")
+	})
+}
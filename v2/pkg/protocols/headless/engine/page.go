@@ -2,6 +2,7 @@ package engine
 
 import (
 	"net/url"
+	"sync"
 	"time"
 
 	"github.com/go-rod/rod"
@@ -10,24 +11,45 @@ import (
 
 // Page is a single page in an isolated browser instanace
 type Page struct {
-	page     *rod.Page
-	rules    []requestRule
-	instance *Instance
-	router   *rod.HijackRouter
+	page         *rod.Page
+	rules        []requestRule
+	instance     *Instance
+	router       *rod.HijackRouter
+	history      []HistoryData
+	historyMutex sync.Mutex
+	// screenshot is the last screenshot taken by a screenshot action with store
+	screenshot []byte
 }
 
 // Run runs a list of actions by creating a new page in the browser.
+//
+// If the navigation or the actions fail because the browser crashed, the
+// browser is restarted and the actions are run again once.
 func (i *Instance) Run(baseURL *url.URL, actions []*Action, timeout time.Duration) (map[string]string, *Page, error) {
+	data, page, err := i.run(baseURL, actions, timeout)
+	if err == nil {
+		return data, page, nil
+	}
+	restarted, recoverErr := i.recover()
+	if recoverErr != nil {
+		return nil, nil, recoverErr
+	}
+	if !restarted {
+		return nil, nil, err
+	}
+	return i.run(baseURL, actions, timeout)
+}
+
+// run runs a list of actions in a new page of the instance
+func (i *Instance) run(baseURL *url.URL, actions []*Action, timeout time.Duration) (map[string]string, *Page, error) {
 	page, err := i.engine.Page(proto.TargetCreateTarget{})
 	if err != nil {
 		return nil, nil, err
 	}
 	page = page.Timeout(timeout)
 
-	if i.browser.customAgent != "" {
-		if userAgentErr := page.SetUserAgent(&proto.NetworkSetUserAgentOverride{UserAgent: i.browser.customAgent}); userAgentErr != nil {
-			return nil, nil, userAgentErr
-		}
+	if userAgentErr := page.SetUserAgent(&proto.NetworkSetUserAgentOverride{UserAgent: i.browser.userAgent()}); userAgentErr != nil {
+		return nil, nil, userAgentErr
 	}
 
 	createdPage := &Page{page: page, instance: i}
@@ -49,11 +71,15 @@ func (i *Instance) Run(baseURL *url.URL, actions []*Action, timeout time.Duratio
 	if err != nil {
 		return nil, nil, err
 	}
+	if err := createdPage.applySession(baseURL); err != nil {
+		return nil, nil, err
+	}
 	go router.Run()
 	data, err := createdPage.ExecuteActions(baseURL, actions)
 	if err != nil {
 		return nil, nil, err
 	}
+	createdPage.persistSession(baseURL)
 	return data, createdPage, nil
 }
 
@@ -63,6 +89,11 @@ func (p *Page) Close() {
 	p.page.Close()
 }
 
+// StoredScreenshot returns the screenshot stored by the screenshot actions if any
+func (p *Page) StoredScreenshot() []byte {
+	return p.screenshot
+}
+
 // Page returns the current page for the actions
 func (p *Page) Page() *rod.Page {
 	return p.page
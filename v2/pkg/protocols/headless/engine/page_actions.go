@@ -65,6 +65,8 @@ func (p *Page) ExecuteActions(baseURL *url.URL, actions []*Action) (map[string]s
 			err = p.KeyboardAction(act, outData)
 		case ActionDebug:
 			err = p.DebugAction(act, outData)
+		case ActionBlock:
+			err = p.ActionBlock(act, outData)
 		case ActionSleep:
 			err = p.SleepAction(act, outData)
 		default:
@@ -81,6 +83,21 @@ type requestRule struct {
 	Action ActionType
 	Part   string
 	Args   map[string]string
+	// URL limits the rule to the requests with a matching url if set
+	URL *regexp.Regexp
+}
+
+// ruleURL compiles the optional url pattern argument of a rule action
+func ruleURL(act *Action) (*regexp.Regexp, error) {
+	pattern := act.GetArg("url")
+	if pattern == "" {
+		return nil, nil
+	}
+	compiled, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not compile url pattern")
+	}
+	return compiled, nil
 }
 
 // ActionAddHeader executes a AddHeader action.
@@ -90,10 +107,15 @@ func (p *Page) ActionAddHeader(act *Action, out map[string]string) error {
 	args := make(map[string]string)
 	args["key"] = act.GetArg("key")
 	args["value"] = act.GetArg("value")
+	urlPattern, err := ruleURL(act)
+	if err != nil {
+		return err
+	}
 	rule := requestRule{
 		Action: ActionAddHeader,
 		Part:   in,
 		Args:   args,
+		URL:    urlPattern,
 	}
 	p.rules = append(p.rules, rule)
 	return nil
@@ -106,10 +128,15 @@ func (p *Page) ActionSetHeader(act *Action, out map[string]string) error {
 	args := make(map[string]string)
 	args["key"] = act.GetArg("key")
 	args["value"] = act.GetArg("value")
+	urlPattern, err := ruleURL(act)
+	if err != nil {
+		return err
+	}
 	rule := requestRule{
 		Action: ActionSetHeader,
 		Part:   in,
 		Args:   args,
+		URL:    urlPattern,
 	}
 	p.rules = append(p.rules, rule)
 	return nil
@@ -121,10 +148,15 @@ func (p *Page) ActionDeleteHeader(act *Action, out map[string]string) error {
 
 	args := make(map[string]string)
 	args["key"] = act.GetArg("key")
+	urlPattern, err := ruleURL(act)
+	if err != nil {
+		return err
+	}
 	rule := requestRule{
 		Action: ActionDeleteHeader,
 		Part:   in,
 		Args:   args,
+		URL:    urlPattern,
 	}
 	p.rules = append(p.rules, rule)
 	return nil
@@ -136,10 +168,15 @@ func (p *Page) ActionSetBody(act *Action, out map[string]string) error {
 
 	args := make(map[string]string)
 	args["body"] = act.GetArg("body")
+	urlPattern, err := ruleURL(act)
+	if err != nil {
+		return err
+	}
 	rule := requestRule{
 		Action: ActionSetBody,
 		Part:   in,
 		Args:   args,
+		URL:    urlPattern,
 	}
 	p.rules = append(p.rules, rule)
 	return nil
@@ -151,15 +188,33 @@ func (p *Page) ActionSetMethod(act *Action, out map[string]string) error {
 
 	args := make(map[string]string)
 	args["method"] = act.GetArg("method")
+	urlPattern, err := ruleURL(act)
+	if err != nil {
+		return err
+	}
 	rule := requestRule{
 		Action: ActionSetMethod,
 		Part:   in,
 		Args:   args,
+		URL:    urlPattern,
 	}
 	p.rules = append(p.rules, rule)
 	return nil
 }
 
+// ActionBlock executes a Block action failing the requests matching the url pattern.
+func (p *Page) ActionBlock(act *Action, out map[string]string) error {
+	urlPattern, err := ruleURL(act)
+	if err != nil {
+		return err
+	}
+	if urlPattern == nil {
+		return errors.New("no url pattern provided")
+	}
+	p.rules = append(p.rules, requestRule{Action: ActionBlock, Part: "request", URL: urlPattern})
+	return nil
+}
+
 // NavigateURL executes an ActionLoadURL actions loading a URL for the page.
 func (p *Page) NavigateURL(action *Action, out map[string]string, parsed *url.URL) error {
 	URL := action.GetArg("url")
@@ -239,10 +294,12 @@ func (p *Page) RightClickElement(act *Action, out map[string]string) error {
 	return nil
 }
 
-// Screenshot executes screenshot action on a page
+// Screenshot executes screenshot action on a page. With the store argument
+// the screenshot is kept to be stored with the results if the request matches.
 func (p *Page) Screenshot(act *Action, out map[string]string) error {
+	store := act.GetArg("store") == "true"
 	to := act.GetArg("to")
-	if to == "" {
+	if to == "" && !store {
 		to = ksuid.New().String()
 		if act.Name != "" {
 			out[act.Name] = to
@@ -258,6 +315,10 @@ func (p *Page) Screenshot(act *Action, out map[string]string) error {
 	if err != nil {
 		return errors.Wrap(err, "could not take screenshot")
 	}
+	if store {
+		p.screenshot = data
+		return nil
+	}
 	err = ioutil.WriteFile(to+".png", data, 0540)
 	if err != nil {
 		return errors.Wrap(err, "could not write screenshot")
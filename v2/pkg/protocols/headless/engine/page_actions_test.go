@@ -49,6 +49,33 @@ func TestActionNavigate(t *testing.T) {
 	require.Equal(t, "Nuclei Test Page", page.Page().MustInfo().Title, "could not navigate correctly")
 }
 
+func TestActionScreenshotStore(t *testing.T) {
+	_ = protocolstate.Init(&types.Options{})
+
+	browser, err := New(&types.Options{ShowBrowser: false})
+	require.Nil(t, err, "could not create browser")
+	defer browser.Close()
+
+	instance, err := browser.NewInstance()
+	require.Nil(t, err, "could not create browser instance")
+	defer instance.Close()
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintln(w, `<html><body><h1>Nuclei Test</h1></body></html>`)
+	}))
+	defer ts.Close()
+
+	parsed, err := url.Parse(ts.URL)
+	require.Nil(t, err, "could not parse URL")
+
+	actions := []*Action{{ActionType: "navigate", Data: map[string]string{"url": "{{BaseURL}}"}}, {ActionType: "waitload"}, {ActionType: "screenshot", Data: map[string]string{"store": "true"}}}
+	_, page, err := instance.Run(parsed, actions, 20*time.Second)
+	require.Nil(t, err, "could not run page actions")
+	defer page.Close()
+
+	require.True(t, strings.HasPrefix(string(page.StoredScreenshot()), "\x89PNG"), "could not store png screenshot")
+}
+
 func TestActionScript(t *testing.T) {
 	_ = protocolstate.Init(&types.Options{})
 
@@ -278,3 +305,51 @@ func TestActionHeadersChange(t *testing.T) {
 
 	require.Equal(t, "found", strings.ToLower(strings.TrimSpace(page.Page().MustElement("html").MustText())), "could not set header correctly")
 }
+
+func TestActionBlockAndHistory(t *testing.T) {
+	_ = protocolstate.Init(&types.Options{})
+
+	browser, err := New(&types.Options{ShowBrowser: false})
+	require.Nil(t, err, "could not create browser")
+	defer browser.Close()
+
+	instance, err := browser.NewInstance()
+	require.Nil(t, err, "could not create browser instance")
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api":
+			fmt.Fprint(w, `{"token":"secret-token"}`)
+		case "/blocked":
+			fmt.Fprint(w, `{"blocked":false}`)
+		default:
+			fmt.Fprintln(w, `
+		<html>
+		<head>
+			<title>Nuclei Test Page</title>
+		</head>
+		<body>Nuclei Test Page</body>
+		<script>fetch("/api"); fetch("/blocked");</script>
+	</html>`)
+		}
+	}))
+	defer ts.Close()
+
+	parsed, err := url.Parse(ts.URL)
+	require.Nil(t, err, "could not parse URL")
+
+	actions := []*Action{
+		{ActionType: "block", Data: map[string]string{"url": "/blocked$"}},
+		{ActionType: "navigate", Data: map[string]string{"url": "{{BaseURL}}"}},
+		{ActionType: "waitload"},
+		{ActionType: "sleep", Data: map[string]string{"duration": "1"}},
+	}
+	_, page, err := instance.Run(parsed, actions, 20*time.Second)
+	require.Nil(t, err, "could not run page actions")
+	defer page.Close()
+
+	history := page.History()
+	require.Len(t, history, 1, "could not get xhr history")
+	require.Contains(t, history[0].RawRequest, "/api", "could not get xhr request")
+	require.Contains(t, history[0].RawResponse, "secret-token", "could not get xhr response")
+}
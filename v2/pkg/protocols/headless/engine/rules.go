@@ -2,14 +2,34 @@ package engine
 
 import (
 	"fmt"
+	"net/http"
+	"strings"
 
 	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
 )
 
+// maxHistorySize is the maximum number of xhr and fetch calls recorded for a page
+const maxHistorySize = 100
+
+// HistoryData contains the request and response of a xhr or fetch call made by a page
+type HistoryData struct {
+	RawRequest  string
+	RawResponse string
+}
+
 // routingRuleHandler handles proxy rule for actions related to request/response modification
 func (p *Page) routingRuleHandler(ctx *rod.Hijack) {
+	requestURL := ctx.Request.URL().String()
+	for _, rule := range p.rules {
+		if rule.Action == ActionBlock && rule.URL.MatchString(requestURL) {
+			ctx.Response.Fail(proto.NetworkErrorReasonBlockedByClient)
+			return
+		}
+	}
+
 	for _, rule := range p.rules {
-		if rule.Part != "request" {
+		if rule.Part != "request" || (rule.URL != nil && !rule.URL.MatchString(requestURL)) {
 			continue
 		}
 
@@ -27,10 +47,10 @@ func (p *Page) routingRuleHandler(ctx *rod.Hijack) {
 			ctx.Request.SetBody(body)
 		}
 	}
-	_ = ctx.LoadResponse(p.instance.browser.httpclient, true)
+	loadErr := ctx.LoadResponse(p.instance.browser.httpclient, true)
 
 	for _, rule := range p.rules {
-		if rule.Part != "response" {
+		if rule.Part != "response" || (rule.URL != nil && !rule.URL.MatchString(requestURL)) {
 			continue
 		}
 		if rule.Action == ActionAddHeader {
@@ -45,4 +65,52 @@ func (p *Page) routingRuleHandler(ctx *rod.Hijack) {
 			ctx.Response.SetBody(rule.Args["body"])
 		}
 	}
+
+	isHistory := ctx.Request.Type() == proto.NetworkResourceTypeXHR || ctx.Request.Type() == proto.NetworkResourceTypeFetch
+	if !isHistory && p.instance.auditLog == nil {
+		return
+	}
+	rawRequest, rawResponse := dumpHijack(ctx)
+	received := len(rawResponse)
+	if loadErr != nil {
+		received = 0
+	}
+	p.instance.auditLog.Log(p.instance.templateID, "headless", requestURL, len(rawRequest), received, loadErr)
+	if isHistory {
+		p.addHistory(rawRequest, rawResponse)
+	}
+}
+
+// dumpHijack returns the raw request and response of a hijacked call
+func dumpHijack(ctx *rod.Hijack) (string, string) {
+	request := ctx.Request.Req()
+	requestBuilder := &strings.Builder{}
+	requestBuilder.WriteString(request.Method + " " + request.URL.String() + " HTTP/1.1\r\n")
+	_ = request.Header.Write(requestBuilder)
+	requestBuilder.WriteString("\r\n")
+	requestBuilder.WriteString(ctx.Request.Body())
+
+	code := ctx.Response.Payload().ResponseCode
+	responseBuilder := &strings.Builder{}
+	responseBuilder.WriteString(fmt.Sprintf("HTTP/1.1 %d %s\r\n", code, http.StatusText(code)))
+	_ = ctx.Response.Headers().Write(responseBuilder)
+	responseBuilder.WriteString("\r\n")
+	responseBuilder.WriteString(ctx.Response.Body())
+	return requestBuilder.String(), responseBuilder.String()
+}
+
+// addHistory records the request and response of a hijacked call
+func (p *Page) addHistory(rawRequest, rawResponse string) {
+	p.historyMutex.Lock()
+	defer p.historyMutex.Unlock()
+	if len(p.history) < maxHistorySize {
+		p.history = append(p.history, HistoryData{RawRequest: rawRequest, RawResponse: rawResponse})
+	}
+}
+
+// History returns the xhr and fetch calls made by the page
+func (p *Page) History() []HistoryData {
+	p.historyMutex.Lock()
+	defer p.historyMutex.Unlock()
+	return append([]HistoryData{}, p.history...)
 }
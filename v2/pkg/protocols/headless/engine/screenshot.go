@@ -0,0 +1,67 @@
+package engine
+
+import (
+	"time"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+)
+
+// ScreenshotAsync takes a screenshot of the URL in the background calling
+// callback with the result. The browser waits for the screenshots on Close.
+func (b *Browser) ScreenshotAsync(URL string, timeout time.Duration, callback func(data []byte, err error)) {
+	b.screenshots.Add(1)
+	go func() {
+		defer b.screenshots.Done()
+		callback(b.Screenshot(URL, timeout))
+	}()
+}
+
+// Screenshot navigates to the URL in a new isolated instance and returns
+// a png screenshot of the loaded page.
+func (b *Browser) Screenshot(URL string, timeout time.Duration) ([]byte, error) {
+	instance, err := b.NewInstance()
+	if err != nil {
+		return nil, err
+	}
+	defer instance.Close()
+
+	page, err := instance.engine.Page(proto.TargetCreateTarget{})
+	if err != nil {
+		return nil, err
+	}
+	defer page.Close()
+	page = page.Timeout(timeout)
+
+	// The requests of the page are sent with the client of the browser to
+	// honor the proxy and source address of the scan.
+	router := page.HijackRequests()
+	if err := router.Add("*", "", func(ctx *rod.Hijack) {
+		if err := ctx.LoadResponse(b.httpclient, true); err != nil {
+			ctx.Response.Fail(proto.NetworkErrorReasonFailed)
+		}
+	}); err != nil {
+		return nil, err
+	}
+	go router.Run()
+	defer func() { _ = router.Stop() }()
+
+	if err := page.SetUserAgent(&proto.NetworkSetUserAgentOverride{UserAgent: b.userAgent()}); err != nil {
+		return nil, err
+	}
+	err = page.SetViewport(&proto.EmulationSetDeviceMetricsOverride{Viewport: &proto.PageViewport{
+		Scale:  1,
+		Width:  float64(1920),
+		Height: float64(1080),
+	}})
+	if err != nil {
+		return nil, err
+	}
+	if err := page.Navigate(URL); err != nil {
+		return nil, err
+	}
+	if err := page.WaitLoad(); err != nil {
+		return nil, err
+	}
+	return page.Screenshot(false, &proto.PageCaptureScreenshot{})
+}
@@ -0,0 +1,215 @@
+package engine
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/go-rod/rod/lib/proto"
+	"github.com/pkg/errors"
+)
+
+// Session contains the cookies and local storage items of a browser session
+type Session struct {
+	// Origin is the origin of the session (eg. https://example.com). The cookies
+	// without domain and the local storage items are only applied to its pages.
+	// Sessions without origin are applied to the target of the page.
+	Origin  string    `json:"origin,omitempty"`
+	Cookies []*Cookie `json:"cookies"`
+	// LocalStorage are the local storage items of the origin
+	LocalStorage map[string]string `json:"local_storage"`
+}
+
+// Cookie is a browser cookie. The target url is used if no domain is provided.
+type Cookie struct {
+	Name     string `json:"name"`
+	Value    string `json:"value"`
+	Domain   string `json:"domain,omitempty"`
+	Path     string `json:"path,omitempty"`
+	Secure   bool   `json:"secure,omitempty"`
+	HTTPOnly bool   `json:"httpOnly,omitempty"`
+}
+
+// LoadSession loads a session from a json file
+func LoadSession(file string) (*Session, error) {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not read session file")
+	}
+	session := &Session{}
+	if err := json.Unmarshal(data, session); err != nil {
+		return nil, errors.Wrap(err, "could not parse session file")
+	}
+	// The session of the file is applied to the pages of all the targets
+	if session.Origin == "" {
+		return nil, errors.New("session file has no origin")
+	}
+	parsed, err := url.Parse(session.Origin)
+	if err != nil || parsed.Scheme == "" || parsed.Host == "" {
+		return nil, errors.Errorf("invalid session origin %s", session.Origin)
+	}
+	session.Origin = originOf(parsed)
+	return session, nil
+}
+
+// originOf returns the origin of an url as reported by the browser,
+// without the default port of the scheme.
+func originOf(u *url.URL) string {
+	host := u.Host
+	if port := u.Port(); (u.Scheme == "http" && port == "80") || (u.Scheme == "https" && port == "443") {
+		host = strings.TrimSuffix(host, ":"+port)
+	}
+	return strings.ToLower(u.Scheme + "://" + host)
+}
+
+// forOrigin returns the items of the session applying to the pages of an
+// origin. The cookies with a domain are scoped by the browser and always kept.
+func (s *Session) forOrigin(origin string) *Session {
+	if s == nil || s.Origin == "" || s.Origin == origin {
+		return s
+	}
+	scoped := &Session{Origin: s.Origin}
+	for _, cookie := range s.Cookies {
+		if cookie.Domain != "" {
+			scoped.Cookies = append(scoped.Cookies, cookie)
+		}
+	}
+	if len(scoped.Cookies) == 0 {
+		return nil
+	}
+	return scoped
+}
+
+// merge returns the session with the items of the other session added,
+// replacing the cookies with the same name and items with the same key.
+func (s *Session) merge(other *Session) *Session {
+	if other == nil {
+		return s
+	}
+	merged := &Session{Origin: other.Origin, LocalStorage: make(map[string]string)}
+	if s != nil {
+		merged.Origin = s.Origin
+		merged.Cookies = append(merged.Cookies, s.Cookies...)
+		for k, v := range s.LocalStorage {
+			merged.LocalStorage[k] = v
+		}
+	}
+	for _, cookie := range other.Cookies {
+		replaced := false
+		for i, existing := range merged.Cookies {
+			if existing.Name == cookie.Name && existing.Domain == cookie.Domain {
+				merged.Cookies[i] = cookie
+				replaced = true
+			}
+		}
+		if !replaced {
+			merged.Cookies = append(merged.Cookies, cookie)
+		}
+	}
+	for k, v := range other.LocalStorage {
+		merged.LocalStorage[k] = v
+	}
+	return merged
+}
+
+// sessionStore keeps the sessions of the origins persisted between pages
+type sessionStore struct {
+	mutex    sync.RWMutex
+	sessions map[string]*Session
+}
+
+func (s *sessionStore) get(origin string) *Session {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return s.sessions[origin]
+}
+
+func (s *sessionStore) set(origin string, session *Session) {
+	s.mutex.Lock()
+	s.sessions[origin] = session
+	s.mutex.Unlock()
+}
+
+// SetSession sets the session applied to the pages of the instance
+func (i *Instance) SetSession(session *Session) {
+	i.session = session
+}
+
+// applySession sets the cookies and local storage of the session of the
+// browser, the persisted session of the origin and the instance session
+// applying to the origin of the page.
+func (p *Page) applySession(baseURL *url.URL) error {
+	origin := originOf(baseURL)
+	session := p.instance.browser.session.forOrigin(origin)
+	if store := p.instance.browser.sessions; store != nil {
+		session = session.merge(store.get(origin))
+	}
+	session = session.merge(p.instance.session.forOrigin(origin))
+	if session == nil {
+		return nil
+	}
+
+	if len(session.Cookies) > 0 {
+		cookies := make([]*proto.NetworkCookieParam, 0, len(session.Cookies))
+		for _, cookie := range session.Cookies {
+			param := &proto.NetworkCookieParam{
+				Name:     cookie.Name,
+				Value:    cookie.Value,
+				Domain:   cookie.Domain,
+				Path:     cookie.Path,
+				Secure:   cookie.Secure,
+				HTTPOnly: cookie.HTTPOnly,
+			}
+			if param.Domain == "" {
+				param.URL = origin
+			}
+			cookies = append(cookies, param)
+		}
+		if err := p.page.SetCookies(cookies); err != nil {
+			return errors.Wrap(err, "could not set cookies")
+		}
+	}
+
+	if len(session.LocalStorage) > 0 {
+		items, err := json.Marshal(session.LocalStorage)
+		if err != nil {
+			return err
+		}
+		originValue, _ := json.Marshal(origin)
+		// The items are set before any script of the target origin runs
+		script := `(() => { if (window.location.origin !== ` + string(originValue) + `) return; const items = ` + string(items) + `; for (const key in items) { window.localStorage.setItem(key, items[key]); } })()`
+		if _, err := p.page.EvalOnNewDocument(script); err != nil {
+			return errors.Wrap(err, "could not set local storage")
+		}
+	}
+	return nil
+}
+
+// persistSession saves the cookies and local storage of the page for its
+// origin if sessions are persisted by the browser.
+func (p *Page) persistSession(baseURL *url.URL) {
+	store := p.instance.browser.sessions
+	if store == nil {
+		return
+	}
+	origin := originOf(baseURL)
+	session := &Session{Origin: origin, LocalStorage: make(map[string]string)}
+	if cookies, err := p.page.Cookies([]string{origin}); err == nil {
+		for _, cookie := range cookies {
+			session.Cookies = append(session.Cookies, &Cookie{
+				Name:     cookie.Name,
+				Value:    cookie.Value,
+				Domain:   cookie.Domain,
+				Path:     cookie.Path,
+				Secure:   cookie.Secure,
+				HTTPOnly: cookie.HTTPOnly,
+			})
+		}
+	}
+	if data, err := p.page.Eval(`() => JSON.stringify(Object.assign({}, window.localStorage))`); err == nil {
+		_ = json.Unmarshal([]byte(data.Value.String()), &session.LocalStorage)
+	}
+	store.set(origin, session)
+}
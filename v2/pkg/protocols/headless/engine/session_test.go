@@ -0,0 +1,72 @@
+package engine
+
+import (
+	"io/ioutil"
+	"net/url"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSessionLoadMerge(t *testing.T) {
+	file, err := ioutil.TempFile("", "session-*.json")
+	require.Nil(t, err, "could not create session file")
+	defer os.Remove(file.Name())
+	_, _ = file.WriteString(`{"origin":"https://Example.com:443/","cookies":[{"name":"sid","value":"old"},{"name":"lang","value":"en"}],"local_storage":{"token":"abc"}}`)
+	file.Close()
+
+	session, err := LoadSession(file.Name())
+	require.Nil(t, err, "could not load session")
+	require.Len(t, session.Cookies, 2, "could not get session cookies")
+	require.Equal(t, "https://example.com", session.Origin, "could not normalize session origin")
+
+	merged := session.merge(&Session{Cookies: []*Cookie{{Name: "sid", Value: "new"}}, LocalStorage: map[string]string{"theme": "dark"}})
+	require.Len(t, merged.Cookies, 2, "could not replace existing cookie")
+	require.Equal(t, "new", merged.Cookies[0].Value, "could not get replaced cookie value")
+	require.Equal(t, map[string]string{"token": "abc", "theme": "dark"}, merged.LocalStorage, "could not merge local storage")
+	require.Equal(t, "old", session.Cookies[0].Value, "could not keep original session unchanged")
+
+	var empty *Session
+	require.Equal(t, session, empty.merge(nil).merge(session), "could not merge nil sessions")
+}
+
+func TestSessionForOrigin(t *testing.T) {
+	session := &Session{
+		Origin:       "https://example.com",
+		Cookies:      []*Cookie{{Name: "sid", Value: "secret"}, {Name: "sso", Value: "shared", Domain: ".example.org"}},
+		LocalStorage: map[string]string{"token": "abc"},
+	}
+	require.Equal(t, session, session.forOrigin("https://example.com"), "could not apply session to its origin")
+
+	other := session.forOrigin("https://attacker.com")
+	require.Len(t, other.Cookies, 1, "could apply cookies without domain to another origin")
+	require.Equal(t, "sso", other.Cookies[0].Name, "could not keep cookies with domain")
+	require.Empty(t, other.LocalStorage, "could apply local storage to another origin")
+
+	require.Nil(t, (&Session{Origin: "https://example.com", LocalStorage: map[string]string{"token": "abc"}}).forOrigin("http://example.com"), "could apply session to another scheme")
+
+	target := &Session{Cookies: []*Cookie{{Name: "sid"}}}
+	require.Equal(t, target, target.forOrigin("https://example.com"), "could not apply session without origin to target")
+
+	for value, origin := range map[string]string{
+		"https://example.com:443/path": "https://example.com",
+		"http://Example.com:80":        "http://example.com",
+		"http://example.com:8080":      "http://example.com:8080",
+	} {
+		parsed, err := url.Parse(value)
+		require.Nil(t, err, "could not parse url")
+		require.Equal(t, origin, originOf(parsed), "could not get origin of %s", value)
+	}
+}
+
+func TestLoadSessionWithoutOrigin(t *testing.T) {
+	file, err := ioutil.TempFile("", "session-*.json")
+	require.Nil(t, err, "could not create session file")
+	defer os.Remove(file.Name())
+	_, _ = file.WriteString(`{"cookies":[{"name":"sid","value":"secret"}]}`)
+	file.Close()
+
+	_, err = LoadSession(file.Name())
+	require.NotNil(t, err, "could load session applying to all targets")
+}
@@ -13,6 +13,11 @@ type Request struct {
 
 	// Steps is the list of actions to run for headless request
 	Steps []*engine.Action `yaml:"steps"`
+	// Cookies are the cookies set for the target before running the steps.
+	// The values can use the values extracted by earlier requests.
+	Cookies map[string]string `yaml:"cookies"`
+	// LocalStorage are the local storage items set for the target origin before running the steps.
+	LocalStorage map[string]string `yaml:"local-storage"`
 
 	// Operators for the current request go here.
 	operators.Operators `yaml:",inline,omitempty"`
@@ -33,6 +38,11 @@ func (r *Request) GetID() string {
 	return r.ID
 }
 
+// GetCompiledOperators returns the compiled operators of the request
+func (r *Request) GetCompiledOperators() *operators.Operators {
+	return r.CompiledOperators
+}
+
 // Compile compiles the protocol request for further execution.
 func (r *Request) Compile(options *protocols.ExecuterOptions) error {
 	if len(r.Matchers) > 0 || len(r.Extractors) > 0 {
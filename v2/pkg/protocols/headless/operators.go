@@ -57,6 +57,8 @@ func (r *Request) Extract(data map[string]interface{}, extractor *extractors.Ext
 		return extractor.ExtractRegex(itemStr)
 	case extractors.KValExtractor:
 		return extractor.ExtractKval(data)
+	case extractors.DSLExtractor:
+		return extractor.ExtractDSL(data)
 	}
 	return nil
 }
@@ -85,7 +87,7 @@ func (r *Request) MakeResultEvent(wrapped *output.InternalWrappedEvent) []*outpu
 
 	// If we have multiple matchers with names, write each of them separately.
 	if len(wrapped.OperatorsResult.Matches) > 0 {
-		for k := range wrapped.OperatorsResult.Matches {
+		for _, k := range wrapped.OperatorsResult.MatcherNames() {
 			data := r.makeResultEventItem(wrapped)
 			data.MatcherName = k
 			results = append(results, data)
@@ -115,6 +117,7 @@ func (r *Request) makeResultEventItem(wrapped *output.InternalWrappedEvent) *out
 		ExtractedResults: wrapped.OperatorsResult.OutputExtracts,
 		Timestamp:        time.Now(),
 		IP:               types.ToString(wrapped.InternalEvent["ip"]),
+		ResponseHash:     output.ResponseHash(types.ToString(wrapped.InternalEvent["data"])),
 	}
 	if r.options.Options.JSONRequests {
 		data.Request = types.ToString(wrapped.InternalEvent["request"])
@@ -9,12 +9,16 @@ import (
 	"github.com/projectdiscovery/gologger"
 	"github.com/projectdiscovery/nuclei/v2/pkg/output"
 	"github.com/projectdiscovery/nuclei/v2/pkg/protocols"
+	"github.com/projectdiscovery/nuclei/v2/pkg/protocols/common/generators"
+	"github.com/projectdiscovery/nuclei/v2/pkg/protocols/common/replacer"
+	"github.com/projectdiscovery/nuclei/v2/pkg/protocols/headless/engine"
 )
 
 var _ protocols.Request = &Request{}
 
 // ExecuteWithResults executes the protocol requests and returns results instead of writing them.
 func (r *Request) ExecuteWithResults(input string, metadata, previous output.InternalEvent, callback protocols.OutputEventCallback) error {
+	started := time.Now()
 	instance, err := r.options.Browser.NewInstance()
 	if err != nil {
 		r.options.Output.Request(r.options.TemplateID, input, "headless", err)
@@ -22,6 +26,10 @@ func (r *Request) ExecuteWithResults(input string, metadata, previous output.Int
 		return errors.Wrap(err, "could get html element")
 	}
 	defer instance.Close()
+	instance.SetAuditLog(r.options.AuditLog, r.options.TemplateID)
+	if len(r.Cookies) > 0 || len(r.LocalStorage) > 0 {
+		instance.SetSession(r.session(generators.MergeMaps(metadata, previous)))
+	}
 
 	parsed, err := url.Parse(input)
 	if err != nil {
@@ -32,12 +40,14 @@ func (r *Request) ExecuteWithResults(input string, metadata, previous output.Int
 	out, page, err := instance.Run(parsed, r.Steps, time.Duration(r.options.Options.PageTimeout)*time.Second)
 	if err != nil {
 		r.options.Output.Request(r.options.TemplateID, input, "headless", err)
+		r.options.AuditLog.Log(r.options.TemplateID, "headless", input, 0, 0, err)
 		r.options.Progress.IncrementFailedRequestsBy(1)
 		return errors.Wrap(err, "could get html element")
 	}
 	defer page.Close()
 
 	r.options.Output.Request(r.options.TemplateID, input, "headless", nil)
+	r.options.AuditLog.Log(r.options.TemplateID, "headless", input, 0, 0, nil)
 	r.options.Progress.IncrementRequests()
 	gologger.Verbose().Msgf("Sent Headless request to %s", input)
 
@@ -61,13 +71,23 @@ func (r *Request) ExecuteWithResults(input string, metadata, previous output.Int
 	for k, v := range out {
 		outputEvent[k] = v
 	}
+	// The xhr and fetch calls of the page can be matched separately
+	xhr, xhrRequests, xhrResponses := &strings.Builder{}, &strings.Builder{}, &strings.Builder{}
+	for _, history := range page.History() {
+		xhrRequests.WriteString(history.RawRequest + "\n")
+		xhrResponses.WriteString(history.RawResponse + "\n")
+		xhr.WriteString(history.RawRequest + "\n" + history.RawResponse + "\n")
+	}
+	outputEvent["xhr"] = xhr.String()
+	outputEvent["xhr_request"] = xhrRequests.String()
+	outputEvent["xhr_response"] = xhrResponses.String()
 
 	if r.options.Options.Debug || r.options.Options.DebugResponse {
 		gologger.Debug().Msgf("[%s] Dumped Headless response for %s", r.options.TemplateID, input)
 		gologger.Print().Msgf("%s", respBody)
 	}
 
-	event := &output.InternalWrappedEvent{InternalEvent: outputEvent}
+	event := &output.InternalWrappedEvent{InternalEvent: outputEvent, Started: started}
 	if r.CompiledOperators != nil {
 		result, ok := r.CompiledOperators.Execute(outputEvent, r.Match, r.Extract)
 		if ok && result != nil {
@@ -75,6 +95,40 @@ func (r *Request) ExecuteWithResults(input string, metadata, previous output.Int
 			event.Results = r.MakeResultEvent(event)
 		}
 	}
+	// Screenshots are only stored with -screenshot or -store-resp
+	if event.OperatorsResult != nil && r.options.ResponseStore != nil {
+		r.saveScreenshot(input, page, event)
+	}
 	callback(event)
 	return nil
 }
+
+// saveScreenshot stores the screenshot of the screenshot actions with store
+// setting its path on the results.
+func (r *Request) saveScreenshot(input string, page *engine.Page, event *output.InternalWrappedEvent) {
+	data := page.StoredScreenshot()
+	if data == nil {
+		return
+	}
+	file := r.options.ResponseStore.ScreenshotFile(input, r.options.TemplateID, page.URL())
+	if err := r.options.ResponseStore.SaveScreenshot(file, data); err != nil {
+		gologger.Warning().Msgf("[%s] Could not store screenshot for %s: %s\n", r.options.TemplateID, input, err)
+		return
+	}
+	event.InternalEvent["screenshot"] = file
+	for _, result := range event.Results {
+		result.Screenshot = file
+	}
+}
+
+// session returns the cookies and local storage of the request with the values replaced
+func (r *Request) session(values map[string]interface{}) *engine.Session {
+	session := &engine.Session{LocalStorage: make(map[string]string, len(r.LocalStorage))}
+	for name, value := range r.Cookies {
+		session.Cookies = append(session.Cookies, &engine.Cookie{Name: name, Value: replacer.Replace(value, values)})
+	}
+	for key, value := range r.LocalStorage {
+		session.LocalStorage[key] = replacer.Replace(value, values)
+	}
+	return session
+}
@@ -0,0 +1,110 @@
+package http
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/projectdiscovery/gologger"
+	"github.com/projectdiscovery/nuclei/v2/pkg/protocols/common/authprovider"
+)
+
+// authState is the state of a request before the credentials of the
+// secrets were injected, restored when the request is retried.
+type authState struct {
+	secrets []*authprovider.Secret
+	retried bool
+
+	header     http.Header
+	rawQuery   string
+	rawHeaders map[string]string
+	path       string
+	fullURL    string
+}
+
+// setAuthHeaders injects the credentials of the secrets matching the host of the request
+func (r *Request) setAuthHeaders(req *generatedRequest) {
+	if r.options.AuthProvider == nil {
+		return
+	}
+	if req.auth == nil {
+		req.auth = &authState{}
+		req.auth.save(req)
+	} else {
+		req.auth.restore(req)
+	}
+
+	if req.rawRequest != nil {
+		req.auth.secrets = r.lookupSecrets(req.rawRequest.FullURL)
+		for _, secret := range req.auth.secrets {
+			path := secret.ApplyToRaw(req.rawRequest.Headers, req.rawRequest.Path)
+			req.rawRequest.FullURL = strings.TrimSuffix(req.rawRequest.FullURL, req.rawRequest.Path) + path
+			req.rawRequest.Path = path
+		}
+		return
+	}
+	req.auth.secrets = r.lookupSecrets(req.request.URL.String())
+	for _, secret := range req.auth.secrets {
+		secret.ApplyToRequest(req.request.Request)
+	}
+}
+
+// lookupSecrets returns the secrets for the target url. The failed logins
+// are reported by the provider, the request is sent without their secrets.
+func (r *Request) lookupSecrets(target string) []*authprovider.Secret {
+	secrets, err := r.options.AuthProvider.Lookup(target)
+	if err != nil {
+		gologger.Verbose().Msgf("[%s] Could not get all secrets for %s: %s\n", r.options.TemplateID, target, err)
+	}
+	return secrets
+}
+
+// retryAuth returns true if the response signals an expired session of the
+// request and new credentials were obtained by logging in again. Requests
+// are only retried once.
+func (r *Request) retryAuth(req *generatedRequest, formedURL string, statusCode int, body []byte) bool {
+	if req.auth == nil || req.auth.retried || req.original.Race {
+		return false
+	}
+	if !r.options.AuthProvider.Expired(req.auth.secrets, statusCode, body) {
+		return false
+	}
+	req.auth.retried = true
+	refreshed, err := r.options.AuthProvider.Refresh(formedURL, req.auth.secrets)
+	if err != nil {
+		gologger.Warning().Msgf("[%s] Could not login again for %s: %s\n", r.options.TemplateID, formedURL, err)
+	}
+	if !refreshed {
+		return false
+	}
+	// The retried request counts towards the rate limit
+	r.options.RateLimiter.Take()
+	return true
+}
+
+// save saves the state of the request before injecting the credentials
+func (s *authState) save(req *generatedRequest) {
+	if req.rawRequest != nil {
+		s.rawHeaders = make(map[string]string, len(req.rawRequest.Headers))
+		for k, v := range req.rawRequest.Headers {
+			s.rawHeaders[k] = v
+		}
+		s.path, s.fullURL = req.rawRequest.Path, req.rawRequest.FullURL
+		return
+	}
+	s.header = req.request.Header.Clone()
+	s.rawQuery = req.request.URL.RawQuery
+}
+
+// restore restores the state of the request saved before injecting the credentials
+func (s *authState) restore(req *generatedRequest) {
+	if req.rawRequest != nil {
+		req.rawRequest.Headers = make(map[string]string, len(s.rawHeaders))
+		for k, v := range s.rawHeaders {
+			req.rawRequest.Headers[k] = v
+		}
+		req.rawRequest.Path, req.rawRequest.FullURL = s.path, s.fullURL
+		return
+	}
+	req.request.Header = s.header.Clone()
+	req.request.URL.RawQuery = s.rawQuery
+}
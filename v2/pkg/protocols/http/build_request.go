@@ -11,11 +11,11 @@ import (
 	"strings"
 	"time"
 
-	"github.com/corpix/uarand"
 	"github.com/pkg/errors"
 	"github.com/projectdiscovery/nuclei/v2/pkg/protocols/common/expressions"
 	"github.com/projectdiscovery/nuclei/v2/pkg/protocols/common/generators"
-	"github.com/projectdiscovery/nuclei/v2/pkg/protocols/common/replacer"
+	"github.com/projectdiscovery/nuclei/v2/pkg/protocols/common/protocolstate"
+	"github.com/projectdiscovery/nuclei/v2/pkg/protocols/http/grpc"
 	"github.com/projectdiscovery/nuclei/v2/pkg/protocols/http/race"
 	"github.com/projectdiscovery/nuclei/v2/pkg/protocols/http/raw"
 	"github.com/projectdiscovery/rawhttp"
@@ -33,17 +33,27 @@ type generatedRequest struct {
 	meta            map[string]interface{}
 	pipelinedClient *rawhttp.PipelineClient
 	request         *retryablehttp.Request
+	randomValues    map[string]interface{}
+	auth            *authState
+	// tlsSNI is the expanded tls server name of the request if any
+	tlsSNI string
+	// fallback is true if the request was retried with the other scheme
+	fallback bool
 }
 
 // Make creates a http request for the provided input.
-// It returns io.EOF as error when all the requests have been exhausted.
+// It returns io.EOF as error when all the requests have been exhausted
+// or the scan was stopped.
 func (r *requestGenerator) Make(baseURL string, dynamicValues map[string]interface{}, interactURL string) (*generatedRequest, error) {
+	if r.options.Cancelled() {
+		return nil, io.EOF
+	}
 	// We get the next payload for the request.
 	data, payloads, ok := r.nextValue()
 	if !ok {
 		return nil, io.EOF
 	}
-	ctx := context.Background()
+	ctx := r.request.requestContext()
 
 	parsed, err := url.Parse(baseURL)
 	if err != nil {
@@ -54,8 +64,20 @@ func (r *requestGenerator) Make(baseURL string, dynamicValues map[string]interfa
 	values := generators.MergeMaps(dynamicValues, map[string]interface{}{
 		"Hostname": parsed.Host,
 	})
+	// Random auto-variables are generated for each request
+	randomValues := generators.RandomValues(r.request.randomVars)
+	for k, v := range randomValues {
+		values[k] = v
+	}
+	if strings.Contains(data, "{{ip}}") {
+		values["ip"] = protocolstate.ResolveIP(parsed.Hostname())
+	}
 
 	isRawRequest := len(r.request.Raw) > 0
+	if pathMode := r.request.pathMode(); !isRawRequest && pathMode != raw.PathModePrepend && strings.HasPrefix(data, "{{BaseURL}}") {
+		data = "{{BaseURL}}" + raw.JoinPath(parsed.Path, strings.TrimPrefix(data, "{{BaseURL}}"), pathMode)
+		parsed.Path = ""
+	}
 	if !isRawRequest && strings.HasSuffix(parsed.Path, "/") && strings.Contains(data, "{{BaseURL}}/") {
 		parsed.Path = strings.TrimSuffix(parsed.Path, "/")
 	}
@@ -64,10 +86,35 @@ func (r *requestGenerator) Make(baseURL string, dynamicValues map[string]interfa
 
 	// If data contains \n it's a raw request, process it like raw. Else
 	// continue with the template based request flow.
+	var request *generatedRequest
 	if isRawRequest {
-		return r.makeHTTPRequestFromRaw(ctx, parsedString, data, values, payloads, interactURL)
+		request, err = r.makeHTTPRequestFromRaw(ctx, parsedString, data, values, payloads, interactURL)
+	} else {
+		request, err = r.makeHTTPRequestFromModel(ctx, data, values, interactURL)
 	}
-	return r.makeHTTPRequestFromModel(ctx, data, values, interactURL)
+	if err != nil {
+		return nil, err
+	}
+	request.randomValues = randomValues
+	if strings.Contains(r.request.TLSSNI, "{{") {
+		if request.tlsSNI, err = expandServerName(r.request.TLSSNI, values); err != nil {
+			return nil, err
+		}
+	}
+	return request, nil
+}
+
+// expandServerName returns the tls server name with the variables of the
+// request expanded, without the port of host variables.
+func expandServerName(serverName string, values map[string]interface{}) (string, error) {
+	final, err := expressions.Evaluate(serverName, values)
+	if err != nil {
+		return "", errors.Wrap(err, "could not evaluate tls server name")
+	}
+	if host, _, splitErr := net.SplitHostPort(final); splitErr == nil {
+		final = host
+	}
+	return final, nil
 }
 
 // Total returns the total number of requests for the generator
@@ -97,7 +144,10 @@ func baseURLWithTemplatePrefs(data string, parsed *url.URL) (string, *url.URL) {
 
 // MakeHTTPRequestFromModel creates a *http.Request from a request template
 func (r *requestGenerator) makeHTTPRequestFromModel(ctx context.Context, data string, values map[string]interface{}, interactURL string) (*generatedRequest, error) {
-	final := replacer.Replace(data, values)
+	final, err := expressions.Evaluate(data, values)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not evaluate helper expressions")
+	}
 	if interactURL != "" {
 		final = r.options.Interactsh.ReplaceMarkers(final, interactURL)
 	}
@@ -135,7 +185,7 @@ func (r *requestGenerator) handleRawWithPayloads(ctx context.Context, rawRequest
 	if err != nil {
 		return nil, errors.Wrap(err, "could not evaluate helper expressions")
 	}
-	rawRequestData, err := raw.Parse(rawRequest, baseURL, r.request.Unsafe)
+	rawRequestData, err := raw.ParseWithPathMode(rawRequest, baseURL, r.request.pathMode(), r.request.Unsafe)
 	if err != nil {
 		return nil, err
 	}
@@ -183,9 +233,13 @@ func (r *requestGenerator) fillRequest(req *http.Request, values map[string]inte
 		if interactURL != "" {
 			value = r.options.Interactsh.ReplaceMarkers(value, interactURL)
 		}
-		req.Header[header] = []string{replacer.Replace(value, values)}
+		evaluated, err := expressions.Evaluate(value, values)
+		if err != nil {
+			return nil, errors.Wrap(err, "could not evaluate helper expressions")
+		}
+		req.Header[header] = []string{evaluated}
 		if header == "Host" {
-			req.Host = replacer.Replace(value, values)
+			req.Host = evaluated
 		}
 	}
 
@@ -195,14 +249,27 @@ func (r *requestGenerator) fillRequest(req *http.Request, values map[string]inte
 	}
 
 	// Check if the user requested a request body
-	if r.request.Body != "" {
+	if r.request.Body != "" || r.request.GRPC != nil {
 		body := r.request.Body
 		if interactURL != "" {
 			body = r.options.Interactsh.ReplaceMarkers(body, interactURL)
 		}
+		body, err := expressions.Evaluate(body, values)
+		if err != nil {
+			return nil, errors.Wrap(err, "could not evaluate helper expressions")
+		}
+		if r.request.GRPC != nil {
+			message, err := r.request.GRPC.EncodeBody(body)
+			if err != nil {
+				return nil, errors.Wrap(err, "could not encode grpc message")
+			}
+			body = string(message)
+			setHeader(req, "Content-Type", grpc.ContentType)
+			setHeader(req, "TE", "trailers")
+		}
 		req.Body = ioutil.NopCloser(strings.NewReader(body))
 	}
-	setHeader(req, "User-Agent", uarand.GetRandom())
+	setHeader(req, "User-Agent", r.request.userAgent())
 
 	// Only set these headers on non raw requests
 	if len(r.request.Raw) == 0 {
@@ -156,3 +156,29 @@ Accept-Encoding: gzip`},
 	authorization = req.request.Header.Get("Authorization")
 	require.Equal(t, "Basic YWRtaW46Z3Vlc3Q=", authorization, "could not get correct authorization headers from raw")
 }
+
+func TestMakeRequestTLSServerName(t *testing.T) {
+	options := testutils.DefaultOptions
+
+	testutils.Init(options)
+	templateID := "testing-sni"
+	request := &Request{
+		ID:     templateID,
+		Path:   []string{"{{BaseURL}}"},
+		Method: "GET",
+		TLSSNI: "admin.{{Hostname}}",
+	}
+	executerOpts := testutils.NewMockExecuterOptions(options, &testutils.TemplateInfo{
+		ID:   templateID,
+		Info: map[string]interface{}{"severity": "low", "name": "test"},
+	})
+	require.Nil(t, request.Compile(executerOpts), "could not compile http request")
+
+	req, err := request.newGenerator().Make("https://example.com:8443", map[string]interface{}{}, "")
+	require.Nil(t, err, "could not make http request")
+	require.Equal(t, "admin.example.com", req.tlsSNI, "could not expand tls server name")
+
+	client, err := request.client("https://example.com:8443", req)
+	require.Nil(t, err, "could not get http client")
+	require.NotEqual(t, request.httpClient, client, "could not get client for tls server name")
+}
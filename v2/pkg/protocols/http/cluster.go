@@ -10,13 +10,16 @@ import (
 // are similar enough to be considered one and can be checked by
 // just adding the matcher/extractors for the request and the correct IDs.
 func (r *Request) CanCluster(other *Request) bool {
-	if len(r.Payloads) > 0 || len(r.Raw) > 0 || len(r.Body) > 0 || r.Unsafe || r.ReqCondition || r.Name != "" {
+	if len(r.Payloads) > 0 || len(r.Raw) > 0 || len(r.Body) > 0 || r.Unsafe || r.ReqCondition || r.Name != "" || len(r.Fuzzing) > 0 || len(other.Fuzzing) > 0 || len(r.Tamper) > 0 || len(other.Tamper) > 0 || r.GRPC != nil || other.GRPC != nil {
 		return false
 	}
 	if r.Method != other.Method ||
 		r.MaxRedirects != other.MaxRedirects ||
-		r.CookieReuse != other.CookieReuse ||
-		r.Redirects != other.Redirects {
+		r.cookieReuse() != other.cookieReuse() ||
+		r.Retries != other.Retries ||
+		r.Redirects != other.Redirects ||
+		r.HTTP2 != other.HTTP2 ||
+		r.Connection != other.Connection {
 		return false
 	}
 	if !compare.StringSlice(r.Path, other.Path) {
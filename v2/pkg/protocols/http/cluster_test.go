@@ -12,4 +12,9 @@ func TestCanCluster(t *testing.T) {
 
 	req = &Request{Path: []string{"{{BaseURL}}"}, Method: "GET"}
 	require.True(t, req.CanCluster(&Request{Path: []string{"{{BaseURL}}"}, Method: "GET"}), "could not cluster GET request")
+
+	disabled := false
+	require.False(t, req.CanCluster(&Request{Path: []string{"{{BaseURL}}"}, Method: "GET", CookieReuse: &disabled}), "could cluster request without cookie reuse")
+	enabled := true
+	require.True(t, req.CanCluster(&Request{Path: []string{"{{BaseURL}}"}, Method: "GET", CookieReuse: &enabled}), "could not cluster request with default cookie reuse")
 }
@@ -0,0 +1,65 @@
+package http
+
+import (
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/projectdiscovery/retryablehttp-go"
+)
+
+// setCSRFTokens injects the csrf tokens found in the previous responses of
+// the host in the state changing requests.
+func (r *Request) setCSRFTokens(req *generatedRequest) error {
+	if r.options.CSRF == nil || req.original.Race {
+		return nil
+	}
+
+	if req.rawRequest != nil {
+		raw := req.rawRequest
+		body := r.options.CSRF.Inject(raw.FullURL, raw.Method, raw.Data, func(key string) string {
+			return raw.Headers[rawHeaderKey(raw.Headers, key)]
+		}, func(key, value string) {
+			raw.Headers[rawHeaderKey(raw.Headers, key)] = value
+		})
+		if body != raw.Data {
+			raw.Data = body
+			if key := rawHeaderKey(raw.Headers, "Content-Length"); raw.Headers[key] != "" {
+				raw.Headers[key] = strconv.Itoa(len(body))
+			}
+		}
+		return nil
+	}
+
+	data, err := req.request.BodyBytes()
+	if err != nil {
+		return errors.Wrap(err, "could not read request body")
+	}
+	request := req.request.Request
+	body := r.options.CSRF.Inject(request.URL.String(), request.Method, string(data), request.Header.Get, request.Header.Set)
+	if body == string(data) {
+		return nil
+	}
+	request.Body = ioutil.NopCloser(strings.NewReader(body))
+	request.ContentLength = int64(len(body))
+	updated, err := retryablehttp.FromRequest(request)
+	if err != nil {
+		return errors.Wrap(err, "could not update request body")
+	}
+	req.request = updated
+	return nil
+}
+
+// rawHeaderKey returns the key of a header in the headers of a raw request,
+// comparing the canonical header keys, or the key if the header is missing.
+func rawHeaderKey(headers map[string]string, key string) string {
+	canonical := http.CanonicalHeaderKey(key)
+	for name := range headers {
+		if http.CanonicalHeaderKey(name) == canonical {
+			return name
+		}
+	}
+	return key
+}
@@ -0,0 +1,63 @@
+package http
+
+import (
+	"bytes"
+	"net"
+	"net/http"
+	"strings"
+)
+
+// httpsPortResponses are the bodies of the responses of https servers to plain http requests
+var httpsPortResponses = [][]byte{
+	[]byte("The plain HTTP request was sent to HTTPS port"),
+	[]byte("Client sent an HTTP request to an HTTPS server"),
+	[]byte("speaking plain HTTP to an SSL-enabled server port"),
+}
+
+// fallbackScheme returns the scheme to retry a request with when the error
+// or the response signal a http/https mismatch, or an empty string.
+func fallbackScheme(scheme string, err error, statusCode int, body []byte) string {
+	if err != nil {
+		message := err.Error()
+		switch {
+		case scheme == "https" && (strings.Contains(message, "server gave HTTP response to HTTPS client") || strings.Contains(message, "first record does not look like a TLS handshake")):
+			return "http"
+		case scheme == "http" && strings.Contains(message, "malformed HTTP response") && strings.Contains(message, `\x15\x03`):
+			// The server answered with a tls alert record
+			return "https"
+		}
+		return ""
+	}
+	if scheme == "http" && statusCode == http.StatusBadRequest {
+		for _, response := range httpsPortResponses {
+			if bytes.Contains(body, response) {
+				return "https"
+			}
+		}
+	}
+	return ""
+}
+
+// retryFallback switches the scheme of the request if the error or the
+// response signal a http/https mismatch, returning true if the request
+// should be retried. Requests are only retried once.
+func (r *Request) retryFallback(req *generatedRequest, err error, statusCode int, body []byte) bool {
+	if r.options.Options.NoHTTPXFallback || req.fallback || req.request == nil || req.original.Race || req.original.Pipeline {
+		return false
+	}
+	scheme := fallbackScheme(req.request.URL.Scheme, err, statusCode, body)
+	if scheme == "" {
+		return false
+	}
+	// The request is retried on the same port with the other scheme
+	if req.request.URL.Port() == "" {
+		port := "80"
+		if req.request.URL.Scheme == "https" {
+			port = "443"
+		}
+		req.request.URL.Host = net.JoinHostPort(req.request.URL.Hostname(), port)
+	}
+	req.fallback = true
+	req.request.URL.Scheme = scheme
+	return true
+}
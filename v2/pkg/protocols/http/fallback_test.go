@@ -0,0 +1,40 @@
+package http
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/projectdiscovery/nuclei/v2/internal/testutils"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFallbackScheme(t *testing.T) {
+	require.Equal(t, "http", fallbackScheme("https", errors.New("http: server gave HTTP response to HTTPS client"), 0, nil), "could not fallback to http")
+	require.Equal(t, "https", fallbackScheme("http", errors.New(`net/http: HTTP/1.x transport connection broken: malformed HTTP response "\x15\x03\x01\x00\x02\x02"`), 0, nil), "could not fallback to https on tls alert")
+	require.Equal(t, "https", fallbackScheme("http", nil, 400, []byte("<h1>400 Bad Request</h1>The plain HTTP request was sent to HTTPS port")), "could not fallback to https on response")
+	require.Empty(t, fallbackScheme("http", errors.New("connection refused"), 0, nil), "could fallback on network error")
+	require.Empty(t, fallbackScheme("https", nil, 400, []byte("The plain HTTP request was sent to HTTPS port")), "could fallback https request on response")
+	require.Empty(t, fallbackScheme("http", nil, 200, []byte("ok")), "could fallback on valid response")
+}
+
+func TestRetryFallbackPort(t *testing.T) {
+	options := testutils.DefaultOptions
+	testutils.Init(options)
+	request := &Request{Path: []string{"{{BaseURL}}"}, Method: "GET"}
+	executerOpts := testutils.NewMockExecuterOptions(options, &testutils.TemplateInfo{
+		ID:   "testing-fallback",
+		Info: map[string]interface{}{"severity": "low", "name": "test"},
+	})
+	require.Nil(t, request.Compile(executerOpts), "could not compile http request")
+
+	tlsErr := errors.New("http: server gave HTTP response to HTTPS client")
+	for input, expected := range map[string]string{
+		"https://example.com":      "http://example.com:443",
+		"https://example.com:8443": "http://example.com:8443",
+	} {
+		generated, err := request.newGenerator().Make(input, map[string]interface{}{}, "")
+		require.Nil(t, err, "could not make http request")
+		require.True(t, request.retryFallback(generated, tlsErr, 0, nil), "could not retry request")
+		require.Equal(t, expected, generated.request.URL.String(), "could not keep port of request")
+	}
+}
@@ -0,0 +1,143 @@
+package http
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/projectdiscovery/nuclei/v2/pkg/output"
+	"github.com/projectdiscovery/nuclei/v2/pkg/protocols"
+	"github.com/projectdiscovery/nuclei/v2/pkg/protocols/common/expressions"
+	"github.com/projectdiscovery/nuclei/v2/pkg/protocols/common/interactsh"
+	"github.com/projectdiscovery/retryablehttp-go"
+	"go.uber.org/multierr"
+)
+
+// executeFuzzingRules executes the fuzzing rules of the request on the
+// requests built for the URL, sending a request for each mutation. The
+// requests of the API specification operations for the URL are used
+// instead of the template ones if available.
+func (r *Request) executeFuzzingRules(reqURL string, dynamicValues, previous output.InternalEvent, callback protocols.OutputEventCallback) error {
+	requestCount := 1
+	if inputRequests := r.options.InputRequests.Get(reqURL); len(inputRequests) > 0 {
+		var requestErr error
+		for _, input := range inputRequests {
+			request, err := http.NewRequestWithContext(r.requestContext(), input.Method, input.URL, strings.NewReader(input.Body))
+			if err != nil {
+				requestErr = multierr.Append(requestErr, err)
+				continue
+			}
+			for key, value := range input.Headers {
+				request.Header.Set(key, value)
+			}
+			if err := r.fuzzRequest(reqURL, request, []byte(input.Body), nil, dynamicValues, previous, callback, &requestCount); err != nil {
+				requestErr = multierr.Append(requestErr, err)
+			}
+		}
+		return requestErr
+	}
+
+	generator := r.newGenerator()
+	var requestErr error
+	for {
+		base, err := generator.Make(reqURL, dynamicValues, "")
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if base.request == nil {
+			return errors.New("fuzzing is not supported for unsafe requests")
+		}
+		body, err := base.request.BodyBytes()
+		if err != nil {
+			return errors.Wrap(err, "could not read request body")
+		}
+		if err := r.fuzzRequest(reqURL, base.request.Request, body, base.randomValues, dynamicValues, previous, callback, &requestCount); err != nil {
+			requestErr = multierr.Append(requestErr, err)
+		}
+	}
+	return requestErr
+}
+
+// fuzzRequest sends the mutations of the fuzzing rules for a base request
+func (r *Request) fuzzRequest(reqURL string, base *http.Request, body []byte, randomValues map[string]interface{}, dynamicValues, previous output.InternalEvent, callback protocols.OutputEventCallback, requestCount *int) error {
+	hasInteractMarkers := interactsh.HasMatchers(r.CompiledOperators)
+
+	var requestErr error
+	for _, rule := range r.Fuzzing {
+		for _, payload := range rule.Fuzz {
+			// Each mutated request gets its own interactsh url so that the
+			// interactions can be attributed to the mutated parameter.
+			var interactURL, value string
+			mutationPayload := func() string {
+				value = payload
+				if r.options.Interactsh != nil && hasInteractMarkers {
+					interactURL = r.options.Interactsh.URL()
+					value = r.options.Interactsh.ReplaceMarkers(value, interactURL)
+				}
+				if evaluated, evalErr := expressions.Evaluate(value, dynamicValues); evalErr == nil {
+					value = evaluated
+				}
+				return value
+			}
+
+			err := rule.Execute(base, body, mutationPayload, func(mutated *http.Request) bool {
+				gotOutput, err := r.executeMutatedRequest(reqURL, mutated, map[string]interface{}{"fuzz": value}, randomValues, interactURL, previous, callback, requestCount)
+				if err != nil {
+					requestErr = multierr.Append(requestErr, err)
+				}
+				return !(r.options.Options.StopAtFirstMatch && gotOutput)
+			})
+			if err != nil {
+				requestErr = multierr.Append(requestErr, err)
+			}
+		}
+	}
+	return requestErr
+}
+
+// executeMutatedRequest sends a mutated request, returning true if the
+// request produced an output.
+func (r *Request) executeMutatedRequest(reqURL string, mutated *http.Request, meta, randomValues map[string]interface{}, interactURL string, previous output.InternalEvent, callback protocols.OutputEventCallback, requestCount *int) (bool, error) {
+	request, err := retryablehttp.FromRequest(mutated)
+	if err != nil {
+		return false, err
+	}
+	generated := &generatedRequest{
+		request:      request,
+		original:     r,
+		meta:         meta,
+		randomValues: randomValues,
+	}
+
+	var gotOutput bool
+	if r.options.WafDetector != nil {
+		time.Sleep(r.options.WafDetector.Delay(reqURL))
+	}
+	r.options.RateLimiter.Take()
+	err = r.executeRequest(reqURL, generated, previous, func(event *output.InternalWrappedEvent) {
+		if event.OperatorsResult != nil {
+			gotOutput = true
+		}
+		if interactURL != "" {
+			r.options.Interactsh.RequestEvent(interactURL, &interactsh.RequestData{
+				MakeResultFunc: r.MakeResultEvent,
+				Event:          event,
+				Operators:      r.CompiledOperators,
+				MatchFunc:      r.Match,
+				ExtractFunc:    r.Extract,
+				Workflow:       r.options.Workflow,
+				Input:          reqURL,
+			})
+		} else {
+			callback(event)
+		}
+	}, *requestCount)
+	*requestCount++
+	r.options.Progress.IncrementRequests()
+	return gotOutput, err
+}
@@ -0,0 +1,276 @@
+package fuzz
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// parameter is a single key value parameter of a part of the request
+type parameter struct {
+	key   string
+	value string
+}
+
+// buildFunc builds a request from the original one with modified parameters
+type buildFunc func(parameters []parameter) (*http.Request, error)
+
+// Execute mutates the parameters of the request selected by the rule with the
+// payload and calls the callback with each mutated request. The payload function
+// is called for each mutated request, right before the callback with it, so that
+// each mutation can use unique values. The body is passed separately as the
+// request body can only be read once. The execution stops if the callback
+// returns false.
+func (r *Rule) Execute(req *http.Request, body []byte, payload func() string, callback func(*http.Request) bool) error {
+	parameters, build, err := r.parameters(req, body)
+	if err != nil {
+		return errors.Wrap(err, "could not get request parameters")
+	}
+
+	var selected []int
+	for i, param := range parameters {
+		if r.matchKeyValue(param.key, param.value) {
+			selected = append(selected, i)
+		}
+	}
+	if len(selected) == 0 {
+		return nil
+	}
+
+	if r.Mode == "multiple" {
+		mutated := append([]parameter{}, parameters...)
+		value := payload()
+		for _, i := range selected {
+			mutated[i].value = r.mutate(mutated[i].value, value)
+		}
+		request, err := build(mutated)
+		if err != nil {
+			return err
+		}
+		callback(request)
+		return nil
+	}
+
+	for _, i := range selected {
+		mutated := append([]parameter{}, parameters...)
+		mutated[i].value = r.mutate(mutated[i].value, payload())
+
+		request, err := build(mutated)
+		if err != nil {
+			return err
+		}
+		if !callback(request) {
+			return nil
+		}
+	}
+	return nil
+}
+
+// parameters returns the parameters of the rule part of the request
+func (r *Rule) parameters(req *http.Request, body []byte) ([]parameter, buildFunc, error) {
+	switch r.Part {
+	case "path":
+		return pathParameters(req, body)
+	case "header":
+		return headerParameters(req, body)
+	case "body":
+		if strings.Contains(req.Header.Get("Content-Type"), "json") {
+			return jsonParameters(req, body)
+		}
+		parameters, err := parseQuery(string(body))
+		if err != nil {
+			return nil, nil, err
+		}
+		return parameters, func(parameters []parameter) (*http.Request, error) {
+			return cloneRequest(req, []byte(encodeQuery(parameters))), nil
+		}, nil
+	}
+	parameters, err := parseQuery(req.URL.RawQuery)
+	if err != nil {
+		return nil, nil, err
+	}
+	return parameters, func(parameters []parameter) (*http.Request, error) {
+		request := cloneRequest(req, body)
+		request.URL.RawQuery = encodeQuery(parameters)
+		return request, nil
+	}, nil
+}
+
+func pathParameters(req *http.Request, body []byte) ([]parameter, buildFunc, error) {
+	segments := strings.Split(req.URL.Path, "/")
+
+	var parameters []parameter
+	for i, segment := range segments {
+		if segment != "" {
+			parameters = append(parameters, parameter{key: strconv.Itoa(i), value: segment})
+		}
+	}
+	return parameters, func(parameters []parameter) (*http.Request, error) {
+		mutated := append([]string{}, segments...)
+		for _, param := range parameters {
+			index, _ := strconv.Atoi(param.key)
+			mutated[index] = param.value
+		}
+		request := cloneRequest(req, body)
+		request.URL.Path = strings.Join(mutated, "/")
+		request.URL.RawPath = ""
+		return request, nil
+	}, nil
+}
+
+func headerParameters(req *http.Request, body []byte) ([]parameter, buildFunc, error) {
+	keys := make([]string, 0, len(req.Header))
+	for key := range req.Header {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	parameters := make([]parameter, 0, len(keys))
+	for _, key := range keys {
+		parameters = append(parameters, parameter{key: key, value: req.Header.Get(key)})
+	}
+	return parameters, func(parameters []parameter) (*http.Request, error) {
+		request := cloneRequest(req, body)
+		for _, param := range parameters {
+			request.Header.Set(param.key, param.value)
+		}
+		return request, nil
+	}, nil
+}
+
+// jsonParameters returns the values of a json object body. The values of
+// nested objects use the dot separated path of their keys (like variables.id).
+func jsonParameters(req *http.Request, body []byte) ([]parameter, buildFunc, error) {
+	object := make(map[string]interface{})
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	if err := decoder.Decode(&object); err != nil {
+		return nil, nil, errors.Wrap(err, "could not decode json body")
+	}
+
+	var parameters []parameter
+	flattenJSON("", object, &parameters)
+	original := parameters
+	return parameters, func(parameters []parameter) (*http.Request, error) {
+		mutated := copyJSON(object).(map[string]interface{})
+		// Only mutated values are changed to strings keeping the other types
+		for i, param := range parameters {
+			if param.value != original[i].value {
+				setJSON(mutated, strings.Split(param.key, "."), param.value)
+			}
+		}
+		data, err := json.Marshal(mutated)
+		if err != nil {
+			return nil, errors.Wrap(err, "could not encode json body")
+		}
+		return cloneRequest(req, data), nil
+	}, nil
+}
+
+// flattenJSON appends the scalar values of an object in the order of their keys
+func flattenJSON(prefix string, object map[string]interface{}, parameters *[]parameter) {
+	keys := make([]string, 0, len(object))
+	for key := range object {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		path := prefix + key
+		switch value := object[key].(type) {
+		case string:
+			*parameters = append(*parameters, parameter{key: path, value: value})
+		case json.Number:
+			*parameters = append(*parameters, parameter{key: path, value: value.String()})
+		case bool:
+			*parameters = append(*parameters, parameter{key: path, value: strconv.FormatBool(value)})
+		case map[string]interface{}:
+			flattenJSON(path+".", value, parameters)
+		}
+	}
+}
+
+// copyJSON returns a deep copy of the nested objects of a json value
+func copyJSON(value interface{}) interface{} {
+	object, ok := value.(map[string]interface{})
+	if !ok {
+		return value
+	}
+	copied := make(map[string]interface{}, len(object))
+	for key, item := range object {
+		copied[key] = copyJSON(item)
+	}
+	return copied
+}
+
+// setJSON sets the value at the path of keys of a json object
+func setJSON(object map[string]interface{}, path []string, value string) {
+	for _, key := range path[:len(path)-1] {
+		nested, ok := object[key].(map[string]interface{})
+		if !ok {
+			return
+		}
+		object = nested
+	}
+	object[path[len(path)-1]] = value
+}
+
+// parseQuery parses a query string keeping the order of the parameters
+func parseQuery(query string) ([]parameter, error) {
+	var parameters []parameter
+	for _, item := range strings.Split(query, "&") {
+		if item == "" {
+			continue
+		}
+		key, value := item, ""
+		if index := strings.Index(item, "="); index != -1 {
+			key, value = item[:index], item[index+1:]
+		}
+		key, err := url.QueryUnescape(key)
+		if err != nil {
+			return nil, err
+		}
+		value, err = url.QueryUnescape(value)
+		if err != nil {
+			return nil, err
+		}
+		parameters = append(parameters, parameter{key: key, value: value})
+	}
+	return parameters, nil
+}
+
+// encodeQuery encodes the parameters as a query string in their order
+func encodeQuery(parameters []parameter) string {
+	builder := &strings.Builder{}
+	for i, param := range parameters {
+		if i > 0 {
+			builder.WriteString("&")
+		}
+		builder.WriteString(url.QueryEscape(param.key))
+		builder.WriteString("=")
+		builder.WriteString(url.QueryEscape(param.value))
+	}
+	return builder.String()
+}
+
+// cloneRequest clones the request with a new body
+func cloneRequest(req *http.Request, body []byte) *http.Request {
+	request := req.Clone(req.Context())
+	request.Body = ioutil.NopCloser(bytes.NewReader(body))
+	request.GetBody = func() (io.ReadCloser, error) {
+		return ioutil.NopCloser(bytes.NewReader(body)), nil
+	}
+	request.ContentLength = int64(len(body))
+	if len(body) == 0 {
+		request.Body = http.NoBody
+	}
+	return request
+}
@@ -0,0 +1,117 @@
+// Package fuzz implements the fuzzing rules of http requests mutating
+// the parameters of user provided requests with payloads.
+package fuzz
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Rule is a single fuzzing rule of a template
+type Rule struct {
+	// Type is the type of the mutation - replace, prefix or postfix.
+	Type string `yaml:"type"`
+	// Part is the part of the request to fuzz - query, body, header or path.
+	Part string `yaml:"part"`
+	// Mode is the mode of fuzzing - single mutates one parameter per request
+	// and multiple mutates all the parameters at once. Default is single.
+	Mode string `yaml:"mode"`
+	// Keys are the names of the parameters to fuzz. All parameters are fuzzed
+	// if neither keys nor keys-regex are provided. The keys of nested json
+	// body values are dot separated (like variables.id).
+	Keys []string `yaml:"keys"`
+	// KeysRegex are regexes matching the names of the parameters to fuzz
+	KeysRegex []string `yaml:"keys-regex"`
+	// Values are regexes matching the values of the parameters to fuzz
+	Values []string `yaml:"values"`
+	// Fuzz are the payloads to mutate the parameters with
+	Fuzz []string `yaml:"fuzz"`
+
+	keys        map[string]struct{}
+	keysRegex   []*regexp.Regexp
+	valuesRegex []*regexp.Regexp
+}
+
+// Compile validates the rule and compiles its selectors
+func (r *Rule) Compile() error {
+	switch r.Type {
+	case "replace", "prefix", "postfix":
+	default:
+		return errors.Errorf("invalid fuzzing type %s (it should be replace, prefix or postfix)", r.Type)
+	}
+	switch r.Part {
+	case "query", "body", "header", "path":
+	case "":
+		r.Part = "query"
+	default:
+		return errors.Errorf("invalid fuzzing part %s (it should be query, body, header or path)", r.Part)
+	}
+	switch r.Mode {
+	case "single", "multiple":
+	case "":
+		r.Mode = "single"
+	default:
+		return errors.Errorf("invalid fuzzing mode %s (it should be single or multiple)", r.Mode)
+	}
+	if len(r.Fuzz) == 0 {
+		return errors.New("no fuzzing payloads provided")
+	}
+
+	r.keys = make(map[string]struct{}, len(r.Keys))
+	for _, key := range r.Keys {
+		r.keys[strings.ToLower(key)] = struct{}{}
+	}
+	for _, expr := range r.KeysRegex {
+		compiled, err := regexp.Compile(expr)
+		if err != nil {
+			return errors.Wrapf(err, "could not compile keys regex %s", expr)
+		}
+		r.keysRegex = append(r.keysRegex, compiled)
+	}
+	for _, expr := range r.Values {
+		compiled, err := regexp.Compile(expr)
+		if err != nil {
+			return errors.Wrapf(err, "could not compile values regex %s", expr)
+		}
+		r.valuesRegex = append(r.valuesRegex, compiled)
+	}
+	return nil
+}
+
+// matchKeyValue returns true if a parameter is selected by the rule
+func (r *Rule) matchKeyValue(key, value string) bool {
+	keyMatched := len(r.keys) == 0 && len(r.keysRegex) == 0
+	if _, ok := r.keys[strings.ToLower(key)]; ok {
+		keyMatched = true
+	}
+	for _, regex := range r.keysRegex {
+		if regex.MatchString(key) {
+			keyMatched = true
+		}
+	}
+	if !keyMatched {
+		return false
+	}
+	if len(r.valuesRegex) == 0 {
+		return true
+	}
+	for _, regex := range r.valuesRegex {
+		if regex.MatchString(value) {
+			return true
+		}
+	}
+	return false
+}
+
+// mutate returns the value of a parameter mutated with the payload
+func (r *Rule) mutate(value, payload string) string {
+	switch r.Type {
+	case "prefix":
+		return payload + value
+	case "postfix":
+		return value + payload
+	}
+	return payload
+}
@@ -0,0 +1,101 @@
+package fuzz
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func executeRule(t *testing.T, rule *Rule, req *http.Request, body string) []*http.Request {
+	err := rule.Compile()
+	require.Nil(t, err, "could not compile rule")
+
+	var requests []*http.Request
+	err = rule.Execute(req, []byte(body), func() string { return rule.Fuzz[0] }, func(request *http.Request) bool {
+		requests = append(requests, request)
+		return true
+	})
+	require.Nil(t, err, "could not execute rule")
+	return requests
+}
+
+func TestQueryFuzzing(t *testing.T) {
+	req, _ := http.NewRequest("GET", "https://example.com/search?q=test&page=1&id=5", nil)
+
+	requests := executeRule(t, &Rule{Type: "postfix", Fuzz: []string{"'"}}, req, "")
+	require.Len(t, requests, 3, "could not fuzz all query parameters")
+	require.Equal(t, "q=test%27&page=1&id=5", requests[0].URL.RawQuery, "could not fuzz first parameter")
+	require.Equal(t, "q=test&page=1&id=5%27", requests[2].URL.RawQuery, "could not fuzz last parameter")
+	require.Equal(t, "q=test&page=1&id=5", req.URL.RawQuery, "could modify original request")
+
+	requests = executeRule(t, &Rule{Type: "replace", Mode: "multiple", Values: []string{`^\d+$`}, Fuzz: []string{"-1"}}, req, "")
+	require.Len(t, requests, 1, "could not fuzz parameters at once")
+	require.Equal(t, "q=test&page=-1&id=-1", requests[0].URL.RawQuery, "could not fuzz numeric parameters")
+
+	requests = executeRule(t, &Rule{Type: "prefix", Keys: []string{"ID"}, Fuzz: []string{"../"}}, req, "")
+	require.Len(t, requests, 1, "could not fuzz selected key")
+	require.Equal(t, "q=test&page=1&id=..%2F5", requests[0].URL.RawQuery, "could not prefix parameter")
+}
+
+func TestBodyFuzzing(t *testing.T) {
+	req, _ := http.NewRequest("POST", "https://example.com/login", nil)
+	req.Header.Set("Content-Type", "application/json")
+
+	requests := executeRule(t, &Rule{Type: "replace", Part: "body", KeysRegex: []string{"^user"}, Fuzz: []string{"admin"}}, req, `{"username":"guest","remember":true}`)
+	require.Len(t, requests, 1, "could not fuzz json body")
+	data, _ := ioutil.ReadAll(requests[0].Body)
+	require.Equal(t, `{"remember":true,"username":"admin"}`, string(data), "could not fuzz json key")
+
+	requests = executeRule(t, &Rule{Type: "postfix", Part: "body", Keys: []string{"variables.id"}, Fuzz: []string{"'"}}, req, `{"query":"query user($id: ID!) { user(id: $id) { name } }","variables":{"id":"5","limit":10}}`)
+	require.Len(t, requests, 1, "could not fuzz nested json body")
+	data, _ = ioutil.ReadAll(requests[0].Body)
+	require.Equal(t, `{"query":"query user($id: ID!) { user(id: $id) { name } }","variables":{"id":"5'","limit":10}}`, string(data), "could not fuzz nested json key")
+
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	requests = executeRule(t, &Rule{Type: "replace", Part: "body", Keys: []string{"b"}, Fuzz: []string{"x y"}}, req, "a=1&b=2")
+	data, _ = ioutil.ReadAll(requests[0].Body)
+	require.Equal(t, "a=1&b=x+y", string(data), "could not fuzz form body")
+	require.Equal(t, int64(9), requests[0].ContentLength, "could not update content length")
+}
+
+func TestPathAndHeaderFuzzing(t *testing.T) {
+	req, _ := http.NewRequest("GET", "https://example.com/api/users/5", nil)
+	req.Header.Set("X-Forwarded-For", "127.0.0.1")
+
+	requests := executeRule(t, &Rule{Type: "replace", Part: "path", Values: []string{`^\d+$`}, Fuzz: []string{"6"}}, req, "")
+	require.Len(t, requests, 1, "could not fuzz path segment")
+	require.Equal(t, "/api/users/6", requests[0].URL.Path, "could not replace path segment")
+
+	requests = executeRule(t, &Rule{Type: "replace", Part: "header", Fuzz: []string{"10.0.0.1"}}, req, "")
+	require.Len(t, requests, 1, "could not fuzz header")
+	require.Equal(t, "10.0.0.1", requests[0].Header.Get("X-Forwarded-For"), "could not replace header")
+}
+
+func TestRuleCompile(t *testing.T) {
+	require.NotNil(t, (&Rule{Type: "append", Fuzz: []string{"'"}}).Compile(), "could compile invalid type")
+	require.NotNil(t, (&Rule{Type: "replace", Part: "cookie", Fuzz: []string{"'"}}).Compile(), "could compile invalid part")
+	require.NotNil(t, (&Rule{Type: "replace"}).Compile(), "could compile rule without payloads")
+	require.True(t, strings.HasPrefix((&Rule{Type: "replace", KeysRegex: []string{"("}, Fuzz: []string{"'"}}).Compile().Error(), "could not compile keys regex"), "could compile invalid regex")
+}
+
+func TestFuzzingPayloadPerMutation(t *testing.T) {
+	req, _ := http.NewRequest("GET", "https://example.com/?url=a&next=b", nil)
+	rule := &Rule{Type: "replace", Fuzz: []string{"{{interactsh-url}}"}}
+	require.Nil(t, rule.Compile(), "could not compile rule")
+
+	var count int
+	var queries []string
+	err := rule.Execute(req, nil, func() string {
+		count++
+		return fmt.Sprintf("%d.oast.example", count)
+	}, func(request *http.Request) bool {
+		queries = append(queries, request.URL.RawQuery)
+		return true
+	})
+	require.Nil(t, err, "could not execute rule")
+	require.Equal(t, []string{"url=1.oast.example&next=b", "url=a&next=2.oast.example"}, queries, "could not use a payload per mutation")
+}
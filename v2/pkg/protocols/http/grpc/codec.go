@@ -0,0 +1,41 @@
+package grpc
+
+import (
+	"bytes"
+	"encoding/json"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// marshal encodes a json message as a protobuf message of the type
+func marshal(descriptor protoreflect.MessageDescriptor, data []byte) ([]byte, error) {
+	message := dynamicpb.NewMessage(descriptor)
+	if len(bytes.TrimSpace(data)) > 0 {
+		if err := protojson.Unmarshal(data, message); err != nil {
+			return nil, err
+		}
+	}
+	return proto.MarshalOptions{Deterministic: true}.Marshal(message)
+}
+
+// unmarshal decodes a protobuf message of the type as a compact json message
+func unmarshal(descriptor protoreflect.MessageDescriptor, data []byte) ([]byte, error) {
+	message := dynamicpb.NewMessage(descriptor)
+	if err := proto.Unmarshal(data, message); err != nil {
+		return nil, err
+	}
+	encoded, err := protojson.Marshal(message)
+	if err != nil {
+		return nil, err
+	}
+	// protojson randomly adds whitespace to its output, compact it so the
+	// responses are stable for matchers.
+	buffer := &bytes.Buffer{}
+	if err := json.Compact(buffer, encoded); err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
@@ -0,0 +1,35 @@
+package grpc
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// parseMethod returns the descriptor of a method (package.Service/Method)
+// from a FileDescriptorSet as written by protoc --descriptor_set_out
+func parseMethod(data []byte, method string) (protoreflect.MethodDescriptor, error) {
+	set := &descriptorpb.FileDescriptorSet{}
+	if err := proto.Unmarshal(data, set); err != nil {
+		return nil, errors.Wrap(err, "could not decode descriptor set")
+	}
+	files, err := protodesc.NewFiles(set)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not resolve descriptor set")
+	}
+
+	name := protoreflect.FullName(strings.Replace(strings.TrimPrefix(method, "/"), "/", ".", 1))
+	descriptor, err := files.FindDescriptorByName(name)
+	if err != nil {
+		return nil, errors.Errorf("unknown grpc method %s", method)
+	}
+	methodDescriptor, ok := descriptor.(protoreflect.MethodDescriptor)
+	if !ok {
+		return nil, errors.Errorf("%s is not a grpc method", method)
+	}
+	return methodDescriptor, nil
+}
@@ -0,0 +1,89 @@
+// Package grpc implements basic unary and server streaming grpc calls
+// over http/2 requests using the types of supplied proto descriptors.
+package grpc
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"io/ioutil"
+	"strings"
+
+	"github.com/pkg/errors"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// ContentType is the content type of grpc requests
+const ContentType = "application/grpc"
+
+// Request is a grpc call made by a http request
+type Request struct {
+	// Descriptor is the file of the proto descriptor set containing the
+	// service, as written by protoc --include_imports --descriptor_set_out.
+	Descriptor string `yaml:"descriptor"`
+	// Method is the full name of the method to call (package.Service/Method).
+	// The body of the request is the input message as json.
+	Method string `yaml:"method"`
+
+	method protoreflect.MethodDescriptor
+}
+
+// Compile loads the descriptor set of the request from the resolved path
+func (r *Request) Compile(descriptorPath string) error {
+	if r.Method == "" {
+		return errors.New("no grpc method specified")
+	}
+	data, err := ioutil.ReadFile(descriptorPath)
+	if err != nil {
+		return errors.Wrap(err, "could not read descriptor set")
+	}
+	r.method, err = parseMethod(data, r.Method)
+	return err
+}
+
+// Path returns the path of the method call
+func (r *Request) Path() string {
+	return "/" + strings.TrimPrefix(r.Method, "/")
+}
+
+// EncodeBody encodes the json input message as a framed protobuf message
+func (r *Request) EncodeBody(body string) ([]byte, error) {
+	data, err := marshal(r.method.Input(), []byte(body))
+	if err != nil {
+		return nil, errors.Wrap(err, "could not encode message")
+	}
+
+	buffer := &bytes.Buffer{}
+	var header [5]byte
+	binary.BigEndian.PutUint32(header[1:], uint32(len(data)))
+	buffer.Write(header[:])
+	buffer.Write(data)
+	return buffer.Bytes(), nil
+}
+
+// DecodeResponse decodes the framed protobuf messages of a response body as
+// json. Each message of streaming responses is on a separate line.
+func (r *Request) DecodeResponse(body []byte) (string, error) {
+	var messages []string
+	reader := bytes.NewReader(body)
+	for reader.Len() > 0 {
+		var header [5]byte
+		if _, err := io.ReadFull(reader, header[:]); err != nil {
+			return "", errors.Wrap(err, "could not read message header")
+		}
+		if header[0] != 0 {
+			return "", errors.New("compressed messages are not supported")
+		}
+		data := make([]byte, binary.BigEndian.Uint32(header[1:]))
+		if _, err := io.ReadFull(reader, data); err != nil {
+			return "", errors.Wrap(err, "could not read message")
+		}
+
+		encoded, err := unmarshal(r.method.Output(), data)
+		if err != nil {
+			return "", errors.Wrap(err, "could not decode message")
+		}
+		messages = append(messages, string(encoded))
+	}
+	return strings.Join(messages, "\n"), nil
+}
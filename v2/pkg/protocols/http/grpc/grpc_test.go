@@ -0,0 +1,127 @@
+package grpc
+
+import (
+	"encoding/binary"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+func field(name string, number int32, label descriptorpb.FieldDescriptorProto_Label, kind descriptorpb.FieldDescriptorProto_Type, typeName string) *descriptorpb.FieldDescriptorProto {
+	field := &descriptorpb.FieldDescriptorProto{Name: proto.String(name), Number: proto.Int32(number), Label: label.Enum(), Type: kind.Enum()}
+	if typeName != "" {
+		field.TypeName = proto.String(typeName)
+	}
+	return field
+}
+
+// testDescriptorSet returns a descriptor set of the following proto file.
+//
+//	package test;
+//	enum Role { USER = 0; ADMIN = 1; }
+//	message HelloRequest { string name = 1; int32 count = 2; repeated int64 ids = 3; Role role = 4; }
+//	message HelloReply { string message = 1; repeated int32 codes = 2; }
+//	service Greeter { rpc SayHello (HelloRequest) returns (HelloReply); }
+func testDescriptorSet(t *testing.T) []byte {
+	optional := descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL
+	repeated := descriptorpb.FieldDescriptorProto_LABEL_REPEATED
+
+	file := &descriptorpb.FileDescriptorProto{
+		Name:    proto.String("test.proto"),
+		Package: proto.String("test"),
+		MessageType: []*descriptorpb.DescriptorProto{{
+			Name: proto.String("HelloRequest"),
+			Field: []*descriptorpb.FieldDescriptorProto{
+				field("name", 1, optional, descriptorpb.FieldDescriptorProto_TYPE_STRING, ""),
+				field("count", 2, optional, descriptorpb.FieldDescriptorProto_TYPE_INT32, ""),
+				field("ids", 3, repeated, descriptorpb.FieldDescriptorProto_TYPE_INT64, ""),
+				field("role", 4, optional, descriptorpb.FieldDescriptorProto_TYPE_ENUM, ".test.Role"),
+			},
+		}, {
+			Name: proto.String("HelloReply"),
+			Field: []*descriptorpb.FieldDescriptorProto{
+				field("message", 1, optional, descriptorpb.FieldDescriptorProto_TYPE_STRING, ""),
+				field("codes", 2, repeated, descriptorpb.FieldDescriptorProto_TYPE_INT32, ""),
+			},
+		}},
+		EnumType: []*descriptorpb.EnumDescriptorProto{{
+			Name: proto.String("Role"),
+			Value: []*descriptorpb.EnumValueDescriptorProto{
+				{Name: proto.String("USER"), Number: proto.Int32(0)},
+				{Name: proto.String("ADMIN"), Number: proto.Int32(1)},
+			},
+		}},
+		Service: []*descriptorpb.ServiceDescriptorProto{{
+			Name: proto.String("Greeter"),
+			Method: []*descriptorpb.MethodDescriptorProto{{
+				Name:       proto.String("SayHello"),
+				InputType:  proto.String(".test.HelloRequest"),
+				OutputType: proto.String(".test.HelloReply"),
+			}},
+		}},
+	}
+	data, err := proto.Marshal(&descriptorpb.FileDescriptorSet{File: []*descriptorpb.FileDescriptorProto{file}})
+	require.Nil(t, err, "could not encode descriptor set")
+	return data
+}
+
+func compileRequest(t *testing.T) *Request {
+	file, err := ioutil.TempFile("", "grpc-*.protoset")
+	require.Nil(t, err, "could not create descriptor file")
+	defer os.Remove(file.Name())
+	_, _ = file.Write(testDescriptorSet(t))
+	file.Close()
+
+	request := &Request{Method: "test.Greeter/SayHello"}
+	require.Nil(t, request.Compile(file.Name()), "could not compile request")
+	return request
+}
+
+func TestEncodeBody(t *testing.T) {
+	request := compileRequest(t)
+	require.Equal(t, "/test.Greeter/SayHello", request.Path(), "could not get method path")
+
+	body, err := request.EncodeBody(`{"name": "nuclei", "count": -1, "ids": [1, "2"], "role": "ADMIN"}`)
+	require.Nil(t, err, "could not encode body")
+	require.Equal(t, []byte{0, 0, 0, 0, byte(len(body) - 5)}, body[:5], "could not get message header")
+
+	message := []byte{0x0a, 0x06, 'n', 'u', 'c', 'l', 'e', 'i', 0x10, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x01, 0x18, 0x01, 0x18, 0x02, 0x20, 0x01}
+	require.Equal(t, message, body[5:], "could not get protobuf encoding")
+
+	_, err = request.EncodeBody(`{"unknown": 1}`)
+	require.NotNil(t, err, "could encode unknown field")
+}
+
+func TestDecodeResponse(t *testing.T) {
+	request := compileRequest(t)
+
+	message := []byte{0x0a, 0x02, 'h', 'i', 0x12, 0x02, 0x01, 0x02}
+	body := make([]byte, 5, 5+2*len(message))
+	binary.BigEndian.PutUint32(body[1:], uint32(len(message)))
+	body = append(body, message...)
+	body = append(body, body...)
+
+	decoded, err := request.DecodeResponse(body)
+	require.Nil(t, err, "could not decode response")
+	require.Equal(t, "{\"message\":\"hi\",\"codes\":[1,2]}\n{\"message\":\"hi\",\"codes\":[1,2]}", decoded, "could not decode streamed messages")
+
+	_, err = request.DecodeResponse(body[:7])
+	require.NotNil(t, err, "could decode truncated response")
+}
+
+func TestCompileUnknownMethod(t *testing.T) {
+	file, err := ioutil.TempFile("", "grpc-*.protoset")
+	require.Nil(t, err, "could not create descriptor file")
+	defer os.Remove(file.Name())
+	_, _ = file.Write(testDescriptorSet(t))
+	file.Close()
+
+	request := &Request{Method: "test.Greeter/SayGoodbye"}
+	require.NotNil(t, request.Compile(file.Name()), "could compile unknown method")
+	request = &Request{Method: "test/HelloRequest"}
+	require.NotNil(t, request.Compile(file.Name()), "could compile message as method")
+}
@@ -1,13 +1,22 @@
 package http
 
 import (
+	"context"
 	"strings"
 
+	"github.com/corpix/uarand"
 	"github.com/pkg/errors"
 	"github.com/projectdiscovery/nuclei/v2/pkg/operators"
+	"github.com/projectdiscovery/nuclei/v2/pkg/operators/matchers"
 	"github.com/projectdiscovery/nuclei/v2/pkg/protocols"
+	"github.com/projectdiscovery/nuclei/v2/pkg/protocols/common/auditlog"
 	"github.com/projectdiscovery/nuclei/v2/pkg/protocols/common/generators"
+	"github.com/projectdiscovery/nuclei/v2/pkg/protocols/common/protocolstate"
+	"github.com/projectdiscovery/nuclei/v2/pkg/protocols/http/fuzz"
+	"github.com/projectdiscovery/nuclei/v2/pkg/protocols/http/grpc"
 	"github.com/projectdiscovery/nuclei/v2/pkg/protocols/http/httpclientpool"
+	"github.com/projectdiscovery/nuclei/v2/pkg/protocols/http/raw"
+	"github.com/projectdiscovery/nuclei/v2/pkg/protocols/http/tamper"
 	"github.com/projectdiscovery/rawhttp"
 	"github.com/projectdiscovery/retryablehttp-go"
 )
@@ -22,7 +31,7 @@ type Request struct {
 	Raw []string `yaml:"raw"`
 	ID  string   `yaml:"id"`
 	// Name is the name of the request
-	Name string `yaml:"Name"`
+	Name string `yaml:"name"`
 	// AttackType is the attack type
 	// Sniper, PitchFork and ClusterBomb. Default is Sniper
 	AttackType string `yaml:"attack"`
@@ -35,7 +44,7 @@ type Request struct {
 	// Headers contains headers to send with the request
 	Headers map[string]string `yaml:"headers"`
 	// RaceNumberRequests is the number of same request to send in race condition attack
-	RaceNumberRequests int `yaml:"race_count"`
+	RaceNumberRequests int `yaml:"race-count"`
 	// MaxRedirects is the maximum number of redirects that should be followed.
 	MaxRedirects int `yaml:"max-redirects"`
 	// PipelineConcurrentConnections is number of connections in pipelining
@@ -47,6 +56,8 @@ type Request struct {
 
 	// MaxSize is the maximum size of http response body to read in bytes.
 	MaxSize int `yaml:"max-size"`
+	// Retries is the number of retries for the request overriding the global value
+	Retries int `yaml:"retries"`
 
 	CompiledOperators *operators.Operators
 
@@ -55,10 +66,15 @@ type Request struct {
 	totalRequests int
 	customHeaders map[string]string
 	generator     *generators.Generator // optional, only enabled when using payloads
+	randomVars    []string              // random auto-variables generated for each request
 	httpClient    *retryablehttp.Client
 	rawhttpClient *rawhttp.Client
-	// CookieReuse is an optional setting that makes cookies shared within requests
-	CookieReuse bool `yaml:"cookie-reuse"`
+	// clientConfiguration is the configuration of the http client of the requests
+	clientConfiguration *httpclientpool.Configuration
+	// CookieReuse shares the cookies between the requests of the template
+	// executed on an input, enabled by default. False sends the requests
+	// without the cookies set by previous responses.
+	CookieReuse *bool `yaml:"cookie-reuse"`
 	// Redirects specifies whether redirects should be followed.
 	Redirects bool `yaml:"redirects"`
 	// Pipeline defines if the attack should be performed with HTTP 1.1 Pipelining (race conditions/billions requests)
@@ -73,6 +89,33 @@ type Request struct {
 	// their history for being matched at the end.
 	// Currently only works with sequential http requests.
 	ReqCondition bool `yaml:"req-condition"`
+	// PathMode specifies how the path of the target is combined with the
+	// request path. Can be prepend (default), replace or merge.
+	PathMode string `yaml:"path-mode"`
+	// Fuzzing are the rules for fuzzing the parameters of the input
+	// requests instead of sending the template requests as is.
+	Fuzzing []*fuzz.Rule `yaml:"fuzzing"`
+	// Tamper are the techniques sending tampered variants of the requests
+	// to bypass access controls - path-case, path-encoding, header-spoofing
+	// and method. The name of each variant is available as tamper.
+	Tamper []string `yaml:"tamper"`
+	// UserAgent overrides the user agent of the requests. The value random
+	// uses a random agent for each request.
+	UserAgent string `yaml:"user-agent"`
+	// HTTP2 sends the requests over http/2. Cleartext http targets are
+	// sent with prior knowledge (h2c).
+	HTTP2 bool `yaml:"http2"`
+	// GRPC makes a grpc call with the json message of the body as input.
+	// The requests are sent over http/2 to the path of the method.
+	GRPC *grpc.Request `yaml:"grpc"`
+	// TLSSNI is the server name sent in the tls handshake instead of the
+	// host of the request, to test virtual hosts of ip targets. The
+	// variables of the request are expanded (eg. admin.{{Hostname}}).
+	TLSSNI string `yaml:"tls-sni"`
+	// Connection overrides the reuse of the connections to the target -
+	// close sends each request on a new connection and keep-alive reuses
+	// the connections even without threads.
+	Connection string `yaml:"connection"`
 }
 
 // GetID returns the unique ID of the request if any.
@@ -80,14 +123,92 @@ func (r *Request) GetID() string {
 	return r.ID
 }
 
+// GetCompiledOperators returns the compiled operators of the request
+func (r *Request) GetCompiledOperators() *operators.Operators {
+	return r.CompiledOperators
+}
+
+// pathMode returns the path mode of the request falling back to the global option
+func (r *Request) pathMode() string {
+	if r.PathMode != "" {
+		return r.PathMode
+	}
+	if r.options != nil && r.options.Options.PathMode != "" {
+		return r.options.Options.PathMode
+	}
+	return raw.PathModePrepend
+}
+
+// userAgent returns the user agent for a request of the template
+func (r *Request) userAgent() string {
+	switch {
+	case strings.EqualFold(r.UserAgent, "random"):
+		return uarand.GetRandom()
+	case r.UserAgent != "":
+		return r.UserAgent
+	}
+	return httpclientpool.UserAgent()
+}
+
+// requestContext returns the context of the requests of the template,
+// recording them in the audit log if enabled.
+func (r *Request) requestContext() context.Context {
+	return auditlog.WithTemplate(r.options.RequestContext(), r.options.AuditLog, r.options.TemplateID)
+}
+
+// client returns the http client for a request to an input, with the tls server
+// name of the request if the template server name has variables and the cookie
+// jar of the input if the cookies are shared.
+func (r *Request) client(input string, request *generatedRequest) (*retryablehttp.Client, error) {
+	client := r.httpClient
+	if request.tlsSNI != "" && request.tlsSNI != r.clientConfiguration.TLSSNI {
+		configuration := *r.clientConfiguration
+		configuration.TLSSNI = request.tlsSNI
+		var err error
+		if client, err = httpclientpool.Get(r.options.Options, &configuration); err != nil {
+			return nil, err
+		}
+	}
+	if !r.cookieReuse() {
+		return client, nil
+	}
+	jar, err := r.options.CookieJars.Get(input)
+	if err != nil {
+		return nil, err
+	}
+	return httpclientpool.WithCookieJar(client, jar), nil
+}
+
+// cookieReuse returns true if the cookies are shared between the requests
+func (r *Request) cookieReuse() bool {
+	return r.CookieReuse == nil || *r.CookieReuse
+}
+
 // Compile compiles the protocol request for further execution.
 func (r *Request) Compile(options *protocols.ExecuterOptions) error {
-	client, err := httpclientpool.Get(options.Options, &httpclientpool.Configuration{
+	switch r.Connection {
+	case "", httpclientpool.ConnectionClose, httpclientpool.ConnectionKeepAlive:
+	default:
+		return errors.Errorf("invalid connection %s (it should be close or keep-alive)", r.Connection)
+	}
+	// The jars are created for each input by the first request of the template
+	// sharing them with the others, unless a scan wide jar is provided.
+	if r.cookieReuse() && options.CookieJars == nil {
+		options.CookieJars = httpclientpool.NewCookieJars(options.CookieJar)
+	}
+	r.clientConfiguration = &httpclientpool.Configuration{
 		Threads:         r.Threads,
 		MaxRedirects:    r.MaxRedirects,
 		FollowRedirects: r.Redirects,
-		CookieReuse:     r.CookieReuse,
-	})
+		Retries:         r.Retries,
+		HTTP2:           r.HTTP2 || r.GRPC != nil,
+		Connection:      r.Connection,
+	}
+	// The server names with variables are expanded for each request
+	if !strings.Contains(r.TLSSNI, "{{") {
+		r.clientConfiguration.TLSSNI = r.TLSSNI
+	}
+	client, err := httpclientpool.Get(options.Options, r.clientConfiguration)
 	if err != nil {
 		return errors.Wrap(err, "could not get dns client")
 	}
@@ -102,6 +223,10 @@ func (r *Request) Compile(options *protocols.ExecuterOptions) error {
 		r.customHeaders[parts[0]] = strings.TrimSpace(parts[1])
 	}
 
+	if err := raw.ValidatePathMode(r.PathMode); err != nil {
+		return errors.Wrap(err, "could not validate path mode")
+	}
+
 	if r.Body != "" && !strings.Contains(r.Body, "\r\n") {
 		r.Body = strings.ReplaceAll(r.Body, "\n", "\r\n")
 	}
@@ -113,11 +238,47 @@ func (r *Request) Compile(options *protocols.ExecuterOptions) error {
 		}
 		r.rawhttpClient = httpclientpool.GetRawHTTP()
 	}
+	// The connections of the raw http clients can't be bound to a local address
+	if (r.Unsafe || r.Pipeline) && protocolstate.SourceIP != nil {
+		return errors.New("unsafe and pipelined requests can't be sent from the source address (-interface or -source-ip)")
+	}
+	if r.GRPC != nil {
+		if len(r.Raw) > 0 {
+			return errors.New("grpc is not supported for raw requests")
+		}
+		descriptor, err := options.Catalog.ResolvePath(r.GRPC.Descriptor, options.TemplatePath)
+		if err != nil {
+			return errors.Wrap(err, "could not resolve grpc descriptor")
+		}
+		if !options.Options.AllowLocalFileAccess && !options.Catalog.IsSandboxed(descriptor, options.TemplatePath) {
+			return errors.Errorf("grpc descriptor %s is outside the templates directory (use -allow-local-file-access)", r.GRPC.Descriptor)
+		}
+		if err := r.GRPC.Compile(descriptor); err != nil {
+			return errors.Wrap(err, "could not compile grpc request")
+		}
+		r.Method = "POST"
+		if len(r.Path) == 0 {
+			r.Path = []string{"{{BaseURL}}" + r.GRPC.Path()}
+		}
+	}
+	for _, rule := range r.Fuzzing {
+		if err := rule.Compile(); err != nil {
+			return errors.Wrap(err, "could not compile fuzzing rule")
+		}
+	}
+	if err := tamper.Compile(r.Tamper); err != nil {
+		return errors.Wrap(err, "could not compile tampering techniques")
+	}
 	if len(r.Matchers) > 0 || len(r.Extractors) > 0 {
 		compiled := &r.Operators
 		if compileErr := compiled.Compile(); compileErr != nil {
 			return errors.Wrap(compileErr, "could not compile operators")
 		}
+		for _, matcher := range compiled.Matchers {
+			if matcher.GetType() == matchers.DiffMatcher && !r.ReqCondition {
+				return errors.New("diff matchers require req-condition")
+			}
+		}
 		r.CompiledOperators = compiled
 	}
 
@@ -136,6 +297,9 @@ func (r *Request) Compile(options *protocols.ExecuterOptions) error {
 				if resolveErr != nil {
 					return errors.Wrap(resolveErr, "could not read payload file")
 				}
+				if !options.Options.AllowLocalFileAccess && !options.Catalog.IsSandboxed(final, options.TemplatePath) {
+					return errors.Errorf("payload file %s is outside the templates directory (use -allow-local-file-access)", payloadStr)
+				}
 				r.Payloads[name] = final
 			}
 		}
@@ -145,12 +309,33 @@ func (r *Request) Compile(options *protocols.ExecuterOptions) error {
 		}
 	}
 	r.options = options
+	r.randomVars = r.getRandomVariables()
 	r.totalRequests = r.Requests()
 	return nil
 }
 
+// getRandomVariables returns the random auto-variables used in the request
+func (r *Request) getRandomVariables() []string {
+	data := make([]string, 0, len(r.Path)+len(r.Raw)+len(r.Headers)+1)
+	data = append(data, r.Path...)
+	data = append(data, r.Raw...)
+	data = append(data, r.Body)
+	for _, value := range r.Headers {
+		data = append(data, value)
+	}
+	return generators.RandomVariables(data...)
+}
+
 // Requests returns the total number of requests the YAML rule will perform
 func (r *Request) Requests() int {
+	if len(r.Tamper) > 0 {
+		return r.requests() * r.tamperRequests()
+	}
+	return r.requests()
+}
+
+// requests returns the number of requests built by the request
+func (r *Request) requests() int {
 	if r.generator != nil {
 		payloadRequests := r.generator.NewIterator().Total() * len(r.Raw)
 		return payloadRequests
@@ -15,7 +15,9 @@ import (
 
 	"github.com/pkg/errors"
 	"github.com/projectdiscovery/fastdialer/fastdialer"
+	"github.com/projectdiscovery/nuclei/v2/pkg/protocols/common/auditlog"
 	"github.com/projectdiscovery/nuclei/v2/pkg/protocols/common/protocolstate"
+	"github.com/projectdiscovery/nuclei/v2/pkg/protocols/common/retry"
 	"github.com/projectdiscovery/nuclei/v2/pkg/types"
 	"github.com/projectdiscovery/rawhttp"
 	"github.com/projectdiscovery/retryablehttp-go"
@@ -27,20 +29,28 @@ var (
 	// Dialer is a copy of the fatdialer from protocolstate
 	Dialer *fastdialer.Dialer
 
-	rawhttpClient *rawhttp.Client
-	poolMutex     *sync.RWMutex
-	normalClient  *retryablehttp.Client
-	clientPool    map[string]*retryablehttp.Client
+	rawhttpClient     *rawhttp.Client
+	rawhttpClientOnce sync.Once
+	poolMutex         *sync.RWMutex
+	normalClient      *retryablehttp.Client
+	clientPool        map[string]*retryablehttp.Client
+	// sharedTransports are the transports shared by the clients with
+	// -max-conns-per-host per tls server name.
+	sharedTransports map[string]*http.Transport
 )
 
 // Init initializes the clientpool implementation
 func Init(options *types.Options) error {
+	if err := initUserAgents(options); err != nil {
+		return err
+	}
 	// Don't create clients if already created in past.
 	if normalClient != nil {
 		return nil
 	}
 	poolMutex = &sync.RWMutex{}
 	clientPool = make(map[string]*retryablehttp.Client)
+	sharedTransports = make(map[string]*http.Transport)
 
 	client, err := wrappedGet(options, &Configuration{})
 	if err != nil {
@@ -50,16 +60,29 @@ func Init(options *types.Options) error {
 	return nil
 }
 
+const (
+	// ConnectionClose closes the connection after each request
+	ConnectionClose = "close"
+	// ConnectionKeepAlive reuses the connections to the hosts between requests
+	ConnectionKeepAlive = "keep-alive"
+)
+
 // Configuration contains the custom configuration options for a client
 type Configuration struct {
 	// Threads contains the threads for the client
 	Threads int
 	// MaxRedirects is the maximum number of redirects to follow
 	MaxRedirects int
-	// CookieReuse enables cookie reuse for the http client (cookiejar impl)
-	CookieReuse bool
 	// FollowRedirects specifies whether to follow redirects
 	FollowRedirects bool
+	// Retries overrides the global number of retries for the client
+	Retries int
+	// HTTP2 sends the requests over http/2, with prior knowledge for cleartext http
+	HTTP2 bool
+	// TLSSNI is the server name sent in the tls handshake instead of the host of the request
+	TLSSNI string
+	// Connection overrides the reuse of the connections - close or keep-alive
+	Connection string
 }
 
 // Hash returns the hash of the configuration to allow client pooling
@@ -72,23 +95,29 @@ func (c *Configuration) Hash() string {
 	builder.WriteString(strconv.Itoa(c.MaxRedirects))
 	builder.WriteString("f")
 	builder.WriteString(strconv.FormatBool(c.FollowRedirects))
-	builder.WriteString("r")
-	builder.WriteString(strconv.FormatBool(c.CookieReuse))
+	builder.WriteString("rt")
+	builder.WriteString(strconv.Itoa(c.Retries))
+	builder.WriteString("h")
+	builder.WriteString(strconv.FormatBool(c.HTTP2))
+	builder.WriteString("s")
+	builder.WriteString(c.TLSSNI)
+	builder.WriteString("c")
+	builder.WriteString(c.Connection)
 	hash := builder.String()
 	return hash
 }
 
 // GetRawHTTP returns the rawhttp request client
 func GetRawHTTP() *rawhttp.Client {
-	if rawhttpClient == nil {
+	rawhttpClientOnce.Do(func() {
 		rawhttpClient = rawhttp.NewClient(rawhttp.DefaultOptions)
-	}
+	})
 	return rawhttpClient
 }
 
 // Get creates or gets a client for the protocol based on custom configuration
 func Get(options *types.Options, configuration *Configuration) (*retryablehttp.Client, error) {
-	if configuration.Threads == 0 && configuration.MaxRedirects == 0 && !configuration.FollowRedirects && !configuration.CookieReuse {
+	if configuration.Threads == 0 && configuration.MaxRedirects == 0 && !configuration.FollowRedirects && configuration.Retries == 0 && !configuration.HTTP2 && configuration.TLSSNI == "" && configuration.Connection == "" {
 		return normalClient, nil
 	}
 	return wrappedGet(options, configuration)
@@ -135,22 +164,116 @@ func wrappedGet(options *types.Options, configuration *Configuration) (*retryabl
 		maxIdleConnsPerHost = 500
 		maxConnsPerHost = 500
 	}
+	if configuration.Connection == ConnectionKeepAlive {
+		disableKeepAlives = false
+		maxIdleConnsPerHost = http.DefaultMaxIdleConnsPerHost
+	}
+	if options.MaxConnsPerHost > 0 {
+		maxConnsPerHost = options.MaxConnsPerHost
+		if !disableKeepAlives {
+			maxIdleConnsPerHost = options.MaxConnsPerHost
+		}
+	}
+	if options.DisableKeepAlive || configuration.Connection == ConnectionClose {
+		disableKeepAlives = true
+		maxIdleConnsPerHost = -1
+	}
 
-	retryablehttpOptions.RetryWaitMax = 10 * time.Second
-	retryablehttpOptions.RetryMax = options.Retries
+	retryPolicy := retry.NewPolicy(options, configuration.Retries)
+	retryablehttpOptions.RetryWaitMin = retryPolicy.Backoff
+	retryablehttpOptions.RetryWaitMax = retryPolicy.MaxBackoff
+	retryablehttpOptions.RetryMax = retryPolicy.Retries
 	followRedirects := configuration.FollowRedirects
 	maxRedirects := configuration.MaxRedirects
 
+	var transport *http.Transport
+	var roundTripper http.RoundTripper
+	if options.MaxConnsPerHost > 0 && !configuration.HTTP2 {
+		// The clients share the transport so that the connections to a host
+		// are limited across all of them, keep-alives are disabled per request.
+		transport = getSharedTransport(options, configuration.TLSSNI, proxyURL)
+		if disableKeepAlives && !transport.DisableKeepAlives {
+			roundTripper = &connectionCloser{transport: transport}
+		}
+	} else {
+		transport = newTransport(options, proxyURL, configuration.TLSSNI, disableKeepAlives, maxIdleConns, maxIdleConnsPerHost, maxConnsPerHost)
+		if configuration.HTTP2 {
+			if roundTripper, err = newHTTP2Transport(transport.Clone()); err != nil {
+				return nil, errors.Wrap(err, "could not configure http2 transport")
+			}
+		}
+	}
+
+	client := retryablehttp.NewWithHTTPClient(&http.Client{
+		Transport:     transport,
+		Timeout:       time.Duration(options.Timeout) * time.Second,
+		CheckRedirect: makeCheckRedirectFunc(followRedirects, maxRedirects),
+	}, retryablehttpOptions)
+	// retryablehttp needs a http transport to create the client, the
+	// wrapping round trippers are set afterwards.
+	if roundTripper != nil {
+		client.HTTPClient.Transport = roundTripper
+	}
+	// Every attempt and redirect of the requests of the templates is audited
+	client.HTTPClient.Transport = auditlog.NewContextTransport("http", client.HTTPClient.Transport)
+	client.CheckRetry = retryablehttp.HostSprayRetryPolicy()
+	client.Backoff = retry.HTTPBackoff
+
+	poolMutex.Lock()
+	clientPool[hash] = client
+	poolMutex.Unlock()
+	return client, nil
+}
+
+// WithCookieJar returns a copy of the client storing the cookies of the
+// responses in the jar. The copies are not pooled as the jars are short-lived.
+func WithCookieJar(client *retryablehttp.Client, jar http.CookieJar) *retryablehttp.Client {
+	if jar == nil {
+		return client
+	}
+	httpClient := *client.HTTPClient
+	httpClient.Jar = jar
+	copied := *client
+	copied.HTTPClient = &httpClient
+	return &copied
+}
+
+// getSharedTransport returns the transport shared by the clients for a tls
+// server name, limiting the connections to each host to -max-conns-per-host.
+func getSharedTransport(options *types.Options, tlsSNI string, proxyURL *url.URL) *http.Transport {
+	poolMutex.Lock()
+	defer poolMutex.Unlock()
+
+	if transport, ok := sharedTransports[tlsSNI]; ok {
+		return transport
+	}
+	maxIdleConnsPerHost := options.MaxConnsPerHost
+	if options.DisableKeepAlive {
+		maxIdleConnsPerHost = -1
+	}
+	transport := newTransport(options, proxyURL, tlsSNI, options.DisableKeepAlive, 0, maxIdleConnsPerHost, options.MaxConnsPerHost)
+	sharedTransports[tlsSNI] = transport
+	return transport
+}
+
+// newTransport creates a http transport dialing with the dialer or the socks proxy
+func newTransport(options *types.Options, proxyURL *url.URL, tlsSNI string, disableKeepAlives bool, maxIdleConns, maxIdleConnsPerHost, maxConnsPerHost int) *http.Transport {
+	dialContext := Dialer.Dial
+	if protocolstate.Resolver != nil {
+		dialContext = protocolstate.Resolver.Dial
+	}
 	transport := &http.Transport{
-		DialContext:         Dialer.Dial,
+		DialContext:         dialContext,
 		MaxIdleConns:        maxIdleConns,
 		MaxIdleConnsPerHost: maxIdleConnsPerHost,
 		MaxConnsPerHost:     maxConnsPerHost,
 		TLSClientConfig: &tls.Config{
 			Renegotiation:      tls.RenegotiateOnceAsClient,
 			InsecureSkipVerify: true,
+			ServerName:         tlsSNI,
 		},
 		DisableKeepAlives: disableKeepAlives,
+		IdleConnTimeout:   time.Duration(options.IdleConnTimeout) * time.Second,
 	}
 
 	// Attempts to overwrite the dial function with the socks proxied version
@@ -174,31 +297,29 @@ func wrappedGet(options *types.Options, configuration *Configuration) (*retryabl
 	if proxyURL != nil {
 		transport.Proxy = http.ProxyURL(proxyURL)
 	}
+	return transport
+}
 
-	var jar *cookiejar.Jar
-	if configuration.CookieReuse {
-		if jar, err = cookiejar.New(&cookiejar.Options{PublicSuffixList: publicsuffix.List}); err != nil {
-			return nil, errors.Wrap(err, "could not create cookiejar")
-		}
-	}
+// connectionCloser closes the connection after each request sent over a
+// shared transport keeping the connections alive.
+type connectionCloser struct {
+	transport http.RoundTripper
+}
 
-	client := retryablehttp.NewWithHTTPClient(&http.Client{
-		Transport:     transport,
-		Timeout:       time.Duration(options.Timeout) * time.Second,
-		CheckRedirect: makeCheckRedirectFunc(followRedirects, maxRedirects),
-	}, retryablehttpOptions)
-	if jar != nil {
-		client.HTTPClient.Jar = jar
-	}
-	client.CheckRetry = retryablehttp.HostSprayRetryPolicy()
+// RoundTrip sends the request with the Connection: close header
+func (c *connectionCloser) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.Close = true
+	return c.transport.RoundTrip(req)
+}
 
-	// Only add to client pool if we don't have a cookie jar in place.
-	if jar == nil {
-		poolMutex.Lock()
-		clientPool[hash] = client
-		poolMutex.Unlock()
+// NewCookieJar returns a cookie jar keeping the cookies of each host
+func NewCookieJar() (http.CookieJar, error) {
+	jar, err := cookiejar.New(&cookiejar.Options{PublicSuffixList: publicsuffix.List})
+	if err != nil {
+		return nil, errors.Wrap(err, "could not create cookiejar")
 	}
-	return client, nil
+	return jar, nil
 }
 
 const defaultMaxRedirects = 10
@@ -0,0 +1,120 @@
+package httpclientpool
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/projectdiscovery/nuclei/v2/pkg/protocols/common/auditlog"
+	"github.com/projectdiscovery/nuclei/v2/pkg/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCookieJarClients(t *testing.T) {
+	options := &types.Options{Timeout: 5}
+	err := Init(options)
+	require.Nil(t, err, "could not init client pool")
+
+	jars := NewCookieJars(nil)
+	first, err := jars.Get("https://example.com")
+	require.Nil(t, err, "could not get cookie jar")
+	second, err := jars.Get("https://example.org")
+	require.Nil(t, err, "could not get cookie jar")
+	same, err := jars.Get("https://example.com")
+	require.Nil(t, err, "could not get cookie jar")
+	require.True(t, first == same, "could not share cookie jar of input")
+	require.True(t, first != second, "could share cookie jar between inputs")
+
+	jars.Release("https://example.com")
+	released, err := jars.Get("https://example.com")
+	require.Nil(t, err, "could not get cookie jar")
+	require.True(t, first != released, "could keep cookie jar of released input")
+
+	pooled := len(clientPool)
+	firstClient := WithCookieJar(normalClient, first)
+	secondClient := WithCookieJar(normalClient, second)
+	require.True(t, firstClient.HTTPClient.Jar == first, "could not set cookie jar")
+	require.True(t, secondClient.HTTPClient.Jar == second, "could not set cookie jar")
+	require.Nil(t, normalClient.HTTPClient.Jar, "could not keep pooled client without cookie jar")
+	require.True(t, firstClient.HTTPClient.Transport == normalClient.HTTPClient.Transport, "could not share transport")
+	require.Len(t, clientPool, pooled, "could pool clients of cookie jars")
+
+	shared, err := NewCookieJar()
+	require.Nil(t, err, "could not create cookie jar")
+	jar, err := NewCookieJars(shared).Get("https://example.com")
+	require.Nil(t, err, "could not get shared cookie jar")
+	require.True(t, jar == shared, "could not use shared cookie jar")
+}
+
+func TestConnectionConfiguration(t *testing.T) {
+	options := &types.Options{Timeout: 5}
+	err := Init(options)
+	require.Nil(t, err, "could not init client pool")
+
+	transport := func(options *types.Options, configuration *Configuration) *http.Transport {
+		client, err := wrappedGet(options, configuration)
+		require.Nil(t, err, "could not get client")
+		transport, ok := auditlog.Unwrap(client.HTTPClient.Transport).(*http.Transport)
+		require.True(t, ok, "could not get http transport")
+		return transport
+	}
+
+	keepAlive := transport(options, &Configuration{Connection: ConnectionKeepAlive})
+	require.False(t, keepAlive.DisableKeepAlives, "could not enable keep-alive")
+	require.Equal(t, http.DefaultMaxIdleConnsPerHost, keepAlive.MaxIdleConnsPerHost, "could not keep idle connections")
+
+	closed := transport(options, &Configuration{Threads: 10, Connection: ConnectionClose})
+	require.True(t, closed.DisableKeepAlives, "could not close connections")
+
+	disabled := transport(&types.Options{Timeout: 5, DisableKeepAlive: true}, &Configuration{Threads: 5, Connection: ConnectionKeepAlive})
+	require.True(t, disabled.DisableKeepAlives, "could not disable keep-alive globally")
+}
+
+func TestSharedTransportMaxConnsPerHost(t *testing.T) {
+	options := &types.Options{Timeout: 5, MaxConnsPerHost: 4}
+	err := Init(options)
+	require.Nil(t, err, "could not init client pool")
+
+	single, err := wrappedGet(options, &Configuration{Threads: 20})
+	require.Nil(t, err, "could not get client")
+	spraying, err := wrappedGet(options, &Configuration{MaxRedirects: 3})
+	require.Nil(t, err, "could not get client")
+
+	transport, ok := auditlog.Unwrap(single.HTTPClient.Transport).(*http.Transport)
+	require.True(t, ok, "could not get shared transport")
+	require.Equal(t, 4, transport.MaxConnsPerHost, "could not limit connections per host")
+	require.False(t, transport.DisableKeepAlives, "could not keep connections alive")
+
+	closer, ok := auditlog.Unwrap(spraying.HTTPClient.Transport).(*connectionCloser)
+	require.True(t, ok, "could not close connections of spraying client")
+	require.True(t, closer.transport == transport, "could not share transport")
+}
+
+func TestConnectionCloser(t *testing.T) {
+	closed := make(chan bool, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		closed <- r.Close
+	}))
+	defer server.Close()
+
+	client := &http.Client{Transport: &connectionCloser{transport: &http.Transport{}}}
+	req, err := http.NewRequest(http.MethodGet, server.URL, nil)
+	require.Nil(t, err, "could not create request")
+	resp, err := client.Do(req)
+	require.Nil(t, err, "could not send request")
+	resp.Body.Close()
+
+	require.True(t, <-closed, "could not send connection close")
+	require.False(t, req.Close, "could modify the original request")
+}
+
+func TestHTTP2Client(t *testing.T) {
+	options := &types.Options{Timeout: 5}
+	err := Init(options)
+	require.Nil(t, err, "could not init client pool")
+
+	client, err := wrappedGet(options, &Configuration{HTTP2: true})
+	require.Nil(t, err, "could not get http2 client")
+	_, ok := auditlog.Unwrap(client.HTTPClient.Transport).(*http2Transport)
+	require.True(t, ok, "could not set http2 transport")
+}
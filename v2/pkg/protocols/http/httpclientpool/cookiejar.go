@@ -0,0 +1,53 @@
+package httpclientpool
+
+import (
+	"net/http"
+	"sync"
+)
+
+// CookieJars contains the cookie jars of the inputs a template is being
+// executed on, so the cookies are shared by the requests of one execution
+// without leaking into the executions on other inputs.
+type CookieJars struct {
+	mutex  sync.Mutex
+	jars   map[string]http.CookieJar
+	shared http.CookieJar
+}
+
+// NewCookieJars creates the cookie jars of a template. If shared is not
+// nil, it is used for all the inputs instead (eg. with -reuse-cookies).
+func NewCookieJars(shared http.CookieJar) *CookieJars {
+	return &CookieJars{jars: make(map[string]http.CookieJar), shared: shared}
+}
+
+// Get returns the cookie jar of an input, creating it on the first request
+func (c *CookieJars) Get(input string) (http.CookieJar, error) {
+	if c == nil {
+		return nil, nil
+	}
+	if c.shared != nil {
+		return c.shared, nil
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if jar, ok := c.jars[input]; ok {
+		return jar, nil
+	}
+	jar, err := NewCookieJar()
+	if err != nil {
+		return nil, err
+	}
+	c.jars[input] = jar
+	return jar, nil
+}
+
+// Release removes the cookie jar of an input once the template execution
+// on it is finished.
+func (c *CookieJars) Release(input string) {
+	if c == nil {
+		return
+	}
+	c.mutex.Lock()
+	delete(c.jars, input)
+	c.mutex.Unlock()
+}
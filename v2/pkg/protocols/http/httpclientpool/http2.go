@@ -0,0 +1,40 @@
+package httpclientpool
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"net/http"
+
+	"golang.org/x/net/http2"
+)
+
+// http2Transport sends https requests over http/2 negotiated with ALPN and
+// http requests over cleartext http/2 with prior knowledge (h2c).
+type http2Transport struct {
+	tls       *http.Transport
+	cleartext *http2.Transport
+}
+
+// newHTTP2Transport creates a http/2 transport from a http transport
+func newHTTP2Transport(transport *http.Transport) (*http2Transport, error) {
+	if err := http2.ConfigureTransport(transport); err != nil {
+		return nil, err
+	}
+	dialContext := transport.DialContext
+	cleartext := &http2.Transport{
+		AllowHTTP: true,
+		DialTLS: func(network, addr string, _ *tls.Config) (net.Conn, error) {
+			return dialContext(context.Background(), network, addr)
+		},
+	}
+	return &http2Transport{tls: transport, cleartext: cleartext}, nil
+}
+
+// RoundTrip executes the request over http/2
+func (t *http2Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.URL.Scheme == "http" {
+		return t.cleartext.RoundTrip(req)
+	}
+	return t.tls.RoundTrip(req)
+}
@@ -0,0 +1,72 @@
+package httpclientpool
+
+import (
+	"bufio"
+	"os"
+	"strings"
+
+	"github.com/corpix/uarand"
+	"github.com/pkg/errors"
+	"github.com/projectdiscovery/nuclei/v2/pkg/types"
+)
+
+// DefaultUserAgent is the user agent used when random agents are disabled
+const DefaultUserAgent = "Mozilla/5.0 (compatible; Nuclei - Open-source project (github.com/projectdiscovery/nuclei))"
+
+// fixedUserAgent is the user agent for all the requests if random agents are disabled
+var fixedUserAgent string
+
+// initUserAgents loads the user agents from the user agent file if any
+// and configures the user agent strategy of the requests.
+func initUserAgents(options *types.Options) error {
+	var agents []string
+	if options.UserAgentFile != "" {
+		var err error
+		if agents, err = readUserAgents(options.UserAgentFile); err != nil {
+			return err
+		}
+		uarand.Default = uarand.NewWithCustomList(agents)
+	}
+
+	fixedUserAgent = ""
+	if !options.RandomAgent {
+		fixedUserAgent = DefaultUserAgent
+		if len(agents) > 0 {
+			fixedUserAgent = agents[0]
+		}
+	}
+	return nil
+}
+
+// readUserAgents reads a list of user agents from a file
+func readUserAgents(file string) ([]string, error) {
+	input, err := os.Open(file)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not open user agent file")
+	}
+	defer input.Close()
+
+	var agents []string
+	scanner := bufio.NewScanner(input)
+	for scanner.Scan() {
+		if agent := strings.TrimSpace(scanner.Text()); agent != "" && !strings.HasPrefix(agent, "#") {
+			agents = append(agents, agent)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, errors.Wrap(err, "could not read user agent file")
+	}
+	if len(agents) == 0 {
+		return nil, errors.New("no user agents found in file")
+	}
+	return agents, nil
+}
+
+// UserAgent returns the user agent for a request, a random one from the
+// list of agents unless random agents were disabled.
+func UserAgent() string {
+	if fixedUserAgent != "" {
+		return fixedUserAgent
+	}
+	return uarand.GetRandom()
+}
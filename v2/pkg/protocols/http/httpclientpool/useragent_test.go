@@ -0,0 +1,32 @@
+package httpclientpool
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/projectdiscovery/nuclei/v2/pkg/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUserAgents(t *testing.T) {
+	file, err := ioutil.TempFile("", "user-agents")
+	require.Nil(t, err, "could not create temporary file")
+	defer os.Remove(file.Name())
+	_, _ = file.WriteString("# comment\nagent-one\n\nagent-two\n")
+	file.Close()
+
+	err = initUserAgents(&types.Options{UserAgentFile: file.Name(), RandomAgent: true})
+	require.Nil(t, err, "could not init user agents")
+	for i := 0; i < 10; i++ {
+		require.Contains(t, []string{"agent-one", "agent-two"}, UserAgent(), "could not get agent from file")
+	}
+
+	err = initUserAgents(&types.Options{UserAgentFile: file.Name()})
+	require.Nil(t, err, "could not init user agents")
+	require.Equal(t, "agent-one", UserAgent(), "could not get fixed agent from file")
+
+	err = initUserAgents(&types.Options{})
+	require.Nil(t, err, "could not init user agents")
+	require.Equal(t, DefaultUserAgent, UserAgent(), "could not get default agent")
+}
@@ -2,6 +2,7 @@ package http
 
 import (
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
 
@@ -36,13 +37,60 @@ func (r *Request) Match(data map[string]interface{}, matcher *matchers.Matcher)
 	case matchers.RegexMatcher:
 		return matcher.Result(matcher.MatchRegex(item))
 	case matchers.BinaryMatcher:
-		return matcher.Result(matcher.MatchBinary(item))
+		return matcher.Result(matcher.MatchBinary(binaryMatchPart(matcher.Part, data, item)))
 	case matchers.DSLMatcher:
 		return matcher.Result(matcher.MatchDSL(data))
+	case matchers.DiffMatcher:
+		return matcher.Result(matchDiff(data, matcher))
 	}
 	return false
 }
 
+// binaryMatchPart returns the part of the response for a binary matcher,
+// with the body before it was transcoded to utf-8 if it was.
+func binaryMatchPart(part string, data output.InternalEvent, item string) string {
+	raw, ok := data["raw_body"]
+	if !ok {
+		return item
+	}
+	switch part {
+	case "body":
+		return types.ToString(raw)
+	case "all":
+		return types.ToString(raw) + types.ToString(data["all_headers"])
+	}
+	return item
+}
+
+// matchDiff compares the responses of the requests of a diff matcher,
+// stored in the data with the request number suffix by req-condition.
+func matchDiff(data output.InternalEvent, matcher *matchers.Matcher) bool {
+	first, second := requestData(data, matcher.Compare[0]), requestData(data, matcher.Compare[1])
+	firstPart, ok := getMatchPart(matcher.Part, first)
+	if !ok {
+		return false
+	}
+	secondPart, ok := getMatchPart(matcher.Part, second)
+	if !ok {
+		return false
+	}
+	firstStatus, _ := first["status_code"].(int)
+	secondStatus, _ := second["status_code"].(int)
+	return matcher.MatchDiff(firstStatus, secondStatus, firstPart, secondPart)
+}
+
+// requestData returns the data of a request number from the history of req-condition
+func requestData(data output.InternalEvent, number int) output.InternalEvent {
+	suffix := "_" + strconv.Itoa(number)
+	result := make(output.InternalEvent)
+	for k, v := range data {
+		if strings.HasSuffix(k, suffix) {
+			result[strings.TrimSuffix(k, suffix)] = v
+		}
+	}
+	return result
+}
+
 // Extract performs extracting operation for a extractor on model and returns true or false.
 func (r *Request) Extract(data map[string]interface{}, extractor *extractors.Extractor) map[string]struct{} {
 	item, ok := getMatchPart(extractor.Part, data)
@@ -54,6 +102,8 @@ func (r *Request) Extract(data map[string]interface{}, extractor *extractors.Ext
 		return extractor.ExtractRegex(item)
 	case extractors.KValExtractor:
 		return extractor.ExtractKval(data)
+	case extractors.DSLExtractor:
+		return extractor.ExtractDSL(data)
 	}
 	return nil
 }
@@ -118,7 +168,7 @@ func (r *Request) MakeResultEvent(wrapped *output.InternalWrappedEvent) []*outpu
 
 	// If we have multiple matchers with names, write each of them separately.
 	if len(wrapped.OperatorsResult.Matches) > 0 {
-		for k := range wrapped.OperatorsResult.Matches {
+		for _, k := range wrapped.OperatorsResult.MatcherNames() {
 			data := r.makeResultEventItem(wrapped)
 			data.MatcherName = k
 			results = append(results, data)
@@ -149,10 +199,11 @@ func (r *Request) makeResultEventItem(wrapped *output.InternalWrappedEvent) *out
 		ExtractedResults: wrapped.OperatorsResult.OutputExtracts,
 		Timestamp:        time.Now(),
 		IP:               types.ToString(wrapped.InternalEvent["ip"]),
+		ResponseHash:     output.ResponseHash(types.ToString(wrapped.InternalEvent["response"])),
 	}
 	if r.options.Options.JSONRequests {
 		data.Request = types.ToString(wrapped.InternalEvent["request"])
-		data.Response = types.ToString(wrapped.InternalEvent["response"])
+		data.Response = truncateBody(types.ToString(wrapped.InternalEvent["response"]), r.options.Options.ResponseSaveSize)
 	}
 	return data
 }
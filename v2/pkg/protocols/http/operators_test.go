@@ -111,6 +111,20 @@ func TestHTTPOperatorMatch(t *testing.T) {
 		matched := request.Match(event, matcher)
 		require.False(t, matched, "could match invalid response matcher")
 	})
+
+	t.Run("binary-raw-body", func(t *testing.T) {
+		matcher := &matchers.Matcher{
+			Part:   "body",
+			Type:   "binary",
+			Binary: []string{"e9"},
+		}
+		err := matcher.CompileMatchers()
+		require.Nil(t, err, "could not compile binary matcher")
+
+		transcoded := map[string]interface{}{"body": "caf\u00e9", "raw_body": "caf\xe9"}
+		require.True(t, request.Match(transcoded, matcher), "could not match raw body bytes")
+		require.False(t, request.Match(map[string]interface{}{"body": "caf\u00e9"}, matcher), "could match transcoded body bytes")
+	})
 }
 
 func TestHTTPOperatorExtract(t *testing.T) {
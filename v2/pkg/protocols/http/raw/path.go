@@ -0,0 +1,93 @@
+package raw
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Path modes for combining the target path with the template request path
+const (
+	// PathModePrepend prepends the target path to the request path
+	PathModePrepend = "prepend"
+	// PathModeReplace replaces the target path with the request path
+	PathModeReplace = "replace"
+	// PathModeMerge prepends the target path to the request path,
+	// removing the segments the target path ends and the request path starts with.
+	PathModeMerge = "merge"
+)
+
+// ValidatePathMode returns an error if the path mode is not supported
+func ValidatePathMode(mode string) error {
+	switch mode {
+	case "", PathModePrepend, PathModeReplace, PathModeMerge:
+		return nil
+	}
+	return fmt.Errorf("invalid path mode %s (it should be prepend, replace or merge)", mode)
+}
+
+// JoinPath combines the target path with the request path using a path mode
+func JoinPath(basePath, requestPath, mode string) string {
+	switch mode {
+	case PathModeReplace:
+		if requestPath != "" && !strings.HasPrefix(requestPath, "/") {
+			requestPath = "/" + requestPath
+		}
+		return requestPath
+	case PathModeMerge:
+		if strings.HasPrefix(requestPath, "/") {
+			return mergePaths(basePath, requestPath)
+		}
+	}
+
+	if strings.HasSuffix(basePath, "/") && strings.HasPrefix(requestPath, "/") {
+		basePath = strings.TrimSuffix(basePath, "/")
+	}
+	joined := basePath + requestPath
+	if strings.HasSuffix(joined, "//") {
+		joined = strings.TrimSuffix(joined, "/")
+	}
+	return joined
+}
+
+// mergePaths joins the paths removing the longest run of segments
+// that the base path ends with and the request path starts with.
+func mergePaths(basePath, requestPath string) string {
+	var query string
+	if index := strings.IndexAny(requestPath, "?#"); index != -1 {
+		requestPath, query = requestPath[:index], requestPath[index:]
+	}
+	baseSegments := splitSegments(basePath)
+	requestSegments := splitSegments(requestPath)
+
+	overlap := 0
+	for size := min(len(baseSegments), len(requestSegments)); size > 0; size-- {
+		if strings.Join(baseSegments[len(baseSegments)-size:], "/") == strings.Join(requestSegments[:size], "/") {
+			overlap = size
+			break
+		}
+	}
+
+	merged := "/" + strings.Join(append(baseSegments, requestSegments[overlap:]...), "/")
+	if strings.HasSuffix(requestPath, "/") && merged != "/" {
+		merged += "/"
+	}
+	return merged + query
+}
+
+// splitSegments splits a path into its non-empty segments
+func splitSegments(path string) []string {
+	var segments []string
+	for _, segment := range strings.Split(path, "/") {
+		if segment != "" {
+			segments = append(segments, segment)
+		}
+	}
+	return segments
+}
+
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -24,6 +24,12 @@ type Request struct {
 
 // Parse parses the raw request as supplied by the user
 func Parse(request, baseURL string, unsafe bool) (*Request, error) {
+	return ParseWithPathMode(request, baseURL, PathModePrepend, unsafe)
+}
+
+// ParseWithPathMode parses the raw request as supplied by the user combining
+// the path of the base url and the request using the path mode.
+func ParseWithPathMode(request, baseURL, pathMode string, unsafe bool) (*Request, error) {
 	rawRequest := &Request{
 		Headers: make(map[string]string),
 	}
@@ -104,13 +110,7 @@ func Parse(request, baseURL string, unsafe bool) (*Request, error) {
 		return nil, fmt.Errorf("could not parse request URL: %s", err)
 	}
 	hostURL := parsedURL.Host
-	if strings.HasSuffix(parsedURL.Path, "/") && strings.HasPrefix(rawRequest.Path, "/") {
-		parsedURL.Path = strings.TrimSuffix(parsedURL.Path, "/")
-	}
-	rawRequest.Path = fmt.Sprintf("%s%s", parsedURL.Path, rawRequest.Path)
-	if strings.HasSuffix(rawRequest.Path, "//") {
-		rawRequest.Path = strings.TrimSuffix(rawRequest.Path, "/")
-	}
+	rawRequest.Path = JoinPath(parsedURL.Path, rawRequest.Path, pathMode)
 	rawRequest.FullURL = fmt.Sprintf("%s://%s%s", parsedURL.Scheme, strings.TrimSpace(hostURL), rawRequest.Path)
 
 	// If raw request doesn't have a Host header
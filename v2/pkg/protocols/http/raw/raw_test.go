@@ -64,3 +64,30 @@ username=admin&password=login`, "https://test.com", false)
 	require.Equal(t, "POST", request.Method, "Could not parse POST method request correctly")
 	require.Equal(t, "username=admin&password=login", request.Data, "Could not parse request data correctly")
 }
+
+func TestParseRawRequestPathModes(t *testing.T) {
+	items := []struct {
+		mode     string
+		path     string
+		expected string
+	}{
+		{mode: PathModePrepend, path: "/api/users", expected: "https://example.com/app/api/users"},
+		{mode: PathModeReplace, path: "/api/users", expected: "https://example.com/api/users"},
+		{mode: PathModeMerge, path: "/api/users", expected: "https://example.com/app/api/users"},
+		{mode: PathModeMerge, path: "/app/api/users?id=1", expected: "https://example.com/app/api/users?id=1"},
+		{mode: PathModeMerge, path: "?id=1", expected: "https://example.com/app/?id=1"},
+	}
+	for _, item := range items {
+		request, err := ParseWithPathMode("GET "+item.path+" HTTP/1.1\nHost: {{Hostname}}", "https://example.com/app/", item.mode, false)
+		require.Nil(t, err, "could not parse GET request")
+		require.Equal(t, item.expected, request.FullURL, "could not get correct url for %s mode with %s", item.mode, item.path)
+	}
+}
+
+func TestJoinPath(t *testing.T) {
+	require.Equal(t, "/app/v1/users/", JoinPath("/app/v1", "/v1/users/", PathModeMerge), "could not merge overlapping paths")
+	require.Equal(t, "/a/b/c", JoinPath("/a/b", "/a/b/c", PathModeMerge), "could not merge overlapping paths")
+	require.Equal(t, "/admin", JoinPath("/app/", "admin", PathModeReplace), "could not replace path")
+	require.Equal(t, "/app/admin", JoinPath("/app/", "/admin", PathModePrepend), "could not prepend path")
+	require.NotNil(t, ValidatePathMode("append"), "could validate invalid path mode")
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"io"
 	"io/ioutil"
+	"net"
 	"net/http"
 	"net/http/httputil"
 	"net/url"
@@ -18,9 +19,11 @@ import (
 	"github.com/projectdiscovery/nuclei/v2/pkg/protocols"
 	"github.com/projectdiscovery/nuclei/v2/pkg/protocols/common/generators"
 	"github.com/projectdiscovery/nuclei/v2/pkg/protocols/common/interactsh"
+	"github.com/projectdiscovery/nuclei/v2/pkg/protocols/common/protocolstate"
 	"github.com/projectdiscovery/nuclei/v2/pkg/protocols/common/tostring"
 	"github.com/projectdiscovery/nuclei/v2/pkg/protocols/http/httpclientpool"
 	"github.com/projectdiscovery/rawhttp"
+	"github.com/projectdiscovery/retryablehttp-go"
 	"github.com/remeh/sizedwaitgroup"
 	"go.uber.org/multierr"
 )
@@ -39,6 +42,7 @@ func (r *Request) executeRaceRequest(reqURL string, previous output.InternalEven
 		return err
 	}
 	r.setCustomHeaders(requestForDump)
+	r.setAuthHeaders(requestForDump)
 	dumpedRequest, err := dump(requestForDump, reqURL)
 	if err != nil {
 		return err
@@ -178,6 +182,16 @@ func (r *Request) executeTurboHTTP(reqURL string, dynamicValues, previous output
 
 // ExecuteWithResults executes the final request on a URL
 func (r *Request) ExecuteWithResults(reqURL string, dynamicValues, previous output.InternalEvent, callback protocols.OutputEventCallback) error {
+	// verify if fuzzing of the input request was requested
+	if len(r.Fuzzing) > 0 {
+		return r.executeFuzzingRules(reqURL, dynamicValues, previous, callback)
+	}
+
+	// verify if tampering of the request was requested
+	if len(r.Tamper) > 0 {
+		return r.executeTamperRequests(reqURL, dynamicValues, previous, callback)
+	}
+
 	// verify if pipeline was requested
 	if r.Pipeline {
 		return r.executeTurboHTTP(reqURL, dynamicValues, previous, callback)
@@ -214,6 +228,9 @@ func (r *Request) ExecuteWithResults(reqURL string, dynamicValues, previous outp
 		}
 
 		var gotOutput bool
+		if r.options.WafDetector != nil {
+			time.Sleep(r.options.WafDetector.Delay(reqURL))
+		}
 		r.options.RateLimiter.Take()
 		err = r.executeRequest(reqURL, request, previous, func(event *output.InternalWrappedEvent) {
 			// Add the extracts to the dynamic values if any.
@@ -228,6 +245,8 @@ func (r *Request) ExecuteWithResults(reqURL string, dynamicValues, previous outp
 					Operators:      r.CompiledOperators,
 					MatchFunc:      r.Match,
 					ExtractFunc:    r.Extract,
+					Workflow:       r.options.Workflow,
+					Input:          reqURL,
 				})
 			} else {
 				callback(event)
@@ -252,6 +271,10 @@ const drainReqSize = int64(8 * 1024)
 // executeRequest executes the actual generated request and returns error if occurred
 func (r *Request) executeRequest(reqURL string, request *generatedRequest, previous output.InternalEvent, callback protocols.OutputEventCallback, requestCount int) error {
 	r.setCustomHeaders(request)
+	r.setAuthHeaders(request)
+	if err := r.setCSRFTokens(request); err != nil {
+		return err
+	}
 
 	var (
 		resp          *http.Response
@@ -308,9 +331,18 @@ func (r *Request) executeRequest(reqURL string, request *generatedRequest, previ
 			}
 		}
 		if resp == nil {
-			resp, err = r.httpClient.Do(request.request)
+			var client *retryablehttp.Client
+			if client, err = r.client(reqURL, request); err == nil {
+				resp, err = client.Do(request.request)
+			}
 		}
 	}
+	// The requests sent by the http clients are audited by their transport
+	rawSent := request.original.Pipeline || (request.original.Unsafe && request.rawRequest != nil)
+	if err != nil && r.retryFallback(request, err, 0, nil) {
+		gologger.Verbose().Msgf("[%s] Protocol error for %s, retrying request with %s", r.options.TemplateID, formedURL, request.request.URL.Scheme)
+		return r.executeRequest(reqURL, request, previous, callback, requestCount)
+	}
 	if resp == nil {
 		err = errors.New("no response got for request")
 	}
@@ -321,6 +353,9 @@ func (r *Request) executeRequest(reqURL string, request *generatedRequest, previ
 			resp.Body.Close()
 		}
 		r.options.Output.Request(r.options.TemplateID, formedURL, "http", err)
+		if rawSent {
+			r.options.AuditLog.Log(r.options.TemplateID, "http", formedURL, len(dumpedRequest), 0, err)
+		}
 		r.options.Progress.IncrementErrorsBy(1)
 		return err
 	}
@@ -339,13 +374,15 @@ func (r *Request) executeRequest(reqURL string, request *generatedRequest, previ
 		return errors.Wrap(err, "could not dump http response")
 	}
 
-	var bodyReader io.Reader
+	// The template max-size takes precedence over the global read size limit
+	maxSize := int64(r.options.Options.ResponseReadSize)
 	if r.MaxSize != 0 {
-		bodyReader = io.LimitReader(resp.Body, int64(r.MaxSize))
-	} else {
-		bodyReader = resp.Body
+		maxSize = int64(r.MaxSize)
+	}
+	data, err := ioutil.ReadAll(limitReader(resp.Body, maxSize))
+	if rawSent {
+		r.options.AuditLog.Log(r.options.TemplateID, "http", formedURL, len(dumpedRequest), len(dumpedResponseHeaders)+len(data), nil)
 	}
-	data, err := ioutil.ReadAll(bodyReader)
 	if err != nil {
 		if !strings.Contains(err.Error(), "unexpected EOF") { // ignore EOF error
 			return errors.Wrap(err, "could not read http body")
@@ -353,7 +390,7 @@ func (r *Request) executeRequest(reqURL string, request *generatedRequest, previ
 	}
 	resp.Body.Close()
 
-	redirectedResponse, err := dumpResponseWithRedirectChain(resp, data)
+	redirectedResponse, err := dumpResponseWithRedirectChain(resp, data, maxSize)
 	if err != nil {
 		return errors.Wrap(err, "could not read http response with redirect chain")
 	}
@@ -362,7 +399,34 @@ func (r *Request) executeRequest(reqURL string, request *generatedRequest, previ
 	// encoding has been specified by the user in the request so in case we have to
 	// manually do it.
 	dataOrig := data
-	data, _ = handleDecompression(resp, data)
+	data, _ = handleDecompression(resp, data, maxSize)
+	rawData := data
+	data = handleCharset(resp, data)
+	transcoded := !bytes.Equal(rawData, data)
+
+	// Requests failing because of an expired session are retried once after logging in again
+	if r.retryAuth(request, formedURL, resp.StatusCode, data) {
+		gologger.Verbose().Msgf("[%s] Session expired for %s, retrying request after login", r.options.TemplateID, formedURL)
+		return r.executeRequest(reqURL, request, previous, callback, requestCount)
+	}
+	if r.retryFallback(request, nil, resp.StatusCode, data) {
+		gologger.Verbose().Msgf("[%s] Plain http request sent to https port of %s, retrying request with https", r.options.TemplateID, formedURL)
+		return r.executeRequest(reqURL, request, previous, callback, requestCount)
+	}
+	if r.GRPC != nil {
+		if decoded, decodeErr := r.GRPC.DecodeResponse(data); decodeErr == nil {
+			data = []byte(decoded)
+		} else {
+			gologger.Verbose().Msgf("[%s] Could not decode grpc response from %s: %s\n", r.options.TemplateID, formedURL, decodeErr)
+		}
+	}
+
+	if r.options.CSRF != nil {
+		r.options.CSRF.Record(formedURL, resp.Header, data)
+	}
+	if r.options.WafDetector != nil && r.options.WafDetector.Record(reqURL, resp.StatusCode, resp.Header, data) {
+		gologger.Verbose().Msgf("[%s] Detected WAF/rate-limit response from %s", r.options.TemplateID, formedURL)
+	}
 
 	// Dump response - step 2 - replace gzip body with deflated one or with itself (NOP operation)
 	dumpedResponseBuilder := &bytes.Buffer{}
@@ -395,11 +459,26 @@ func (r *Request) executeRequest(reqURL string, request *generatedRequest, previ
 	finalEvent := make(output.InternalEvent)
 
 	outputEvent := r.responseToDSLMap(resp, reqURL, matchedURL, tostring.UnsafeToString(dumpedRequest), tostring.UnsafeToString(dumpedResponse), tostring.UnsafeToString(data), headersToString(resp.Header), duration, request.meta)
-	if i := strings.LastIndex(hostname, ":"); i != -1 {
-		hostname = hostname[:i]
+	if host, _, splitErr := net.SplitHostPort(hostname); splitErr == nil {
+		hostname = host
+	}
+	dialedIP := httpclientpool.Dialer.GetDialedIP(hostname)
+	if dialedIP == "" && protocolstate.Resolver != nil {
+		dialedIP = protocolstate.ResolveIP(hostname)
+	}
+	outputEvent["ip"] = dialedIP
+	// Binary matchers match the bytes of the body before it was transcoded
+	if transcoded {
+		outputEvent["raw_body"] = tostring.UnsafeToString(rawData)
 	}
-	outputEvent["ip"] = httpclientpool.Dialer.GetDialedIP(hostname)
 	outputEvent["redirect-chain"] = tostring.UnsafeToString(redirectedResponse)
+	if r.GRPC != nil {
+		outputEvent["grpc_status"] = grpcTrailer(resp, "grpc-status")
+		outputEvent["grpc_message"] = grpcTrailer(resp, "grpc-message")
+	}
+	for k, v := range request.randomValues {
+		outputEvent[k] = v
+	}
 	for k, v := range previous {
 		finalEvent[k] = v
 	}
@@ -415,7 +494,7 @@ func (r *Request) executeRequest(reqURL string, request *generatedRequest, previ
 		}
 	}
 
-	event := &output.InternalWrappedEvent{InternalEvent: outputEvent}
+	event := &output.InternalWrappedEvent{InternalEvent: outputEvent, Started: timeStart}
 	if !interactsh.HasMatchers(r.CompiledOperators) {
 		if r.CompiledOperators != nil {
 			var ok bool
@@ -427,6 +506,7 @@ func (r *Request) executeRequest(reqURL string, request *generatedRequest, previ
 			event.InternalEvent = outputEvent
 		}
 	}
+	r.saveScreenshot(reqURL, event)
 	callback(event)
 	return nil
 }
@@ -445,3 +525,12 @@ func (r *Request) setCustomHeaders(req *generatedRequest) {
 		}
 	}
 }
+
+// grpcTrailer returns a grpc trailer of the response. Trailers-only
+// responses have them in the headers.
+func grpcTrailer(resp *http.Response, name string) string {
+	if value := resp.Trailer.Get(name); value != "" {
+		return value
+	}
+	return resp.Header.Get(name)
+}
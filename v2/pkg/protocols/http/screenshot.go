@@ -0,0 +1,42 @@
+package http
+
+import (
+	"strings"
+	"time"
+
+	"github.com/projectdiscovery/gologger"
+	"github.com/projectdiscovery/nuclei/v2/pkg/output"
+	"github.com/projectdiscovery/nuclei/v2/pkg/types"
+)
+
+// severityRanks are the ranks of the template severities in increasing order
+var severityRanks = map[string]int{"info": 0, "low": 1, "medium": 2, "high": 3, "critical": 4}
+
+// saveScreenshot stores a screenshot of the matched url of the event using
+// the headless browser if screenshots are enabled for the template severity.
+//
+// The screenshot is taken in the background, the results get the path of
+// the file it is written to.
+func (r *Request) saveScreenshot(input string, event *output.InternalWrappedEvent) {
+	if !r.options.Options.Screenshot || r.options.Browser == nil || r.options.ResponseStore == nil || event.OperatorsResult == nil || len(event.Results) == 0 {
+		return
+	}
+	severity := strings.ToLower(types.ToString(r.options.TemplateInfo["severity"]))
+	if severityRanks[severity] < severityRanks[strings.ToLower(r.options.Options.ScreenshotSeverity)] {
+		return
+	}
+
+	matched := types.ToString(event.InternalEvent["matched"])
+	file := r.options.ResponseStore.ScreenshotFile(input, r.options.TemplateID, matched)
+	r.options.Browser.ScreenshotAsync(matched, time.Duration(r.options.Options.PageTimeout)*time.Second, func(data []byte, err error) {
+		if err == nil {
+			err = r.options.ResponseStore.SaveScreenshot(file, data)
+		}
+		if err != nil {
+			gologger.Warning().Msgf("[%s] Could not take screenshot for %s: %s\n", r.options.TemplateID, matched, err)
+		}
+	})
+	for _, result := range event.Results {
+		result.Screenshot = file
+	}
+}
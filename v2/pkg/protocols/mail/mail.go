@@ -0,0 +1,135 @@
+package mail
+
+import (
+	"net"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/projectdiscovery/fastdialer/fastdialer"
+	"github.com/projectdiscovery/nuclei/v2/pkg/operators"
+	"github.com/projectdiscovery/nuclei/v2/pkg/protocols"
+	"github.com/projectdiscovery/nuclei/v2/pkg/protocols/common/generators"
+	"github.com/projectdiscovery/nuclei/v2/pkg/protocols/common/retry"
+	"github.com/projectdiscovery/nuclei/v2/pkg/protocols/network/networkclientpool"
+)
+
+// Supported mail protocols
+const (
+	ProtocolSMTP = "smtp"
+	ProtocolIMAP = "imap"
+	ProtocolPOP3 = "pop3"
+)
+
+// defaultPorts are the default ports of the protocols for plain and tls connections
+var defaultPorts = map[string][2]string{
+	ProtocolSMTP: {"25", "465"},
+	ProtocolIMAP: {"143", "993"},
+	ProtocolPOP3: {"110", "995"},
+}
+
+// Request contains a mail protocol request to be made from a template
+type Request struct {
+	ID string `yaml:"id"`
+
+	// Protocol is the mail protocol of the server - smtp, imap or pop3
+	Protocol string `yaml:"protocol"`
+	// Address is the address of the server. The default port of the protocol
+	// is used if no port is provided, and tls:// uses implicit tls.
+	Address   []string `yaml:"host"`
+	addresses []address
+	// StartTLS upgrades the connection with STARTTLS (STLS for pop3) before
+	// sending the commands if the server supports it.
+	StartTLS bool `yaml:"starttls"`
+	// Commands are the commands sent after the greeting and capabilities
+	// probing. The imap commands are tagged automatically.
+	Commands []string `yaml:"commands"`
+	// Relay tests if the smtp server accepts mail from the sender to the recipient
+	Relay *Relay `yaml:"relay"`
+	// Retries is the number of retries for the connection overriding the global value
+	Retries int `yaml:"retries"`
+
+	// Operators for the current request go here.
+	operators.Operators `yaml:",inline,omitempty"`
+	CompiledOperators   *operators.Operators
+
+	dialer      *fastdialer.Dialer
+	retryPolicy *retry.Policy
+	randomVars  []string
+	options     *protocols.ExecuterOptions
+}
+
+// Relay is an open relay test of a smtp server
+type Relay struct {
+	// From is the sender address of the mail
+	From string `yaml:"from"`
+	// To is the recipient address of the mail on a domain not handled by the server
+	To string `yaml:"to"`
+}
+
+type address struct {
+	host string
+	port string
+	tls  bool
+}
+
+// GetID returns the unique ID of the request if any.
+func (r *Request) GetID() string {
+	return r.ID
+}
+
+// Compile compiles the protocol request for further execution.
+func (r *Request) Compile(options *protocols.ExecuterOptions) error {
+	r.Protocol = strings.ToLower(r.Protocol)
+	if _, ok := defaultPorts[r.Protocol]; !ok {
+		return errors.Errorf("invalid mail protocol %s (it should be smtp, imap or pop3)", r.Protocol)
+	}
+	if r.Relay != nil && (r.Protocol != ProtocolSMTP || r.Relay.From == "" || r.Relay.To == "") {
+		return errors.New("relay test requires the smtp protocol with from and to addresses")
+	}
+
+	r.addresses = r.addresses[:0]
+	for _, value := range r.Address {
+		addr := address{}
+		if strings.HasPrefix(value, "tls://") {
+			addr.tls = true
+			value = strings.TrimPrefix(value, "tls://")
+		}
+		addr.host = value
+		if strings.Contains(value, ":") {
+			host, port, err := net.SplitHostPort(value)
+			if err != nil {
+				return errors.Wrap(err, "could not parse address")
+			}
+			addr.host, addr.port = host, port
+		}
+		r.addresses = append(r.addresses, addr)
+	}
+
+	client, err := networkclientpool.Get(options.Options, &networkclientpool.Configuration{})
+	if err != nil {
+		return errors.Wrap(err, "could not get network client")
+	}
+	r.dialer = client
+	r.retryPolicy = retry.NewPolicy(options.Options, r.Retries)
+
+	values := append([]string{}, r.Commands...)
+	if r.Relay != nil {
+		values = append(values, r.Relay.From, r.Relay.To)
+	}
+	r.randomVars = generators.RandomVariables(values...)
+
+	if len(r.Matchers) > 0 || len(r.Extractors) > 0 {
+		compiled := &r.Operators
+		if err := compiled.Compile(); err != nil {
+			return errors.Wrap(err, "could not compile operators")
+		}
+		r.CompiledOperators = compiled
+	}
+	r.options = options
+	return nil
+}
+
+// Requests returns the total number of requests the YAML rule will perform
+func (r *Request) Requests() int {
+	return len(r.Address)
+}
@@ -0,0 +1,38 @@
+package mail
+
+import (
+	"testing"
+
+	"github.com/projectdiscovery/nuclei/v2/internal/testutils"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMailCompileMake(t *testing.T) {
+	options := testutils.DefaultOptions
+
+	testutils.Init(options)
+	templateID := "testing-mail"
+	request := &Request{
+		ID:       templateID,
+		Protocol: "SMTP",
+		Address:  []string{"{{Hostname}}", "{{Hostname}}:587", "tls://{{Hostname}}"},
+		Relay:    &Relay{From: "test@example.com", To: "test@{{randstr}}.com"},
+	}
+	executerOpts := testutils.NewMockExecuterOptions(options, &testutils.TemplateInfo{
+		ID:   templateID,
+		Info: map[string]interface{}{"severity": "low", "name": "test"},
+	})
+	err := request.Compile(executerOpts)
+	require.Nil(t, err, "could not compile mail request")
+
+	require.Equal(t, ProtocolSMTP, request.Protocol, "could not normalize protocol")
+	require.Equal(t, 3, len(request.addresses), "could not get correct number of input address")
+	require.Equal(t, "587", request.addresses[1].port, "could not get correct port for host")
+	require.True(t, request.addresses[2].tls, "could not get tls for host")
+	require.Equal(t, "", request.addresses[2].port, "could not get default port for tls host")
+	require.Equal(t, []string{"randstr"}, request.randomVars, "could not get random variables")
+
+	invalid := &Request{ID: templateID, Protocol: "imap", Address: []string{"{{Hostname}}"}, Relay: &Relay{From: "a", To: "b"}}
+	err = invalid.Compile(executerOpts)
+	require.NotNil(t, err, "could not detect relay with invalid protocol")
+}
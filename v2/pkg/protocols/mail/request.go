@@ -0,0 +1,115 @@
+package mail
+
+import (
+	"net"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/projectdiscovery/gologger"
+	"github.com/projectdiscovery/nuclei/v2/pkg/output"
+	"github.com/projectdiscovery/nuclei/v2/pkg/protocols"
+	"github.com/projectdiscovery/nuclei/v2/pkg/protocols/common/auditlog"
+	"github.com/projectdiscovery/nuclei/v2/pkg/protocols/common/generators"
+	"github.com/projectdiscovery/nuclei/v2/pkg/protocols/common/protocolstate"
+	"github.com/projectdiscovery/nuclei/v2/pkg/protocols/common/replacer"
+)
+
+var _ protocols.Request = &Request{}
+
+// ExecuteWithResults executes the protocol requests and returns results instead of writing them.
+func (r *Request) ExecuteWithResults(input string, metadata, previous output.InternalEvent, callback protocols.OutputEventCallback) error {
+	hostname, inputPort := protocolstate.HostPort(input)
+
+	for _, addr := range r.addresses {
+		values := generators.MergeMaps(metadata, map[string]interface{}{"Hostname": hostname})
+		host := replacer.Replace(addr.host, values)
+		port := addr.port
+		if port == "" {
+			port = inputPort
+		}
+		if port == "" {
+			port = defaultPorts[r.Protocol][0]
+			if addr.tls {
+				port = defaultPorts[r.Protocol][1]
+			}
+		}
+		actualAddress := net.JoinHostPort(strings.Trim(host, "[]"), port)
+
+		if err := r.executeAddress(actualAddress, host, input, addr.tls, metadata, previous, callback); err != nil {
+			gologger.Verbose().Label("ERR").Msgf("Could not make %s request for %s: %s\n", r.Protocol, actualAddress, err)
+		}
+	}
+	return nil
+}
+
+// executeAddress executes the mail session for an address
+func (r *Request) executeAddress(actualAddress, host, input string, shouldUseTLS bool, metadata, previous output.InternalEvent, callback protocols.OutputEventCallback) error {
+	started := time.Now()
+	var conn net.Conn
+	err := r.retryPolicy.Do(func() error {
+		var dialErr error
+		conn, dialErr = protocolstate.Dial(r.options.RequestContext(), r.dialer, actualAddress, shouldUseTLS)
+		return dialErr
+	})
+	if err != nil {
+		r.options.Output.Request(r.options.TemplateID, actualAddress, r.Protocol, err)
+		r.options.AuditLog.Log(r.options.TemplateID, r.Protocol, actualAddress, 0, 0, err)
+		r.options.Progress.IncrementFailedRequestsBy(1)
+		return errors.Wrap(err, "could not connect to server")
+	}
+	defer conn.Close()
+	_ = conn.SetDeadline(time.Now().Add(time.Duration(r.options.Options.Timeout) * time.Second))
+
+	randomValues := generators.RandomValues(r.randomVars)
+	values := generators.MergeMaps(metadata, randomValues)
+	values["Hostname"] = host
+	commands := make([]string, 0, len(r.Commands))
+	for _, command := range r.Commands {
+		commands = append(commands, replacer.Replace(command, values))
+	}
+	var relay *Relay
+	if r.Relay != nil {
+		relay = &Relay{From: replacer.Replace(r.Relay.From, values), To: replacer.Replace(r.Relay.To, values)}
+	}
+
+	counter := &auditlog.Counter{}
+	s := newSession(counter.Wrap(conn), host)
+	result, err := s.run(r.Protocol, commands, relay, r.StartTLS)
+	r.options.Output.Request(r.options.TemplateID, actualAddress, r.Protocol, err)
+	r.options.AuditLog.Log(r.options.TemplateID, r.Protocol, actualAddress, counter.Sent(), counter.Received(), err)
+	r.options.Progress.IncrementRequests()
+	if err != nil {
+		r.options.Progress.IncrementFailedRequestsBy(1)
+		return errors.Wrap(err, "could not run mail session")
+	}
+	gologger.Verbose().Msgf("Sent %s request to %s", strings.ToUpper(r.Protocol), actualAddress)
+
+	if r.options.Options.Debug || r.options.Options.DebugRequests || r.options.Options.DebugResponse {
+		gologger.Info().Str("address", actualAddress).Msgf("[%s] Dumped %s session for %s", r.options.TemplateID, strings.ToUpper(r.Protocol), actualAddress)
+		gologger.Print().Msgf("%s", s.transcript.String())
+	}
+
+	outputEvent := r.responseToDSLMap(s.requests.String(), result, s.transcript.String(), input, actualAddress)
+	outputEvent["ip"] = r.dialer.GetDialedIP(host)
+	if outputEvent["ip"] == "" && protocolstate.Resolver != nil {
+		outputEvent["ip"] = protocolstate.ResolveIP(host)
+	}
+	for k, v := range previous {
+		outputEvent[k] = v
+	}
+	for k, v := range randomValues {
+		outputEvent[k] = v
+	}
+
+	event := &output.InternalWrappedEvent{InternalEvent: outputEvent, Started: started}
+	if r.CompiledOperators != nil {
+		result, ok := r.CompiledOperators.Execute(outputEvent, r.Match, r.Extract)
+		if ok && result != nil {
+			event.OperatorsResult = result
+			event.Results = r.MakeResultEvent(event)
+		}
+	}
+	callback(event)
+	return nil
+}
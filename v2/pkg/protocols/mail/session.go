@@ -0,0 +1,371 @@
+package mail
+
+import (
+	"bufio"
+	"crypto/tls"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// maxResponseLines is the maximum number of lines of a single response
+const maxResponseLines = 1000
+
+// heloName is the client name sent in the smtp greeting
+const heloName = "nuclei.local"
+
+// result contains the responses of a mail session
+type result struct {
+	banner       string
+	capabilities string
+	auth         []string
+	starttls     bool
+	tlsUpgraded  bool
+	relay        bool
+	relayData    string
+	responses    []string
+}
+
+// session is a line based conversation with a mail server
+type session struct {
+	conn       net.Conn
+	reader     *bufio.Reader
+	serverName string
+	tag        int
+	transcript *strings.Builder
+	requests   *strings.Builder
+}
+
+func newSession(conn net.Conn, serverName string) *session {
+	return &session{
+		conn:       conn,
+		reader:     bufio.NewReader(conn),
+		serverName: serverName,
+		transcript: &strings.Builder{},
+		requests:   &strings.Builder{},
+	}
+}
+
+// send writes a command line to the server
+func (s *session) send(command string) error {
+	line := command + "\r\n"
+	s.transcript.WriteString(line)
+	s.requests.WriteString(line)
+	_, err := s.conn.Write([]byte(line))
+	return err
+}
+
+// readLine reads a single response line from the server
+func (s *session) readLine() (string, error) {
+	line, err := s.reader.ReadString('\n')
+	s.transcript.WriteString(line)
+	if err != nil && line == "" {
+		return "", err
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+// readUntil reads response lines until the last one for which done returns true
+func (s *session) readUntil(done func(line string) bool) (string, error) {
+	var lines []string
+	for i := 0; i < maxResponseLines; i++ {
+		line, err := s.readLine()
+		if err != nil {
+			return strings.Join(lines, "\n"), err
+		}
+		lines = append(lines, line)
+		if done(line) {
+			break
+		}
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+// upgradeTLS upgrades the connection of the session to tls
+func (s *session) upgradeTLS() error {
+	conn := tls.Client(s.conn, &tls.Config{InsecureSkipVerify: true, ServerName: s.serverName})
+	if err := conn.Handshake(); err != nil {
+		return errors.Wrap(err, "could not upgrade connection to tls")
+	}
+	s.conn = conn
+	s.reader = bufio.NewReader(conn)
+	return nil
+}
+
+// run runs the conversation of the request protocol
+func (s *session) run(protocol string, commands []string, relay *Relay, startTLS bool) (*result, error) {
+	switch protocol {
+	case ProtocolSMTP:
+		return s.runSMTP(commands, relay, startTLS)
+	case ProtocolIMAP:
+		return s.runIMAP(commands, startTLS)
+	}
+	return s.runPOP3(commands, startTLS)
+}
+
+// smtpDone returns true for the last line of a smtp reply
+func smtpDone(line string) bool {
+	return len(line) < 4 || line[3] != '-'
+}
+
+// smtpCode returns the code of a smtp reply
+func smtpCode(reply string) int {
+	lines := strings.Split(reply, "\n")
+	last := lines[len(lines)-1]
+	if len(last) < 3 {
+		return 0
+	}
+	code, _ := strconv.Atoi(last[:3])
+	return code
+}
+
+func (s *session) smtpCommand(command string) (string, error) {
+	if err := s.send(command); err != nil {
+		return "", err
+	}
+	return s.readUntil(smtpDone)
+}
+
+func (s *session) runSMTP(commands []string, relay *Relay, startTLS bool) (*result, error) {
+	result := &result{}
+	var err error
+	if result.banner, err = s.readUntil(smtpDone); err != nil {
+		return nil, errors.Wrap(err, "could not read banner")
+	}
+
+	ehlo := func() error {
+		reply, err := s.smtpCommand("EHLO " + heloName)
+		if err != nil {
+			return err
+		}
+		if smtpCode(reply) != 250 {
+			reply, err = s.smtpCommand("HELO " + heloName)
+			if err != nil {
+				return err
+			}
+		}
+		result.capabilities = reply
+		result.auth = nil
+		for _, line := range strings.Split(reply, "\n") {
+			if len(line) < 4 {
+				continue
+			}
+			extension := strings.ToUpper(strings.TrimSpace(line[4:]))
+			switch {
+			case extension == "STARTTLS":
+				result.starttls = true
+			case strings.HasPrefix(extension, "AUTH ") || strings.HasPrefix(extension, "AUTH="):
+				result.auth = append(result.auth, strings.Fields(extension[5:])...)
+			}
+		}
+		return nil
+	}
+	if err := ehlo(); err != nil {
+		return nil, errors.Wrap(err, "could not send ehlo")
+	}
+
+	if startTLS && result.starttls {
+		reply, err := s.smtpCommand("STARTTLS")
+		if err != nil {
+			return nil, err
+		}
+		if smtpCode(reply) == 220 {
+			if err := s.upgradeTLS(); err != nil {
+				return nil, err
+			}
+			result.tlsUpgraded = true
+			if err := ehlo(); err != nil {
+				return nil, errors.Wrap(err, "could not send ehlo")
+			}
+		}
+	}
+
+	for _, command := range commands {
+		reply, err := s.smtpCommand(command)
+		if err != nil {
+			return nil, err
+		}
+		result.responses = append(result.responses, reply)
+	}
+
+	if relay != nil {
+		from, err := s.smtpCommand("MAIL FROM:<" + relay.From + ">")
+		if err != nil {
+			return nil, err
+		}
+		result.relayData = from
+		if smtpCode(from) == 250 {
+			to, err := s.smtpCommand("RCPT TO:<" + relay.To + ">")
+			if err != nil {
+				return nil, err
+			}
+			result.relayData += "\n" + to
+			code := smtpCode(to)
+			result.relay = code == 250 || code == 251
+		}
+		// The transaction is reset without sending any mail
+		_, _ = s.smtpCommand("RSET")
+	}
+	_, _ = s.smtpCommand("QUIT")
+	return result, nil
+}
+
+// imapCommand sends a tagged imap command reading the response until the tagged line
+func (s *session) imapCommand(command string) (string, error) {
+	s.tag++
+	tag := "a" + strconv.Itoa(s.tag)
+	if err := s.send(tag + " " + command); err != nil {
+		return "", err
+	}
+	return s.readUntil(func(line string) bool {
+		return strings.HasPrefix(line, tag+" ")
+	})
+}
+
+func (s *session) runIMAP(commands []string, startTLS bool) (*result, error) {
+	result := &result{}
+	var err error
+	if result.banner, err = s.readLine(); err != nil {
+		return nil, errors.Wrap(err, "could not read banner")
+	}
+
+	capability := func() error {
+		reply, err := s.imapCommand("CAPABILITY")
+		if err != nil {
+			return err
+		}
+		result.capabilities = reply
+		result.auth = nil
+		for _, line := range strings.Split(reply, "\n") {
+			if !strings.HasPrefix(strings.ToUpper(line), "* CAPABILITY") {
+				continue
+			}
+			for _, capability := range strings.Fields(line)[2:] {
+				capability = strings.ToUpper(capability)
+				switch {
+				case capability == "STARTTLS":
+					result.starttls = true
+				case strings.HasPrefix(capability, "AUTH="):
+					result.auth = append(result.auth, strings.TrimPrefix(capability, "AUTH="))
+				}
+			}
+		}
+		return nil
+	}
+	if err := capability(); err != nil {
+		return nil, errors.Wrap(err, "could not get capabilities")
+	}
+
+	if startTLS && result.starttls {
+		reply, err := s.imapCommand("STARTTLS")
+		if err != nil {
+			return nil, err
+		}
+		if strings.Contains(strings.ToUpper(reply), " OK") {
+			if err := s.upgradeTLS(); err != nil {
+				return nil, err
+			}
+			result.tlsUpgraded = true
+			if err := capability(); err != nil {
+				return nil, errors.Wrap(err, "could not get capabilities")
+			}
+		}
+	}
+
+	for _, command := range commands {
+		reply, err := s.imapCommand(command)
+		if err != nil {
+			return nil, err
+		}
+		result.responses = append(result.responses, reply)
+	}
+	_, _ = s.imapCommand("LOGOUT")
+	return result, nil
+}
+
+// pop3MultiLine are the pop3 commands with multi-line responses
+var pop3MultiLine = map[string]struct{}{"CAPA": {}, "LIST": {}, "UIDL": {}, "RETR": {}, "TOP": {}}
+
+// pop3Command sends a pop3 command reading multi-line responses until the terminating dot
+func (s *session) pop3Command(command string) (string, error) {
+	if err := s.send(command); err != nil {
+		return "", err
+	}
+	reply, err := s.readLine()
+	if err != nil || !strings.HasPrefix(reply, "+OK") {
+		return reply, err
+	}
+
+	fields := strings.Fields(strings.ToUpper(command))
+	if len(fields) == 0 {
+		return reply, nil
+	}
+	if _, ok := pop3MultiLine[fields[0]]; !ok || (len(fields) > 1 && (fields[0] == "LIST" || fields[0] == "UIDL")) {
+		return reply, nil
+	}
+	rest, err := s.readUntil(func(line string) bool { return line == "." })
+	return reply + "\n" + rest, err
+}
+
+func (s *session) runPOP3(commands []string, startTLS bool) (*result, error) {
+	result := &result{}
+	var err error
+	if result.banner, err = s.readLine(); err != nil {
+		return nil, errors.Wrap(err, "could not read banner")
+	}
+
+	capa := func() error {
+		reply, err := s.pop3Command("CAPA")
+		if err != nil {
+			return err
+		}
+		result.capabilities = reply
+		result.auth = nil
+		for _, line := range strings.Split(reply, "\n") {
+			fields := strings.Fields(strings.ToUpper(line))
+			if len(fields) == 0 {
+				continue
+			}
+			switch fields[0] {
+			case "STLS":
+				result.starttls = true
+			case "SASL":
+				result.auth = append(result.auth, fields[1:]...)
+			case "USER":
+				result.auth = append(result.auth, "USER")
+			}
+		}
+		return nil
+	}
+	if err := capa(); err != nil {
+		return nil, errors.Wrap(err, "could not get capabilities")
+	}
+
+	if startTLS && result.starttls {
+		reply, err := s.pop3Command("STLS")
+		if err != nil {
+			return nil, err
+		}
+		if strings.HasPrefix(reply, "+OK") {
+			if err := s.upgradeTLS(); err != nil {
+				return nil, err
+			}
+			result.tlsUpgraded = true
+			if err := capa(); err != nil {
+				return nil, errors.Wrap(err, "could not get capabilities")
+			}
+		}
+	}
+
+	for _, command := range commands {
+		reply, err := s.pop3Command(command)
+		if err != nil {
+			return nil, err
+		}
+		result.responses = append(result.responses, reply)
+	}
+	_, _ = s.pop3Command("QUIT")
+	return result, nil
+}
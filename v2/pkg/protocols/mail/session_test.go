@@ -0,0 +1,88 @@
+package mail
+
+import (
+	"bufio"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// serve runs a fake line based server answering commands from the replies
+func serve(conn net.Conn, banner string, replies map[string]string) {
+	defer conn.Close()
+	_, _ = conn.Write([]byte(banner))
+	reader := bufio.NewReader(conn)
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return
+		}
+		command := strings.TrimRight(line, "\r\n")
+		for prefix, reply := range replies {
+			if strings.HasPrefix(command, prefix) {
+				_, _ = conn.Write([]byte(reply))
+				break
+			}
+		}
+		if strings.HasPrefix(command, "QUIT") {
+			return
+		}
+	}
+}
+
+func TestSessionSMTP(t *testing.T) {
+	client, server := net.Pipe()
+	go serve(server, "220 mail.example.com ESMTP\r\n", map[string]string{
+		"EHLO":      "250-mail.example.com\r\n250-AUTH PLAIN LOGIN\r\n250 STARTTLS\r\n",
+		"VRFY":      "252 cannot verify\r\n",
+		"MAIL FROM": "250 ok\r\n",
+		"RCPT TO":   "250 accepted\r\n",
+		"RSET":      "250 ok\r\n",
+		"QUIT":      "221 bye\r\n",
+	})
+
+	s := newSession(client, "mail.example.com")
+	result, err := s.run(ProtocolSMTP, []string{"VRFY root"}, &Relay{From: "a@example.com", To: "b@example.org"}, false)
+	require.Nil(t, err, "could not run smtp session")
+	require.Equal(t, "220 mail.example.com ESMTP", result.banner, "could not get banner")
+	require.Equal(t, []string{"PLAIN", "LOGIN"}, result.auth, "could not get auth mechanisms")
+	require.True(t, result.starttls, "could not get starttls support")
+	require.True(t, result.relay, "could not detect open relay")
+	require.Equal(t, []string{"252 cannot verify"}, result.responses, "could not get command responses")
+	require.Contains(t, s.requests.String(), "RCPT TO:<b@example.org>", "could not get relay requests")
+}
+
+func TestSessionIMAP(t *testing.T) {
+	client, server := net.Pipe()
+	go serve(server, "* OK IMAP ready\r\n", map[string]string{
+		"a1 CAPABILITY": "* CAPABILITY IMAP4rev1 STARTTLS AUTH=PLAIN\r\na1 OK done\r\n",
+		"a2 NOOP":       "a2 OK noop\r\n",
+		"a3 LOGOUT":     "* BYE\r\na3 OK bye\r\n",
+	})
+
+	s := newSession(client, "mail.example.com")
+	result, err := s.run(ProtocolIMAP, []string{"NOOP"}, nil, false)
+	require.Nil(t, err, "could not run imap session")
+	require.Equal(t, "* OK IMAP ready", result.banner, "could not get banner")
+	require.Equal(t, []string{"PLAIN"}, result.auth, "could not get auth mechanisms")
+	require.True(t, result.starttls, "could not get starttls support")
+	require.Equal(t, []string{"a2 OK noop"}, result.responses, "could not get command responses")
+}
+
+func TestSessionPOP3(t *testing.T) {
+	client, server := net.Pipe()
+	go serve(server, "+OK POP3 ready\r\n", map[string]string{
+		"CAPA": "+OK\r\nUSER\r\nSASL PLAIN\r\n.\r\n",
+		"LIST": "+OK 1 messages\r\n1 120\r\n.\r\n",
+		"QUIT": "+OK bye\r\n",
+	})
+
+	s := newSession(client, "mail.example.com")
+	result, err := s.run(ProtocolPOP3, []string{"LIST"}, nil, false)
+	require.Nil(t, err, "could not run pop3 session")
+	require.Equal(t, []string{"USER", "PLAIN"}, result.auth, "could not get auth mechanisms")
+	require.False(t, result.starttls, "could not get starttls support")
+	require.Equal(t, []string{"+OK 1 messages\n1 120\n."}, result.responses, "could not get command responses")
+}
@@ -9,6 +9,9 @@ import (
 	"github.com/projectdiscovery/nuclei/v2/pkg/operators"
 	"github.com/projectdiscovery/nuclei/v2/pkg/protocols"
 	"github.com/projectdiscovery/nuclei/v2/pkg/protocols/common/expressions"
+	"github.com/projectdiscovery/nuclei/v2/pkg/protocols/common/generators"
+	"github.com/projectdiscovery/nuclei/v2/pkg/protocols/common/retry"
+	"github.com/projectdiscovery/nuclei/v2/pkg/protocols/common/tlsfingerprint"
 	"github.com/projectdiscovery/nuclei/v2/pkg/protocols/network/networkclientpool"
 )
 
@@ -24,14 +27,19 @@ type Request struct {
 	Inputs []*Input `yaml:"inputs"`
 	// ReadSize is the size of response to read (1024 if not provided by default)
 	ReadSize int `yaml:"read-size"`
+	// Retries is the number of retries for the connection overriding the global value
+	Retries int `yaml:"retries"`
 
 	// Operators for the current request go here.
 	operators.Operators `yaml:",inline,omitempty"`
 	CompiledOperators   *operators.Operators
 
 	// cache any variables that may be needed for operation.
-	dialer  *fastdialer.Dialer
-	options *protocols.ExecuterOptions
+	dialer        *fastdialer.Dialer
+	fingerprinter *tlsfingerprint.Fingerprinter
+	retryPolicy   *retry.Policy
+	randomVars    []string
+	options       *protocols.ExecuterOptions
 }
 
 type addressKV struct {
@@ -57,6 +65,11 @@ func (r *Request) GetID() string {
 	return r.ID
 }
 
+// GetCompiledOperators returns the compiled operators of the request
+func (r *Request) GetCompiledOperators() *operators.Operators {
+	return r.CompiledOperators
+}
+
 // Compile compiles the protocol request for further execution.
 func (r *Request) Compile(options *protocols.ExecuterOptions) error {
 	var shouldUseTLS bool
@@ -94,6 +107,13 @@ func (r *Request) Compile(options *protocols.ExecuterOptions) error {
 		return errors.Wrap(err, "could not get network client")
 	}
 	r.dialer = client
+	r.retryPolicy = retry.NewPolicy(options.Options, r.Retries)
+
+	inputs := make([]string, 0, len(r.Inputs))
+	for _, input := range r.Inputs {
+		inputs = append(inputs, input.Data)
+	}
+	r.randomVars = generators.RandomVariables(inputs...)
 
 	if len(r.Matchers) > 0 || len(r.Extractors) > 0 {
 		compiled := &r.Operators
@@ -102,10 +122,30 @@ func (r *Request) Compile(options *protocols.ExecuterOptions) error {
 		}
 		r.CompiledOperators = compiled
 	}
+
+	// Fingerprint tls servers only if the operators use the fingerprints
+	// as it requires sending 10 additional handshakes to each address.
+	if shouldUseTLS && r.usesFingerprints() {
+		r.fingerprinter = networkclientpool.GetFingerprinter(options.Options)
+	}
 	r.options = options
 	return nil
 }
 
+// usesFingerprints returns true if the operators reference the tls fingerprint variables
+func (r *Request) usesFingerprints() bool {
+	var items []string
+	for _, matcher := range r.Matchers {
+		items = append(items, matcher.Part)
+		items = append(items, matcher.DSL...)
+	}
+	for _, extractor := range r.Extractors {
+		items = append(items, extractor.Part)
+		items = append(items, extractor.DSL...)
+	}
+	return tlsfingerprint.IsReferenced(items...)
+}
+
 // Requests returns the total number of requests the YAML rule will perform
 func (r *Request) Requests() int {
 	return len(r.Address)
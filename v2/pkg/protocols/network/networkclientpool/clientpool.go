@@ -1,13 +1,20 @@
 package networkclientpool
 
 import (
+	"sync"
+	"time"
+
 	"github.com/projectdiscovery/fastdialer/fastdialer"
 	"github.com/projectdiscovery/nuclei/v2/pkg/protocols/common/protocolstate"
+	"github.com/projectdiscovery/nuclei/v2/pkg/protocols/common/tlsfingerprint"
 	"github.com/projectdiscovery/nuclei/v2/pkg/types"
 )
 
 var (
 	normalClient *fastdialer.Dialer
+
+	fingerprinter     *tlsfingerprint.Fingerprinter
+	fingerprinterOnce sync.Once
 )
 
 // Init initializes the clientpool implementation
@@ -32,3 +39,16 @@ func (c *Configuration) Hash() string {
 func Get(options *types.Options, configuration *Configuration) (*fastdialer.Dialer, error) {
 	return normalClient, nil
 }
+
+// GetFingerprinter returns the shared tls fingerprinter so that each
+// address is fingerprinted only once during the scan.
+func GetFingerprinter(options *types.Options) *tlsfingerprint.Fingerprinter {
+	fingerprinterOnce.Do(func() {
+		dial := normalClient.Dial
+		if protocolstate.Resolver != nil {
+			dial = protocolstate.Resolver.Dial
+		}
+		fingerprinter = tlsfingerprint.New(dial, time.Duration(options.Timeout)*time.Second)
+	})
+	return fingerprinter
+}
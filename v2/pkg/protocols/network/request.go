@@ -1,7 +1,6 @@
 package network
 
 import (
-	"context"
 	"encoding/hex"
 	"io"
 	"net"
@@ -13,7 +12,9 @@ import (
 	"github.com/projectdiscovery/gologger"
 	"github.com/projectdiscovery/nuclei/v2/pkg/output"
 	"github.com/projectdiscovery/nuclei/v2/pkg/protocols"
+	"github.com/projectdiscovery/nuclei/v2/pkg/protocols/common/generators"
 	"github.com/projectdiscovery/nuclei/v2/pkg/protocols/common/interactsh"
+	"github.com/projectdiscovery/nuclei/v2/pkg/protocols/common/protocolstate"
 	"github.com/projectdiscovery/nuclei/v2/pkg/protocols/common/replacer"
 )
 
@@ -29,15 +30,23 @@ func (r *Request) ExecuteWithResults(input string, metadata, previous output.Int
 	}
 
 	for _, kv := range r.addresses {
-		actualAddress := replacer.Replace(kv.ip, map[string]interface{}{"Hostname": address})
+		values := generators.MergeMaps(metadata, map[string]interface{}{"Hostname": address})
+		if strings.Contains(kv.ip, "{{ip}}") {
+			host := address
+			if splitHost, _, splitErr := net.SplitHostPort(address); splitErr == nil {
+				host = splitHost
+			}
+			values["ip"] = protocolstate.ResolveIP(host)
+		}
+		actualAddress := replacer.Replace(kv.ip, values)
 		if kv.port != "" {
-			if strings.Contains(address, ":") {
-				actualAddress, _, _ = net.SplitHostPort(actualAddress)
+			if host, _, splitErr := net.SplitHostPort(actualAddress); splitErr == nil {
+				actualAddress = host
 			}
-			actualAddress = net.JoinHostPort(actualAddress, kv.port)
+			actualAddress = net.JoinHostPort(strings.Trim(actualAddress, "[]"), kv.port)
 		}
 
-		err = r.executeAddress(actualAddress, address, input, kv.tls, previous, callback)
+		err = r.executeAddress(actualAddress, address, input, kv.tls, metadata, previous, callback)
 		if err != nil {
 			gologger.Verbose().Label("ERR").Msgf("Could not make network request for %s: %s\n", actualAddress, err)
 			continue
@@ -47,7 +56,8 @@ func (r *Request) ExecuteWithResults(input string, metadata, previous output.Int
 }
 
 // executeAddress executes the request for an address
-func (r *Request) executeAddress(actualAddress, address, input string, shouldUseTLS bool, previous output.InternalEvent, callback protocols.OutputEventCallback) error {
+func (r *Request) executeAddress(actualAddress, address, input string, shouldUseTLS bool, metadata, previous output.InternalEvent, callback protocols.OutputEventCallback) error {
+	started := time.Now()
 	if !strings.Contains(actualAddress, ":") {
 		err := errors.New("no port provided in network protocol request")
 		r.options.Output.Request(r.options.TemplateID, address, "network", err)
@@ -65,17 +75,29 @@ func (r *Request) executeAddress(actualAddress, address, input string, shouldUse
 		hostname = host
 	}
 
-	if shouldUseTLS {
-		conn, err = r.dialer.DialTLS(context.Background(), "tcp", actualAddress)
-	} else {
-		conn, err = r.dialer.Dial(context.Background(), "tcp", actualAddress)
-	}
+	err = r.retryPolicy.Do(func() error {
+		var dialErr error
+		if protocolstate.Resolver != nil {
+			if shouldUseTLS {
+				conn, dialErr = protocolstate.Resolver.DialTLS(r.options.RequestContext(), "tcp", actualAddress)
+			} else {
+				conn, dialErr = protocolstate.Resolver.Dial(r.options.RequestContext(), "tcp", actualAddress)
+			}
+		} else if shouldUseTLS {
+			conn, dialErr = r.dialer.DialTLS(r.options.RequestContext(), "tcp", actualAddress)
+		} else {
+			conn, dialErr = r.dialer.Dial(r.options.RequestContext(), "tcp", actualAddress)
+		}
+		return dialErr
+	})
 	if err != nil {
 		r.options.Output.Request(r.options.TemplateID, address, "network", err)
+		r.options.AuditLog.Log(r.options.TemplateID, "network", actualAddress, 0, 0, err)
 		r.options.Progress.IncrementFailedRequestsBy(1)
 		return errors.Wrap(err, "could not connect to server request")
 	}
 	defer conn.Close()
+	var sent, received int
 	_ = conn.SetReadDeadline(time.Now().Add(time.Duration(r.options.Options.Timeout) * time.Second))
 
 	hasInteractMarkers := interactsh.HasMatchers(r.CompiledOperators)
@@ -86,31 +108,41 @@ func (r *Request) executeAddress(actualAddress, address, input string, shouldUse
 
 	responseBuilder := &strings.Builder{}
 	reqBuilder := &strings.Builder{}
+	randomValues := generators.RandomValues(r.randomVars)
+	inputValues := generators.MergeMaps(metadata, randomValues)
 
 	inputEvents := make(map[string]interface{})
 	for _, input := range r.Inputs {
 		var data []byte
 
+		inputData := input.Data
 		switch input.Type {
 		case "hex":
 			data, err = hex.DecodeString(input.Data)
 		default:
 			if interactURL != "" {
-				input.Data = r.options.Interactsh.ReplaceMarkers(input.Data, interactURL)
+				inputData = r.options.Interactsh.ReplaceMarkers(inputData, interactURL)
 			}
-			data = []byte(input.Data)
+			if len(inputValues) > 0 {
+				inputData = replacer.Replace(inputData, inputValues)
+			}
+			data = []byte(inputData)
 		}
 		if err != nil {
 			r.options.Output.Request(r.options.TemplateID, address, "network", err)
+			r.options.AuditLog.Log(r.options.TemplateID, "network", actualAddress, sent, received, err)
 			r.options.Progress.IncrementFailedRequestsBy(1)
 			return errors.Wrap(err, "could not write request to server")
 		}
-		reqBuilder.Grow(len(input.Data))
-		reqBuilder.WriteString(input.Data)
+		reqBuilder.Grow(len(inputData))
+		reqBuilder.WriteString(inputData)
 
-		_, err = conn.Write(data)
+		var written int
+		written, err = conn.Write(data)
+		sent += written
 		if err != nil {
 			r.options.Output.Request(r.options.TemplateID, address, "network", err)
+			r.options.AuditLog.Log(r.options.TemplateID, "network", actualAddress, sent, received, err)
 			r.options.Progress.IncrementFailedRequestsBy(1)
 			return errors.Wrap(err, "could not write request to server")
 		}
@@ -118,6 +150,7 @@ func (r *Request) executeAddress(actualAddress, address, input string, shouldUse
 		if input.Read > 0 {
 			buffer := make([]byte, input.Read)
 			n, _ := conn.Read(buffer)
+			received += n
 			responseBuilder.Write(buffer[:n])
 			if input.Name != "" {
 				inputEvents[input.Name] = string(buffer[:n])
@@ -140,10 +173,13 @@ func (r *Request) executeAddress(actualAddress, address, input string, shouldUse
 	}
 	final := make([]byte, bufferSize)
 	n, err := conn.Read(final)
+	received += n
 	if err != nil && err != io.EOF {
 		r.options.Output.Request(r.options.TemplateID, address, "network", err)
+		r.options.AuditLog.Log(r.options.TemplateID, "network", actualAddress, sent, received, err)
 		return errors.Wrap(err, "could not read from server")
 	}
+	r.options.AuditLog.Log(r.options.TemplateID, "network", actualAddress, sent, received, nil)
 	responseBuilder.Write(final[:n])
 
 	if r.options.Options.Debug || r.options.Options.DebugResponse {
@@ -151,15 +187,27 @@ func (r *Request) executeAddress(actualAddress, address, input string, shouldUse
 		gologger.Print().Msgf("%s", responseBuilder.String())
 	}
 	outputEvent := r.responseToDSLMap(reqBuilder.String(), string(final[:n]), responseBuilder.String(), input, actualAddress)
-	outputEvent["ip"] = r.dialer.GetDialedIP(hostname)
+	dialedIP := r.dialer.GetDialedIP(hostname)
+	if dialedIP == "" && protocolstate.Resolver != nil {
+		dialedIP = protocolstate.ResolveIP(hostname)
+	}
+	outputEvent["ip"] = dialedIP
+	if shouldUseTLS && r.fingerprinter != nil {
+		for k, v := range r.fingerprinter.Get(actualAddress).Variables() {
+			outputEvent[k] = v
+		}
+	}
 	for k, v := range previous {
 		outputEvent[k] = v
 	}
 	for k, v := range inputEvents {
 		outputEvent[k] = v
 	}
+	for k, v := range randomValues {
+		outputEvent[k] = v
+	}
 
-	event := &output.InternalWrappedEvent{InternalEvent: outputEvent}
+	event := &output.InternalWrappedEvent{InternalEvent: outputEvent, Started: started}
 	if !hasInteractMarkers {
 		if r.CompiledOperators != nil {
 			result, ok := r.CompiledOperators.Execute(outputEvent, r.Match, r.Extract)
@@ -176,6 +224,8 @@ func (r *Request) executeAddress(actualAddress, address, input string, shouldUse
 			Operators:      r.CompiledOperators,
 			MatchFunc:      r.Match,
 			ExtractFunc:    r.Extract,
+			Workflow:       r.options.Workflow,
+			Input:          input,
 		})
 	}
 	return nil
@@ -1,16 +1,32 @@
 package protocols
 
 import (
+	"context"
+	"net/http"
+
 	"github.com/projectdiscovery/nuclei/v2/pkg/catalog"
+	"github.com/projectdiscovery/nuclei/v2/pkg/input/apispec"
 	"github.com/projectdiscovery/nuclei/v2/pkg/operators"
 	"github.com/projectdiscovery/nuclei/v2/pkg/operators/extractors"
 	"github.com/projectdiscovery/nuclei/v2/pkg/operators/matchers"
 	"github.com/projectdiscovery/nuclei/v2/pkg/output"
 	"github.com/projectdiscovery/nuclei/v2/pkg/progress"
 	"github.com/projectdiscovery/nuclei/v2/pkg/projectfile"
+	"github.com/projectdiscovery/nuclei/v2/pkg/protocols/common/auditlog"
+	"github.com/projectdiscovery/nuclei/v2/pkg/protocols/common/authprovider"
+	"github.com/projectdiscovery/nuclei/v2/pkg/protocols/common/contextstore"
+	"github.com/projectdiscovery/nuclei/v2/pkg/protocols/common/csrf"
+	"github.com/projectdiscovery/nuclei/v2/pkg/protocols/common/globalmatchers"
 	"github.com/projectdiscovery/nuclei/v2/pkg/protocols/common/interactsh"
+	"github.com/projectdiscovery/nuclei/v2/pkg/protocols/common/profiler"
+	"github.com/projectdiscovery/nuclei/v2/pkg/protocols/common/responsestore"
+	"github.com/projectdiscovery/nuclei/v2/pkg/protocols/common/variables"
+	"github.com/projectdiscovery/nuclei/v2/pkg/protocols/common/wafdetect"
 	"github.com/projectdiscovery/nuclei/v2/pkg/protocols/headless/engine"
+	"github.com/projectdiscovery/nuclei/v2/pkg/protocols/http/httpclientpool"
 	"github.com/projectdiscovery/nuclei/v2/pkg/reporting"
+	"github.com/projectdiscovery/nuclei/v2/pkg/reporting/triage"
+	"github.com/projectdiscovery/nuclei/v2/pkg/tracing"
 	"github.com/projectdiscovery/nuclei/v2/pkg/types"
 	"go.uber.org/ratelimit"
 )
@@ -53,10 +69,67 @@ type ExecuterOptions struct {
 	Browser *engine.Browser
 	// Interactsh is a client for interactsh oob polling server
 	Interactsh *interactsh.Client
+	// WafDetector tracks WAF/rate-limit responses per host if enabled
+	WafDetector *wafdetect.Detector
+	// GlobalMatchers contains matchers evaluated on responses of all templates
+	GlobalMatchers *globalmatchers.Storage
+	// Tracer records spans of the execution if tracing is enabled
+	Tracer *tracing.Tracer
+	// ResponseStore saves the requests and responses to disk if enabled
+	ResponseStore *responsestore.Store
+	// Variables are the template variables evaluated for each input
+	Variables variables.Variable
+	// Profiler records the time, requests and errors of the templates if enabled
+	Profiler *profiler.Profiler
+	// InputRequests are the requests of the API specification operations for the targets
+	InputRequests *apispec.Store
+	// AuthProvider injects the credentials of the secrets file in the requests
+	AuthProvider *authprovider.Provider
+	// CSRF injects the csrf tokens of the previous responses in the requests if enabled
+	CSRF *csrf.Store
+	// Triage applies the severity overrides and suppressions of the triage file to the results
+	Triage *triage.Rules
+	// AuditLog records every outbound request if enabled
+	AuditLog *auditlog.Logger
+	// ContextStore contains the values stored by the extractors for each host
+	ContextStore *contextstore.Store
+	// CookieJar is the cookie jar shared by the http requests of all the
+	// templates with -reuse-cookies. (Optional)
+	CookieJar http.CookieJar
+	// CookieJars contains the cookie jars of the inputs the http requests
+	// of the template are executed on.
+	CookieJars *httpclientpool.CookieJars
+	// Context is cancelled when the scan is stopped, aborting the requests
+	// being executed. (Optional)
+	Context context.Context
+	// Workflow is the workflow running the template whose info is
+	// inherited by the results. (Optional)
+	Workflow *output.Workflow
+	// EventHook is called with every event of the requests, matched or
+	// not, along with the request of the event. (Optional)
+	EventHook func(req Request, event *output.InternalWrappedEvent)
+	// ResultHook is called with every result written for an input, after
+	// the triage rules are applied. (Optional)
+	ResultHook func(input string, result *output.ResultEvent)
 
 	Operators []*operators.Operators // only used by offlinehttp module
 }
 
+// RequestContext returns the context of the requests, which is cancelled
+// when the scan is stopped.
+func (e *ExecuterOptions) RequestContext() context.Context {
+	if e.Context == nil {
+		return context.Background()
+	}
+	return e.Context
+}
+
+// Cancelled returns true if the scan is stopped and no more requests
+// should be sent.
+func (e *ExecuterOptions) Cancelled() bool {
+	return e.Context != nil && e.Context.Err() != nil
+}
+
 // Request is an interface implemented any protocol based request generator.
 type Request interface {
 	// Compile compiles the request generators preparing any requests possible.
@@ -0,0 +1,306 @@
+package service
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// BER tags of the ldap messages
+const (
+	berInteger          = 0x02
+	berOctetString      = 0x04
+	berBoolean          = 0x01
+	berEnumerated       = 0x0a
+	berSequence         = 0x30
+	berSet              = 0x31
+	ldapBindRequest     = 0x60
+	ldapBindResponse    = 0x61
+	ldapSearchRequest   = 0x63
+	ldapSearchEntry     = 0x64
+	ldapSearchDone      = 0x65
+	ldapSimpleAuth      = 0x80
+	ldapPresentFilter   = 0x87
+	maxLDAPMessages     = 100
+	maxBERElementLength = 1 << 20
+)
+
+// rootDSEAttributes are the attributes read from the root dse
+var rootDSEAttributes = []string{
+	"namingContexts", "defaultNamingContext", "rootDomainNamingContext", "dnsHostName", "serverName",
+	"supportedLDAPVersion", "supportedSASLMechanisms", "vendorName", "vendorVersion",
+	"domainFunctionality", "forestFunctionality", "domainControllerFunctionality",
+}
+
+// berElement is a decoded BER element
+type berElement struct {
+	tag     byte
+	content []byte
+}
+
+// berEncode encodes a BER element with the concatenated contents
+func berEncode(tag byte, contents ...[]byte) []byte {
+	var content []byte
+	for _, item := range contents {
+		content = append(content, item...)
+	}
+	b := []byte{tag}
+	length := len(content)
+	switch {
+	case length < 0x80:
+		b = append(b, byte(length))
+	case length < 0x100:
+		b = append(b, 0x81, byte(length))
+	default:
+		b = append(b, 0x82, byte(length>>8), byte(length))
+	}
+	return append(b, content...)
+}
+
+// berInt encodes a small non negative integer
+func berInt(tag byte, value int) []byte {
+	var content []byte
+	for value > 0 {
+		content = append([]byte{byte(value)}, content...)
+		value >>= 8
+	}
+	if len(content) == 0 || content[0]&0x80 != 0 {
+		content = append([]byte{0}, content...)
+	}
+	return berEncode(tag, content)
+}
+
+// berDecode decodes the first element of the data returning the remaining data
+func berDecode(data []byte) (berElement, []byte, error) {
+	if len(data) < 2 {
+		return berElement{}, nil, errors.New("invalid ber element")
+	}
+	tag, length, offset := data[0], int(data[1]), 2
+	if length&0x80 != 0 {
+		size := length & 0x7f
+		if size == 0 || size > 4 || len(data) < 2+size {
+			return berElement{}, nil, errors.New("invalid ber length")
+		}
+		length = 0
+		for _, b := range data[2 : 2+size] {
+			length = length<<8 | int(b)
+		}
+		offset += size
+	}
+	if length < 0 || len(data)-offset < length {
+		return berElement{}, nil, errors.New("truncated ber element")
+	}
+	return berElement{tag: tag, content: data[offset : offset+length]}, data[offset+length:], nil
+}
+
+// berElements decodes all the elements of a constructed content
+func berElements(data []byte) ([]berElement, error) {
+	var elements []berElement
+	for len(data) > 0 {
+		element, rest, err := berDecode(data)
+		if err != nil {
+			return nil, err
+		}
+		elements = append(elements, element)
+		data = rest
+	}
+	return elements, nil
+}
+
+// berReadElement reads a complete BER element from the reader
+func berReadElement(reader *bufio.Reader) ([]byte, error) {
+	header := make([]byte, 2)
+	if _, err := io.ReadFull(reader, header); err != nil {
+		return nil, err
+	}
+	length := int(header[1])
+	if length&0x80 != 0 {
+		size := length & 0x7f
+		if size == 0 || size > 4 {
+			return nil, errors.New("invalid ber length")
+		}
+		lengthBytes := make([]byte, size)
+		if _, err := io.ReadFull(reader, lengthBytes); err != nil {
+			return nil, err
+		}
+		header = append(header, lengthBytes...)
+		length = 0
+		for _, b := range lengthBytes {
+			length = length<<8 | int(b)
+		}
+	}
+	if length < 0 || length > maxBERElementLength {
+		return nil, errors.New("ber element is too large")
+	}
+	content := make([]byte, length)
+	if _, err := io.ReadFull(reader, content); err != nil {
+		return nil, err
+	}
+	return append(header, content...), nil
+}
+
+// ldapAnonymousBindRequest returns a simple bind request with empty credentials
+func ldapAnonymousBindRequest(messageID int) []byte {
+	return berEncode(berSequence,
+		berInt(berInteger, messageID),
+		berEncode(ldapBindRequest, berInt(berInteger, 3), berEncode(berOctetString), berEncode(ldapSimpleAuth)),
+	)
+}
+
+// ldapRootDSERequest returns a base search request of the root dse
+func ldapRootDSERequest(messageID int) []byte {
+	var attributes [][]byte
+	for _, attribute := range rootDSEAttributes {
+		attributes = append(attributes, berEncode(berOctetString, []byte(attribute)))
+	}
+	return berEncode(berSequence,
+		berInt(berInteger, messageID),
+		berEncode(ldapSearchRequest,
+			berEncode(berOctetString),
+			berInt(berEnumerated, 0), // baseObject scope
+			berInt(berEnumerated, 0), // neverDerefAliases
+			berInt(berInteger, 0),
+			berInt(berInteger, 0),
+			berEncode(berBoolean, []byte{0}),
+			berEncode(ldapPresentFilter, []byte("objectClass")),
+			berEncode(berSequence, attributes...),
+		),
+	)
+}
+
+// ldapProtocolOp decodes a ldap message returning its protocol operation
+func ldapProtocolOp(data []byte) (berElement, error) {
+	message, _, err := berDecode(data)
+	if err != nil {
+		return berElement{}, err
+	}
+	elements, err := berElements(message.content)
+	if err != nil {
+		return berElement{}, err
+	}
+	if message.tag != berSequence || len(elements) < 2 {
+		return berElement{}, errors.New("invalid ldap message")
+	}
+	return elements[1], nil
+}
+
+// parseLDAPResult parses the result code and diagnostic message of a ldap result
+func parseLDAPResult(op berElement) (int, string, error) {
+	elements, err := berElements(op.content)
+	if err != nil {
+		return 0, "", err
+	}
+	if len(elements) < 3 || elements[0].tag != berEnumerated {
+		return 0, "", errors.New("invalid ldap result")
+	}
+	code := 0
+	for _, b := range elements[0].content {
+		code = code<<8 | int(b)
+	}
+	return code, string(elements[2].content), nil
+}
+
+// parseLDAPEntry parses the attributes of a search result entry
+func parseLDAPEntry(op berElement, attributes map[string][]string) error {
+	elements, err := berElements(op.content)
+	if err != nil {
+		return err
+	}
+	if len(elements) < 2 {
+		return errors.New("invalid ldap search entry")
+	}
+	partials, err := berElements(elements[1].content)
+	if err != nil {
+		return err
+	}
+	for _, partial := range partials {
+		parts, err := berElements(partial.content)
+		if err != nil || len(parts) < 2 {
+			continue
+		}
+		values, err := berElements(parts[1].content)
+		if err != nil {
+			continue
+		}
+		name := strings.ToLower(string(parts[0].content))
+		for _, value := range values {
+			attributes[name] = append(attributes[name], string(value.content))
+		}
+	}
+	return nil
+}
+
+// probeLDAP performs an anonymous bind and reads the root dse
+func probeLDAP(dial func() (net.Conn, error)) (map[string]interface{}, error) {
+	conn, err := dial()
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+
+	if _, err := conn.Write(ldapAnonymousBindRequest(1)); err != nil {
+		return nil, errors.Wrap(err, "could not write bind request")
+	}
+	data, err := berReadElement(reader)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not read bind response")
+	}
+	op, err := ldapProtocolOp(data)
+	if err != nil {
+		return nil, err
+	}
+	if op.tag != ldapBindResponse {
+		return nil, errors.New("invalid ldap bind response")
+	}
+	code, message, err := parseLDAPResult(op)
+	if err != nil {
+		return nil, err
+	}
+
+	values := map[string]interface{}{
+		"anonymous_bind": code == 0,
+		"bind_result":    code,
+		"bind_message":   message,
+	}
+	// The root dse is usually readable even without a successful bind
+	attributes := make(map[string][]string)
+	if _, err := conn.Write(ldapRootDSERequest(2)); err == nil {
+		for i := 0; i < maxLDAPMessages; i++ {
+			data, err := berReadElement(reader)
+			if err != nil {
+				break
+			}
+			op, err := ldapProtocolOp(data)
+			if err != nil || op.tag == ldapSearchDone {
+				break
+			}
+			if op.tag == ldapSearchEntry {
+				_ = parseLDAPEntry(op, attributes)
+			}
+		}
+	}
+
+	names := make([]string, 0, len(attributes))
+	for name := range attributes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	builder := &strings.Builder{}
+	builder.WriteString("anonymous_bind: ")
+	if code == 0 {
+		builder.WriteString("true")
+	} else {
+		builder.WriteString("false")
+	}
+	for _, name := range names {
+		values[name] = strings.Join(attributes[name], " ")
+		builder.WriteString("\n" + name + ": " + strings.Join(attributes[name], " "))
+	}
+	values["data"] = builder.String()
+	return values, nil
+}
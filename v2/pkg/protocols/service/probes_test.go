@@ -0,0 +1,109 @@
+package service
+
+import (
+	"bufio"
+	"encoding/binary"
+	"io"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// pipeDialer returns a dialer of in-memory connections served by the handler
+func pipeDialer(handler func(conn net.Conn)) func() (net.Conn, error) {
+	return func() (net.Conn, error) {
+		client, server := net.Pipe()
+		go func() {
+			defer server.Close()
+			handler(server)
+		}()
+		return client, nil
+	}
+}
+
+func TestProbeRDP(t *testing.T) {
+	// The server only allows hybrid security (nla)
+	dial := pipeDialer(func(conn net.Conn) {
+		request := make([]byte, 19)
+		if _, err := io.ReadFull(conn, request); err != nil {
+			return
+		}
+		requested := binary.LittleEndian.Uint32(request[15:])
+		response := []byte{0x03, 0x00, 0x00, 0x13, 0x0e, 0xd0, 0, 0, 0x12, 0x34, 0, 0x02, 0, 0x08, 0, 0, 0, 0, 0}
+		if requested&rdpProtocolHybrid == 0 {
+			response[11] = rdpNegotiationFailure
+			binary.LittleEndian.PutUint32(response[15:], 5)
+		} else {
+			binary.LittleEndian.PutUint32(response[15:], rdpProtocolHybrid)
+		}
+		_, _ = conn.Write(response)
+	})
+
+	values, err := probeRDP(dial)
+	require.Nil(t, err, "could not probe rdp")
+	require.Equal(t, "hybrid", values["security_protocols"], "could not get security protocols")
+	require.Equal(t, true, values["nla_required"], "could not detect required nla")
+	require.Equal(t, false, values["rdp_security"], "could not detect standard rdp security")
+	require.Contains(t, values["failures"], "rdp:hybrid_required_by_server", "could not get failure codes")
+}
+
+func TestProbeSMB(t *testing.T) {
+	dial := pipeDialer(func(conn net.Conn) {
+		header := make([]byte, 4)
+		if _, err := io.ReadFull(conn, header); err != nil {
+			return
+		}
+		request := make([]byte, int(header[1])<<16|int(header[2])<<8|int(header[3]))
+		if _, err := io.ReadFull(conn, request); err != nil {
+			return
+		}
+		if request[0] == 0xff {
+			// Reject smb1 by not selecting any dialect
+			response := make([]byte, 37)
+			copy(response, "\xffSMB\x72")
+			response[32] = 1
+			binary.LittleEndian.PutUint16(response[33:], 0xffff)
+			_, _ = conn.Write(netBIOSMessage(response))
+			return
+		}
+		response := make([]byte, 128)
+		copy(response, "\xfeSMB")
+		binary.LittleEndian.PutUint16(response[64+2:], smb2SigningEnabled|smb2SigningRequired)
+		binary.LittleEndian.PutUint16(response[64+4:], 0x0311)
+		binary.LittleEndian.PutUint32(response[64+24:], smb2CapEncryption)
+		_, _ = conn.Write(netBIOSMessage(response))
+	})
+
+	values, err := probeSMB(dial)
+	require.Nil(t, err, "could not probe smb")
+	require.Equal(t, "3.1.1", values["dialect"], "could not get dialect")
+	require.Equal(t, true, values["signing_required"], "could not get signing requirement")
+	require.Equal(t, true, values["encryption"], "could not get encryption capability")
+	require.Equal(t, false, values["smbv1"], "could not detect disabled smbv1")
+}
+
+func TestProbeLDAP(t *testing.T) {
+	dial := pipeDialer(func(conn net.Conn) {
+		reader := bufio.NewReader(conn)
+		if _, err := berReadElement(reader); err != nil {
+			return
+		}
+		result := berEncode(ldapBindResponse, berInt(berEnumerated, 0), berEncode(berOctetString), berEncode(berOctetString))
+		_, _ = conn.Write(berEncode(berSequence, berInt(berInteger, 1), result))
+
+		if _, err := berReadElement(reader); err != nil {
+			return
+		}
+		attribute := berEncode(berSequence, berEncode(berOctetString, []byte("defaultNamingContext")), berEncode(berSet, berEncode(berOctetString, []byte("DC=example,DC=com"))))
+		entry := berEncode(ldapSearchEntry, berEncode(berOctetString), berEncode(berSequence, attribute))
+		_, _ = conn.Write(berEncode(berSequence, berInt(berInteger, 2), entry))
+		done := berEncode(ldapSearchDone, berInt(berEnumerated, 0), berEncode(berOctetString), berEncode(berOctetString))
+		_, _ = conn.Write(berEncode(berSequence, berInt(berInteger, 2), done))
+	})
+
+	values, err := probeLDAP(dial)
+	require.Nil(t, err, "could not probe ldap")
+	require.Equal(t, true, values["anonymous_bind"], "could not detect anonymous bind")
+	require.Equal(t, "DC=example,DC=com", values["defaultnamingcontext"], "could not get root dse attribute")
+}
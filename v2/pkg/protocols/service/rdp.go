@@ -0,0 +1,145 @@
+package service
+
+import (
+	"encoding/binary"
+	"io"
+	"net"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Security protocols of the rdp negotiation
+const (
+	rdpProtocolRDP      = 0x0
+	rdpProtocolSSL      = 0x1
+	rdpProtocolHybrid   = 0x2
+	rdpProtocolHybridEx = 0x8
+)
+
+// Types of the rdp negotiation responses
+const (
+	rdpNegotiationResponse = 0x02
+	rdpNegotiationFailure  = 0x03
+)
+
+// rdpProtocols are the security protocols probed in order
+var rdpProtocols = []struct {
+	value uint32
+	name  string
+}{
+	{rdpProtocolRDP, "rdp"},
+	{rdpProtocolSSL, "ssl"},
+	{rdpProtocolHybrid, "hybrid"},
+	{rdpProtocolHybridEx, "hybrid_ex"},
+}
+
+// rdpFailures are the names of the rdp negotiation failure codes
+var rdpFailures = map[uint32]string{
+	1: "ssl_required_by_server",
+	2: "ssl_not_allowed_by_server",
+	3: "ssl_cert_not_on_server",
+	4: "inconsistent_flags",
+	5: "hybrid_required_by_server",
+	6: "ssl_with_user_auth_required_by_server",
+}
+
+// rdpNegotiationRequest returns a x.224 connection request with a rdp
+// negotiation request for the security protocols.
+func rdpNegotiationRequest(protocols uint32) []byte {
+	packet := []byte{
+		0x03, 0x00, 0x00, 0x13, // TPKT header
+		0x0e, 0xe0, 0x00, 0x00, 0x00, 0x00, 0x00, // X.224 connection request
+		0x01, 0x00, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, // RDP negotiation request
+	}
+	binary.LittleEndian.PutUint32(packet[15:], protocols)
+	return packet
+}
+
+// parseRDPNegotiation parses the negotiation of a x.224 connection
+// confirm. The type is zero if the server sent no negotiation data.
+func parseRDPNegotiation(data []byte) (byte, uint32, error) {
+	if len(data) < 11 || data[0] != 0x03 || data[5]&0xf0 != 0xd0 {
+		return 0, 0, errors.New("invalid x.224 connection confirm")
+	}
+	if int(data[4]) < 14 || len(data) < 19 {
+		return 0, 0, nil
+	}
+	return data[11], binary.LittleEndian.Uint32(data[15:]), nil
+}
+
+// probeRDP negotiates each security protocol on a separate connection
+func probeRDP(dial func() (net.Conn, error)) (map[string]interface{}, error) {
+	var supported, failures []string
+	for i, protocol := range rdpProtocols {
+		conn, err := dial()
+		if err != nil {
+			if i == 0 {
+				return nil, err
+			}
+			continue
+		}
+		negotiationType, value, err := rdpNegotiate(conn, protocol.value)
+		conn.Close()
+		if err != nil {
+			if i == 0 {
+				return nil, err
+			}
+			continue
+		}
+
+		switch negotiationType {
+		case 0:
+			if protocol.value == rdpProtocolRDP {
+				supported = append(supported, protocol.name)
+			}
+		case rdpNegotiationResponse:
+			if value == protocol.value {
+				supported = append(supported, protocol.name)
+			}
+		case rdpNegotiationFailure:
+			if name, ok := rdpFailures[value]; ok {
+				failures = append(failures, protocol.name+":"+name)
+			}
+		}
+	}
+
+	values := map[string]interface{}{
+		"security_protocols": strings.Join(supported, " "),
+		"failures":           strings.Join(failures, " "),
+		"rdp_security":       contains(supported, "rdp"),
+		"nla_required":       len(supported) > 0 && !contains(supported, "rdp") && !contains(supported, "ssl"),
+	}
+	values["data"] = "security_protocols: " + values["security_protocols"].(string) + "\nfailures: " + values["failures"].(string)
+	return values, nil
+}
+
+// rdpNegotiate sends a negotiation request reading the connection confirm
+func rdpNegotiate(conn net.Conn, protocols uint32) (byte, uint32, error) {
+	if _, err := conn.Write(rdpNegotiationRequest(protocols)); err != nil {
+		return 0, 0, errors.Wrap(err, "could not write negotiation request")
+	}
+	header := make([]byte, 4)
+	if _, err := io.ReadFull(conn, header); err != nil {
+		return 0, 0, errors.Wrap(err, "could not read connection confirm")
+	}
+	length := int(binary.BigEndian.Uint16(header[2:]))
+	if length < 4 {
+		return 0, 0, errors.New("invalid tpkt header")
+	}
+	data := make([]byte, length)
+	copy(data, header)
+	if _, err := io.ReadFull(conn, data[4:]); err != nil {
+		return 0, 0, errors.Wrap(err, "could not read connection confirm")
+	}
+	return parseRDPNegotiation(data)
+}
+
+func contains(items []string, value string) bool {
+	for _, item := range items {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,105 @@
+package service
+
+import (
+	"net"
+	"strings"
+	"time"
+
+	"github.com/projectdiscovery/gologger"
+	"github.com/projectdiscovery/nuclei/v2/pkg/output"
+	"github.com/projectdiscovery/nuclei/v2/pkg/protocols"
+	"github.com/projectdiscovery/nuclei/v2/pkg/protocols/common/auditlog"
+	"github.com/projectdiscovery/nuclei/v2/pkg/protocols/common/generators"
+	"github.com/projectdiscovery/nuclei/v2/pkg/protocols/common/protocolstate"
+	"github.com/projectdiscovery/nuclei/v2/pkg/protocols/common/replacer"
+)
+
+var _ protocols.Request = &Request{}
+
+// probes are the probe functions of the protocols
+var probes = map[string]func(dial func() (net.Conn, error)) (map[string]interface{}, error){
+	ProtocolRDP:  probeRDP,
+	ProtocolSMB:  probeSMB,
+	ProtocolLDAP: probeLDAP,
+}
+
+// ExecuteWithResults executes the protocol requests and returns results instead of writing them.
+func (r *Request) ExecuteWithResults(input string, metadata, previous output.InternalEvent, callback protocols.OutputEventCallback) error {
+	hostname, inputPort := protocolstate.HostPort(input)
+
+	for _, addr := range r.addresses {
+		values := generators.MergeMaps(metadata, map[string]interface{}{"Hostname": hostname})
+		host := replacer.Replace(addr.host, values)
+		port := addr.port
+		if port == "" {
+			port = inputPort
+		}
+		if port == "" {
+			port = defaultPorts[r.Protocol][0]
+			if addr.tls {
+				port = defaultPorts[r.Protocol][1]
+			}
+		}
+		actualAddress := net.JoinHostPort(strings.Trim(host, "[]"), port)
+
+		if err := r.executeAddress(actualAddress, host, input, addr.tls, previous, callback); err != nil {
+			gologger.Verbose().Label("ERR").Msgf("Could not make %s request for %s: %s\n", r.Protocol, actualAddress, err)
+		}
+	}
+	return nil
+}
+
+// executeAddress executes the service probe for an address
+func (r *Request) executeAddress(actualAddress, host, input string, shouldUseTLS bool, previous output.InternalEvent, callback protocols.OutputEventCallback) error {
+	started := time.Now()
+	// Some probes use a separate connection for each negotiation
+	counter := &auditlog.Counter{}
+	dial := func() (net.Conn, error) {
+		var conn net.Conn
+		err := r.retryPolicy.Do(func() error {
+			var dialErr error
+			conn, dialErr = protocolstate.Dial(r.options.RequestContext(), r.dialer, actualAddress, shouldUseTLS)
+			return dialErr
+		})
+		if err != nil {
+			return nil, err
+		}
+		_ = conn.SetDeadline(time.Now().Add(time.Duration(r.options.Options.Timeout) * time.Second))
+		return counter.Wrap(conn), nil
+	}
+
+	values, err := probes[r.Protocol](dial)
+	r.options.Output.Request(r.options.TemplateID, actualAddress, r.Protocol, err)
+	r.options.AuditLog.Log(r.options.TemplateID, r.Protocol, actualAddress, counter.Sent(), counter.Received(), err)
+	r.options.Progress.IncrementRequests()
+	if err != nil {
+		r.options.Progress.IncrementFailedRequestsBy(1)
+		return err
+	}
+	gologger.Verbose().Msgf("Sent %s request to %s", strings.ToUpper(r.Protocol), actualAddress)
+
+	if r.options.Options.Debug || r.options.Options.DebugResponse {
+		gologger.Info().Str("address", actualAddress).Msgf("[%s] Dumped %s probe for %s", r.options.TemplateID, strings.ToUpper(r.Protocol), actualAddress)
+		gologger.Print().Msgf("%s", values["data"])
+	}
+
+	outputEvent := r.responseToDSLMap(values, input, actualAddress)
+	outputEvent["ip"] = r.dialer.GetDialedIP(host)
+	if outputEvent["ip"] == "" && protocolstate.Resolver != nil {
+		outputEvent["ip"] = protocolstate.ResolveIP(host)
+	}
+	for k, v := range previous {
+		outputEvent[k] = v
+	}
+
+	event := &output.InternalWrappedEvent{InternalEvent: outputEvent, Started: started}
+	if r.CompiledOperators != nil {
+		result, ok := r.CompiledOperators.Execute(outputEvent, r.Match, r.Extract)
+		if ok && result != nil {
+			event.OperatorsResult = result
+			event.Results = r.MakeResultEvent(event)
+		}
+	}
+	callback(event)
+	return nil
+}
@@ -0,0 +1,111 @@
+// Package service implements structured probes of internal network
+// services - rdp security negotiation, smb dialect and signing detection
+// and ldap anonymous bind with root dse lookup.
+package service
+
+import (
+	"net"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/projectdiscovery/fastdialer/fastdialer"
+	"github.com/projectdiscovery/nuclei/v2/pkg/operators"
+	"github.com/projectdiscovery/nuclei/v2/pkg/protocols"
+	"github.com/projectdiscovery/nuclei/v2/pkg/protocols/common/retry"
+	"github.com/projectdiscovery/nuclei/v2/pkg/protocols/network/networkclientpool"
+)
+
+// Supported service protocols
+const (
+	ProtocolRDP  = "rdp"
+	ProtocolSMB  = "smb"
+	ProtocolLDAP = "ldap"
+)
+
+// defaultPorts are the default ports of the protocols for plain and tls connections
+var defaultPorts = map[string][2]string{
+	ProtocolRDP:  {"3389", "3389"},
+	ProtocolSMB:  {"445", "445"},
+	ProtocolLDAP: {"389", "636"},
+}
+
+// Request contains a service probe request to be made from a template
+type Request struct {
+	ID string `yaml:"id"`
+
+	// Protocol is the protocol of the service - rdp, smb or ldap
+	Protocol string `yaml:"protocol"`
+	// Address is the address of the service. The default port of the protocol
+	// is used if no port is provided, and tls:// uses implicit tls (ldaps).
+	Address   []string `yaml:"host"`
+	addresses []address
+	// Retries is the number of retries for the connection overriding the global value
+	Retries int `yaml:"retries"`
+
+	// Operators for the current request go here.
+	operators.Operators `yaml:",inline,omitempty"`
+	CompiledOperators   *operators.Operators
+
+	dialer      *fastdialer.Dialer
+	retryPolicy *retry.Policy
+	options     *protocols.ExecuterOptions
+}
+
+type address struct {
+	host string
+	port string
+	tls  bool
+}
+
+// GetID returns the unique ID of the request if any.
+func (r *Request) GetID() string {
+	return r.ID
+}
+
+// Compile compiles the protocol request for further execution.
+func (r *Request) Compile(options *protocols.ExecuterOptions) error {
+	r.Protocol = strings.ToLower(r.Protocol)
+	if _, ok := defaultPorts[r.Protocol]; !ok {
+		return errors.Errorf("invalid service protocol %s (it should be rdp, smb or ldap)", r.Protocol)
+	}
+
+	r.addresses = r.addresses[:0]
+	for _, value := range r.Address {
+		addr := address{}
+		if strings.HasPrefix(value, "tls://") {
+			addr.tls = true
+			value = strings.TrimPrefix(value, "tls://")
+		}
+		addr.host = value
+		if strings.Contains(value, ":") {
+			host, port, err := net.SplitHostPort(value)
+			if err != nil {
+				return errors.Wrap(err, "could not parse address")
+			}
+			addr.host, addr.port = host, port
+		}
+		r.addresses = append(r.addresses, addr)
+	}
+
+	client, err := networkclientpool.Get(options.Options, &networkclientpool.Configuration{})
+	if err != nil {
+		return errors.Wrap(err, "could not get network client")
+	}
+	r.dialer = client
+	r.retryPolicy = retry.NewPolicy(options.Options, r.Retries)
+
+	if len(r.Matchers) > 0 || len(r.Extractors) > 0 {
+		compiled := &r.Operators
+		if err := compiled.Compile(); err != nil {
+			return errors.Wrap(err, "could not compile operators")
+		}
+		r.CompiledOperators = compiled
+	}
+	r.options = options
+	return nil
+}
+
+// Requests returns the total number of requests the YAML rule will perform
+func (r *Request) Requests() int {
+	return len(r.Address)
+}
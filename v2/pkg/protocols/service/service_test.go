@@ -0,0 +1,34 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/projectdiscovery/nuclei/v2/internal/testutils"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServiceCompileMake(t *testing.T) {
+	options := testutils.DefaultOptions
+
+	testutils.Init(options)
+	templateID := "testing-service"
+	request := &Request{
+		ID:       templateID,
+		Protocol: "LDAP",
+		Address:  []string{"{{Hostname}}", "tls://{{Hostname}}:3269"},
+	}
+	executerOpts := testutils.NewMockExecuterOptions(options, &testutils.TemplateInfo{
+		ID:   templateID,
+		Info: map[string]interface{}{"severity": "low", "name": "test"},
+	})
+	err := request.Compile(executerOpts)
+	require.Nil(t, err, "could not compile service request")
+	require.Equal(t, ProtocolLDAP, request.Protocol, "could not normalize protocol")
+	require.Equal(t, 2, len(request.addresses), "could not get correct number of input address")
+	require.True(t, request.addresses[1].tls, "could not get tls for host")
+	require.Equal(t, "3269", request.addresses[1].port, "could not get correct port for host")
+
+	invalid := &Request{ID: templateID, Protocol: "ftp"}
+	err = invalid.Compile(executerOpts)
+	require.NotNil(t, err, "could not detect invalid protocol")
+}
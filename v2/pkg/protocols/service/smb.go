@@ -0,0 +1,186 @@
+package service
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net"
+
+	"github.com/pkg/errors"
+)
+
+// smb2Dialects are the dialects offered in the smb2 negotiate request
+var smb2Dialects = []uint16{0x0202, 0x0210, 0x0300, 0x0302, 0x0311}
+
+// Security modes and capabilities of the smb2 negotiate response
+const (
+	smb2SigningEnabled  = 0x01
+	smb2SigningRequired = 0x02
+	smb2CapEncryption   = 0x40
+)
+
+// maxNetBIOSSize is the maximum size of a read netbios message
+const maxNetBIOSSize = 1 << 20
+
+// smb2NegotiateRequest returns a smb2 negotiate request offering the
+// dialects up to 3.1.1 with the negotiate contexts required by it.
+func smb2NegotiateRequest() []byte {
+	header := make([]byte, 64)
+	copy(header, "\xfeSMB")
+	binary.LittleEndian.PutUint16(header[4:], 64) // StructureSize
+	binary.LittleEndian.PutUint16(header[14:], 1) // CreditRequest
+
+	body := make([]byte, 36)
+	binary.LittleEndian.PutUint16(body[0:], 36)
+	binary.LittleEndian.PutUint16(body[2:], uint16(len(smb2Dialects)))
+	binary.LittleEndian.PutUint16(body[4:], smb2SigningEnabled)
+	binary.LittleEndian.PutUint32(body[8:], smb2CapEncryption)
+	_, _ = rand.Read(body[12:28]) // ClientGuid
+	for _, dialect := range smb2Dialects {
+		body = appendUint16(body, dialect)
+	}
+	for (len(header)+len(body))%8 != 0 {
+		body = append(body, 0)
+	}
+	binary.LittleEndian.PutUint32(body[28:], uint32(len(header)+len(body)))
+	binary.LittleEndian.PutUint16(body[32:], 2)
+
+	// Preauth integrity capabilities with SHA-512 and a random salt
+	salt := make([]byte, 32)
+	_, _ = rand.Read(salt)
+	preauth := appendUint16(appendUint16(appendUint16(nil, 1), uint16(len(salt))), 0x0001)
+	body = appendNegotiateContext(body, 0x0001, append(preauth, salt...))
+	for (len(header)+len(body))%8 != 0 {
+		body = append(body, 0)
+	}
+	// Encryption capabilities with AES-128-GCM and AES-128-CCM
+	body = appendNegotiateContext(body, 0x0002, appendUint16(appendUint16(appendUint16(nil, 2), 0x0002), 0x0001))
+
+	return netBIOSMessage(append(header, body...))
+}
+
+// smb1NegotiateRequest returns a smb1 negotiate request offering only the NT LM 0.12 dialect
+func smb1NegotiateRequest() []byte {
+	header := make([]byte, 32)
+	copy(header, "\xffSMB")
+	header[4] = 0x72                                   // Negotiate
+	header[9] = 0x18                                   // Flags
+	binary.LittleEndian.PutUint16(header[10:], 0xc801) // Flags2
+	dialects := []byte("\x02NT LM 0.12\x00")
+	body := append([]byte{0}, appendUint16(nil, uint16(len(dialects)))...)
+	return netBIOSMessage(append(header, append(body, dialects...)...))
+}
+
+// smb2Negotiation is the parsed smb2 negotiate response
+type smb2Negotiation struct {
+	dialect      uint16
+	securityMode uint16
+	serverGUID   []byte
+	capabilities uint32
+}
+
+// parseSMB2Negotiate parses a smb2 negotiate response without netbios header
+func parseSMB2Negotiate(data []byte) (*smb2Negotiation, error) {
+	if len(data) < 64+64 || !bytes.HasPrefix(data, []byte("\xfeSMB")) {
+		return nil, errors.New("invalid smb2 negotiate response")
+	}
+	if status := binary.LittleEndian.Uint32(data[8:]); status != 0 {
+		return nil, errors.Errorf("smb2 negotiate failed with status 0x%08x", status)
+	}
+	body := data[64:]
+	return &smb2Negotiation{
+		securityMode: binary.LittleEndian.Uint16(body[2:]),
+		dialect:      binary.LittleEndian.Uint16(body[4:]),
+		serverGUID:   body[8:24],
+		capabilities: binary.LittleEndian.Uint32(body[24:]),
+	}, nil
+}
+
+// isSMB1Negotiated returns true if a smb1 negotiate response selected a dialect
+func isSMB1Negotiated(data []byte) bool {
+	if len(data) < 35 || !bytes.HasPrefix(data, []byte("\xffSMB")) || data[4] != 0x72 {
+		return false
+	}
+	if binary.LittleEndian.Uint32(data[5:]) != 0 || data[32] == 0 {
+		return false
+	}
+	return binary.LittleEndian.Uint16(data[33:]) != 0xffff
+}
+
+// probeSMB detects the smb2 dialect and signing requirement and smb1 support
+func probeSMB(dial func() (net.Conn, error)) (map[string]interface{}, error) {
+	conn, err := dial()
+	if err != nil {
+		return nil, err
+	}
+	data, err := netBIOSExchange(conn, smb2NegotiateRequest())
+	conn.Close()
+	if err != nil {
+		return nil, err
+	}
+	negotiation, err := parseSMB2Negotiate(data)
+	if err != nil {
+		return nil, err
+	}
+
+	smb1 := false
+	if conn, err := dial(); err == nil {
+		if data, err := netBIOSExchange(conn, smb1NegotiateRequest()); err == nil {
+			smb1 = isSMB1Negotiated(data)
+		}
+		conn.Close()
+	}
+
+	dialect := fmt.Sprintf("%d.%d.%d", negotiation.dialect>>8, negotiation.dialect>>4&0xf, negotiation.dialect&0xf)
+	values := map[string]interface{}{
+		"dialect":          dialect,
+		"signing_enabled":  negotiation.securityMode&smb2SigningEnabled != 0,
+		"signing_required": negotiation.securityMode&smb2SigningRequired != 0,
+		"encryption":       negotiation.capabilities&smb2CapEncryption != 0,
+		"smbv1":            smb1,
+		"server_guid":      hex.EncodeToString(negotiation.serverGUID),
+		"capabilities":     negotiation.capabilities,
+	}
+	values["data"] = fmt.Sprintf("dialect: %s\nsigning_enabled: %v\nsigning_required: %v\nencryption: %v\nsmbv1: %v", dialect, values["signing_enabled"], values["signing_required"], values["encryption"], smb1)
+	return values, nil
+}
+
+// netBIOSExchange writes a message and reads the response without netbios header
+func netBIOSExchange(conn net.Conn, message []byte) ([]byte, error) {
+	if _, err := conn.Write(message); err != nil {
+		return nil, errors.Wrap(err, "could not write message")
+	}
+	header := make([]byte, 4)
+	if _, err := io.ReadFull(conn, header); err != nil {
+		return nil, errors.Wrap(err, "could not read message")
+	}
+	length := int(header[1])<<16 | int(header[2])<<8 | int(header[3])
+	if length > maxNetBIOSSize {
+		return nil, errors.New("message is too large")
+	}
+	data := make([]byte, length)
+	if _, err := io.ReadFull(conn, data); err != nil {
+		return nil, errors.Wrap(err, "could not read message")
+	}
+	return data, nil
+}
+
+// netBIOSMessage prepends the netbios session header to a message
+func netBIOSMessage(data []byte) []byte {
+	header := []byte{0, byte(len(data) >> 16), byte(len(data) >> 8), byte(len(data))}
+	return append(header, data...)
+}
+
+func appendNegotiateContext(b []byte, contextType uint16, data []byte) []byte {
+	b = appendUint16(b, contextType)
+	b = appendUint16(b, uint16(len(data)))
+	b = append(b, 0, 0, 0, 0)
+	return append(b, data...)
+}
+
+func appendUint16(b []byte, value uint16) []byte {
+	return append(b, byte(value), byte(value>>8))
+}
@@ -0,0 +1,93 @@
+package ssh
+
+import (
+	"bytes"
+	"encoding/binary"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// msgKexInit is the message number of the key exchange init message
+const msgKexInit = 20
+
+// kexInit contains the algorithms advertised by a server
+type kexInit struct {
+	kexAlgorithms     []string
+	hostKeyAlgorithms []string
+	ciphers           []string
+	macs              []string
+	compression       []string
+}
+
+// parseHandshake parses the identification line and the key exchange init
+// message from the first bytes sent by a server. The message is sent
+// before any encryption is negotiated.
+func parseHandshake(data []byte) (string, *kexInit, error) {
+	var banner string
+	for {
+		index := bytes.IndexByte(data, '\n')
+		if index < 0 {
+			return "", nil, errors.New("could not read identification")
+		}
+		line := strings.TrimRight(string(data[:index]), "\r")
+		data = data[index+1:]
+		// Servers may send other lines before the identification
+		if strings.HasPrefix(line, "SSH-") {
+			banner = line
+			break
+		}
+	}
+
+	if len(data) < 6 {
+		return banner, nil, errors.New("could not read key exchange init")
+	}
+	length := binary.BigEndian.Uint32(data)
+	padding := uint32(data[4])
+	if length < padding+1 || uint32(len(data)-4) < length {
+		return banner, nil, errors.New("invalid key exchange init packet")
+	}
+	payload := data[5 : 4+length-padding]
+	// The payload has the message number and a random cookie of 16 bytes
+	if len(payload) < 17 || payload[0] != msgKexInit {
+		return banner, nil, errors.New("invalid key exchange init message")
+	}
+	payload = payload[17:]
+
+	// kex, host key, ciphers and macs for each direction, and compression for each direction
+	lists := make([][]string, 8)
+	for i := range lists {
+		if len(payload) < 4 {
+			return banner, nil, errors.New("invalid key exchange init message")
+		}
+		size := binary.BigEndian.Uint32(payload)
+		if uint32(len(payload)-4) < size {
+			return banner, nil, errors.New("invalid key exchange init message")
+		}
+		if size > 0 {
+			lists[i] = strings.Split(string(payload[4:4+size]), ",")
+		}
+		payload = payload[4+size:]
+	}
+	return banner, &kexInit{
+		kexAlgorithms:     lists[0],
+		hostKeyAlgorithms: lists[1],
+		ciphers:           union(lists[2], lists[3]),
+		macs:              union(lists[4], lists[5]),
+		compression:       union(lists[6], lists[7]),
+	}, nil
+}
+
+// union returns the items of both lists without duplicates
+func union(a, b []string) []string {
+	seen := make(map[string]struct{}, len(a)+len(b))
+	var items []string
+	for _, item := range append(append([]string{}, a...), b...) {
+		if _, ok := seen[item]; ok {
+			continue
+		}
+		seen[item] = struct{}{}
+		items = append(items, item)
+	}
+	return items
+}
@@ -0,0 +1,48 @@
+package ssh
+
+import (
+	"encoding/binary"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseHandshake(t *testing.T) {
+	payload := []byte{msgKexInit}
+	payload = append(payload, make([]byte, 16)...)
+	for _, list := range []string{
+		"curve25519-sha256,diffie-hellman-group1-sha1",
+		"ssh-ed25519",
+		"aes128-ctr,3des-cbc", "aes128-ctr,arcfour",
+		"hmac-sha2-256", "hmac-md5",
+		"none", "none",
+		"", "",
+	} {
+		size := make([]byte, 4)
+		binary.BigEndian.PutUint32(size, uint32(len(list)))
+		payload = append(payload, size...)
+		payload = append(payload, list...)
+	}
+	payload = append(payload, 0, 0, 0, 0, 0)
+
+	padding := 4
+	packet := make([]byte, 5)
+	binary.BigEndian.PutUint32(packet, uint32(len(payload)+padding+1))
+	packet[4] = byte(padding)
+	packet = append(packet, payload...)
+	packet = append(packet, make([]byte, padding)...)
+
+	data := append([]byte("pre-banner line\r\nSSH-2.0-OpenSSH_7.4\r\n"), packet...)
+	banner, kexInit, err := parseHandshake(data)
+	require.Nil(t, err, "could not parse handshake")
+	require.Equal(t, "SSH-2.0-OpenSSH_7.4", banner, "could not get banner")
+	require.Equal(t, []string{"curve25519-sha256", "diffie-hellman-group1-sha1"}, kexInit.kexAlgorithms, "could not get kex algorithms")
+	require.Equal(t, "aes128-ctr 3des-cbc arcfour", strings.Join(kexInit.ciphers, " "), "could not get ciphers")
+	require.Equal(t, []string{"hmac-sha2-256", "hmac-md5"}, kexInit.macs, "could not get macs")
+	require.Equal(t, []string{"none"}, kexInit.compression, "could not get compression")
+
+	banner, _, err = parseHandshake([]byte("SSH-2.0-dropbear\r\n"))
+	require.NotNil(t, err, "could not detect missing key exchange init")
+	require.Equal(t, "SSH-2.0-dropbear", banner, "could not get banner without key exchange init")
+}
@@ -0,0 +1,202 @@
+package ssh
+
+import (
+	"bytes"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/projectdiscovery/gologger"
+	"github.com/projectdiscovery/nuclei/v2/pkg/output"
+	"github.com/projectdiscovery/nuclei/v2/pkg/protocols"
+	"github.com/projectdiscovery/nuclei/v2/pkg/protocols/common/auditlog"
+	"github.com/projectdiscovery/nuclei/v2/pkg/protocols/common/generators"
+	"github.com/projectdiscovery/nuclei/v2/pkg/protocols/common/protocolstate"
+	"github.com/projectdiscovery/nuclei/v2/pkg/protocols/common/replacer"
+	"golang.org/x/crypto/ssh"
+)
+
+var _ protocols.Request = &Request{}
+
+// maxRecordSize is the maximum number of bytes recorded from the server
+const maxRecordSize = 64 * 1024
+
+// Algorithms supported by the client. The weak ones are included so that
+// the handshake succeeds with old servers.
+var (
+	keyExchanges      = []string{"curve25519-sha256@libssh.org", "ecdh-sha2-nistp256", "ecdh-sha2-nistp384", "ecdh-sha2-nistp521", "diffie-hellman-group14-sha1", "diffie-hellman-group1-sha1"}
+	ciphers           = []string{"aes128-gcm@openssh.com", "chacha20-poly1305@openssh.com", "aes128-ctr", "aes192-ctr", "aes256-ctr", "aes128-cbc", "3des-cbc", "arcfour256", "arcfour128", "arcfour"}
+	macs              = []string{"hmac-sha2-256-etm@openssh.com", "hmac-sha2-256", "hmac-sha1", "hmac-sha1-96"}
+	hostKeyAlgorithms = []string{ssh.KeyAlgoED25519, ssh.KeyAlgoECDSA256, ssh.KeyAlgoECDSA384, ssh.KeyAlgoECDSA521, ssh.KeyAlgoRSA, ssh.KeyAlgoDSA}
+)
+
+// recordingConn records the first bytes read from a connection
+type recordingConn struct {
+	net.Conn
+	buffer bytes.Buffer
+}
+
+func (c *recordingConn) Read(b []byte) (int, error) {
+	n, err := c.Conn.Read(b)
+	if n > 0 && c.buffer.Len() < maxRecordSize {
+		c.buffer.Write(b[:n])
+	}
+	return n, err
+}
+
+// handshake contains the information collected during a ssh handshake
+type handshake struct {
+	banner        string
+	authBanner    string
+	kexInit       *kexInit
+	authMethods   []string
+	authenticated bool
+	hostKey       ssh.PublicKey
+}
+
+// ExecuteWithResults executes the protocol requests and returns results instead of writing them.
+func (r *Request) ExecuteWithResults(input string, metadata, previous output.InternalEvent, callback protocols.OutputEventCallback) error {
+	hostname, inputPort := protocolstate.HostPort(input)
+
+	for _, addr := range r.addresses {
+		values := generators.MergeMaps(metadata, map[string]interface{}{"Hostname": hostname})
+		host := replacer.Replace(addr.host, values)
+		port := addr.port
+		if port == "" {
+			port = inputPort
+		}
+		if port == "" {
+			port = defaultPort
+		}
+		actualAddress := net.JoinHostPort(strings.Trim(host, "[]"), port)
+
+		if err := r.executeAddress(actualAddress, host, input, previous, callback); err != nil {
+			gologger.Verbose().Label("ERR").Msgf("Could not make ssh request for %s: %s\n", actualAddress, err)
+		}
+	}
+	return nil
+}
+
+// executeAddress executes the ssh handshake for an address
+func (r *Request) executeAddress(actualAddress, host, input string, previous output.InternalEvent, callback protocols.OutputEventCallback) error {
+	started := time.Now()
+	var conn net.Conn
+	err := r.retryPolicy.Do(func() error {
+		var dialErr error
+		conn, dialErr = protocolstate.Dial(r.options.RequestContext(), r.dialer, actualAddress, false)
+		return dialErr
+	})
+	if err != nil {
+		r.options.Output.Request(r.options.TemplateID, actualAddress, "ssh", err)
+		r.options.AuditLog.Log(r.options.TemplateID, "ssh", actualAddress, 0, 0, err)
+		r.options.Progress.IncrementFailedRequestsBy(1)
+		return errors.Wrap(err, "could not connect to server")
+	}
+	defer conn.Close()
+	_ = conn.SetDeadline(time.Now().Add(time.Duration(r.options.Options.Timeout) * time.Second))
+
+	counter := &auditlog.Counter{}
+	result, err := r.handshake(counter.Wrap(conn), actualAddress)
+	r.options.Output.Request(r.options.TemplateID, actualAddress, "ssh", err)
+	r.options.AuditLog.Log(r.options.TemplateID, "ssh", actualAddress, counter.Sent(), counter.Received(), err)
+	r.options.Progress.IncrementRequests()
+	if err != nil {
+		r.options.Progress.IncrementFailedRequestsBy(1)
+		return err
+	}
+	gologger.Verbose().Msgf("Sent SSH request to %s", actualAddress)
+
+	outputEvent := r.responseToDSLMap(result, input, actualAddress)
+	if r.options.Options.Debug || r.options.Options.DebugResponse {
+		gologger.Info().Str("address", actualAddress).Msgf("[%s] Dumped SSH handshake for %s", r.options.TemplateID, actualAddress)
+		gologger.Print().Msgf("%s", outputEvent["data"])
+	}
+	outputEvent["ip"] = r.dialer.GetDialedIP(host)
+	if outputEvent["ip"] == "" && protocolstate.Resolver != nil {
+		outputEvent["ip"] = protocolstate.ResolveIP(host)
+	}
+	for k, v := range previous {
+		outputEvent[k] = v
+	}
+
+	event := &output.InternalWrappedEvent{InternalEvent: outputEvent, Started: started}
+	if r.CompiledOperators != nil {
+		result, ok := r.CompiledOperators.Execute(outputEvent, r.Match, r.Extract)
+		if ok && result != nil {
+			event.OperatorsResult = result
+			event.Results = r.MakeResultEvent(event)
+		}
+	}
+	callback(event)
+	return nil
+}
+
+// handshake performs the ssh handshake on the connection, collecting the
+// advertised algorithms and probing the auth methods with empty credentials.
+func (r *Request) handshake(conn net.Conn, actualAddress string) (*handshake, error) {
+	result := &handshake{}
+	recordMethod := func(method string) {
+		for _, existing := range result.authMethods {
+			if existing == method {
+				return
+			}
+		}
+		result.authMethods = append(result.authMethods, method)
+	}
+
+	config := &ssh.ClientConfig{
+		User: r.Username,
+		Auth: []ssh.AuthMethod{
+			ssh.PublicKeysCallback(func() ([]ssh.Signer, error) {
+				recordMethod("publickey")
+				return nil, nil
+			}),
+			ssh.KeyboardInteractive(func(user, instruction string, questions []string, echos []bool) ([]string, error) {
+				recordMethod("keyboard-interactive")
+				return make([]string, len(questions)), nil
+			}),
+			ssh.PasswordCallback(func() (string, error) {
+				recordMethod("password")
+				return "", nil
+			}),
+		},
+		HostKeyCallback: func(hostname string, remote net.Addr, key ssh.PublicKey) error {
+			result.hostKey = key
+			return nil
+		},
+		BannerCallback: func(message string) error {
+			result.authBanner = message
+			return nil
+		},
+		HostKeyAlgorithms: hostKeyAlgorithms,
+	}
+	config.KeyExchanges = keyExchanges
+	config.Ciphers = ciphers
+	config.MACs = macs
+
+	recorder := &recordingConn{Conn: conn}
+	client, _, _, err := ssh.NewClientConn(recorder, actualAddress, config)
+	if err == nil {
+		result.authenticated = true
+		if len(result.authMethods) == 0 {
+			result.authMethods = append(result.authMethods, "none")
+		}
+		client.Close()
+	}
+
+	// The advertised algorithms are available even if no algorithms are
+	// supported by the client or the authentication failed.
+	var parseErr error
+	result.banner, result.kexInit, parseErr = parseHandshake(recorder.buffer.Bytes())
+	if result.banner == "" {
+		if err != nil {
+			return nil, errors.Wrap(err, "could not perform handshake")
+		}
+		return nil, errors.Wrap(parseErr, "could not parse handshake")
+	}
+	if result.kexInit == nil {
+		result.kexInit = &kexInit{}
+	}
+	return result, nil
+}
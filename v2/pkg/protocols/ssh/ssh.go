@@ -0,0 +1,92 @@
+package ssh
+
+import (
+	"net"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/projectdiscovery/fastdialer/fastdialer"
+	"github.com/projectdiscovery/nuclei/v2/pkg/operators"
+	"github.com/projectdiscovery/nuclei/v2/pkg/protocols"
+	"github.com/projectdiscovery/nuclei/v2/pkg/protocols/common/retry"
+	"github.com/projectdiscovery/nuclei/v2/pkg/protocols/network/networkclientpool"
+)
+
+// defaultPort is the port used for addresses without a port
+const defaultPort = "22"
+
+// defaultUsername is the username used for probing the auth methods
+const defaultUsername = "nuclei"
+
+// Request contains a SSH protocol request to be made from a template
+type Request struct {
+	ID string `yaml:"id"`
+
+	// Address is the address of the server. Port 22 is used if no port is provided.
+	Address   []string `yaml:"host"`
+	addresses []address
+	// Username is the user for which the supported auth methods are probed.
+	// The methods are probed with empty credentials.
+	Username string `yaml:"username"`
+	// Retries is the number of retries for the connection overriding the global value
+	Retries int `yaml:"retries"`
+
+	// Operators for the current request go here.
+	operators.Operators `yaml:",inline,omitempty"`
+	CompiledOperators   *operators.Operators
+
+	dialer      *fastdialer.Dialer
+	retryPolicy *retry.Policy
+	options     *protocols.ExecuterOptions
+}
+
+type address struct {
+	host string
+	port string
+}
+
+// GetID returns the unique ID of the request if any.
+func (r *Request) GetID() string {
+	return r.ID
+}
+
+// Compile compiles the protocol request for further execution.
+func (r *Request) Compile(options *protocols.ExecuterOptions) error {
+	r.addresses = r.addresses[:0]
+	for _, value := range r.Address {
+		addr := address{host: value}
+		if strings.Contains(value, ":") {
+			host, port, err := net.SplitHostPort(value)
+			if err != nil {
+				return errors.Wrap(err, "could not parse address")
+			}
+			addr.host, addr.port = host, port
+		}
+		r.addresses = append(r.addresses, addr)
+	}
+	if r.Username == "" {
+		r.Username = defaultUsername
+	}
+
+	client, err := networkclientpool.Get(options.Options, &networkclientpool.Configuration{})
+	if err != nil {
+		return errors.Wrap(err, "could not get network client")
+	}
+	r.dialer = client
+	r.retryPolicy = retry.NewPolicy(options.Options, r.Retries)
+
+	if len(r.Matchers) > 0 || len(r.Extractors) > 0 {
+		compiled := &r.Operators
+		if err := compiled.Compile(); err != nil {
+			return errors.Wrap(err, "could not compile operators")
+		}
+		r.CompiledOperators = compiled
+	}
+	r.options = options
+	return nil
+}
+
+// Requests returns the total number of requests the YAML rule will perform
+func (r *Request) Requests() int {
+	return len(r.Address)
+}
@@ -0,0 +1,30 @@
+package ssh
+
+import (
+	"testing"
+
+	"github.com/projectdiscovery/nuclei/v2/internal/testutils"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSSHCompileMake(t *testing.T) {
+	options := testutils.DefaultOptions
+
+	testutils.Init(options)
+	templateID := "testing-ssh"
+	request := &Request{
+		ID:      templateID,
+		Address: []string{"{{Hostname}}", "{{Hostname}}:2222"},
+	}
+	executerOpts := testutils.NewMockExecuterOptions(options, &testutils.TemplateInfo{
+		ID:   templateID,
+		Info: map[string]interface{}{"severity": "low", "name": "test"},
+	})
+	err := request.Compile(executerOpts)
+	require.Nil(t, err, "could not compile ssh request")
+
+	require.Equal(t, 2, len(request.addresses), "could not get correct number of input address")
+	require.Equal(t, "", request.addresses[0].port, "could not get default port for host")
+	require.Equal(t, "2222", request.addresses[1].port, "could not get correct port for host")
+	require.Equal(t, defaultUsername, request.Username, "could not get default username")
+}
@@ -0,0 +1,218 @@
+// Package enrichment annotates the findings of CVE templates with the EPSS
+// score of the CVE and its membership in the CISA KEV catalog.
+package enrichment
+
+import (
+	"compress/gzip"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/projectdiscovery/nuclei/v2/pkg/output"
+	"github.com/projectdiscovery/nuclei/v2/pkg/types"
+)
+
+var (
+	// epssURL is the url of the gzipped csv of the current EPSS scores
+	epssURL = "https://epss.cyentia.com/epss_scores-current.csv.gz"
+	// kevURL is the url of the CISA known exploited vulnerabilities catalog
+	kevURL = "https://www.cisa.gov/sites/default/files/feeds/known_exploited_vulnerabilities.json"
+)
+
+var cveRegex = regexp.MustCompile(`(?i)cve-\d{4}-\d{4,}`)
+
+// Entry is the enrichment data of a CVE
+type Entry struct {
+	EPSS       float64 `json:"epss,omitempty"`
+	Percentile float64 `json:"percentile,omitempty"`
+	KEV        bool    `json:"kev,omitempty"`
+}
+
+// Dataset contains the enrichment data of the CVEs keyed by uppercase id
+type Dataset struct {
+	entries map[string]*Entry
+}
+
+// Load loads a dataset from a json file
+func Load(file string) (*Dataset, error) {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not read enrichment dataset")
+	}
+	entries := make(map[string]*Entry)
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, errors.Wrap(err, "could not parse enrichment dataset")
+	}
+	return &Dataset{entries: entries}, nil
+}
+
+// Download downloads the EPSS scores and the KEV catalog and writes
+// the merged dataset to a json file.
+func Download(file string) error {
+	client := &http.Client{Timeout: 2 * time.Minute}
+	entries := make(map[string]*Entry)
+
+	if err := downloadEPSS(client, entries); err != nil {
+		return err
+	}
+	if err := downloadKEV(client, entries); err != nil {
+		return err
+	}
+	data, err := json.Marshal(entries)
+	if err != nil {
+		return errors.Wrap(err, "could not marshal enrichment dataset")
+	}
+	if err := os.MkdirAll(path.Dir(file), os.ModePerm); err != nil {
+		return errors.Wrap(err, "could not create enrichment dataset directory")
+	}
+	if err := ioutil.WriteFile(file, data, 0644); err != nil {
+		return errors.Wrap(err, "could not write enrichment dataset")
+	}
+	return nil
+}
+
+// Enrich annotates a finding with the enrichment data of the CVEs of its
+// template. The highest EPSS score is used for templates of several CVEs.
+func (d *Dataset) Enrich(event *output.ResultEvent) {
+	for _, cve := range eventCVEs(event) {
+		entry, ok := d.entries[cve]
+		if !ok {
+			continue
+		}
+		if entry.EPSS > event.EPSSScore {
+			event.EPSSScore, event.EPSSPercentile = entry.EPSS, entry.Percentile
+		}
+		if entry.KEV {
+			event.KEV = true
+		}
+	}
+}
+
+// eventCVEs returns the CVE ids found in the template id, tags and
+// classification of a finding.
+func eventCVEs(event *output.ResultEvent) []string {
+	values := []string{
+		event.TemplateID,
+		types.ToString(event.Info["tags"]),
+		types.ToString(types.ToStringMap(event.Info["classification"])["cve-id"]),
+	}
+	unique := make(map[string]struct{})
+	var cves []string
+	for _, value := range values {
+		for _, match := range cveRegex.FindAllString(value, -1) {
+			cve := strings.ToUpper(match)
+			if _, ok := unique[cve]; ok {
+				continue
+			}
+			unique[cve] = struct{}{}
+			cves = append(cves, cve)
+		}
+	}
+	return cves
+}
+
+// downloadEPSS downloads the EPSS scores into the entries
+func downloadEPSS(client *http.Client, entries map[string]*Entry) error {
+	body, err := fetch(client, epssURL)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+
+	reader, err := gzip.NewReader(body)
+	if err != nil {
+		return errors.Wrap(err, "could not decompress epss scores")
+	}
+	defer reader.Close()
+
+	csvReader := csv.NewReader(reader)
+	csvReader.Comment = '#'
+	csvReader.FieldsPerRecord = -1
+	for {
+		record, err := csvReader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return errors.Wrap(err, "could not parse epss scores")
+		}
+		if len(record) < 3 || !cveRegex.MatchString(record[0]) {
+			continue // header
+		}
+		epss, _ := strconv.ParseFloat(record[1], 64)
+		percentile, _ := strconv.ParseFloat(record[2], 64)
+		entry := getEntry(entries, record[0])
+		entry.EPSS, entry.Percentile = epss, percentile
+	}
+	return nil
+}
+
+// downloadKEV downloads the KEV catalog into the entries
+func downloadKEV(client *http.Client, entries map[string]*Entry) error {
+	body, err := fetch(client, kevURL)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+
+	catalog := struct {
+		Vulnerabilities []struct {
+			CVEID string `json:"cveID"`
+		} `json:"vulnerabilities"`
+	}{}
+	if err := json.NewDecoder(body).Decode(&catalog); err != nil {
+		return errors.Wrap(err, "could not parse kev catalog")
+	}
+	for _, vulnerability := range catalog.Vulnerabilities {
+		getEntry(entries, vulnerability.CVEID).KEV = true
+	}
+	return nil
+}
+
+func getEntry(entries map[string]*Entry, cve string) *Entry {
+	cve = strings.ToUpper(strings.TrimSpace(cve))
+	entry, ok := entries[cve]
+	if !ok {
+		entry = &Entry{}
+		entries[cve] = entry
+	}
+	return entry
+}
+
+func fetch(client *http.Client, URL string) (io.ReadCloser, error) {
+	resp, err := client.Get(URL)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not download %s", URL)
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, errors.Errorf("unexpected status code %d for %s", resp.StatusCode, URL)
+	}
+	return resp.Body, nil
+}
+
+// Writer is an output writer enriching the findings before writing them
+type Writer struct {
+	output.Writer
+	dataset *Dataset
+}
+
+// NewWriter creates a new writer enriching the findings with a dataset
+func NewWriter(writer output.Writer, dataset *Dataset) *Writer {
+	return &Writer{Writer: writer, dataset: dataset}
+}
+
+// Write enriches the event and writes it to the wrapped writer
+func (w *Writer) Write(event *output.ResultEvent) error {
+	w.dataset.Enrich(event)
+	return w.Writer.Write(event)
+}
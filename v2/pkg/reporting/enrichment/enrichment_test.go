@@ -0,0 +1,53 @@
+package enrichment
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/projectdiscovery/nuclei/v2/pkg/output"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDownloadEnrich(t *testing.T) {
+	epss := &bytes.Buffer{}
+	writer := gzip.NewWriter(epss)
+	_, _ = writer.Write([]byte("#model_version:v2023.03.01,score_date:2023-03-07T00:00:00+0000\ncve,epss,percentile\nCVE-2021-44228,0.97565,0.99996\nCVE-2020-0001,0.00043,0.07\n"))
+	writer.Close()
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/epss" {
+			_, _ = w.Write(epss.Bytes())
+			return
+		}
+		_, _ = w.Write([]byte(`{"vulnerabilities":[{"cveID":"CVE-2021-44228"}]}`))
+	}))
+	defer ts.Close()
+	epssURL, kevURL = ts.URL+"/epss", ts.URL+"/kev"
+
+	dir, err := ioutil.TempDir("", "nuclei-enrichment-*")
+	require.Nil(t, err, "could not create temporary directory")
+	defer os.RemoveAll(dir)
+
+	file := path.Join(dir, "enrichment.json")
+	err = Download(file)
+	require.Nil(t, err, "could not download dataset")
+	dataset, err := Load(file)
+	require.Nil(t, err, "could not load dataset")
+
+	event := &output.ResultEvent{TemplateID: "CVE-2021-44228", Info: map[string]interface{}{"tags": "cve,cve2021,cve-2020-0001"}}
+	dataset.Enrich(event)
+	require.Equal(t, 0.97565, event.EPSSScore, "could not enrich epss score")
+	require.Equal(t, 0.99996, event.EPSSPercentile, "could not enrich epss percentile")
+	require.True(t, event.KEV, "could not enrich kev membership")
+
+	event = &output.ResultEvent{TemplateID: "tech-detect", Info: map[string]interface{}{"tags": "tech"}}
+	dataset.Enrich(event)
+	require.Equal(t, float64(0), event.EPSSScore, "could enrich non cve finding")
+	require.False(t, event.KEV, "could enrich non cve finding")
+}
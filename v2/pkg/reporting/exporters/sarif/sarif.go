@@ -1,8 +1,11 @@
 package sarif
 
 import (
+	"bytes"
 	"crypto/sha1"
 	"encoding/hex"
+	"encoding/json"
+	"io"
 	"os"
 	"path"
 	"strings"
@@ -14,14 +17,19 @@ import (
 	"github.com/projectdiscovery/nuclei/v2/pkg/reporting/format"
 )
 
+// fingerprintKey is the key of the finding fingerprint in the partial
+// fingerprints of the results
+const fingerprintKey = "nucleiFingerprint/v1"
+
 // Exporter is an exporter for nuclei sarif output format.
 type Exporter struct {
 	sarif *sarif.Report
 	run   *sarif.Run
 	mutex *sync.Mutex
 
-	home    string
-	options *Options
+	home     string
+	options  *Options
+	metadata *output.ScanMetadata
 }
 
 // Options contains the configuration options for sarif exporter client
@@ -70,6 +78,11 @@ func (i *Exporter) Export(event *output.ResultEvent) error {
 		templateURL = "https://github.com/projectdiscovery/nuclei-templates"
 	}
 
+	fingerprint := event.Fingerprint
+	if fingerprint == "" {
+		fingerprint = output.Fingerprint(event)
+	}
+
 	var ruleDescription string
 	if d, ok := event.Info["description"]; ok {
 		ruleDescription = d.(string)
@@ -85,7 +98,8 @@ func (i *Exporter) Export(event *output.ResultEvent) error {
 		WithFullDescription(sarif.NewMultiformatMessageString(ruleDescription))
 	result := i.run.AddResult(templateID).
 		WithMessage(sarif.NewMessage().WithText(event.Host)).
-		WithLevel(sarifSeverity)
+		WithLevel(sarifSeverity).
+		WithPartialFingerPrints(map[string]interface{}{fingerprintKey: fingerprint})
 
 		// Also write file match metadata to file
 	if event.Type == "file" && (event.FileToIndexPosition != nil && len(event.FileToIndexPosition) > 0) {
@@ -139,5 +153,49 @@ func (i *Exporter) Close() error {
 		return errors.Wrap(err, "could not create sarif output file")
 	}
 	defer file.Close()
-	return i.sarif.Write(file)
+	if i.metadata == nil {
+		return i.sarif.Write(file)
+	}
+	return i.writeWithMetadata(file)
+}
+
+// SetScanMetadata sets the metadata of the scan written in the run properties
+func (i *Exporter) SetScanMetadata(metadata *output.ScanMetadata) {
+	i.mutex.Lock()
+	i.metadata = metadata
+	i.mutex.Unlock()
+}
+
+// writeWithMetadata writes the report with the scan metadata in the
+// property bag of the run.
+func (i *Exporter) writeWithMetadata(writer io.Writer) error {
+	buffer := &bytes.Buffer{}
+	if err := i.sarif.Write(buffer); err != nil {
+		return err
+	}
+	report := make(map[string]interface{})
+	if err := json.Unmarshal(buffer.Bytes(), &report); err != nil {
+		return errors.Wrap(err, "could not parse sarif report")
+	}
+	i.metadata.Finish()
+	if runs, ok := report["runs"].([]interface{}); ok {
+		for _, item := range runs {
+			run, ok := item.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			properties, ok := run["properties"].(map[string]interface{})
+			if !ok {
+				properties = make(map[string]interface{})
+			}
+			properties["scan_metadata"] = i.metadata
+			run["properties"] = properties
+		}
+	}
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return errors.Wrap(err, "could not marshal sarif report")
+	}
+	_, err = writer.Write(data)
+	return err
 }
@@ -0,0 +1,40 @@
+package sarif
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/projectdiscovery/nuclei/v2/pkg/output"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExportPartialFingerprints(t *testing.T) {
+	directory, err := ioutil.TempDir("", "sarif-*")
+	require.Nil(t, err, "could not create temporary directory")
+	defer os.RemoveAll(directory)
+
+	file := filepath.Join(directory, "report.sarif")
+	exporter, err := New(&Options{File: file})
+	require.Nil(t, err, "could not create sarif exporter")
+
+	event := &output.ResultEvent{TemplateID: "git-config", Host: "https://example.com", Matched: "https://example.com/.git/config", Info: map[string]interface{}{"name": "Git Config", "severity": "medium"}}
+	require.Nil(t, exporter.Export(event), "could not export event")
+	require.Nil(t, exporter.Close(), "could not close exporter")
+
+	data, err := ioutil.ReadFile(file)
+	require.Nil(t, err, "could not read sarif report")
+	var report struct {
+		Runs []struct {
+			Results []struct {
+				PartialFingerprints map[string]string `json:"partialFingerprints"`
+			} `json:"results"`
+		} `json:"runs"`
+	}
+	require.Nil(t, json.Unmarshal(data, &report), "could not decode sarif report")
+	require.Len(t, report.Runs, 1, "could not get sarif run")
+	require.Len(t, report.Runs[0].Results, 1, "could not get sarif result")
+	require.Equal(t, output.Fingerprint(event), report.Runs[0].Results[0].PartialFingerprints[fingerprintKey], "could not get partial fingerprint")
+}
@@ -0,0 +1,196 @@
+package syslog
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	jsoniter "github.com/json-iterator/go"
+	"github.com/pkg/errors"
+	"github.com/projectdiscovery/nuclei/v2/pkg/output"
+	"github.com/projectdiscovery/nuclei/v2/pkg/types"
+)
+
+// Exporter is an exporter sending findings to a syslog server in RFC5424 format.
+type Exporter struct {
+	options  *Options
+	hostname string
+	facility int
+
+	mutex *sync.Mutex
+	conn  net.Conn
+}
+
+// Options contains the configuration options for syslog exporter client
+type Options struct {
+	// Address is the host:port of the syslog server
+	Address string `yaml:"address"`
+	// Protocol is the transport protocol to use (udp, tcp or tls). Default is udp.
+	Protocol string `yaml:"protocol"`
+	// Facility is the syslog facility code for messages. Default is 16 (local0).
+	Facility *int `yaml:"facility"`
+	// AppName is the application name for messages. Default is nuclei.
+	AppName string `yaml:"app-name"`
+	// Hostname is the hostname for messages. Default is the system hostname.
+	Hostname string `yaml:"hostname"`
+	// InsecureSkipVerify disables certificate verification for tls protocol
+	InsecureSkipVerify bool `yaml:"insecure-skip-verify"`
+}
+
+// defaultFacility is the local0 syslog facility
+const defaultFacility = 16
+
+// structuredDataID is the SD-ID for the nuclei structured data element
+const structuredDataID = "nuclei@32473"
+
+// New creates a new syslog exporter integration client based on options.
+func New(options *Options) (*Exporter, error) {
+	if options.Address == "" {
+		return nil, errors.New("no syslog address specified")
+	}
+	if options.Protocol == "" {
+		options.Protocol = "udp"
+	}
+	if options.Protocol != "udp" && options.Protocol != "tcp" && options.Protocol != "tls" {
+		return nil, fmt.Errorf("unknown syslog protocol: %s", options.Protocol)
+	}
+	if options.AppName == "" {
+		options.AppName = "nuclei"
+	}
+	facility := defaultFacility
+	if options.Facility != nil {
+		facility = *options.Facility
+	}
+	if facility < 0 || facility > 23 {
+		return nil, fmt.Errorf("invalid syslog facility: %d", facility)
+	}
+	hostname := options.Hostname
+	if hostname == "" {
+		hostname, _ = os.Hostname()
+	}
+	if hostname == "" {
+		hostname = "-"
+	}
+
+	exporter := &Exporter{options: options, hostname: hostname, facility: facility, mutex: &sync.Mutex{}}
+	if err := exporter.connect(); err != nil {
+		return nil, errors.Wrap(err, "could not connect to syslog server")
+	}
+	return exporter, nil
+}
+
+// connect creates the connection to the syslog server
+func (i *Exporter) connect() error {
+	dialer := &net.Dialer{Timeout: 10 * time.Second}
+
+	var conn net.Conn
+	var err error
+	switch i.options.Protocol {
+	case "tls":
+		conn, err = tls.DialWithDialer(dialer, "tcp", i.options.Address, &tls.Config{InsecureSkipVerify: i.options.InsecureSkipVerify})
+	default:
+		conn, err = dialer.Dial(i.options.Protocol, i.options.Address)
+	}
+	if err != nil {
+		return err
+	}
+	i.conn = conn
+	return nil
+}
+
+// Export exports a passed result event to the syslog server
+func (i *Exporter) Export(event *output.ResultEvent) error {
+	message, err := i.format(event, time.Now())
+	if err != nil {
+		return errors.Wrap(err, "could not format syslog message")
+	}
+	// Stream transports use octet-counting framing (RFC6587)
+	if i.options.Protocol != "udp" {
+		message = append([]byte(strconv.Itoa(len(message))+" "), message...)
+	}
+
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+
+	if _, err := i.conn.Write(message); err != nil {
+		// Reconnect once on write errors for stream connections
+		if i.options.Protocol == "udp" {
+			return errors.Wrap(err, "could not write syslog message")
+		}
+		_ = i.conn.Close()
+		if err := i.connect(); err != nil {
+			return errors.Wrap(err, "could not reconnect to syslog server")
+		}
+		if _, err := i.conn.Write(message); err != nil {
+			return errors.Wrap(err, "could not write syslog message")
+		}
+	}
+	return nil
+}
+
+// format formats a result event as a RFC5424 syslog message
+func (i *Exporter) format(event *output.ResultEvent, timestamp time.Time) ([]byte, error) {
+	data, err := jsoniter.Marshal(event)
+	if err != nil {
+		return nil, err
+	}
+	severity := types.ToString(event.Info["severity"])
+	priority := i.facility*8 + syslogSeverity(severity)
+
+	builder := &strings.Builder{}
+	fmt.Fprintf(builder, "<%d>1 %s %s %s %d finding ", priority, timestamp.UTC().Format(time.RFC3339Nano), i.hostname, i.options.AppName, os.Getpid())
+	builder.WriteString("[")
+	builder.WriteString(structuredDataID)
+	writeParam(builder, "template", event.TemplateID)
+	writeParam(builder, "severity", severity)
+	writeParam(builder, "host", event.Host)
+	if event.MatcherName != "" {
+		writeParam(builder, "matcher", event.MatcherName)
+	}
+	builder.WriteString("] ")
+	builder.Write(data)
+	return []byte(builder.String()), nil
+}
+
+// writeParam writes an escaped structured data parameter
+func writeParam(builder *strings.Builder, name, value string) {
+	builder.WriteString(" ")
+	builder.WriteString(name)
+	builder.WriteString("=\"")
+	builder.WriteString(paramEscaper.Replace(value))
+	builder.WriteString("\"")
+}
+
+// paramEscaper escapes the characters not allowed in structured data values
+var paramEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, `]`, `\]`)
+
+// syslogSeverity returns the syslog severity code for a template severity
+func syslogSeverity(severity string) int {
+	switch strings.ToLower(severity) {
+	case "critical":
+		return 2
+	case "high":
+		return 3
+	case "medium":
+		return 4
+	case "low":
+		return 5
+	case "info":
+		return 6
+	default:
+		return 7
+	}
+}
+
+// Close closes the exporter after operation
+func (i *Exporter) Close() error {
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+
+	return i.conn.Close()
+}
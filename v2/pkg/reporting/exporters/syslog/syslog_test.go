@@ -0,0 +1,76 @@
+package syslog
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/projectdiscovery/nuclei/v2/pkg/output"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSyslogUDP(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.Nil(t, err, "could not listen on udp")
+	defer conn.Close()
+
+	exporter, err := New(&Options{Address: conn.LocalAddr().String(), Hostname: "scanner"})
+	require.Nil(t, err, "could not create syslog exporter")
+	defer exporter.Close()
+
+	event := &output.ResultEvent{TemplateID: "test", Host: "https://example.com", MatcherName: `a"b]`, Info: map[string]interface{}{"severity": "high"}}
+	err = exporter.Export(event)
+	require.Nil(t, err, "could not export event")
+
+	buffer := make([]byte, 4096)
+	_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	n, _, err := conn.ReadFrom(buffer)
+	require.Nil(t, err, "could not read syslog message")
+
+	message := string(buffer[:n])
+	require.True(t, strings.HasPrefix(message, "<131>1 "), "could not get correct priority")
+	require.Contains(t, message, " scanner nuclei ", "could not get hostname and app name")
+	require.Contains(t, message, `[nuclei@32473 template="test" severity="high" host="https://example.com" matcher="a\"b\]"]`, "could not get structured data")
+	require.Contains(t, message, `"templateID":"test"`, "could not get json message")
+}
+
+func TestSyslogTCPFraming(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.Nil(t, err, "could not listen on tcp")
+	defer listener.Close()
+
+	received := make(chan string, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		reader := bufio.NewReader(conn)
+		length, _ := reader.ReadString(' ')
+		size, _ := strconv.Atoi(strings.TrimSpace(length))
+		message := make([]byte, size)
+		_, _ = io.ReadFull(reader, message)
+		received <- string(message)
+	}()
+
+	exporter, err := New(&Options{Address: listener.Addr().String(), Protocol: "tcp"})
+	require.Nil(t, err, "could not create syslog exporter")
+	defer exporter.Close()
+
+	err = exporter.Export(&output.ResultEvent{TemplateID: "test", Info: map[string]interface{}{}})
+	require.Nil(t, err, "could not export event")
+
+	select {
+	case message := <-received:
+		require.True(t, strings.HasPrefix(message, "<135>1 "), "could not get framed message")
+		require.True(t, strings.HasSuffix(message, "}"), "could not get complete framed message")
+	case <-time.After(5 * time.Second):
+		t.Fatal("could not receive syslog message")
+	}
+}
@@ -21,7 +21,11 @@ func Summary(event *output.ResultEvent) string {
 	builder.WriteString("] ")
 	builder.WriteString(types.ToString(event.Info["name"]))
 	builder.WriteString(" found on ")
-	builder.WriteString(event.Host)
+	if len(event.AffectedHosts) > 1 {
+		builder.WriteString(fmt.Sprintf("%d hosts", len(event.AffectedHosts)))
+	} else {
+		builder.WriteString(event.Host)
+	}
 	data := builder.String()
 	return data
 }
@@ -42,13 +46,35 @@ func MarkdownDescription(event *output.ResultEvent) string {
 	builder.WriteString(event.Matched)
 	builder.WriteString("\n\n**Timestamp**: ")
 	builder.WriteString(event.Timestamp.Format("Mon Jan 2 15:04:05 -0700 MST 2006"))
+	if event.Fingerprint != "" {
+		builder.WriteString("\n\n**Fingerprint**: ")
+		builder.WriteString(event.Fingerprint)
+	}
+	if len(event.AffectedHosts) > 1 {
+		builder.WriteString("\n\n**Affected Hosts**:\n\n")
+		for _, host := range event.AffectedHosts {
+			builder.WriteString("- ")
+			builder.WriteString(host)
+			builder.WriteString("\n")
+		}
+	}
 	builder.WriteString("\n\n**Template Information**\n\n| Key | Value |\n|---|---|\n")
 	for k, v := range event.Info {
-		if k == "reference" {
+		if IsStructuredField(k) {
 			continue
 		}
 		builder.WriteString(fmt.Sprintf("| %s | %s |\n", k, v))
 	}
+	if impact := Impact(event); impact != "" {
+		builder.WriteString("\n**Impact**\n\n")
+		builder.WriteString(impact)
+		builder.WriteString("\n")
+	}
+	if remediation := Remediation(event); remediation != "" {
+		builder.WriteString("\n**Remediation**\n\n")
+		builder.WriteString(remediation)
+		builder.WriteString("\n")
+	}
 	if event.Request != "" {
 		builder.WriteString("\n**Request**\n\n```http\n")
 		builder.WriteString(event.Request)
@@ -113,25 +139,10 @@ func MarkdownDescription(event *output.ResultEvent) string {
 			builder.WriteString("\n```\n")
 		}
 	}
-	if d, ok := event.Info["reference"]; ok {
+	if references := References(event); len(references) > 0 {
 		builder.WriteString("\nReference: \n")
-
-		switch v := d.(type) {
-		case string:
-			if !strings.HasPrefix(v, "-") {
-				builder.WriteString("- ")
-			}
-			builder.WriteString(v)
-		case []interface{}:
-			slice := types.ToStringSlice(v)
-			for i, item := range slice {
-				builder.WriteString("- ")
-				builder.WriteString(item)
-				if len(slice)-1 != i {
-					builder.WriteString("\n")
-				}
-			}
-		}
+		builder.WriteString("- ")
+		builder.WriteString(strings.Join(references, "\n- "))
 	}
 
 	builder.WriteString("\n---\nGenerated by [Nuclei](https://github.com/projectdiscovery/nuclei)")
@@ -154,3 +165,56 @@ func GetMatchedTemplate(event *output.ResultEvent) string {
 	template := builder.String()
 	return template
 }
+
+// structuredFields are the template info fields written in their own
+// sections instead of the template information table.
+var structuredFields = map[string]struct{}{
+	"reference":   {},
+	"references":  {},
+	"impact":      {},
+	"remediation": {},
+}
+
+// IsStructuredField returns true if the info field has its own section in the descriptions
+func IsStructuredField(key string) bool {
+	_, ok := structuredFields[key]
+	return ok
+}
+
+// Impact returns the impact of the finding from the template info
+func Impact(event *output.ResultEvent) string {
+	return strings.TrimSpace(types.ToString(event.Info["impact"]))
+}
+
+// Remediation returns the remediation guidance of the finding from the template info
+func Remediation(event *output.ResultEvent) string {
+	return strings.TrimSpace(types.ToString(event.Info["remediation"]))
+}
+
+// References returns the unique references of the template info. Both the
+// reference and references fields are supported, as lists or one per line.
+func References(event *output.ResultEvent) []string {
+	var references []string
+	unique := make(map[string]struct{})
+	for _, key := range []string{"reference", "references"} {
+		var values []string
+		switch v := event.Info[key].(type) {
+		case string:
+			values = strings.Split(v, "\n")
+		case []interface{}:
+			values = types.ToStringSlice(v)
+		}
+		for _, value := range values {
+			value = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(value), "- "))
+			if value == "" {
+				continue
+			}
+			if _, ok := unique[value]; ok {
+				continue
+			}
+			unique[value] = struct{}{}
+			references = append(references, value)
+		}
+	}
+	return references
+}
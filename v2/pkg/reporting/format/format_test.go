@@ -0,0 +1,31 @@
+package format
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/projectdiscovery/nuclei/v2/pkg/output"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStructuredInfoFields(t *testing.T) {
+	event := &output.ResultEvent{
+		TemplateID: "exposed-panel",
+		Host:       "https://example.com",
+		Info: map[string]interface{}{
+			"name":        "Exposed Panel",
+			"severity":    "medium",
+			"impact":      "Attackers can log in with the default credentials.\n",
+			"remediation": "Change the default credentials.",
+			"reference":   "- https://example.com/advisory\n- https://example.com/docs",
+			"references":  []interface{}{"https://example.com/docs", "https://example.com/fix"},
+		},
+	}
+	require.Equal(t, "Attackers can log in with the default credentials.", Impact(event), "could not get impact")
+	require.Equal(t, "Change the default credentials.", Remediation(event), "could not get remediation")
+	require.Equal(t, []string{"https://example.com/advisory", "https://example.com/docs", "https://example.com/fix"}, References(event), "could not get references")
+
+	description := MarkdownDescription(event)
+	require.True(t, strings.Contains(description, "**Remediation**\n\nChange the default credentials."), "could not write remediation section")
+	require.False(t, strings.Contains(description, "| remediation |"), "could write remediation in info table")
+}
@@ -0,0 +1,56 @@
+package reporting
+
+import (
+	"sync"
+
+	"github.com/projectdiscovery/nuclei/v2/pkg/output"
+)
+
+// groups contains the findings grouped by template and matcher
+type groups struct {
+	mutex  sync.Mutex
+	order  []string
+	events map[string]*output.ResultEvent
+}
+
+func newGroups() *groups {
+	return &groups{events: make(map[string]*output.ResultEvent)}
+}
+
+// add adds the host of a finding to the group of its template. The first
+// finding of the template is used for the details of the group.
+func (g *groups) add(event *output.ResultEvent) {
+	key := event.TemplateID + ":" + event.MatcherName
+
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	grouped, ok := g.events[key]
+	if !ok {
+		copied := *event
+		copied.AffectedHosts = []string{event.Host}
+		g.events[key] = &copied
+		g.order = append(g.order, key)
+		return
+	}
+	for _, host := range grouped.AffectedHosts {
+		if host == event.Host {
+			return
+		}
+	}
+	grouped.AffectedHosts = append(grouped.AffectedHosts, event.Host)
+}
+
+// flush returns the grouped findings in the order they were first found
+func (g *groups) flush() []*output.ResultEvent {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	events := make([]*output.ResultEvent, 0, len(g.order))
+	for _, key := range g.order {
+		events = append(events, g.events[key])
+	}
+	g.order = nil
+	g.events = make(map[string]*output.ResultEvent)
+	return events
+}
@@ -0,0 +1,63 @@
+package reporting
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/projectdiscovery/nuclei/v2/pkg/output"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGroupsByTemplate(t *testing.T) {
+	groups := newGroups()
+	groups.add(&output.ResultEvent{TemplateID: "exposed-panel", Host: "https://a.example.com", Matched: "https://a.example.com/admin"})
+	groups.add(&output.ResultEvent{TemplateID: "git-config", Host: "https://a.example.com"})
+	groups.add(&output.ResultEvent{TemplateID: "exposed-panel", Host: "https://b.example.com"})
+	groups.add(&output.ResultEvent{TemplateID: "exposed-panel", Host: "https://a.example.com"})
+	groups.add(&output.ResultEvent{TemplateID: "exposed-panel", MatcherName: "version", Host: "https://c.example.com"})
+
+	events := groups.flush()
+	require.Len(t, events, 3, "could not group findings by template and matcher")
+	require.Equal(t, "exposed-panel", events[0].TemplateID, "could not keep order of groups")
+	require.Equal(t, []string{"https://a.example.com", "https://b.example.com"}, events[0].AffectedHosts, "could not get affected hosts")
+	require.Equal(t, "https://a.example.com/admin", events[0].Matched, "could not keep details of first finding")
+	require.Equal(t, []string{"https://c.example.com"}, events[2].AffectedHosts, "could not group by matcher")
+	require.Empty(t, groups.flush(), "could not reset groups")
+}
+
+type recordingExporter struct {
+	mutex  sync.Mutex
+	events []*output.ResultEvent
+}
+
+func (r *recordingExporter) Close() error { return nil }
+
+func (r *recordingExporter) Export(event *output.ResultEvent) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.events = append(r.events, event)
+	return nil
+}
+
+func (r *recordingExporter) exported() int {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return len(r.events)
+}
+
+func TestGroupsFlushPeriodically(t *testing.T) {
+	exporter := &recordingExporter{}
+	client := &Client{exporters: []Exporter{exporter}, groups: newGroups(), stop: make(chan struct{})}
+	client.flushWg.Add(1)
+	go client.flushLoop(10 * time.Millisecond)
+
+	client.groups.add(&output.ResultEvent{TemplateID: "exposed-panel", Host: "https://a.example.com"})
+	require.Eventually(t, func() bool { return exporter.exported() == 1 }, time.Second, 5*time.Millisecond, "could not flush grouped findings before close")
+
+	close(client.stop)
+	client.flushWg.Wait()
+	client.groups.add(&output.ResultEvent{TemplateID: "git-config", Host: "https://a.example.com"})
+	client.flushGroups()
+	require.Equal(t, 2, exporter.exported(), "could not flush remaining grouped findings")
+}
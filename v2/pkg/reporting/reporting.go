@@ -2,12 +2,16 @@ package reporting
 
 import (
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/pkg/errors"
+	"github.com/projectdiscovery/gologger"
 	"github.com/projectdiscovery/nuclei/v2/pkg/output"
 	"github.com/projectdiscovery/nuclei/v2/pkg/reporting/dedupe"
 	"github.com/projectdiscovery/nuclei/v2/pkg/reporting/exporters/disk"
 	"github.com/projectdiscovery/nuclei/v2/pkg/reporting/exporters/sarif"
+	"github.com/projectdiscovery/nuclei/v2/pkg/reporting/exporters/syslog"
 	"github.com/projectdiscovery/nuclei/v2/pkg/reporting/trackers/github"
 	"github.com/projectdiscovery/nuclei/v2/pkg/reporting/trackers/gitlab"
 	"github.com/projectdiscovery/nuclei/v2/pkg/reporting/trackers/jira"
@@ -31,8 +35,19 @@ type Options struct {
 	DiskExporter *disk.Options `yaml:"disk"`
 	// SarifExporter contains configuration options for Sarif Exporter Module
 	SarifExporter *sarif.Options `yaml:"sarif"`
+	// SyslogExporter contains configuration options for Syslog Exporter Module
+	SyslogExporter *syslog.Options `yaml:"syslog"`
+	// GroupByTemplate reports a single finding per template with the list of
+	// affected hosts instead of one per host.
+	GroupByTemplate bool `yaml:"group-by-template"`
+	// GroupFlushInterval is the number of seconds after which the grouped
+	// findings are reported, so they are not lost if the scan is interrupted.
+	GroupFlushInterval int `yaml:"group-flush-interval"`
 }
 
+// defaultGroupFlushInterval is the interval the grouped findings are reported at
+const defaultGroupFlushInterval = 5 * time.Minute
+
 // Filter filters the received event and decides whether to perform
 // reporting for it or not.
 type Filter struct {
@@ -88,12 +103,21 @@ type Exporter interface {
 	Export(event *output.ResultEvent) error
 }
 
+// MetadataExporter is an exporter embedding the metadata of the scan in its output
+type MetadataExporter interface {
+	// SetScanMetadata sets the metadata of the scan of the exported issues
+	SetScanMetadata(metadata *output.ScanMetadata)
+}
+
 // Client is a client for nuclei issue tracking module
 type Client struct {
 	trackers  []Tracker
 	exporters []Exporter
 	options   *Options
 	dedupe    *dedupe.Storage
+	groups    *groups
+	stop      chan struct{}
+	flushWg   sync.WaitGroup
 }
 
 // New creates a new nuclei issue tracker reporting client
@@ -106,6 +130,9 @@ func New(options *Options, db string) (*Client, error) {
 	}
 
 	client := &Client{options: options}
+	if options.GroupByTemplate {
+		client.groups = newGroups()
+	}
 	if options.Github != nil {
 		tracker, err := github.New(options.Github)
 		if err != nil {
@@ -141,22 +168,78 @@ func New(options *Options, db string) (*Client, error) {
 		}
 		client.exporters = append(client.exporters, exporter)
 	}
+	if options.SyslogExporter != nil {
+		exporter, err := syslog.New(options.SyslogExporter)
+		if err != nil {
+			return nil, errors.Wrap(err, "could not create exporting client")
+		}
+		client.exporters = append(client.exporters, exporter)
+	}
 	storage, err := dedupe.New(db)
 	if err != nil {
 		return nil, err
 	}
 	client.dedupe = storage
+
+	if client.groups != nil {
+		interval := defaultGroupFlushInterval
+		if options.GroupFlushInterval > 0 {
+			interval = time.Duration(options.GroupFlushInterval) * time.Second
+		}
+		client.stop = make(chan struct{})
+		client.flushWg.Add(1)
+		go client.flushLoop(interval)
+	}
 	return client, nil
 }
 
-// Close closes the issue tracker reporting client
+// flushLoop reports the grouped findings at each interval until the client is closed
+func (c *Client) flushLoop(interval time.Duration) {
+	defer c.flushWg.Done()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			c.flushGroups()
+		case <-c.stop:
+			return
+		}
+	}
+}
+
+// flushGroups reports the findings grouped since the last flush
+func (c *Client) flushGroups() {
+	for _, event := range c.groups.flush() {
+		if err := c.report(event); err != nil {
+			gologger.Warning().Msgf("Could not create grouped issue on tracker: %s", err)
+		}
+	}
+}
+
+// Close closes the issue tracker reporting client reporting the grouped findings if any
 func (c *Client) Close() {
+	if c.groups != nil {
+		close(c.stop)
+		c.flushWg.Wait()
+		c.flushGroups()
+	}
 	c.dedupe.Close()
 	for _, exporter := range c.exporters {
 		exporter.Close()
 	}
 }
 
+// SetScanMetadata sets the metadata of the scan on the exporters embedding it
+func (c *Client) SetScanMetadata(metadata *output.ScanMetadata) {
+	for _, exporter := range c.exporters {
+		if metadataExporter, ok := exporter.(MetadataExporter); ok {
+			metadataExporter.SetScanMetadata(metadata)
+		}
+	}
+}
+
 // CreateIssue creates an issue in the tracker
 func (c *Client) CreateIssue(event *output.ResultEvent) error {
 	if c.options.AllowList != nil && !c.options.AllowList.GetMatch(event) {
@@ -166,17 +249,33 @@ func (c *Client) CreateIssue(event *output.ResultEvent) error {
 		return nil
 	}
 
+	if event.Fingerprint == "" {
+		event.Fingerprint = output.Fingerprint(event)
+	}
 	unique, err := c.dedupe.Index(event)
 	if unique {
-		for _, tracker := range c.trackers {
-			if trackerErr := tracker.CreateIssue(event); trackerErr != nil {
-				err = multierr.Append(err, trackerErr)
-			}
-		}
-		for _, exporter := range c.exporters {
-			if exportErr := exporter.Export(event); exportErr != nil {
-				err = multierr.Append(err, exportErr)
-			}
+		if c.groups != nil {
+			c.groups.add(event)
+			return err
+		}
+		if reportErr := c.report(event); reportErr != nil {
+			err = multierr.Append(err, reportErr)
+		}
+	}
+	return err
+}
+
+// report creates the issue of the event in the trackers and exporters
+func (c *Client) report(event *output.ResultEvent) error {
+	var err error
+	for _, tracker := range c.trackers {
+		if trackerErr := tracker.CreateIssue(event); trackerErr != nil {
+			err = multierr.Append(err, trackerErr)
+		}
+	}
+	for _, exporter := range c.exporters {
+		if exportErr := exporter.Export(event); exportErr != nil {
+			err = multierr.Append(err, exportErr)
 		}
 	}
 	return err
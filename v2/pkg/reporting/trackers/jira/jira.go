@@ -108,13 +108,31 @@ func jiraFormatDescription(event *output.ResultEvent) string {
 	builder.WriteString(event.Matched)
 	builder.WriteString("\n\n*Timestamp*: ")
 	builder.WriteString(event.Timestamp.Format("Mon Jan 2 15:04:05 -0700 MST 2006"))
+	if len(event.AffectedHosts) > 1 {
+		builder.WriteString("\n\n*Affected Hosts*:\n\n")
+		for _, host := range event.AffectedHosts {
+			builder.WriteString("* ")
+			builder.WriteString(host)
+			builder.WriteString("\n")
+		}
+	}
 	builder.WriteString("\n\n*Template Information*\n\n| Key | Value |\n")
 	for k, v := range event.Info {
-		if k == "reference" {
+		if format.IsStructuredField(k) {
 			continue
 		}
 		builder.WriteString(fmt.Sprintf("| %s | %s |\n", k, v))
 	}
+	if impact := format.Impact(event); impact != "" {
+		builder.WriteString("\n*Impact*\n\n")
+		builder.WriteString(impact)
+		builder.WriteString("\n")
+	}
+	if remediation := format.Remediation(event); remediation != "" {
+		builder.WriteString("\n*Remediation*\n\n")
+		builder.WriteString(remediation)
+		builder.WriteString("\n")
+	}
 	builder.WriteString("\n*Request*\n\n{code}\n")
 	builder.WriteString(event.Request)
 	builder.WriteString("\n{code}\n\n*Response*\n\n{code}\n")
@@ -174,25 +192,10 @@ func jiraFormatDescription(event *output.ResultEvent) string {
 			builder.WriteString("\n{code}\n")
 		}
 	}
-	if d, ok := event.Info["reference"]; ok {
+	if references := format.References(event); len(references) > 0 {
 		builder.WriteString("\nReference: \n")
-
-		switch v := d.(type) {
-		case string:
-			if !strings.HasPrefix(v, "-") {
-				builder.WriteString("- ")
-			}
-			builder.WriteString(v)
-		case []interface{}:
-			slice := types.ToStringSlice(v)
-			for i, item := range slice {
-				builder.WriteString("- ")
-				builder.WriteString(item)
-				if len(slice)-1 != i {
-					builder.WriteString("\n")
-				}
-			}
-		}
+		builder.WriteString("- ")
+		builder.WriteString(strings.Join(references, "\n- "))
 	}
 	builder.WriteString("\n---\nGenerated by [Nuclei|https://github.com/projectdiscovery/nuclei]")
 	data := builder.String()
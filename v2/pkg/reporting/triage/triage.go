@@ -0,0 +1,148 @@
+// Package triage applies the rules of a local triage file to the findings
+// before they are written to the output and reported, overriding their
+// severity or suppressing accepted-risk findings until an expiry date.
+package triage
+
+import (
+	"io/ioutil"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/projectdiscovery/nuclei/v2/pkg/output"
+	"github.com/projectdiscovery/nuclei/v2/pkg/protocols/common/wafdetect"
+	"gopkg.in/yaml.v2"
+)
+
+// dateLayout is the layout of the expiry dates of the rules
+const dateLayout = "2006-01-02"
+
+// Rules contains the severity overrides and suppressions of the triage file
+type Rules struct {
+	Overrides    []*Override    `yaml:"overrides"`
+	Suppressions []*Suppression `yaml:"suppressions"`
+
+	now func() time.Time
+}
+
+// Override overrides the severity of the findings of a template
+type Override struct {
+	// Template is the id of the template, glob patterns are supported
+	Template string `yaml:"template"`
+	// Host restricts the rule to the findings of a host if not empty
+	Host string `yaml:"host"`
+	// Severity is the new severity of the findings
+	Severity string `yaml:"severity"`
+	// Reason is the justification of the override
+	Reason string `yaml:"reason"`
+}
+
+// Suppression suppresses the findings of a template
+type Suppression struct {
+	// Template is the id of the template, glob patterns are supported
+	Template string `yaml:"template"`
+	// Host restricts the rule to the findings of a host if not empty
+	Host string `yaml:"host"`
+	// Expires is the date (YYYY-MM-DD) after which the findings are reported again
+	Expires string `yaml:"expires"`
+	// Reason is the justification of the accepted risk
+	Reason string `yaml:"reason"`
+
+	expires time.Time
+}
+
+// New loads the triage rules from a file
+func New(file string) (*Rules, error) {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not read triage file")
+	}
+	rules := &Rules{now: time.Now}
+	if err := yaml.Unmarshal(data, rules); err != nil {
+		return nil, errors.Wrap(err, "could not parse triage file")
+	}
+
+	for _, override := range rules.Overrides {
+		if override.Template == "" || override.Severity == "" {
+			return nil, errors.New("severity override requires a template and a severity")
+		}
+	}
+	for _, suppression := range rules.Suppressions {
+		if suppression.Template == "" {
+			return nil, errors.New("suppression requires a template")
+		}
+		if suppression.Reason == "" {
+			return nil, errors.Errorf("no reason for suppression of %s", suppression.Template)
+		}
+		if suppression.Expires != "" {
+			expires, err := time.Parse(dateLayout, suppression.Expires)
+			if err != nil {
+				return nil, errors.Wrapf(err, "could not parse expiry date of suppression of %s", suppression.Template)
+			}
+			// Suppressions are valid for the whole day of the expiry date
+			suppression.expires = expires.AddDate(0, 0, 1)
+		}
+	}
+	return rules, nil
+}
+
+// Expired returns the suppressions whose expiry date has passed
+func (r *Rules) Expired() []*Suppression {
+	var expired []*Suppression
+	for _, suppression := range r.Suppressions {
+		if suppression.expired(r.now()) {
+			expired = append(expired, suppression)
+		}
+	}
+	return expired
+}
+
+// Apply applies the rules to a finding returning false if it is suppressed.
+// The severity of overridden findings is replaced in a copy of the info.
+func (r *Rules) Apply(event *output.ResultEvent) bool {
+	if r == nil {
+		return true
+	}
+	now := r.now()
+	for _, suppression := range r.Suppressions {
+		if !suppression.expired(now) && matches(suppression.Template, suppression.Host, event) {
+			return false
+		}
+	}
+	for _, override := range r.Overrides {
+		if !matches(override.Template, override.Host, event) {
+			continue
+		}
+		info := make(map[string]interface{}, len(event.Info))
+		for k, v := range event.Info {
+			info[k] = v
+		}
+		info["severity"] = override.Severity
+		event.Info = info
+		break
+	}
+	return true
+}
+
+func (s *Suppression) expired(now time.Time) bool {
+	return !s.expires.IsZero() && !now.Before(s.expires)
+}
+
+// matches returns true if the template and host patterns match the finding.
+// Hosts are matched against the input and its hostname.
+func matches(template, host string, event *output.ResultEvent) bool {
+	if matched, _ := path.Match(template, event.TemplateID); !matched {
+		return false
+	}
+	if host == "" {
+		return true
+	}
+	host = strings.ToLower(host)
+	for _, value := range []string{strings.ToLower(event.Host), wafdetect.HostKey(event.Host)} {
+		if matched, _ := path.Match(host, value); matched || host == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,66 @@
+package triage
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/projectdiscovery/nuclei/v2/pkg/output"
+	"github.com/stretchr/testify/require"
+)
+
+const testRules = `
+overrides:
+  - template: tech-*
+    severity: low
+    reason: inventory findings are tracked
+suppressions:
+  - template: git-config
+    host: internal.example.com
+    expires: 2021-06-30
+    reason: accepted risk until migration
+  - template: exposed-panel
+    expires: 2021-01-31
+    reason: expired acceptance
+`
+
+func TestRulesApply(t *testing.T) {
+	file, err := ioutil.TempFile("", "triage-*.yaml")
+	require.Nil(t, err, "could not create triage file")
+	defer os.Remove(file.Name())
+	_, _ = file.WriteString(testRules)
+	file.Close()
+
+	rules, err := New(file.Name())
+	require.Nil(t, err, "could not load triage rules")
+	rules.now = func() time.Time { return time.Date(2021, 6, 30, 12, 0, 0, 0, time.UTC) }
+
+	info := map[string]interface{}{"severity": "info"}
+	event := &output.ResultEvent{TemplateID: "tech-detect", Host: "https://example.com", Info: info}
+	require.True(t, rules.Apply(event), "could suppress overridden finding")
+	require.Equal(t, "low", event.Info["severity"], "could not override severity")
+	require.Equal(t, "info", info["severity"], "could modify the template info")
+
+	require.False(t, rules.Apply(&output.ResultEvent{TemplateID: "git-config", Host: "https://internal.example.com:8443"}), "could not suppress finding of host")
+	require.True(t, rules.Apply(&output.ResultEvent{TemplateID: "git-config", Host: "https://example.com"}), "could suppress finding of other host")
+	require.True(t, rules.Apply(&output.ResultEvent{TemplateID: "exposed-panel", Host: "https://example.com"}), "could apply expired suppression")
+
+	expired := rules.Expired()
+	require.Len(t, expired, 1, "could not get expired suppressions")
+	require.Equal(t, "exposed-panel", expired[0].Template, "could not get correct expired suppression")
+
+	rules.now = func() time.Time { return time.Date(2021, 7, 1, 0, 0, 0, 0, time.UTC) }
+	require.True(t, rules.Apply(&output.ResultEvent{TemplateID: "git-config", Host: "https://internal.example.com"}), "could apply suppression after expiry date")
+}
+
+func TestRulesRequireReason(t *testing.T) {
+	file, err := ioutil.TempFile("", "triage-*.yaml")
+	require.Nil(t, err, "could not create triage file")
+	defer os.Remove(file.Name())
+	_, _ = file.WriteString("suppressions:\n  - template: git-config\n")
+	file.Close()
+
+	_, err = New(file.Name())
+	require.NotNil(t, err, "could load suppression without reason")
+}
@@ -0,0 +1,171 @@
+// Package schedule implements parsing of cron expressions for scheduling
+// recurring scans.
+package schedule
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schedule is a parsed cron expression
+type Schedule struct {
+	minute     uint64
+	hour       uint64
+	dayOfMonth uint64
+	month      uint64
+	dayOfWeek  uint64
+
+	// dayOfMonthAny and dayOfWeekAny are true for unrestricted day fields
+	dayOfMonthAny bool
+	dayOfWeekAny  bool
+}
+
+// descriptors are the supported shorthand expressions
+var descriptors = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// field is the allowed range of a cron expression field
+type field struct {
+	name     string
+	min, max int
+}
+
+var fields = []field{
+	{name: "minute", min: 0, max: 59},
+	{name: "hour", min: 0, max: 23},
+	{name: "day of month", min: 1, max: 31},
+	{name: "month", min: 1, max: 12},
+	{name: "day of week", min: 0, max: 7},
+}
+
+// Parse parses a standard 5 field cron expression (minute, hour, day of
+// month, month and day of week) or one of the @daily style descriptors.
+func Parse(expression string) (*Schedule, error) {
+	expression = strings.TrimSpace(expression)
+	if descriptor, ok := descriptors[strings.ToLower(expression)]; ok {
+		expression = descriptor
+	}
+	parts := strings.Fields(expression)
+	if len(parts) != len(fields) {
+		return nil, fmt.Errorf("invalid cron expression %q: expected %d fields", expression, len(fields))
+	}
+
+	values := make([]uint64, len(fields))
+	for i, part := range parts {
+		value, err := parseField(part, fields[i])
+		if err != nil {
+			return nil, fmt.Errorf("invalid cron expression %q: %s", expression, err)
+		}
+		values[i] = value
+	}
+	// Sunday can be specified as both 0 and 7
+	if values[4]&(1<<7) != 0 {
+		values[4] |= 1
+	}
+	return &Schedule{
+		minute:        values[0],
+		hour:          values[1],
+		dayOfMonth:    values[2],
+		month:         values[3],
+		dayOfWeek:     values[4],
+		dayOfMonthAny: strings.HasPrefix(parts[2], "*"),
+		dayOfWeekAny:  strings.HasPrefix(parts[4], "*"),
+	}, nil
+}
+
+// parseField parses a comma separated list of values, ranges and steps
+// into a bitset of the matching values.
+func parseField(value string, f field) (uint64, error) {
+	var bits uint64
+	for _, item := range strings.Split(value, ",") {
+		step := 1
+		if index := strings.Index(item, "/"); index != -1 {
+			parsed, err := strconv.Atoi(item[index+1:])
+			if err != nil || parsed <= 0 {
+				return 0, fmt.Errorf("invalid step in %s field: %s", f.name, item)
+			}
+			step = parsed
+			item = item[:index]
+		}
+
+		start, end := f.min, f.max
+		switch {
+		case item == "*":
+		case strings.Contains(item, "-"):
+			parts := strings.SplitN(item, "-", 2)
+			var err error
+			if start, err = strconv.Atoi(parts[0]); err != nil {
+				return 0, fmt.Errorf("invalid range in %s field: %s", f.name, item)
+			}
+			if end, err = strconv.Atoi(parts[1]); err != nil {
+				return 0, fmt.Errorf("invalid range in %s field: %s", f.name, item)
+			}
+		default:
+			parsed, err := strconv.Atoi(item)
+			if err != nil {
+				return 0, fmt.Errorf("invalid value in %s field: %s", f.name, item)
+			}
+			start, end = parsed, parsed
+			// A single value with a step runs until the end of the range
+			if step > 1 {
+				end = f.max
+			}
+		}
+		if start < f.min || end > f.max || start > end {
+			return 0, fmt.Errorf("value out of range in %s field: %s", f.name, item)
+		}
+		for i := start; i <= end; i += step {
+			bits |= 1 << uint(i)
+		}
+	}
+	return bits, nil
+}
+
+// Next returns the next time after the provided time matching the schedule.
+// A zero time is returned if no matching time is found within five years.
+func (s *Schedule) Next(after time.Time) time.Time {
+	t := after.Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(5, 0, 0)
+
+	for t.Before(limit) {
+		if s.month&(1<<uint(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if !s.matchesDay(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if s.hour&(1<<uint(t.Hour())) == 0 {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+			continue
+		}
+		if s.minute&(1<<uint(t.Minute())) == 0 {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
+
+// matchesDay returns true if the day matches the schedule. If both day
+// fields are restricted, matching either of them is enough like in cron.
+func (s *Schedule) matchesDay(t time.Time) bool {
+	dayOfMonth := s.dayOfMonth&(1<<uint(t.Day())) != 0
+	dayOfWeek := s.dayOfWeek&(1<<uint(t.Weekday())) != 0
+
+	if !s.dayOfMonthAny && !s.dayOfWeekAny {
+		return dayOfMonth || dayOfWeek
+	}
+	return dayOfMonth && dayOfWeek
+}
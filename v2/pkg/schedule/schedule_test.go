@@ -0,0 +1,43 @@
+package schedule
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestScheduleNext(t *testing.T) {
+	base := time.Date(2021, 6, 15, 10, 30, 0, 0, time.UTC) // Tuesday
+
+	items := []struct {
+		expression string
+		expected   time.Time
+	}{
+		{expression: "0 2 * * *", expected: time.Date(2021, 6, 16, 2, 0, 0, 0, time.UTC)},
+		{expression: "*/15 * * * *", expected: time.Date(2021, 6, 15, 10, 45, 0, 0, time.UTC)},
+		{expression: "@hourly", expected: time.Date(2021, 6, 15, 11, 0, 0, 0, time.UTC)},
+		{expression: "0 0 1 1 *", expected: time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{expression: "30 9 * * 1-5", expected: time.Date(2021, 6, 16, 9, 30, 0, 0, time.UTC)},
+		{expression: "0 12 * * 7", expected: time.Date(2021, 6, 20, 12, 0, 0, 0, time.UTC)},
+		{expression: "0 0 20 * 1", expected: time.Date(2021, 6, 20, 0, 0, 0, 0, time.UTC)},
+		{expression: "0 8,20 * 6 *", expected: time.Date(2021, 6, 15, 20, 0, 0, 0, time.UTC)},
+		{expression: "0 0 29 2 *", expected: time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, item := range items {
+		schedule, err := Parse(item.expression)
+		require.Nil(t, err, "could not parse %s", item.expression)
+		require.Equal(t, item.expected, schedule.Next(base), "could not get next time for %s", item.expression)
+	}
+
+	schedule, err := Parse("0 0 31 2 *")
+	require.Nil(t, err, "could not parse impossible date")
+	require.True(t, schedule.Next(base).IsZero(), "could get next time for impossible date")
+}
+
+func TestScheduleParseErrors(t *testing.T) {
+	for _, expression := range []string{"", "* * * *", "60 * * * *", "* * 0 * *", "*/0 * * * *", "5-1 * * * *", "a * * * *"} {
+		_, err := Parse(expression)
+		require.NotNil(t, err, "could parse invalid expression %q", expression)
+	}
+}
@@ -9,8 +9,10 @@ import (
 
 	"github.com/pkg/errors"
 	"github.com/projectdiscovery/nuclei/v2/pkg/operators"
+	"github.com/projectdiscovery/nuclei/v2/pkg/output"
 	"github.com/projectdiscovery/nuclei/v2/pkg/protocols"
 	"github.com/projectdiscovery/nuclei/v2/pkg/protocols/common/executer"
+	"github.com/projectdiscovery/nuclei/v2/pkg/protocols/common/variables"
 	"github.com/projectdiscovery/nuclei/v2/pkg/protocols/offlinehttp"
 	"github.com/projectdiscovery/nuclei/v2/pkg/types"
 	"github.com/projectdiscovery/nuclei/v2/pkg/workflows"
@@ -20,8 +22,6 @@ import (
 // Parse parses a yaml request template file
 //nolint:gocritic // this cannot be passed by pointer
 func Parse(filePath string, options protocols.ExecuterOptions) (*Template, error) {
-	template := &Template{}
-
 	f, err := os.Open(filePath)
 	if err != nil {
 		return nil, err
@@ -33,42 +33,32 @@ func Parse(filePath string, options protocols.ExecuterOptions) (*Template, error
 		return nil, err
 	}
 
-	data = template.expandPreprocessors(data)
-	err = yaml.NewDecoder(bytes.NewReader(data)).Decode(template)
+	template, err := decodeTemplate(data)
 	if err != nil {
 		return nil, err
 	}
 
-	if _, ok := template.Info["name"]; !ok {
-		return nil, errors.New("no template name field provided")
-	}
-	if _, ok := template.Info["author"]; !ok {
-		return nil, errors.New("no template author field provided")
-	}
-	templateTags, ok := template.Info["tags"]
-	if !ok {
-		templateTags = ""
-	}
-	matchWithTags := false
-	if len(options.Options.Tags) > 0 {
-		if err := matchTemplateWithTags(types.ToString(templateTags), types.ToString(template.Info["severity"]), options.Options.Tags); err != nil {
-			return nil, fmt.Errorf("tags filter not matched %s", templateTags)
-		}
-		matchWithTags = true
+	if err := prepareInfo(template.Info, options.Options); err != nil {
+		return nil, err
 	}
-	if len(options.Options.ExcludeTags) > 0 && !matchWithTags {
-		if err := matchTemplateWithTags(types.ToString(templateTags), types.ToString(template.Info["severity"]), options.Options.ExcludeTags); err == nil {
-			return nil, fmt.Errorf("exclude-tags filter matched %s", templateTags)
-		}
+	if err := matchTemplateFilters(template.Info, template.Protocols(), options.Options); err != nil {
+		return nil, err
 	}
+	template.References = referencedVariables(data)
 
 	// Setting up variables regarding template metadata
 	options.TemplateID = template.ID
 	options.TemplateInfo = template.Info
 	options.TemplatePath = filePath
+	// Variables supplied by the caller (eg. the login variables of secrets) override the template ones
+	templateVariables := template.Variables
+	if options.Options.EnvironmentVariables {
+		templateVariables = templateVariables.ExpandEnv()
+	}
+	options.Variables = append(append(variables.Variable{}, templateVariables...), options.Variables...)
 
 	// If no requests, and it is also not a workflow, return error.
-	if len(template.RequestsDNS)+len(template.RequestsHTTP)+len(template.RequestsFile)+len(template.RequestsNetwork)+len(template.RequestsHeadless)+len(template.Workflows) == 0 {
+	if len(template.RequestsDNS)+len(template.RequestsHTTP)+len(template.RequestsFile)+len(template.RequestsNetwork)+len(template.RequestsMail)+len(template.RequestsSSH)+len(template.RequestsService)+len(template.RequestsHeadless)+len(template.Workflows) == 0 {
 		return nil, fmt.Errorf("no requests defined for %s", template.ID)
 	}
 
@@ -126,6 +116,24 @@ func Parse(filePath string, options protocols.ExecuterOptions) (*Template, error
 		}
 		template.Executer = executer.NewExecuter(requests, &options)
 	}
+	if len(template.RequestsMail) > 0 && !options.Options.OfflineHTTP {
+		for _, req := range template.RequestsMail {
+			requests = append(requests, req)
+		}
+		template.Executer = executer.NewExecuter(requests, &options)
+	}
+	if len(template.RequestsSSH) > 0 && !options.Options.OfflineHTTP {
+		for _, req := range template.RequestsSSH {
+			requests = append(requests, req)
+		}
+		template.Executer = executer.NewExecuter(requests, &options)
+	}
+	if len(template.RequestsService) > 0 && !options.Options.OfflineHTTP {
+		for _, req := range template.RequestsService {
+			requests = append(requests, req)
+		}
+		template.Executer = executer.NewExecuter(requests, &options)
+	}
 	if len(template.RequestsHeadless) > 0 && !options.Options.OfflineHTTP && options.Options.Headless {
 		for _, req := range template.RequestsHeadless {
 			requests = append(requests, req)
@@ -146,6 +154,137 @@ func Parse(filePath string, options protocols.ExecuterOptions) (*Template, error
 	return template, nil
 }
 
+// Header contains the fields of a template needed to select it before
+// the template is compiled.
+type Header struct {
+	// ID is the unique id of the template
+	ID string
+	// Info contains information about the template
+	Info map[string]interface{}
+	// Workflow is true if the template is a workflow
+	Workflow bool
+}
+
+// templateHeader is the yaml layout of a template header, the requests
+// are only counted and not decoded.
+type templateHeader struct {
+	ID               string                 `yaml:"id"`
+	Info             map[string]interface{} `yaml:"info"`
+	Workflows        []struct{}             `yaml:"workflows"`
+	RequestsHTTP     []struct{}             `yaml:"requests"`
+	RequestsDNS      []struct{}             `yaml:"dns"`
+	RequestsFile     []struct{}             `yaml:"file"`
+	RequestsNetwork  []struct{}             `yaml:"network"`
+	RequestsMail     []struct{}             `yaml:"mail"`
+	RequestsSSH      []struct{}             `yaml:"ssh"`
+	RequestsService  []struct{}             `yaml:"service"`
+	RequestsHeadless []struct{}             `yaml:"headless"`
+}
+
+// protocols returns the protocols of the requests in the template header
+func (h *templateHeader) protocols() []string {
+	var protocols []string
+	add := func(name string, count int) {
+		if count > 0 {
+			protocols = append(protocols, name)
+		}
+	}
+	add("http", len(h.RequestsHTTP))
+	add("dns", len(h.RequestsDNS))
+	add("file", len(h.RequestsFile))
+	add("network", len(h.RequestsNetwork))
+	add("mail", len(h.RequestsMail))
+	add("ssh", len(h.RequestsSSH))
+	add("service", len(h.RequestsService))
+	add("headless", len(h.RequestsHeadless))
+	return protocols
+}
+
+// ParseHeader parses the header of a yaml template file without compiling
+// its requests, returning an error if the template is excluded by the
+// tags or protocol filters.
+func ParseHeader(filePath string, options *types.Options) (*Header, error) {
+	data, err := ioutil.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+	migrated, err := Migrate(data)
+	if err != nil {
+		return nil, err
+	}
+	header := &templateHeader{}
+	if err := yaml.NewDecoder(bytes.NewReader(migrated)).Decode(header); err != nil {
+		return nil, err
+	}
+	if err := prepareInfo(header.Info, options); err != nil {
+		return nil, err
+	}
+	if err := matchTemplateFilters(header.Info, header.protocols(), options); err != nil {
+		return nil, err
+	}
+	return &Header{ID: header.ID, Info: header.Info, Workflow: len(header.Workflows) > 0}, nil
+}
+
+// prepareInfo validates, normalizes and localizes the template information
+func prepareInfo(info map[string]interface{}, options *types.Options) error {
+	if _, ok := info["name"]; !ok {
+		return errors.New("no template name field provided")
+	}
+	if _, ok := info["author"]; !ok {
+		return errors.New("no template author field provided")
+	}
+	if err := normalizeInfo(info); err != nil {
+		return err
+	}
+	localizeInfo(info, options.Lang)
+	return nil
+}
+
+// matchTemplateFilters returns an error if the template is excluded by the
+// tags or protocol filters.
+func matchTemplateFilters(info map[string]interface{}, protocols []string, options *types.Options) error {
+	templateTags, ok := info["tags"]
+	if !ok {
+		templateTags = ""
+	}
+	matchWithTags := false
+	if len(options.Tags) > 0 {
+		if err := matchTemplateWithTags(types.ToString(templateTags), types.ToString(info["severity"]), options.Tags); err != nil {
+			return fmt.Errorf("tags filter not matched %s", templateTags)
+		}
+		matchWithTags = true
+	}
+	if len(options.ExcludeTags) > 0 && !matchWithTags {
+		if err := matchTemplateWithTags(types.ToString(templateTags), types.ToString(info["severity"]), options.ExcludeTags); err == nil {
+			return fmt.Errorf("exclude-tags filter matched %s", templateTags)
+		}
+	}
+	return matchTemplateWithProtocols(protocols, options.Protocols, options.ExcludeProtocols)
+}
+
+// decodeTemplate decodes the template data
+func decodeTemplate(data []byte) (*Template, error) {
+	// Templates of older spec versions are migrated to the current one
+	migrated, err := Migrate(data)
+	if err != nil {
+		return nil, err
+	}
+	template := &Template{}
+	if err := yaml.NewDecoder(bytes.NewReader(migrated)).Decode(template); err != nil {
+		return nil, err
+	}
+	// Random auto-variables are generated for each request by http, dns and network
+	// protocols, other templates have them expanded once for the whole template.
+	if !template.hasPerRequestVariables() {
+		template = &Template{}
+		expanded := template.expandPreprocessors(migrated)
+		if err := yaml.NewDecoder(bytes.NewReader(expanded)).Decode(template); err != nil {
+			return nil, err
+		}
+	}
+	return template, nil
+}
+
 // compileWorkflow compiles the workflow for execution
 func (t *Template) compileWorkflow(options *protocols.ExecuterOptions, workflow *workflows.Workflow) error {
 	for _, workflow := range workflow.Workflows {
@@ -191,6 +330,23 @@ func (t *Template) parseWorkflowTemplate(workflow *workflows.WorkflowTemplate, o
 			RateLimiter:  options.RateLimiter,
 			IssuesClient: options.IssuesClient,
 			ProjectFile:  options.ProjectFile,
+			WafDetector:  options.WafDetector,
+
+			GlobalMatchers: options.GlobalMatchers,
+			Tracer:         options.Tracer,
+			ResponseStore:  options.ResponseStore,
+			Profiler:       options.Profiler,
+			InputRequests:  options.InputRequests,
+			AuthProvider:   options.AuthProvider,
+			CSRF:           options.CSRF,
+			Triage:         options.Triage,
+			AuditLog:       options.AuditLog,
+			ContextStore:   options.ContextStore,
+			CookieJar:      options.CookieJar,
+			Context:        options.Context,
+			ResultHook:     options.ResultHook,
+			// The findings of the workflow templates inherit the info of the workflow
+			Workflow: output.NewWorkflow(options.TemplateID, options.TemplateInfo),
 		}
 		template, err := Parse(path, opts)
 		if err != nil {
@@ -239,6 +395,60 @@ mainLoop:
 	return nil
 }
 
+// matchTemplateWithProtocols returns an error if any of the protocols of a
+// template is not allowed or is excluded by the protocol filters.
+func matchTemplateWithProtocols(protocols, allowed, excluded []string) error {
+	contains := func(values []string, protocol string) bool {
+		for _, value := range values {
+			for _, name := range strings.Split(value, ",") {
+				if strings.EqualFold(strings.TrimSpace(name), protocol) {
+					return true
+				}
+			}
+		}
+		return false
+	}
+	for _, protocol := range protocols {
+		if len(allowed) > 0 && !contains(allowed, protocol) {
+			return fmt.Errorf("protocol filter not matched %s", protocol)
+		}
+		if contains(excluded, protocol) {
+			return fmt.Errorf("exclude-protocol filter matched %s", protocol)
+		}
+	}
+	return nil
+}
+
+// ProtocolAllowed returns true if a protocol is allowed and not excluded
+// by the protocol filters.
+func ProtocolAllowed(protocol string, allowed, excluded []string) bool {
+	return matchTemplateWithProtocols([]string{protocol}, allowed, excluded) == nil
+}
+
+// ValidateProtocols returns an error if a protocol filter contains a
+// protocol not supported by the templates
+func ValidateProtocols(values []string) error {
+	for _, value := range values {
+		for _, name := range strings.Split(value, ",") {
+			name = strings.TrimSpace(name)
+			if name == "" {
+				continue
+			}
+			supported := false
+			for _, protocol := range SupportedProtocols {
+				if strings.EqualFold(name, protocol) {
+					supported = true
+					break
+				}
+			}
+			if !supported {
+				return fmt.Errorf("invalid protocol type %s (%s)", name, strings.Join(SupportedProtocols, ", "))
+			}
+		}
+	}
+	return nil
+}
+
 // getKeyValue returns key value pair for a data string
 func getKeyValue(data string) (key, value string) {
 	if strings.Contains(data, ":") {
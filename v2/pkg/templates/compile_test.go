@@ -1,8 +1,11 @@
 package templates
 
 import (
+	"io/ioutil"
+	"os"
 	"testing"
 
+	"github.com/projectdiscovery/nuclei/v2/pkg/types"
 	"github.com/stretchr/testify/require"
 )
 
@@ -39,3 +42,48 @@ func TestMatchTemplateWithTags(t *testing.T) {
 		require.NotNil(t, err, "could get value tag for blank severity")
 	})
 }
+
+func TestMatchTemplateWithProtocols(t *testing.T) {
+	err := matchTemplateWithProtocols([]string{"http"}, []string{"http,dns"}, nil)
+	require.Nil(t, err, "could not match allowed protocol")
+
+	err = matchTemplateWithProtocols([]string{"http", "headless"}, []string{"http", "dns"}, nil)
+	require.NotNil(t, err, "could match template with a protocol not allowed")
+
+	err = matchTemplateWithProtocols([]string{"headless"}, nil, []string{"headless,code"})
+	require.NotNil(t, err, "could match template with excluded protocol")
+
+	err = matchTemplateWithProtocols([]string{"dns"}, nil, []string{" HEADLESS"})
+	require.Nil(t, err, "could not match template without excluded protocol")
+}
+
+func TestParseHeader(t *testing.T) {
+	file, err := ioutil.TempFile("", "template-*.yaml")
+	require.Nil(t, err, "could not create temporary file")
+	defer os.Remove(file.Name())
+
+	_, _ = file.WriteString("id: panel\ninfo:\n  name: Panel\n  author: pdteam\n  severity: info\n  tags: panel\nrequests:\n  - method: GET\n    path:\n      - \"{{BaseURL}}/login\"\n")
+	file.Close()
+
+	header, err := ParseHeader(file.Name(), &types.Options{})
+	require.Nil(t, err, "could not parse template header")
+	require.Equal(t, "panel", header.ID, "could not get template id")
+	require.Equal(t, "Panel", header.Info["name"], "could not get template info")
+	require.False(t, header.Workflow, "could parse template as workflow")
+
+	_, err = ParseHeader(file.Name(), &types.Options{ExcludeProtocols: []string{"http"}})
+	require.NotNil(t, err, "could parse template header of excluded protocol")
+
+	_, err = ParseHeader(file.Name(), &types.Options{Tags: []string{"cve"}})
+	require.NotNil(t, err, "could parse template header without matching tags")
+}
+
+func TestValidateProtocols(t *testing.T) {
+	require.Nil(t, ValidateProtocols([]string{"http, DNS", "headless"}), "could not validate supported protocols")
+	require.NotNil(t, ValidateProtocols([]string{"http,code"}), "could validate unsupported protocol")
+}
+
+func TestReferencedVariables(t *testing.T) {
+	names := referencedVariables([]byte("path: '{{BaseURL}}/api?t={{base64(token)}}'\nbody: '{{ user_id }}'"))
+	require.Equal(t, map[string]struct{}{"BaseURL": {}, "base64": {}, "token": {}, "user_id": {}}, names, "could not get referenced variables")
+}
@@ -0,0 +1,153 @@
+package templates
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/projectdiscovery/nuclei/v2/pkg/types"
+)
+
+// confidenceRanks are the valid confidence levels of the templates in increasing order
+var confidenceRanks = map[string]int{"low": 0, "medium": 1, "high": 2}
+
+// severityRanks are the severities of the templates in increasing order
+var severityRanks = map[string]int{"info": 0, "low": 1, "medium": 2, "high": 3, "critical": 4}
+
+// rankedFields are the info fields compared by rank instead of value
+var rankedFields = map[string]map[string]int{
+	"confidence": confidenceRanks,
+	"severity":   severityRanks,
+}
+
+// conditionOperators are the supported operators, longest first
+var conditionOperators = []string{">=", "<=", "!=", "==", ">", "<", "="}
+
+// Condition is a condition on the info of the templates (ex. confidence>=medium)
+type Condition struct {
+	field    string
+	operator string
+	value    string
+}
+
+// ParseConditions parses a list of template conditions
+func ParseConditions(values []string) ([]*Condition, error) {
+	var conditions []*Condition
+	for _, value := range values {
+		condition, err := ParseCondition(value)
+		if err != nil {
+			return nil, err
+		}
+		conditions = append(conditions, condition)
+	}
+	return conditions, nil
+}
+
+// ParseCondition parses a template condition of the form <field><operator><value>.
+// Fields are info keys, with metadata keys addressed as metadata.<key>.
+func ParseCondition(value string) (*Condition, error) {
+	for _, operator := range conditionOperators {
+		index := strings.Index(value, operator)
+		if index == -1 {
+			continue
+		}
+		condition := &Condition{
+			field:    strings.ToLower(strings.TrimSpace(value[:index])),
+			operator: operator,
+			value:    strings.ToLower(strings.TrimSpace(value[index+len(operator):])),
+		}
+		if condition.operator == "==" {
+			condition.operator = "="
+		}
+		if condition.field == "" || condition.value == "" {
+			return nil, errors.Errorf("invalid template condition %s", value)
+		}
+		if ranks, ok := rankedFields[condition.field]; ok {
+			if _, ok := ranks[condition.value]; !ok {
+				return nil, errors.Errorf("invalid %s in template condition %s", condition.field, value)
+			}
+		}
+		return condition, nil
+	}
+	return nil, errors.Errorf("no operator in template condition %s", value)
+}
+
+// MatchConditions returns true if the template info matches all the conditions
+func MatchConditions(conditions []*Condition, info map[string]interface{}) bool {
+	for _, condition := range conditions {
+		if !condition.Match(info) {
+			return false
+		}
+	}
+	return true
+}
+
+// Match returns true if the template info matches the condition.
+// Templates without the field never match the condition.
+func (c *Condition) Match(info map[string]interface{}) bool {
+	value, ok := infoValue(info, c.field)
+	if !ok {
+		return false
+	}
+	actual := strings.ToLower(types.ToString(value))
+
+	if c.operator == "=" || c.operator == "!=" {
+		return (actual == c.value) == (c.operator == "=")
+	}
+	var left, right float64
+	if ranks, ok := rankedFields[c.field]; ok {
+		rank, ok := ranks[actual]
+		if !ok {
+			return false
+		}
+		left, right = float64(rank), float64(ranks[c.value])
+	} else {
+		var err error
+		if left, err = strconv.ParseFloat(actual, 64); err != nil {
+			return false
+		}
+		if right, err = strconv.ParseFloat(c.value, 64); err != nil {
+			return false
+		}
+	}
+	switch c.operator {
+	case ">=":
+		return left >= right
+	case "<=":
+		return left <= right
+	case ">":
+		return left > right
+	default:
+		return left < right
+	}
+}
+
+// infoValue returns the value of a field of the template info. Fields
+// missing in the info are looked up in the metadata of the template.
+func infoValue(info map[string]interface{}, field string) (interface{}, bool) {
+	if strings.HasPrefix(field, "metadata.") {
+		value, ok := types.ToStringMap(info["metadata"])[strings.TrimPrefix(field, "metadata.")]
+		return value, ok
+	}
+	if value, ok := info[field]; ok {
+		return value, true
+	}
+	value, ok := types.ToStringMap(info["metadata"])[field]
+	return value, ok
+}
+
+// normalizeInfo validates the confidence of the template info, converting the
+// metadata to a string map so it can be written in the json output.
+func normalizeInfo(info map[string]interface{}) error {
+	if metadata := types.ToStringMap(info["metadata"]); metadata != nil {
+		info["metadata"] = metadata
+	}
+	value, ok := infoValue(info, "confidence")
+	if !ok {
+		return nil
+	}
+	if _, ok := confidenceRanks[strings.ToLower(types.ToString(value))]; !ok {
+		return errors.Errorf("invalid template confidence %s", types.ToString(value))
+	}
+	return nil
+}
@@ -0,0 +1,42 @@
+package templates
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTemplateConditions(t *testing.T) {
+	info := map[string]interface{}{
+		"severity":   "high",
+		"confidence": "medium",
+		"metadata":   map[interface{}]interface{}{"verified": true, "max-requests": 3},
+	}
+	err := normalizeInfo(info)
+	require.Nil(t, err, "could not normalize info")
+
+	tests := map[string]bool{
+		"confidence>=medium":     true,
+		"confidence>medium":      false,
+		"confidence=low":         false,
+		"severity>=high":         true,
+		"severity<critical":      true,
+		"metadata.verified=true": true,
+		"verified!=true":         false,
+		"max-requests<=2":        false,
+		"missing=value":          false,
+	}
+	for value, expected := range tests {
+		condition, err := ParseCondition(value)
+		require.Nil(t, err, "could not parse condition %s", value)
+		require.Equal(t, expected, condition.Match(info), "could not match condition %s", value)
+	}
+
+	_, err = ParseCondition("confidence>=unknown")
+	require.NotNil(t, err, "could parse invalid confidence")
+	_, err = ParseCondition("confidence")
+	require.NotNil(t, err, "could parse condition without operator")
+
+	err = normalizeInfo(map[string]interface{}{"confidence": "maybe"})
+	require.NotNil(t, err, "could normalize invalid confidence")
+}
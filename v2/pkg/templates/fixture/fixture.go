@@ -0,0 +1,330 @@
+// Package fixture serves canned http and tcp responses, for the mock server
+// and the test fixtures shipped with templates as <template>_test.yaml files.
+package fixture
+
+import (
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httputil"
+	"os"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/projectdiscovery/gologger"
+	"github.com/projectdiscovery/nuclei/v2/pkg/catalog"
+	"gopkg.in/yaml.v2"
+)
+
+// Response is a canned response served for the requests matching
+// its method, path and request content.
+type Response struct {
+	// Method restricts the response to a request method if not empty
+	Method string `yaml:"method"`
+	// Path restricts the response to a request path if not empty, glob patterns are supported
+	Path string `yaml:"path"`
+	// Request restricts the response to the requests containing the value if not empty.
+	// It is matched against the raw http request or the data sent over tcp.
+	Request string `yaml:"request"`
+	// Optional is true if the template is not expected to send the request of the response
+	Optional bool `yaml:"optional"`
+	// Status is the status code of the response, 200 by default
+	Status int `yaml:"status"`
+	// Headers are the headers of the response
+	Headers map[string]string `yaml:"headers"`
+	// Body is the body of the response, or the data sent back over tcp
+	Body string `yaml:"body"`
+}
+
+// Matches returns true if the response is served for a request
+func (r *Response) Matches(req *http.Request) bool {
+	if r.Method != "" && !strings.EqualFold(r.Method, req.Method) {
+		return false
+	}
+	if r.Request != "" {
+		dumped, err := httputil.DumpRequest(req, true)
+		if err != nil || !strings.Contains(string(dumped), r.Request) {
+			return false
+		}
+	}
+	if r.Path == "" {
+		return true
+	}
+	if r.Path == req.URL.Path || r.Path == req.URL.RequestURI() {
+		return true
+	}
+	matched, _ := path.Match(r.Path, req.URL.Path)
+	return matched
+}
+
+// String returns the request a response is served for
+func (r *Response) String() string {
+	var parts []string
+	if r.Method != "" {
+		parts = append(parts, strings.ToUpper(r.Method))
+	}
+	if r.Path != "" {
+		parts = append(parts, r.Path)
+	}
+	if r.Request != "" {
+		parts = append(parts, strconv.Quote(r.Request))
+	}
+	if len(parts) == 0 {
+		return "any request"
+	}
+	return strings.Join(parts, " ")
+}
+
+// connReadTimeout is the time to wait for the data of a tcp connection
+const connReadTimeout = 2 * time.Second
+
+// Server serves canned responses over http and tcp, recording the
+// responses served.
+type Server struct {
+	responses []*Response
+	mutex     sync.Mutex
+	served    map[*Response]struct{}
+}
+
+// NewServer returns a server for canned responses
+func NewServer(responses []*Response) *Server {
+	return &Server{responses: responses, served: make(map[*Response]struct{})}
+}
+
+// Handler returns a handler serving the first response matching each
+// request, or a 404 if none matches.
+func Handler(responses []*Response) http.Handler {
+	return NewServer(responses)
+}
+
+// ServeHTTP writes the first response matching a request
+func (s *Server) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	response := s.serve(func(response *Response) bool {
+		return response.Matches(req)
+	})
+	if response == nil {
+		http.NotFound(w, req)
+		return
+	}
+	for key, value := range response.Headers {
+		w.Header().Set(key, value)
+	}
+	status := response.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	w.WriteHeader(status)
+	_, _ = w.Write([]byte(response.Body))
+}
+
+// ServeConn writes the body of the first response matching the data of
+// each read of a tcp connection, until no response matches.
+func (s *Server) ServeConn(conn net.Conn) {
+	defer conn.Close()
+
+	buffer := make([]byte, 4096)
+	for {
+		_ = conn.SetReadDeadline(time.Now().Add(connReadTimeout))
+		n, err := conn.Read(buffer)
+		if n == 0 && err != nil {
+			return
+		}
+		data := string(buffer[:n])
+		response := s.serve(func(response *Response) bool {
+			return response.Request == "" || strings.Contains(data, response.Request)
+		})
+		if response == nil {
+			return
+		}
+		if _, err := conn.Write([]byte(response.Body)); err != nil {
+			return
+		}
+	}
+}
+
+// serve returns the first response matching a request and records it
+func (s *Server) serve(matches func(*Response) bool) *Response {
+	for _, response := range s.responses {
+		if !matches(response) {
+			continue
+		}
+		s.mutex.Lock()
+		s.served[response] = struct{}{}
+		s.mutex.Unlock()
+		return response
+	}
+	return nil
+}
+
+// Unserved returns the responses whose requests were not sent, except
+// the optional ones.
+func (s *Server) Unserved() []*Response {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	var unserved []*Response
+	for _, response := range s.responses {
+		if _, ok := s.served[response]; !ok && !response.Optional {
+			unserved = append(unserved, response)
+		}
+	}
+	return unserved
+}
+
+// LoadResponses loads the canned responses of a mock server from a file
+func LoadResponses(file string) ([]*Response, error) {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not read responses")
+	}
+	fixtures := struct {
+		Responses []*Response `yaml:"responses"`
+	}{}
+	if err := yaml.Unmarshal(data, &fixtures); err != nil {
+		return nil, errors.Wrap(err, "could not parse responses")
+	}
+	if len(fixtures.Responses) == 0 {
+		return nil, errors.New("no responses defined")
+	}
+	return fixtures.Responses, nil
+}
+
+// FileHandler returns a handler serving the responses of a file, reloaded
+// when the file is modified so they can be edited while serving them.
+func FileHandler(file string) (http.Handler, error) {
+	responses, err := LoadResponses(file)
+	if err != nil {
+		return nil, err
+	}
+	stat, err := os.Stat(file)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not stat responses")
+	}
+	return &fileHandler{file: file, modified: stat.ModTime(), handler: Handler(responses)}, nil
+}
+
+type fileHandler struct {
+	file     string
+	mutex    sync.Mutex
+	modified time.Time
+	handler  http.Handler
+}
+
+func (f *fileHandler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	f.mutex.Lock()
+	if stat, err := os.Stat(f.file); err == nil && stat.ModTime().After(f.modified) {
+		if responses, err := LoadResponses(f.file); err != nil {
+			gologger.Warning().Msgf("Could not reload responses: %s\n", err)
+		} else {
+			gologger.Info().Msgf("Reloaded %d responses from %s", len(responses), f.file)
+			f.handler = Handler(responses)
+		}
+		f.modified = stat.ModTime()
+	}
+	handler := f.handler
+	f.mutex.Unlock()
+
+	handler.ServeHTTP(w, req)
+}
+
+// Test is a test case of a template
+type Test struct {
+	// Name is the name of the test case
+	Name string `yaml:"name"`
+	// Responses are the responses served to the template
+	Responses []*Response `yaml:"responses"`
+	// Matched is true if the template is expected to match the responses
+	Matched bool `yaml:"matched"`
+	// Matchers are the expected outcomes of the named matchers of the template
+	Matchers map[string]bool `yaml:"matchers"`
+	// Extracted are the values the template is expected to extract
+	Extracted []string `yaml:"extracted"`
+}
+
+// Suite contains the test cases of a template
+type Suite struct {
+	Tests []*Test `yaml:"tests"`
+}
+
+// SuiteFile returns the path of the test fixtures of a template
+func SuiteFile(templatePath string) string {
+	return strings.TrimSuffix(templatePath, path.Ext(templatePath)) + catalog.TestSuffix
+}
+
+// LoadSuite loads the test fixtures of a template from a file
+func LoadSuite(file string) (*Suite, error) {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not read test fixtures")
+	}
+	suite := &Suite{}
+	if err := yaml.Unmarshal(data, suite); err != nil {
+		return nil, errors.Wrap(err, "could not parse test fixtures")
+	}
+	for i, test := range suite.Tests {
+		if test.Name == "" {
+			return nil, errors.Errorf("no name for test %d", i+1)
+		}
+		if len(test.Responses) == 0 {
+			return nil, errors.Errorf("no responses for test %s", test.Name)
+		}
+	}
+	return suite, nil
+}
+
+// Result is the outcome of a template run against a test case
+type Result struct {
+	// Matched is true if the template matched
+	Matched bool
+	// Extracted are the values extracted by the template
+	Extracted []string
+	// Matchers are the named matchers of the template and whether they matched
+	Matchers map[string]bool
+	// Unserved are the responses whose requests were not sent by the template
+	Unserved []*Response
+}
+
+// Check returns an error if the results of a test case are not the expected ones
+func (t *Test) Check(result *Result) error {
+	if result.Matched != t.Matched {
+		if t.Matched {
+			return errors.New("template did not match")
+		}
+		return errors.New("template matched")
+	}
+	if len(result.Unserved) > 0 {
+		return errors.Errorf("template did not send %s", result.Unserved[0])
+	}
+	names := make([]string, 0, len(t.Matchers))
+	for name := range t.Matchers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		matched, ok := result.Matchers[name]
+		if !ok {
+			return errors.Errorf("template has no matcher %s", name)
+		}
+		if expected := t.Matchers[name]; matched != expected {
+			if expected {
+				return errors.Errorf("matcher %s did not match", name)
+			}
+			return errors.Errorf("matcher %s matched", name)
+		}
+	}
+	values := make(map[string]struct{}, len(result.Extracted))
+	for _, value := range result.Extracted {
+		values[value] = struct{}{}
+	}
+	for _, value := range t.Extracted {
+		if _, ok := values[value]; !ok {
+			return errors.Errorf("template did not extract %s", value)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,97 @@
+package fixture
+
+import (
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandler(t *testing.T) {
+	server := httptest.NewServer(Handler([]*Response{
+		{Method: "POST", Path: "/login", Status: 302, Headers: map[string]string{"Location": "/admin"}},
+		{Path: "/api/*", Body: `{"version":"1.2.3"}`, Headers: map[string]string{"Content-Type": "application/json"}},
+		{Path: "/", Body: "welcome"},
+	}))
+	defer server.Close()
+
+	client := &http.Client{CheckRedirect: func(req *http.Request, via []*http.Request) error {
+		return http.ErrUseLastResponse
+	}}
+	for _, test := range []struct {
+		method string
+		path   string
+		status int
+		body   string
+	}{
+		{method: "POST", path: "/login", status: 302},
+		{method: "GET", path: "/login", status: 404, body: "404 page not found\n"},
+		{method: "GET", path: "/api/version", status: 200, body: `{"version":"1.2.3"}`},
+		{method: "GET", path: "/", status: 200, body: "welcome"},
+	} {
+		req, err := http.NewRequest(test.method, server.URL+test.path, nil)
+		require.Nil(t, err, "could not create request")
+		resp, err := client.Do(req)
+		require.Nil(t, err, "could not send request")
+		body, err := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		require.Nil(t, err, "could not read body")
+
+		require.Equal(t, test.status, resp.StatusCode, "could not get status of %s %s", test.method, test.path)
+		require.Equal(t, test.body, string(body), "could not get body of %s %s", test.method, test.path)
+	}
+}
+
+func TestCheck(t *testing.T) {
+	test := &Test{Name: "vulnerable", Matched: true, Extracted: []string{"1.2.3"}, Matchers: map[string]bool{"version": true, "patched": false}}
+	require.Nil(t, test.Check(&Result{Matched: true, Extracted: []string{"1.2.3", "other"}, Matchers: map[string]bool{"version": true, "patched": false}}), "could not check expected results")
+	require.NotNil(t, test.Check(&Result{Matched: false}), "could check missing match")
+	require.NotNil(t, test.Check(&Result{Matched: true, Extracted: []string{"1.2.4"}, Matchers: map[string]bool{"version": true, "patched": false}}), "could check missing extraction")
+	require.NotNil(t, test.Check(&Result{Matched: true, Extracted: []string{"1.2.3"}, Matchers: map[string]bool{"version": true, "patched": true}}), "could check unexpected matcher match")
+	require.NotNil(t, test.Check(&Result{Matched: true, Extracted: []string{"1.2.3"}, Matchers: map[string]bool{"version": true}}), "could check missing matcher")
+	require.NotNil(t, test.Check(&Result{Matched: true, Extracted: []string{"1.2.3"}, Matchers: map[string]bool{"version": true, "patched": false}, Unserved: []*Response{{Path: "/version"}}}), "could check unsent request")
+
+	test = &Test{Name: "patched"}
+	require.Nil(t, test.Check(&Result{}), "could not check expected non match")
+	require.NotNil(t, test.Check(&Result{Matched: true}), "could check unexpected match")
+}
+
+func TestServerUnserved(t *testing.T) {
+	login := &Response{Method: "POST", Path: "/login", Request: "username=admin"}
+	optional := &Response{Path: "/favicon.ico", Optional: true}
+	server := NewServer([]*Response{login, optional})
+	httpServer := httptest.NewServer(server)
+	defer httpServer.Close()
+
+	resp, err := http.Post(httpServer.URL+"/login", "application/x-www-form-urlencoded", strings.NewReader("username=guest"))
+	require.Nil(t, err, "could not send request")
+	resp.Body.Close()
+	require.Equal(t, []*Response{login}, server.Unserved(), "could serve response for other request content")
+
+	resp, err = http.Post(httpServer.URL+"/login", "application/x-www-form-urlencoded", strings.NewReader("username=admin"))
+	require.Nil(t, err, "could not send request")
+	resp.Body.Close()
+	require.Empty(t, server.Unserved(), "could not record served responses")
+}
+
+func TestServerConn(t *testing.T) {
+	server := NewServer([]*Response{{Request: "INFO", Body: "redis_version:6.0.9"}, {Body: "-ERR unknown command"}})
+	client, conn := net.Pipe()
+	go server.ServeConn(conn)
+	defer client.Close()
+
+	_, err := client.Write([]byte("INFO\r\n"))
+	require.Nil(t, err, "could not write data")
+	buffer := make([]byte, 64)
+	n, err := client.Read(buffer)
+	require.Nil(t, err, "could not read data")
+	require.Equal(t, "redis_version:6.0.9", string(buffer[:n]), "could not get response for data")
+}
+
+func TestSuiteFile(t *testing.T) {
+	require.Equal(t, "cves/CVE-2021-1234_test.yaml", SuiteFile("cves/CVE-2021-1234.yaml"), "could not get suite file")
+}
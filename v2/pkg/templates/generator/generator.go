@@ -0,0 +1,168 @@
+// Package generator writes skeleton templates for the supported protocols
+// to be completed by template authors.
+package generator
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/projectdiscovery/nuclei/v2/pkg/templates"
+	"gopkg.in/yaml.v2"
+)
+
+// Protocols are the protocols templates can be generated for
+var Protocols = []string{"http", "dns", "network", "file", "headless"}
+
+// MatcherTypes are the types of matchers templates can be generated with
+var MatcherTypes = []string{"word", "regex", "status", "size", "binary", "dsl"}
+
+// Severities are the severities of the templates
+var Severities = []string{"info", "low", "medium", "high", "critical"}
+
+// idRegex validates the ids of the templates
+var idRegex = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_.-]*$`)
+
+// requests are the skeleton requests of each protocol
+var requests = map[string]string{
+	"http": `requests:
+  - method: GET
+    path:
+      - "{{BaseURL}}"
+`,
+	"dns": `dns:
+  - name: "{{FQDN}}"
+    type: A
+    class: inet
+    recursion: true
+    retries: 3
+`,
+	"network": `network:
+  - host:
+      - "{{Hostname}}"
+    inputs:
+      - data: "PING\r\n"
+    read-size: 1024
+`,
+	"file": `file:
+  - extensions:
+      - all
+`,
+	"headless": `headless:
+  - steps:
+      - action: navigate
+        args:
+          url: "{{BaseURL}}"
+      - action: waitload
+`,
+}
+
+// dslParts are the response variables of each protocol used in dsl matchers
+var dslParts = map[string]string{
+	"http":     "body",
+	"dns":      "raw",
+	"network":  "data",
+	"file":     "raw",
+	"headless": "data",
+}
+
+// Options contains the answers used to generate a template
+type Options struct {
+	ID          string
+	Name        string
+	Author      string
+	Severity    string
+	Description string
+	Tags        []string
+	Protocol    string
+	MatcherType string
+}
+
+// Validate validates the options of a template
+func (o *Options) Validate() error {
+	if !idRegex.MatchString(o.ID) {
+		return errors.Errorf("invalid template id %s", o.ID)
+	}
+	if o.Name == "" || o.Author == "" {
+		return errors.New("template name and author are required")
+	}
+	if !contains(Severities, o.Severity) {
+		return errors.Errorf("invalid severity %s", o.Severity)
+	}
+	if !contains(Protocols, o.Protocol) {
+		return errors.Errorf("invalid protocol %s", o.Protocol)
+	}
+	if !contains(MatcherTypes, o.MatcherType) {
+		return errors.Errorf("invalid matcher type %s", o.MatcherType)
+	}
+	if o.MatcherType == "status" && o.Protocol != "http" {
+		return errors.New("status matchers are only supported by http templates")
+	}
+	return nil
+}
+
+// Generate returns the yaml of a skeleton template for the options
+func Generate(options *Options) ([]byte, error) {
+	if err := options.Validate(); err != nil {
+		return nil, err
+	}
+
+	info := yaml.MapSlice{
+		{Key: "name", Value: options.Name},
+		{Key: "author", Value: options.Author},
+		{Key: "severity", Value: options.Severity},
+	}
+	if options.Description != "" {
+		info = append(info, yaml.MapItem{Key: "description", Value: options.Description})
+	}
+	if len(options.Tags) > 0 {
+		info = append(info, yaml.MapItem{Key: "tags", Value: strings.Join(options.Tags, ",")})
+	}
+	header, err := yaml.Marshal(yaml.MapSlice{
+		{Key: "id", Value: options.ID},
+		{Key: "spec-version", Value: templates.CurrentSpecVersion},
+		{Key: "info", Value: info},
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "could not marshal template info")
+	}
+
+	buffer := &bytes.Buffer{}
+	buffer.Write(header)
+	buffer.WriteString("\n")
+	buffer.WriteString(requests[options.Protocol])
+	buffer.WriteString("    matchers:\n")
+	buffer.WriteString(matcher(options.Protocol, options.MatcherType))
+	return buffer.Bytes(), nil
+}
+
+// matcher returns the skeleton matcher of a type for a protocol
+func matcher(protocol, matcherType string) string {
+	var values string
+	switch matcherType {
+	case "word":
+		values = "        words:\n          - \"CHANGE_ME\"\n"
+	case "regex":
+		values = "        regex:\n          - \"CHANGE_ME\"\n"
+	case "status":
+		values = "        status:\n          - 200\n"
+	case "size":
+		values = "        size:\n          - 1024\n"
+	case "binary":
+		values = "        binary:\n          - \"4348414e47455f4d45\" # CHANGE_ME\n"
+	case "dsl":
+		values = fmt.Sprintf("        dsl:\n          - \"contains(%s, 'CHANGE_ME')\"\n", dslParts[protocol])
+	}
+	return "      - type: " + matcherType + "\n" + values
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
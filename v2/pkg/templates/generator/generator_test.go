@@ -0,0 +1,94 @@
+package generator
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/projectdiscovery/nuclei/v2/internal/testutils"
+	"github.com/projectdiscovery/nuclei/v2/pkg/templates"
+	"github.com/projectdiscovery/nuclei/v2/pkg/types"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v2"
+)
+
+func TestGenerate(t *testing.T) {
+	options := *testutils.DefaultOptions
+	options.Headless = true
+	testutils.Init(&options)
+
+	requestKeys := map[string]string{"http": "requests", "dns": "dns", "network": "network", "file": "file", "headless": "headless"}
+
+	for _, protocol := range Protocols {
+		for _, matcherType := range MatcherTypes {
+			if matcherType == "status" && protocol != "http" {
+				continue
+			}
+			data, err := Generate(&Options{
+				ID:          "internal-panel",
+				Name:        "Internal: Admin Panel",
+				Author:      "security-team",
+				Severity:    "medium",
+				Tags:        []string{"panel", "internal"},
+				Protocol:    protocol,
+				MatcherType: matcherType,
+			})
+			require.Nil(t, err, "could not generate %s template with %s matcher", protocol, matcherType)
+
+			template := struct {
+				ID   string            `yaml:"id"`
+				Info map[string]string `yaml:"info"`
+			}{}
+			require.Nil(t, yaml.Unmarshal(data, &template), "could not parse %s template", protocol)
+			require.Equal(t, "internal-panel", template.ID, "could not get template id")
+			require.Equal(t, "Internal: Admin Panel", template.Info["name"], "could not get template name")
+			require.Equal(t, "panel,internal", template.Info["tags"], "could not get template tags")
+
+			raw := make(map[string]interface{})
+			require.Nil(t, yaml.Unmarshal(data, &raw), "could not parse %s requests", protocol)
+			requests, ok := raw[requestKeys[protocol]].([]interface{})
+			require.True(t, ok, "could not get %s requests", protocol)
+			require.Len(t, requests, 1, "could not get %s request", protocol)
+			matchers, ok := requests[0].(map[interface{}]interface{})["matchers"].([]interface{})
+			require.True(t, ok, "could not get %s matchers", protocol)
+			require.Equal(t, matcherType, matchers[0].(map[interface{}]interface{})["type"], "could not get matcher type")
+
+			compiled := parseGenerated(t, data, &options)
+			require.Equal(t, 1, compiled.TotalRequests, "could not compile %s template with %s matcher", protocol, matcherType)
+		}
+	}
+}
+
+func TestGenerateInvalid(t *testing.T) {
+	valid := Options{ID: "test", Name: "Test", Author: "test", Severity: "info", Protocol: "http", MatcherType: "word"}
+
+	for name, modify := range map[string]func(*Options){
+		"id":       func(o *Options) { o.ID = "bad id" },
+		"author":   func(o *Options) { o.Author = "" },
+		"severity": func(o *Options) { o.Severity = "urgent" },
+		"protocol": func(o *Options) { o.Protocol = "smtp" },
+		"matcher":  func(o *Options) { o.MatcherType = "xpath" },
+		"status":   func(o *Options) { o.Protocol = "dns"; o.MatcherType = "status" },
+	} {
+		options := valid
+		modify(&options)
+		_, err := Generate(&options)
+		require.NotNil(t, err, "could generate template with invalid %s", name)
+	}
+}
+
+// parseGenerated parses a generated template like nuclei does when running it
+func parseGenerated(t *testing.T, data []byte, options *types.Options) *templates.Template {
+	file, err := ioutil.TempFile("", "template-*.yaml")
+	require.Nil(t, err, "could not create temporary file")
+	defer os.Remove(file.Name())
+
+	_, _ = file.Write(data)
+	file.Close()
+
+	executerOpts := testutils.NewMockExecuterOptions(options, &testutils.TemplateInfo{Path: file.Name()})
+	template, err := templates.Parse(file.Name(), *executerOpts)
+	require.Nil(t, err, "could not parse generated template")
+	require.NotNil(t, template, "could not get generated template")
+	return template
+}
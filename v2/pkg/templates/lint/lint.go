@@ -0,0 +1,293 @@
+// Package lint detects common pitfalls and style issues in templates
+// that are valid for the schema but unlikely to work as intended.
+package lint
+
+import (
+	"bufio"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/projectdiscovery/nuclei/v2/pkg/operators"
+	"github.com/projectdiscovery/nuclei/v2/pkg/templates"
+	"github.com/projectdiscovery/nuclei/v2/pkg/types"
+	"github.com/projectdiscovery/nuclei/v2/pkg/workflows"
+	"gopkg.in/yaml.v2"
+)
+
+// Levels of the issues
+const (
+	LevelError   = "error"
+	LevelWarning = "warning"
+)
+
+// MaxPayloadRequests is the number of payload combinations of a request
+// above which the payloads are reported as unbounded.
+var MaxPayloadRequests = 10000
+
+// severities are the valid severities of the templates
+var severities = map[string]struct{}{"info": {}, "low": {}, "medium": {}, "high": {}, "critical": {}}
+
+// alwaysMatchesProbe is matched with the regexes that match an empty response
+// to tell anchored empty matches from regexes matching any response.
+const alwaysMatchesProbe = "nuclei\nlint"
+
+// partMatchers are the types of matchers applied on a part of the response
+var partMatchers = map[string]struct{}{"word": {}, "regex": {}, "binary": {}}
+
+// payloadlessProtocols are the template sections of the protocols whose
+// requests don't support payloads and silently ignore them.
+var payloadlessProtocols = []string{"dns", "file", "network", "mail", "ssh", "service", "headless"}
+
+// Issue is an issue found in a template
+type Issue struct {
+	Path    string `json:"path"`
+	ID      string `json:"id,omitempty"`
+	Rule    string `json:"rule"`
+	Level   string `json:"level"`
+	Message string `json:"message"`
+}
+
+// Lint returns the issues found in a template file
+func Lint(templatePath string) ([]*Issue, error) {
+	data, err := ioutil.ReadFile(templatePath)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not read template")
+	}
+	template := &templates.Template{}
+	if err := yaml.Unmarshal(data, template); err != nil {
+		return nil, errors.Wrap(err, "could not parse template")
+	}
+
+	linter := &linter{path: templatePath, template: template}
+	if len(template.Workflows) > 0 {
+		linter.lintWorkflowInfo()
+		linter.lintWorkflows(template.Workflows)
+		return linter.issues, nil
+	}
+	linter.lintInfo()
+	for _, request := range linter.operators() {
+		linter.lintOperators(request)
+	}
+	for _, request := range template.RequestsHTTP {
+		linter.lintPayloads(request.Payloads, request.AttackType)
+	}
+	linter.lintUnsupportedPayloads(data)
+	return linter.issues, nil
+}
+
+// linter collects the issues of a template
+type linter struct {
+	path     string
+	template *templates.Template
+	issues   []*Issue
+}
+
+func (l *linter) report(rule, level, format string, args ...interface{}) {
+	l.issues = append(l.issues, &Issue{
+		Path:    l.path,
+		ID:      l.template.ID,
+		Rule:    rule,
+		Level:   level,
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+// lintInfo checks the severity and tags of the template
+func (l *linter) lintInfo() {
+	severity := strings.ToLower(types.ToString(l.template.Info["severity"]))
+	if severity == "" {
+		l.report("missing-severity", LevelError, "template has no severity")
+	} else if _, ok := severities[severity]; !ok {
+		l.report("invalid-severity", LevelError, "template has invalid severity %s", severity)
+	}
+	if len(types.ToStringSlice(l.template.Info["tags"])) == 0 {
+		l.report("missing-tags", LevelWarning, "template has no tags")
+	}
+}
+
+// lintWorkflowInfo checks the severity of a workflow, which is optional
+func (l *linter) lintWorkflowInfo() {
+	severity := strings.ToLower(types.ToString(l.template.Info["severity"]))
+	if _, ok := severities[severity]; severity != "" && !ok {
+		l.report("invalid-severity", LevelError, "workflow has invalid severity %s", severity)
+	}
+}
+
+// lintWorkflows checks that the workflow steps and their subtemplates
+// have a template to run.
+func (l *linter) lintWorkflows(steps []*workflows.WorkflowTemplate) {
+	for _, step := range steps {
+		if step.Template == "" {
+			l.report("missing-workflow-template", LevelError, "workflow step has no template")
+		}
+		l.lintWorkflows(step.Subtemplates)
+		for _, matcher := range step.Matchers {
+			if matcher.Name == "" && len(matcher.Names) == 0 {
+				l.report("missing-matcher-name", LevelError, "workflow matcher has no name and never runs its subtemplates")
+			}
+			l.lintWorkflows(matcher.Subtemplates)
+		}
+	}
+}
+
+// lintUnsupportedPayloads reports the payloads of the requests of the
+// protocols without payload support, which are ignored when decoding.
+func (l *linter) lintUnsupportedPayloads(data []byte) {
+	sections := make(map[string]interface{})
+	if err := yaml.Unmarshal(data, &sections); err != nil {
+		return
+	}
+	for _, protocol := range payloadlessProtocols {
+		requests, _ := sections[protocol].([]interface{})
+		for i, item := range requests {
+			request, ok := item.(map[interface{}]interface{})
+			if !ok {
+				continue
+			}
+			_, payloads := request["payloads"]
+			_, attack := request["attack"]
+			if payloads || attack {
+				l.report("unsupported-payloads", LevelError, "%s request %d has payloads which are only supported by http requests", protocol, i+1)
+			}
+		}
+	}
+}
+
+// operators returns the operators of the requests of all the protocols
+func (l *linter) operators() []*operators.Operators {
+	var all []*operators.Operators
+	for _, request := range l.template.RequestsHTTP {
+		all = append(all, &request.Operators)
+	}
+	for _, request := range l.template.RequestsDNS {
+		all = append(all, &request.Operators)
+	}
+	for _, request := range l.template.RequestsFile {
+		all = append(all, &request.Operators)
+	}
+	for _, request := range l.template.RequestsNetwork {
+		all = append(all, &request.Operators)
+	}
+	for _, request := range l.template.RequestsMail {
+		all = append(all, &request.Operators)
+	}
+	for _, request := range l.template.RequestsSSH {
+		all = append(all, &request.Operators)
+	}
+	for _, request := range l.template.RequestsService {
+		all = append(all, &request.Operators)
+	}
+	for _, request := range l.template.RequestsHeadless {
+		all = append(all, &request.Operators)
+	}
+	return all
+}
+
+// lintOperators checks the matchers of a request
+func (l *linter) lintOperators(request *operators.Operators) {
+	if len(request.Matchers) == 0 && len(request.Extractors) == 0 {
+		l.report("no-operators", LevelWarning, "request has no matchers or extractors and never produces results")
+	}
+	for i, matcher := range request.Matchers {
+		if _, ok := partMatchers[matcher.Type]; ok && matcher.Part == "" {
+			l.report("implicit-part", LevelWarning, "%s matcher %d has no part and is applied on the body", matcher.Type, i+1)
+		}
+		for _, expression := range matcher.Regex {
+			compiled, err := regexp.Compile(expression)
+			if err != nil {
+				l.report("invalid-regex", LevelError, "could not compile regex %s: %s", expression, err)
+				continue
+			}
+			if compiled.MatchString("") && compiled.MatchString(alwaysMatchesProbe) {
+				l.report("regex-always-matches", LevelError, "regex %s matches any response", expression)
+			}
+		}
+	}
+}
+
+// lintPayloads checks that the payload files exist and that the
+// number of payload combinations is bounded.
+func (l *linter) lintPayloads(payloads map[string]interface{}, attackType string) {
+	var counts []int
+	for name, payload := range payloads {
+		switch value := payload.(type) {
+		case string:
+			file, ok := l.resolvePayload(value)
+			if !ok {
+				l.report("payload-not-found", LevelError, "file %s of payload %s does not exist", value, name)
+				continue
+			}
+			if count, err := countLines(file); err == nil {
+				counts = append(counts, count)
+			}
+		case []interface{}:
+			counts = append(counts, len(value))
+		}
+	}
+	if len(counts) == 0 {
+		return
+	}
+
+	total := 0
+	switch attackType {
+	case "pitchfork":
+		for _, count := range counts {
+			if count > total {
+				total = count
+			}
+		}
+	case "clusterbomb":
+		total = 1
+		for _, count := range counts {
+			if total *= count; total > MaxPayloadRequests {
+				break
+			}
+		}
+	default:
+		for _, count := range counts {
+			total += count
+		}
+	}
+	if total > MaxPayloadRequests {
+		l.report("unbounded-payloads", LevelWarning, "payloads generate more than %d requests per target", MaxPayloadRequests)
+	}
+}
+
+// resolvePayload returns the path of a payload file, looking it up in the
+// working directory and then relative to the directories of the template.
+func (l *linter) resolvePayload(file string) (string, bool) {
+	if _, err := os.Stat(file); err == nil {
+		return file, true
+	}
+	tokens := strings.Split(l.path, "/")
+	for i := range tokens {
+		candidate := path.Join(strings.Join(tokens[:i], "/"), file)
+		if _, err := os.Stat(candidate); err == nil {
+			return candidate, true
+		}
+	}
+	return "", false
+}
+
+// countLines returns the number of non-empty lines of a payload file
+func countLines(file string) (int, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	count := 0
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if strings.TrimSpace(scanner.Text()) != "" {
+			count++
+		}
+	}
+	return count, scanner.Err()
+}
@@ -0,0 +1,134 @@
+package lint
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func lintTemplate(t *testing.T, content string) map[string]string {
+	dir, err := ioutil.TempDir("", "nuclei-lint-*")
+	require.Nil(t, err, "could not create temporary directory")
+	defer os.RemoveAll(dir)
+
+	file := filepath.Join(dir, "template.yaml")
+	require.Nil(t, ioutil.WriteFile(file, []byte(content), 0644), "could not write template")
+	require.Nil(t, ioutil.WriteFile(filepath.Join(dir, "users.txt"), []byte(strings.Repeat("user\n", 200)), 0644), "could not write payload")
+
+	issues, err := Lint(file)
+	require.Nil(t, err, "could not lint template")
+	rules := make(map[string]string)
+	for _, issue := range issues {
+		rules[issue.Rule] = issue.Level
+	}
+	return rules
+}
+
+func TestLintClean(t *testing.T) {
+	rules := lintTemplate(t, `id: clean
+info:
+  name: Clean
+  author: test
+  severity: low
+  tags: panel
+requests:
+  - method: GET
+    path:
+      - "{{BaseURL}}"
+    matchers:
+      - type: regex
+        part: body
+        regex:
+          - "^$"
+          - "admin[0-9]+"
+`)
+	require.Empty(t, rules, "could not lint clean template")
+}
+
+func TestLintIssues(t *testing.T) {
+	rules := lintTemplate(t, `id: issues
+info:
+  name: Issues
+  author: test
+requests:
+  - method: GET
+    path:
+      - "{{BaseURL}}/{{user}}/{{pass}}"
+    attack: clusterbomb
+    payloads:
+      user: users.txt
+      pass: users.txt
+    matchers:
+      - type: word
+        words:
+          - admin
+      - type: regex
+        part: body
+        regex:
+          - ".*"
+          - "(invalid"
+  - method: GET
+    path:
+      - "{{BaseURL}}/{{word}}"
+    payloads:
+      word: missing.txt
+dns:
+  - name: "{{FQDN}}.{{word}}"
+    payloads:
+      word: users.txt
+    matchers:
+      - type: word
+        part: answer
+        words:
+          - example
+`)
+	require.Equal(t, map[string]string{
+		"missing-severity":     LevelError,
+		"missing-tags":         LevelWarning,
+		"implicit-part":        LevelWarning,
+		"regex-always-matches": LevelError,
+		"invalid-regex":        LevelError,
+		"no-operators":         LevelWarning,
+		"payload-not-found":    LevelError,
+		"unbounded-payloads":   LevelWarning,
+		"unsupported-payloads": LevelError,
+	}, rules, "could not lint template issues")
+}
+
+func TestLintWorkflow(t *testing.T) {
+	rules := lintTemplate(t, `id: workflow
+info:
+  name: Workflow
+  author: test
+workflows:
+  - template: technologies/tech-detect.yaml
+    matchers:
+      - name: wordpress
+        subtemplates:
+          - template: cves/wordpress.yaml
+`)
+	require.Empty(t, rules, "could not lint valid workflow")
+
+	rules = lintTemplate(t, `id: workflow
+info:
+  name: Workflow
+  author: test
+  severity: severe
+workflows:
+  - template: technologies/tech-detect.yaml
+    subtemplates:
+      - tags: wordpress
+    matchers:
+      - subtemplates:
+          - template: cves/wordpress.yaml
+`)
+	require.Equal(t, map[string]string{
+		"invalid-severity":          LevelError,
+		"missing-workflow-template": LevelError,
+		"missing-matcher-name":      LevelError,
+	}, rules, "could not lint workflow issues")
+}
@@ -0,0 +1,25 @@
+package templates
+
+import "strings"
+
+// localizedFields are the display fields of the info having language variants
+var localizedFields = []string{"name", "description"}
+
+// localizeInfo replaces the display fields of the info with their variant for
+// a language (ex. name_zh for name when the language is zh). Classification
+// fields like severity and tags are never localized so filters keep working.
+func localizeInfo(info map[string]interface{}, lang string) {
+	lang = strings.ToLower(strings.TrimSpace(lang))
+	if lang == "" {
+		return
+	}
+	for _, field := range localizedFields {
+		key := field + "_" + lang
+		value, ok := info[key]
+		if !ok {
+			continue
+		}
+		info[field] = value
+		delete(info, key)
+	}
+}
@@ -0,0 +1,31 @@
+package templates
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocalizeInfo(t *testing.T) {
+	info := map[string]interface{}{
+		"name":           "Exposed Panel",
+		"name_zh":        "暴露的管理面板",
+		"description":    "The panel is exposed.",
+		"description_zh": "管理面板暴露在互联网上。",
+		"severity":       "medium",
+		"severity_zh":    "critical",
+		"tags_zh":        "面板",
+	}
+	localizeInfo(info, "ZH")
+	require.Equal(t, map[string]interface{}{
+		"name":        "暴露的管理面板",
+		"description": "管理面板暴露在互联网上。",
+		"severity":    "medium",
+		"severity_zh": "critical",
+		"tags_zh":     "面板",
+	}, info, "could not localize only the display fields of info")
+
+	info = map[string]interface{}{"name": "Exposed Panel", "name_zh": "暴露的管理面板"}
+	localizeInfo(info, "")
+	require.Equal(t, "Exposed Panel", info["name"], "could localize info without language")
+}
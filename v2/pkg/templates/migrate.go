@@ -0,0 +1,121 @@
+package templates
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strconv"
+
+	"github.com/pkg/errors"
+)
+
+// CurrentSpecVersion is the version of the template format supported by the engine.
+// Templates without a spec-version are version 1 templates.
+const CurrentSpecVersion = 2
+
+// specVersionRegex matches the spec-version field of a template
+var specVersionRegex = regexp.MustCompile(`(?m)^spec-version:[ \t]*(\S+)[ \t]*$`)
+
+// idRegex matches the id field of a template
+var idRegex = regexp.MustCompile(`(?m)^id:.*$`)
+
+// migration rewrites the deprecated fields of a spec version to the next version
+type migration struct {
+	// renames are the renamed keys of the version
+	renames []*keyRename
+}
+
+// itemRegex matches the prefix of the first item of a section
+var itemRegex = regexp.MustCompile(`(?m)^([ \t]*-[ \t]+)\S`)
+
+// keyRename renames a key of the items of a top-level section of a
+// template, preserving the indentation and comments of the template.
+type keyRename struct {
+	section *regexp.Regexp
+	from    string
+	to      string
+}
+
+func rename(section, from, to string) *keyRename {
+	return &keyRename{
+		section: regexp.MustCompile(`(?m)^` + regexp.QuoteMeta(section) + `:.*(?:\n(?:[ \t#-].*)?)*`),
+		from:    from,
+		to:      to,
+	}
+}
+
+// apply renames the key in the items of the section of a template. Only
+// the keys at the indentation of the items are renamed so nested values
+// like raw requests are left untouched.
+func (k *keyRename) apply(data []byte) []byte {
+	return k.section.ReplaceAllFunc(data, func(section []byte) []byte {
+		if !bytes.Contains(section, []byte(k.from+":")) {
+			return section
+		}
+		item := itemRegex.FindSubmatch(section)
+		if item == nil {
+			return section
+		}
+		prefix := string(item[1])
+		key := regexp.MustCompile(fmt.Sprintf(`(?m)^(%s|[ \t]{%d})%s:`, regexp.QuoteMeta(prefix), len(prefix), regexp.QuoteMeta(k.from)))
+		return key.ReplaceAll(section, []byte("${1}"+k.to+":"))
+	})
+}
+
+// migrations are the migrations of each spec version to the next one,
+// the first migration upgrading version 1 templates to version 2.
+var migrations = []*migration{
+	{renames: []*keyRename{
+		// http requests used a capitalized name and an underscore in the race count
+		rename("requests", "Name", "name"),
+		rename("requests", "race_count", "race-count"),
+	}},
+}
+
+// SpecVersion returns the spec version of a template
+func SpecVersion(data []byte) (int, error) {
+	match := specVersionRegex.FindSubmatch(data)
+	if match == nil {
+		return 1, nil
+	}
+	version, err := strconv.Atoi(string(match[1]))
+	if err != nil || version < 1 {
+		return 0, errors.Errorf("invalid spec-version %s", match[1])
+	}
+	return version, nil
+}
+
+// Migrate rewrites the deprecated fields of a template to the current spec
+// version, returning the template unmodified if it is already up to date.
+func Migrate(data []byte) ([]byte, error) {
+	version, err := SpecVersion(data)
+	if err != nil {
+		return nil, err
+	}
+	if version > CurrentSpecVersion {
+		return nil, errors.Errorf("template requires spec-version %d but %d is supported, update nuclei", version, CurrentSpecVersion)
+	}
+	for _, migration := range migrations[version-1:] {
+		for _, rename := range migration.renames {
+			data = rename.apply(data)
+		}
+	}
+	return data, nil
+}
+
+// SetSpecVersion sets the spec version of a template to the current version,
+// adding the field after the id of the template if it is missing.
+func SetSpecVersion(data []byte) []byte {
+	field := []byte("spec-version: " + strconv.Itoa(CurrentSpecVersion))
+	if specVersionRegex.Match(data) {
+		return specVersionRegex.ReplaceAll(data, field)
+	}
+	if location := idRegex.FindIndex(data); location != nil {
+		result := make([]byte, 0, len(data)+len(field)+1)
+		result = append(result, data[:location[1]]...)
+		result = append(result, '\n')
+		result = append(result, field...)
+		return append(result, data[location[1]:]...)
+	}
+	return append(append(field, '\n'), data...)
+}
@@ -0,0 +1,64 @@
+package templates
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const legacyTemplate = `id: race-condition
+info:
+  name: Race condition
+  author: test
+  severity: medium
+
+variables:
+  Name: "{{rand_base(5)}}"
+
+requests:
+  # single request sent in parallel
+  - Name: coupon
+    race: true
+    race_count: 10
+    raw:
+      - |
+        POST /coupon HTTP/1.1
+        Host: {{Hostname}}
+
+        Name: {{Name}}
+    matchers:
+      - type: word
+        words:
+          - applied`
+
+func TestMigrate(t *testing.T) {
+	version, err := SpecVersion([]byte(legacyTemplate))
+	require.Nil(t, err, "could not get spec version")
+	require.Equal(t, 1, version, "could not get default spec version")
+
+	migrated, err := Migrate([]byte(legacyTemplate))
+	require.Nil(t, err, "could not migrate template")
+	require.Contains(t, string(migrated), "  - name: coupon\n", "could not rename request name")
+	require.Contains(t, string(migrated), "    race-count: 10\n", "could not rename race count")
+	require.Contains(t, string(migrated), "variables:\n  Name:", "could rename variable outside of requests")
+	require.Contains(t, string(migrated), "  # single request sent in parallel\n", "could not keep comments")
+
+	updated := SetSpecVersion(migrated)
+	require.Contains(t, string(updated), "id: race-condition\nspec-version: 2\ninfo:", "could not add spec version")
+	version, err = SpecVersion(updated)
+	require.Nil(t, err, "could not get spec version")
+	require.Equal(t, CurrentSpecVersion, version, "could not get current spec version")
+
+	again, err := Migrate(updated)
+	require.Nil(t, err, "could not migrate current template")
+	require.Equal(t, string(updated), string(again), "could migrate current template")
+	require.Equal(t, string(updated), string(SetSpecVersion(updated)), "could change current spec version")
+}
+
+func TestMigrateUnsupportedVersion(t *testing.T) {
+	_, err := Migrate([]byte("id: test\nspec-version: 99\n"))
+	require.NotNil(t, err, "could migrate unsupported spec version")
+
+	_, err = Migrate([]byte("id: test\nspec-version: next\n"))
+	require.NotNil(t, err, "could migrate invalid spec version")
+}
@@ -10,6 +10,12 @@ import (
 
 var preprocessorRegex = regexp.MustCompile(`\{\{([a-z0-9_]+)\}\}`)
 
+// hasPerRequestVariables returns true if the template protocols support
+// random auto-variables generated per request.
+func (t *Template) hasPerRequestVariables() bool {
+	return len(t.RequestsHTTP)+len(t.RequestsDNS)+len(t.RequestsNetwork)+len(t.RequestsMail) > 0
+}
+
 // expandPreprocessors expands the pre-processors if any for a template data.
 func (t *Template) expandPreprocessors(data []byte) []byte {
 	foundMap := make(map[string]struct{})
@@ -0,0 +1,212 @@
+// Package schema generates the JSON Schema of the template format from
+// the yaml declarations of the templates, protocols and operators.
+package schema
+
+import (
+	"encoding/json"
+	"path"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/projectdiscovery/nuclei/v2/pkg/operators/extractors"
+	"github.com/projectdiscovery/nuclei/v2/pkg/operators/matchers"
+	"github.com/projectdiscovery/nuclei/v2/pkg/protocols/common/generators"
+	"github.com/projectdiscovery/nuclei/v2/pkg/protocols/common/variables"
+	"github.com/projectdiscovery/nuclei/v2/pkg/protocols/headless/engine"
+	"github.com/projectdiscovery/nuclei/v2/pkg/templates"
+)
+
+// schemaURI is the JSON Schema draft of the generated schema
+const schemaURI = "http://json-schema.org/draft-07/schema#"
+
+// severities are the severities of the templates
+var severities = []string{"info", "low", "medium", "high", "critical"}
+
+// enums are the allowed values of properties keyed by definition and property
+var enums = map[string][]string{
+	"matchers.Matcher.type":                  keys(matchers.MatcherTypes),
+	"matchers.Matcher.condition":             keys(matchers.ConditionTypes),
+	"operators.Operators.matchers-condition": keys(matchers.ConditionTypes),
+	"extractors.Extractor.type":              keys(extractors.ExtractorTypes),
+	"engine.Action.action":                   keys(engine.ActionStringToAction),
+	"http.Request.attack":                    keys(generators.StringToType),
+}
+
+// required are the required properties keyed by definition
+var required = map[string][]string{
+	"templates.Template":   {"id", "info"},
+	"matchers.Matcher":     {"type"},
+	"extractors.Extractor": {"type"},
+}
+
+// Generate returns the JSON Schema of the templates
+func Generate() ([]byte, error) {
+	g := &generator{definitions: make(map[string]interface{})}
+	root := g.schemaFor(reflect.TypeOf(templates.Template{}))
+
+	schema := map[string]interface{}{
+		"$schema":     schemaURI,
+		"title":       "nuclei template",
+		"$ref":        root["$ref"],
+		"definitions": g.definitions,
+	}
+	data, err := json.MarshalIndent(schema, "", "  ")
+	if err != nil {
+		return nil, errors.Wrap(err, "could not marshal template schema")
+	}
+	return data, nil
+}
+
+// generator generates the schemas of the types, collecting the
+// schemas of the structs as definitions.
+type generator struct {
+	definitions map[string]interface{}
+}
+
+// schemaFor returns the schema of a type, nil for unsupported types
+func (g *generator) schemaFor(t reflect.Type) map[string]interface{} {
+	if t == reflect.TypeOf(variables.Variable{}) {
+		// Variables are declared as an ordered mapping of names to values
+		return map[string]interface{}{"type": "object", "additionalProperties": map[string]interface{}{"type": "string"}}
+	}
+	switch t.Kind() {
+	case reflect.Ptr:
+		return g.schemaFor(t.Elem())
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	case reflect.Slice, reflect.Array:
+		items := g.schemaFor(t.Elem())
+		if items == nil {
+			return nil
+		}
+		return map[string]interface{}{"type": "array", "items": items}
+	case reflect.Map:
+		values := g.schemaFor(t.Elem())
+		if values == nil {
+			return nil
+		}
+		return map[string]interface{}{"type": "object", "additionalProperties": values}
+	case reflect.Interface:
+		return map[string]interface{}{}
+	case reflect.Struct:
+		return g.reference(t)
+	}
+	return nil
+}
+
+// reference returns a reference to the definition of a struct
+func (g *generator) reference(t reflect.Type) map[string]interface{} {
+	name := definitionName(t)
+	if _, ok := g.definitions[name]; !ok {
+		// The placeholder stops the recursion of self-referencing structs
+		g.definitions[name] = nil
+		properties := make(map[string]interface{})
+		g.addProperties(t, name, properties)
+
+		definition := map[string]interface{}{"type": "object", "properties": properties}
+		if fields, ok := required[name]; ok {
+			definition["required"] = fields
+		}
+		g.definitions[name] = definition
+	}
+	return map[string]interface{}{"$ref": "#/definitions/" + name}
+}
+
+// addProperties adds the yaml fields of a struct to the properties,
+// merging the fields of the inlined structs. Fields without yaml tag
+// are internal and not part of the template format.
+func (g *generator) addProperties(t reflect.Type, definition string, properties map[string]interface{}) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" || field.Tag.Get("jsonschema") == "-" {
+			continue
+		}
+		name, inline := yamlName(field)
+		if (name == "" && !inline) || name == "-" {
+			continue
+		}
+		if inline {
+			fieldType := field.Type
+			if fieldType.Kind() == reflect.Ptr {
+				fieldType = fieldType.Elem()
+			}
+			if fieldType.Kind() == reflect.Struct {
+				g.addProperties(fieldType, definitionName(fieldType), properties)
+			}
+			continue
+		}
+
+		var schema map[string]interface{}
+		if definition == "templates.Template" && name == "info" {
+			schema = infoSchema()
+		} else if schema = g.schemaFor(field.Type); schema == nil {
+			continue
+		}
+		if values, ok := enums[definition+"."+name]; ok {
+			schema["enum"] = values
+		}
+		properties[name] = schema
+	}
+}
+
+// infoSchema returns the schema of the info block of the templates
+func infoSchema() map[string]interface{} {
+	stringOrList := map[string]interface{}{
+		"anyOf": []interface{}{
+			map[string]interface{}{"type": "string"},
+			map[string]interface{}{"type": "array", "items": map[string]interface{}{"type": "string"}},
+		},
+	}
+	return map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"name":           map[string]interface{}{"type": "string"},
+			"author":         stringOrList,
+			"severity":       map[string]interface{}{"type": "string", "enum": severities},
+			"description":    map[string]interface{}{"type": "string"},
+			"tags":           stringOrList,
+			"reference":      stringOrList,
+			"remediation":    map[string]interface{}{"type": "string"},
+			"impact":         map[string]interface{}{"type": "string"},
+			"confidence":     map[string]interface{}{"type": "string", "enum": []string{"low", "medium", "high"}},
+			"classification": map[string]interface{}{"type": "object"},
+			"metadata":       map[string]interface{}{"type": "object"},
+		},
+		"required": []string{"name", "author", "severity"},
+	}
+}
+
+// yamlName returns the yaml name of a field and whether it is inlined,
+// an empty name for the fields without yaml name.
+func yamlName(field reflect.StructField) (string, bool) {
+	parts := strings.Split(field.Tag.Get("yaml"), ",")
+	for _, option := range parts[1:] {
+		if option == "inline" {
+			return "", true
+		}
+	}
+	return parts[0], false
+}
+
+// definitionName returns the name of the definition of a struct (ex. http.Request)
+func definitionName(t reflect.Type) string {
+	return path.Base(t.PkgPath()) + "." + t.Name()
+}
+
+func keys(values interface{}) []string {
+	var result []string
+	for _, key := range reflect.ValueOf(values).MapKeys() {
+		result = append(result, key.String())
+	}
+	sort.Strings(result)
+	return result
+}
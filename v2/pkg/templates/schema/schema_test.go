@@ -0,0 +1,65 @@
+package schema
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerate(t *testing.T) {
+	data, err := Generate()
+	require.Nil(t, err, "could not generate schema")
+
+	schema := struct {
+		Ref         string `json:"$ref"`
+		Definitions map[string]struct {
+			Properties map[string]struct {
+				Type string        `json:"type"`
+				Ref  string        `json:"$ref"`
+				Enum []interface{} `json:"enum"`
+			} `json:"properties"`
+			Required []string `json:"required"`
+		} `json:"definitions"`
+	}{}
+	require.Nil(t, json.Unmarshal(data, &schema), "could not parse schema")
+	require.Equal(t, "#/definitions/templates.Template", schema.Ref, "could not get root definition")
+
+	template := schema.Definitions["templates.Template"]
+	for _, property := range []string{"id", "info", "requests", "dns", "file", "network", "headless", "workflows", "variables"} {
+		require.Contains(t, template.Properties, property, "could not get template property %s", property)
+	}
+	require.NotContains(t, template.Properties, "compiledworkflow", "could get internal template property")
+	require.NotContains(t, template.Properties, "executer", "could get internal template property")
+	require.Equal(t, []string{"id", "info"}, template.Required, "could not get required template properties")
+
+	request := schema.Definitions["http.Request"]
+	require.Contains(t, request.Properties, "matchers", "could not get inlined operators")
+	require.Contains(t, request.Properties["attack"].Enum, "clusterbomb", "could not get attack types")
+
+	matcher := schema.Definitions["matchers.Matcher"]
+	require.Contains(t, matcher.Properties["type"].Enum, "word", "could not get matcher types")
+	require.Equal(t, "array", matcher.Properties["words"].Type, "could not get matcher words")
+	require.Contains(t, schema.Definitions, "extractors.Extractor", "could not get extractor definition")
+}
+
+func TestAddPropertiesYAMLTags(t *testing.T) {
+	type Inlined struct {
+		Inlined string `yaml:"inlined"`
+	}
+	type tagged struct {
+		Name     string `yaml:"name"`
+		Untagged string
+		Ignored  string `yaml:"-"`
+		Inlined  `yaml:",inline"`
+	}
+
+	g := &generator{definitions: make(map[string]interface{})}
+	properties := make(map[string]interface{})
+	g.addProperties(reflect.TypeOf(tagged{}), "schema.tagged", properties)
+
+	require.Contains(t, properties, "name", "could not get tagged property")
+	require.Contains(t, properties, "inlined", "could not get inlined property")
+	require.Len(t, properties, 2, "could get untagged or ignored properties")
+}
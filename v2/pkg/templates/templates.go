@@ -1,12 +1,20 @@
 package templates
 
 import (
+	"regexp"
+
+	"github.com/projectdiscovery/nuclei/v2/pkg/operators"
 	"github.com/projectdiscovery/nuclei/v2/pkg/protocols"
+	"github.com/projectdiscovery/nuclei/v2/pkg/protocols/common/globalmatchers"
+	"github.com/projectdiscovery/nuclei/v2/pkg/protocols/common/variables"
 	"github.com/projectdiscovery/nuclei/v2/pkg/protocols/dns"
 	"github.com/projectdiscovery/nuclei/v2/pkg/protocols/file"
 	"github.com/projectdiscovery/nuclei/v2/pkg/protocols/headless"
 	"github.com/projectdiscovery/nuclei/v2/pkg/protocols/http"
+	"github.com/projectdiscovery/nuclei/v2/pkg/protocols/mail"
 	"github.com/projectdiscovery/nuclei/v2/pkg/protocols/network"
+	"github.com/projectdiscovery/nuclei/v2/pkg/protocols/service"
+	"github.com/projectdiscovery/nuclei/v2/pkg/protocols/ssh"
 	"github.com/projectdiscovery/nuclei/v2/pkg/workflows"
 )
 
@@ -14,8 +22,20 @@ import (
 type Template struct {
 	// ID is the unique id for the template
 	ID string `yaml:"id"`
+	// SpecVersion is the version of the template format, templates of older
+	// versions are migrated when they are parsed.
+	SpecVersion int `yaml:"spec-version,omitempty" json:"spec-version,omitempty"`
 	// Info contains information about the template
 	Info map[string]interface{} `yaml:"info"`
+	// Requires are the ids of the templates which must match a target
+	// before the template runs on it.
+	Requires []string `yaml:"requires,omitempty" json:"requires,omitempty"`
+	// GlobalMatchers marks the template matchers to be evaluated on the
+	// responses of all other templates instead of sending its own requests.
+	GlobalMatchers bool `yaml:"global-matchers,omitempty"`
+	// Variables are the template variables usable in requests and matchers,
+	// with their values evaluated for each target.
+	Variables variables.Variable `yaml:"variables,omitempty" json:"variables,omitempty"`
 	// RequestsHTTP contains the http request to make in the template
 	RequestsHTTP []*http.Request `yaml:"requests,omitempty" json:"requests"`
 	// RequestsDNS contains the dns request to make in the template
@@ -24,6 +44,12 @@ type Template struct {
 	RequestsFile []*file.Request `yaml:"file,omitempty" json:"file"`
 	// RequestsNetwork contains the network request to make in the template
 	RequestsNetwork []*network.Request `yaml:"network,omitempty" json:"network"`
+	// RequestsMail contains the mail protocol request to make in the template
+	RequestsMail []*mail.Request `yaml:"mail,omitempty" json:"mail"`
+	// RequestsSSH contains the ssh request to make in the template
+	RequestsSSH []*ssh.Request `yaml:"ssh,omitempty" json:"ssh"`
+	// RequestsService contains the rdp, smb and ldap service request to make in the template
+	RequestsService []*service.Request `yaml:"service,omitempty" json:"service"`
 	// RequestsHeadless contains the headless request to make in the template.
 	RequestsHeadless []*headless.Request `yaml:"headless,omitempty" json:"headless"`
 
@@ -31,6 +57,8 @@ type Template struct {
 	workflows.Workflow `yaml:",inline,omitempty"`
 	CompiledWorkflow   *workflows.Workflow `yaml:"-" json:"-" jsonschema:"-"`
 
+	// References are the names of the variables referenced by the template
+	References map[string]struct{} `yaml:"-" json:"-"`
 	// TotalRequests is the total number of requests for the template.
 	TotalRequests int `yaml:"-" json:"-"`
 	// Executer is the actual template executor for running template requests
@@ -38,3 +66,109 @@ type Template struct {
 
 	Path string `yaml:"-" json:"-"`
 }
+
+// AddGlobalMatchers adds the compiled operators of the template requests
+// to the global matchers evaluated on the responses of other templates.
+func (t *Template) AddGlobalMatchers(storage *globalmatchers.Storage) {
+	for _, req := range t.RequestsHTTP {
+		storage.Add(t.ID, t.Path, t.Info, req.CompiledOperators, req)
+	}
+	for _, req := range t.RequestsDNS {
+		storage.Add(t.ID, t.Path, t.Info, req.CompiledOperators, req)
+	}
+	for _, req := range t.RequestsFile {
+		storage.Add(t.ID, t.Path, t.Info, req.CompiledOperators, req)
+	}
+	for _, req := range t.RequestsNetwork {
+		storage.Add(t.ID, t.Path, t.Info, req.CompiledOperators, req)
+	}
+	for _, req := range t.RequestsMail {
+		storage.Add(t.ID, t.Path, t.Info, req.CompiledOperators, req)
+	}
+	for _, req := range t.RequestsSSH {
+		storage.Add(t.ID, t.Path, t.Info, req.CompiledOperators, req)
+	}
+	for _, req := range t.RequestsService {
+		storage.Add(t.ID, t.Path, t.Info, req.CompiledOperators, req)
+	}
+	for _, req := range t.RequestsHeadless {
+		storage.Add(t.ID, t.Path, t.Info, req.CompiledOperators, req)
+	}
+}
+
+// StoredValues returns the names of the values stored for the host by the
+// extractors of the template requests.
+func (t *Template) StoredValues() []string {
+	var names []string
+	add := func(operators *operators.Operators) {
+		for _, extractor := range operators.Extractors {
+			if extractor.Store && extractor.Name != "" {
+				names = append(names, extractor.Name)
+			}
+		}
+	}
+	for _, req := range t.RequestsHTTP {
+		add(&req.Operators)
+	}
+	for _, req := range t.RequestsDNS {
+		add(&req.Operators)
+	}
+	for _, req := range t.RequestsFile {
+		add(&req.Operators)
+	}
+	for _, req := range t.RequestsNetwork {
+		add(&req.Operators)
+	}
+	for _, req := range t.RequestsMail {
+		add(&req.Operators)
+	}
+	for _, req := range t.RequestsSSH {
+		add(&req.Operators)
+	}
+	for _, req := range t.RequestsService {
+		add(&req.Operators)
+	}
+	for _, req := range t.RequestsHeadless {
+		add(&req.Operators)
+	}
+	return names
+}
+
+var (
+	markerRegex     = regexp.MustCompile(`\{\{(.*?)\}\}`)
+	identifierRegex = regexp.MustCompile(`[A-Za-z_][A-Za-z0-9_]*`)
+)
+
+// referencedVariables returns the names of the identifiers used in the
+// markers of the template data, including the arguments of helpers.
+func referencedVariables(data []byte) map[string]struct{} {
+	names := make(map[string]struct{})
+	for _, marker := range markerRegex.FindAllSubmatch(data, -1) {
+		for _, name := range identifierRegex.FindAll(marker[1], -1) {
+			names[string(name)] = struct{}{}
+		}
+	}
+	return names
+}
+
+// SupportedProtocols are the names of the protocols of the template requests
+var SupportedProtocols = []string{"http", "dns", "file", "network", "mail", "ssh", "service", "headless"}
+
+// Protocols returns the names of the protocols of the template requests
+func (t *Template) Protocols() []string {
+	var protocols []string
+	add := func(name string, count int) {
+		if count > 0 {
+			protocols = append(protocols, name)
+		}
+	}
+	add("http", len(t.RequestsHTTP))
+	add("dns", len(t.RequestsDNS))
+	add("file", len(t.RequestsFile))
+	add("network", len(t.RequestsNetwork))
+	add("mail", len(t.RequestsMail))
+	add("ssh", len(t.RequestsSSH))
+	add("service", len(t.RequestsService))
+	add("headless", len(t.RequestsHeadless))
+	return protocols
+}
@@ -0,0 +1,286 @@
+package tracing
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	jsoniter "github.com/json-iterator/go"
+	"github.com/pkg/errors"
+	"github.com/projectdiscovery/gologger"
+)
+
+// Tracer records spans of the scan execution exporting them to an
+// OTLP/HTTP collector and span durations as StatsD timings.
+type Tracer struct {
+	options    *Options
+	httpClient *http.Client
+	statsd     net.Conn
+
+	mutex   sync.Mutex
+	pending []*Span
+	flush   chan struct{}
+	done    chan struct{}
+	wg      sync.WaitGroup
+}
+
+// Options contains configuration options for the tracer
+type Options struct {
+	// Endpoint is the OTLP/HTTP collector url (eg. http://localhost:4318)
+	Endpoint string
+	// StatsDAddress is the host:port of a StatsD server for span timings
+	StatsDAddress string
+	// ServiceName is the service name reported for the spans
+	ServiceName string
+	// BatchSize is the number of spans after which they are exported
+	BatchSize int
+	// FlushInterval is the interval after which pending spans are exported
+	FlushInterval time.Duration
+}
+
+// Span is a single timed operation of the scan execution
+type Span struct {
+	tracer     *Tracer
+	name       string
+	traceID    string
+	spanID     string
+	parentID   string
+	start      time.Time
+	end        time.Time
+	attributes []string
+	err        string
+}
+
+// New creates a new tracer from the provided options
+func New(options *Options) (*Tracer, error) {
+	if options.ServiceName == "" {
+		options.ServiceName = "nuclei"
+	}
+	if options.BatchSize <= 0 {
+		options.BatchSize = 512
+	}
+	if options.FlushInterval <= 0 {
+		options.FlushInterval = 5 * time.Second
+	}
+	tracer := &Tracer{
+		options:    options,
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+		flush:      make(chan struct{}, 1),
+		done:       make(chan struct{}),
+	}
+	if options.StatsDAddress != "" {
+		conn, err := net.Dial("udp", options.StatsDAddress)
+		if err != nil {
+			return nil, errors.Wrap(err, "could not connect to statsd server")
+		}
+		tracer.statsd = conn
+	}
+	if options.Endpoint != "" {
+		tracer.wg.Add(1)
+		go tracer.flushLoop()
+	}
+	return tracer, nil
+}
+
+// Start starts a new span as a child of parent if not nil. Attributes
+// are specified as key value pairs.
+func (t *Tracer) Start(name string, parent *Span, attributes ...string) *Span {
+	return t.StartAt(name, parent, time.Now(), attributes...)
+}
+
+// StartAt starts a new span at the start time, for operations which were
+// already started when the span is created.
+func (t *Tracer) StartAt(name string, parent *Span, start time.Time, attributes ...string) *Span {
+	if t == nil {
+		return nil
+	}
+	if start.IsZero() {
+		start = time.Now()
+	}
+	span := &Span{tracer: t, name: name, spanID: randomID(8), start: start, attributes: attributes}
+	if parent != nil {
+		span.traceID = parent.traceID
+		span.parentID = parent.spanID
+	} else {
+		span.traceID = randomID(16)
+	}
+	return span
+}
+
+// SetError marks the span as failed with an error
+func (s *Span) SetError(err error) {
+	if s == nil || err == nil {
+		return
+	}
+	s.err = err.Error()
+}
+
+// End ends the span recording it for export
+func (s *Span) End() {
+	if s == nil {
+		return
+	}
+	s.end = time.Now()
+	s.tracer.record(s)
+}
+
+// record records an ended span sending its timing to statsd. The spans
+// are exported in batches by the flush loop, off the scan path.
+func (t *Tracer) record(span *Span) {
+	if t.statsd != nil {
+		_, _ = fmt.Fprintf(t.statsd, "%s.%s.duration:%d|ms", t.options.ServiceName, span.name, span.end.Sub(span.start).Milliseconds())
+	}
+	if t.options.Endpoint == "" {
+		return
+	}
+
+	t.mutex.Lock()
+	t.pending = append(t.pending, span)
+	full := len(t.pending) >= t.options.BatchSize
+	t.mutex.Unlock()
+
+	if full {
+		select {
+		case t.flush <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// flushLoop exports the pending spans periodically until closed
+func (t *Tracer) flushLoop() {
+	defer t.wg.Done()
+
+	ticker := time.NewTicker(t.options.FlushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			t.export()
+		case <-t.flush:
+			t.export()
+		case <-t.done:
+			return
+		}
+	}
+}
+
+// export exports the pending spans logging the export errors
+func (t *Tracer) export() {
+	if err := t.Flush(); err != nil {
+		gologger.Warning().Msgf("Could not export trace spans: %s\n", err)
+	}
+}
+
+// Flush exports all the pending spans to the collector
+func (t *Tracer) Flush() error {
+	if t == nil {
+		return nil
+	}
+	t.mutex.Lock()
+	spans := t.pending
+	t.pending = nil
+	t.mutex.Unlock()
+
+	for len(spans) > 0 {
+		batch := spans
+		if len(batch) > t.options.BatchSize {
+			batch = spans[:t.options.BatchSize]
+		}
+		spans = spans[len(batch):]
+		if err := t.send(batch); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// send exports a batch of spans to the collector
+func (t *Tracer) send(spans []*Span) error {
+	data, err := jsoniter.Marshal(t.buildRequest(spans))
+	if err != nil {
+		return errors.Wrap(err, "could not marshal spans")
+	}
+	endpoint := strings.TrimSuffix(t.options.Endpoint, "/") + "/v1/traces"
+	resp, err := t.httpClient.Post(endpoint, "application/json", bytes.NewReader(data))
+	if err != nil {
+		return errors.Wrap(err, "could not export spans")
+	}
+	resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("unexpected status code %d exporting spans", resp.StatusCode)
+	}
+	return nil
+}
+
+// Close flushes the pending spans and closes the tracer
+func (t *Tracer) Close() {
+	if t == nil {
+		return
+	}
+	if t.options.Endpoint != "" {
+		close(t.done)
+		t.wg.Wait()
+		t.export()
+	}
+	if t.statsd != nil {
+		_ = t.statsd.Close()
+	}
+}
+
+// buildRequest builds an OTLP/JSON trace export request for spans
+func (t *Tracer) buildRequest(spans []*Span) map[string]interface{} {
+	items := make([]map[string]interface{}, 0, len(spans))
+	for _, span := range spans {
+		item := map[string]interface{}{
+			"traceId":           span.traceID,
+			"spanId":            span.spanID,
+			"name":              span.name,
+			"kind":              1,
+			"startTimeUnixNano": strconv.FormatInt(span.start.UnixNano(), 10),
+			"endTimeUnixNano":   strconv.FormatInt(span.end.UnixNano(), 10),
+			"attributes":        otlpAttributes(span.attributes...),
+		}
+		if span.parentID != "" {
+			item["parentSpanId"] = span.parentID
+		}
+		if span.err != "" {
+			item["status"] = map[string]interface{}{"code": 2, "message": span.err}
+		}
+		items = append(items, item)
+	}
+	return map[string]interface{}{
+		"resourceSpans": []interface{}{
+			map[string]interface{}{
+				"resource":   map[string]interface{}{"attributes": otlpAttributes("service.name", t.options.ServiceName)},
+				"scopeSpans": []interface{}{map[string]interface{}{"scope": map[string]interface{}{"name": "nuclei"}, "spans": items}},
+			},
+		},
+	}
+}
+
+// otlpAttributes converts key value pairs to OTLP attributes
+func otlpAttributes(pairs ...string) []interface{} {
+	attributes := make([]interface{}, 0, len(pairs)/2)
+	for i := 0; i+1 < len(pairs); i += 2 {
+		attributes = append(attributes, map[string]interface{}{
+			"key":   pairs[i],
+			"value": map[string]interface{}{"stringValue": pairs[i+1]},
+		})
+	}
+	return attributes
+}
+
+// randomID returns a random hex encoded id of size bytes
+func randomID(size int) string {
+	id := make([]byte, size)
+	_, _ = rand.Read(id)
+	return hex.EncodeToString(id)
+}
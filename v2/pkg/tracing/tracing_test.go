@@ -0,0 +1,103 @@
+package tracing
+
+import (
+	"errors"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	jsoniter "github.com/json-iterator/go"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTracerExport(t *testing.T) {
+	var received map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/v1/traces", r.URL.Path, "could not get correct export path")
+		data, _ := ioutil.ReadAll(r.Body)
+		_ = jsoniter.Unmarshal(data, &received)
+	}))
+	defer server.Close()
+
+	tracer, err := New(&Options{Endpoint: server.URL})
+	require.Nil(t, err, "could not create tracer")
+
+	parent := tracer.Start("template", nil, "template.id", "test", "host", "example.com")
+	child := tracer.Start("request", parent, "protocol", "http")
+	child.SetError(errors.New("timeout"))
+	child.End()
+	parent.End()
+	tracer.Close()
+
+	require.NotNil(t, received, "could not receive exported spans")
+	resourceSpans := received["resourceSpans"].([]interface{})[0].(map[string]interface{})
+	spans := resourceSpans["scopeSpans"].([]interface{})[0].(map[string]interface{})["spans"].([]interface{})
+	require.Len(t, spans, 2, "could not get all spans")
+
+	childSpan, parentSpan := spans[0].(map[string]interface{}), spans[1].(map[string]interface{})
+	require.Equal(t, parentSpan["traceId"], childSpan["traceId"], "could not get same trace for child")
+	require.Equal(t, parentSpan["spanId"], childSpan["parentSpanId"], "could not get parent of child")
+	require.Len(t, childSpan["traceId"], 32, "could not get correct trace id length")
+	require.Equal(t, "timeout", childSpan["status"].(map[string]interface{})["message"], "could not get span error")
+}
+
+func TestTracerBatchExport(t *testing.T) {
+	exported := make(chan int, 4)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var received map[string]interface{}
+		data, _ := ioutil.ReadAll(r.Body)
+		_ = jsoniter.Unmarshal(data, &received)
+		resourceSpans := received["resourceSpans"].([]interface{})[0].(map[string]interface{})
+		exported <- len(resourceSpans["scopeSpans"].([]interface{})[0].(map[string]interface{})["spans"].([]interface{}))
+	}))
+	defer server.Close()
+
+	tracer, err := New(&Options{Endpoint: server.URL, BatchSize: 2, FlushInterval: time.Hour})
+	require.Nil(t, err, "could not create tracer")
+	defer tracer.Close()
+
+	start := time.Now().Add(-time.Second)
+	span := tracer.StartAt("request", nil, start)
+	require.Equal(t, start, span.start, "could not start span at start time")
+	span.End()
+	tracer.Start("request", nil).End()
+
+	select {
+	case count := <-exported:
+		require.Equal(t, 2, count, "could not export full batch")
+	case <-time.After(5 * time.Second):
+		require.Fail(t, "could not export full batch before the flush interval")
+	}
+}
+
+func TestTracerStatsD(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.Nil(t, err, "could not listen on udp")
+	defer conn.Close()
+
+	tracer, err := New(&Options{StatsDAddress: conn.LocalAddr().String()})
+	require.Nil(t, err, "could not create tracer")
+	defer tracer.Close()
+
+	tracer.Start("template", nil).End()
+
+	buffer := make([]byte, 512)
+	_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	n, _, err := conn.ReadFrom(buffer)
+	require.Nil(t, err, "could not read statsd metric")
+	require.True(t, strings.HasPrefix(string(buffer[:n]), "nuclei.template.duration:"), "could not get correct metric")
+	require.True(t, strings.HasSuffix(string(buffer[:n]), "|ms"), "could not get timing metric")
+}
+
+func TestNilTracer(t *testing.T) {
+	var tracer *Tracer
+	span := tracer.Start("template", nil)
+	require.Nil(t, span, "could get span from nil tracer")
+	span.SetError(errors.New("test"))
+	span.End()
+	tracer.Close()
+}
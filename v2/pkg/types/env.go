@@ -0,0 +1,21 @@
+package types
+
+import (
+	"os"
+	"regexp"
+)
+
+var envVariableRegex = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// ExpandEnv expands ${NAME} references to environment variables in data.
+//
+// References to variables not set in the environment are left untouched
+// so that payloads like ${jndi:...} or ${IFS} are not modified.
+func ExpandEnv(data []byte) []byte {
+	return envVariableRegex.ReplaceAllFunc(data, func(match []byte) []byte {
+		if value, ok := os.LookupEnv(string(match[2 : len(match)-1])); ok {
+			return []byte(value)
+		}
+		return match
+	})
+}
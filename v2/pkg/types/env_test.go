@@ -0,0 +1,16 @@
+package types
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestExpandEnv(t *testing.T) {
+	os.Setenv("NUCLEI_TEST_TOKEN", "secret")
+	defer os.Unsetenv("NUCLEI_TEST_TOKEN")
+
+	data := ExpandEnv([]byte("token: ${NUCLEI_TEST_TOKEN}\npayload: ${jndi:ldap://x}\ncmd: a${NUCLEI_TEST_UNSET}b $NUCLEI_TEST_TOKEN"))
+	require.Equal(t, "token: secret\npayload: ${jndi:ldap://x}\ncmd: a${NUCLEI_TEST_UNSET}b $NUCLEI_TEST_TOKEN", string(data), "could not expand environment variables")
+}
@@ -10,6 +10,12 @@ type Options struct {
 	Tags goflags.StringSlice
 	// ExcludeTags is the list of tags to exclude
 	ExcludeTags goflags.StringSlice
+	// Protocols is the list of protocols the templates are allowed to use
+	Protocols goflags.StringSlice
+	// ExcludeProtocols is the list of protocols the templates are not allowed to use
+	ExcludeProtocols goflags.StringSlice
+	// TemplateConditions filters templates based on conditions on their info (ex. confidence>=medium)
+	TemplateConditions goflags.StringSlice
 	// Workflows specifies any workflows to run by nuclei
 	Workflows goflags.StringSlice
 	// Templates specifies the template/templates to use
@@ -19,8 +25,11 @@ type Options struct {
 	// CustomHeaders is the list of custom global headers to send with each request.
 	CustomHeaders goflags.StringSlice
 	// Severity filters templates based on their severity and only run the matching ones.
-	Severity              goflags.StringSlice
+	Severity goflags.StringSlice
+	// IPVersion is the ordered list of ip versions (4, 6) to scan targets with.
+	IPVersion             goflags.StringSlice
 	InternalResolversList []string // normalized from resolvers flag as well as file provided.
+	InternalDoHResolvers  []string // DNS-over-HTTPS resolver URLs from the resolvers file.
 	// ProjectPath allows nuclei to use a user defined project folder
 	ProjectPath string
 	// InteractshURL is the URL for the interactsh server.
@@ -29,6 +38,62 @@ type Options struct {
 	Target string
 	// Targets specifies the targets to scan using templates.
 	Targets string
+	// TargetRoutes is a JSON lines file of targets with the tags and templates to run on them
+	TargetRoutes string
+	// UncoverQuery is the search query to discover targets with uncover
+	UncoverQuery string
+	// UncoverEngine are the search engines to run the uncover query on
+	UncoverEngine goflags.StringSlice
+	// UncoverLimit is the maximum number of targets per uncover engine
+	UncoverLimit int
+	// UncoverConfig is the file containing the API keys of the uncover engines
+	UncoverConfig string
+	// NmapInput is a nmap or masscan XML output file of services to scan
+	NmapInput string
+	// APISpec is an OpenAPI 3 specification or Postman collection of requests to scan
+	APISpec string
+	// APIVariables are the key=value variables used for the API specification requests
+	APIVariables goflags.StringSlice
+	// TrafficInput is a Burp Suite XML export or HAR file of proxy traffic of requests to scan
+	TrafficInput string
+	// SecretsFile is the file with the credentials injected in the http requests of authenticated scans
+	SecretsFile string
+	// CSRFPatterns are the additional patterns of the csrf tokens extracted from the responses
+	CSRFPatterns goflags.StringSlice
+	// Ports is a list of ports and port ranges to scan each of the targets on
+	Ports string
+	// CrawlDepth is the maximum number of links followed from each crawled target
+	CrawlDepth int
+	// CrawlScope is the scope of the crawled urls - host or subdomain
+	CrawlScope string
+	// CrawlLimit is the maximum number of urls discovered per crawled target
+	CrawlLimit int
+	// Jitter is the maximum random delay in milliseconds added before each request
+	Jitter int
+	// Seed is the seed for the random ordering and jitter
+	Seed int
+	// UserAgentFile is a file with a list of user agents to use for http requests
+	UserAgentFile string
+	// Shard is the index/total shard of the targets to scan
+	Shard string
+	// PathMode is the default mode of combining target and template paths
+	PathMode string
+	// StoreResponseDir is the directory to store the responses in
+	StoreResponseDir string
+	// ScreenshotSeverity is the minimum severity of http matches to screenshot
+	ScreenshotSeverity string
+	// HeadlessSession is a json file of the cookies and local storage items of an origin for the headless pages
+	HeadlessSession string
+	// HeadlessMaxTabs is the maximum number of concurrently open headless browser tabs
+	HeadlessMaxTabs int
+	// CPUProfile is the file to write a cpu profile of the scan to
+	CPUProfile string
+	// MemProfile is the file to write a memory profile to at the end of the scan
+	MemProfile string
+	// ScanTimeout is the time budget of the scan (eg. 4h) after which the remaining work is skipped
+	ScanTimeout string
+	// HostTimeout is the time budget of each host (eg. 10m) after which the remaining work for it is skipped
+	HostTimeout string
 	// Output is the file to write found results to.
 	Output string
 	// ProxyURL is the URL for the proxy server
@@ -39,8 +104,22 @@ type Options struct {
 	TemplatesDirectory string
 	// TraceLogFile specifies a file to write with the trace of all requests
 	TraceLogFile string
+	// AuditLog is the JSONL file recording every outbound request with its byte counts
+	AuditLog string
+	// ScanMetadataFile is the json file to write the metadata of the scan to
+	ScanMetadataFile string
 	// ReportingDB is the db for report storage as well as deduplication
 	ReportingDB string
+	// GroupIssues reports a single issue per template with the affected hosts
+	GroupIssues bool
+	// Enrich annotates the findings of CVE templates with their EPSS score and KEV membership
+	Enrich bool
+	// EnrichmentFile is the file of the enrichment dataset
+	EnrichmentFile string
+	// UpdateEnrichment downloads the latest enrichment dataset
+	UpdateEnrichment bool
+	// TriageFile is the file of the severity overrides and suppressions applied to the findings
+	TriageFile string
 	// ReportingConfig is the config file for nuclei reporting module
 	ReportingConfig string
 	// DiskExportDirectory is the directory to export reports in markdown on disk to
@@ -49,10 +128,45 @@ type Options struct {
 	SarifExport string
 	// ResolversFile is a file containing resolvers for nuclei.
 	ResolversFile string
+	// HostsFile is an /etc/hosts style file containing static host mappings
+	HostsFile string
+	// DNSDataset is a zone file or passive DNS dataset answering the dns
+	// requests of the templates instead of live queries.
+	DNSDataset string
+	// Takeover checks the inputs for subdomain takeover instead of running templates
+	Takeover bool
+	// TakeoverFingerprints is a yaml file of takeover fingerprints replacing the default ones
+	TakeoverFingerprints string
+	// Interface is the network interface whose address the connections originate from
+	Interface string
+	// SourceIP is the local ip address the connections originate from
+	SourceIP string
+	// OTLPEndpoint is the OTLP/HTTP collector url to export execution traces to
+	OTLPEndpoint string
+	// StatsDAddress is the StatsD server address to send execution timings to
+	StatsDAddress string
+	// Coordinator is the address to listen on for workers in distributed mode
+	Coordinator string
+	// Worker is the coordinator url to pull work units from in distributed mode
+	Worker string
+	// DistributedToken is the token used to authenticate workers with the coordinator
+	DistributedToken string
+	// ServerAddress is the address to listen on for api requests in server mode
+	ServerAddress string
+	// ServerToken is the token used to authenticate api requests in server mode
+	ServerToken string
+	// GRPCAddress is the address to listen on for grpc scan calls in server mode
+	GRPCAddress string
+	// Schedule is the cron expression to rerun the scan on as a monitoring daemon
+	Schedule string
+	// ScheduleState is the directory storing the findings seen by scheduled scans
+	ScheduleState string
 	// StatsInterval is the number of seconds to display stats after
 	StatsInterval int
 	// MetricsPort is the port to show metrics on
 	MetricsPort int
+	// PProfPort is the port to expose pprof diagnostics on
+	PProfPort int
 	// BulkSize is the of targets analyzed in parallel for each template
 	BulkSize int
 	// TemplateThreads is the number of templates executed in parallel
@@ -61,10 +175,26 @@ type Options struct {
 	Timeout int
 	// Retries is the number of times to retry the request
 	Retries int
+	// Verify is the number of times matched templates are re-executed to verify the findings
+	Verify int
+	// RetryBackoff is the base delay in milliseconds for exponential retry backoff
+	RetryBackoff int
+	// RetryMaxBackoff is the maximum delay in milliseconds between two retries
+	RetryMaxBackoff int
 	// Rate-Limit is the maximum number of requests per specified target
 	RateLimit int
 	// PageTimeout is the maximum time to wait for a page in seconds
 	PageTimeout int
+	// ResponseReadSize is the maximum size of a response body to read in bytes
+	ResponseReadSize int
+	// ResponseSaveSize is the maximum size of a response body to save in output in bytes
+	ResponseSaveSize int
+	// OutputBufferSize is the memory in megabytes to buffer findings in before spilling to disk
+	OutputBufferSize int
+	// UnitTargets is the number of targets per distributed work unit
+	UnitTargets int
+	// UnitTemplates is the number of templates per distributed work unit
+	UnitTemplates int
 	// InteractionsCacheSize is the number of interaction-url->req to keep in cache at a time.
 	InteractionsCacheSize int
 	// InteractionsPollDuration is the number of seconds to wait before each interaction poll
@@ -72,6 +202,9 @@ type Options struct {
 	// Eviction is the number of seconds after which to automatically discard
 	// interaction requests.
 	InteractionsEviction int
+	// WafThreshold is the number of WAF/rate-limit responses after which a host is
+	// considered blocked and intrusive templates are skipped for it.
+	WafThreshold int
 	// InteractionsColldownPeriod is additional seconds to wait for interactions after closing
 	// of the poller.
 	InteractionsColldownPeriod int
@@ -83,6 +216,22 @@ type Options struct {
 	Headless bool
 	// ShowBrowser specifies whether the show the browser in headless mode
 	ShowBrowser bool
+	// HeadlessPersistSession persists the cookies and local storage of the
+	// headless pages per host for the following templates
+	HeadlessPersistSession bool
+	// MaxConnsPerHost limits the number of connections to each host, 0 uses
+	// the defaults of the http clients.
+	MaxConnsPerHost int
+	// IdleConnTimeout is the number of seconds an idle connection is kept open
+	IdleConnTimeout int
+	// DisableKeepAlive sends each http request on a new connection
+	DisableKeepAlive bool
+	// EnvironmentVariables expands ${NAME} references to environment
+	// variables in the variables block of the templates.
+	EnvironmentVariables bool
+	// ReuseCookies shares the cookies of each host between the http
+	// requests of all the templates instead of each template.
+	ReuseCookies bool
 	// SytemResolvers enables override of nuclei's DNS client opting to use system resolver stack.
 	SystemResolvers bool
 	// Metrics enables display of metrics via an http endpoint
@@ -93,7 +242,11 @@ type Options struct {
 	DebugRequests bool
 	// DebugResponse mode allows debugging response for the engine
 	DebugResponse bool
-	// Silent suppresses any extra text and only writes found URLs on screen.
+	// LogFile is the file the log messages are written to along the screen
+	LogFile string
+	// LogLevel is the maximum level of the log messages (fatal, silent, error, info, warning, debug, verbose)
+	LogLevel string
+	// Silent suppresses the banner and logs, only writing the results on stdout.
 	Silent bool
 	// Version specifies if we should just show version and exit
 	Version bool
@@ -119,10 +272,84 @@ type Options struct {
 	StopAtFirstMatch bool
 	// NoMeta disables display of metadata for the matches
 	NoMeta bool
+	// Control enables the control socket to pause, resume and adjust the scan
+	Control bool
+	// ControlSocket is the path of the control socket
+	ControlSocket string
+	// MockServer is the fixture file of the canned responses served by the mock server
+	MockServer string
+	// MockServerAddress is the address the mock server listens on
+	MockServerAddress string
+	// Test is the directory of the templates whose test fixtures are run instead of scanning
+	Test string
+	// MigrateTemplates is the directory of the templates to migrate to the current spec version
+	MigrateTemplates string
+	// TemplateSchema writes the JSON Schema of the templates to stdout
+	TemplateSchema bool
+	// Lint checks the templates for common pitfalls instead of running them
+	Lint bool
+	// ConfirmThreshold is the number of planned requests above which the
+	// scan only runs when confirmed with Yes, 0 disables the confirmation.
+	ConfirmThreshold int
+	// Yes confirms running scans above the confirmation threshold
+	Yes bool
+	// TemplateStats prints the statistics of the selected templates instead of running them
+	TemplateStats bool
+	// NewTemplate is the file to write a skeleton template to after asking for its details
+	NewTemplate string
+	// WatchTemplates schedules the templates added to the template paths during the scan
+	WatchTemplates bool
+	// Dashboard shows a live terminal dashboard of the scan instead of the findings
+	Dashboard bool
+	// Table writes the findings on screen as aligned columns
+	Table bool
+	// TableWidth is the width the columns of the table output are truncated at
+	TableWidth int
+	// Lang is the language of the template info fields to display and export (ex. zh for name_zh)
+	Lang string
 	// Project is used to avoid sending same HTTP request multiple times
 	Project bool
 	// NewTemplates only runs newly added templates from the repository
 	NewTemplates bool
 	// NoInteractsh disables use of interactsh server for interaction polling
 	NoInteractsh bool
+	// NoHTTPXFallback disables the retry of http requests with the other scheme on protocol errors
+	NoHTTPXFallback bool
+	// WafDetection enables detection of WAF/rate-limit responses per host
+	WafDetection bool
+	// CSRF injects the csrf tokens of the previous responses of a host in its state changing requests
+	CSRF bool
+	// RandomAgent uses a random user agent for each http and headless request
+	RandomAgent bool
+	// StoreResponse saves the request and response of every match to disk
+	StoreResponse bool
+	// StoreResponseAll saves the requests and responses of all the traffic to disk
+	StoreResponseAll bool
+	// Screenshot saves a screenshot of the matched urls of http templates
+	Screenshot bool
+	// ProfileTemplates records the time, requests and errors of each template
+	// and prints a report at the end of the scan.
+	ProfileTemplates bool
+	// PProf exposes pprof diagnostics on localhost during the scan
+	PProf bool
+	// Shuffle randomizes the order of the targets and templates
+	Shuffle bool
+	// Crawl enables discovering the urls of the targets for fuzzing templates
+	Crawl bool
+	// GraphQL enables introspecting the http targets for graphql operations to fuzz
+	GraphQL bool
+	// GraphQLMutations includes the mutations found by introspection in the fuzzed operations
+	GraphQLMutations bool
+	// Uncover enables loading targets from search engines using the uncover query
+	Uncover bool
+	// ServiceDetection enables banner grabbing of host:port inputs to run
+	// network templates only on the ports running their service.
+	ServiceDetection bool
+	// FileAllowlist is the list of directories the file templates are allowed to read
+	FileAllowlist goflags.StringSlice
+	// AllowLocalFileAccess allows templates to load payloads from files
+	// outside the templates directory.
+	AllowLocalFileAccess bool
+	// Server runs nuclei as a REST api server for submitting scans
+	Server bool
 }
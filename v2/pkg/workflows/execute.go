@@ -73,9 +73,7 @@ func (w *Workflow) runWorkflowStep(template *WorkflowTemplate, input string, res
 				}
 
 				for _, matcher := range template.Matchers {
-					_, matchOK := event.OperatorsResult.Matches[matcher.Name]
-					_, extractOK := event.OperatorsResult.Extracts[matcher.Name]
-					if !matchOK && !extractOK {
+					if !matcher.Match(event.OperatorsResult) {
 						continue
 					}
 
@@ -186,3 +186,27 @@ func (m *mockExecuter) ExecuteWithResults(input string, callback protocols.Outpu
 	}
 	return nil
 }
+
+func TestWorkflowMatcherNames(t *testing.T) {
+	result := &operators.Result{
+		Matches:  map[string]struct{}{"tomcat": {}, "manager": {}},
+		Extracts: map[string][]string{"version": {"9.0"}},
+	}
+
+	require.True(t, (&Matcher{Name: "tomcat"}).Match(result), "could not match single name")
+	require.True(t, (&Matcher{Names: []string{"apache", "version"}}).Match(result), "could not match any name")
+	require.True(t, (&Matcher{Names: []string{"tomcat", "manager"}, Condition: "and"}).Match(result), "could not match all names")
+	require.False(t, (&Matcher{Name: "tomcat", Names: []string{"apache"}, Condition: "and"}).Match(result), "could match with missing name")
+	require.False(t, (&Matcher{}).Match(result), "could match without names")
+}
+
+func TestWorkflowEstimatedRequests(t *testing.T) {
+	pair := func() *ProtocolExecuterPair { return &ProtocolExecuterPair{Executer: &mockExecuter{}} }
+	workflow := &Workflow{Workflows: []*WorkflowTemplate{
+		{Executers: []*ProtocolExecuterPair{pair(), pair()}, Subtemplates: []*WorkflowTemplate{{Executers: []*ProtocolExecuterPair{pair()}}}},
+		{Executers: []*ProtocolExecuterPair{pair()}, Matchers: []*Matcher{{Name: "tomcat", Subtemplates: []*WorkflowTemplate{
+			{Executers: []*ProtocolExecuterPair{pair()}, Subtemplates: []*WorkflowTemplate{{Executers: []*ProtocolExecuterPair{pair()}}}},
+		}}}},
+	}}
+	require.Equal(t, 6, workflow.EstimatedRequests(), "could not estimate workflow requests")
+}
@@ -1,6 +1,9 @@
 package workflows
 
-import "github.com/projectdiscovery/nuclei/v2/pkg/protocols"
+import (
+	"github.com/projectdiscovery/nuclei/v2/pkg/operators"
+	"github.com/projectdiscovery/nuclei/v2/pkg/protocols"
+)
 
 // Workflow is a workflow to execute with chained requests, etc.
 type Workflow struct {
@@ -22,6 +25,29 @@ type WorkflowTemplate struct {
 	Executers []*ProtocolExecuterPair
 }
 
+// EstimatedRequests returns the number of requests of the workflow for a
+// target if all its subtemplates run. The subtemplates actually running
+// depend on the matches, so it is an upper bound of the requests.
+func (w *Workflow) EstimatedRequests() int {
+	return estimatedRequests(w.Workflows)
+}
+
+// estimatedRequests returns the requests of the workflow templates with
+// their subtemplates
+func estimatedRequests(list []*WorkflowTemplate) int {
+	var count int
+	for _, template := range list {
+		for _, executer := range template.Executers {
+			count += executer.Executer.Requests()
+		}
+		count += estimatedRequests(template.Subtemplates)
+		for _, matcher := range template.Matchers {
+			count += estimatedRequests(matcher.Subtemplates)
+		}
+	}
+	return count
+}
+
 // ProtocolExecuterPair is a pair of protocol executer and its options
 type ProtocolExecuterPair struct {
 	Executer protocols.Executer
@@ -32,6 +58,35 @@ type ProtocolExecuterPair struct {
 type Matcher struct {
 	// Name is the name of the item to match.
 	Name string `yaml:"name"`
+	// Names are multiple names of items to match along with Name.
+	Names []string `yaml:"names"`
+	// Condition is the condition between the names (and/or). Default is OR.
+	Condition string `yaml:"condition"`
 	// Subtemplates are ran if the name of matcher matches.
 	Subtemplates []*WorkflowTemplate `yaml:"subtemplates"`
 }
+
+// Match returns true if the matcher names match the names of matched
+// matchers or extractors in the operators result.
+func (m *Matcher) Match(result *operators.Result) bool {
+	names := m.Names
+	if m.Name != "" {
+		names = append([]string{m.Name}, names...)
+	}
+	if len(names) == 0 {
+		return false
+	}
+	for _, name := range names {
+		_, matchOK := result.Matches[name]
+		_, extractOK := result.Extracts[name]
+		matched := matchOK || extractOK
+
+		if m.Condition == "and" && !matched {
+			return false
+		}
+		if m.Condition != "and" && matched {
+			return true
+		}
+	}
+	return m.Condition == "and"
+}